@@ -4,23 +4,32 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cloud-shuttle/drover/internal/beads"
 	"github.com/cloud-shuttle/drover/internal/config"
 	"github.com/cloud-shuttle/drover/internal/dashboard"
 	"github.com/cloud-shuttle/drover/internal/db"
 	"github.com/cloud-shuttle/drover/internal/events"
 	"github.com/cloud-shuttle/drover/internal/git"
 	"github.com/cloud-shuttle/drover/internal/modes"
+	"github.com/cloud-shuttle/drover/internal/notify"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/cloud-shuttle/drover/internal/project"
 	"github.com/cloud-shuttle/drover/internal/template"
 	"github.com/cloud-shuttle/drover/internal/tui"
 	"github.com/cloud-shuttle/drover/pkg/types"
@@ -64,15 +73,6 @@ Database modes:
 			}
 			defer store.Close()
 
-			if err := store.InitSchema(); err != nil {
-				return fmt.Errorf("initializing schema: %w", err)
-			}
-
-			// Run any necessary migrations for existing databases
-			if err := store.MigrateSchema(); err != nil {
-				return fmt.Errorf("migrating schema: %w", err)
-			}
-
 			// Copy task template
 			templatePath := filepath.Join(droverDir, "task_template.yaml")
 			templateContent := `# Drover Task Template
@@ -149,23 +149,62 @@ Example for a web project:
 
 # Default labels to apply to all tasks
 # default_labels = ["drover", "go", "backend"]
+
+# Paths agents may not modify; such changes are dropped and the task fails
+# with a policy_violation verdict
+# protected_paths = [".github/workflows/", "infra/"]
+
+# Commands that must pass in a task's worktree before its work is merged; a
+# failure fails the attempt and its output is guidance for the retry
+# verify = ["go build ./...", "go test ./..."]
+# verify_timeout = "10m"
+
+# How new task and epic IDs look: "timestamp" (default), "ulid", "uuid"
+# (UUIDv7) or "short" (e.g. task-k3m9qz)
+# id_format = "short"
+
+# Which ready task workers claim first: "priority" (default; priority, then
+# age), "fifo" (oldest first), "critical-path" (priority, then the task with
+# the most work waiting on it, which shortens runs with long dependency
+# chains) or "round-robin" (epics take turns, so none is starved)
+# schedule = "critical-path"
+
+# How failed attempts are retried: exponential backoff with jitter, and which
+# failure classes (worktree, agent, timeout, rate_limit, git, dod, tests,
+# verdict, verify) are retried at all. The default is
+# "backoff=30s,max=10m,factor=2,jitter=0.2,on=all"
+# retry_policy = "backoff=1m,on=agent|timeout|rate_limit"
+
+# Models a task falls back to in turn when the agent's model is rate limited:
+# a model of the agent, or another agent and its model
+# model_fallback = ["haiku", "opencode/openai/gpt-4o"]
+
+# Output of an agent run kept in memory per stream; a run over it keeps its
+# start and end and writes all of it to .drover/logs (default 32M)
+# max_output = "64M"
 `
+			// Record the branch task work merges into so runs don't have to guess
+			if branch, err := git.DetectDefaultBranch(dir, cfg.BranchPrefix); err == nil {
+				configContent += fmt.Sprintf("\n# Branch task work is merged into (detected at init)\ntarget_branch = %q\n", branch)
+			} else {
+				configContent += "\n# Branch task work is merged into (default: origin's default branch)\n# target_branch = \"main\"\n"
+			}
 			if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
 				return fmt.Errorf("creating project config: %w", err)
 			}
 
-			fmt.Printf("🐂 Initialized Drover in %s\n", droverDir)
-			fmt.Println("\nWorkflow Engine:")
-			fmt.Println("  • DBOS with SQLite (default): Durable execution, automatic recovery")
-			fmt.Println("  • DBOS with PostgreSQL: Set DBOS_SYSTEM_DATABASE_URL for production")
-			fmt.Println("\nNext steps:")
-			fmt.Println("  drover epic add \"My Epic\"")
-			fmt.Println("  drover add \"My first task\" --epic <epic-id>")
-			fmt.Println("  drover run")
-			fmt.Println("\n📋 Files created:")
-			fmt.Println("  • .drover/task_template.yaml - Task quality template")
-			fmt.Println("  • .drover.toml - Project configuration")
-			fmt.Println("\n💡 Customize .drover.toml with your project guidelines!")
+			output.Printf("🐂 Initialized Drover in %s\n", droverDir)
+			output.Println("\nWorkflow Engine:")
+			output.Println("  • DBOS with SQLite (default): Durable execution, automatic recovery")
+			output.Println("  • DBOS with PostgreSQL: Set DBOS_SYSTEM_DATABASE_URL for production")
+			output.Println("\nNext steps:")
+			output.Println("  drover epic add \"My Epic\"")
+			output.Println("  drover add \"My first task\" --epic <epic-id>")
+			output.Println("  drover run")
+			output.Println("\n📋 Files created:")
+			output.Println("  • .drover/task_template.yaml - Task quality template")
+			output.Println("  • .drover.toml - Project configuration")
+			output.Println("\n💡 Customize .drover.toml with your project guidelines!")
 
 			return nil
 		},
@@ -179,6 +218,27 @@ func runCmd() *cobra.Command {
 	var poolEnabled bool
 	var poolMinSize int
 	var poolMaxSize int
+	var poolRetain bool
+	var isolation string
+	var prMode bool
+	var branchTemplate string
+	var targetBranch string
+	var rampUp time.Duration
+	var leaseTTL time.Duration
+	var drainTimeout time.Duration
+	var maxCost float64
+	var maxDuration time.Duration
+	var preemptGap int
+	var hedgeFactor float64
+	var hedgeBudget int
+	var failureStreak int
+	var daemon bool
+	var idleAfter time.Duration
+	var controlAddr string
+	var dashboardPort string
+	var diagnosticsIterations int
+	var testShards int
+	var openCodeServers int
 	var workerMode string
 	var requireApproval bool
 	var planningRequireApproval bool
@@ -188,6 +248,20 @@ func runCmd() *cobra.Command {
 	var buildingVerifySteps bool
 	var refinementEnabled bool
 	var refinementMaxRefinements int
+	var dryRun bool
+	var schedule string
+	var retryPolicy string
+	var fixBlockers bool
+	var claudeResume bool
+	var modelFallback string
+	var maxOutput string
+	var remoteWorkers string
+	var workerPool int
+	var notifyOn string
+	var notifyFailuresOnly bool
+	var queueConcurrency int
+	var queueGlobalConcurrency int
+	var queueRateLimit string
 
 	cmd := &cobra.Command{
 		Use:   "run",
@@ -200,16 +274,197 @@ to control parallelism. Use --epic to filter execution to a specific epic.
 DBOS Workflow Engine:
 - Default: SQLite-based orchestration (zero setup)
 - With DBOS_SYSTEM_DATABASE_URL: DBOS with PostgreSQL (production mode)
+Each task attempt runs as a DBOS workflow named after the task and attempt,
+so running 'drover run' again attaches to tasks still queued or running
+instead of starting them twice.
+--queue-concurrency, --queue-global-concurrency and --queue-rate-limit limit
+the DBOS task queue; 'drover queue limits' tightens or relaxes them on a
+running deployment, up to the limits it started with.
 
 Worktree Pooling:
 Use --pool to enable worktree pooling for faster cold-start times.
-Pre-warmed worktrees reduce setup time for tasks.`,
+Pre-warmed worktrees reduce setup time for tasks. With --pool-retain a task
+whose work was merged hands its worktree back to the pool, reset to the
+target branch but keeping ignored build output, so later tasks in the same
+part of the tree start with warm build caches. A task gets the warm worktree
+that last ran a task of its epic or built the paths it mentions.
+
+Isolation:
+Use --isolation clone to give each task a full local clone (git clone --shared)
+instead of a linked worktree, for tools that misbehave when .git is a file.
+
+Diagnostics:
+Use --diagnostics N to run go vet, tsc or cargo clippy after the agent finishes
+and hand their findings back to it, up to N times, before the task is committed.
+go vet checks only the packages the agent changed. Set diagnostics_command in .drover.toml to use a different checker.
+
+Test sharding:
+Use --test-shards N to split a task's go test or jest run into up to N parallel
+shards, one per worker that is idle when the tests start.
+
+Leases:
+A claimed task is leased to its worker, which renews the lease while it runs.
+If the worker dies the lease expires after --lease-ttl and the task goes back
+to the queue, counting the lost run as an attempt.
+
+Interrupting:
+The first Ctrl-C drains the run: workers stop claiming tasks and the run ends
+once the tasks in flight finish. A second Ctrl-C, or --drain-timeout passing
+(10m by default), stops the in-flight tasks at once. With --drain-timeout 0
+the first Ctrl-C stops them.
+
+Cost budget:
+Use --max-cost to cap what the run spends on agents, in USD, as the agents
+report it. Once the budget is reached no new tasks start; the ones in flight
+finish and the summary shows the spend per epic. Agents that don't report
+cost never reach the budget.
+
+Time budget:
+Use --max-duration to bound how long the run goes on, e.g. --max-duration 8h
+for an overnight run. A task is only started if a task of average duration
+(over recent tasks) would finish in time; after that the run drains, letting
+the tasks in flight finish.
+
+Preemption:
+Use --preempt N to make room for urgent work: when every worker is busy and
+a ready task outranks the lowest-priority task in flight by N or more
+priority levels, that task is paused, keeping its worktree, and the urgent
+one takes its worker. Once the urgent task is done the paused task goes
+back to the queue and resumes in its worktree where it left off.
+
+Hedging:
+Use --hedge-factor F to race stuck tasks: when a task's agent has been
+running for F times the p95 duration of recent tasks, a second attempt
+starts in a fresh worktree. Whichever finishes first successfully is kept
+and the other is stopped. --hedge-budget (2 by default) bounds how many
+second attempts a run starts. Hedging needs at least 10 completed tasks of
+history.
+
+Kill switch:
+Use --failure-streak N to park the run once N consecutive tasks fail with
+the same error, such as an expired API key or a provider outage. The run
+stops claiming tasks, alerts through the chat notifications, lets the tasks
+in flight finish and ends "parked"; the remaining tasks wait for the next
+run.
+
+Live settings:
+Save .drover/control.toml while a run goes to change its worker count, task
+and drain timeouts and backpressure limits without stopping agents in flight
+(see the README). DBOS runs use 'drover queue limits' instead.
+
+Control API:
+Use --control-addr (e.g. localhost:7070) to steer the run over HTTP:
+GET /run reports its state, and POST /run/pause, /run/resume, /run/drain,
+/run/abort and /run/workers ({"workers": 8}) pause and resume claiming,
+drain, stop the tasks in flight, or change the worker count. POSTs must be
+application/json, and cross-origin requests and requests for another Host
+are rejected. Set DROVER_CONTROL_TOKEN to require it as a bearer token; a
+non-loopback address needs one. SQLite engine only.
+
+Live dashboard:
+Use --dashboard-port (e.g. 3847) to serve the web dashboard from the run
+itself. Claude, Codex and Amp then report their work as JSON events, as
+OpenCode always does, and each tool call shows up on the dashboard as it
+happens; the task's output still
+reads as plain text.
+
+Daemon:
+Use --daemon to keep the run going once the queue empties: it waits for
+tasks to be added (e.g. by 'drover add' from another shell) and runs them as
+they arrive, until Ctrl-C, --max-duration or --max-cost ends it. When the
+queue has been empty for --idle-after (5m by default) the worktree pool
+drops its warm worktrees, warming them again when work comes in. Daemon mode
+needs the SQLite engine.
+
+Retries:
+A failed attempt goes back to the queue until the task runs out of attempts,
+after an exponential backoff with jitter (30s, doubling up to 10m, by
+default). Use --retry-policy or retry_policy in .drover.toml to change it,
+e.g. "backoff=1m,max=30m,factor=3,jitter=0.1,on=agent|timeout|rate_limit";
+failures of classes not listed in on fail the task at once. Tasks can
+override the policy with 'drover add --retry-policy'. The next attempt is
+told the error the previous one failed with; with --claude-resume (or
+DROVER_CLAUDE_RESUME) Claude also resumes the previous attempt's session.
+
+Model fallback:
+Use --model-fallback or model_fallback in .drover.toml to keep tasks going
+when the agent's model is rate limited, e.g. "haiku,opencode/openai/gpt-4o":
+a run that ends in a rate limit goes on with the next model, and the limited
+model is passed over for 5 minutes. Each attempt records the model that ran
+it ('drover show').
+
+Output limit:
+An agent run keeps up to 32M of its stdout and of its stderr in memory (set
+with --max-output or max_output in .drover.toml). A chattier run keeps the
+start and end of its output, noting how much was cut, and writes all of it to
+the attempt's log under .drover/logs.
+
+Remote workers:
+--remote-workers (or DROVER_REMOTE_WORKERS) runs tasks with drover-worker on
+other machines over SSH, e.g. builder@big-box,gpu-1, each task on the host
+running the fewest. A task's worktree is synced to ~/.drover/remote/<task> on
+the host with rsync (DROVER_REMOTE_WORKER_DIR changes where), the worker runs
+there and the worktree is synced back to be committed as usual. The hosts need
+drover-worker, Claude Code and rsync on the PATH (DROVER_REMOTE_WORKER_BINARY
+names the worker), and key-based SSH access, as ssh runs in batch mode.
+
+Worker pool:
+--worker-pool N (or DROVER_WORKER_POOL) keeps N drover-worker processes
+running and dispatches tasks to them over the worker protocol, so a task
+doesn't wait for a worker to start. A worker is replaced after 25 tasks
+(DROVER_WORKER_POOL_MAX_TASKS) to bound what it leaks, and one that crashes
+is replaced at once. Pooled workers run locally, without a sandbox.
+
+Verdicts:
+Agents that report a verdict on their own work decide what a clean exit
+means: pass completes and merges the task, fail retries it (failure class
+verdict) with the agent's reason as guidance for the next attempt, and
+blocked marks it blocked with the reason until 'drover resolve' sends it
+back to the queue.
+
+Verification:
+Commands listed under verify in .drover.toml (e.g. verify = ["go test ./..."])
+run in a task's worktree after the agent finishes and before its work is
+merged. A failing command fails the attempt (failure class verify) with a
+fail verdict, and the end of its output is guidance for the next attempt.
+
+Blockers:
+Use --fix-blockers to have failures caused by something outside the task's
+own work turn into fix tasks: a missing module, package or tool, tests
+failing in code the task didn't change, or a broken lint configuration. The
+failed task waits for its fix task to complete and then runs again. Tasks failing on the
+same blocker share one fix task, which is claimed ahead of every task waiting
+on it: one above the highest of their priorities, plus one per further waiting
+task, so fixes blocking more work run first.
+
+Notifications:
+Set DROVER_SLACK_WEBHOOK_URL or DROVER_DISCORD_WEBHOOK_URL to have the run
+post when it starts, an alert with an excerpt of the error for each task that
+fails for good, and a summary when it ends. DROVER_DASHBOARD_URL adds a link
+to every message. --notify-on limits what is posted, e.g. "failure,end", and
+--notify-failures-only skips the start and any summary without failures.
+
+Scheduling:
+Workers claim the highest-priority ready task, oldest first. With
+--schedule critical-path, ties go to the task with the longest chain of
+unfinished tasks waiting on it, then the one blocking the most tasks, so
+long dependency chains start early and the run finishes sooner.
+--schedule fifo claims the oldest ready task whatever its priority, and
+--schedule round-robin has the epics with ready tasks take turns. Set
+schedule in .drover.toml to make it the default.
+
+Dry run:
+Use --dry-run to print the waves of tasks the run would execute, by dependency
+and priority for the worker count, and any dependency cycles, without
+creating worktrees or invoking agents.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectDir, store, err := requireProject()
 			if err != nil {
 				return err
 			}
 			defer store.Close()
+			// Workers, not the operator, make the transitions during a run
+			store.SetActor("")
 
 			// Override config if flags specified
 			runCfg := *cfg
@@ -217,13 +472,145 @@ Pre-warmed worktrees reduce setup time for tasks.`,
 				runCfg.Workers = workers
 			}
 			runCfg.Verbose = verbose
-			runCfg.PoolEnabled = poolEnabled
+			if cmd.Flags().Changed("pool") {
+				runCfg.PoolEnabled = poolEnabled
+			}
 			if poolMinSize > 0 {
 				runCfg.PoolMinSize = poolMinSize
 			}
 			if poolMaxSize > 0 {
 				runCfg.PoolMaxSize = poolMaxSize
 			}
+			if cmd.Flags().Changed("pool-retain") {
+				runCfg.PoolRetain = poolRetain
+			}
+			if isolation != "" {
+				runCfg.IsolationMode = isolation
+			}
+			if cmd.Flags().Changed("pr-mode") {
+				runCfg.PRMode = prMode
+			}
+			if branchTemplate != "" {
+				runCfg.BranchTemplate = branchTemplate
+			}
+			if targetBranch != "" {
+				runCfg.TargetBranch = targetBranch
+			}
+			runCfg.Epic = epicID
+			if cmd.Flags().Changed("ramp-up") {
+				runCfg.BackpressureRampUpInterval = rampUp
+			}
+			if cmd.Flags().Changed("lease-ttl") {
+				runCfg.LeaseTTL = leaseTTL
+			}
+			if cmd.Flags().Changed("drain-timeout") {
+				runCfg.DrainTimeout = drainTimeout
+			}
+			if cmd.Flags().Changed("max-cost") {
+				if maxCost < 0 {
+					return fmt.Errorf("--max-cost must not be negative")
+				}
+				runCfg.MaxCost = maxCost
+			}
+			if cmd.Flags().Changed("max-duration") {
+				if maxDuration < 0 {
+					return fmt.Errorf("--max-duration must not be negative")
+				}
+				runCfg.MaxDuration = maxDuration
+			}
+			if cmd.Flags().Changed("preempt") {
+				if preemptGap < 0 {
+					return fmt.Errorf("--preempt must not be negative")
+				}
+				runCfg.PreemptGap = preemptGap
+			}
+			if cmd.Flags().Changed("hedge-factor") {
+				if hedgeFactor < 0 {
+					return fmt.Errorf("--hedge-factor must not be negative")
+				}
+				runCfg.HedgeFactor = hedgeFactor
+			}
+			if cmd.Flags().Changed("hedge-budget") {
+				if hedgeBudget < 0 {
+					return fmt.Errorf("--hedge-budget must not be negative")
+				}
+				runCfg.HedgeBudget = hedgeBudget
+			}
+			if cmd.Flags().Changed("failure-streak") {
+				if failureStreak < 0 {
+					return fmt.Errorf("--failure-streak must not be negative")
+				}
+				runCfg.FailureStreak = failureStreak
+			}
+			if cmd.Flags().Changed("daemon") {
+				runCfg.Daemon = daemon
+			}
+			if cmd.Flags().Changed("idle-after") {
+				if idleAfter < 0 {
+					return fmt.Errorf("--idle-after must not be negative")
+				}
+				runCfg.DaemonIdle = idleAfter
+			}
+			if controlAddr != "" {
+				runCfg.ControlAddr = controlAddr
+			}
+			if dashboardPort != "" {
+				runCfg.DashboardPort = dashboardPort
+			}
+			if cmd.Flags().Changed("fix-blockers") {
+				runCfg.FixBlockers = fixBlockers
+			}
+			if cmd.Flags().Changed("model-fallback") {
+				runCfg.ModelFallback = modelFallback
+			}
+			if cmd.Flags().Changed("max-output") {
+				runCfg.MaxOutput = maxOutput
+			}
+			if cmd.Flags().Changed("remote-workers") {
+				runCfg.RemoteWorkers = remoteWorkers
+				runCfg.UseWorkerSubprocess = runCfg.UseWorkerSubprocess || remoteWorkers != ""
+			}
+			if cmd.Flags().Changed("worker-pool") {
+				if workerPool < 0 {
+					return fmt.Errorf("--worker-pool must not be negative")
+				}
+				runCfg.WorkerPoolSize = workerPool
+				runCfg.UseWorkerSubprocess = runCfg.UseWorkerSubprocess || workerPool > 0
+			}
+			if cmd.Flags().Changed("claude-resume") {
+				runCfg.ClaudeResume = claudeResume
+			}
+			if cmd.Flags().Changed("notify-on") {
+				if _, err := notify.ParseEvents(notifyOn); err != nil {
+					return fmt.Errorf("--notify-on: %w", err)
+				}
+				runCfg.NotifyOn = notifyOn
+			}
+			if cmd.Flags().Changed("notify-failures-only") {
+				runCfg.NotifyFailuresOnly = notifyFailuresOnly
+			}
+			if cmd.Flags().Changed("queue-concurrency") {
+				runCfg.QueueWorkerConcurrency = queueConcurrency
+			}
+			if cmd.Flags().Changed("queue-global-concurrency") {
+				runCfg.QueueGlobalConcurrency = queueGlobalConcurrency
+			}
+			if cmd.Flags().Changed("queue-rate-limit") {
+				limit, period, err := config.ParseRateLimit(queueRateLimit)
+				if err != nil {
+					return fmt.Errorf("--queue-rate-limit: %w", err)
+				}
+				runCfg.QueueRateLimit, runCfg.QueueRatePeriod = limit, period
+			}
+			if cmd.Flags().Changed("diagnostics") {
+				runCfg.DiagnosticsIterations = diagnosticsIterations
+			}
+			if cmd.Flags().Changed("test-shards") {
+				runCfg.TestShards = testShards
+			}
+			if cmd.Flags().Changed("opencode-servers") {
+				runCfg.OpenCodeServers = openCodeServers
+			}
 			// Override worker mode settings if flags specified
 			if workerMode != "" {
 				runCfg.WorkerMode = modes.WorkerMode(workerMode)
@@ -253,10 +640,48 @@ Pre-warmed worktrees reduce setup time for tasks.`,
 				runCfg.Modes.Refinement.MaxRefinements = refinementMaxRefinements
 			}
 
+			if cmd.Flags().Changed("schedule") {
+				runCfg.Schedule = schedule
+			}
+			if cmd.Flags().Changed("retry-policy") {
+				runCfg.RetryPolicy = retryPolicy
+			}
+			claimSchedule, err := applySchedule(projectDir, store, runCfg.Schedule)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				return printDryRun(store, runCfg.Workers, epicID, claimSchedule)
+			}
+
+			// Dependency problems don't stop the rest of the run, but say
+			// up front which tasks it will never reach
+			if report, err := store.ValidateGraph(); err != nil {
+				output.Printf("⚠️  Checking the dependency graph: %v\n", err)
+			} else if !report.OK() {
+				printGraphReport(report)
+				output.Println("⚠️  These tasks will not run; see 'drover validate-graph'")
+			}
+
+			if runCfg.DashboardPort != "" {
+				stop, err := serveRunDashboard(store, projectDir, runCfg.DashboardPort)
+				if err != nil {
+					return err
+				}
+				defer stop()
+			}
+
 			// Check if DBOS mode is enabled via environment variable
 			dbosURL := os.Getenv("DBOS_SYSTEM_DATABASE_URL")
 
 			if dbosURL != "" {
+				if runCfg.Daemon {
+					return fmt.Errorf("--daemon is not supported with the DBOS engine")
+				}
+				if runCfg.ControlAddr != "" {
+					return fmt.Errorf("--control-addr is not supported with the DBOS engine")
+				}
 				// Use DBOS orchestrator for production
 				return runWithDBOS(cmd, &runCfg, store, projectDir, dbosURL, epicID)
 			}
@@ -272,6 +697,41 @@ Pre-warmed worktrees reduce setup time for tasks.`,
 	cmd.Flags().BoolVar(&poolEnabled, "pool", false, "Enable worktree pooling for faster cold-start")
 	cmd.Flags().IntVar(&poolMinSize, "pool-min", 0, "Minimum warm worktrees (default: 2)")
 	cmd.Flags().IntVar(&poolMaxSize, "pool-max", 0, "Maximum pooled worktrees (default: 10)")
+	cmd.Flags().BoolVar(&poolRetain, "pool-retain", false, "Return the worktrees of merged tasks to the pool warm instead of removing them")
+	cmd.Flags().StringVar(&isolation, "isolation", "", "Task isolation: worktree or clone (default: worktree)")
+	cmd.Flags().BoolVar(&prMode, "pr-mode", false, "Push task branches and report commit status checks instead of merging to main")
+	cmd.Flags().DurationVar(&rampUp, "ramp-up", 0, "Start one worker and add another every interval while healthy (e.g. 15s)")
+	cmd.Flags().DurationVar(&leaseTTL, "lease-ttl", 0, "Return a claimed task to the queue when its worker stops renewing the claim for this long (default: 5m, 0 disables)")
+	cmd.Flags().DurationVar(&maxDuration, "max-duration", 0, "Stop starting tasks that wouldn't finish within this long of the run starting, e.g. 2h (0 = no limit)")
+	cmd.Flags().IntVar(&preemptGap, "preempt", 0, "Pause the lowest-priority running task for a ready task at least this many priority levels higher (0 = never preempt)")
+	cmd.Flags().Float64Var(&hedgeFactor, "hedge-factor", 0, "Start a second attempt of a task whose agent runs past this multiple of the p95 task duration, keeping whichever finishes first (0 = never hedge)")
+	cmd.Flags().IntVar(&hedgeBudget, "hedge-budget", 0, "Second attempts --hedge-factor may start in a run (default: 2)")
+	cmd.Flags().IntVar(&failureStreak, "failure-streak", 0, "Park the run once this many consecutive tasks fail the same way, e.g. on an auth failure or outage (0 = never)")
+	cmd.Flags().BoolVar(&daemon, "daemon", false, "Keep running once the queue empties, executing tasks as they are added")
+	cmd.Flags().StringVar(&dashboardPort, "dashboard-port", "", "Serve the web dashboard from the run on this port, streaming agents' tool calls to it live")
+	cmd.Flags().StringVar(&controlAddr, "control-addr", "", "Serve an HTTP API to pause, resume, drain or abort the run and change its workers on this address, e.g. localhost:7070")
+	cmd.Flags().DurationVar(&idleAfter, "idle-after", 0, "In daemon mode, scale the worktree pool down after the queue has been empty this long (default: 5m, 0 never)")
+	cmd.Flags().Float64Var(&maxCost, "max-cost", 0, "Stop starting tasks once the run has spent this many USD, finishing the ones in flight (0 = no limit)")
+	cmd.Flags().DurationVar(&drainTimeout, "drain-timeout", 0, "After Ctrl-C, how long to let in-flight tasks finish before stopping them (default: 10m, 0 stops them at once)")
+	cmd.Flags().IntVar(&diagnosticsIterations, "diagnostics", 0, "Fix-it rounds feeding vet/tsc/clippy findings back to the agent before commit (0 disables)")
+	cmd.Flags().IntVar(&testShards, "test-shards", 0, "Split go test/jest runs into up to N parallel shards across idle workers (0 disables)")
+	cmd.Flags().IntVar(&openCodeServers, "opencode-servers", 0, "Keep N warm opencode servers and attach task executions to them (opencode agent only)")
+	cmd.Flags().StringVar(&branchTemplate, "branch-template", "", "Task branch name template using {prefix}, {id}, {epic}, {slug}, {date} (default: {prefix}-{id})")
+	cmd.Flags().StringVar(&targetBranch, "target-branch", "", "Branch to merge task work into (default: target_branch in .drover.toml, else origin's default branch)")
+	cmd.Flags().StringVar(&retryPolicy, "retry-policy", "", "How failed attempts are retried, e.g. \"backoff=30s,max=10m,factor=2,jitter=0.2,on=all\" (default: retry_policy in .drover.toml)")
+	cmd.Flags().BoolVar(&fixBlockers, "fix-blockers", false, "Queue a fix task, and make the task wait for it, when a failure comes from a missing dependency, an unrelated failing test or the lint configuration")
+	cmd.Flags().StringVar(&modelFallback, "model-fallback", "", "Models a rate limited task falls back to in turn, e.g. \"haiku,opencode/openai/gpt-4o\" (default: model_fallback in .drover.toml)")
+	cmd.Flags().StringVar(&remoteWorkers, "remote-workers", "", "SSH hosts to run drover-worker on, comma-separated, e.g. builder@big-box; worktrees are synced there with rsync (default: DROVER_REMOTE_WORKERS)")
+	cmd.Flags().IntVar(&workerPool, "worker-pool", 0, "Keep N drover-worker processes running and dispatch tasks to them rather than starting a worker per task (default: DROVER_WORKER_POOL)")
+	cmd.Flags().StringVar(&maxOutput, "max-output", "", "Output of an agent run kept in memory per stream, e.g. 64M; a run over it keeps its start and end and spills the rest to the attempt's log (default: max_output in .drover.toml, else 32M)")
+	cmd.Flags().BoolVar(&claudeResume, "claude-resume", false, "Resume the previous attempt's Claude session when a task is retried, so Claude remembers what it tried (claude agent only)")
+	cmd.Flags().StringVar(&notifyOn, "notify-on", "", "Chat notifications to post: any of start, failure, end (default: DROVER_NOTIFY_ON, else all)")
+	cmd.Flags().BoolVar(&notifyFailuresOnly, "notify-failures-only", false, "Only post chat notifications about failures: no run start, and no summary for a run without failed tasks")
+	cmd.Flags().IntVar(&queueConcurrency, "queue-concurrency", 0, "DBOS: tasks this process runs at once (0 = no limit)")
+	cmd.Flags().IntVar(&queueGlobalConcurrency, "queue-global-concurrency", 0, "DBOS: tasks running at once across every process sharing the queue (0 = no limit)")
+	cmd.Flags().StringVar(&queueRateLimit, "queue-rate-limit", "", "DBOS: task starts allowed per period, e.g. \"10/1m\" (default: no limit)")
+	cmd.Flags().StringVar(&schedule, "schedule", "", "Which ready task is claimed first: priority, fifo, critical-path or round-robin (default: schedule in .drover.toml, else priority)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the execution plan without creating worktrees or invoking agents")
 
 	// Worker mode flags
 	cmd.Flags().StringVar(&workerMode, "mode", "", "Worker mode: combined, planning, or building")
@@ -289,11 +749,11 @@ Pre-warmed worktrees reduce setup time for tasks.`,
 
 // runWithDBOS executes tasks using DBOS workflow engine
 func runWithDBOS(cmd *cobra.Command, runCfg *config.Config, store *db.Store, projectDir, dbosURL, epicID string) error {
-	fmt.Println("🐂 Using DBOS workflow engine (PostgreSQL)")
+	output.Println("🐂 Using DBOS workflow engine (PostgreSQL)")
 
 	// Show epic filter if specified
 	if epicID != "" {
-		fmt.Printf("🎯 Filtering to epic: %s\n", epicID)
+		output.Printf("🎯 Filtering to epic: %s\n", epicID)
 	}
 
 	// Initialize DBOS context
@@ -310,6 +770,7 @@ func runWithDBOS(cmd *cobra.Command, runCfg *config.Config, store *db.Store, pro
 	if err != nil {
 		return fmt.Errorf("creating DBOS orchestrator: %w", err)
 	}
+	defer orch.Stop()
 
 	// Register workflows
 	if err := orch.RegisterWorkflows(); err != nil {
@@ -328,9 +789,23 @@ func runWithDBOS(cmd *cobra.Command, runCfg *config.Config, store *db.Store, pro
 		return fmt.Errorf("listing tasks: %w", err)
 	}
 
+	// Enqueue in claim order, so tasks moved with 'drover queue' keep their place
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].ClaimPriority() > tasks[j].ClaimPriority()
+	})
+
 	// Convert to DBOS TaskInput format
 	taskInputs := make([]workflow.TaskInput, 0, len(tasks))
 	for _, task := range tasks {
+		// Sub-tasks run in sequence inside their parent's workflow
+		if task.ParentID != "" {
+			continue
+		}
+		// Tasks scheduled for later wait for a run after their not-before time
+		if task.Status == "ready" && task.ScheduledAt != nil && *task.ScheduledAt > time.Now().Unix() {
+			output.Printf("⏰ Skipping task %s: scheduled for %s\n", task.ID, time.Unix(*task.ScheduledAt, 0).Format(time.RFC3339))
+			continue
+		}
 		if task.Status == "ready" || task.Status == "claimed" || task.Status == "in_progress" {
 			blockedBy, _ := store.GetBlockedBy(task.ID)
 			taskInputs = append(taskInputs, workflow.TaskInput{
@@ -341,11 +816,14 @@ func runWithDBOS(cmd *cobra.Command, runCfg *config.Config, store *db.Store, pro
 				Priority:    task.Priority,
 				MaxAttempts: task.MaxAttempts,
 				BlockedBy:   blockedBy,
+				MutexKey:    task.MutexKey,
+				Repo:        task.Repo,
 			})
 		}
 	}
 
 	// Execute with queue for parallel processing
+	orch.NotifyRunStarted(epicID, len(taskInputs))
 	input := workflow.QueuedTasksInput{Tasks: taskInputs}
 	handle, err := dbos.RunWorkflow(dbosCtx, orch.ExecuteTasksWithQueue, input)
 	if err != nil {
@@ -354,6 +832,7 @@ func runWithDBOS(cmd *cobra.Command, runCfg *config.Config, store *db.Store, pro
 
 	// Wait for results
 	stats, err := handle.GetResult()
+	orch.WriteRunReport(epicID, err == nil)
 	if err != nil {
 		return fmt.Errorf("DBOS workflow execution failed: %w", err)
 	}
@@ -364,7 +843,7 @@ func runWithDBOS(cmd *cobra.Command, runCfg *config.Config, store *db.Store, pro
 }
 
 func runWithSQLite(cmd *cobra.Command, runCfg *config.Config, store *db.Store, projectDir, epicID string) error {
-	fmt.Println("🐂 Using SQLite-based orchestrator (local mode)")
+	output.Println("🐂 Using SQLite-based orchestrator (local mode)")
 
 	// Create orchestrator
 	orch, err := workflow.NewOrchestrator(runCfg, store, projectDir)
@@ -377,23 +856,10 @@ func runWithSQLite(cmd *cobra.Command, runCfg *config.Config, store *db.Store, p
 		orch.SetEpicFilter(epicID)
 	}
 
-	// Setup context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Handle interrupt signals - only process the first one
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		fmt.Println("\n🛑 Interrupt received, stopping gracefully...")
-		cancel()
-		// Stop listening for signals after first interrupt
-		signal.Stop(sigCh)
-	}()
-
-	// Run the orchestrator
-	return orch.Run(ctx)
+	// Run the orchestrator. It handles interrupts itself: the first drains
+	// the run, a second (or the drain timeout passing) cancels the tasks in
+	// flight
+	return orch.Run(context.Background())
 }
 
 func addCmd() *cobra.Command {
@@ -407,6 +873,13 @@ func addCmd() *cobra.Command {
 		testMode     string
 		testScope    string
 		testCommand  string
+		operator     string
+		notBefore    string
+		due          string
+		retryPolicy  string
+		mutexKey     string
+		repo         string
+		envVars      []string
 	)
 
 	command := &cobra.Command{
@@ -433,10 +906,37 @@ Automated Testing:
     diff       (default) Only run tests if files changed
     all        Always run all tests
     skip       Skip running tests
-  Use --test-command for custom test command (e.g., "make test-unit")`,
+  Use --test-command for custom test command (e.g., "make test-unit")
+
+Scheduling:
+  Use --not-before to hold a task until a time, and --due to set a deadline.
+  Both take a date ("2026-03-01"), a date and time ("2026-03-01 14:00",
+  RFC 3339) or an offset from now ("36h", "2d")
+
+Retries:
+  Use --retry-policy to override the run's retry policy for this task, e.g.
+  "backoff=1m,max=30m,on=agent|timeout". Settings: backoff, max, factor,
+  jitter and on (failure classes: worktree, agent, timeout, rate_limit, git,
+  dod, tests, verdict, verify, or all)
+
+Mutual exclusion:
+  Use --mutex-key to keep tasks that must not run in parallel apart, e.g. two
+  that both edit schema.sql: of the tasks sharing a key, only one is in
+  flight at a time. Sub-tasks run inside their parent, under its key
+
+Repositories:
+  Use --repo to have the task work in another repository the project drives,
+  by its name under [repos] in .drover.toml (e.g. --repo frontend). The task
+  gets a worktree of that repository and its work is merged into that
+  repository's target branch. Sub-tasks work in their parent's repository
+
+Environment:
+  Use --env KEY=VALUE (repeatable) to set variables the task's agent, tests
+  and verify commands run with, e.g. --env DATABASE_URL=postgres://localhost/test.
+  They override the project's [env] in .drover.toml; see 'drover env'`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			_, store, err := requireProject()
+			projectDir, store, err := requireProject()
 			if err != nil {
 				return err
 			}
@@ -444,6 +944,32 @@ Automated Testing:
 
 			title := args[0]
 
+			if repo != "" {
+				projectCfg, err := project.Load(projectDir)
+				if err != nil {
+					return fmt.Errorf("loading project config: %w", err)
+				}
+				if _, err := projectCfg.RepoPath(projectDir, repo); err != nil {
+					return fmt.Errorf("--repo: %w", err)
+				}
+			}
+
+			scheduledAt, err := parseScheduleTime(notBefore)
+			if err != nil {
+				return fmt.Errorf("--not-before: %w", err)
+			}
+			dueAt, err := parseScheduleTime(due)
+			if err != nil {
+				return fmt.Errorf("--due: %w", err)
+			}
+			if _, err := workflow.ParseRetryPolicy(retryPolicy, workflow.DefaultRetryPolicy); err != nil {
+				return fmt.Errorf("--retry-policy: %w", err)
+			}
+			taskEnv, err := workflow.ParseEnv(envVars)
+			if err != nil {
+				return fmt.Errorf("--env: %w", err)
+			}
+
 			// Auto-detect hierarchical ID syntax (e.g., "task-123.1 Title here")
 			if parentID == "" {
 				// First, try to extract a hierarchical ID prefix from the title
@@ -468,6 +994,12 @@ Automated Testing:
 						// Extract the actual title (after the hierarchical ID prefix)
 						title = strings.TrimSpace(strings.TrimPrefix(title, firstWord+" "))
 
+						if mutexKey != "" {
+							return fmt.Errorf("--mutex-key applies to top-level tasks; sub-tasks run under their parent's")
+						}
+						if repo != "" {
+							return fmt.Errorf("--repo applies to top-level tasks; sub-tasks work in their parent's repository")
+						}
 						// Use CreateSubTaskWithSequence when user specifies a sequence number
 						subTask, err := store.CreateSubTaskWithSequence(title, desc, parentID, sequence, priority, blockedBy)
 						if err != nil {
@@ -479,7 +1011,22 @@ Automated Testing:
 								return fmt.Errorf("setting test configuration: %w", err)
 							}
 						}
-						fmt.Printf("✅ Created task %s\n", subTask.ID)
+						if !scheduledAt.IsZero() || !dueAt.IsZero() {
+							if err := store.SetTaskSchedule(subTask.ID, scheduledAt, dueAt); err != nil {
+								return err
+							}
+						}
+						if retryPolicy != "" {
+							if err := store.SetTaskRetryPolicy(subTask.ID, retryPolicy); err != nil {
+								return err
+							}
+						}
+						if len(taskEnv) > 0 {
+							if err := store.SetTaskEnv(subTask.ID, taskEnv); err != nil {
+								return err
+							}
+						}
+						output.Printf("✅ Created task %s\n", subTask.ID)
 						return nil
 					}
 				}
@@ -489,38 +1036,73 @@ Automated Testing:
 			if !skipValidation {
 				errors := template.Validate(title, desc)
 				if len(errors) > 0 {
-					fmt.Printf("⚠️  Task quality validation failed:\n\n")
+					output.Printf("⚠️  Task quality validation failed:\n\n")
 					for _, e := range errors {
-						fmt.Printf("  [%s] %s\n", e.Field, e.Message)
+						output.Printf("  [%s] %s\n", e.Field, e.Message)
 						for _, s := range e.Suggestions {
-							fmt.Printf("    → %s\n", s)
+							output.Printf("    → %s\n", s)
 						}
-						fmt.Println()
+						output.Println()
 					}
-					fmt.Println("💡 Tips for better tasks:")
-					fmt.Println("  1. Be specific: mention files, components, or packages")
-					fmt.Println("  2. Use action verbs: Create, Fix, Add, Update, Implement")
-					fmt.Println("  3. Add acceptance criteria: how to verify it works")
-					fmt.Println("  4. Include technical details: function names, feature flags")
-					fmt.Println("\nReference template: .drover/task_template.yaml")
-					fmt.Println("\nUse --skip-validation to create this task anyway (not recommended)")
+					output.Println("💡 Tips for better tasks:")
+					output.Println("  1. Be specific: mention files, components, or packages")
+					output.Println("  2. Use action verbs: Create, Fix, Add, Update, Implement")
+					output.Println("  3. Add acceptance criteria: how to verify it works")
+					output.Println("  4. Include technical details: function names, feature flags")
+					output.Println("\nReference template: .drover/task_template.yaml")
+					output.Println("\nUse --skip-validation to create this task anyway (not recommended)")
 					return fmt.Errorf("task validation failed")
 				}
 			}
 
+			if parentID != "" && mutexKey != "" {
+				return fmt.Errorf("--mutex-key applies to top-level tasks; sub-tasks run under their parent's")
+			}
+			if parentID != "" && repo != "" {
+				return fmt.Errorf("--repo applies to top-level tasks; sub-tasks work in their parent's repository")
+			}
+
 			var task *types.Task
 			if parentID != "" {
 				// Create sub-task with hierarchical ID
 				task, err = store.CreateSubTask(title, desc, parentID, priority, blockedBy)
+				if err == nil && operator != "" {
+					err = store.AssignOperator(task.ID, operator)
+				}
 			} else {
 				// Create regular task with test configuration
-				task, err = store.CreateTaskWithTestConfig(title, desc, epicID, priority, blockedBy, "", testMode, testScope, testCommand)
+				task, err = store.CreateTaskWithTestConfig(title, desc, epicID, priority, blockedBy, operator, testMode, testScope, testCommand)
 			}
 			if err != nil {
 				return err
 			}
+			if !scheduledAt.IsZero() || !dueAt.IsZero() {
+				if err := store.SetTaskSchedule(task.ID, scheduledAt, dueAt); err != nil {
+					return err
+				}
+			}
+			if retryPolicy != "" {
+				if err := store.SetTaskRetryPolicy(task.ID, retryPolicy); err != nil {
+					return err
+				}
+			}
+			if mutexKey != "" {
+				if err := store.SetTaskMutexKey(task.ID, mutexKey); err != nil {
+					return err
+				}
+			}
+			if repo != "" {
+				if err := store.SetTaskRepo(task.ID, repo); err != nil {
+					return err
+				}
+			}
+			if len(taskEnv) > 0 {
+				if err := store.SetTaskEnv(task.ID, taskEnv); err != nil {
+					return err
+				}
+			}
 
-			fmt.Printf("✅ Created task %s\n", task.ID)
+			output.Printf("✅ Created task %s\n", task.ID)
 			return nil
 		},
 	}
@@ -534,6 +1116,13 @@ Automated Testing:
 	command.Flags().StringVar(&testMode, "test-mode", "", "Test execution mode: strict (block on failure), lenient (warn only), disabled")
 	command.Flags().StringVar(&testScope, "test-scope", "", "Test scope: diff (only if changed), all (always), skip")
 	command.Flags().StringVar(&testCommand, "test-command", "", "Custom test command (e.g., 'make test-unit')")
+	command.Flags().StringVar(&operator, "operator", "", "Operator (human or bot) overseeing the task")
+	command.Flags().StringVar(&notBefore, "not-before", "", "Don't start the task before this time (date, RFC 3339 or offset like 36h)")
+	command.Flags().StringVar(&due, "due", "", "Deadline after which the task is reported overdue")
+	command.Flags().StringVar(&retryPolicy, "retry-policy", "", "Retry policy overrides for this task, e.g. \"backoff=1m,on=agent|timeout\"")
+	command.Flags().StringVar(&mutexKey, "mutex-key", "", "Never run this task alongside another with the same key (e.g. schema.sql)")
+	command.Flags().StringVar(&repo, "repo", "", "Repository the task works in, by its name under [repos] in .drover.toml")
+	command.Flags().StringArrayVar(&envVars, "env", nil, "Environment variable for the task's agent, as KEY=VALUE (repeatable)")
 	return command
 }
 
@@ -567,8 +1156,8 @@ Examples:
 				return err
 			}
 
-			fmt.Printf("⚡ Quick capture: %s\n", task.ID)
-			fmt.Printf("   %s\n", task.Title)
+			output.Printf("⚡ Quick capture: %s\n", task.ID)
+			output.Printf("   %s\n", task.Title)
 			return nil
 		},
 	}
@@ -597,7 +1186,7 @@ Examples:
 			defer store.Close()
 
 			// Clear screen on start
-			fmt.Print("\033[H\033[2J")
+			output.Print("\033[H\033[2J")
 
 			// Set up signal handling for graceful exit
 			sigChan := make(chan os.Signal, 1)
@@ -613,41 +1202,44 @@ Examples:
 				select {
 				case <-sigChan:
 					// User pressed Ctrl+C
-					fmt.Println("\n\n👋 Watch mode stopped")
+					output.Println("\n\n👋 Watch mode stopped")
 					return nil
 
 				case <-ticker.C:
 					// Get fresh status
 					status, err := store.GetProjectStatus()
 					if err != nil {
-						fmt.Printf("\nError getting status: %v\n", err)
+						output.Printf("\nError getting status: %v\n", err)
 						return err
 					}
 
 					// Only update if something changed
 					if lastStatus == nil || statusChanged(lastStatus, status) {
 						// Clear screen and move cursor to top-left
-						fmt.Print("\033[H\033[2J")
+						output.Print("\033[H\033[2J")
 
 						if onelineMode {
 							// Compact one-line display
-							fmt.Printf("🐂 [%s] %s\n", time.Now().Format("15:04:05"),
+							output.Printf("🐂 [%s] %s\n", time.Now().Format("15:04:05"),
 								printStatusOnelineContent(status))
 						} else {
 							// Full status display with header
-							fmt.Printf("🐂 Drover Watch (live - %s)\n", time.Now().Format("15:04:05"))
-							fmt.Println("════════════════════════════════════════")
-							fmt.Printf("\nTotal:      %d\n", status.Total)
-							fmt.Printf("Ready:      %d\n", status.Ready)
-							fmt.Printf("In Progress: %d\n", status.InProgress)
-							fmt.Printf("Paused:     %d\n", status.Paused)
-							fmt.Printf("Completed:  %d\n", status.Completed)
-							fmt.Printf("Failed:     %d\n", status.Failed)
-							fmt.Printf("Blocked:    %d\n", status.Blocked)
+							output.Printf("🐂 Drover Watch (live - %s)\n", time.Now().Format("15:04:05"))
+							output.Println("════════════════════════════════════════")
+							output.Printf("\nTotal:      %d\n", status.Total)
+							output.Printf("Ready:      %d\n", status.Ready)
+							output.Printf("In Progress: %d\n", status.InProgress)
+							output.Printf("Paused:     %d\n", status.Paused)
+							output.Printf("Completed:  %d\n", status.Completed)
+							output.Printf("Failed:     %d\n", status.Failed)
+							output.Printf("Blocked:    %d\n", status.Blocked)
+							if status.Overdue > 0 {
+								output.Printf("Overdue:    %d\n", status.Overdue)
+							}
 
 							if status.Total > 0 {
 								progress := float64(status.Completed) / float64(status.Total) * 100
-								fmt.Printf("\nProgress: %.1f%%\n", progress)
+								output.Printf("\nProgress: %.1f%%\n", progress)
 								printProgressBarCompact(progress)
 							}
 						}
@@ -676,18 +1268,24 @@ func epicCmd() *cobra.Command {
 			defer store.Close()
 
 			desc, _ := cmd.Flags().GetString("description")
+			parent, _ := cmd.Flags().GetString("parent")
 
-			epic, err := store.CreateEpic(args[0], desc)
+			epic, err := store.CreateSubEpic(args[0], desc, parent)
 			if err != nil {
 				return err
 			}
 
-			fmt.Printf("✅ Created epic %s: %s\n", epic.ID, epic.Title)
+			if epic.ParentEpicID != "" {
+				output.Printf("✅ Created epic %s: %s (sub-epic of %s)\n", epic.ID, epic.Title, epic.ParentEpicID)
+			} else {
+				output.Printf("✅ Created epic %s: %s\n", epic.ID, epic.Title)
+			}
 			return nil
 		},
 	}
 
 	epicAdd.Flags().StringP("description", "d", "", "Epic description")
+	epicAdd.Flags().String("parent", "", "Parent epic ID, to add this epic as a phase of a larger one")
 
 	command := &cobra.Command{
 		Use:   "epic",
@@ -696,7 +1294,133 @@ func epicCmd() *cobra.Command {
 			return cmd.Help()
 		},
 	}
-	command.AddCommand(epicAdd)
+	command.AddCommand(epicAdd, epicCancelCmd(), epicRetryCmd())
+	return command
+}
+
+// epicCancelCmd cancels every unfinished task of an epic
+func epicCancelCmd() *cobra.Command {
+	var reason string
+
+	command := &cobra.Command{
+		Use:   "cancel <epic-id>",
+		Short: "Cancel an epic's unfinished tasks",
+		Long: `Cancel every ready, claimed or running task of an epic and its sub-epics.
+
+Ready tasks are cancelled at once; running ones are stopped by their workers,
+as with 'drover cancel'. Tasks of the epic a DBOS run already queued are
+skipped when their turn comes. Use 'drover epic retry' to run them again.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			epicID := args[0]
+			tasks, err := store.ListTasksByEpic(epicID)
+			if err != nil {
+				return fmt.Errorf("listing tasks: %w", err)
+			}
+			if len(tasks) == 0 {
+				return fmt.Errorf("no tasks in epic %s", epicID)
+			}
+
+			cancelled, stopping := 0, 0
+			for _, task := range tasks {
+				switch task.Status {
+				case types.TaskStatusReady:
+					if err := store.CancelTask(task.ID, reason); err != nil {
+						return fmt.Errorf("cancelling task %s: %w", task.ID, err)
+					}
+					var dataJSON string
+					if reason != "" {
+						data, _ := json.Marshal(map[string]any{"reason": reason})
+						dataJSON = string(data)
+					}
+					_ = store.RecordEvent(uuid.New().String(), string(events.EventTaskCancelled), time.Now().Unix(), task.ID, task.EpicID, dataJSON)
+					cancelled++
+				case types.TaskStatusClaimed, types.TaskStatusInProgress:
+					if err := store.RequestCancel(task.ID, reason); err != nil {
+						return fmt.Errorf("requesting cancellation of task %s: %w", task.ID, err)
+					}
+					stopping++
+				}
+			}
+
+			if cancelled+stopping == 0 {
+				output.Printf("Epic %s has no unfinished tasks to cancel\n", epicID)
+				return nil
+			}
+			output.Printf("✅ Cancelled %d task(s) of epic %s\n", cancelled, epicID)
+			if stopping > 0 {
+				output.Printf("🛑 Cancelling %d running task(s); their workers will stop the agents\n", stopping)
+			}
+			if reason != "" {
+				output.Printf("   Reason: %s\n", reason)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&reason, "reason", "", "Reason for cancellation (optional)")
+	return command
+}
+
+// epicRetryCmd resets an epic's failed and cancelled tasks to ready
+func epicRetryCmd() *cobra.Command {
+	var force bool
+
+	command := &cobra.Command{
+		Use:   "retry <epic-id>",
+		Short: "Retry an epic's failed and cancelled tasks",
+		Long: `Reset every failed or cancelled task of an epic and its sub-epics to 'ready',
+as 'drover retry' does for one task.
+
+Tasks that reached max_attempts are left alone unless --force resets their
+attempt counters.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			epicID := args[0]
+			tasks, err := store.ListTasksByEpic(epicID)
+			if err != nil {
+				return fmt.Errorf("listing tasks: %w", err)
+			}
+			if len(tasks) == 0 {
+				return fmt.Errorf("no tasks in epic %s", epicID)
+			}
+
+			retried, exhausted := 0, 0
+			for _, task := range tasks {
+				if task.Status != types.TaskStatusFailed && task.Status != types.TaskStatusCancelled {
+					continue
+				}
+				if task.Attempts >= task.MaxAttempts && !force {
+					exhausted++
+					continue
+				}
+				if err := store.RetryTask(task.ID, force); err != nil {
+					return fmt.Errorf("retrying task %s: %w", task.ID, err)
+				}
+				retried++
+			}
+
+			output.Printf("✅ Retrying %d task(s) of epic %s\n", retried, epicID)
+			if exhausted > 0 {
+				output.Printf("⚠️  %d task(s) reached max attempts; use --force to retry them too\n", exhausted)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&force, "force", false, "Reset attempt counters to retry tasks that reached max attempts")
 	return command
 }
 
@@ -704,6 +1428,7 @@ func statusCmd() *cobra.Command {
 	var watchMode bool
 	var treeMode bool
 	var onelineMode bool
+	var operator string
 
 	command := &cobra.Command{
 		Use:   "status",
@@ -723,6 +1448,10 @@ func statusCmd() *cobra.Command {
 				return printTreeStatus(store)
 			}
 
+			if operator != "" {
+				return printOperatorTasks(store, operator)
+			}
+
 			status, err := store.GetProjectStatus()
 			if err != nil {
 				return err
@@ -741,9 +1470,28 @@ func statusCmd() *cobra.Command {
 	command.Flags().BoolVarP(&watchMode, "watch", "w", false, "Watch mode - live updates")
 	command.Flags().BoolVarP(&treeMode, "tree", "t", false, "Tree mode - show hierarchical view")
 	command.Flags().BoolVar(&onelineMode, "oneline", false, "Single line summary (e.g., for shell prompts)")
+	command.Flags().StringVar(&operator, "operator", "", "List the tasks overseen by this operator")
 	return command
 }
 
+// printOperatorTasks lists the tasks an operator is overseeing
+func printOperatorTasks(store *db.Store, operator string) error {
+	tasks, err := store.ListTasksByOperator(operator)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		output.Printf("No tasks assigned to %s\n", operator)
+		return nil
+	}
+
+	output.Printf("👤 %s is overseeing %d tasks:\n\n", operator, len(tasks))
+	for _, task := range tasks {
+		output.Printf("  %-16s %-14s %s\n", task.ID, formatTaskStatus(task.Status), task.Title)
+	}
+	return nil
+}
+
 func resumeCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "resume",
@@ -754,10 +1502,10 @@ DBOS automatically handles workflow recovery through its durable execution engin
 If a workflow is interrupted, simply run 'drover run' again and DBOS will
 continue from where it left off.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("🐂 DBOS mode: Workflows are automatically recovered on 'drover run'")
-			fmt.Println("\nTo resume execution, simply run:")
-			fmt.Println("  drover run")
-			fmt.Println("\n💡 DBOS handles workflow recovery automatically through durable execution.")
+			output.Println("🐂 DBOS mode: Workflows are automatically recovered on 'drover run'")
+			output.Println("\nTo resume execution, simply run:")
+			output.Println("  drover run")
+			output.Println("\n💡 DBOS handles workflow recovery automatically through durable execution.")
 			return nil
 		},
 	}
@@ -765,12 +1513,15 @@ continue from where it left off.`,
 
 func infoCmd() *cobra.Command {
 	return &cobra.Command{
-		Use:   "info <task-id>",
-		Short: "Show detailed information about a specific task",
+		Use:     "info <task-id>",
+		Aliases: []string{"show"},
+		Short:   "Show detailed information about a specific task",
 		Long: `Show detailed information about a specific task.
 
 Displays task title, description, status, epic, priority, dependencies,
-and other metadata. Useful for inspecting individual task details.`,
+and other metadata, followed by each execution attempt: when it ran, on
+which worker, how it ended, its error and verdict, and where the agent's
+output was saved. Useful for inspecting individual task details.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			_, store, err := requireProject()
@@ -808,7 +1559,13 @@ and other metadata. Useful for inspecting individual task details.`,
 				}
 			}
 
+			attempts, err := store.ListAttempts(taskID)
+			if err != nil {
+				return err
+			}
+
 			printTaskInfo(task, blockedBy, blocking)
+			printTaskAttempts(attempts)
 			return nil
 		},
 	}
@@ -820,6 +1577,8 @@ func resetCmd() *cobra.Command {
 		resetInProgress bool
 		resetClaimed bool
 		resetFailed bool
+		yes bool
+		force bool
 	)
 
 	command := &cobra.Command{
@@ -828,25 +1587,19 @@ func resetCmd() *cobra.Command {
 		Long: `Reset tasks back to ready status.
 
 If task IDs are provided, only those specific tasks will be reset.
-Otherwise, use flags to specify which statuses to reset.`,
+Otherwise, use flags to specify which statuses to reset.
+
+Resetting clears claims and attempt counts, so the tasks affected, any still
+running and any worktrees with uncommitted changes are listed before asking
+for confirmation. Use --yes to skip the confirmation. Tasks whose workers are
+still alive are not reset unless --force is given.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			_, store, err := requireProject()
+			projectDir, store, err := requireProject()
 			if err != nil {
 				return err
 			}
 			defer store.Close()
 
-			// If specific task IDs are provided, reset only those
-			if len(args) > 0 {
-				count, err := store.ResetTasksByIDs(args)
-				if err != nil {
-					return err
-				}
-				fmt.Printf("🔄 Reset %d task(s) to ready status\n", count)
-				return nil
-			}
-
-			// Otherwise, use status-based reset (existing behavior)
 			var statusesToReset []types.TaskStatus
 
 			if resetCompleted {
@@ -871,12 +1624,76 @@ Otherwise, use flags to specify which statuses to reset.`,
 				}
 			}
 
-			count, err := store.ResetTasks(statusesToReset)
+			// Work out which tasks would be reset
+			var affected []*types.Task
+			if len(args) > 0 {
+				for _, id := range args {
+					task, err := store.GetTask(id)
+					if err != nil {
+						return fmt.Errorf("task not found: %s", id)
+					}
+					affected = append(affected, task)
+				}
+			} else {
+				tasks, err := store.ListTasks()
+				if err != nil {
+					return err
+				}
+				for _, task := range tasks {
+					if slices.Contains(statusesToReset, task.Status) {
+						affected = append(affected, task)
+					}
+				}
+			}
+			if len(affected) == 0 {
+				output.Println("No tasks to reset")
+				return nil
+			}
+
+			ids := make([]string, len(affected))
+			titles := make(map[string]string, len(affected))
+			byStatus := make(map[types.TaskStatus]int)
+			for i, task := range affected {
+				ids[i] = task.ID
+				titles[task.ID] = task.Title
+				byStatus[task.Status]++
+			}
+			gitMgr := git.NewWorktreeManager(projectDir, filepath.Join(projectDir, ".drover", "worktrees"))
+			radius := assessBlastRadius(store, gitMgr, ids)
+
+			output.Printf("🔄 %d task(s) will be reset to ready, clearing their claims and attempts:\n", len(affected))
+			for _, status := range []types.TaskStatus{
+				types.TaskStatusClaimed, types.TaskStatusInProgress, types.TaskStatusCompleted,
+				types.TaskStatusFailed, types.TaskStatusBlocked, types.TaskStatusReady,
+			} {
+				if n := byStatus[status]; n > 0 {
+					output.Printf("  %d %s\n", n, status)
+					delete(byStatus, status)
+				}
+			}
+			for status, n := range byStatus {
+				output.Printf("  %d %s\n", n, status)
+			}
+			radius.print(titles)
+
+			if err := radius.refuseLive(force, "reset"); err != nil {
+				return err
+			}
+			if !confirm("Reset these tasks?", yes || force) {
+				return nil
+			}
+
+			var count int
+			if len(args) > 0 {
+				count, err = store.ResetTasksByIDs(args)
+			} else {
+				count, err = store.ResetTasks(statusesToReset)
+			}
 			if err != nil {
 				return err
 			}
 
-			fmt.Printf("🔄 Reset %d tasks to ready status\n", count)
+			output.Printf("🔄 Reset %d task(s) to ready status\n", count)
 			return nil
 		},
 	}
@@ -885,6 +1702,8 @@ Otherwise, use flags to specify which statuses to reset.`,
 	command.Flags().BoolVar(&resetInProgress, "in-progress", false, "Reset in-progress tasks")
 	command.Flags().BoolVar(&resetClaimed, "claimed", false, "Reset claimed tasks")
 	command.Flags().BoolVar(&resetFailed, "failed", false, "Reset failed tasks")
+	command.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+	command.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation and reset tasks whose workers are still running")
 
 	return command
 }
@@ -1024,6 +1843,7 @@ func exportToBeads(projectDir string, store *db.Store) error {
 				"status":      status,
 				"priority":    task.Priority,
 				"epic_id":     task.EpicID,
+				"reason":      beads.CloseReason(task.Status),
 			},
 		}
 		if err := encoder.Encode(record); err != nil {
@@ -1031,7 +1851,28 @@ func exportToBeads(projectDir string, store *db.Store) error {
 		}
 	}
 
-	fmt.Printf("✅ Exported %d epics and %d tasks to %s\n", len(epics), len(tasks), jsonlPath)
+	// Export dependencies as links
+	deps, err := store.ListAllDependencies()
+	if err != nil {
+		return fmt.Errorf("querying dependencies: %w", err)
+	}
+	for _, dep := range deps {
+		record := map[string]interface{}{
+			"type":      "link",
+			"id":        fmt.Sprintf("link-%s-%s", dep.TaskID, dep.BlockedBy),
+			"timestamp": time.Now(),
+			"data": map[string]interface{}{
+				"from":      dep.TaskID,
+				"to":        dep.BlockedBy,
+				"link_type": "blocked_by",
+			},
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("encoding dependency: %w", err)
+		}
+	}
+
+	output.Printf("✅ Exported %d epics, %d tasks and %d dependencies to %s\n", len(epics), len(tasks), len(deps), jsonlPath)
 	return nil
 }
 
@@ -1096,10 +1937,10 @@ func exportSession(projectDir string, store *db.Store, outputPath, format string
 		return fmt.Errorf("writing session file: %w", err)
 	}
 
-	fmt.Printf("✅ Exported session to %s\n", outputPath)
-	fmt.Printf("   Epics: %d, Tasks: %d, Dependencies: %d, Worktrees: %d\n",
+	output.Printf("✅ Exported session to %s\n", outputPath)
+	output.Printf("   Epics: %d, Tasks: %d, Dependencies: %d, Worktrees: %d\n",
 		len(epics), len(tasks), len(dependencies), len(worktrees))
-	fmt.Println("\nUse 'drover import <file>' on another machine to continue.")
+	output.Println("\nUse 'drover import <file>' on another machine to continue.")
 
 	return nil
 }
@@ -1110,29 +1951,406 @@ func droverStatusToBeads(status types.TaskStatus) string {
 		return "open"
 	case types.TaskStatusInProgress:
 		return "active"
-	case types.TaskStatusCompleted, types.TaskStatusFailed:
+	case types.TaskStatusCompleted, types.TaskStatusFailed, types.TaskStatusCancelled:
 		return "closed"
 	default:
 		return "open"
 	}
 }
 
-// pauseCmd pauses a running task
-func pauseCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "pause <task-id>",
-		Short: "Pause a running task",
-		Long: `Pause a running task, preserving its worktree state.
+// pauseCmd pauses a running task
+func pauseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pause <task-id>",
+		Short: "Pause a running task",
+		Long: `Pause a running task, preserving its worktree state.
+
+The task must be in 'in_progress' or 'claimed' status to be paused.
+Pausing a task will:
+  - Stop the task's execution
+  - Preserve the worktree state
+  - Keep any changes made so far
+  - Allow manual intervention in the worktree
+
+Use 'drover resume-task' to continue the task from where it left off.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+
+			// Get task details first
+			task, err := store.GetTask(taskID)
+			if err != nil {
+				return fmt.Errorf("task not found: %s", taskID)
+			}
+
+			// Pause the task
+			if err := store.PauseTask(taskID); err != nil {
+				return fmt.Errorf("pausing task: %w", err)
+			}
+
+			// Record paused event
+			eventID := uuid.New().String()
+			timestamp := time.Now().Unix()
+			_ = store.RecordEvent(eventID, string(events.EventTaskPaused), timestamp, taskID, task.EpicID, "")
+
+			output.Printf("⏸️  Paused task %s\n", taskID)
+			output.Printf("   %s\n", task.Title)
+			output.Printf("\nWorktree state preserved. Use 'drover resume-task %s' to continue.\n", taskID)
+
+			return nil
+		},
+	}
+}
+
+// resumeCmdForTask resumes a paused task
+func resumeCmdForTask() *cobra.Command {
+	var hint string
+
+	command := &cobra.Command{
+		Use:   "resume-task <task-id>",
+		Short: "Resume a paused task",
+		Long: `Resume a paused task, continuing from where it left off.
+
+The task must be in 'paused' status to be resumed.
+Optionally provide guidance/hints that will be injected when the task continues.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+
+			// Get task details first
+			task, err := store.GetTask(taskID)
+			if err != nil {
+				return fmt.Errorf("task not found: %s", taskID)
+			}
+
+			// Add hint if provided
+			if hint != "" {
+				_, err := store.AddGuidance(taskID, hint)
+				if err != nil {
+					return fmt.Errorf("adding guidance: %w", err)
+				}
+				output.Printf("💡 Added guidance to task %s\n", taskID)
+			}
+
+			// Resume the task
+			if err := store.ResumeTask(taskID); err != nil {
+				return fmt.Errorf("resuming task: %w", err)
+			}
+
+			// Record resumed event
+			eventID := uuid.New().String()
+			timestamp := time.Now().Unix()
+			var dataJSON string
+			if hint != "" {
+				data, _ := json.Marshal(map[string]any{"hint": hint})
+				dataJSON = string(data)
+			}
+			_ = store.RecordEvent(eventID, string(events.EventTaskResumed), timestamp, taskID, task.EpicID, dataJSON)
+
+			output.Printf("▶️  Resumed task %s\n", taskID)
+			output.Printf("   %s\n", task.Title)
+			if hint != "" {
+				output.Println("\nGuidance will be injected when the task is claimed.")
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&hint, "hint", "H", "", "Guidance message to inject when task resumes")
+	return command
+}
+
+// hintCmd adds guidance to a task's queue
+func hintCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hint <task-id> <message>",
+		Short: "Send guidance to a running task",
+		Long: `Send guidance or hints to a running task.
+
+The guidance will be queued and injected at the next safe point
+during task execution. This allows you to steer the AI without
+interrupting its work.
+
+With process-isolated workers (DROVER_USE_WORKER_SUBPROCESS=true) the
+running agent is handed the guidance within a few seconds, once it's done
+with what it's doing; other agents get it on the task's next attempt.
+
+Example:
+  drover hint task-123 "Try using the existing auth middleware"`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+			message := strings.Join(args[1:], " ")
+
+			// Get task details first
+			task, err := store.GetTask(taskID)
+			if err != nil {
+				return fmt.Errorf("task not found: %s", taskID)
+			}
+
+			// Add guidance
+			guidance, err := store.AddGuidance(taskID, message)
+			if err != nil {
+				return fmt.Errorf("adding guidance: %w", err)
+			}
+
+			output.Printf("💡 Guidance queued for %s\n", taskID)
+			output.Printf("   Task: %s\n", task.Title)
+			output.Printf("   Message: %s\n", message)
+			output.Printf("   ID: %s\n", guidance.ID)
+
+			return nil
+		},
+	}
+}
+
+// commentCmd adds a human note to a task's activity timeline
+func commentCmd() *cobra.Command {
+	var author string
+
+	command := &cobra.Command{
+		Use:   "comment <task-id> <message>",
+		Short: "Add a note to a task's activity timeline",
+		Long: `Add a note to a task's activity timeline.
+
+Comments are for people reading the task's history; unlike hints they are
+not sent to the agent. View them with 'drover activity <task-id>'.
+
+Example:
+  drover comment task-123 "Blocked on the staging credentials, asked ops"`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+			if _, err := store.GetTask(taskID); err != nil {
+				return fmt.Errorf("task not found: %s", taskID)
+			}
+
+			if author == "" {
+				author = config.GetOperator()
+			}
+			if _, err := store.AddComment(taskID, author, strings.Join(args[1:], " ")); err != nil {
+				return fmt.Errorf("adding comment: %w", err)
+			}
+
+			output.Printf("💬 Comment added to %s\n", taskID)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&author, "author", "", "Comment author (default: current operator)")
+	return command
+}
+
+// activityCmd shows a task's activity timeline
+func activityCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "activity <task-id>",
+		Short: "Show a task's activity timeline",
+		Long: `Show everything that happened to a task, oldest first: status changes,
+agent verdicts, guidance sent to the agent and comments.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+			task, err := store.GetTask(taskID)
+			if err != nil {
+				return fmt.Errorf("task not found: %s", taskID)
+			}
+
+			activity, err := store.ListActivity(taskID)
+			if err != nil {
+				return fmt.Errorf("listing activity: %w", err)
+			}
+
+			output.Printf("🕘 %s: %s\n\n", taskID, task.Title)
+			if len(activity) == 0 {
+				output.Println("No activity recorded yet")
+				return nil
+			}
+			for _, a := range activity {
+				author := ""
+				if a.Author != "" {
+					author = " (" + a.Author + ")"
+				}
+				output.Printf("  %s  %-8s %s%s\n", formatTimestamp(a.CreatedAt), a.Kind, a.Body, author)
+			}
+			return nil
+		},
+	}
+}
+
+// assignCmd sets the operator overseeing a task
+func assignCmd() *cobra.Command {
+	var unassign bool
+
+	command := &cobra.Command{
+		Use:   "assign <task-id> [operator]",
+		Short: "Assign the operator overseeing a task",
+		Long: `Assign which human or bot is overseeing a task.
+
+The operator defaults to the current one (see 'drover operator login').
+Use --clear to unassign. List an operator's tasks with 'drover status --operator <name>'.
+
+Example:
+  drover assign task-123 alice
+  drover assign task-123 --clear`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+			operator := ""
+			if !unassign {
+				if len(args) == 2 {
+					operator = args[1]
+				} else {
+					operator = config.GetOperator()
+				}
+				if operator == "" {
+					return fmt.Errorf("no operator given and none logged in; pass one or use --clear")
+				}
+			}
+
+			if err := store.AssignOperator(taskID, operator); err != nil {
+				return err
+			}
+
+			if operator == "" {
+				output.Printf("👤 %s is no longer assigned to an operator\n", taskID)
+			} else {
+				output.Printf("👤 %s assigned to %s\n", taskID, operator)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&unassign, "clear", false, "Unassign the task")
+	return command
+}
+
+// mutexCmd sets or clears the key that keeps a task from running alongside
+// the others that share it
+func mutexCmd() *cobra.Command {
+	var unset bool
+
+	command := &cobra.Command{
+		Use:   "mutex <task-id> [key]",
+		Short: "Keep a task from running alongside others with the same key",
+		Long: `Set the mutex key of a task. Of the tasks sharing a key, only one is in
+flight at a time; the others wait in the queue until it finishes, while
+tasks with other keys or none keep running. Use it for tasks that must not
+run in parallel, e.g. two that both edit schema.sql.
+
+Without a key, prints the task's current one. Use --clear to remove it.
+
+Example:
+  drover mutex task-123 schema.sql
+  drover mutex task-123 --clear`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			task, err := store.GetTask(args[0])
+			if err != nil {
+				return err
+			}
+			if task.ParentID != "" {
+				return fmt.Errorf("%s is a sub-task, which runs under its parent's mutex key", task.ID)
+			}
+			if len(args) == 1 && !unset {
+				if task.MutexKey == "" {
+					output.Printf("🔓 %s has no mutex key\n", task.ID)
+				} else {
+					output.Printf("🔒 %s: %s\n", task.ID, task.MutexKey)
+				}
+				return nil
+			}
+
+			key := ""
+			if !unset {
+				key = strings.TrimSpace(args[1])
+				if key == "" {
+					return fmt.Errorf("empty mutex key; use --clear to remove it")
+				}
+			}
+			if err := store.SetTaskMutexKey(task.ID, key); err != nil {
+				return err
+			}
+			if key == "" {
+				output.Printf("🔓 %s no longer has a mutex key\n", task.ID)
+			} else {
+				output.Printf("🔒 %s won't run alongside other tasks keyed %s\n", task.ID, key)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&unset, "clear", false, "Remove the task's mutex key")
+	return command
+}
+
+// envCmd sets, removes or lists the environment variables a task's agent
+// runs with
+func envCmd() *cobra.Command {
+	var unset []string
+	var clear bool
 
-The task must be in 'in_progress' or 'claimed' status to be paused.
-Pausing a task will:
-  - Stop the task's execution
-  - Preserve the worktree state
-  - Keep any changes made so far
-  - Allow manual intervention in the worktree
+	command := &cobra.Command{
+		Use:   "env <task-id> [KEY=VALUE...]",
+		Short: "Set environment variables for a task's agent",
+		Long: `Set environment variables the task's agent runs with, e.g. feature flags,
+API base URLs or a DATABASE_URL for integration tests. Drover's tests and
+verify commands for the task see them too. They override the project-wide
+defaults under [env] in .drover.toml; a sub-task runs with its parent's
+variables and its own on top.
+
+Without variables, lists the task's current ones. Values of secret-looking
+variables (tokens, passwords, API keys, URL credentials) are redacted when
+shown, in run logs and in saved agent output.
 
-Use 'drover resume' to continue the task from where it left off.`,
-		Args: cobra.ExactArgs(1),
+Example:
+  drover env task-123 FEATURE_X=on API_BASE_URL=http://localhost:8080
+  drover env task-123 --unset FEATURE_X
+  drover env task-123 --clear`,
+		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			_, store, err := requireProject()
 			if err != nil {
@@ -1140,44 +2358,69 @@ Use 'drover resume' to continue the task from where it left off.`,
 			}
 			defer store.Close()
 
-			taskID := args[0]
-
-			// Get task details first
-			task, err := store.GetTask(taskID)
+			task, err := store.GetTask(args[0])
 			if err != nil {
-				return fmt.Errorf("task not found: %s", taskID)
+				return err
 			}
-
-			// Pause the task
-			if err := store.PauseTask(taskID); err != nil {
-				return fmt.Errorf("pausing task: %w", err)
+			set, err := workflow.ParseEnv(args[1:])
+			if err != nil {
+				return err
+			}
+			if len(set) == 0 && len(unset) == 0 && !clear {
+				printTaskEnv(task)
+				return nil
 			}
 
-			// Record paused event
-			eventID := uuid.New().String()
-			timestamp := time.Now().Unix()
-			_ = store.RecordEvent(eventID, string(events.EventTaskPaused), timestamp, taskID, task.EpicID, "")
-
-			fmt.Printf("⏸️  Paused task %s\n", taskID)
-			fmt.Printf("   %s\n", task.Title)
-			fmt.Println("\nWorktree state preserved. Use 'drover resume' to continue.")
-
+			env := task.Env
+			if clear || env == nil {
+				env = make(map[string]string)
+			}
+			for _, name := range unset {
+				delete(env, name)
+			}
+			for name, value := range set {
+				env[name] = value
+			}
+			if err := store.SetTaskEnv(task.ID, env); err != nil {
+				return err
+			}
+			task.Env = env
+			printTaskEnv(task)
 			return nil
 		},
 	}
+
+	command.Flags().StringSliceVar(&unset, "unset", nil, "Variables to remove")
+	command.Flags().BoolVar(&clear, "clear", false, "Remove all of the task's variables first")
+	return command
 }
 
-// resumeCmdForTask resumes a paused task
-func resumeCmdForTask() *cobra.Command {
-	var hint string
+// printTaskEnv lists a task's environment variables, secrets redacted
+func printTaskEnv(task *types.Task) {
+	if len(task.Env) == 0 {
+		output.Printf("%s has no environment variables of its own\n", task.ID)
+		return
+	}
+	names := make([]string, 0, len(task.Env))
+	for name := range task.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	output.Printf("🔧 %s:\n", task.ID)
+	for _, name := range names {
+		output.Printf("   %s=%s\n", name, workflow.RedactEnvValue(name, task.Env[name]))
+	}
+}
 
-	command := &cobra.Command{
-		Use:   "resume-task <task-id>",
-		Short: "Resume a paused task",
-		Long: `Resume a paused task, continuing from where it left off.
+// auditCmd shows the append-only audit log of a task's status transitions
+func auditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "audit <task-id>",
+		Short: "Show the audit log of a task's status transitions",
+		Long: `Show every status transition recorded for a task, oldest first, with who
+made it and the error or reason recorded alongside.
 
-The task must be in 'paused' status to be resumed.
-Optionally provide guidance/hints that will be injected when the task continues.`,
+The audit log is append-only and is kept even after the task is deleted.`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			_, store, err := requireProject()
@@ -1187,65 +2430,51 @@ Optionally provide guidance/hints that will be injected when the task continues.
 			defer store.Close()
 
 			taskID := args[0]
-
-			// Get task details first
-			task, err := store.GetTask(taskID)
+			events, err := store.ListAudit(taskID)
 			if err != nil {
-				return fmt.Errorf("task not found: %s", taskID)
-			}
-
-			// Add hint if provided
-			if hint != "" {
-				_, err := store.AddGuidance(taskID, hint)
-				if err != nil {
-					return fmt.Errorf("adding guidance: %w", err)
-				}
-				fmt.Printf("💡 Added guidance to task %s\n", taskID)
-			}
-
-			// Resume the task
-			if err := store.ResumeTask(taskID); err != nil {
-				return fmt.Errorf("resuming task: %w", err)
+				return fmt.Errorf("listing audit log: %w", err)
 			}
-
-			// Record resumed event
-			eventID := uuid.New().String()
-			timestamp := time.Now().Unix()
-			var dataJSON string
-			if hint != "" {
-				data, _ := json.Marshal(map[string]any{"hint": hint})
-				dataJSON = string(data)
+			if len(events) == 0 {
+				output.Printf("No audit events recorded for %s\n", taskID)
+				return nil
 			}
-			_ = store.RecordEvent(eventID, string(events.EventTaskResumed), timestamp, taskID, task.EpicID, dataJSON)
 
-			fmt.Printf("▶️  Resumed task %s\n", taskID)
-			fmt.Printf("   %s\n", task.Title)
-			if hint != "" {
-				fmt.Println("\nGuidance will be injected when the task is claimed.")
+			output.Printf("📜 Audit log for %s (%d events)\n\n", taskID, len(events))
+			for _, e := range events {
+				output.Printf("  %s  %-12s %s → %s\n", formatTimestamp(e.CreatedAt), e.Actor, e.From, e.To)
+				if e.Error != "" {
+					output.Printf("      %s\n", e.Error)
+				}
 			}
-
 			return nil
 		},
 	}
-
-	command.Flags().StringVarP(&hint, "hint", "H", "", "Guidance message to inject when task resumes")
-	return command
 }
 
-// hintCmd adds guidance to a task's queue
-func hintCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "hint <task-id> <message>",
-		Short: "Send guidance to a running task",
-		Long: `Send guidance or hints to a running task.
+// gcCmd archives finished tasks and purges old archived ones
+func gcCmd() *cobra.Command {
+	var days int
+	var purgeDays int
 
-The guidance will be queued and injected at the next safe point
-during task execution. This allows you to steer the AI without
-interrupting its work.
+	command := &cobra.Command{
+		Use:   "gc",
+		Short: "Archive finished tasks and purge old archives",
+		Long: `Move tasks that finished (completed, failed or cancelled) long ago out of the
+live tables into archive tables, keeping the queries run on every poll fast.
+
+A task's dependencies, activity, attempts and events are archived with it;
+its checkpoint, guidance, worktree record, plans and conversations are deleted.
+Sub-task trees are archived whole once every task in them has finished, and
+tasks an unfinished task still depends on are kept. The audit log is never
+touched.
+
+Archived tasks are deleted for good after --purge-days (0 keeps them forever).
+Defaults come from DROVER_ARCHIVE_RETENTION_DAYS (30) and
+DROVER_ARCHIVE_PURGE_DAYS (0).
 
 Example:
-  drover hint task-123 "Try using the existing auth middleware"`,
-		Args: cobra.MinimumNArgs(2),
+  drover gc
+  drover gc --days 7 --purge-days 90`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			_, store, err := requireProject()
 			if err != nil {
@@ -1253,29 +2482,45 @@ Example:
 			}
 			defer store.Close()
 
-			taskID := args[0]
-			message := strings.Join(args[1:], " ")
-
-			// Get task details first
-			task, err := store.GetTask(taskID)
-			if err != nil {
-				return fmt.Errorf("task not found: %s", taskID)
+			if !cmd.Flags().Changed("days") {
+				days = cfg.ArchiveRetentionDays
+			}
+			if !cmd.Flags().Changed("purge-days") {
+				purgeDays = cfg.ArchivePurgeDays
+			}
+			if days < 0 || purgeDays < 0 {
+				return fmt.Errorf("retention days must not be negative")
 			}
 
-			// Add guidance
-			guidance, err := store.AddGuidance(taskID, message)
+			day := 24 * time.Hour
+			archived, err := store.ArchiveTasks(time.Duration(days) * day)
 			if err != nil {
-				return fmt.Errorf("adding guidance: %w", err)
+				return fmt.Errorf("archiving tasks: %w", err)
 			}
+			output.Printf("🗄️  Archived %d tasks finished more than %d days ago (%d activity entries, %d attempts, %d events)\n",
+				archived.Tasks, days, archived.Activity, archived.Attempts, archived.Events)
 
-			fmt.Printf("💡 Guidance queued for %s\n", taskID)
-			fmt.Printf("   Task: %s\n", task.Title)
-			fmt.Printf("   Message: %s\n", message)
-			fmt.Printf("   ID: %s\n", guidance.ID)
+			if purgeDays > 0 {
+				purged, err := store.PurgeArchive(time.Duration(purgeDays) * day)
+				if err != nil {
+					return fmt.Errorf("purging archive: %w", err)
+				}
+				output.Printf("🗑️  Purged %d tasks archived more than %d days ago (%d activity entries, %d attempts, %d events)\n",
+					purged.Tasks, purgeDays, purged.Activity, purged.Attempts, purged.Events)
+			}
 
+			live, total, err := store.ArchiveStats()
+			if err != nil {
+				return fmt.Errorf("counting tasks: %w", err)
+			}
+			output.Printf("\n%d live tasks, %d archived\n", live, total)
 			return nil
 		},
 	}
+
+	command.Flags().IntVar(&days, "days", 30, "Archive tasks finished more than this many days ago")
+	command.Flags().IntVar(&purgeDays, "purge-days", 0, "Delete archived tasks older than this many days (0 = keep forever)")
+	return command
 }
 
 // importCmd imports a session from an export file
@@ -1320,10 +2565,10 @@ Examples:
 				return fmt.Errorf("unsupported session version: %s (expected 1.0)", session.Version)
 			}
 
-			fmt.Printf("📦 Importing session from %s\n", importFile)
-			fmt.Printf("   Repository: %s\n", session.Repository)
-			fmt.Printf("   Exported: %s\n", session.ExportedAt)
-			fmt.Printf("   Epics: %d, Tasks: %d, Dependencies: %d\n",
+			output.Printf("📦 Importing session from %s\n", importFile)
+			output.Printf("   Repository: %s\n", session.Repository)
+			output.Printf("   Exported: %s\n", session.ExportedAt)
+			output.Printf("   Epics: %d, Tasks: %d, Dependencies: %d\n",
 				len(session.Epics), len(session.Tasks), len(session.Dependencies))
 
 			// Import the session
@@ -1331,10 +2576,10 @@ Examples:
 				return fmt.Errorf("importing session: %w", err)
 			}
 
-			fmt.Println("\n✅ Session imported successfully")
+			output.Println("\n✅ Session imported successfully")
 
 			if continueExecution {
-				fmt.Println("\n▶️  Starting execution...")
+				output.Println("\n▶️  Starting execution...")
 				// Create a new orchestrator and run
 				runCfg, err := config.Load()
 				if err != nil {
@@ -1354,6 +2599,7 @@ Examples:
 	}
 
 	command.Flags().BoolVarP(&continueExecution, "continue", "c", false, "Continue execution after import")
+	command.AddCommand(importBeadsCmd())
 	return command
 }
 
@@ -1403,16 +2649,121 @@ Example:
 				return fmt.Errorf("worktree not found at %s (it may have been cleaned up)", worktreePath)
 			}
 
-			fmt.Printf("📁 Worktree path: %s\n", worktreePath)
-			fmt.Printf("\nTask: %s\n", task.Title)
-			fmt.Println("\nYou can now:")
-			fmt.Printf("  cd %s\n", worktreePath)
-			fmt.Println("  # Make your manual edits...")
-			fmt.Println("  drover resume-task", taskID)
+			output.Printf("📁 Worktree path: %s\n", worktreePath)
+			output.Printf("\nTask: %s\n", task.Title)
+			output.Println("\nYou can now:")
+			output.Printf("  cd %s\n", worktreePath)
+			output.Println("  # Make your manual edits...")
+			output.Println("  drover resume-task", taskID)
+
+			return nil
+		},
+	}
+}
+
+// scheduleCmd sets or clears a task's not-before and due dates
+func scheduleCmd() *cobra.Command {
+	var (
+		notBefore  string
+		due        string
+		clearDates bool
+	)
+
+	command := &cobra.Command{
+		Use:   "schedule <task-id>",
+		Short: "Set when a task may start and when it is due",
+		Long: `Set when a task may start and when it is due.
+
+Workers don't claim a task before its not-before time, so tasks can be staged
+for later; unfinished tasks past their due date are reported as overdue by
+'drover status' and during runs. Times are a date ("2026-03-01"), a date and
+time ("2026-03-01 14:00", RFC 3339) or an offset from now ("36h", "2d").
+
+Examples:
+  drover schedule task-123 --not-before 2026-03-01
+  drover schedule task-123 --due 2d
+  drover schedule task-123 --clear`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			task, err := store.GetTask(args[0])
+			if err != nil {
+				return fmt.Errorf("task not found: %s", args[0])
+			}
+
+			var scheduledAt, dueAt time.Time
+			if !clearDates {
+				// Keep the date that isn't being changed
+				if task.ScheduledAt != nil {
+					scheduledAt = time.Unix(*task.ScheduledAt, 0)
+				}
+				if task.DueAt != nil {
+					dueAt = time.Unix(*task.DueAt, 0)
+				}
+				if cmd.Flags().Changed("not-before") {
+					if scheduledAt, err = parseScheduleTime(notBefore); err != nil {
+						return fmt.Errorf("--not-before: %w", err)
+					}
+				}
+				if cmd.Flags().Changed("due") {
+					if dueAt, err = parseScheduleTime(due); err != nil {
+						return fmt.Errorf("--due: %w", err)
+					}
+				}
+			}
 
+			if err := store.SetTaskSchedule(task.ID, scheduledAt, dueAt); err != nil {
+				return err
+			}
+			output.Printf("⏰ Task %s: not before %s, due %s\n", task.ID, formatScheduleTime(scheduledAt), formatScheduleTime(dueAt))
 			return nil
 		},
 	}
+
+	command.Flags().StringVar(&notBefore, "not-before", "", "Don't start the task before this time (empty clears it)")
+	command.Flags().StringVar(&due, "due", "", "Deadline after which the task is reported overdue (empty clears it)")
+	command.Flags().BoolVar(&clearDates, "clear", false, "Clear both dates")
+	return command
+}
+
+// parseScheduleTime parses a --not-before or --due value: a date, a date and
+// time in local time, RFC 3339, or an offset from now such as "36h" or "2d".
+// An empty value is the zero time
+func parseScheduleTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return time.Now().AddDate(0, 0, n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use 2026-03-01, \"2026-03-01 14:00\", RFC 3339 or an offset like 36h or 2d)", value)
+}
+
+// formatScheduleTime formats a schedule date for display, "-" when unset
+func formatScheduleTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04")
 }
 
 // shareCmd creates a shareable session link
@@ -1483,16 +2834,16 @@ Examples:
 				return fmt.Errorf("creating share: %w", err)
 			}
 
-			fmt.Printf("🔗 Shareable session link created!\n\n")
-			fmt.Printf("Token: %s\n", share.Token)
+			output.Printf("🔗 Shareable session link created!\n\n")
+			output.Printf("Token: %s\n", share.Token)
 			if share.ExpiresAt != nil {
 				expiresAt := time.Unix(*share.ExpiresAt, 0)
-				fmt.Printf("Expires: %s\n", expiresAt.Format(time.RFC1123))
+				output.Printf("Expires: %s\n", expiresAt.Format(time.RFC1123))
 			} else {
-				fmt.Printf("Expires: never\n")
+				output.Printf("Expires: never\n")
 			}
-			fmt.Printf("\nShare this token with other operators. They can import the session with:\n")
-			fmt.Printf("  drover import-share %s\n", share.Token)
+			output.Printf("\nShare this token with other operators. They can import the session with:\n")
+			output.Printf("  drover import-share %s\n", share.Token)
 
 			return nil
 		},
@@ -1549,11 +2900,11 @@ Examples:
 				return fmt.Errorf("unsupported session version: %s (expected 1.0)", session.Version)
 			}
 
-			fmt.Printf("📦 Importing shared session\n")
-			fmt.Printf("   Created by: %s\n", share.CreatedBy)
-			fmt.Printf("   Repository: %s\n", session.Repository)
-			fmt.Printf("   Exported: %s\n", session.ExportedAt)
-			fmt.Printf("   Epics: %d, Tasks: %d, Dependencies: %d\n",
+			output.Printf("📦 Importing shared session\n")
+			output.Printf("   Created by: %s\n", share.CreatedBy)
+			output.Printf("   Repository: %s\n", session.Repository)
+			output.Printf("   Exported: %s\n", session.ExportedAt)
+			output.Printf("   Epics: %d, Tasks: %d, Dependencies: %d\n",
 				len(session.Epics), len(session.Tasks), len(session.Dependencies))
 
 			// Import the session
@@ -1561,10 +2912,10 @@ Examples:
 				return fmt.Errorf("importing session: %w", err)
 			}
 
-			fmt.Println("\n✅ Session imported successfully")
+			output.Println("\n✅ Session imported successfully")
 
 			if continueExecution {
-				fmt.Println("\n▶️  Starting execution...")
+				output.Println("\n▶️  Starting execution...")
 				runCfg, err := config.Load()
 				if err != nil {
 					return fmt.Errorf("loading config: %w", err)
@@ -1639,11 +2990,11 @@ Example:
 				return fmt.Errorf("creating operator: %w", err)
 			}
 
-			fmt.Printf("✅ Operator created successfully!\n\n")
-			fmt.Printf("Name: %s\n", op.Name)
-			fmt.Printf("API Key: %s\n\n", op.APIKey)
-			fmt.Printf("Save this API key securely. You'll need it to authenticate as this operator.\n")
-			fmt.Printf("Use it with: export DROVER_API_KEY=%s\n", op.APIKey)
+			output.Printf("✅ Operator created successfully!\n\n")
+			output.Printf("Name: %s\n", op.Name)
+			output.Printf("API Key: %s\n\n", op.APIKey)
+			output.Printf("Save this API key securely. You'll need it to authenticate as this operator.\n")
+			output.Printf("Use it with: export DROVER_API_KEY=%s\n", op.APIKey)
 
 			return nil
 		},
@@ -1673,18 +3024,18 @@ Example:
 			}
 
 			if len(operators) == 0 {
-				fmt.Println("No operators found. Create one with: drover operator create <name>")
+				output.Println("No operators found. Create one with: drover operator create <name>")
 				return nil
 			}
 
-			fmt.Printf("Operators (%d):\n\n", len(operators))
+			output.Printf("Operators (%d):\n\n", len(operators))
 			for _, op := range operators {
-				fmt.Printf("  • %s\n", op.Name)
+				output.Printf("  • %s\n", op.Name)
 				if op.LastActive != nil {
 					lastActive := time.Unix(*op.LastActive, 0)
-					fmt.Printf("    Last active: %s\n", lastActive.Format(time.RFC1123))
+					output.Printf("    Last active: %s\n", lastActive.Format(time.RFC1123))
 				} else {
-					fmt.Printf("    Last active: never\n")
+					output.Printf("    Last active: never\n")
 				}
 			}
 
@@ -1725,7 +3076,7 @@ Example:
 				return fmt.Errorf("deleting operator: %w", err)
 			}
 
-			fmt.Printf("✅ Operator '%s' deleted successfully\n", name)
+			output.Printf("✅ Operator '%s' deleted successfully\n", name)
 
 			return nil
 		},
@@ -1753,7 +3104,7 @@ Example:
 				return fmt.Errorf("setting operator: %w", err)
 			}
 
-			fmt.Printf("✅ Logged in as '%s'\n", name)
+			output.Printf("✅ Logged in as '%s'\n", name)
 
 			return nil
 		},
@@ -1763,7 +3114,7 @@ Example:
 // runWatchMode continuously updates the status display
 func runWatchMode(store *db.Store) error {
 	// Clear screen on start
-	fmt.Print("\033[H\033[2J")
+	output.Print("\033[H\033[2J")
 
 	// Set up signal handling for graceful exit
 	sigChan := make(chan os.Signal, 1)
@@ -1779,40 +3130,40 @@ func runWatchMode(store *db.Store) error {
 		select {
 		case <-sigChan:
 			// User pressed Ctrl+C
-			fmt.Println("\n\n👋 Watch mode stopped")
+			output.Println("\n\n👋 Watch mode stopped")
 			return nil
 
 		case <-ticker.C:
 			// Get fresh status
 			status, err := store.GetProjectStatus()
 			if err != nil {
-				fmt.Printf("\nError getting status: %v\n", err)
+				output.Printf("\nError getting status: %v\n", err)
 				return err
 			}
 
 			// Only update if something changed
 			if lastStatus == nil || statusChanged(lastStatus, status) {
 				// Clear screen and move cursor to top-left
-				fmt.Print("\033[H\033[2J")
+				output.Print("\033[H\033[2J")
 
 				// Print header with timestamp
-				fmt.Printf("🐂 Drover Status (watch mode - %s)\n", time.Now().Format("15:04:05"))
-				fmt.Println("════════════════════════════════════════")
-				fmt.Printf("\nTotal:      %d\n", status.Total)
-				fmt.Printf("Ready:      %d\n", status.Ready)
-				fmt.Printf("In Progress: %d\n", status.InProgress)
-				fmt.Printf("Paused:     %d\n", status.Paused)
-				fmt.Printf("Completed:  %d\n", status.Completed)
-				fmt.Printf("Failed:     %d\n", status.Failed)
-				fmt.Printf("Blocked:    %d\n", status.Blocked)
+				output.Printf("🐂 Drover Status (watch mode - %s)\n", time.Now().Format("15:04:05"))
+				output.Println("════════════════════════════════════════")
+				output.Printf("\nTotal:      %d\n", status.Total)
+				output.Printf("Ready:      %d\n", status.Ready)
+				output.Printf("In Progress: %d\n", status.InProgress)
+				output.Printf("Paused:     %d\n", status.Paused)
+				output.Printf("Completed:  %d\n", status.Completed)
+				output.Printf("Failed:     %d\n", status.Failed)
+				output.Printf("Blocked:    %d\n", status.Blocked)
 
 				if status.Total > 0 {
 					progress := float64(status.Completed) / float64(status.Total) * 100
-					fmt.Printf("\nProgress: %.1f%%\n", progress)
+					output.Printf("\nProgress: %.1f%%\n", progress)
 					printProgressBar(progress)
 				}
 
-				fmt.Println("\nPress Ctrl+C to exit")
+				output.Println("\nPress Ctrl+C to exit")
 
 				lastStatus = status
 			}
@@ -1832,28 +3183,62 @@ func statusChanged(old, new *db.ProjectStatus) bool {
 }
 
 func printStatus(status *db.ProjectStatus) {
-	fmt.Println("\n🐂 Drover Status")
-	fmt.Println("════════════════")
-	fmt.Printf("\nTotal:      %d\n", status.Total)
-	fmt.Printf("Ready:      %d\n", status.Ready)
-	fmt.Printf("In Progress: %d\n", status.InProgress)
-	fmt.Printf("Paused:     %d\n", status.Paused)
-	fmt.Printf("Completed:  %d\n", status.Completed)
-	fmt.Printf("Failed:     %d\n", status.Failed)
-	fmt.Printf("Blocked:    %d\n", status.Blocked)
+	output.Println("\n🐂 Drover Status")
+	output.Println("════════════════")
+	output.Printf("\nTotal:      %d\n", status.Total)
+	output.Printf("Ready:      %d\n", status.Ready)
+	output.Printf("In Progress: %d\n", status.InProgress)
+	output.Printf("Paused:     %d\n", status.Paused)
+	output.Printf("Completed:  %d\n", status.Completed)
+	output.Printf("Failed:     %d\n", status.Failed)
+	if status.Failed > 0 {
+		output.Printf("  ☠️  %d in the dead-letter queue (%d out of attempts): drover dlq\n", status.Failed, status.Exhausted)
+	}
+	output.Printf("Blocked:    %d\n", status.Blocked)
+	if status.Scheduled > 0 {
+		output.Printf("Scheduled:  %d\n", status.Scheduled)
+	}
+	if status.Overdue > 0 {
+		output.Printf("Overdue:    %d\n", status.Overdue)
+	}
 
 	if status.Total > 0 {
 		progress := float64(status.Completed) / float64(status.Total) * 100
-		fmt.Printf("\nProgress: %.1f%%\n", progress)
+		output.Printf("\nProgress: %.1f%%\n", progress)
 		printProgressBar(progress)
 	}
+
+	if !status.Usage.IsZero() {
+		output.Printf("\nSpent:      %s\n", status.Usage)
+	}
+
+	if len(status.Epics) > 0 {
+		output.Println("\nEpics:")
+		for _, epic := range status.Epics {
+			printEpicProgress(epic, 0)
+		}
+	}
+}
+
+// printEpicProgress prints an epic's rolled-up progress, then its sub-epics indented below it
+func printEpicProgress(epic *db.EpicProgress, depth int) {
+	output.Printf("  %s%s %s  %d/%d done (%.0f%%)  %s",
+		strings.Repeat("  ", depth), epic.Epic.ID, epic.Epic.Title,
+		epic.Completed, epic.Total, epic.Progress(), epic.Status)
+	if !epic.Usage.IsZero() {
+		output.Printf("  $%.2f", epic.Usage.CostUSD)
+	}
+	output.Println()
+	for _, child := range epic.Children {
+		printEpicProgress(child, depth+1)
+	}
 }
 
 // printStatusOneline prints a single-line status summary
 // Format: "X running, Y queued, Z completed, W blocked"
 // Useful for shell prompt integration
 func printStatusOneline(status *db.ProjectStatus) {
-	fmt.Printf("%d running, %d queued, %d completed, %d blocked",
+	output.Printf("%d running, %d queued, %d completed, %d blocked",
 		status.InProgress, status.Ready, status.Completed, status.Blocked)
 }
 
@@ -1867,15 +3252,15 @@ func printProgressBar(percent float64) {
 	width := 40
 	filled := int(percent / 100 * float64(width))
 
-	fmt.Print("[")
+	output.Print("[")
 	for i := 0; i < width; i++ {
 		if i < filled {
-			fmt.Print("█")
+			output.Print("█")
 		} else {
-			fmt.Print("░")
+			output.Print("░")
 		}
 	}
-	fmt.Printf("] %.1f%%\n", percent)
+	output.Printf("] %.1f%%\n", percent)
 }
 
 // printProgressBarCompact prints a shorter progress bar
@@ -1883,21 +3268,21 @@ func printProgressBarCompact(percent float64) {
 	width := 20
 	filled := int(percent / 100 * float64(width))
 
-	fmt.Print("[")
+	output.Print("[")
 	for i := 0; i < width; i++ {
 		if i < filled {
-			fmt.Print("█")
+			output.Print("█")
 		} else {
-			fmt.Print("░")
+			output.Print("░")
 		}
 	}
-	fmt.Printf("] %.1f%%\n", percent)
+	output.Printf("] %.1f%%\n", percent)
 }
 
 // printTreeStatus displays tasks in a hierarchical tree view
 func printTreeStatus(store *db.Store) error {
-	fmt.Println("\n🐂 Drover Task Tree")
-	fmt.Println("════════════════════")
+	output.Println("\n🐂 Drover Task Tree")
+	output.Println("════════════════════")
 
 	// Get all tasks
 	tasks, err := store.ListTasks()
@@ -1947,7 +3332,7 @@ func printTaskNode(task *types.Task, subTasks map[string][]*types.Task, prefix s
 	if prefix == "" {
 		connector = ""
 	}
-	fmt.Printf("%s%s%s %s: %s\n", prefix, connector, icon, task.ID, task.Title)
+	output.Printf("%s%s%s %s: %s\n", prefix, connector, icon, task.ID, task.Title)
 
 	// Print children if any
 	children := subTasks[task.ID]
@@ -1966,62 +3351,122 @@ func printTaskNode(task *types.Task, subTasks map[string][]*types.Task, prefix s
 }
 
 func printTaskInfo(task *types.Task, blockedBy, blocking []string) {
-	fmt.Println("\n📋 Task Info")
-	fmt.Println("════════════")
+	output.Println("\n📋 Task Info")
+	output.Println("════════════")
 
-	fmt.Printf("\nID:         %s\n", task.ID)
-	fmt.Printf("Title:      %s\n", task.Title)
-	fmt.Printf("Status:     %s\n", formatTaskStatus(task.Status))
-	fmt.Printf("Priority:   %d\n", task.Priority)
+	output.Printf("\nID:         %s\n", task.ID)
+	output.Printf("Title:      %s\n", task.Title)
+	output.Printf("Status:     %s\n", formatTaskStatus(task.Status))
+	output.Printf("Priority:   %d\n", task.Priority)
 
 	if task.Description != "" {
-		fmt.Printf("\nDescription:\n")
-		fmt.Printf("  %s\n", task.Description)
+		output.Printf("\nDescription:\n")
+		output.Printf("  %s\n", task.Description)
 	}
 
 	if task.EpicID != "" {
-		fmt.Printf("\nEpic:       %s\n", task.EpicID)
+		output.Printf("\nEpic:       %s\n", task.EpicID)
 	}
 
 	// Timestamps
-	fmt.Printf("\nCreated:    %s\n", formatTimestamp(task.CreatedAt))
-	fmt.Printf("Updated:    %s\n", formatTimestamp(task.UpdatedAt))
+	output.Printf("\nCreated:    %s\n", formatTimestamp(task.CreatedAt))
+	output.Printf("Updated:    %s\n", formatTimestamp(task.UpdatedAt))
 
 	// Attempts
 	if task.Attempts > 0 {
-		fmt.Printf("Attempts:   %d / %d\n", task.Attempts, task.MaxAttempts)
+		output.Printf("Attempts:   %d / %d\n", task.Attempts, task.MaxAttempts)
+	}
+	if task.RetryPolicy != "" {
+		output.Printf("Retries:    %s\n", task.RetryPolicy)
+	}
+	if task.MutexKey != "" {
+		output.Printf("Mutex key:  %s\n", task.MutexKey)
+	}
+	if task.Repo != "" {
+		output.Printf("Repository: %s\n", task.Repo)
+	}
+	if len(task.Env) > 0 {
+		names := make([]string, 0, len(task.Env))
+		for name := range task.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			label := "Env:"
+			if i > 0 {
+				label = ""
+			}
+			output.Printf("%-12s%s=%s\n", label, name, workflow.RedactEnvValue(name, task.Env[name]))
+		}
+	}
+	// A retried task waits out its backoff before it can be claimed again
+	if task.ScheduledAt != nil && *task.ScheduledAt > time.Now().Unix() {
+		output.Printf("Not before: %s\n", formatTimestamp(*task.ScheduledAt))
+	}
+	if usage := db.TaskUsage(task); !usage.IsZero() {
+		output.Printf("Spent:      %s\n", usage)
 	}
 
 	// Claim info
 	if task.ClaimedBy != "" {
-		fmt.Printf("Claimed by: %s\n", task.ClaimedBy)
+		output.Printf("Claimed by: %s\n", task.ClaimedBy)
 		if task.ClaimedAt != nil {
-			fmt.Printf("Claimed at: %s\n", formatTimestamp(*task.ClaimedAt))
+			output.Printf("Claimed at: %s\n", formatTimestamp(*task.ClaimedAt))
 		}
 	}
 
 	// Error info
 	if task.LastError != "" {
-		fmt.Printf("\nLast Error:\n")
-		fmt.Printf("  %s\n", task.LastError)
+		output.Printf("\nLast Error:\n")
+		output.Printf("  %s\n", task.LastError)
 	}
 
 	// Dependencies
 	if len(blockedBy) > 0 {
-		fmt.Printf("\nBlocked by:\n")
+		output.Printf("\nBlocked by:\n")
 		for _, id := range blockedBy {
-			fmt.Printf("  • %s\n", id)
+			output.Printf("  • %s\n", id)
 		}
 	}
 
 	if len(blocking) > 0 {
-		fmt.Printf("\nBlocking:\n")
+		output.Printf("\nBlocking:\n")
 		for _, id := range blocking {
-			fmt.Printf("  • %s\n", id)
+			output.Printf("  • %s\n", id)
+		}
+	}
+}
+
+// printTaskAttempts lists a task's execution attempts, oldest first
+func printTaskAttempts(attempts []*types.TaskAttempt) {
+	if len(attempts) > 0 {
+		output.Printf("\nAttempt history:\n")
+	}
+	for _, a := range attempts {
+		outcome, took := "running", ""
+		if a.EndedAt != nil {
+			outcome = formatTaskStatus(a.Outcome)
+			took = fmt.Sprintf(" in %s", time.Duration(*a.EndedAt-a.StartedAt)*time.Second)
+		}
+		output.Printf("  #%d  %s  %s  %s%s\n", a.Number, formatTimestamp(a.StartedAt), a.WorkerID, outcome, took)
+		if a.Model != "" {
+			output.Printf("      Model:   %s\n", a.Model)
+		}
+		if a.Verdict != "" {
+			output.Printf("      Verdict: %s\n", a.Verdict)
+		}
+		if a.Error != "" {
+			output.Printf("      Error:   %s\n", a.Error)
+		}
+		if a.OutputPath != "" {
+			output.Printf("      Output:  %s\n", a.OutputPath)
+		}
+		if a.TranscriptSize > 0 {
+			output.Printf("      Logs:    drover logs %s --attempt %d\n", a.TaskID, a.Number)
 		}
 	}
 
-	fmt.Println()
+	output.Println()
 }
 
 func formatTaskStatus(status types.TaskStatus) string {
@@ -2103,43 +3548,57 @@ func worktreeListCmd() *cobra.Command {
 			}
 
 			if len(worktrees) == 0 && len(onDisk) == 0 {
-				fmt.Println("No worktrees found")
+				output.Println("No worktrees found")
 				return nil
 			}
 
-			fmt.Println("\n🌳 Worktrees")
-			fmt.Println("════════════")
+			output.Println("\n🌳 Worktrees")
+			output.Println("════════════")
 
 			// Print tracked worktrees
+			// Pooled worktrees live under their pool ID, so match on the recorded path
 			for _, w := range worktrees {
+				diskID := filepath.Base(w.Path)
 				onDiskIndicator := "✓"
-				if !onDiskMap[w.TaskID] {
+				if !onDiskMap[diskID] {
 					onDiskIndicator = "✗ (missing)"
+					if w.Status == git.WorktreeRemoved {
+						onDiskIndicator = "✗ (removed)"
+					}
 				}
 
-				fmt.Printf("\n%s %s\n", onDiskIndicator, w.TaskID)
-				fmt.Printf("  Status:    %s\n", w.Status)
-				fmt.Printf("  Path:      %s\n", w.Path)
+				output.Printf("\n%s %s\n", onDiskIndicator, w.TaskID)
+				output.Printf("  Status:    %s\n", w.Status)
+				output.Printf("  Path:      %s\n", w.Path)
+				if w.Branch != "" {
+					output.Printf("  Branch:    %s\n", w.Branch)
+				}
 				if w.TaskTitle != "" {
-					fmt.Printf("  Task:      %s\n", w.TaskTitle)
+					output.Printf("  Task:      %s\n", w.TaskTitle)
 				}
 				if w.TaskStatus != "" {
-					fmt.Printf("  Task Status: %s\n", w.TaskStatus)
+					output.Printf("  Task Status: %s\n", w.TaskStatus)
+				}
+				if w.SetupMs > 0 {
+					output.Printf("  Setup:     %s\n", time.Duration(w.SetupMs)*time.Millisecond)
 				}
 
-				// Get disk usage
-				if onDiskMap[w.TaskID] {
-					size, _ := gitMgr.GetDiskUsage(w.TaskID)
-					fmt.Printf("  Disk:      %s\n", formatBytes(size))
+				// Get disk usage (live if on disk, otherwise the last recorded size)
+				if !onDiskMap[diskID] && w.DiskSize > 0 {
+					output.Printf("  Disk:      %s (last recorded)\n", formatBytes(w.DiskSize))
+				}
+				if onDiskMap[diskID] {
+					size, _ := gitMgr.GetDiskUsage(diskID)
+					output.Printf("  Disk:      %s\n", formatBytes(size))
 
 					// Show build artifacts if verbose
 					if verbose {
-						artifacts, _ := gitMgr.GetBuildArtifactSizes(w.TaskID)
+						artifacts, _ := gitMgr.GetBuildArtifactSizes(diskID)
 						if len(artifacts) > 0 {
-							fmt.Printf("  Artifacts:\n")
+							output.Printf("  Artifacts:\n")
 							for name, size := range artifacts {
 								if size > 0 {
-									fmt.Printf("    - %s: %s\n", name, formatBytes(size))
+									output.Printf("    - %s: %s\n", name, formatBytes(size))
 								}
 							}
 						}
@@ -2152,7 +3611,7 @@ func worktreeListCmd() *cobra.Command {
 			for _, id := range onDisk {
 				found := false
 				for _, w := range worktrees {
-					if w.TaskID == id {
+					if w.TaskID == id || filepath.Base(w.Path) == id {
 						found = true
 						break
 					}
@@ -2163,10 +3622,10 @@ func worktreeListCmd() *cobra.Command {
 			}
 
 			if len(orphaned) > 0 {
-				fmt.Println("\n👻 Orphaned (on disk but not tracked):")
+				output.Println("\n👻 Orphaned (on disk but not tracked):")
 				for _, id := range orphaned {
 					size, _ := gitMgr.GetDiskUsage(id)
-					fmt.Printf("  %s (%s)\n", id, formatBytes(size))
+					output.Printf("  %s (%s)\n", id, formatBytes(size))
 				}
 			}
 
@@ -2181,6 +3640,7 @@ func worktreeListCmd() *cobra.Command {
 // worktreeCleanupCmd removes all worktrees
 func worktreeCleanupCmd() *cobra.Command {
 	var force bool
+	var yes bool
 
 	command := &cobra.Command{
 		Use:   "cleanup",
@@ -2190,7 +3650,10 @@ func worktreeCleanupCmd() *cobra.Command {
 This command aggressively removes all worktrees and their build artifacts
 including target/, node_modules/, vendor/, etc.
 
-Use --force to skip confirmation.`,
+Worktrees of tasks still running and worktrees with uncommitted changes are
+listed before asking for confirmation. Use --yes to skip the confirmation.
+Worktrees of tasks whose workers are still alive are not removed unless
+--force is given.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectDir, store, err := requireProject()
 			if err != nil {
@@ -2211,12 +3674,12 @@ Use --force to skip confirmation.`,
 				// Check for orphaned worktrees
 				onDisk, _ := gitMgr.ListWorktreesOnDisk()
 				if len(onDisk) == 0 {
-					fmt.Println("No worktrees to clean")
+					output.Println("No worktrees to clean")
 					return nil
 				}
-				fmt.Printf("Found %d orphaned worktrees (not tracked in database)\n", len(onDisk))
+				output.Printf("Found %d orphaned worktrees (not tracked in database)\n", len(onDisk))
 			} else {
-				fmt.Printf("Found %d tracked worktrees\n", len(worktrees))
+				output.Printf("Found %d tracked worktrees\n", len(worktrees))
 			}
 
 			// Calculate total disk usage
@@ -2227,18 +3690,23 @@ Use --force to skip confirmation.`,
 			}
 
 			if totalSize > 0 {
-				fmt.Printf("Total disk usage: %s\n", formatBytes(totalSize))
+				output.Printf("Total disk usage: %s\n", formatBytes(totalSize))
 			}
 
-			// Confirm unless --force
-			if !force {
-				fmt.Print("\nRemove all worktrees? [y/N] ")
-				var response string
-				fmt.Scanln(&response)
-				if response != "y" && response != "Y" {
-					fmt.Println("Aborted")
-					return nil
-				}
+			// Show running tasks and uncommitted work that would be lost
+			onDisk, _ := gitMgr.ListWorktreesOnDisk()
+			titles := make(map[string]string, len(worktrees))
+			for _, w := range worktrees {
+				titles[w.TaskID] = w.TaskTitle
+			}
+			radius := assessBlastRadius(store, gitMgr, onDisk)
+			radius.print(titles)
+			if err := radius.refuseLive(force, "remove the worktrees of"); err != nil {
+				return err
+			}
+
+			if !confirm("Remove all worktrees?", yes || force) {
+				return nil
 			}
 
 			// Clean up all worktrees
@@ -2252,18 +3720,20 @@ Use --force to skip confirmation.`,
 				store.UpdateWorktreeStatus(w.TaskID, "removed")
 			}
 
-			fmt.Printf("\n✅ Removed %d worktrees, freed %s\n", count, formatBytes(freed))
+			output.Printf("\n✅ Removed %d worktrees, freed %s\n", count, formatBytes(freed))
 			return nil
 		},
 	}
 
-	command.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation")
+	command.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+	command.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation and remove worktrees of tasks still running")
 	return command
 }
 
 // worktreePruneCmd removes worktrees for completed/failed tasks
 func worktreePruneCmd() *cobra.Command {
 	var force bool
+	var yes bool
 	var aggressive bool
 
 	command := &cobra.Command{
@@ -2274,8 +3744,10 @@ func worktreePruneCmd() *cobra.Command {
 This is safer than 'cleanup' as it only removes worktrees for tasks that
 are no longer active (completed or failed).
 
+Worktrees with uncommitted changes are listed before asking for confirmation.
+
 Use --aggressive to also remove build artifacts (target/, node_modules/, etc.)
-Use --force to skip confirmation.`,
+Use --yes to skip confirmation.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectDir, store, err := requireProject()
 			if err != nil {
@@ -2305,13 +3777,13 @@ Use --force to skip confirmation.`,
 			}
 
 			if len(worktrees) == 0 && len(orphanedTaskIDs) == 0 {
-				fmt.Println("No worktrees to prune (no completed/failed tasks with worktrees)")
+				output.Println("No worktrees to prune (no completed/failed tasks with worktrees)")
 				return nil
 			}
 
 			// If we only have orphaned worktrees, clean them up
 			if len(worktrees) == 0 && len(orphanedTaskIDs) > 0 {
-				fmt.Printf("Found %d orphaned worktree(s) (not tracked in database)\n", len(orphanedTaskIDs))
+				output.Printf("Found %d orphaned worktree(s) (not tracked in database)\n", len(orphanedTaskIDs))
 
 				// Calculate sizes before removal
 				orphanedSizes := make(map[string]int64)
@@ -2323,23 +3795,17 @@ Use --force to skip confirmation.`,
 					}
 				}
 				if totalOrphanedSize > 0 {
-					fmt.Printf("Total disk usage: %s\n", formatBytes(totalOrphanedSize))
+					output.Printf("Total disk usage: %s\n", formatBytes(totalOrphanedSize))
 				}
 
-				fmt.Println("\nOrphaned worktrees to be removed:")
+				output.Println("\nOrphaned worktrees to be removed:")
 				for _, taskID := range orphanedTaskIDs {
-					fmt.Printf("  - %s (%s)\n", taskID, formatBytes(orphanedSizes[taskID]))
+					output.Printf("  - %s (%s)\n", taskID, formatBytes(orphanedSizes[taskID]))
 				}
+				assessBlastRadius(store, gitMgr, orphanedTaskIDs).print(nil)
 
-				// Confirm unless --force
-				if !force {
-					fmt.Print("\nRemove these orphaned worktrees? [y/N] ")
-					var response string
-					fmt.Scanln(&response)
-					if response != "y" && response != "Y" {
-						fmt.Println("Aborted")
-						return nil
-					}
+				if !confirm("Remove these orphaned worktrees?", yes || force) {
+					return nil
 				}
 
 				// Remove orphaned worktrees
@@ -2358,17 +3824,17 @@ Use --force to skip confirmation.`,
 					}
 
 					if err != nil {
-						fmt.Printf("⚠️  Failed to remove %s: %v\n", taskID, err)
+						output.Printf("⚠️  Failed to remove %s: %v\n", taskID, err)
 						continue
 					}
 					totalFreed += freed
 				}
 
-				fmt.Printf("\n✅ Pruned %d orphaned worktrees, freed %s\n", len(orphanedTaskIDs), formatBytes(totalFreed))
+				output.Printf("\n✅ Pruned %d orphaned worktrees, freed %s\n", len(orphanedTaskIDs), formatBytes(totalFreed))
 				return nil
 			}
 
-			fmt.Printf("Found %d worktrees for completed/failed tasks\n", len(worktrees))
+			output.Printf("Found %d worktrees for completed/failed tasks\n", len(worktrees))
 
 			// Calculate total disk usage
 			var totalSize int64
@@ -2378,24 +3844,22 @@ Use --force to skip confirmation.`,
 			}
 
 			if totalSize > 0 {
-				fmt.Printf("Total disk usage: %s\n", formatBytes(totalSize))
+				output.Printf("Total disk usage: %s\n", formatBytes(totalSize))
 			}
 
-			fmt.Println("\nWorktrees to be removed:")
-			for _, w := range worktrees {
+			output.Println("\nWorktrees to be removed:")
+			ids := make([]string, len(worktrees))
+			titles := make(map[string]string, len(worktrees))
+			for i, w := range worktrees {
 				size, _ := gitMgr.GetDiskUsage(w.TaskID)
-				fmt.Printf("  - %s: %s (%s)\n", w.TaskID, w.TaskTitle, formatBytes(size))
+				output.Printf("  - %s: %s (%s)\n", w.TaskID, w.TaskTitle, formatBytes(size))
+				ids[i] = w.TaskID
+				titles[w.TaskID] = w.TaskTitle
 			}
+			assessBlastRadius(store, gitMgr, ids).print(titles)
 
-			// Confirm unless --force
-			if !force {
-				fmt.Print("\nRemove these worktrees? [y/N] ")
-				var response string
-				fmt.Scanln(&response)
-				if response != "y" && response != "Y" {
-					fmt.Println("Aborted")
-					return nil
-				}
+			if !confirm("Remove these worktrees?", yes || force) {
+				return nil
 			}
 
 			// Remove each worktree
@@ -2411,7 +3875,7 @@ Use --force to skip confirmation.`,
 				}
 
 				if err != nil {
-					fmt.Printf("⚠️  Failed to remove %s: %v\n", w.TaskID, err)
+					output.Printf("⚠️  Failed to remove %s: %v\n", w.TaskID, err)
 					continue
 				}
 
@@ -2420,19 +3884,20 @@ Use --force to skip confirmation.`,
 				store.DeleteWorktree(w.TaskID)
 			}
 
-			fmt.Printf("\n✅ Pruned %d worktrees, freed %s\n", len(worktrees), formatBytes(totalFreed))
+			output.Printf("\n✅ Pruned %d worktrees, freed %s\n", len(worktrees), formatBytes(totalFreed))
 
 			// Prune orphaned worktrees too
 			orphaned, orphanedFreed, err := gitMgr.PruneOrphaned()
 			if err == nil && len(orphaned) > 0 {
-				fmt.Printf("Also removed %d orphaned worktrees, freed %s\n", len(orphaned), formatBytes(orphanedFreed))
+				output.Printf("Also removed %d orphaned worktrees, freed %s\n", len(orphaned), formatBytes(orphanedFreed))
 			}
 
 			return nil
 		},
 	}
 
-	command.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation")
+	command.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+	command.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation (same as --yes)")
 	command.Flags().BoolVarP(&aggressive, "aggressive", "a", false, "Remove build artifacts (target/, node_modules/, etc.)")
 	return command
 }
@@ -2457,14 +3922,20 @@ func formatBytes(bytes int64) string {
 // dashboardCmd starts the web dashboard
 func dashboardCmd() *cobra.Command {
 	var (
-		port string
-		open bool
+		port     string
+		open     bool
+		snapshot time.Duration
 	)
 
 	command := &cobra.Command{
 		Use:   "dashboard",
 		Short: "Start the web dashboard",
-		Long:  `Start a local web dashboard for visualizing project progress, tasks, and workers in real-time.`,
+		Long: `Start a local web dashboard for visualizing project progress, tasks, and workers in real-time.
+
+The dashboard reads the database through its own read-only connections, so
+it never holds up workers claiming tasks. With --snapshot it reads a copy of
+the database refreshed at that interval instead, and never touches the live
+file's locks at all.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			projectDir, store, err := requireProject()
 			if err != nil {
@@ -2472,21 +3943,50 @@ func dashboardCmd() *cobra.Command {
 			}
 			defer store.Close()
 
-			return runDashboard(store, projectDir, port, open)
+			return runDashboard(store, projectDir, port, open, snapshot)
 		},
 	}
 
 	command.Flags().StringVarP(&port, "port", "p", "3847", "Port to run dashboard on")
 	command.Flags().BoolVar(&open, "open", false, "Open browser automatically")
+	command.Flags().DurationVar(&snapshot, "snapshot", 0, "Read a copy of the database refreshed this often, e.g. 10s (default: read the live database)")
 	return command
 }
 
-func runDashboard(store *db.Store, projectDir string, port string, openBrowser bool) error {
-	// Import dashboard package
-	dash := dashboard.Config{
+// serveRunDashboard serves the dashboard from within a run, so the run's
+// broadcasts reach it, and returns a function that stops it
+func serveRunDashboard(store *db.Store, projectDir, port string) (func(), error) {
+	server, err := dashboard.New(dashboard.Config{
 		Addr:        ":" + port,
 		DatabaseURL: filepath.Join(projectDir, ".drover", "drover.db"),
 		Store:       store,
+		ProjectDir:  projectDir,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating dashboard: %w", err)
+	}
+	dashboard.SetGlobal(server)
+	go func() {
+		if err := server.Start(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			output.Printf("⚠️  Dashboard: %v\n", err)
+		}
+	}()
+	return func() {
+		dashboard.SetGlobal(nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+func runDashboard(store *db.Store, projectDir string, port string, openBrowser bool, snapshot time.Duration) error {
+	// Import dashboard package
+	dash := dashboard.Config{
+		Addr:             ":" + port,
+		DatabaseURL:      filepath.Join(projectDir, ".drover", "drover.db"),
+		Store:            store,
+		ProjectDir:       projectDir,
+		SnapshotInterval: snapshot,
 	}
 
 	server, err := dashboard.New(dash)
@@ -2579,32 +4079,32 @@ Available statuses:
 			}
 
 			if len(plans) == 0 {
-				fmt.Println("No plans found.")
+				output.Println("No plans found.")
 				return nil
 			}
 
-			fmt.Printf("\n📋 Plans (%d)\n", len(plans))
-			fmt.Println("══════════════════")
+			output.Printf("\n📋 Plans (%d)\n", len(plans))
+			output.Println("══════════════════")
 
 			for _, plan := range plans {
-				fmt.Printf("\n%s %s\n", formatPlanStatus(plan.Status), plan.ID)
-				fmt.Printf("  Title:    %s\n", plan.Title)
-				fmt.Printf("  Task:     %s\n", plan.TaskID)
+				output.Printf("\n%s %s\n", formatPlanStatus(plan.Status), plan.ID)
+				output.Printf("  Title:    %s\n", plan.Title)
+				output.Printf("  Task:     %s\n", plan.TaskID)
 				if plan.Complexity != "" {
-					fmt.Printf("  Complexity: %s\n", plan.Complexity)
+					output.Printf("  Complexity: %s\n", plan.Complexity)
 				}
 				if plan.EstimatedTime > 0 {
-					fmt.Printf("  Est. Time: %s\n", plan.EstimatedTime)
+					output.Printf("  Est. Time: %s\n", plan.EstimatedTime)
 				}
-				fmt.Printf("  Steps:    %d\n", len(plan.Steps))
+				output.Printf("  Steps:    %d\n", len(plan.Steps))
 				if plan.ApprovedBy != "" {
-					fmt.Printf("  Approved: by %s\n", plan.ApprovedBy)
+					output.Printf("  Approved: by %s\n", plan.ApprovedBy)
 				}
 				if plan.RejectionReason != "" {
-					fmt.Printf("  Rejected: %s\n", plan.RejectionReason)
+					output.Printf("  Rejected: %s\n", plan.RejectionReason)
 				}
 			}
-			fmt.Println()
+			output.Println()
 
 			return nil
 		},
@@ -2673,7 +4173,7 @@ The plan must be in 'pending' or 'draft' status to be approved.`,
 				return fmt.Errorf("approving plan: %w", err)
 			}
 
-			fmt.Printf("✅ Approved plan %s\n", planID)
+			output.Printf("✅ Approved plan %s\n", planID)
 
 			return nil
 		},
@@ -2705,7 +4205,7 @@ for the planning worker to use when creating a revised plan.`,
 
 			// If no feedback provided via flag, prompt for it
 			if feedback == "" {
-				fmt.Print("Enter rejection reason (optional, press Enter to skip): ")
+				output.Print("Enter rejection reason (optional, press Enter to skip): ")
 				fmt.Scanln(&feedback)
 			}
 
@@ -2714,9 +4214,9 @@ for the planning worker to use when creating a revised plan.`,
 				return fmt.Errorf("rejecting plan: %w", err)
 			}
 
-			fmt.Printf("❌ Rejected plan %s\n", planID)
+			output.Printf("❌ Rejected plan %s\n", planID)
 			if feedback != "" {
-				fmt.Printf("   Feedback: %s\n", feedback)
+				output.Printf("   Feedback: %s\n", feedback)
 			}
 
 			return nil
@@ -2749,11 +4249,11 @@ Warning: This action cannot be undone. Use --force to skip confirmation.`,
 
 			// Confirm unless --force
 			if !force {
-				fmt.Printf("Delete plan %s? [y/N] ", planID)
+				output.Printf("Delete plan %s? [y/N] ", planID)
 				var response string
 				fmt.Scanln(&response)
 				if response != "y" && response != "Y" {
-					fmt.Println("Aborted")
+					output.Println("Aborted")
 					return nil
 				}
 			}
@@ -2763,7 +4263,7 @@ Warning: This action cannot be undone. Use --force to skip confirmation.`,
 				return fmt.Errorf("deleting plan: %w", err)
 			}
 
-			fmt.Printf("🗑️  Deleted plan %s\n", planID)
+			output.Printf("🗑️  Deleted plan %s\n", planID)
 
 			return nil
 		},
@@ -2775,98 +4275,98 @@ Warning: This action cannot be undone. Use --force to skip confirmation.`,
 
 // printPlanDetails prints detailed information about a plan
 func printPlanDetails(plan *db.Plan) {
-	fmt.Println("\n📋 Plan Details")
-	fmt.Println("════════════════")
+	output.Println("\n📋 Plan Details")
+	output.Println("════════════════")
 
-	fmt.Printf("\nID:         %s\n", plan.ID)
-	fmt.Printf("Status:     %s\n", formatPlanStatus(plan.Status))
-	fmt.Printf("Title:      %s\n", plan.Title)
-	fmt.Printf("Task:       %s\n", plan.TaskID)
+	output.Printf("\nID:         %s\n", plan.ID)
+	output.Printf("Status:     %s\n", formatPlanStatus(plan.Status))
+	output.Printf("Title:      %s\n", plan.Title)
+	output.Printf("Task:       %s\n", plan.TaskID)
 
 	if plan.Description != "" {
-		fmt.Printf("\nDescription:\n  %s\n", plan.Description)
+		output.Printf("\nDescription:\n  %s\n", plan.Description)
 	}
 
 	if plan.Complexity != "" {
-		fmt.Printf("\nComplexity:  %s", plan.Complexity)
+		output.Printf("\nComplexity:  %s", plan.Complexity)
 	}
 
 	if plan.EstimatedTime > 0 {
-		fmt.Printf("\nEst. Time:  %s", plan.EstimatedTime)
+		output.Printf("\nEst. Time:  %s", plan.EstimatedTime)
 	}
 
 	if len(plan.RiskFactors) > 0 {
-		fmt.Printf("\nRisk Factors:\n")
+		output.Printf("\nRisk Factors:\n")
 		for _, rf := range plan.RiskFactors {
-			fmt.Printf("  • %s\n", rf)
+			output.Printf("  • %s\n", rf)
 		}
 	}
 
 	if len(plan.Dependencies) > 0 {
-		fmt.Printf("\nDependencies:\n")
+		output.Printf("\nDependencies:\n")
 		for _, dep := range plan.Dependencies {
-			fmt.Printf("  • %s\n", dep)
+			output.Printf("  • %s\n", dep)
 		}
 	}
 
 	if len(plan.FilesToCreate) > 0 {
-		fmt.Printf("\nFiles to Create (%d):\n", len(plan.FilesToCreate))
+		output.Printf("\nFiles to Create (%d):\n", len(plan.FilesToCreate))
 		for _, f := range plan.FilesToCreate {
-			fmt.Printf("  • %s\n", f.Path)
+			output.Printf("  • %s\n", f.Path)
 		}
 	}
 
 	if len(plan.FilesToModify) > 0 {
-		fmt.Printf("\nFiles to Modify (%d):\n", len(plan.FilesToModify))
+		output.Printf("\nFiles to Modify (%d):\n", len(plan.FilesToModify))
 		for _, f := range plan.FilesToModify {
-			fmt.Printf("  • %s\n", f.Path)
+			output.Printf("  • %s\n", f.Path)
 		}
 	}
 
 	if len(plan.Steps) > 0 {
-		fmt.Printf("\nSteps (%d):\n", len(plan.Steps))
+		output.Printf("\nSteps (%d):\n", len(plan.Steps))
 		for i, step := range plan.Steps {
-			fmt.Printf("  %d. %s\n", i+1, step.Description)
+			output.Printf("  %d. %s\n", i+1, step.Description)
 			if step.EstimatedTime > 0 {
-				fmt.Printf("     (est: %s)\n", step.EstimatedTime)
+				output.Printf("     (est: %s)\n", step.EstimatedTime)
 			}
 		}
 	}
 
 	if plan.ApprovedBy != "" {
-		fmt.Printf("\nApproved by: %s\n", plan.ApprovedBy)
+		output.Printf("\nApproved by: %s\n", plan.ApprovedBy)
 		if plan.ApprovedAt != nil {
-			fmt.Printf("Approved at: %s\n", plan.ApprovedAt.Format(time.RFC1123))
+			output.Printf("Approved at: %s\n", plan.ApprovedAt.Format(time.RFC1123))
 		}
 	}
 
 	if plan.RejectionReason != "" {
-		fmt.Printf("\nRejection: %s\n", plan.RejectionReason)
+		output.Printf("\nRejection: %s\n", plan.RejectionReason)
 	}
 
 	if plan.Revision > 0 {
-		fmt.Printf("\nRevision: %d\n", plan.Revision)
+		output.Printf("\nRevision: %d\n", plan.Revision)
 	}
 
 	if plan.ParentPlanID != "" {
-		fmt.Printf("Parent Plan: %s\n", plan.ParentPlanID)
+		output.Printf("Parent Plan: %s\n", plan.ParentPlanID)
 	}
 
 	if len(plan.Feedback) > 0 {
-		fmt.Printf("\nFeedback:\n")
+		output.Printf("\nFeedback:\n")
 		for _, fb := range plan.Feedback {
-			fmt.Printf("  • %s\n", fb)
+			output.Printf("  • %s\n", fb)
 		}
 	}
 
 	// Timestamps
-	fmt.Printf("\nCreated:    %s\n", plan.CreatedAt.Format(time.RFC1123))
-	fmt.Printf("Updated:    %s\n", plan.UpdatedAt.Format(time.RFC1123))
+	output.Printf("\nCreated:    %s\n", plan.CreatedAt.Format(time.RFC1123))
+	output.Printf("Updated:    %s\n", plan.UpdatedAt.Format(time.RFC1123))
 	if plan.CreatedBy != "" {
-		fmt.Printf("Created by: %s\n", plan.CreatedBy)
+		output.Printf("Created by: %s\n", plan.CreatedBy)
 	}
 
-	fmt.Println()
+	output.Println()
 }
 
 // formatPlanStatus returns a formatted plan status string
@@ -2937,7 +4437,7 @@ Example:
 			}
 
 			if len(plans) == 0 {
-				fmt.Println("No plans to review.")
+				output.Println("No plans to review.")
 				return nil
 			}
 
@@ -2962,17 +4462,22 @@ func runPlanReviewTUI(plans []*db.Plan, store *db.Store) error {
 // cancelCmd cancels a running or ready task
 func cancelCmd() *cobra.Command {
 	var reason string
+	var force bool
 
 	command := &cobra.Command{
 		Use:   "cancel <task-id>",
 		Short: "Cancel a task",
 		Long: `Cancel a running, ready, or claimed task.
 
-Canceling a task will:
-  - Stop the task's execution immediately
-  - Mark the task as 'cancelled'
-  - Release any claims on the task
-  - Record the cancellation reason
+A ready task is cancelled at once. A claimed or running task is stopped by
+the worker running it: within a few seconds it kills the agent and every
+process the agent started, releases the task's worktree, and marks the task
+'cancelled' rather than failed. The cancellation reason is recorded as the
+task's last error.
+
+If the worker running the task is gone, its expired lease cancels the task
+when it is reaped. --force cancels the task right away without waiting for
+the worker; use it only when no run is executing the task.
 
 Use 'drover retry' to retry a cancelled task if needed.`,
 		Args: cobra.ExactArgs(1),
@@ -2996,6 +4501,19 @@ Use 'drover retry' to retry a cancelled task if needed.`,
 				return fmt.Errorf("cannot cancel task with status '%s'", task.Status)
 			}
 
+			// A running task is stopped by its worker, which finishes the cancellation
+			if task.Status != types.TaskStatusReady && !force {
+				if err := store.RequestCancel(taskID, reason); err != nil {
+					return fmt.Errorf("requesting cancellation: %w", err)
+				}
+				output.Printf("🛑 Cancelling task %s; its worker will stop the agent\n", taskID)
+				output.Printf("   %s\n", task.Title)
+				if reason != "" {
+					output.Printf("   Reason: %s\n", reason)
+				}
+				return nil
+			}
+
 			// Cancel the task
 			if err := store.CancelTask(taskID, reason); err != nil {
 				return fmt.Errorf("cancelling task: %w", err)
@@ -3011,10 +4529,10 @@ Use 'drover retry' to retry a cancelled task if needed.`,
 			}
 			_ = store.RecordEvent(eventID, string(events.EventTaskCancelled), timestamp, taskID, task.EpicID, dataJSON)
 
-			fmt.Printf("✅ Cancelled task %s\n", taskID)
-			fmt.Printf("   %s\n", task.Title)
+			output.Printf("✅ Cancelled task %s\n", taskID)
+			output.Printf("   %s\n", task.Title)
 			if reason != "" {
-				fmt.Printf("   Reason: %s\n", reason)
+				output.Printf("   Reason: %s\n", reason)
 			}
 
 			return nil
@@ -3022,6 +4540,7 @@ Use 'drover retry' to retry a cancelled task if needed.`,
 	}
 
 	command.Flags().StringVar(&reason, "reason", "", "Reason for cancellation (optional)")
+	command.Flags().BoolVar(&force, "force", false, "Cancel a running task at once instead of waiting for its worker to stop it")
 	return command
 }
 
@@ -3065,8 +4584,8 @@ additional attempts.`,
 
 			// Check if force is needed
 			if task.Attempts >= task.MaxAttempts && !force {
-				fmt.Printf("⚠️  Task has reached max attempts (%d/%d)\n", task.Attempts, task.MaxAttempts)
-				fmt.Println("Use --force to reset the attempt counter and retry anyway.")
+				output.Printf("⚠️  Task has reached max attempts (%d/%d)\n", task.Attempts, task.MaxAttempts)
+				output.Println("Use --force to reset the attempt counter and retry anyway.")
 				return fmt.Errorf("max attempts reached")
 			}
 
@@ -3075,12 +4594,12 @@ additional attempts.`,
 				return fmt.Errorf("retrying task: %w", err)
 			}
 
-			fmt.Printf("✅ Retrying task %s\n", taskID)
-			fmt.Printf("   %s\n", task.Title)
+			output.Printf("✅ Retrying task %s\n", taskID)
+			output.Printf("   %s\n", task.Title)
 			if force {
-				fmt.Printf("   Attempt counter reset (will be attempt 1/%d)\n", task.MaxAttempts)
+				output.Printf("   Attempt counter reset (will be attempt 1/%d)\n", task.MaxAttempts)
 			} else {
-				fmt.Printf("   Will be attempt %d/%d\n", task.Attempts+1, task.MaxAttempts)
+				output.Printf("   Will be attempt %d/%d\n", task.Attempts+1, task.MaxAttempts)
 			}
 
 			return nil
@@ -3149,11 +4668,11 @@ the task and want to allow it to proceed.`,
 			}
 			_ = store.RecordEvent(eventID, string(events.EventTaskUnblocked), timestamp, taskID, task.EpicID, dataJSON)
 
-			fmt.Printf("✅ Resolved task %s\n", taskID)
-			fmt.Printf("   %s\n", task.Title)
-			fmt.Printf("   Removed %d blocker(s)\n", len(blockers))
+			output.Printf("✅ Resolved task %s\n", taskID)
+			output.Printf("   %s\n", task.Title)
+			output.Printf("   Removed %d blocker(s)\n", len(blockers))
 			if note != "" {
-				fmt.Printf("   Note: %s\n", note)
+				output.Printf("   Note: %s\n", note)
 			}
 
 			return nil
@@ -3267,12 +4786,12 @@ Examples:
 						if err != nil {
 							return fmt.Errorf("marshaling event: %w", err)
 						}
-						fmt.Println(string(data))
+						output.Println(string(data))
 					}
 				} else {
 					// Output in human-readable format
 					if len(events) == 0 && !quiet {
-						fmt.Println("No events found.")
+						output.Println("No events found.")
 					}
 					for _, e := range events {
 						printEvent(e)
@@ -3284,9 +4803,9 @@ Examples:
 			// Follow mode: stream events in real-time
 			// For now, just show historical events and note that follow is not yet implemented
 			if !quiet {
-				fmt.Println("📡 Streaming events...")
-				fmt.Println("Note: Real-time follow mode will be implemented in a future update.")
-				fmt.Println()
+				output.Println("📡 Streaming events...")
+				output.Println("Note: Real-time follow mode will be implemented in a future update.")
+				output.Println()
 			}
 
 			events, err := store.QueryEvents(types, epicID, taskID, sinceTS, untilTS, limit)
@@ -3300,7 +4819,7 @@ Examples:
 					if err != nil {
 						return fmt.Errorf("marshaling event: %w", err)
 					}
-					fmt.Println(string(data))
+					output.Println(string(data))
 				}
 			} else {
 				for _, e := range events {
@@ -3311,8 +4830,8 @@ Examples:
 			// In follow mode, we would poll for new events here
 			// For now, just show a message
 			if !quiet && len(events) > 0 {
-				fmt.Println()
-				fmt.Println("Waiting for new events... (Ctrl+C to exit)")
+				output.Println()
+				output.Println("Waiting for new events... (Ctrl+C to exit)")
 				<-ctx.Done()
 			}
 
@@ -3368,17 +4887,17 @@ func printEvent(event map[string]any) {
 		emoji = "📡"
 	}
 
-	fmt.Printf("%s [%s] %s task=%s", emoji, timeStr, eventType, taskID)
+	output.Printf("%s [%s] %s task=%s", emoji, timeStr, eventType, taskID)
 
 	if epicID, ok := event["epic_id"].(string); ok && epicID != "" {
-		fmt.Printf(" epic=%s", epicID)
+		output.Printf(" epic=%s", epicID)
 	}
 
 	if data, ok := event["data"].(string); ok && data != "" {
-		fmt.Printf(" data=%s", data)
+		output.Printf(" data=%s", data)
 	}
 
-	fmt.Println()
+	output.Println()
 }
 
 // flagsCmd manages feature flags
@@ -3388,8 +4907,8 @@ func flagsCmd() *cobra.Command {
 		Short: "Manage feature flags",
 		Long:  `Manage feature flags for experimental features.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("Feature flags management is coming soon.")
-			fmt.Println("This will allow enabling/disabling experimental features.")
+			output.Println("Feature flags management is coming soon.")
+			output.Println("This will allow enabling/disabling experimental features.")
 			return nil
 		},
 	}
@@ -3414,8 +4933,8 @@ func searchCmd() *cobra.Command {
 			defer store.Close()
 
 			// For now, just show a placeholder
-			fmt.Printf("Searching for: %s\n", query)
-			fmt.Println("Full-text search will be implemented in a future update.")
+			output.Printf("Searching for: %s\n", query)
+			output.Println("Full-text search will be implemented in a future update.")
 			return nil
 		},
 	}
@@ -3431,8 +4950,8 @@ func backpressureCmd() *cobra.Command {
 
 This helps prevent OOM by reducing worker spawning when Claude API is rate-limited.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("Backpressure management is coming soon.")
-			fmt.Println("This will be part of the memory management improvements.")
+			output.Println("Backpressure management is coming soon.")
+			output.Println("This will be part of the memory management improvements.")
 			return nil
 		},
 	}
@@ -3446,7 +4965,7 @@ func proxyCmd() *cobra.Command {
 		Short: "Manage the LLM proxy server",
 		Long:  `Configure and manage the proxy server for LLM API requests.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("Proxy server management is coming soon.")
+			output.Println("Proxy server management is coming soon.")
 			return nil
 		},
 	}
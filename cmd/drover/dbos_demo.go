@@ -9,6 +9,7 @@ import (
 	"os"
 	"time"
 
+	"github.com/cloud-shuttle/drover/internal/output"
 	"github.com/cloud-shuttle/drover/internal/workflow"
 	"github.com/dbos-inc/dbos-transact-golang/dbos"
 	"github.com/spf13/cobra"
@@ -106,16 +107,16 @@ Note: This requires PostgreSQL to be running and DBOS_SYSTEM_DATABASE_URL to be
 				},
 			}
 
-			fmt.Println("\n🚀 Starting DBOS Demo")
-			fmt.Println("===================")
-			fmt.Printf("Database: %s\n", dbURL)
-			fmt.Printf("Tasks: %d\n", len(tasks))
+			output.Println("\n🚀 Starting DBOS Demo")
+			output.Println("===================")
+			output.Printf("Database: %s\n", dbURL)
+			output.Printf("Tasks: %d\n", len(tasks))
 			if useQueue {
-				fmt.Printf("Mode:     Queue-based (parallel)\n")
+				output.Printf("Mode:     Queue-based (parallel)\n")
 			} else {
-				fmt.Printf("Mode:     Sequential\n")
+				output.Printf("Mode:     Sequential\n")
 			}
-			fmt.Println()
+			output.Println()
 
 			if useQueue {
 				// Execute with queue (parallel execution)
@@ -144,20 +145,20 @@ Note: This requires PostgreSQL to be running and DBOS_SYSTEM_DATABASE_URL to be
 				orchestrator.PrintResults(results)
 			}
 
-			fmt.Println("\n✅ Demo complete!")
-			fmt.Println("\n💡 Key differences from SQLite-based implementation:")
-			fmt.Println("  • State is automatically checkpointed to PostgreSQL")
-			fmt.Println("  • Each step can be independently recovered on failure")
-			fmt.Println("  • No manual task status management needed")
-			fmt.Println("  • Retries are built into the step execution")
+			output.Println("\n✅ Demo complete!")
+			output.Println("\n💡 Key differences from SQLite-based implementation:")
+			output.Println("  • State is automatically checkpointed to PostgreSQL")
+			output.Println("  • Each step can be independently recovered on failure")
+			output.Println("  • No manual task status management needed")
+			output.Println("  • Retries are built into the step execution")
 			if useQueue {
-				fmt.Println("  • Queue-based parallel execution with automatic worker pooling")
+				output.Println("  • Queue-based parallel execution with automatic worker pooling")
 			}
-			fmt.Println("\n📝 To use in production:")
-			fmt.Println("  1. Set DBOS_SYSTEM_DATABASE_URL to your PostgreSQL instance")
-			fmt.Println("  2. Replace 'drover run' with DBOS-based workflow execution")
-			fmt.Println("  3. Remove SQLite database and manual state management")
-			fmt.Println("  4. Configure queue workers based on your concurrency needs")
+			output.Println("\n📝 To use in production:")
+			output.Println("  1. Set DBOS_SYSTEM_DATABASE_URL to your PostgreSQL instance")
+			output.Println("  2. Replace 'drover run' with DBOS-based workflow execution")
+			output.Println("  3. Remove SQLite database and manual state management")
+			output.Println("  4. Configure queue workers based on your concurrency needs")
 
 			return nil
 		},
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// depCmd changes task dependencies after the tasks were created
+func depCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "dep",
+		Short: "Add or remove task dependencies",
+		Long: `Add or remove dependencies between existing tasks.
+
+A task waits for every task it depends on to complete. Adding a dependency
+on an unfinished task moves a ready task to blocked; removing a task's last
+unfinished dependency moves it back to ready. Tasks already running are left
+alone.
+
+Examples:
+  drover dep add task-b task-a          # task-b waits for task-a
+  drover dep add task-c task-a task-b   # task-c waits for both
+  drover dep rm task-b task-a`,
+	}
+	command.AddCommand(depAddCmd(), depRmCmd())
+	return command
+}
+
+func depAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <task-id> <blocked-by-id>...",
+		Short: "Make a task wait for other tasks",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+			for _, blockerID := range args[1:] {
+				if err := store.AddDependency(taskID, blockerID); err != nil {
+					return err
+				}
+				output.Printf("🔗 %s now depends on %s\n", taskID, blockerID)
+			}
+			return printDepStatus(store, taskID)
+		},
+	}
+}
+
+func depRmCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm <task-id> <blocked-by-id>...",
+		Aliases: []string{"remove"},
+		Short:   "Stop a task waiting for other tasks",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+			for _, blockerID := range args[1:] {
+				if err := store.RemoveDependency(taskID, blockerID); err != nil {
+					return err
+				}
+				output.Printf("✂️  %s no longer depends on %s\n", taskID, blockerID)
+			}
+			return printDepStatus(store, taskID)
+		},
+	}
+}
+
+// printDepStatus shows the status a dependency change left a task in
+func printDepStatus(store *db.Store, taskID string) error {
+	task, err := store.GetTask(taskID)
+	if err != nil {
+		return err
+	}
+	output.Printf("%s is %s\n", taskID, formatTaskStatus(task.Status))
+	return nil
+}
+
+// validateGraphCmd checks the dependency graph for deadlocks before a run
+func validateGraphCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate-graph",
+		Short: "Check task dependencies for cycles and tasks that can never run",
+		Long: `Check the dependencies of unfinished tasks for problems that would stall a run.
+
+Reports dependency cycles, where tasks wait for each other forever, and
+tasks that can never become ready because they wait, directly or through
+other tasks, for a task that failed, was cancelled or is on a cycle.
+Exits with an error if any are found.
+
+'drover dep add' refuses dependencies that close a cycle, but cycles can
+still arrive through imports. Break one with 'drover dep rm'; retry failed
+blockers with 'drover reset --failed'.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			report, err := store.ValidateGraph()
+			if err != nil {
+				return err
+			}
+			if report.OK() {
+				output.Println("✅ Every unfinished task can run")
+				return nil
+			}
+			printGraphReport(report)
+			return fmt.Errorf("%d cycle(s), %d task(s) that can never run", len(report.Cycles), len(report.Stuck))
+		},
+	}
+}
+
+func printGraphReport(report *db.GraphReport) {
+	if len(report.Cycles) > 0 {
+		output.Printf("🔁 Dependency cycles:\n")
+		for _, cycle := range report.Cycles {
+			output.Printf("  • %s → %s\n", strings.Join(cycle, " → "), cycle[0])
+		}
+	}
+	if len(report.Stuck) > 0 {
+		output.Printf("🚫 Tasks that can never run:\n")
+		for _, t := range report.Stuck {
+			output.Printf("  • %s: %s\n", t.TaskID, t.Reason)
+		}
+	}
+}
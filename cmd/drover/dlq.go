@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// maxFailureGuidance caps how much of the last error --with-failure passes
+// on to the agent
+const maxFailureGuidance = 2000
+
+// dlqCmd shows and requeues the dead-letter queue
+func dlqCmd() *cobra.Command {
+	var epicID string
+
+	command := &cobra.Command{
+		Use:   "dlq",
+		Short: "Show and requeue tasks that failed for good",
+		Long: `Show and requeue the dead-letter queue: failed tasks, which no run picks up
+again on its own, whether they used up their attempts or failed in a way the
+retry policy doesn't retry.
+
+Requeueing a task makes it ready with a fresh set of attempts. --with-failure
+queues its last error as guidance for the agent's next attempt, and
+--guidance adds your own.
+
+Examples:
+  drover dlq
+  drover dlq show task-123
+  drover dlq requeue task-123 --with-failure
+  drover dlq requeue task-123 --guidance "The fixtures moved to testdata/"
+  drover dlq requeue --all`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			tasks, err := store.ListDeadLetters(epicID)
+			if err != nil {
+				return err
+			}
+			if len(tasks) == 0 {
+				output.Println("The dead-letter queue is empty")
+				return nil
+			}
+
+			output.Printf("%-16s  %-8s  %-16s  %-30s  %s\n", "Task", "Attempts", "Failed", "Title", "Last error")
+			for _, task := range tasks {
+				output.Printf("%-16s  %-8s  %-16s  %-30s  %s\n", task.ID,
+					fmt.Sprintf("%d/%d", task.Attempts, task.MaxAttempts),
+					formatTimestamp(task.UpdatedAt), oneLine(task.Title, 30),
+					oneLine(task.LastError, 60))
+			}
+			output.Printf("\n%d task(s); 'drover dlq requeue <task-id>' runs one again\n", len(tasks))
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&epicID, "epic", "e", "", "Only show tasks in this epic")
+	command.AddCommand(dlqShowCmd(), dlqRequeueCmd())
+	return command
+}
+
+// dlqShowCmd prints a dead-lettered task's full last error and attempts
+func dlqShowCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <task-id>",
+		Short: "Show why a task failed, attempt by attempt",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			task, err := store.GetTask(args[0])
+			if err != nil {
+				return err
+			}
+			if task.Status != types.TaskStatusFailed {
+				return fmt.Errorf("task %s is %s, not in the dead-letter queue", task.ID, task.Status)
+			}
+			attempts, err := store.ListAttempts(task.ID)
+			if err != nil {
+				return err
+			}
+
+			output.Printf("☠️  %s: %s\n", task.ID, task.Title)
+			output.Printf("Attempts:   %d/%d\n", task.Attempts, task.MaxAttempts)
+			output.Printf("Failed:     %s\n", formatTimestamp(task.UpdatedAt))
+			if task.LastError != "" {
+				output.Printf("\nLast error:\n%s\n", task.LastError)
+			}
+			printTaskAttempts(attempts)
+			return nil
+		},
+	}
+}
+
+// dlqRequeueCmd moves dead-lettered tasks back to ready
+func dlqRequeueCmd() *cobra.Command {
+	var (
+		all         bool
+		epicID      string
+		withFailure bool
+		guidance    string
+	)
+
+	command := &cobra.Command{
+		Use:   "requeue [task-id...]",
+		Short: "Make failed tasks ready again with a fresh set of attempts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("give either task IDs or --all")
+			}
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			var tasks []*types.Task
+			if all {
+				if tasks, err = store.ListDeadLetters(epicID); err != nil {
+					return err
+				}
+			} else {
+				for _, id := range args {
+					task, err := store.GetTask(id)
+					if err != nil {
+						return err
+					}
+					tasks = append(tasks, task)
+				}
+			}
+
+			requeued := 0
+			for _, task := range tasks {
+				message := strings.TrimSpace(guidance)
+				if withFailure {
+					message = joinGuidance(failureGuidance(task), message)
+				}
+				if err := store.RequeueDeadLetter(task.ID, message); err != nil {
+					return err
+				}
+				requeued++
+				output.Printf("♻️  Requeued %s: %s\n", task.ID, task.Title)
+			}
+			if requeued == 0 {
+				output.Println("The dead-letter queue is empty")
+			}
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&all, "all", false, "Requeue every task in the dead-letter queue")
+	command.Flags().StringVarP(&epicID, "epic", "e", "", "With --all, only requeue tasks in this epic")
+	command.Flags().BoolVar(&withFailure, "with-failure", false, "Tell the agent how the last attempt failed")
+	command.Flags().StringVar(&guidance, "guidance", "", "Guidance for the agent's next attempt")
+	return command
+}
+
+// failureGuidance tells the agent how a task's previous attempts ended, or
+// returns "" when there's no error to pass on
+func failureGuidance(task *types.Task) string {
+	lastError := strings.TrimSpace(task.LastError)
+	if lastError == "" {
+		return ""
+	}
+	if len(lastError) > maxFailureGuidance {
+		lastError = "…" + lastError[len(lastError)-maxFailureGuidance:]
+	}
+	return fmt.Sprintf("This task failed before (%d of %d attempts used). The last attempt ended with:\n\n%s\n\nAvoid repeating that failure.",
+		task.Attempts, task.MaxAttempts, lastError)
+}
+
+// joinGuidance joins the non-empty guidance parts into one message
+func joinGuidance(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// oneLine returns the first line of s, cut to at most n characters
+func oneLine(s string, n int) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
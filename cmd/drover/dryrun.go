@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/cloud-shuttle/drover/internal/simulate"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// printDryRun prints the order 'drover run' would execute the unfinished
+// tasks in with the given workers and schedule, without executing anything
+func printDryRun(store *db.Store, workers int, epicID string, schedule db.Schedule) error {
+	tasks, err := store.ListTasksByEpic(epicID)
+	if err != nil {
+		return fmt.Errorf("listing tasks: %w", err)
+	}
+	deps, err := store.ListAllDependencies()
+	if err != nil {
+		return fmt.Errorf("listing dependencies: %w", err)
+	}
+	graph := simulationGraph(tasks, deps)
+	if len(graph) == 0 {
+		output.Println("Nothing to run: every task has finished")
+		return nil
+	}
+
+	plan, err := simulate.PlanWaves(graph, max(workers, 1), schedule == db.ScheduleCriticalPath)
+	if err != nil {
+		return err
+	}
+
+	byID := make(map[string]*types.Task, len(tasks))
+	subTasks := make(map[string]int)
+	for _, t := range tasks {
+		byID[t.ID] = t
+		if t.ParentID != "" && t.Status != types.TaskStatusCompleted {
+			subTasks[t.ParentID]++
+		}
+	}
+
+	output.Printf("🧪 Dry run: %d task(s) on %d worker(s), %s schedule; nothing will be executed\n", len(graph), plan.Workers, schedule)
+	if epicID != "" {
+		output.Printf("🎯 Epic: %s\n", epicID)
+	}
+	for n, wave := range plan.Waves {
+		output.Printf("\nWave %d (%d task(s))\n", n+1, len(wave))
+		for _, id := range wave {
+			t := byID[id]
+			line := fmt.Sprintf("  • %s  [p%d]  %s", id, t.ClaimPriority(), t.Title)
+			if n := subTasks[id]; n > 0 {
+				line += fmt.Sprintf(" (+%d sub-task(s))", n)
+			}
+			if t.Status != types.TaskStatusReady && t.Status != types.TaskStatusBlocked {
+				line += fmt.Sprintf(" (%s)", t.Status)
+			}
+			if t.ScheduledAt != nil && time.Unix(*t.ScheduledAt, 0).After(time.Now()) {
+				line += fmt.Sprintf(" ⏰ not before %s", formatTimestamp(*t.ScheduledAt))
+			}
+			output.Println(line)
+		}
+	}
+
+	output.Printf("\nWaves:        %d\n", len(plan.Waves))
+	output.Printf("Parallelism:  %.1f task(s) per wave; at most %d could run at once\n", plan.Parallelism(), plan.MaxParallel)
+	if plan.MaxParallel < plan.Workers {
+		output.Printf("💡 Workers beyond %d would sit idle\n", plan.MaxParallel)
+	}
+
+	report, err := store.ValidateGraph()
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		output.Println()
+		printGraphReport(report)
+	} else if len(plan.Unschedulable) > 0 {
+		output.Printf("\n⚠️  %d task(s) can never start: %v\n", len(plan.Unschedulable), plan.Unschedulable)
+	}
+	return nil
+}
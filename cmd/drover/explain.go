@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/explain"
+	"github.com/cloud-shuttle/drover/internal/llmproxy/client"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func explainCmd() *cobra.Command {
+	var (
+		model     string
+		directAPI bool
+		raw       bool
+	)
+
+	command := &cobra.Command{
+		Use:   "explain <task-id>",
+		Short: "Explain in plain language what happened to a task",
+		Long: `Explain what happened to a task and what to do next.
+
+Assembles the task's status transitions, activity timeline, events, attempts,
+last error, verdict, agent output and the changes on its worktree branch, and
+asks an AI model for a short explanation: what happened, the likely root cause,
+and the next steps.
+
+By default, this command uses the LLM proxy server. You can use --direct-api
+to connect to Anthropic's API directly (requires ANTHROPIC_API_KEY). Use --raw
+to print the assembled history without calling a model.
+
+Examples:
+  drover explain task-123
+  drover explain task-123 --direct-api
+  drover explain task-123 --raw`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			history, err := explain.Gather(store, projectDir, args[0])
+			if err != nil {
+				return err
+			}
+
+			if raw {
+				output.Print(history.Render())
+				return nil
+			}
+
+			apiKey := os.Getenv("ANTHROPIC_API_KEY")
+			if apiKey == "" {
+				return fmt.Errorf("ANTHROPIC_API_KEY environment variable is required\n\n" +
+					"Set your API key, or use --raw to print the task's history without AI")
+			}
+			if model == "" {
+				model = "claude-sonnet-4-20250514"
+			}
+
+			var explainer *explain.Explainer
+			if directAPI {
+				explainer = explain.NewExplainerWithDirectAPI(apiKey, model)
+			} else {
+				baseURL := os.Getenv("DROVER_LLM_PROXY_URL")
+				if baseURL == "" {
+					baseURL = "http://localhost:8080"
+				}
+
+				llmClient := client.NewClient(client.Config{
+					BaseURL: baseURL,
+					APIKey:  apiKey,
+					Timeout: 2 * time.Minute,
+				})
+
+				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				_, healthErr := llmClient.GetHealth(ctx)
+				cancel()
+				if healthErr != nil {
+					output.Printf("⚠️  LLM proxy server not available at %s\n", baseURL)
+					output.Printf("   Error: %v\n\n", healthErr)
+					output.Println("💡 Options:")
+					output.Println("   1. Start the proxy server: drover proxy serve")
+					output.Printf("   2. Use direct API: drover explain %s --direct-api\n", args[0])
+					output.Printf("   3. Print the raw history: drover explain %s --raw\n", args[0])
+					output.Println()
+					return fmt.Errorf("LLM proxy server not available")
+				}
+
+				explainer = explain.NewExplainer(llmClient, model)
+			}
+
+			output.Printf("🔎 %s: %s (%s)\n\n", history.Task.ID, history.Task.Title, history.Task.Status)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+
+			explanation, err := explainer.Explain(ctx, history)
+			if err != nil {
+				return fmt.Errorf("AI explanation failed: %w", err)
+			}
+			output.Println(explanation)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&model, "model", "", "AI model to use (default: claude-sonnet-4-20250514)")
+	command.Flags().BoolVar(&directAPI, "direct-api", false, "Use Anthropic API directly instead of proxy")
+	command.Flags().BoolVar(&raw, "raw", false, "Print the assembled history without calling a model")
+
+	return command
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/cloud-shuttle/drover/internal/fleet"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// fleetCmd manages a worker fleet shared by several projects
+func fleetCmd() *cobra.Command {
+	var registryPath string
+
+	command := &cobra.Command{
+		Use:   "fleet",
+		Short: "Serve several projects from one shared pool of workers",
+		Long: `Run one long-lived pool of workers that serves several registered projects,
+so agent capacity can be managed centrally instead of running drover per repo.
+
+Each project can have a quota, the most of its tasks that run at once, and a
+weight, its share of the workers relative to the other projects. A free worker
+serves the project with the fewest running tasks per unit of weight that is
+below its quota and has ready tasks.
+
+Examples:
+  drover fleet add ~/src/api --quota 4 --weight 2
+  drover fleet add ~/src/web --quota 2
+  drover fleet list
+  drover fleet run --workers 6`,
+	}
+	command.PersistentFlags().StringVar(&registryPath, "registry", "", "Registry file (default: ~/.drover/fleet.json)")
+
+	loadRegistry := func() (*fleet.Registry, string, error) {
+		path := registryPath
+		if path == "" {
+			var err error
+			if path, err = fleet.DefaultRegistryPath(); err != nil {
+				return nil, "", err
+			}
+		}
+		registry, err := fleet.LoadRegistry(path)
+		return registry, path, err
+	}
+
+	command.AddCommand(
+		fleetAddCmd(loadRegistry),
+		fleetRemoveCmd(loadRegistry),
+		fleetListCmd(loadRegistry),
+		fleetRunCmd(loadRegistry),
+	)
+	return command
+}
+
+// registryLoader loads the fleet registry and returns it with its path
+type registryLoader func() (*fleet.Registry, string, error)
+
+func fleetAddCmd(load registryLoader) *cobra.Command {
+	var project fleet.Project
+
+	command := &cobra.Command{
+		Use:   "add <project-dir>",
+		Short: "Register a project with the fleet",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, path, err := load()
+			if err != nil {
+				return err
+			}
+			project.Dir = args[0]
+			if err := registry.Add(project); err != nil {
+				return err
+			}
+			if err := registry.Save(path); err != nil {
+				return err
+			}
+			added := registry.Projects[len(registry.Projects)-1]
+			output.Printf("✅ Registered %s (%s)\n", added.Name, added.Dir)
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&project.Name, "name", "", "Project name (default: the directory name)")
+	command.Flags().IntVar(&project.Quota, "quota", 0, "Most tasks of this project to run at once (0 = no limit)")
+	command.Flags().IntVar(&project.Weight, "weight", 1, "Share of the workers relative to other projects")
+	return command
+}
+
+func fleetRemoveCmd(load registryLoader) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Unregister a project from the fleet",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, path, err := load()
+			if err != nil {
+				return err
+			}
+			if err := registry.Remove(args[0]); err != nil {
+				return err
+			}
+			if err := registry.Save(path); err != nil {
+				return err
+			}
+			output.Printf("🗑️  Unregistered %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func fleetListCmd(load registryLoader) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the projects the fleet serves",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, _, err := load()
+			if err != nil {
+				return err
+			}
+			if len(registry.Projects) == 0 {
+				output.Println("No projects registered. Add one with: drover fleet add <project-dir>")
+				return nil
+			}
+			output.Printf("%-20s  %-6s  %-6s  %s\n", "Name", "Quota", "Weight", "Directory")
+			for _, p := range registry.Projects {
+				quota := "-"
+				if p.Quota > 0 {
+					quota = fmt.Sprint(p.Quota)
+				}
+				output.Printf("%-20s  %-6s  %-6d  %s\n", p.Name, quota, max(p.Weight, 1), p.Dir)
+			}
+			return nil
+		},
+	}
+}
+
+func fleetRunCmd(load registryLoader) *cobra.Command {
+	var workers int
+
+	command := &cobra.Command{
+		Use:   "run",
+		Short: "Serve the registered projects until interrupted",
+		Long: `Serve the registered projects until interrupted.
+
+Unlike 'drover run', the fleet keeps running when the queues are empty and
+picks up tasks as they are added to any project. Tasks run with the legacy
+orchestrator; each project's .drover.toml applies to its own tasks.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			registry, _, err := load()
+			if err != nil {
+				return err
+			}
+			if workers == 0 {
+				workers = cfg.Workers
+			}
+
+			f, err := fleet.New(cfg, registry.Projects, workers)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			return f.Run(ctx)
+		},
+	}
+
+	command.Flags().IntVarP(&workers, "workers", "w", 0, "Workers shared by all projects (default: the configured workers)")
+	return command
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/beads"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+func importBeadsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "beads [file]",
+		Short: "Import epics, tasks and dependencies from a beads JSONL file",
+		Long: `Import epics, tasks and dependencies from a beads JSONL file, such as the
+.beads/beads.jsonl written by 'drover export'.
+
+Task and epic IDs are kept, so importing the same file again only adds what
+is missing, and a project can be moved between machines by exporting on one
+and importing on the other. Completed, failed and cancelled tasks keep their
+status. Tasks that were in progress elsewhere are imported as ready, and
+ready tasks waiting on unfinished dependencies as blocked.
+
+Examples:
+  drover import beads                      # Import .beads/beads.jsonl
+  drover import beads backup/beads.jsonl`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			path := filepath.Join(projectDir, ".beads", "beads.jsonl")
+			if len(args) > 0 {
+				path = args[0]
+			}
+			epics, tasks, deps, err := beads.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("reading beads file: %w", err)
+			}
+
+			output.Printf("📦 Importing from %s\n", path)
+			plan, err := planBeadsImport(store, epics, tasks, deps)
+			if err != nil {
+				return err
+			}
+			if err := store.ImportSession(plan.session); err != nil {
+				return fmt.Errorf("importing beads: %w", err)
+			}
+
+			for _, w := range plan.warnings {
+				output.Printf("⚠️  %s\n", w)
+			}
+			output.Printf("\n✅ Imported %d epic(s), %d task(s) and %d dependencies",
+				len(plan.session.Epics), len(plan.session.Tasks), len(plan.session.Dependencies))
+			if plan.existing > 0 {
+				output.Printf(", %d already present", plan.existing)
+			}
+			output.Println()
+			return nil
+		},
+	}
+}
+
+// beadsImportPlan is what a beads import adds to the project
+type beadsImportPlan struct {
+	session  *db.SessionExport // Only the epics, tasks and dependencies not yet present
+	existing int               // Epics and tasks already in the project
+	warnings []string
+}
+
+// planBeadsImport works out which records of a beads file are new, and the
+// status each new task should start in here
+func planBeadsImport(store *db.Store, epics []types.Epic, tasks []types.Task, deps []types.TaskDependency) (*beadsImportPlan, error) {
+	plan := &beadsImportPlan{session: &db.SessionExport{Version: "1.0"}}
+
+	existingEpics, err := store.ListEpics()
+	if err != nil {
+		return nil, fmt.Errorf("listing epics: %w", err)
+	}
+	existingTasks, err := store.ListTasks()
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+
+	knownEpics := make(map[string]bool)
+	for _, e := range existingEpics {
+		knownEpics[e.ID] = true
+	}
+	for i := range epics {
+		epic := &epics[i]
+		if knownEpics[epic.ID] {
+			plan.existing++
+			continue
+		}
+		knownEpics[epic.ID] = true
+		if epic.Status != types.EpicStatusClosed {
+			epic.Status = types.EpicStatusOpen
+		}
+		plan.session.Epics = append(plan.session.Epics, epic)
+	}
+
+	// Status of every task after the import, to tell which blockers are unfinished
+	status := make(map[string]types.TaskStatus)
+	for _, t := range existingTasks {
+		status[t.ID] = t.Status
+	}
+	var newTasks []*types.Task
+	for i := range tasks {
+		task := &tasks[i]
+		if _, ok := status[task.ID]; ok {
+			plan.existing++
+			continue
+		}
+		// Claims don't carry over between machines, so work in progress
+		// elsewhere starts over here
+		if task.Status == types.TaskStatusInProgress || task.Status == types.TaskStatusClaimed {
+			task.Status = types.TaskStatusReady
+		}
+		status[task.ID] = task.Status
+		newTasks = append(newTasks, task)
+	}
+
+	blockers := make(map[string][]string)
+	for _, dep := range deps {
+		blockers[dep.TaskID] = append(blockers[dep.TaskID], dep.BlockedBy)
+	}
+
+	now := time.Now().Unix()
+	added := make(map[string]bool)
+	for _, task := range newTasks {
+		if task.EpicID != "" && !knownEpics[task.EpicID] {
+			plan.warnings = append(plan.warnings, fmt.Sprintf("%s: epic %s not found, imported without an epic", task.ID, task.EpicID))
+			task.EpicID = ""
+		}
+		if task.ParentID != "" {
+			if _, ok := status[task.ParentID]; !ok {
+				plan.warnings = append(plan.warnings, fmt.Sprintf("%s: parent %s not found, skipped", task.ID, task.ParentID))
+				continue
+			}
+		}
+		if task.Status == types.TaskStatusReady {
+			for _, b := range blockers[task.ID] {
+				if s := status[b]; s != types.TaskStatusCompleted && s != types.TaskStatusCancelled {
+					task.Status = types.TaskStatusBlocked
+					break
+				}
+			}
+		}
+		task.Type = types.TaskTypeOther
+		task.MaxAttempts = cfg.MaxTaskAttempts
+		task.UpdatedAt = now
+		added[task.ID] = true
+		plan.session.Tasks = append(plan.session.Tasks, task)
+	}
+
+	// Parents must exist before their sub-tasks
+	sort.SliceStable(plan.session.Tasks, func(i, j int) bool {
+		return plan.session.Tasks[i].ParentID == "" && plan.session.Tasks[j].ParentID != ""
+	})
+
+	for _, dep := range deps {
+		if added[dep.TaskID] {
+			if _, ok := status[dep.BlockedBy]; ok {
+				plan.session.Dependencies = append(plan.session.Dependencies, dep)
+			}
+		}
+	}
+	return plan, nil
+}
@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -20,8 +21,7 @@ type JSONLRecord struct {
 	Type           string   `json:"type"`
 	Title          string   `json:"title"`
 	Description    string   `json:"description"`
-	Priority       int      `json:"priority"`
-	PriorityStr    string   `json:"priority,omitempty"` // Alternative string priority
+	Priority       json.RawMessage `json:"priority,omitempty"` // Integer, or a name like "high"
 	EpicID         string   `json:"epic_id,omitempty"`
 	StoryID        string   `json:"story_id,omitempty"`
 	StoryPoints    int      `json:"story_points,omitempty"`
@@ -73,15 +73,8 @@ func runImportJSONL(filename string, skipValidation bool) error {
 		return fmt.Errorf("opening database: %w", err)
 	}
 	defer store.Close()
-
-	// Initialize schema if this is a fresh database
-	if err := store.InitSchema(); err != nil {
-		return fmt.Errorf("initializing schema: %w", err)
-	}
-
-	// Run migrations for existing databases
-	if err := store.MigrateSchema(); err != nil {
-		return fmt.Errorf("migrating schema: %w", err)
+	if err := applyIDFormat(projectDir, store); err != nil {
+		return err
 	}
 
 	// Open the JSONL file
@@ -91,8 +84,8 @@ func runImportJSONL(filename string, skipValidation bool) error {
 	}
 	defer file.Close()
 
-	fmt.Printf("📦 Importing from %s\n", filename)
-	fmt.Println()
+	output.Printf("📦 Importing from %s\n", filename)
+	output.Println()
 
 	// Maps to track external IDs to Drover IDs
 	epicIDMap := make(map[string]string)  // EPIC-001 -> epic-123
@@ -117,40 +110,40 @@ func runImportJSONL(filename string, skipValidation bool) error {
 		// Parse JSON
 		var record JSONLRecord
 		if err := json.Unmarshal([]byte(line), &record); err != nil {
-			fmt.Printf("⚠️  Line %d: Skipping invalid JSON: %v\n", lineNum, err)
+			output.Printf("⚠️  Line %d: Skipping invalid JSON: %v\n", lineNum, err)
 			continue
 		}
 
 		// Validate required fields
 		if record.Type == "" {
-			fmt.Printf("⚠️  Line %d: Skipping (missing 'type' field)\n", lineNum)
+			output.Printf("⚠️  Line %d: Skipping (missing 'type' field)\n", lineNum)
 			continue
 		}
 		if record.ID == "" {
-			fmt.Printf("⚠️  Line %d: Skipping (missing 'id' field)\n", lineNum)
+			output.Printf("⚠️  Line %d: Skipping (missing 'id' field)\n", lineNum)
 			continue
 		}
 
 		// Normalize priority
-		priority := normalizePriority(record.Priority, record.PriorityStr)
+		priority := normalizePriority(record.Priority)
 
 		switch record.Type {
 		case "epic":
 			epic, err := store.CreateEpic(record.Title, record.Description)
 			if err != nil {
-				fmt.Printf("❌ [%s] Failed to create epic: %v\n", record.ID, err)
+				output.Printf("❌ [%s] Failed to create epic: %v\n", record.ID, err)
 				continue
 			}
 			epicIDMap[record.ID] = epic.ID
 			epicCount++
-			fmt.Printf("✅ [EPIC] %s -> %s\n", record.ID, epic.ID)
-			fmt.Printf("         %s\n", record.Title)
+			output.Printf("✅ [EPIC] %s -> %s\n", record.ID, epic.ID)
+			output.Printf("         %s\n", record.Title)
 
 		case "story":
 			// Resolve epic ID
 			epicID, ok := epicIDMap[record.EpicID]
 			if !ok {
-				fmt.Printf("⚠️  [%s] Skipping (epic '%s' not found)\n", record.ID, record.EpicID)
+				output.Printf("⚠️  [%s] Skipping (epic '%s' not found)\n", record.ID, record.EpicID)
 				continue
 			}
 
@@ -176,19 +169,19 @@ func runImportJSONL(filename string, skipValidation bool) error {
 				"",
 			)
 			if err != nil {
-				fmt.Printf("❌ [%s] Failed to create story: %v\n", record.ID, err)
+				output.Printf("❌ [%s] Failed to create story: %v\n", record.ID, err)
 				continue
 			}
 			storyIDMap[record.ID] = task.ID
 			storyCount++
-			fmt.Printf("✅ [STORY] %s -> %s\n", record.ID, task.ID)
-			fmt.Printf("         %s\n", record.Title)
+			output.Printf("✅ [STORY] %s -> %s\n", record.ID, task.ID)
+			output.Printf("         %s\n", record.Title)
 
 		case "task":
 			// Resolve story ID (story is the parent task)
 			parentID, ok := storyIDMap[record.StoryID]
 			if !ok {
-				fmt.Printf("⚠️  [%s] Skipping (story '%s' not found)\n", record.ID, record.StoryID)
+				output.Printf("⚠️  [%s] Skipping (story '%s' not found)\n", record.ID, record.StoryID)
 				continue
 			}
 
@@ -210,15 +203,15 @@ func runImportJSONL(filename string, skipValidation bool) error {
 				nil, // no blocked-by
 			)
 			if err != nil {
-				fmt.Printf("❌ [%s] Failed to create task: %v\n", record.ID, err)
+				output.Printf("❌ [%s] Failed to create task: %v\n", record.ID, err)
 				continue
 			}
 			taskCount++
-			fmt.Printf("✅ [TASK] %s -> %s\n", record.ID, task.ID)
-			fmt.Printf("         %s\n", record.Title)
+			output.Printf("✅ [TASK] %s -> %s\n", record.ID, task.ID)
+			output.Printf("         %s\n", record.Title)
 
 		default:
-			fmt.Printf("⚠️  Line %d: Skipping unknown type '%s'\n", lineNum, record.Type)
+			output.Printf("⚠️  Line %d: Skipping unknown type '%s'\n", lineNum, record.Type)
 		}
 	}
 
@@ -226,23 +219,26 @@ func runImportJSONL(filename string, skipValidation bool) error {
 		return fmt.Errorf("reading file: %w", err)
 	}
 
-	fmt.Println()
-	fmt.Println("=== Import Complete ===")
-	fmt.Printf("Epics:  %d\n", epicCount)
-	fmt.Printf("Stories: %d\n", storyCount)
-	fmt.Printf("Tasks:  %d\n", taskCount)
-	fmt.Println()
-	fmt.Printf("Run 'cd %s && drover status' to see all tasks\n", projectDir)
+	output.Println()
+	output.Println("=== Import Complete ===")
+	output.Printf("Epics:  %d\n", epicCount)
+	output.Printf("Stories: %d\n", storyCount)
+	output.Printf("Tasks:  %d\n", taskCount)
+	output.Println()
+	output.Printf("Run 'cd %s && drover status' to see all tasks\n", projectDir)
 
 	return nil
 }
 
 // normalizePriority converts string priority to integer, or returns the integer as-is
-func normalizePriority(priorityInt int, priorityStr string) int {
+func normalizePriority(raw json.RawMessage) int {
 	// If integer is set, use it
-	if priorityInt > 0 {
+	var priorityInt int
+	if err := json.Unmarshal(raw, &priorityInt); err == nil && priorityInt > 0 {
 		return priorityInt
 	}
+	var priorityStr string
+	json.Unmarshal(raw, &priorityStr)
 
 	// Map string priorities to integers
 	switch strings.ToLower(priorityStr) {
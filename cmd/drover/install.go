@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 
+	"github.com/cloud-shuttle/drover/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -46,7 +47,7 @@ After installation, you can run 'drover' from any directory.`,
 
 			// Build for current platform if we're running from source
 			if filepath.Base(execPath) != "drover" {
-				fmt.Println("Building Drover...")
+				output.Println("Building Drover...")
 				buildCmd := exec.Command("go", "build", "-o", "drover", "./cmd/drover")
 				buildCmd.Dir = filepath.Dir(execPath)
 				if err := buildCmd.Run(); err != nil {
@@ -66,23 +67,23 @@ After installation, you can run 'drover' from any directory.`,
 				return fmt.Errorf("installing to %s: %w\nTry running with sudo", destPath, err)
 			}
 
-			fmt.Printf("✅ Installed Drover to %s\n", destPath)
+			output.Printf("✅ Installed Drover to %s\n", destPath)
 
 			// Check if it's in PATH
 			if _, err := exec.LookPath("drover"); err != nil {
-				fmt.Printf("\n⚠️  Warning: %s may not be in your PATH\n", binDir)
-				fmt.Println("\nAdd this to your ~/.bashrc or ~/.zshrc:")
-				fmt.Printf("   export PATH=\"$PATH:%s\"\n", binDir)
-				fmt.Println("\nThen run: source ~/.bashrc (or source ~/.zshrc)")
+				output.Printf("\n⚠️  Warning: %s may not be in your PATH\n", binDir)
+				output.Println("\nAdd this to your ~/.bashrc or ~/.zshrc:")
+				output.Printf("   export PATH=\"$PATH:%s\"\n", binDir)
+				output.Println("\nThen run: source ~/.bashrc (or source ~/.zshrc)")
 			} else {
-				fmt.Println("✨ Drover is ready to use from any directory!")
+				output.Println("✨ Drover is ready to use from any directory!")
 			}
 
-			fmt.Println("\nQuick start:")
-			fmt.Println("  cd /path/to/your/project")
-			fmt.Println("  drover init")
-			fmt.Println("  drover add \"My first task\"")
-			fmt.Println("  drover run")
+			output.Println("\nQuick start:")
+			output.Println("  cd /path/to/your/project")
+			output.Println("  drover init")
+			output.Println("  drover add \"My first task\"")
+			output.Println("  drover run")
 
 			return nil
 		},
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/integrations"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// jiraCmd groups the Jira integration commands
+func jiraCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "jira",
+		Short: "Import Jira issues and sync task status back to Jira",
+		Long: `Import Jira issues as tasks and keep Jira up to date as they run.
+
+Configure the connection with environment variables:
+  DROVER_JIRA_URL      Jira base URL, e.g. https://example.atlassian.net
+  DROVER_JIRA_EMAIL    Jira Cloud account email (omit for a Data Center token)
+  DROVER_JIRA_TOKEN    API token or personal access token (or JIRA_API_TOKEN)
+
+While 'drover run' works on an imported task, its issue is moved to
+"In Progress", and to "Done" once the task completes. Change the target
+statuses with DROVER_JIRA_IN_PROGRESS_STATUS and DROVER_JIRA_DONE_STATUS,
+or turn syncing off with DROVER_JIRA_SYNC=false.`,
+	}
+	command.AddCommand(jiraImportCmd())
+	return command
+}
+
+func jiraImportCmd() *cobra.Command {
+	var (
+		jql    string
+		epicID string
+	)
+
+	command := &cobra.Command{
+		Use:   "import",
+		Short: "Import the issues matching a JQL query",
+		Long: `Import the issues matching a JQL query as tasks.
+
+Jira epics become epics, sub-tasks become sub-tasks of their parent's task,
+and "is blocked by" links become dependencies. Each task keeps its Jira key,
+so running the same import again only adds new issues. Issues already done
+in Jira are not imported.
+
+Examples:
+  drover jira import --jql "project = PROJ AND labels = drover"
+  drover jira import --jql "sprint in openSprints()" --epic epic-a1b2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if jql == "" {
+				return fmt.Errorf("--jql is required")
+			}
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			client := cfg.CreateJiraClient()
+			if client == nil {
+				return fmt.Errorf("jira is not configured: set DROVER_JIRA_URL and DROVER_JIRA_TOKEN")
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+			defer cancel()
+
+			result, err := integrations.ImportJira(ctx, client, store, integrations.JiraImportOptions{
+				JQL:    jql,
+				EpicID: epicID,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, t := range result.Tasks {
+				output.Printf("✅ %s -> %s\n", t.Key, t.TaskID)
+				output.Printf("   %s\n", t.Title)
+			}
+			for _, w := range result.Warnings {
+				output.Printf("⚠️  %s\n", w)
+			}
+			output.Printf("\n📦 Imported %d task(s) and %d epic(s)", len(result.Tasks), result.EpicsCreated)
+			if result.Existing > 0 {
+				output.Printf(", %d already imported", result.Existing)
+			}
+			if result.Done > 0 {
+				output.Printf(", %d done in Jira skipped", result.Done)
+			}
+			output.Println()
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&jql, "jql", "", "JQL query selecting the issues to import")
+	command.Flags().StringVarP(&epicID, "epic", "e", "", "Epic for issues that don't belong to a Jira epic")
+	return command
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/integrations"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// linearCmd groups the Linear integration commands
+func linearCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "linear",
+		Short: "Import Linear issues as tasks",
+		Long: `Import Linear issues as tasks, in bulk or as they are labeled for automation.
+
+Configure the connection with environment variables:
+  DROVER_LINEAR_API_KEY         Personal API key (or LINEAR_API_KEY)
+  DROVER_LINEAR_WEBHOOK_SECRET  Signing secret of the webhook, for 'serve'
+  DROVER_LINEAR_LABEL           Label marking issues for automation (default: drover)
+
+Linear projects become epics, sub-issues become sub-tasks of their parent's
+task, and "blocked by" relations become dependencies. Urgent and high
+priority issues are claimed first, low priority ones last.`,
+	}
+	command.AddCommand(linearImportCmd(), linearServeCmd())
+	return command
+}
+
+func linearImportCmd() *cobra.Command {
+	var (
+		filter integrations.LinearFilter
+		epicID string
+		all    bool
+	)
+
+	command := &cobra.Command{
+		Use:   "import",
+		Short: "Import the issues matching a filter",
+		Long: `Import the Linear issues matching a filter as tasks.
+
+By default only issues carrying the automation label are imported; pass --all
+to import every issue the other filters match. Each task keeps its Linear
+identifier, so running the same import again only adds new issues. Completed
+and canceled issues are not imported.
+
+Examples:
+  drover linear import --team ENG
+  drover linear import --project "Checkout revamp" --all
+  drover linear import --team ENG --label agent-ready --epic epic-a1b2`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !all && filter.Label == "" {
+				filter.Label = cfg.LinearLabel
+			}
+			if filter.Team == "" && filter.Project == "" && filter.Label == "" {
+				return fmt.Errorf("--team, --project or --label is required with --all")
+			}
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			client := cfg.CreateLinearClient()
+			if client == nil {
+				return fmt.Errorf("linear is not configured: set DROVER_LINEAR_API_KEY")
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+			defer cancel()
+
+			result, err := integrations.ImportLinear(ctx, client, store, integrations.LinearImportOptions{
+				Filter: filter,
+				EpicID: epicID,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, t := range result.Tasks {
+				output.Printf("✅ %s -> %s\n", t.Key, t.TaskID)
+				output.Printf("   %s\n", t.Title)
+			}
+			for _, w := range result.Warnings {
+				output.Printf("⚠️  %s\n", w)
+			}
+			output.Printf("\n📦 Imported %d task(s) and %d epic(s)", len(result.Tasks), result.EpicsCreated)
+			if result.Existing > 0 {
+				output.Printf(", %d already imported", result.Existing)
+			}
+			if result.Done > 0 {
+				output.Printf(", %d done in Linear skipped", result.Done)
+			}
+			output.Println()
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&filter.Team, "team", "", "Team key, e.g. ENG")
+	command.Flags().StringVar(&filter.Project, "project", "", "Project name")
+	command.Flags().StringVar(&filter.Label, "label", "", "Label (default: the automation label)")
+	command.Flags().BoolVar(&all, "all", false, "Don't require the automation label")
+	command.Flags().StringVarP(&epicID, "epic", "e", "", "Epic for issues that don't belong to a Linear project")
+	return command
+}
+
+func linearServeCmd() *cobra.Command {
+	var (
+		addr     string
+		label    string
+		epicID   string
+		insecure bool
+	)
+
+	command := &cobra.Command{
+		Use:   "serve",
+		Short: "Receive Linear webhooks and queue issues as they are labeled",
+		Long: `Receive Linear webhooks and queue issues as tasks as they are labeled.
+
+Create a webhook in Linear (Settings > API > Webhooks) for Issue events,
+pointing at http://<host><addr>/linear, and set its signing secret in
+DROVER_LINEAR_WEBHOOK_SECRET. When an issue is created or updated with the
+automation label it is imported as a ready task, which a running
+'drover run' or 'drover fleet run' then picks up.
+
+Without a signing secret anyone who can reach the address could queue
+tasks, so the server refuses to start unless --insecure is given. It listens
+on 127.0.0.1 by default; pass --addr :8091 (or put a proxy in front) for
+Linear to reach it.
+
+Examples:
+  drover linear serve --addr :8091
+  drover linear serve --addr :9000 --label agent-ready`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if label == "" {
+				label = cfg.LinearLabel
+			}
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			client := cfg.CreateLinearClient()
+			if client == nil {
+				return fmt.Errorf("linear is not configured: set DROVER_LINEAR_API_KEY")
+			}
+			if cfg.LinearWebhookSecret == "" {
+				if !insecure {
+					return fmt.Errorf("DROVER_LINEAR_WEBHOOK_SECRET is not set; set it to Linear's signing secret, or pass --insecure to accept unsigned webhooks")
+				}
+				log.Println("⚠️  DROVER_LINEAR_WEBHOOK_SECRET is not set, webhook signatures are not checked (--insecure)")
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle("/linear", integrations.NewLinearWebhook(client, store, label, epicID, cfg.LinearWebhookSecret, nil))
+			server := &http.Server{Addr: addr, Handler: mux}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				server.Shutdown(shutdownCtx)
+			}()
+
+			log.Printf("🔗 Listening for Linear webhooks on %s/linear (label %q)", addr, label)
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&addr, "addr", "127.0.0.1:8091", "Address to listen on")
+	command.Flags().BoolVar(&insecure, "insecure", false, "Accept unsigned webhooks when DROVER_LINEAR_WEBHOOK_SECRET is not set")
+	command.Flags().StringVar(&label, "label", "", "Label marking issues for automation (default: DROVER_LINEAR_LABEL or drover)")
+	command.Flags().StringVarP(&epicID, "epic", "e", "", "Epic for issues that don't belong to a Linear project")
+	return command
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/cloud-shuttle/drover/internal/attemptlog"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// logsCmd prints what the agent output while working on a task
+func logsCmd() *cobra.Command {
+	var attemptNumber int
+	var full bool
+
+	command := &cobra.Command{
+		Use:   "logs <task-id>",
+		Short: "Show the agent's output for a task",
+		Long: `Show what the agent output while working on a task.
+
+Prints the transcript of the task's latest attempt, or of the attempt given
+with --attempt; 'drover info' lists them. Transcripts over 1 MiB keep their
+end. When an attempt has no transcript in the database, its log file under
+.drover/logs is printed instead.
+
+--full prints the log file, with the parts rotated out of it oldest first,
+for output longer than the transcript keeps. How big a log grows before it
+rotates, how many parts are kept and how long logs are kept are set under
+[logs] in .drover.toml.
+
+Examples:
+  drover logs task-123
+  drover logs task-123 --attempt 1
+  drover logs task-123 --full | less`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			taskID := args[0]
+			attempts, err := store.ListAttempts(taskID)
+			if err != nil {
+				return err
+			}
+			if len(attempts) == 0 {
+				return fmt.Errorf("task %s has not run yet", taskID)
+			}
+
+			attempt := attempts[len(attempts)-1]
+			if attemptNumber > 0 {
+				if attemptNumber > len(attempts) {
+					return fmt.Errorf("task %s has %d attempt(s), not %d", taskID, len(attempts), attemptNumber)
+				}
+				attempt = attempts[attemptNumber-1]
+			}
+
+			if full {
+				if attempt.OutputPath == "" {
+					return fmt.Errorf("no log file was kept for attempt %d of task %s", attempt.Number, taskID)
+				}
+				logFile, err := attemptlog.Open(filepath.Join(projectDir, attempt.OutputPath))
+				if err != nil {
+					return fmt.Errorf("reading output log: %w", err)
+				}
+				defer logFile.Close()
+				output.Printf("── %s attempt %d/%d on %s, started %s ──\n",
+					taskID, attempt.Number, len(attempts), attempt.WorkerID, formatTimestamp(attempt.StartedAt))
+				_, err = io.Copy(cmd.OutOrStdout(), logFile)
+				return err
+			}
+
+			transcript, err := store.GetTranscript(taskID, attempt.Number)
+			if err != nil {
+				return err
+			}
+			if transcript == "" && attempt.OutputPath != "" {
+				logFile, err := attemptlog.Open(filepath.Join(projectDir, attempt.OutputPath))
+				if err != nil {
+					return fmt.Errorf("reading output log: %w", err)
+				}
+				data, err := io.ReadAll(logFile)
+				logFile.Close()
+				if err != nil {
+					return fmt.Errorf("reading output log: %w", err)
+				}
+				transcript = string(data)
+			}
+			if transcript == "" {
+				return fmt.Errorf("no output was recorded for attempt %d of task %s", attempt.Number, taskID)
+			}
+
+			output.Printf("── %s attempt %d/%d on %s, started %s ──\n",
+				taskID, attempt.Number, len(attempts), attempt.WorkerID, formatTimestamp(attempt.StartedAt))
+			fmt.Fprint(cmd.OutOrStdout(), transcript)
+			if transcript[len(transcript)-1] != '\n' {
+				fmt.Fprintln(cmd.OutOrStdout())
+			}
+			return nil
+		},
+	}
+
+	command.Flags().IntVar(&attemptNumber, "attempt", 0, "Attempt number to show (default: the latest)")
+	command.Flags().BoolVar(&full, "full", false, "Print the attempt's whole log file, rotated parts included, instead of its transcript")
+	return command
+}
@@ -4,11 +4,14 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 
 	"github.com/cloud-shuttle/drover/internal/config"
 	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/cloud-shuttle/drover/internal/project"
 	"github.com/cloud-shuttle/drover/pkg/telemetry"
 	"github.com/spf13/cobra"
 )
@@ -45,7 +48,17 @@ func main() {
 agents in parallel to complete your entire project. It manages task dependencies,
 handles failures gracefully, and guarantees progress through crashes and restarts.`,
 		Version: "0.3.0",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			style, err := output.ParseStyle(cfg.OutputStyle)
+			if err != nil {
+				return err
+			}
+			output.SetStyle(style)
+			log.SetOutput(output.NewWriter(os.Stderr))
+			return nil
+		},
 	}
+	rootCmd.PersistentFlags().StringVar(&cfg.OutputStyle, "output-style", cfg.OutputStyle, "Output style: emoji, plain, or ascii (env: DROVER_OUTPUT_STYLE)")
 
 	rootCmd.AddCommand(
 		initCmd(),
@@ -54,6 +67,9 @@ handles failures gracefully, and guarantees progress through crashes and restart
 		quickCmd(),
 		epicCmd(),
 		infoCmd(),
+		logsCmd(),
+		depCmd(),
+		validateGraphCmd(),
 		statusCmd(),
 		watchCmd(),
 		resumeCmd(),
@@ -61,6 +77,8 @@ handles failures gracefully, and guarantees progress through crashes and restart
 		exportCmd(),
 		importCmd(),
 		importJSONLCmd(),
+		jiraCmd(),
+		linearCmd(),
 		shareCmd(),
 		importShareCmd(),
 		operatorCmd(),
@@ -71,7 +89,18 @@ handles failures gracefully, and guarantees progress through crashes and restart
 		pauseCmd(),
 		resumeCmdForTask(),
 		hintCmd(),
+		commentCmd(),
+		activityCmd(),
+		auditCmd(),
+		explainCmd(),
+		gcCmd(),
+		assignCmd(),
+		mutexCmd(),
+		envCmd(),
 		editCmd(),
+		scheduleCmd(),
+		queueCmd(),
+		dlqCmd(),
 		flagsCmd(),
 		searchCmd(),
 		backpressureCmd(),
@@ -82,10 +111,14 @@ handles failures gracefully, and guarantees progress through crashes and restart
 		resolveCmd(),
 		streamCmd(),
 		specCmd(),
+		simulateCmd(),
+		fleetCmd(),
+		migrateCmd(),
+		runsCmd(),
 	)
 
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, output.Format(err.Error()))
 		os.Exit(1)
 	}
 }
@@ -117,16 +150,51 @@ func requireProject() (string, *db.Store, error) {
 		return "", nil, err
 	}
 
+	// Opening migrates the schema to the latest version
 	store, err := db.Open(filepath.Join(dir, ".drover", "drover.db"))
 	if err != nil {
 		return "", nil, fmt.Errorf("opening database: %w", err)
 	}
 
-	// Run migrations to ensure database schema is up to date
-	if err := store.MigrateSchema(); err != nil {
+	// Attribute status changes made from the CLI to the operator
+	store.SetActor(config.GetOperator())
+
+	if err := applyIDFormat(dir, store); err != nil {
 		store.Close()
-		return "", nil, fmt.Errorf("migrating database schema: %w", err)
+		return "", nil, err
 	}
 
 	return dir, store, nil
 }
+
+// applyIDFormat makes the store create IDs in the project's id_format
+func applyIDFormat(dir string, store *db.Store) error {
+	projectCfg, err := project.Load(dir)
+	if err != nil {
+		return fmt.Errorf("loading project config: %w", err)
+	}
+	format, err := db.ParseIDFormat(projectCfg.IDFormat)
+	if err != nil {
+		return fmt.Errorf(".drover.toml: %w", err)
+	}
+	store.SetIDFormat(format)
+	return nil
+}
+
+// applySchedule makes the store claim tasks in the named schedule, or in the
+// project's schedule when name is empty, and returns the schedule used
+func applySchedule(dir string, store *db.Store, name string) (db.Schedule, error) {
+	if name == "" {
+		projectCfg, err := project.Load(dir)
+		if err != nil {
+			return "", fmt.Errorf("loading project config: %w", err)
+		}
+		name = projectCfg.Schedule
+	}
+	schedule, err := db.ParseSchedule(name)
+	if err != nil {
+		return "", err
+	}
+	store.SetSchedule(schedule)
+	return schedule, nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// migrateCmd shows and controls the database schema version
+func migrateCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "migrate",
+		Short: "Show or change the database schema version",
+		Long: `Show or change the version of the project database's schema.
+
+Every drover command migrates the database to the newest schema it knows when
+it opens it, so this is rarely needed. Use it to see which migrations are
+applied, or to migrate down before going back to an older drover, which
+refuses to open a database migrated by a newer one.
+
+Examples:
+  drover migrate                # Same as 'drover migrate status'
+  drover migrate up
+  drover migrate down --to 3`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateStatus()
+		},
+	}
+	command.AddCommand(migrateStatusCmd(), migrateUpCmd(), migrateDownCmd())
+	return command
+}
+
+func migrateStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "List migrations and whether each is applied",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateStatus()
+		},
+	}
+}
+
+func migrateUpCmd() *cobra.Command {
+	var to int
+
+	command := &cobra.Command{
+		Use:   "up",
+		Short: "Apply pending migrations",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openUnmigrated()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			from, err := store.SchemaVersion()
+			if err != nil {
+				return err
+			}
+			if to == 0 {
+				if to, err = db.LatestSchemaVersion(); err != nil {
+					return err
+				}
+			}
+			if to < from {
+				return fmt.Errorf("database is already at version %d; use 'drover migrate down' to go back", from)
+			}
+			if err := store.MigrateTo(to); err != nil {
+				return err
+			}
+			if to == from {
+				output.Printf("✅ Already at schema version %d\n", to)
+			} else {
+				output.Printf("✅ Migrated schema from version %d to %d\n", from, to)
+			}
+			return nil
+		},
+	}
+	command.Flags().IntVar(&to, "to", 0, "Version to migrate to (default: the latest)")
+	return command
+}
+
+func migrateDownCmd() *cobra.Command {
+	var (
+		to  int
+		yes bool
+	)
+
+	command := &cobra.Command{
+		Use:   "down",
+		Short: "Revert migrations down to a version",
+		Long: `Revert migrations, newest first, until the schema is at the given version.
+
+Reverting a migration drops what it added, including any data stored in it.
+
+Examples:
+  drover migrate down --to 3`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == 0 {
+				return fmt.Errorf("--to is required")
+			}
+			store, err := openUnmigrated()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			from, err := store.SchemaVersion()
+			if err != nil {
+				return err
+			}
+			if to >= from {
+				output.Printf("✅ Already at schema version %d\n", from)
+				return nil
+			}
+
+			migrations, err := store.Migrations()
+			if err != nil {
+				return err
+			}
+			output.Printf("Reverting %d migration(s):\n", from-to)
+			for i := from; i > to; i-- {
+				m := migrations[i-1]
+				output.Printf("  - %d %s\n", m.Version, m.Name)
+			}
+			if !confirm("Revert these migrations? Data they added is lost.", yes) {
+				return nil
+			}
+
+			if err := store.MigrateTo(to); err != nil {
+				return err
+			}
+			output.Printf("✅ Migrated schema from version %d down to %d\n", from, to)
+			return nil
+		},
+	}
+	command.Flags().IntVar(&to, "to", 0, "Version to migrate down to")
+	command.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+	return command
+}
+
+func runMigrateStatus() error {
+	store, err := openUnmigrated()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	migrations, err := store.Migrations()
+	if err != nil {
+		return err
+	}
+	current, err := store.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	latest, err := db.LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	output.Printf("Schema version %d (latest %d)\n\n", current, latest)
+	for _, m := range migrations {
+		state := "pending"
+		switch {
+		case m.Unknown:
+			state = "applied by a newer drover"
+		case m.Applied:
+			state = "applied " + m.AppliedAt.Format("2006-01-02 15:04")
+		}
+		output.Printf("  %4d  %-32s %s\n", m.Version, m.Name, state)
+	}
+	if current > latest {
+		output.Printf("\n⚠️  This drover is older than the database; upgrade drover to use it\n")
+	}
+	return nil
+}
+
+// openUnmigrated opens the project database without migrating it first
+func openUnmigrated() (*db.Store, error) {
+	dir, err := findProjectDir()
+	if err != nil {
+		return nil, err
+	}
+	store, err := db.OpenUnmigrated(filepath.Join(dir, ".drover", "drover.db"))
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return store, nil
+}
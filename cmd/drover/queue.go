@@ -0,0 +1,224 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// queueCmd shows and reorders the run queue
+func queueCmd() *cobra.Command {
+	var epicID string
+
+	command := &cobra.Command{
+		Use:   "queue",
+		Short: "Show and reorder the run queue",
+		Long: `Show the queued tasks in the order workers will claim them, and reorder them.
+
+Moving a task sets its effective priority, which workers claim by in place of
+its priority. A running 'drover run' picks the new order up on its next claim,
+without restarting. Fix tasks queued for blockers (see 'drover run
+--fix-blockers') rise ahead of the tasks waiting on them, shown as 3↑7;
+moving one puts it where it was moved instead. With --dbos, tasks are enqueued when the run starts, so
+moves apply to the next run.
+
+Examples:
+  drover queue
+  drover queue pin task-123      # Run next
+  drover queue bump task-123     # Ahead of everything not pinned
+  drover queue defer task-123    # Behind everything else
+  drover queue reset task-123    # Back to its own priority`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			tasks, err := store.ListQueue(epicID)
+			if err != nil {
+				return err
+			}
+			if len(tasks) == 0 {
+				output.Println("The queue is empty")
+				return nil
+			}
+
+			escalated, err := store.EscalatedPriorities()
+			if err != nil {
+				return err
+			}
+
+			output.Printf("%-4s  %-16s  %-8s  %-10s  %s\n", "#", "Task", "Priority", "Status", "Title")
+			for i, task := range tasks {
+				priority := fmt.Sprintf("%d", task.Priority)
+				switch p, ok := escalated[task.ID]; {
+				case ok:
+					priority = fmt.Sprintf("%d↑%d", task.Priority, p)
+				case task.EffectivePriority == nil:
+				case *task.EffectivePriority >= db.PinnedPriority:
+					priority = "pinned"
+				default:
+					priority = fmt.Sprintf("%d→%d", task.Priority, *task.EffectivePriority)
+				}
+				output.Printf("%-4d  %-16s  %-8s  %-10s  %s\n", i+1, task.ID, priority, task.Status, task.Title)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&epicID, "epic", "e", "", "Only show tasks in this epic")
+	command.AddCommand(
+		queueMoveCmd(db.QueuePin, "Pin a task to run next"),
+		queueMoveCmd(db.QueueBump, "Move a task ahead of every task not pinned"),
+		queueMoveCmd(db.QueueDefer, "Move a task behind every other task"),
+		queueMoveCmd(db.QueueReset, "Return a task to its own priority"),
+		queueLimitsCmd(),
+	)
+	return command
+}
+
+// queueMoveCmd builds the subcommand applying one queue move
+func queueMoveCmd(move db.QueueMove, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   string(move) + " <task-id>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			if err := store.MoveTask(args[0], move); err != nil {
+				return err
+			}
+
+			tasks, err := store.ListQueue("")
+			if err != nil {
+				return err
+			}
+			for i, task := range tasks {
+				if task.ID == args[0] {
+					output.Printf("📋 Task %s is now #%d of %d in the queue\n", task.ID, i+1, len(tasks))
+					break
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// queueLimitsCmd shows and changes the DBOS task queue's limits on a live
+// deployment
+func queueLimitsCmd() *cobra.Command {
+	var workerConcurrency, globalConcurrency int
+	var rateLimit string
+	var reset bool
+
+	command := &cobra.Command{
+		Use:   "limits",
+		Short: "Show or change the DBOS task queue's limits on a running deployment",
+		Long: `Show or change the limits of the DBOS task queue while 'drover run --dbos'
+is running. Running orchestrators pick changes up within a few seconds and
+apply them to tasks not yet started; tasks in flight finish.
+
+The limits a run started with (--queue-concurrency, --queue-global-concurrency,
+--queue-rate-limit) are also enforced by DBOS, so a running deployment can be
+tightened below them and relaxed back up to them, but raising them further
+takes a restart. 0 lifts a limit. --reset returns to the started limits.
+
+Examples:
+  drover queue limits
+  drover queue limits --worker-concurrency 2
+  drover queue limits --rate-limit 10/1m
+  drover queue limits --reset`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+
+			var change db.QueueLimits
+			changed := false
+			if cmd.Flags().Changed("worker-concurrency") {
+				if workerConcurrency < 0 {
+					return fmt.Errorf("--worker-concurrency must not be negative")
+				}
+				change.WorkerConcurrency, changed = &workerConcurrency, true
+			}
+			if cmd.Flags().Changed("global-concurrency") {
+				if globalConcurrency < 0 {
+					return fmt.Errorf("--global-concurrency must not be negative")
+				}
+				change.GlobalConcurrency, changed = &globalConcurrency, true
+			}
+			if cmd.Flags().Changed("rate-limit") {
+				limit, period, err := config.ParseRateLimit(rateLimit)
+				if err != nil {
+					return fmt.Errorf("--rate-limit: %w", err)
+				}
+				change.RateLimit, change.RatePeriod, changed = &limit, &period, true
+			}
+
+			switch {
+			case reset && changed:
+				return fmt.Errorf("--reset can't be combined with new limits")
+			case reset:
+				if err := store.ResetQueueLimits(); err != nil {
+					return err
+				}
+				output.Println("🎚️  Queue limits reset; running orchestrators return to the limits they started with")
+				return nil
+			case changed:
+				if err := store.SetQueueLimits(change); err != nil {
+					return err
+				}
+			}
+
+			limits, err := store.GetQueueLimits()
+			if err != nil {
+				return err
+			}
+			if limits == nil {
+				output.Println("No queue limits set; running orchestrators use the limits they started with")
+				return nil
+			}
+			show := func(n *int) string {
+				switch {
+				case n == nil:
+					return "as started"
+				case *n == 0:
+					return "unlimited"
+				}
+				return fmt.Sprint(*n)
+			}
+			rate := show(limits.RateLimit)
+			if limits.RateLimit != nil && *limits.RateLimit > 0 && limits.RatePeriod != nil {
+				rate = fmt.Sprintf("%d per %v", *limits.RateLimit, *limits.RatePeriod)
+			}
+			if changed {
+				output.Println("🎚️  Queue limits updated")
+			}
+			output.Printf("Worker concurrency:  %s\n", show(limits.WorkerConcurrency))
+			output.Printf("Global concurrency:  %s\n", show(limits.GlobalConcurrency))
+			output.Printf("Rate limit:          %s\n", rate)
+			output.Printf("Updated:             %s\n", limits.UpdatedAt.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	command.Flags().IntVar(&workerConcurrency, "worker-concurrency", 0, "Tasks each orchestrator runs at once (0 = no limit)")
+	command.Flags().IntVar(&globalConcurrency, "global-concurrency", 0, "Tasks running at once across orchestrators (0 = no limit)")
+	command.Flags().StringVar(&rateLimit, "rate-limit", "", "Task starts allowed per period, e.g. \"10/1m\" (0 = no limit)")
+	command.Flags().BoolVar(&reset, "reset", false, "Drop the limits set here and return to the ones runs started with")
+	return command
+}
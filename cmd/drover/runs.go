@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/cloud-shuttle/drover/internal/workflow"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// runsCmd lists the reports past runs left under .drover/runs
+func runsCmd() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "runs",
+		Short: "Browse the reports of past runs",
+		Long: `Browse the reports of past runs.
+
+Every 'drover run' writes a report when it ends, under .drover/runs/<id>/:
+report.json for tools and report.md for people. It covers each task the run
+attempted: what it ended as, how many attempts and how long it took, what it
+cost, whether its changes were merged (or pushed, in PR mode) and, for tasks
+that didn't complete, the last error. Run IDs are the run's start time.
+
+Examples:
+  drover runs
+  drover runs list
+  drover runs show
+  drover runs show 20261016-142233
+  drover runs show 20261016-142233 --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listRuns()
+		},
+	}
+
+	command.AddCommand(runsListCmd(), runsShowCmd())
+	return command
+}
+
+// runsListCmd lists the past runs, newest first
+func runsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List past runs, newest first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listRuns()
+		},
+	}
+}
+
+// listRuns prints one line per run report
+func listRuns() error {
+	projectDir, err := findProjectDir()
+	if err != nil {
+		return err
+	}
+
+	reports, err := workflow.LoadRunReports(projectDir)
+	if err != nil {
+		return err
+	}
+	if len(reports) == 0 {
+		output.Println("No runs recorded yet; 'drover run' writes a report when it ends")
+		return nil
+	}
+
+	output.Printf("%-18s  %-19s  %-10s  %-11s  %5s  %9s  %6s  %9s\n",
+		"Run", "Started", "Duration", "Outcome", "Tasks", "Completed", "Failed", "Cost")
+	for _, r := range reports {
+		output.Printf("%-18s  %-19s  %-10s  %-11s  %5d  %9d  %6d  %9s\n", r.ID,
+			formatTimestamp(r.StartedAt), r.Duration().Round(time.Second), r.Outcome, len(r.Tasks),
+			r.Count(types.TaskStatusCompleted), r.Count(types.TaskStatusFailed), fmt.Sprintf("$%.2f", r.CostUSD))
+	}
+	output.Printf("\n%d run(s); 'drover runs show <run>' prints one\n", len(reports))
+	return nil
+}
+
+// runsShowCmd prints one run's report
+func runsShowCmd() *cobra.Command {
+	var asJSON bool
+
+	command := &cobra.Command{
+		Use:   "show [run]",
+		Short: "Show a run's report (default: the latest run)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectDir, err := findProjectDir()
+			if err != nil {
+				return err
+			}
+
+			var report *workflow.RunReport
+			if len(args) == 1 {
+				if report, err = workflow.LoadRunReport(projectDir, args[0]); err != nil {
+					return err
+				}
+			} else {
+				reports, err := workflow.LoadRunReports(projectDir)
+				if err != nil {
+					return err
+				}
+				if len(reports) == 0 {
+					return fmt.Errorf("no runs recorded yet")
+				}
+				report = reports[0]
+			}
+
+			if asJSON {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("encoding run report: %w", err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return nil
+			}
+			fmt.Fprint(cmd.OutOrStdout(), report.Markdown())
+			return nil
+		},
+	}
+
+	command.Flags().BoolVar(&asJSON, "json", false, "Print the report as JSON")
+	return command
+}
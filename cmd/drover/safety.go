@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/cloud-shuttle/drover/internal/workflow"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// confirm asks a yes/no question, defaulting to no. With yes set the question
+// is skipped. Without a terminal to answer, the answer is no
+func confirm(prompt string, yes bool) bool {
+	if yes {
+		return true
+	}
+	output.Printf("\n%s [y/N] ", prompt)
+	var response string
+	fmt.Scanln(&response)
+	if response != "y" && response != "Y" {
+		output.Println("Aborted")
+		return false
+	}
+	return true
+}
+
+// blastRadius is what a destructive command would affect beyond the rows it
+// changes: work still running and work not yet committed
+type blastRadius struct {
+	live  map[string]int // Task ID -> PID of a worker still running it
+	dirty map[string]int // Task ID -> files with uncommitted changes in its worktree
+}
+
+// assessBlastRadius checks tasks for live workers and, when gitMgr is set,
+// for uncommitted changes in their worktrees
+func assessBlastRadius(store *db.Store, gitMgr *git.WorktreeManager, taskIDs []string) *blastRadius {
+	br := &blastRadius{live: make(map[string]int), dirty: make(map[string]int)}
+	for _, id := range taskIDs {
+		if pid, ok := liveWorker(store, id); ok {
+			br.live[id] = pid
+		}
+		if gitMgr == nil {
+			continue
+		}
+		if n, err := gitMgr.UncommittedFiles(id); err == nil && n > 0 {
+			br.dirty[id] = n
+		}
+	}
+	return br
+}
+
+// liveWorker returns the PID of the worker running a task, if that process
+// is still alive. Workers record their PID in the task's crash-recovery
+// checkpoint when they start it
+func liveWorker(store *db.Store, taskID string) (int, bool) {
+	checkpoint, err := store.GetCheckpoint(taskID)
+	if err != nil || checkpoint == nil {
+		return 0, false
+	}
+	if checkpoint.State != types.TaskStatusInProgress || checkpoint.WorkerPID <= 0 {
+		return 0, false
+	}
+	return checkpoint.WorkerPID, workflow.ProcessAlive(checkpoint.WorkerPID)
+}
+
+// print lists the live workers and dirty worktrees, if any
+func (br *blastRadius) print(titles map[string]string) {
+	if len(br.live) > 0 {
+		output.Printf("\n⚡ %d task(s) still running:\n", len(br.live))
+		for _, id := range sortedKeys(br.live) {
+			output.Printf("  - %s: %s (worker pid %d)\n", id, titles[id], br.live[id])
+		}
+	}
+	if len(br.dirty) > 0 {
+		output.Printf("\n✏️  %d worktree(s) with uncommitted changes:\n", len(br.dirty))
+		for _, id := range sortedKeys(br.dirty) {
+			output.Printf("  - %s: %s (%d file(s))\n", id, titles[id], br.dirty[id])
+		}
+	}
+}
+
+// refuseLive returns an error when tasks still have live workers, unless force
+func (br *blastRadius) refuseLive(force bool, action string) error {
+	if len(br.live) == 0 || force {
+		return nil
+	}
+	return fmt.Errorf("refusing to %s %d task(s) whose workers are still running; stop them first or pass --force", action, len(br.live))
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
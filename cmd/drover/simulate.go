@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/output"
+	"github.com/cloud-shuttle/drover/internal/simulate"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// simulateHistoryLimit bounds how many recent task durations are resampled
+const simulateHistoryLimit = 500
+
+func simulateCmd() *cobra.Command {
+	var (
+		epicID          string
+		workers         []int
+		trials          int
+		costPerHour     float64
+		defaultDuration time.Duration
+		seed            uint64
+		schedule        string
+	)
+
+	command := &cobra.Command{
+		Use:   "simulate",
+		Short: "Estimate run time and cost from the task graph without executing",
+		Long: `Estimate how long and how much a run will take, without executing anything.
+
+Replays the dependency graph of the unfinished tasks many times (Monte Carlo),
+drawing each task's duration from the durations of recently completed tasks,
+with workers claiming ready tasks as 'drover run' does in the configured
+schedule (--schedule compares another). Sub-tasks
+run inside their parent, and scheduled tasks wait for their not-before time.
+With fewer than 5 completed tasks on record, durations are drawn around
+--default-duration instead.
+
+Reports the expected makespan (p50/p90), agent cost percentiles from
+--cost-per-hour, worker utilization, and how much each extra worker helps,
+so a run can be sized before spending money.
+
+Examples:
+  drover simulate
+  drover simulate --workers 2,4,6,8 --cost-per-hour 6
+  drover simulate --epic epic-a1b2 --trials 5000
+  drover simulate --schedule critical-path`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, store, err := requireProject()
+			if err != nil {
+				return err
+			}
+			defer store.Close()
+			if schedule == "" {
+				schedule = cfg.Schedule
+			}
+			claimSchedule, err := applySchedule(dir, store, schedule)
+			if err != nil {
+				return err
+			}
+
+			tasks, err := store.ListTasksByEpic(epicID)
+			if err != nil {
+				return fmt.Errorf("listing tasks: %w", err)
+			}
+			deps, err := store.ListAllDependencies()
+			if err != nil {
+				return fmt.Errorf("listing dependencies: %w", err)
+			}
+			graph := simulationGraph(tasks, deps)
+			if len(graph) == 0 {
+				output.Println("Nothing to simulate: every task has finished")
+				return nil
+			}
+
+			history, err := store.CompletedTaskDurations(simulateHistoryLimit)
+			if err != nil {
+				return err
+			}
+
+			if len(workers) == 0 {
+				workers = defaultWorkerSweep(cfg.Workers)
+			}
+			sort.Ints(workers)
+			if seed == 0 {
+				seed = uint64(time.Now().UnixNano())
+			}
+
+			report, err := simulate.Run(graph, simulate.Config{
+				Workers:         workers,
+				Trials:          trials,
+				History:         history,
+				DefaultDuration: defaultDuration,
+				CostPerHour:     costPerHour,
+				Seed:            seed,
+				CriticalPath:    claimSchedule == db.ScheduleCriticalPath,
+			})
+			if err != nil {
+				return err
+			}
+			printSimulation(report, len(history), costPerHour)
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&epicID, "epic", "e", "", "Only simulate tasks in this epic")
+	command.Flags().IntSliceVarP(&workers, "workers", "w", nil, "Worker counts to compare (default: around the configured workers)")
+	command.Flags().IntVar(&trials, "trials", 1000, "Monte Carlo trials per worker count")
+	command.Flags().Float64Var(&costPerHour, "cost-per-hour", 0, "Agent spend per hour of task execution, in dollars")
+	command.Flags().DurationVar(&defaultDuration, "default-duration", 10*time.Minute, "Typical task duration when there is too little history")
+	command.Flags().StringVar(&schedule, "schedule", "", "Claim order to simulate: priority or critical-path; other schedules simulate as priority (default: as 'drover run')")
+	command.Flags().Uint64Var(&seed, "seed", 0, "Random seed, for reproducible estimates (default: random)")
+	return command
+}
+
+// simulationGraph converts the unfinished top-level tasks into the simulated
+// graph. Each pending sub-task adds an execution to its parent
+func simulationGraph(tasks []*types.Task, deps []types.TaskDependency) []simulate.Task {
+	finished := func(t *types.Task) bool {
+		switch t.Status {
+		case types.TaskStatusCompleted, types.TaskStatusFailed, types.TaskStatusCancelled:
+			return true
+		}
+		return false
+	}
+
+	steps := make(map[string]int)
+	for _, t := range tasks {
+		if t.ParentID != "" && !finished(t) {
+			steps[t.ParentID]++
+		}
+	}
+	blockedBy := make(map[string][]string)
+	for _, d := range deps {
+		blockedBy[d.TaskID] = append(blockedBy[d.TaskID], d.BlockedBy)
+	}
+
+	now := time.Now()
+	var graph []simulate.Task
+	for i, t := range tasks {
+		if t.ParentID != "" || finished(t) {
+			continue
+		}
+		var notBefore time.Duration
+		if t.ScheduledAt != nil {
+			notBefore = max(time.Unix(*t.ScheduledAt, 0).Sub(now), 0)
+		}
+		graph = append(graph, simulate.Task{
+			ID:        t.ID,
+			Priority:  t.ClaimPriority(),
+			Order:     i, // Tasks are listed oldest first, the order workers claim ties in
+			BlockedBy: blockedBy[t.ID],
+			Steps:     1 + steps[t.ID],
+			NotBefore: notBefore,
+		})
+	}
+	return graph
+}
+
+// defaultWorkerSweep returns worker counts from half to double the configured count
+func defaultWorkerSweep(configured int) []int {
+	configured = max(configured, 1)
+	seen := make(map[int]bool)
+	var sweep []int
+	for _, w := range []int{1, configured / 2, configured, configured + configured/2, configured * 2} {
+		if w >= 1 && !seen[w] {
+			seen[w] = true
+			sweep = append(sweep, w)
+		}
+	}
+	return sweep
+}
+
+func printSimulation(report *simulate.Report, historySize int, costPerHour float64) {
+	output.Println("\n🎲 Drover Simulation")
+	output.Println("════════════════════")
+	output.Printf("\nTasks:      %d\n", report.Tasks)
+	if report.FromHistory {
+		output.Printf("Durations:  resampled from %d completed tasks\n", historySize)
+	} else {
+		output.Printf("Durations:  estimated (only %d completed tasks on record)\n", historySize)
+	}
+	if len(report.Unschedulable) > 0 {
+		output.Printf("⚠️  %d task(s) can never start (dependency cycle): %v\n", len(report.Unschedulable), report.Unschedulable)
+	}
+
+	output.Println()
+	if costPerHour > 0 {
+		output.Printf("%-8s  %-10s  %-10s  %-28s  %s\n", "Workers", "p50", "p90", "Cost p10 / p50 / p90", "Utilization")
+	} else {
+		output.Printf("%-8s  %-10s  %-10s  %s\n", "Workers", "p50", "p90", "Utilization")
+	}
+	for _, e := range report.Estimates {
+		p50, p90 := e.MakespanP50.Round(time.Minute).String(), e.MakespanP90.Round(time.Minute).String()
+		if costPerHour > 0 {
+			cost := fmt.Sprintf("$%.2f / $%.2f / $%.2f", e.CostP10, e.CostP50, e.CostP90)
+			output.Printf("%-8d  %-10s  %-10s  %-28s  %.0f%%\n", e.Workers, p50, p90, cost, e.Utilization*100)
+		} else {
+			output.Printf("%-8d  %-10s  %-10s  %.0f%%\n", e.Workers, p50, p90, e.Utilization*100)
+		}
+	}
+
+	if lines := report.Sensitivity(); len(lines) > 0 {
+		output.Println()
+		for _, line := range lines {
+			output.Printf("  • %s\n", line)
+		}
+	}
+	if costPerHour <= 0 {
+		output.Println("\n💡 Pass --cost-per-hour to estimate spend")
+	}
+}
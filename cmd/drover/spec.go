@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/cloud-shuttle/drover/internal/llmproxy/client"
+	"github.com/cloud-shuttle/drover/internal/output"
 	"github.com/cloud-shuttle/drover/internal/spec"
 	"github.com/spf13/cobra"
 )
@@ -69,11 +70,11 @@ Examples:
 				return fmt.Errorf("parsing input: %w", err)
 			}
 
-			fmt.Printf("📄 Analyzing %d file(s)\n", len(files))
+			output.Printf("📄 Analyzing %d file(s)\n", len(files))
 			for _, f := range files {
-				fmt.Printf("   - %s\n", f)
+				output.Printf("   - %s\n", f)
 			}
-			fmt.Println()
+			output.Println()
 
 			apiKey := os.Getenv("ANTHROPIC_API_KEY")
 			if apiKey == "" {
@@ -92,7 +93,7 @@ Examples:
 			if directAPI {
 				// Use direct Anthropic API
 				analyzer = spec.NewAnalyzerWithDirectAPI(apiKey, model)
-				fmt.Println("🤖 Analyzing specification with AI (Direct API)...")
+				output.Println("🤖 Analyzing specification with AI (Direct API)...")
 			} else {
 				// Setup LLM client via proxy
 				baseURL := os.Getenv("DROVER_LLM_PROXY_URL")
@@ -112,19 +113,19 @@ Examples:
 
 				_, healthErr := llmClient.GetHealth(ctx)
 				if healthErr != nil {
-					fmt.Printf("⚠️  LLM proxy server not available at %s\n", baseURL)
-					fmt.Printf("   Error: %v\n\n", healthErr)
-					fmt.Println("💡 Options:")
-					fmt.Println("   1. Start the proxy server: drover proxy serve")
-					fmt.Println("   2. Use direct API: drover spec spec.md --direct-api")
-					fmt.Println()
+					output.Printf("⚠️  LLM proxy server not available at %s\n", baseURL)
+					output.Printf("   Error: %v\n\n", healthErr)
+					output.Println("💡 Options:")
+					output.Println("   1. Start the proxy server: drover proxy serve")
+					output.Println("   2. Use direct API: drover spec spec.md --direct-api")
+					output.Println()
 					return fmt.Errorf("LLM proxy server not available")
 				}
 
 				analyzer = spec.NewAnalyzer(llmClient, model)
-				fmt.Println("🤖 Analyzing specification with AI (via proxy)...")
+				output.Println("🤖 Analyzing specification with AI (via proxy)...")
 			}
-			fmt.Printf("   Model: %s\n", model)
+			output.Printf("   Model: %s\n", model)
 
 			// Analyze the spec
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
@@ -136,28 +137,28 @@ Examples:
 			}
 
 			// Show preview
-			fmt.Println("\n📋 Generated Plan:")
-			fmt.Println("════════════════════════════════════════")
+			output.Println("\n📋 Generated Plan:")
+			output.Println("════════════════════════════════════════")
 			printAnalysis(analysis)
 
 			if dryRun {
-				fmt.Println("\n🔍 Dry-run mode - no changes made")
+				output.Println("\n🔍 Dry-run mode - no changes made")
 				return nil
 			}
 
 			// Confirm unless --yes flag
 			if !yes {
-				fmt.Print("\n✅ Create these epics and tasks? [y/N] ")
+				output.Print("\n✅ Create these epics and tasks? [y/N] ")
 				var response string
 				fmt.Scanln(&response)
 				if response != "y" && response != "Y" {
-					fmt.Println("❌ Cancelled")
+					output.Println("❌ Cancelled")
 					return nil
 				}
 			}
 
 			// Write to database
-			fmt.Println("\n💾 Creating epics and tasks...")
+			output.Println("\n💾 Creating epics and tasks...")
 			writer := spec.NewWriter(store)
 			result, err := writer.WriteAnalysis(analysis)
 			if err != nil {
@@ -165,13 +166,13 @@ Examples:
 			}
 
 			// Show results
-			fmt.Println("\n✅ Successfully created:")
-			fmt.Printf("   %d epics\n", len(result.Epics))
-			fmt.Printf("   %d tasks\n", len(result.Tasks))
-			fmt.Printf("   %d subtasks\n", len(result.SubTasks))
-			fmt.Println("\nEpic IDs:")
+			output.Println("\n✅ Successfully created:")
+			output.Printf("   %d epics\n", len(result.Epics))
+			output.Printf("   %d tasks\n", len(result.Tasks))
+			output.Printf("   %d subtasks\n", len(result.SubTasks))
+			output.Println("\nEpic IDs:")
 			for _, epic := range result.Epics {
-				fmt.Printf("   - %s: %s\n", epic.ID, epic.Title)
+				output.Printf("   - %s: %s\n", epic.ID, epic.Title)
 			}
 
 			return nil
@@ -189,25 +190,28 @@ Examples:
 // printAnalysis displays the analysis in a readable format
 func printAnalysis(analysis *spec.SpecAnalysis) {
 	for i, epic := range analysis.Epics {
-		fmt.Printf("\n📌 Epic %d: %s\n", i+1, epic.Title)
-		fmt.Printf("   %s\n", epic.Description)
+		output.Printf("\n📌 Epic %d: %s\n", i+1, epic.Title)
+		output.Printf("   %s\n", epic.Description)
 
 		for j, task := range epic.Tasks {
-			fmt.Printf("\n   [%d] %s\n", j+1, task.Title)
-			fmt.Printf("       Type: %s | Priority: %d\n", task.Type, task.Priority)
-			fmt.Printf("       Tests: %s/%s\n", task.TestMode, task.TestScope)
+			output.Printf("\n   [%d] %s\n", j+1, task.Title)
+			output.Printf("       Type: %s | Priority: %d\n", task.Type, task.Priority)
+			output.Printf("       Tests: %s/%s\n", task.TestMode, task.TestScope)
+			if task.MutexKey != "" {
+				output.Printf("       Mutex key: %s\n", task.MutexKey)
+			}
 
 			if len(task.AcceptanceCriteria) > 0 {
-				fmt.Printf("       Acceptance Criteria:\n")
+				output.Printf("       Acceptance Criteria:\n")
 				for _, ac := range task.AcceptanceCriteria {
-					fmt.Printf("         ✓ %s\n", ac)
+					output.Printf("         ✓ %s\n", ac)
 				}
 			}
 
 			if len(task.SubTasks) > 0 {
-				fmt.Printf("       Subtasks (%d):\n", len(task.SubTasks))
+				output.Printf("       Subtasks (%d):\n", len(task.SubTasks))
 				for k, st := range task.SubTasks {
-					fmt.Printf("         %d. %s\n", k+1, st.Title)
+					output.Printf("         %d. %s\n", k+1, st.Title)
 				}
 			}
 		}
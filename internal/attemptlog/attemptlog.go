@@ -0,0 +1,222 @@
+// Package attemptlog keeps the agent output of each task attempt in
+// .drover/logs/<task>/<attempt>.log, rotating a log by size and removing old
+// ones
+package attemptlog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Dir is where attempt logs are kept, relative to the project directory
+const Dir = ".drover/logs"
+
+const (
+	// DefaultMaxSize is the size a log is rotated at when none is configured
+	DefaultMaxSize int64 = 10 << 20
+
+	// DefaultMaxFiles is how many rotated parts of a log are kept when none
+	// is configured
+	DefaultMaxFiles = 3
+)
+
+// Rotation is when a log is rotated and how much of it is kept
+type Rotation struct {
+	MaxSize  int64 // A log reaching this size moves to <log>.1 (0 = DefaultMaxSize)
+	MaxFiles int   // Rotated parts kept, <log>.1 the newest (0 = DefaultMaxFiles, <0 = none)
+}
+
+// withDefaults fills in the settings left unset
+func (r Rotation) withDefaults() Rotation {
+	if r.MaxSize <= 0 {
+		r.MaxSize = DefaultMaxSize
+	}
+	if r.MaxFiles == 0 {
+		r.MaxFiles = DefaultMaxFiles
+	}
+	if r.MaxFiles < 0 {
+		r.MaxFiles = 0
+	}
+	return r
+}
+
+// Path returns the log of a task's attempt, relative to the project directory
+func Path(taskID string, attempt int) string {
+	return filepath.Join(Dir, taskID, fmt.Sprintf("%d.log", attempt))
+}
+
+// Writer writes a log, moving it to <log>.1 and starting it afresh each
+// time it reaches the rotation's size. Older parts shift up, <log>.2 and so
+// on, and those past the rotation's count are removed
+type Writer struct {
+	path     string
+	rotation Rotation
+	file     *os.File
+	size     int64
+}
+
+// Create creates the log at path, replacing one there and its rotated parts
+func Create(path string, rotation Rotation) (*Writer, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	for _, part := range parts(path) {
+		if part != path {
+			os.Remove(part)
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{path: path, rotation: rotation.withDefaults(), file: file}, nil
+}
+
+// Write adds p to the log, rotating it first when p would take it past the
+// rotation's size. A write is never split, so a line stays in one part
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.size > 0 && w.size+int64(len(p)) > w.rotation.MaxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate moves the log to <log>.1, shifting the parts before it up
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	if w.rotation.MaxFiles == 0 {
+		os.Remove(w.path)
+	} else {
+		os.Remove(rotated(w.path, w.rotation.MaxFiles))
+		for n := w.rotation.MaxFiles - 1; n >= 1; n-- {
+			os.Rename(rotated(w.path, n), rotated(w.path, n+1))
+		}
+		if err := os.Rename(w.path, rotated(w.path, 1)); err != nil {
+			return err
+		}
+	}
+	file, err := os.Create(w.path)
+	if err != nil {
+		return err
+	}
+	w.file, w.size = file, 0
+	return nil
+}
+
+// Close closes the log
+func (w *Writer) Close() error {
+	return w.file.Close()
+}
+
+// rotated returns the name of the nth rotated part of a log
+func rotated(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
+
+// parts returns the files of a log, oldest first: its rotated parts, then
+// the log itself
+func parts(path string) []string {
+	var found []string
+	for n := 1; ; n++ {
+		if _, err := os.Stat(rotated(path, n)); err != nil {
+			break
+		}
+		found = append(found, rotated(path, n))
+	}
+	for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+		found[i], found[j] = found[j], found[i]
+	}
+	return append(found, path)
+}
+
+// Open opens a log for reading from its oldest rotated part on, so it reads
+// as the whole of what was kept
+func Open(path string) (io.ReadCloser, error) {
+	var files []*os.File
+	var readers []io.Reader
+	for _, part := range parts(path) {
+		file, err := os.Open(part)
+		if err != nil {
+			if part != path && errors.Is(err, os.ErrNotExist) {
+				continue // Rotated away since it was listed
+			}
+			for _, f := range files {
+				f.Close()
+			}
+			return nil, err
+		}
+		files = append(files, file)
+		readers = append(readers, file)
+	}
+	return &multiFile{Reader: io.MultiReader(readers...), files: files}, nil
+}
+
+// multiFile reads several files as one
+type multiFile struct {
+	io.Reader
+	files []*os.File
+}
+
+// Close closes every file
+func (m *multiFile) Close() error {
+	var errs []error
+	for _, f := range m.files {
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Prune removes the attempt logs under the project directory last written
+// more than maxAge ago, and the task directories they leave empty, returning
+// how many logs it removed
+func Prune(projectDir string, maxAge time.Duration) (int, error) {
+	root := filepath.Join(projectDir, Dir)
+	tasks, err := os.ReadDir(root)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	cutoff := time.Now().Add(-maxAge)
+	removed := 0
+	for _, task := range tasks {
+		if !task.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, task.Name())
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return removed, err
+		}
+		kept := len(entries)
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || entry.IsDir() || !strings.Contains(entry.Name(), ".log") || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+				return removed, err
+			}
+			kept--
+			if strings.HasSuffix(entry.Name(), ".log") {
+				removed++
+			}
+		}
+		if kept == 0 {
+			os.Remove(dir)
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,89 @@
+package attemptlog
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriter_Rotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "task-1", "1.log")
+	w, err := Create(path, Rotation{MaxSize: 10, MaxFiles: 2})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	for _, line := range []string{"one\n", "two\n", "three\n", "four\n", "five\n", "six\n"} {
+		if _, err := io.WriteString(w, line); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	want := map[string]string{path + ".2": "three\n", path + ".1": "four\nfive\n", path: "six\n"}
+	for file, content := range want {
+		data, err := os.ReadFile(file)
+		if err != nil || string(data) != content {
+			t.Errorf("%s = %q (%v), want %q", filepath.Base(file), data, err, content)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected parts past MaxFiles to be removed, got %v", err)
+	}
+
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer r.Close()
+	all, _ := io.ReadAll(r)
+	if string(all) != "three\nfour\nfive\nsix\n" {
+		t.Errorf("Open read %q, want the kept parts oldest first", all)
+	}
+
+	// Writing the log again starts it afresh
+	w, err = Create(path, Rotation{})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	io.WriteString(w, "retry\n")
+	w.Close()
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("Expected the earlier rotated parts to be removed, got %v", err)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	projectDir := t.TempDir()
+	old := filepath.Join(projectDir, Path("task-1", 1))
+	recent := filepath.Join(projectDir, Path("task-2", 1))
+	for _, file := range []string{old, old + ".1", recent} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("output\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stale := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(old, stale, stale)
+	os.Chtimes(old+".1", stale, stale)
+
+	removed, err := Prune(projectDir, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Prune removed %d logs, want 1", removed)
+	}
+	if _, err := os.Stat(filepath.Dir(old)); !os.IsNotExist(err) {
+		t.Errorf("Expected task-1's emptied directory to be removed, got %v", err)
+	}
+	if data, err := os.ReadFile(recent); err != nil || !strings.Contains(string(data), "output") {
+		t.Errorf("Expected task-2's recent log to stay, got %v", err)
+	}
+}
@@ -36,6 +36,12 @@ type Controller struct {
 	// Backoff state
 	currentBackoff    time.Duration
 	backoffMultiplier float64
+
+	// Ramp-up state: workers are admitted one at a time at startup
+	rampCeiling      int           // Current ramp limit (0 = not ramping)
+	rampLastStep     time.Time     // When the ramp limit last changed
+	lastNegative     time.Time     // Last rate-limit/slow/error signal
+	coldStartEstimate time.Duration // EWMA of observed worker cold-start time
 }
 
 // ControllerConfig holds backpressure controller configuration
@@ -53,6 +59,10 @@ type ControllerConfig struct {
 	MemoryThresholdMB     int64 // Minimum available MB before throttling
 	MemoryCriticalMB      int64 // Critical memory threshold - stop spawning
 	WorkerRSSLimitMB      int64 // Per-worker RSS limit in MB
+
+	// Staggered ramp-up: start one worker, then admit another every interval
+	// while signals stay OK (0 disables ramp-up)
+	RampUpInterval time.Duration
 }
 
 // DefaultControllerConfig returns default backpressure controller configuration
@@ -98,13 +108,94 @@ func NewController(cfg ControllerConfig) *Controller {
 		cfg.SlowCountThreshold = 3
 	}
 
-	return &Controller{
+	c := &Controller{
 		config:            cfg,
 		maxInFlight:       cfg.InitialConcurrency,
 		configuredMax:     cfg.MaxConcurrency,
 		currentBackoff:    cfg.RateLimitBackoff,
 		backoffMultiplier: 2.0,
 	}
+	c.startRamp(time.Now())
+	return c
+}
+
+// coldStartAlpha weights new cold-start observations in the moving average
+const coldStartAlpha = 0.3
+
+// startRamp begins a staggered ramp-up if configured
+func (c *Controller) startRamp(now time.Time) {
+	c.rampCeiling = 0
+	if c.config.RampUpInterval > 0 && c.maxInFlight > 1 {
+		c.rampCeiling = 1
+		c.rampLastStep = now
+	}
+}
+
+// rampInterval returns the delay between ramp steps
+// Workers that take longer to warm up than the configured interval are given
+// time to finish their cold start before the next one is admitted
+func (c *Controller) rampInterval() time.Duration {
+	if c.coldStartEstimate > c.config.RampUpInterval {
+		return c.coldStartEstimate
+	}
+	return c.config.RampUpInterval
+}
+
+// advanceRamp raises the ramp limit by one if a full interval passed without negative signals
+func (c *Controller) advanceRamp(now time.Time) {
+	if c.rampCeiling == 0 {
+		return
+	}
+	if c.rampCeiling >= c.maxInFlight {
+		c.rampCeiling = 0
+		log.Printf("[backpressure] ramp-up complete at concurrency %d", c.maxInFlight)
+		return
+	}
+	if now.Sub(c.rampLastStep) < c.rampInterval() {
+		return
+	}
+	if c.lastNegative.After(c.rampLastStep) {
+		// Hold the ramp: wait a full interval of clean signals before adding load
+		c.rampLastStep = now
+		log.Printf("[backpressure] ramp-up holding at %d after negative signal", c.rampCeiling)
+		return
+	}
+
+	c.rampCeiling++
+	c.rampLastStep = now
+	log.Printf("[backpressure] ramp-up: admitting worker %d/%d", c.rampCeiling, c.maxInFlight)
+	if c.rampCeiling >= c.maxInFlight {
+		c.rampCeiling = 0
+		log.Printf("[backpressure] ramp-up complete at concurrency %d", c.maxInFlight)
+	}
+}
+
+// effectiveLimit returns the concurrency limit including any active ramp-up
+func (c *Controller) effectiveLimit() int {
+	if c.rampCeiling > 0 && c.rampCeiling < c.maxInFlight {
+		return c.rampCeiling
+	}
+	return c.maxInFlight
+}
+
+// ObserveColdStart records how long a worker took from claim to agent start
+// (worktree creation, dependency setup); used to pace the ramp-up
+func (c *Controller) ObserveColdStart(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.coldStartEstimate == 0 {
+		c.coldStartEstimate = d
+		return
+	}
+	c.coldStartEstimate = time.Duration(coldStartAlpha*float64(d) + (1-coldStartAlpha)*float64(c.coldStartEstimate))
+}
+
+// EstimatedColdStart returns the moving average of observed cold-start times
+func (c *Controller) EstimatedColdStart() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.coldStartEstimate
 }
 
 // OnWorkerSignal processes a worker signal and adjusts backpressure state
@@ -114,12 +205,15 @@ func (c *Controller) OnWorkerSignal(signal WorkerSignal) {
 
 	switch signal {
 	case SignalRateLimited:
+		c.lastNegative = time.Now()
 		c.handleRateLimit()
 
 	case SignalSlowResponse:
+		c.lastNegative = time.Now()
 		c.handleSlowResponse()
 
 	case SignalAPIError:
+		c.lastNegative = time.Now()
 		c.handleAPIError()
 
 	case SignalOK:
@@ -188,16 +282,19 @@ func (c *Controller) handleOK() {
 
 // CanSpawn checks if a new worker can be spawned based on backpressure state
 func (c *Controller) CanSpawn() bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
 
 	// Check if we're in backoff period
-	if time.Now().Before(c.rateLimitUntil) {
+	if now.Before(c.rateLimitUntil) {
 		return false
 	}
 
-	// Check if we're at concurrency limit
-	if c.currentInFlight >= c.maxInFlight {
+	// Check if we're at concurrency limit (including staggered ramp-up)
+	c.advanceRamp(now)
+	if c.currentInFlight >= c.effectiveLimit() {
 		return false
 	}
 
@@ -279,6 +376,9 @@ type Stats struct {
 	BackoffUntil    time.Time // When backoff ends
 	InBackoff       bool      // Currently in backoff
 	ConsecutiveSlow int       // Count of slow responses
+	RampingUp       bool          // Staggered ramp-up in progress
+	RampLimit       int           // Current ramp-up limit (0 when not ramping)
+	EstimatedColdStart time.Duration // Moving average of worker cold-start time
 }
 
 // GetStats returns current statistics
@@ -292,7 +392,70 @@ func (c *Controller) GetStats() Stats {
 		BackoffUntil:    c.rateLimitUntil,
 		InBackoff:       time.Now().Before(c.rateLimitUntil),
 		ConsecutiveSlow: c.consecutiveSlow,
+		RampingUp:       c.rampCeiling > 0,
+		RampLimit:       c.rampCeiling,
+		EstimatedColdStart: c.coldStartEstimate,
+	}
+}
+
+// Limits are the settings of a controller that can change while it runs
+type Limits struct {
+	MinConcurrency   int
+	MaxConcurrency   int
+	RateLimitBackoff time.Duration
+	MaxBackoff       time.Duration
+}
+
+// Limits returns the controller's current limits
+func (c *Controller) Limits() Limits {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Limits{
+		MinConcurrency:   c.config.MinConcurrency,
+		MaxConcurrency:   c.config.MaxConcurrency,
+		RateLimitBackoff: c.config.RateLimitBackoff,
+		MaxBackoff:       c.config.MaxBackoff,
+	}
+}
+
+// SetLimits changes the controller's limits while it runs. Zero fields keep
+// their value; the current concurrency is clamped into the new range
+func (c *Controller) SetLimits(l Limits) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if l.MinConcurrency > 0 {
+		c.config.MinConcurrency = l.MinConcurrency
+	}
+	if l.MaxConcurrency > 0 {
+		c.config.MaxConcurrency = l.MaxConcurrency
+		c.configuredMax = l.MaxConcurrency
+	}
+	if c.config.MaxConcurrency < c.config.MinConcurrency {
+		c.config.MaxConcurrency = c.config.MinConcurrency
+		c.configuredMax = c.config.MinConcurrency
+	}
+	if l.RateLimitBackoff > 0 {
+		if c.currentBackoff == c.config.RateLimitBackoff {
+			c.currentBackoff = l.RateLimitBackoff
+		}
+		c.config.RateLimitBackoff = l.RateLimitBackoff
+	}
+	if l.MaxBackoff > 0 {
+		c.config.MaxBackoff = l.MaxBackoff
+		if c.currentBackoff > l.MaxBackoff {
+			c.currentBackoff = l.MaxBackoff
+		}
+	}
+
+	if c.maxInFlight > c.config.MaxConcurrency {
+		c.maxInFlight = c.config.MaxConcurrency
+	}
+	if c.maxInFlight < c.config.MinConcurrency {
+		c.maxInFlight = c.config.MinConcurrency
 	}
+	log.Printf("[backpressure] limits changed: concurrency %d-%d (now %d), backoff %v up to %v",
+		c.config.MinConcurrency, c.config.MaxConcurrency, c.maxInFlight, c.config.RateLimitBackoff, c.config.MaxBackoff)
 }
 
 // Reset resets the controller to initial state
@@ -305,6 +468,8 @@ func (c *Controller) Reset() {
 	c.consecutiveSlow = 0
 	c.rateLimitUntil = time.Time{}
 	c.currentBackoff = c.config.RateLimitBackoff
+	c.lastNegative = time.Time{}
+	c.startRamp(time.Now())
 
 	log.Printf("[backpressure] controller reset")
 }
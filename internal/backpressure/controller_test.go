@@ -495,3 +495,114 @@ func TestControllerGetBackoffDeadline(t *testing.T) {
 		t.Error("GetBackoffDeadline() is in the past")
 	}
 }
+
+func TestControllerRampUp(t *testing.T) {
+	cfg := ControllerConfig{
+		InitialConcurrency: 3,
+		MinConcurrency:     1,
+		MaxConcurrency:     3,
+		RampUpInterval:     50 * time.Millisecond,
+	}
+	c := NewController(cfg)
+
+	// Only one worker is admitted at first
+	if !c.CanSpawn() {
+		t.Fatal("CanSpawn() = false, want true for the first worker")
+	}
+	c.WorkerStarted()
+	if c.CanSpawn() {
+		t.Error("CanSpawn() = true before the ramp interval elapsed")
+	}
+
+	stats := c.GetStats()
+	if !stats.RampingUp || stats.RampLimit != 1 {
+		t.Errorf("GetStats() RampingUp=%v RampLimit=%d, want true/1", stats.RampingUp, stats.RampLimit)
+	}
+
+	// After one clean interval a second worker is admitted
+	time.Sleep(60 * time.Millisecond)
+	if !c.CanSpawn() {
+		t.Fatal("CanSpawn() = false after ramp interval")
+	}
+	c.WorkerStarted()
+
+	// A negative signal holds the ramp for another interval
+	c.OnWorkerSignal(SignalSlowResponse)
+	time.Sleep(60 * time.Millisecond)
+	if c.CanSpawn() {
+		t.Error("CanSpawn() = true despite a negative signal during ramp-up")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if !c.CanSpawn() {
+		t.Error("CanSpawn() = false after a clean interval following the hold")
+	}
+	c.WorkerStarted()
+
+	if c.GetStats().RampingUp {
+		t.Error("Ramp-up should be complete once the full concurrency is admitted")
+	}
+}
+
+func TestControllerRampUpDisabled(t *testing.T) {
+	c := NewController(ControllerConfig{InitialConcurrency: 2, MaxConcurrency: 2})
+
+	c.WorkerStarted()
+	if !c.CanSpawn() {
+		t.Error("CanSpawn() = false, want true without ramp-up")
+	}
+	if c.GetStats().RampingUp {
+		t.Error("RampingUp = true without RampUpInterval")
+	}
+}
+
+func TestControllerObserveColdStart(t *testing.T) {
+	c := NewController(ControllerConfig{InitialConcurrency: 2, MaxConcurrency: 2, RampUpInterval: time.Second})
+
+	c.ObserveColdStart(10 * time.Second)
+	if got := c.EstimatedColdStart(); got != 10*time.Second {
+		t.Errorf("EstimatedColdStart() = %v, want 10s", got)
+	}
+
+	c.ObserveColdStart(20 * time.Second)
+	if got := c.EstimatedColdStart(); got != 13*time.Second {
+		t.Errorf("EstimatedColdStart() = %v, want 13s", got)
+	}
+
+	// Slow cold starts stretch the ramp interval beyond the configured value
+	if got := c.rampInterval(); got != 13*time.Second {
+		t.Errorf("rampInterval() = %v, want 13s", got)
+	}
+}
+
+func TestControllerSetLimits(t *testing.T) {
+	c := NewController(ControllerConfig{InitialConcurrency: 4, MinConcurrency: 1, MaxConcurrency: 4})
+
+	// Lowering the ceiling clamps the current concurrency
+	c.SetLimits(Limits{MaxConcurrency: 2, RateLimitBackoff: 10 * time.Second})
+	if got := c.GetCurrentConcurrency(); got != 2 {
+		t.Errorf("GetCurrentConcurrency() = %d, want 2", got)
+	}
+	limits := c.Limits()
+	if limits.MinConcurrency != 1 || limits.MaxConcurrency != 2 || limits.RateLimitBackoff != 10*time.Second || limits.MaxBackoff != 5*time.Minute {
+		t.Errorf("Limits() = %+v, want 1-2 with a 10s backoff and the 5m maximum kept", limits)
+	}
+
+	// Raising the floor above the ceiling lifts both
+	c.SetLimits(Limits{MinConcurrency: 3})
+	if got := c.GetCurrentConcurrency(); got != 3 {
+		t.Errorf("GetCurrentConcurrency() = %d, want 3", got)
+	}
+	if got := c.Limits().MaxConcurrency; got != 3 {
+		t.Errorf("MaxConcurrency = %d, want 3", got)
+	}
+
+	// The concurrency recovers up to a raised ceiling
+	c.SetLimits(Limits{MaxConcurrency: 5})
+	c.OnWorkerSignal(SignalOK)
+	c.OnWorkerSignal(SignalOK)
+	c.OnWorkerSignal(SignalOK)
+	if got := c.GetCurrentConcurrency(); got != 5 {
+		t.Errorf("GetCurrentConcurrency() = %d, want 5", got)
+	}
+}
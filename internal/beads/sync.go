@@ -77,12 +77,18 @@ func DefaultSyncConfig(projectDir string) SyncConfig {
 // ImportFromBeads reads .beads/beads.jsonl and returns Drover types
 func ImportFromBeads(config SyncConfig) ([]types.Epic, []types.Task, []types.TaskDependency, error) {
 	jsonlPath := filepath.Join(config.BeadsDir, "beads.jsonl")
+	if _, err := os.Stat(jsonlPath); os.IsNotExist(err) {
+		return nil, nil, nil, fmt.Errorf("beads not initialized - run 'bd init' first")
+	}
+	return ReadFile(jsonlPath)
+}
 
-	file, err := os.Open(jsonlPath)
+// ReadFile reads a beads JSONL file and returns Drover types. Sub-tasks are
+// recognized by their hierarchical IDs, and only "blocked_by" links between
+// tasks in the file become dependencies
+func ReadFile(path string) ([]types.Epic, []types.Task, []types.TaskDependency, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil, nil, fmt.Errorf("beads not initialized - run 'bd init' first")
-		}
 		return nil, nil, nil, err
 	}
 	defer file.Close()
@@ -147,7 +153,7 @@ func ImportFromBeads(config SyncConfig) ([]types.Epic, []types.Task, []types.Tas
 				ParentID:       parentID,
 				SequenceNumber: sequenceNumber,
 				Priority:       taskData.Priority,
-				Status:         beadsStatusToDrover(taskData.Status),
+				Status:         beadsTaskStatus(taskData),
 				CreatedAt:      record.Timestamp.Unix(),
 				UpdatedAt:      time.Now().Unix(),
 			}
@@ -162,6 +168,9 @@ func ImportFromBeads(config SyncConfig) ([]types.Epic, []types.Task, []types.Tas
 			}
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("reading %s: %w", path, err)
+	}
 
 	// Process dependencies
 	var deps []types.TaskDependency
@@ -225,6 +234,7 @@ func ExportToBeads(epics []types.Epic, tasks []types.Task, deps []types.TaskDepe
 			Status:      droverStatusToBeads(task.Status),
 			Priority:    task.Priority,
 			EpicID:      task.EpicID,
+			Reason:      CloseReason(task.Status),
 		}
 		record.Data, _ = json.Marshal(taskData)
 		encoder.Encode(record)
@@ -308,6 +318,20 @@ func beadsStatusToDrover(beadsStatus string) types.TaskStatus {
 	}
 }
 
+// beadsTaskStatus is the Drover status of a bead, telling closed beads apart
+// by the reason they were closed with
+func beadsTaskStatus(task BeadTask) types.TaskStatus {
+	if task.Status == "closed" {
+		switch task.Reason {
+		case "failed":
+			return types.TaskStatusFailed
+		case "wontfix", "cancelled":
+			return types.TaskStatusCancelled
+		}
+	}
+	return beadsStatusToDrover(task.Status)
+}
+
 func droverStatusToBeads(droverStatus types.TaskStatus) string {
 	switch droverStatus {
 	case types.TaskStatusReady, types.TaskStatusClaimed, types.TaskStatusBlocked:
@@ -316,9 +340,23 @@ func droverStatusToBeads(droverStatus types.TaskStatus) string {
 		return "active"
 	case types.TaskStatusCompleted:
 		return "closed"
-	case types.TaskStatusFailed:
+	case types.TaskStatusFailed, types.TaskStatusCancelled:
 		return "closed"
 	default:
 		return "open"
 	}
 }
+
+// CloseReason is the reason a bead is closed with for a finished Drover
+// status, so importing it restores the status. Empty for open statuses
+func CloseReason(droverStatus types.TaskStatus) string {
+	switch droverStatus {
+	case types.TaskStatusCompleted:
+		return "completed"
+	case types.TaskStatusFailed:
+		return "failed"
+	case types.TaskStatusCancelled:
+		return "wontfix"
+	}
+	return ""
+}
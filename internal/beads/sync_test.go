@@ -81,6 +81,45 @@ func TestImportFromBeads(t *testing.T) {
 	}
 }
 
+// TestReadFile_RoundTrip tests that exported statuses survive a re-import
+func TestReadFile_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	config := SyncConfig{BeadsDir: tmpDir}
+
+	tasks := []types.Task{
+		{ID: "task-1", Title: "Done", Status: types.TaskStatusCompleted, CreatedAt: 1},
+		{ID: "task-2", Title: "Broken", Status: types.TaskStatusFailed, CreatedAt: 2},
+		{ID: "task-3", Title: "Dropped", Status: types.TaskStatusCancelled, CreatedAt: 3},
+		{ID: "task-4", Title: "Waiting", Status: types.TaskStatusBlocked, CreatedAt: 4},
+	}
+	deps := []types.TaskDependency{{TaskID: "task-4", BlockedBy: "task-2"}}
+	if err := ExportToBeads(nil, tasks, deps, config); err != nil {
+		t.Fatalf("ExportToBeads failed: %v", err)
+	}
+
+	_, imported, importedDeps, err := ReadFile(filepath.Join(tmpDir, "beads.jsonl"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	want := []types.TaskStatus{
+		types.TaskStatusCompleted,
+		types.TaskStatusFailed,
+		types.TaskStatusCancelled,
+		types.TaskStatusReady, // Blocked is recomputed from dependencies
+	}
+	if len(imported) != len(want) {
+		t.Fatalf("Expected %d tasks, got %d", len(want), len(imported))
+	}
+	for i, task := range imported {
+		if task.Status != want[i] {
+			t.Errorf("%s: status %s, want %s", task.ID, task.Status, want[i])
+		}
+	}
+	if len(importedDeps) != 1 || importedDeps[0] != deps[0] {
+		t.Errorf("Expected dependencies %v, got %v", deps, importedDeps)
+	}
+}
+
 // TestExportToBeads tests exporting tasks to beads.jsonl
 func TestExportToBeads(t *testing.T) {
 	tmpDir := t.TempDir()
@@ -4,12 +4,17 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cloud-shuttle/drover/internal/analytics"
+	"github.com/cloud-shuttle/drover/internal/integrations"
 	"github.com/cloud-shuttle/drover/internal/modes"
+	"github.com/cloud-shuttle/drover/internal/notify"
 	"github.com/cloud-shuttle/drover/internal/webhooks"
 )
 
@@ -25,24 +30,90 @@ type Config struct {
 	TaskTimeout     time.Duration
 	MaxTaskAttempts int
 
+	// Diagnostics fix-it loop: feed go vet/tsc/clippy findings back to the agent before commit
+	DiagnosticsIterations int           // agent re-runs to fix diagnostics (0 disables)
+	DiagnosticsCommand    string        // overrides the command detected from the project files
+	DiagnosticsTimeout    time.Duration // upper bound for one diagnostics run
+
+	// Test gate sharding: split go test/jest runs across idle workers
+	TestShards int // upper bound on parallel shards per test run (0 or 1 disables)
+
+	// Retention for drover gc
+	ArchiveRetentionDays int // days finished tasks stay live before they are archived
+	ArchivePurgeDays     int // days archived tasks are kept before they are deleted (0 = forever)
+
 	// Retry settings
 	ClaimTimeout  time.Duration
 	StallTimeout  time.Duration
+	LeaseTTL      time.Duration // claims not renewed for this long return to the queue (0 disables)
+	DrainTimeout  time.Duration // how long an interrupted run waits for in-flight tasks (0 = stop them at once)
+	MaxCost       float64       // USD a run may spend before it stops starting tasks (0 = no limit)
+	MaxDuration   time.Duration // wall-clock budget after which a run starts no more tasks (0 = no limit)
+	PreemptGap    int           // priority lead a ready task needs to pause the lowest-priority task in flight (0 = never preempt)
+	HedgeFactor   float64       // hedge a task whose agent runs past this multiple of the p95 task duration (0 = never hedge)
+	HedgeBudget   int           // speculative second attempts a run may launch
+	FailureStreak int           // consecutive tasks failing the same way that park the run (0 = never)
+	Daemon        bool          // keep running once the queue empties, executing tasks as they are added
+	DaemonIdle    time.Duration // how long a daemon's queue stays empty before the worktree pool scales down
+	ControlAddr   string        // address the run's HTTP control API listens on, e.g. "localhost:7070" (empty = off)
+	ControlToken  string        // bearer token the control API requires (empty = none)
+	DashboardPort string        // port the run serves the web dashboard on, with agents' work streamed live (empty = off)
 	PollInterval  time.Duration
 	AutoUnblock   bool
+	Schedule      string // which ready task is claimed first: a db.Schedule name such as "priority" (empty = .drover.toml)
+	RetryPolicy   string // backoff and retried failure classes, e.g. "backoff=30s,on=agent|timeout" (empty = .drover.toml)
+	FixBlockers   bool   // queue a fix task for failures caused by blockers outside the task (missing dependency, unrelated test, lint config)
+
+	// DBOS task queue limits (0 = no limit); 'drover queue limits' changes them on a live deployment
+	QueueWorkerConcurrency int           // tasks one orchestrator runs at once
+	QueueGlobalConcurrency int           // tasks running at once across orchestrators
+	QueueRateLimit         int           // task starts per QueueRatePeriod
+	QueueRatePeriod        time.Duration
 
 	// Git settings
-	WorktreeDir string
+	WorktreeDir   string
+	IsolationMode string // "worktree" (default) or "clone" for full local clones
+	ProtectedPaths []string // paths task commits may not modify (merged with .drover.toml)
+	BranchPrefix   string   // prefix for task branches
+	BranchTemplate string   // branch name template: {prefix}, {id}, {epic}, {slug}, {date}
+	TargetBranch   string   // branch to merge into (overrides .drover.toml and detection)
+	Bootstrap      bool     // create a scaffold task first when the repository has no commits
+	Epic           string   // epic the run is filtered to, which a scaffold task joins (empty = all)
+	GitNetworkTimeout time.Duration // upper bound for git push/fetch (0 = no limit)
 
 	// Agent settings
-	AgentType  string  // "claude", "codex", or "amp"
+	AgentType  string  // "claude", "codex", "amp", "opencode", "aider", "goose", "custom" or "llm"
 	AgentPath  string  // path to agent binary
 	ClaudePath string  // deprecated: use AgentPath instead
+	AgentModel string  // model the agent runs (empty = its default), also used for commit attribution
+	AgentProvider string // LLM provider of the model (goose only; empty = goose's configured one)
+	ClaudeResume  bool   // resume the previous attempt's Claude session when a task is retried (claude only)
+	ModelFallback string // models a rate limited task falls back to in turn, e.g. "haiku,opencode/openai/gpt-4o" (empty = .drover.toml)
+	MaxOutput     string // output of an agent run kept in memory per stream, e.g. "64M"; the rest spills to a file (empty = .drover.toml, else 32M)
+
+	// Commit attribution: task commits are authored as the agent that produced them
+	CommitAttribution bool   // set GIT_AUTHOR_NAME/EMAIL on task commits (opt-in with DROVER_COMMIT_ATTRIBUTION)
+	CommitAuthorName  string // author name template: {agent}, {model}
+	CommitAuthorEmail string // author email template: {agent}, {model}
+
+	// Direct LLM API agent (type "llm")
+	LLMURL    string // OpenAI-compatible chat API it calls (default: a local 'drover proxy serve')
+	LLMAPIKey string // API key sent to it
+
+	// OpenCode server attach mode (opencode run --attach)
+	OpenCodeURL     string // running opencode server every execution attaches to
+	OpenCodeServers int    // warm opencode servers drover launches and supervises (0 = none)
 
 	// Process-isolated worker settings (for OOM prevention)
 	UseWorkerSubprocess bool   // use drover-worker for process isolation
 	WorkerBinary        string // path to drover-worker binary (default: "drover-worker")
 	WorkerMemoryLimit   string // memory limit for worker processes (e.g., "512M", "2G")
+	WorkerProtocol      string // "grpc" (default) or "json", the older stdin/stdout protocol
+	RemoteWorkers       string // SSH hosts to run workers on, comma-separated (empty = locally)
+	RemoteWorkerDir     string // where worktrees go on a remote host (empty = ~/.drover/remote)
+	RemoteWorkerBinary  string // drover-worker on a remote host (empty = "drover-worker")
+	WorkerPoolSize      int    // long-lived workers tasks are dispatched to (0 = a worker process per task)
+	WorkerPoolMaxTasks  int    // tasks a pooled worker runs before it's replaced (0 = 25)
 
 	// Backpressure settings (adaptive concurrency control)
 	BackpressureEnabled           bool          // enable backpressure control
@@ -52,6 +123,7 @@ type Config struct {
 	BackpressureRateLimitBackoff   time.Duration // initial backoff on rate limit
 	BackpressureMaxBackoff         time.Duration // maximum backoff duration
 	BackpressureSlowThreshold      time.Duration // response time considered slow
+	BackpressureRampUpInterval     time.Duration // delay between admitting workers at startup (0 disables)
 
 	// Memory-aware backpressure settings (drover-mem-6)
 	BackpressureMemoryAwareEnabled bool   // enable memory-aware spawning
@@ -63,6 +135,27 @@ type Config struct {
 	WorkerMode    modes.WorkerMode // "combined", "planning", or "building"
 	RequireApproval bool             // require manual approval for plans
 
+	// PR mode: push task branches and report commit status checks instead of merging locally
+	PRMode        bool
+	PRRemote      string // remote to push task branches to
+	GitHubRepo    string // owner/name for commit status checks
+	GitHubToken   string
+	GitHubAPIURL  string
+	StatusContext string // commit status context prefix
+
+	// Jira integration: import issues and push status transitions back
+	JiraURL              string // e.g. https://example.atlassian.net
+	JiraEmail            string // Jira Cloud account for basic auth; empty for a bearer token
+	JiraToken            string
+	JiraInProgressStatus string // status an issue moves to when its task starts
+	JiraDoneStatus       string // status an issue moves to when its task completes
+	JiraSync             bool   // push status transitions during runs
+
+	// Linear integration: import issues, optionally as they are labeled
+	LinearAPIKey        string
+	LinearWebhookSecret string // signing secret of the Linear webhook
+	LinearLabel         string // label marking issues for automation
+
 	// Beads sync settings
 	AutoSyncBeads bool
 
@@ -72,12 +165,29 @@ type Config struct {
 	// Verbose mode for debugging
 	Verbose bool
 
+	// Output style for CLI and logs: "emoji" (default), "plain", or "ascii"
+	OutputStyle string
+
 	// Worktree pool settings
 	PoolEnabled      bool
 	PoolMinSize      int
 	PoolMaxSize      int
 	PoolWarmup       time.Duration
 	PoolCleanupOnExit bool
+	PoolRetain                    bool          // return a completed task's worktree to the pool warm instead of draining it
+	PoolGoBuildCache              bool          // share GOCACHE across pooled worktrees
+	PoolGoBuildCacheMode          string        // "global" or "lockhash" (per go.sum)
+	PoolGoBuildCacheMaxMB         int64         // go clean -cache above this size (0 = no cap)
+	PoolGoBuildCacheCleanInterval time.Duration // how often the cache clean policy runs
+	PoolFetchInterval             time.Duration // scheduled fetch of pooled worktrees (0 disables)
+	PoolFetchJitter               time.Duration // random extra delay per fetch round
+	PoolFetchSkipInUse            bool          // don't fetch worktrees running a task
+	PoolStaleAfter                time.Duration // refresh warm worktrees older than this before use (0 disables)
+	PoolStalePolicy               string        // "rebase" or "recycle"
+	PoolAdaptiveMaxSize           bool          // lower PoolMaxSize to what free disk/memory can hold, but not below Workers
+	PoolDiskReserveMB             int64         // free disk the pool leaves untouched
+	PoolMemoryReserveMB           int64         // available memory the pool leaves untouched
+	PoolSizingInterval            time.Duration // how often the adaptive max size is re-evaluated
 
 	// Modes configuration (for planning/building separation)
 	Modes *modes.Config
@@ -88,6 +198,13 @@ type Config struct {
 	WebhookSecret   string
 	WebhookWorkers  int
 
+	// Chat notifications: run summaries and failure alerts posted to Slack or Discord
+	SlackWebhookURL    string
+	DiscordWebhookURL  string
+	NotifyOn           string // comma-separated: start, failure, end (empty for all)
+	NotifyFailuresOnly bool   // skip run starts, and run ends without failed tasks
+	DashboardURL       string // linked from notifications
+
 	// Analytics settings
 	AnalyticsEnabled  bool
 	AnalyticsConfig   string
@@ -108,20 +225,58 @@ func Load() (*Config, error) {
 		Workers:         3,
 		TaskTimeout:     60 * time.Minute,
 		MaxTaskAttempts: 3,
+		DiagnosticsIterations: 0, // Fix-it loop disabled by default
+		DiagnosticsTimeout:    5 * time.Minute,
+		TestShards:            0, // Test runs are not sharded by default
+		ArchiveRetentionDays:  30,
+		ArchivePurgeDays:      0, // Keep archived tasks forever by default
 		ClaimTimeout:    5 * time.Minute,
 		StallTimeout:    5 * time.Minute,
+		LeaseTTL:        5 * time.Minute,
+		DrainTimeout:    10 * time.Minute,
+		DaemonIdle:      5 * time.Minute,
+		HedgeBudget:     2,
 		PollInterval:    2 * time.Second,
 		AutoUnblock:     true,
 		WorktreeDir:     ".drover/worktrees",
+		IsolationMode:   "worktree",
+		OutputStyle:     "emoji",
+		BranchPrefix:    "drover",
+		BranchTemplate:  "{prefix}-{id}",
+		PRRemote:        "origin",
+		GitHubAPIURL:    "https://api.github.com",
+		StatusContext:   "drover",
+		JiraInProgressStatus: "In Progress",
+		JiraDoneStatus:       "Done",
+		JiraSync:             true,
+		LinearLabel:          "drover",
 		AgentType:       "claude", // Default to Claude for backwards compatibility
 		AgentPath:       "claude", // Will be resolved based on AgentType
 		ClaudePath:      "claude", // Deprecated but kept for backwards compatibility
+		Bootstrap:         true,
+		GitNetworkTimeout: 5 * time.Minute,
+		CommitAuthorName:  "drover[{model}]",
+		CommitAuthorEmail: "drover+{agent}@localhost",
 		AutoSyncBeads:   false,    // Default to off for backwards compatibility
 		PoolEnabled:     false,    // Worktree pooling disabled by default
 		PoolMinSize:     2,        // Minimum warm worktrees
 		PoolMaxSize:     10,       // Maximum pooled worktrees
 		PoolWarmup:      5 * time.Minute,
 		PoolCleanupOnExit: true,   // Clean up pooled worktrees on exit
+		PoolRetain:        false,  // Drain worktrees after each task
+		PoolGoBuildCache:              true,
+		PoolGoBuildCacheMode:          "global",
+		PoolGoBuildCacheMaxMB:         10240, // 10GB cap before go clean -cache
+		PoolGoBuildCacheCleanInterval: time.Hour,
+		PoolFetchInterval:             0, // Scheduled fetch disabled by default
+		PoolFetchJitter:               30 * time.Second,
+		PoolFetchSkipInUse:            true,
+		PoolStaleAfter:                30 * time.Minute,
+		PoolStalePolicy:               "rebase",
+		PoolAdaptiveMaxSize:           true,
+		PoolDiskReserveMB:             2048,
+		PoolMemoryReserveMB:           1024,
+		PoolSizingInterval:            30 * time.Second,
 		UseWorkerSubprocess: false, // Process-isolated workers disabled by default
 		WorkerBinary:        "drover-worker",
 		WorkerMemoryLimit:   "",  // No memory limit by default
@@ -160,6 +315,65 @@ func Load() (*Config, error) {
 	if v := os.Getenv("DROVER_TASK_TIMEOUT"); v != "" {
 		cfg.TaskTimeout = parseDurationOrDefault(v, 10*time.Minute)
 	}
+	if v := os.Getenv("DROVER_LEASE_TTL"); v != "" {
+		cfg.LeaseTTL = parseDurationOrDefault(v, 5*time.Minute)
+	}
+	if v := os.Getenv("DROVER_DRAIN_TIMEOUT"); v != "" {
+		cfg.DrainTimeout = parseDurationOrDefault(v, 10*time.Minute)
+	}
+	if v := os.Getenv("DROVER_MAX_COST"); v != "" {
+		cfg.MaxCost = parseFloatOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_MAX_DURATION"); v != "" {
+		cfg.MaxDuration = parseDurationOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_PREEMPT"); v != "" {
+		cfg.PreemptGap = parseIntOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_HEDGE_FACTOR"); v != "" {
+		cfg.HedgeFactor = parseFloatOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_HEDGE_BUDGET"); v != "" {
+		cfg.HedgeBudget = parseIntOrDefault(v, 2)
+	}
+	if v := os.Getenv("DROVER_FAILURE_STREAK"); v != "" {
+		cfg.FailureStreak = parseIntOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_DAEMON"); v != "" {
+		cfg.Daemon = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_DAEMON_IDLE"); v != "" {
+		cfg.DaemonIdle = parseDurationOrDefault(v, 5*time.Minute)
+	}
+	if v := os.Getenv("DROVER_CONTROL_ADDR"); v != "" {
+		cfg.ControlAddr = v
+	}
+	if v := os.Getenv("DROVER_CONTROL_TOKEN"); v != "" {
+		cfg.ControlToken = v
+	}
+	if v := os.Getenv("DROVER_DASHBOARD_PORT"); v != "" {
+		cfg.DashboardPort = v
+	}
+	if v := os.Getenv("DROVER_SCHEDULE"); v != "" {
+		cfg.Schedule = v
+	}
+	if v := os.Getenv("DROVER_RETRY_POLICY"); v != "" {
+		cfg.RetryPolicy = v
+	}
+	if v := os.Getenv("DROVER_FIX_BLOCKERS"); v != "" {
+		cfg.FixBlockers = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_QUEUE_WORKER_CONCURRENCY"); v != "" {
+		cfg.QueueWorkerConcurrency = parseIntOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_QUEUE_GLOBAL_CONCURRENCY"); v != "" {
+		cfg.QueueGlobalConcurrency = parseIntOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_QUEUE_RATE_LIMIT"); v != "" {
+		if limit, period, err := ParseRateLimit(v); err == nil {
+			cfg.QueueRateLimit, cfg.QueueRatePeriod = limit, period
+		}
+	}
 	if v := os.Getenv("DROVER_AUTO_SYNC_BEADS"); v != "" {
 		cfg.AutoSyncBeads = v == "true" || v == "1"
 	}
@@ -173,6 +387,129 @@ func Load() (*Config, error) {
 		cfg.AgentPath = v
 		cfg.ClaudePath = v
 	}
+	if v := os.Getenv("DROVER_AGENT_MODEL"); v != "" {
+		cfg.AgentModel = v
+	}
+	if v := os.Getenv("DROVER_AGENT_PROVIDER"); v != "" {
+		cfg.AgentProvider = v
+	}
+	if v := os.Getenv("DROVER_MODEL_FALLBACK"); v != "" {
+		cfg.ModelFallback = v
+	}
+	if v := os.Getenv("DROVER_MAX_OUTPUT"); v != "" {
+		cfg.MaxOutput = v
+	}
+	if v := os.Getenv("DROVER_CLAUDE_RESUME"); v != "" {
+		cfg.ClaudeResume = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_LLM_PROXY_URL"); v != "" {
+		cfg.LLMURL = v
+	}
+	if v := os.Getenv("DROVER_LLM_API_KEY"); v != "" {
+		cfg.LLMAPIKey = v
+	}
+	if v := os.Getenv("DROVER_COMMIT_ATTRIBUTION"); v != "" {
+		cfg.CommitAttribution = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_COMMIT_AUTHOR_NAME"); v != "" {
+		cfg.CommitAuthorName = v
+	}
+	if v := os.Getenv("DROVER_COMMIT_AUTHOR_EMAIL"); v != "" {
+		cfg.CommitAuthorEmail = v
+	}
+	if v := os.Getenv("DROVER_ISOLATION_MODE"); v != "" {
+		cfg.IsolationMode = v
+	}
+	if v := os.Getenv("DROVER_BRANCH_PREFIX"); v != "" {
+		cfg.BranchPrefix = v
+	}
+	if v := os.Getenv("DROVER_BRANCH_TEMPLATE"); v != "" {
+		cfg.BranchTemplate = v
+	}
+	if v := os.Getenv("DROVER_TARGET_BRANCH"); v != "" {
+		cfg.TargetBranch = v
+	}
+	if v := os.Getenv("DROVER_BOOTSTRAP"); v != "" {
+		cfg.Bootstrap = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_GIT_NETWORK_TIMEOUT"); v != "" {
+		cfg.GitNetworkTimeout = parseDurationOrDefault(v, 5*time.Minute)
+	}
+	if v := os.Getenv("DROVER_DIAGNOSTICS_ITERATIONS"); v != "" {
+		cfg.DiagnosticsIterations = parseIntOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_DIAGNOSTICS_COMMAND"); v != "" {
+		cfg.DiagnosticsCommand = v
+	}
+	if v := os.Getenv("DROVER_DIAGNOSTICS_TIMEOUT"); v != "" {
+		cfg.DiagnosticsTimeout = parseDurationOrDefault(v, 5*time.Minute)
+	}
+	if v := os.Getenv("DROVER_TEST_SHARDS"); v != "" {
+		cfg.TestShards = parseIntOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_ARCHIVE_RETENTION_DAYS"); v != "" {
+		cfg.ArchiveRetentionDays = parseIntOrDefault(v, 30)
+	}
+	if v := os.Getenv("DROVER_ARCHIVE_PURGE_DAYS"); v != "" {
+		cfg.ArchivePurgeDays = parseIntOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_PROTECTED_PATHS"); v != "" {
+		cfg.ProtectedPaths = strings.Split(v, ",")
+	}
+	if v := os.Getenv("DROVER_OUTPUT_STYLE"); v != "" {
+		cfg.OutputStyle = v
+	}
+	if v := os.Getenv("DROVER_PR_MODE"); v != "" {
+		cfg.PRMode = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_PR_REMOTE"); v != "" {
+		cfg.PRRemote = v
+	}
+	if v := os.Getenv("DROVER_GITHUB_REPO"); v != "" {
+		cfg.GitHubRepo = v
+	}
+	if v := os.Getenv("DROVER_GITHUB_TOKEN"); v != "" {
+		cfg.GitHubToken = v
+	} else if v := os.Getenv("GITHUB_TOKEN"); v != "" {
+		cfg.GitHubToken = v
+	}
+	if v := os.Getenv("DROVER_GITHUB_API_URL"); v != "" {
+		cfg.GitHubAPIURL = v
+	}
+	if v := os.Getenv("DROVER_STATUS_CONTEXT"); v != "" {
+		cfg.StatusContext = v
+	}
+	if v := os.Getenv("DROVER_JIRA_URL"); v != "" {
+		cfg.JiraURL = v
+	}
+	if v := os.Getenv("DROVER_JIRA_EMAIL"); v != "" {
+		cfg.JiraEmail = v
+	}
+	if v := os.Getenv("DROVER_JIRA_TOKEN"); v != "" {
+		cfg.JiraToken = v
+	} else if v := os.Getenv("JIRA_API_TOKEN"); v != "" {
+		cfg.JiraToken = v
+	}
+	if v := os.Getenv("DROVER_JIRA_IN_PROGRESS_STATUS"); v != "" {
+		cfg.JiraInProgressStatus = v
+	}
+	if v := os.Getenv("DROVER_JIRA_DONE_STATUS"); v != "" {
+		cfg.JiraDoneStatus = v
+	}
+	if v := os.Getenv("DROVER_JIRA_SYNC"); v != "" {
+		cfg.JiraSync = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_LINEAR_API_KEY"); v != "" {
+		cfg.LinearAPIKey = v
+	} else if v := os.Getenv("LINEAR_API_KEY"); v != "" {
+		cfg.LinearAPIKey = v
+	}
+	if v := os.Getenv("DROVER_LINEAR_WEBHOOK_SECRET"); v != "" {
+		cfg.LinearWebhookSecret = v
+	}
+	if v := os.Getenv("DROVER_LINEAR_LABEL"); v != "" {
+		cfg.LinearLabel = v
+	}
 	if v := os.Getenv("DROVER_POOL_ENABLED"); v != "" {
 		cfg.PoolEnabled = v == "true" || v == "1"
 	}
@@ -188,6 +525,54 @@ func Load() (*Config, error) {
 	if v := os.Getenv("DROVER_POOL_CLEANUP_ON_EXIT"); v != "" {
 		cfg.PoolCleanupOnExit = v == "true" || v == "1"
 	}
+	if v := os.Getenv("DROVER_POOL_RETAIN"); v != "" {
+		cfg.PoolRetain = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_POOL_GO_BUILD_CACHE"); v != "" {
+		cfg.PoolGoBuildCache = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_POOL_GO_BUILD_CACHE_MODE"); v != "" {
+		cfg.PoolGoBuildCacheMode = v
+	}
+	if v := os.Getenv("DROVER_POOL_GO_BUILD_CACHE_MAX_MB"); v != "" {
+		cfg.PoolGoBuildCacheMaxMB = parseInt64OrDefault(v, 10240)
+	}
+	if v := os.Getenv("DROVER_POOL_GO_BUILD_CACHE_CLEAN_INTERVAL"); v != "" {
+		cfg.PoolGoBuildCacheCleanInterval = parseDurationOrDefault(v, time.Hour)
+	}
+	if v := os.Getenv("DROVER_POOL_FETCH_INTERVAL"); v != "" {
+		cfg.PoolFetchInterval = parseDurationOrDefault(v, 0)
+	}
+	if v := os.Getenv("DROVER_POOL_FETCH_JITTER"); v != "" {
+		cfg.PoolFetchJitter = parseDurationOrDefault(v, 30*time.Second)
+	}
+	if v := os.Getenv("DROVER_POOL_FETCH_SKIP_IN_USE"); v != "" {
+		cfg.PoolFetchSkipInUse = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_POOL_STALE_AFTER"); v != "" {
+		cfg.PoolStaleAfter = parseDurationOrDefault(v, 30*time.Minute)
+	}
+	if v := os.Getenv("DROVER_POOL_STALE_POLICY"); v != "" {
+		cfg.PoolStalePolicy = v
+	}
+	if v := os.Getenv("DROVER_POOL_ADAPTIVE_MAX_SIZE"); v != "" {
+		cfg.PoolAdaptiveMaxSize = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_POOL_DISK_RESERVE_MB"); v != "" {
+		cfg.PoolDiskReserveMB = parseInt64OrDefault(v, 2048)
+	}
+	if v := os.Getenv("DROVER_POOL_MEMORY_RESERVE_MB"); v != "" {
+		cfg.PoolMemoryReserveMB = parseInt64OrDefault(v, 1024)
+	}
+	if v := os.Getenv("DROVER_POOL_SIZING_INTERVAL"); v != "" {
+		cfg.PoolSizingInterval = parseDurationOrDefault(v, 30*time.Second)
+	}
+	if v := os.Getenv("DROVER_OPENCODE_URL"); v != "" {
+		cfg.OpenCodeURL = v
+	}
+	if v := os.Getenv("DROVER_OPENCODE_SERVERS"); v != "" {
+		cfg.OpenCodeServers = parseIntOrDefault(v, 0)
+	}
 	if v := os.Getenv("DROVER_USE_WORKER_SUBPROCESS"); v != "" {
 		cfg.UseWorkerSubprocess = v == "true" || v == "1"
 	}
@@ -197,6 +582,26 @@ func Load() (*Config, error) {
 	if v := os.Getenv("DROVER_WORKER_MEMORY_LIMIT"); v != "" {
 		cfg.WorkerMemoryLimit = v
 	}
+	if v := os.Getenv("DROVER_WORKER_PROTOCOL"); v != "" {
+		cfg.WorkerProtocol = v
+	}
+	if v := os.Getenv("DROVER_REMOTE_WORKERS"); v != "" {
+		cfg.RemoteWorkers = v
+		cfg.UseWorkerSubprocess = true // Remote workers are drover-worker processes
+	}
+	if v := os.Getenv("DROVER_REMOTE_WORKER_DIR"); v != "" {
+		cfg.RemoteWorkerDir = v
+	}
+	if v := os.Getenv("DROVER_REMOTE_WORKER_BINARY"); v != "" {
+		cfg.RemoteWorkerBinary = v
+	}
+	if v := os.Getenv("DROVER_WORKER_POOL"); v != "" {
+		cfg.WorkerPoolSize = parseIntOrDefault(v, 0)
+		cfg.UseWorkerSubprocess = cfg.UseWorkerSubprocess || cfg.WorkerPoolSize > 0 // Pooled workers are drover-worker processes
+	}
+	if v := os.Getenv("DROVER_WORKER_POOL_MAX_TASKS"); v != "" {
+		cfg.WorkerPoolMaxTasks = parseIntOrDefault(v, 0)
+	}
 	if v := os.Getenv("DROVER_WORKER_MODE"); v != "" {
 		cfg.WorkerMode = modes.WorkerMode(v)
 	}
@@ -221,6 +626,9 @@ func Load() (*Config, error) {
 	if v := os.Getenv("DROVER_BACKPRESSURE_SLOW_THRESHOLD"); v != "" {
 		cfg.BackpressureSlowThreshold = parseDurationOrDefault(v, 10*time.Second)
 	}
+	if v := os.Getenv("DROVER_BACKPRESSURE_RAMP_UP_INTERVAL"); v != "" {
+		cfg.BackpressureRampUpInterval = parseDurationOrDefault(v, 0)
+	}
 	// Memory-aware backpressure settings (drover-mem-6)
 	if v := os.Getenv("DROVER_BACKPRESSURE_MEMORY_AWARE_ENABLED"); v != "" {
 		cfg.BackpressureMemoryAwareEnabled = v == "true" || v == "1"
@@ -270,6 +678,21 @@ func Load() (*Config, error) {
 	if v := os.Getenv("DROVER_WEBHOOK_WORKERS"); v != "" {
 		cfg.WebhookWorkers = parseIntOrDefault(v, 3)
 	}
+	if v := os.Getenv("DROVER_SLACK_WEBHOOK_URL"); v != "" {
+		cfg.SlackWebhookURL = v
+	}
+	if v := os.Getenv("DROVER_DISCORD_WEBHOOK_URL"); v != "" {
+		cfg.DiscordWebhookURL = v
+	}
+	if v := os.Getenv("DROVER_NOTIFY_ON"); v != "" {
+		cfg.NotifyOn = v
+	}
+	if v := os.Getenv("DROVER_NOTIFY_FAILURES_ONLY"); v != "" {
+		cfg.NotifyFailuresOnly = v == "true" || v == "1"
+	}
+	if v := os.Getenv("DROVER_DASHBOARD_URL"); v != "" {
+		cfg.DashboardURL = v
+	}
 	if v := os.Getenv("DROVER_ANALYTICS_ENABLED"); v != "" {
 		cfg.AnalyticsEnabled = v == "true" || v == "1"
 	}
@@ -308,6 +731,10 @@ func Load() (*Config, error) {
 			cfg.AgentPath = "codex"
 		case "amp":
 			cfg.AgentPath = "amp"
+		case "aider":
+			cfg.AgentPath = "aider"
+		case "goose":
+			cfg.AgentPath = "goose"
 		}
 	}
 
@@ -339,6 +766,14 @@ func parseInt64OrDefault(s string, def int64) int64 {
 	return i
 }
 
+func parseFloatOrDefault(s string, def float64) float64 {
+	var f float64
+	if _, err := fmt.Sscanf(s, "%g", &f); err != nil {
+		return def
+	}
+	return f
+}
+
 func parseDurationOrDefault(s string, def time.Duration) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
@@ -347,6 +782,32 @@ func parseDurationOrDefault(s string, def time.Duration) time.Duration {
 	return d
 }
 
+// ParseRateLimit parses a rate limit such as "10/1m": at most 10 per minute.
+// A bare unit means one of it ("30/m" is 30 per minute); "0" lifts the limit
+func ParseRateLimit(spec string) (int, time.Duration, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "0" {
+		return 0, 0, nil
+	}
+	count, per, ok := strings.Cut(spec, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid rate limit %q: want <count>/<period>, e.g. 10/1m", spec)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || limit < 0 {
+		return 0, 0, fmt.Errorf("invalid rate limit %q: count must be a non-negative number", spec)
+	}
+	per = strings.TrimSpace(per)
+	if per != "" && (per[0] < '0' || per[0] > '9') {
+		per = "1" + per
+	}
+	period, err := time.ParseDuration(per)
+	if err != nil || period <= 0 {
+		return 0, 0, fmt.Errorf("invalid rate limit %q: period must be a positive duration", spec)
+	}
+	return limit, period, nil
+}
+
 // GetOperator returns the current operator name from environment or config file
 func GetOperator() string {
 	if v := os.Getenv("DROVER_OPERATOR"); v != "" {
@@ -456,6 +917,66 @@ func (c *Config) CreateWebhookManager() *webhooks.Manager {
 	return mgr
 }
 
+// CreateStatusReporter creates a commit status reporter for PR mode
+// Returns nil when PR mode is off or no repository/token is configured
+func (c *Config) CreateStatusReporter() *webhooks.StatusReporter {
+	if !c.PRMode {
+		return nil
+	}
+	if c.GitHubRepo == "" || c.GitHubToken == "" {
+		log.Printf("⚠️  PR mode is on without DROVER_GITHUB_REPO/GITHUB_TOKEN, commit status checks are disabled")
+		return nil
+	}
+	return webhooks.NewStatusReporter(c.GitHubAPIURL, c.GitHubRepo, c.GitHubToken, c.StatusContext)
+}
+
+// CreateNotifier creates the notifier posting run summaries and failure
+// alerts to chat. Returns nil when no Slack or Discord webhook is configured
+func (c *Config) CreateNotifier() *notify.Notifier {
+	events, err := notify.ParseEvents(c.NotifyOn)
+	if err != nil {
+		fmt.Printf("[config] warning: DROVER_NOTIFY_ON: %v, sending every notification\n", err)
+	}
+	return notify.New(notify.Config{
+		SlackWebhookURL:   c.SlackWebhookURL,
+		DiscordWebhookURL: c.DiscordWebhookURL,
+		Events:            events,
+		FailuresOnly:      c.NotifyFailuresOnly,
+		DashboardURL:      c.DashboardURL,
+	})
+}
+
+// CreateJiraClient creates a Jira API client
+// Returns nil when no Jira URL or token is configured
+func (c *Config) CreateJiraClient() *integrations.JiraClient {
+	if c.JiraURL == "" || c.JiraToken == "" {
+		return nil
+	}
+	return integrations.NewJiraClient(c.JiraURL, c.JiraEmail, c.JiraToken)
+}
+
+// CreateJiraSync creates the syncer pushing task status transitions to Jira
+// Returns nil when Jira isn't configured or sync is turned off
+func (c *Config) CreateJiraSync() *integrations.JiraSync {
+	if !c.JiraSync {
+		return nil
+	}
+	client := c.CreateJiraClient()
+	if client == nil {
+		return nil
+	}
+	return integrations.NewJiraSync(client, c.JiraInProgressStatus, c.JiraDoneStatus)
+}
+
+// CreateLinearClient creates a Linear API client
+// Returns nil when no Linear API key is configured
+func (c *Config) CreateLinearClient() *integrations.LinearClient {
+	if c.LinearAPIKey == "" {
+		return nil
+	}
+	return integrations.NewLinearClient("", c.LinearAPIKey)
+}
+
 // CreateAnalyticsManager creates and configures an analytics manager from the config
 func (c *Config) CreateAnalyticsManager() (*analytics.Manager, error) {
 	if !c.AnalyticsEnabled {
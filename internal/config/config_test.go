@@ -54,3 +54,33 @@ func TestParseDurationOrDefault(t *testing.T) {
 		})
 	}
 }
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		input      string
+		wantLimit  int
+		wantPeriod time.Duration
+		wantErr    bool
+	}{
+		{"10/1m", 10, time.Minute, false},
+		{"30/m", 30, time.Minute, false},
+		{"5 / 30s", 5, 30 * time.Second, false},
+		{"0", 0, 0, false}, // lifts the limit
+		{"10", 0, 0, true},
+		{"-1/m", 0, 0, true},
+		{"10/soon", 0, 0, true},
+		{"10/0s", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			limit, period, err := ParseRateLimit(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRateLimit(%q) error = %v; want error %v", tt.input, err, tt.wantErr)
+			}
+			if limit != tt.wantLimit || period != tt.wantPeriod {
+				t.Errorf("ParseRateLimit(%q) = %d/%v; want %d/%v", tt.input, limit, period, tt.wantLimit, tt.wantPeriod)
+			}
+		})
+	}
+}
@@ -2,10 +2,18 @@ package dashboard
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
 	"net/http"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/attemptlog"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
 )
 
 // handleStatus returns the overall project statistics
@@ -32,6 +40,7 @@ func (s *Server) handleEpics(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 	epic := r.URL.Query().Get("epic")
 	status := r.URL.Query().Get("status")
+	operator := r.URL.Query().Get("operator")
 
 	// Validate status if provided
 	if status != "" {
@@ -42,7 +51,7 @@ func (s *Server) handleTasks(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	tasks, err := s.getTasks(epic, status)
+	tasks, err := s.getTasks(epic, status, operator)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -60,6 +69,22 @@ func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	id := strings.TrimPrefix(path, prefix)
+	if strings.HasSuffix(id, "/activity") {
+		s.handleTaskActivity(w, r)
+		return
+	}
+	if strings.HasSuffix(id, "/attempts") {
+		s.handleTaskAttempts(w, r)
+		return
+	}
+	if strings.HasSuffix(id, "/transcript") {
+		s.handleAttemptTranscript(w, r)
+		return
+	}
+	if strings.HasSuffix(id, "/log") {
+		s.handleAttemptLog(w, r)
+		return
+	}
 
 	task, err := s.getTask(id)
 	if err != nil {
@@ -73,6 +98,110 @@ func (s *Server) handleTask(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, task)
 }
 
+// handleTaskActivity returns a task's activity timeline
+func (s *Server) handleTaskActivity(w http.ResponseWriter, r *http.Request) {
+	// Extract ID from path "/api/tasks/{id}/activity"
+	id := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/activity"), "/api/tasks/")
+
+	activity, err := s.reader().ListActivity(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if activity == nil {
+		activity = []*types.TaskActivity{}
+	}
+	jsonResponse(w, activity)
+}
+
+// handleTaskAttempts returns a task's execution attempts, oldest first
+func (s *Server) handleTaskAttempts(w http.ResponseWriter, r *http.Request) {
+	// Extract ID from path "/api/tasks/{id}/attempts"
+	id := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/attempts"), "/api/tasks/")
+
+	attempts, err := s.reader().ListAttempts(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if attempts == nil {
+		attempts = []*types.TaskAttempt{}
+	}
+	jsonResponse(w, attempts)
+}
+
+// handleAttemptTranscript returns the agent output kept for one attempt
+func (s *Server) handleAttemptTranscript(w http.ResponseWriter, r *http.Request) {
+	// Extract ID and attempt from path "/api/tasks/{id}/attempts/{n}/transcript"
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/tasks/"), "/")
+	if len(parts) != 4 || parts[1] != "attempts" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	number, err := strconv.Atoi(parts[2])
+	if err != nil {
+		http.Error(w, "invalid attempt number", http.StatusBadRequest)
+		return
+	}
+
+	transcript, err := s.reader().GetTranscript(parts[0], number)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if transcript == "" {
+		http.Error(w, "no transcript kept for this attempt", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(transcript))
+}
+
+// handleAttemptLog returns an attempt's log file under .drover/logs, its
+// rotated parts first
+func (s *Server) handleAttemptLog(w http.ResponseWriter, r *http.Request) {
+	// Extract ID and attempt from path "/api/tasks/{id}/attempts/{n}/log"
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/tasks/"), "/")
+	if len(parts) != 4 || parts[1] != "attempts" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	number, err := strconv.Atoi(parts[2])
+	if err != nil {
+		http.Error(w, "invalid attempt number", http.StatusBadRequest)
+		return
+	}
+	if s.projectDir == "" {
+		http.Error(w, "attempt logs aren't served", http.StatusNotFound)
+		return
+	}
+
+	attempts, err := s.reader().ListAttempts(parts[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var outputPath string
+	for _, a := range attempts {
+		if a.Number == number {
+			outputPath = a.OutputPath
+		}
+	}
+	if outputPath == "" {
+		http.Error(w, "no log kept for this attempt", http.StatusNotFound)
+		return
+	}
+
+	logFile, err := attemptlog.Open(filepath.Join(s.projectDir, outputPath))
+	if err != nil {
+		http.Error(w, "log no longer kept: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	defer logFile.Close()
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	io.Copy(w, logFile)
+}
+
 // handlePauseTask pauses a running task
 func (s *Server) handlePauseTask(w http.ResponseWriter, r *http.Request) {
 	// Extract ID from path "/api/tasks/{id}/pause"
@@ -170,6 +299,71 @@ func (s *Server) handleAddGuidance(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, map[string]string{"status": "added", "id": guidance.ID})
 }
 
+// handleAssignOperator assigns the human or bot overseeing a task
+// An empty operator unassigns it
+func (s *Server) handleAssignOperator(w http.ResponseWriter, r *http.Request) {
+	// Extract ID from path "/api/tasks/{id}/assign"
+	id := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/assign"), "/api/tasks/")
+
+	var req struct {
+		Operator string `json:"operator"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	operator := strings.TrimSpace(req.Operator)
+	if err := s.store.AssignOperator(id, operator); err != nil {
+		if strings.HasPrefix(err.Error(), "task not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.broadcastTaskAssigned(id, operator)
+
+	jsonResponse(w, map[string]string{"status": "assigned", "operator": operator})
+}
+
+// handleMoveTask reorders a queued task: bump, pin, defer or reset
+// Running orchestrators pick the new order up on their next claim
+func (s *Server) handleMoveTask(w http.ResponseWriter, r *http.Request) {
+	// Extract ID from path "/api/tasks/{id}/move"
+	id := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/move"), "/api/tasks/")
+
+	var req struct {
+		Move string `json:"move"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	move := db.QueueMove(strings.TrimSpace(req.Move))
+	switch move {
+	case db.QueueBump, db.QueuePin, db.QueueDefer, db.QueueReset:
+	default:
+		http.Error(w, "move must be bump, pin, defer or reset", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.store.MoveTask(id, move); err != nil {
+		if strings.HasPrefix(err.Error(), "task not found") {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	s.broadcastTaskMoved(id, move)
+
+	jsonResponse(w, map[string]string{"status": "moved", "move": string(move)})
+}
+
 // handleWorkers returns active worker information
 func (s *Server) handleWorkers(w http.ResponseWriter, r *http.Request) {
 	workers, err := s.getWorkers()
@@ -196,7 +390,7 @@ func jsonResponse(w http.ResponseWriter, data any) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// handleTaskAction routes POST requests for task actions (pause, resume, guidance)
+// handleTaskAction routes POST requests for task actions (pause, resume, guidance, assign, move)
 func (s *Server) handleTaskAction(w http.ResponseWriter, r *http.Request) {
 	// Extract ID and action from path "/api/tasks/{id}/{action}"
 	path := r.URL.Path
@@ -222,6 +416,10 @@ func (s *Server) handleTaskAction(w http.ResponseWriter, r *http.Request) {
 		s.handleResumeTask(w, r)
 	case "guidance":
 		s.handleAddGuidance(w, r)
+	case "assign":
+		s.handleAssignOperator(w, r)
+	case "move":
+		s.handleMoveTask(w, r)
 	default:
 		http.Error(w, "unknown action", http.StatusBadRequest)
 	}
@@ -249,6 +447,22 @@ func (s *Server) broadcastTaskGuidance(taskID, message string) {
 	})
 }
 
+// broadcastTaskAssigned broadcasts a task operator assignment event
+func (s *Server) broadcastTaskAssigned(taskID, operator string) {
+	s.Broadcast(EventTaskAssigned, map[string]string{
+		"task_id":  taskID,
+		"operator": operator,
+	})
+}
+
+// broadcastTaskMoved broadcasts a task queue move event
+func (s *Server) broadcastTaskMoved(taskID string, move db.QueueMove) {
+	s.Broadcast(EventTaskMoved, map[string]string{
+		"task_id": taskID,
+		"move":    string(move),
+	})
+}
+
 // handleWorktreeFiles returns files in a task's worktree
 func (s *Server) handleWorktreeFiles(w http.ResponseWriter, r *http.Request) {
 	// Extract task ID and optional path from "/api/worktrees/{taskID}/files?path=xxx"
@@ -275,7 +489,7 @@ func (s *Server) handleWorktreeFiles(w http.ResponseWriter, r *http.Request) {
 
 	files, err := s.getWorktreeFiles(taskID, filePath)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		worktreeError(w, err)
 		return
 	}
 
@@ -308,7 +522,7 @@ func (s *Server) handleWorktreeFileContents(w http.ResponseWriter, r *http.Reque
 
 	content, err := s.getWorktreeFileContents(taskID, filePath)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		worktreeError(w, err)
 		return
 	}
 
@@ -328,6 +542,16 @@ func (s *Server) handleWorktreeFileContents(w http.ResponseWriter, r *http.Reque
 	w.Write([]byte(content))
 }
 
+// worktreeError reports a failed worktree lookup, as 404 when there is
+// nothing on disk to show
+func worktreeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNoWorktree) || errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
 // handleWorktreeAPI routes worktree requests based on suffix
 func (s *Server) handleWorktreeAPI(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Path
@@ -34,7 +34,10 @@ const (
 	EventTaskPaused     = "task_paused"
 	EventTaskResumed    = "task_resumed"
 	EventTaskGuidance   = "task_guidance"
+	EventTaskAssigned   = "task_assigned"
+	EventTaskMoved      = "task_moved"
 	EventWorkerStatus   = "worker_status"
+	EventAgentEvent     = "agent_event"
 	EventStatsUpdate    = "stats_update"
 )
 
@@ -48,6 +51,27 @@ type TaskEvent struct {
 	EpicID string `json:"epic_id,omitempty"`
 }
 
+// AgentEvent is broadcast for each step an agent reports while it works on
+// a task, such as a tool call
+type AgentEvent struct {
+	TaskID string `json:"task_id"`
+	Agent  string `json:"agent"`
+	Kind   string `json:"kind"` // message, tool_call, tool_result, result or error
+	Tool   string `json:"tool,omitempty"`
+	Text   string `json:"text,omitempty"`
+}
+
+// BroadcastAgentEvent broadcasts a step of an agent's work. Agents report
+// steps faster than a slow client may read them, so events that don't fit in
+// the broadcast queue are dropped rather than holding up the agent
+func BroadcastAgentEvent(event AgentEvent) {
+	dash := GetGlobal()
+	if dash == nil {
+		return
+	}
+	dash.tryBroadcast(EventAgentEvent, event)
+}
+
 // BroadcastTaskClaimed broadcasts when a worker claims a task
 func BroadcastTaskClaimed(taskID, title, worker string) {
 	dash := GetGlobal()
@@ -2,11 +2,14 @@ package dashboard
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/cloud-shuttle/drover/internal/git"
 )
 
 // Stats represents overall project statistics
@@ -24,14 +27,16 @@ type Stats struct {
 
 // EpicWithCount represents an epic with task counts
 type EpicWithCount struct {
-	ID          string `json:"id"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
-	TaskCount   int    `json:"task_count"`
-	Completed   int    `json:"completed"`
-	Ready       int    `json:"ready"`
-	Active      int    `json:"active"`
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	Status       string `json:"status"`
+	ParentEpicID string `json:"parent_epic_id,omitempty"`
+	Depth        int    `json:"depth"`      // Nesting level, 0 for top-level epics
+	TaskCount    int    `json:"task_count"` // Includes the tasks of sub-epics
+	Completed    int    `json:"completed"`
+	Ready        int    `json:"ready"`
+	Active       int    `json:"active"`
 }
 
 // TaskWithEpic represents a task with epic information
@@ -44,6 +49,7 @@ type TaskWithEpic struct {
 	ParentID       string  `json:"parent_id"`
 	SequenceNumber int     `json:"sequence_number"`
 	Priority       int     `json:"priority"`
+	EffectivePriority *int `json:"effective_priority,omitempty"` // Set when the task was moved in the queue
 	Status         string  `json:"status"`
 	Attempts       int     `json:"attempts"`
 	MaxAttempts    int     `json:"max_attempts"`
@@ -86,8 +92,11 @@ type Graph struct {
 func (s *Server) getStatus() (*Stats, error) {
 	stats := &Stats{}
 
+	ctx, cancel := s.queryContext()
+	defer cancel()
+
 	// Count by status
-	rows, err := s.db.Query(`
+	rows, err := s.reader().DB.QueryContext(ctx, `
 		SELECT status, COUNT(*) FROM tasks GROUP BY status
 	`)
 	if err != nil {
@@ -130,25 +139,35 @@ func (s *Server) getStatus() (*Stats, error) {
 	return stats, nil
 }
 
-// getEpics retrieves all epics with task counts
+// getEpics retrieves all epics with task counts. Counts include the tasks of
+// sub-epics, and sub-epics follow their parent epic
 func (s *Server) getEpics() ([]EpicWithCount, error) {
 	query := `
+		WITH RECURSIVE tree(root, id) AS (
+			SELECT id, id FROM epics
+			UNION
+			SELECT tree.root, e.id FROM epics e JOIN tree ON e.parent_epic_id = tree.id
+		)
 		SELECT
 			e.id,
 			e.title,
 			COALESCE(e.description, ''),
 			e.status,
+			COALESCE(e.parent_epic_id, ''),
 			COALESCE(COUNT(t.id), 0) as task_count,
 			COALESCE(SUM(CASE WHEN t.status = 'completed' THEN 1 ELSE 0 END), 0) as completed,
 			COALESCE(SUM(CASE WHEN t.status = 'ready' THEN 1 ELSE 0 END), 0) as ready,
 			COALESCE(SUM(CASE WHEN t.status IN ('claimed', 'in_progress') THEN 1 ELSE 0 END), 0) as active
 		FROM epics e
-		LEFT JOIN tasks t ON e.id = t.epic_id
+		JOIN tree ON tree.root = e.id
+		LEFT JOIN tasks t ON t.epic_id = tree.id
 		GROUP BY e.id
 		ORDER BY e.created_at ASC
 	`
 
-	rows, err := s.db.Query(query)
+	ctx, cancel := s.queryContext()
+	defer cancel()
+	rows, err := s.reader().DB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -157,18 +176,58 @@ func (s *Server) getEpics() ([]EpicWithCount, error) {
 	var epics []EpicWithCount
 	for rows.Next() {
 		var e EpicWithCount
-		if err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.Status,
+		if err := rows.Scan(&e.ID, &e.Title, &e.Description, &e.Status, &e.ParentEpicID,
 			&e.TaskCount, &e.Completed, &e.Ready, &e.Active); err != nil {
 			continue
 		}
 		epics = append(epics, e)
 	}
 
-	return epics, nil
+	return nestEpics(epics), nil
+}
+
+// nestEpics orders epics so each is followed by its sub-epics, setting their depth
+func nestEpics(epics []EpicWithCount) []EpicWithCount {
+	known := make(map[string]bool, len(epics))
+	children := make(map[string][]EpicWithCount)
+	for _, e := range epics {
+		known[e.ID] = true
+	}
+	var roots []EpicWithCount
+	for _, e := range epics {
+		if known[e.ParentEpicID] && e.ParentEpicID != e.ID {
+			children[e.ParentEpicID] = append(children[e.ParentEpicID], e)
+		} else {
+			roots = append(roots, e)
+		}
+	}
+
+	nested := make([]EpicWithCount, 0, len(epics))
+	placed := make(map[string]bool, len(epics))
+	var walk func(e EpicWithCount, depth int)
+	walk = func(e EpicWithCount, depth int) {
+		if placed[e.ID] {
+			return
+		}
+		placed[e.ID] = true
+		e.Depth = depth
+		nested = append(nested, e)
+		for _, child := range children[e.ID] {
+			walk(child, depth+1)
+		}
+	}
+	for _, e := range roots {
+		walk(e, 0)
+	}
+	// Epics in a parent cycle are unreachable from any root; list them flat
+	for _, e := range epics {
+		walk(e, 0)
+	}
+	return nested
 }
 
 // getTasks retrieves tasks with optional filters
-func (s *Server) getTasks(epic, status string) ([]TaskWithEpic, error) {
+func (s *Server) getTasks(epic, status, operator string) ([]TaskWithEpic, error) {
 	var rows *sql.Rows
 	var err error
 
@@ -177,7 +236,7 @@ func (s *Server) getTasks(epic, status string) ([]TaskWithEpic, error) {
 			t.id, t.title, COALESCE(t.description, ''),
 			COALESCE(t.epic_id, ''), COALESCE(e.title, ''),
 			COALESCE(t.parent_id, ''), t.sequence_number,
-			t.priority, t.status, t.attempts, t.max_attempts,
+			t.priority, t.effective_priority, t.status, t.attempts, t.max_attempts,
 			COALESCE(t.last_error, ''),
 			COALESCE(t.claimed_by, ''), COALESCE(t.claimed_at, 0),
 			COALESCE(t.operator, ''),
@@ -187,28 +246,40 @@ func (s *Server) getTasks(epic, status string) ([]TaskWithEpic, error) {
 	`
 
 	// Build WHERE clause
-	whereClause := ""
+	var conditions []string
 	args := []interface{}{}
 
-	if epic != "" && status != "" {
-		whereClause = " WHERE t.epic_id = ? AND t.status = ?"
-		args = []interface{}{epic, status}
-	} else if epic != "" {
-		whereClause = " WHERE t.epic_id = ?"
-		args = []interface{}{epic}
-	} else if status != "" {
-		whereClause = " WHERE t.status = ?"
-		args = []interface{}{status}
+	if epic != "" {
+		// Include the tasks of sub-epics
+		conditions = append(conditions, `t.epic_id IN (
+			WITH RECURSIVE subtree(id) AS (
+				SELECT ?
+				UNION
+				SELECT e2.id FROM epics e2 JOIN subtree ON e2.parent_epic_id = subtree.id
+			)
+			SELECT id FROM subtree
+		)`)
+		args = append(args, epic)
+	}
+	if status != "" {
+		conditions = append(conditions, "t.status = ?")
+		args = append(args, status)
+	}
+	if operator != "" {
+		conditions = append(conditions, "t.operator = ?")
+		args = append(args, operator)
 	}
 
-	query += whereClause + " ORDER BY t.priority DESC, t.created_at ASC"
-
-	if len(args) > 0 {
-		rows, err = s.db.Query(query, args...)
-	} else {
-		rows, err = s.db.Query(query)
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = " WHERE " + strings.Join(conditions, " AND ")
 	}
 
+	query += whereClause + " ORDER BY COALESCE(t.effective_priority, t.priority) DESC, t.created_at ASC"
+
+	ctx, cancel := s.queryContext()
+	defer cancel()
+	rows, err = s.reader().DB.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +292,7 @@ func (s *Server) getTasks(epic, status string) ([]TaskWithEpic, error) {
 			&t.ID, &t.Title, &t.Description,
 			&t.EpicID, &t.EpicTitle,
 			&t.ParentID, &t.SequenceNumber,
-			&t.Priority, &t.Status, &t.Attempts, &t.MaxAttempts,
+			&t.Priority, &t.EffectivePriority, &t.Status, &t.Attempts, &t.MaxAttempts,
 			&t.LastError,
 			&t.ClaimedBy, &t.ClaimedAt,
 			&t.Operator,
@@ -252,8 +323,11 @@ func (s *Server) getTask(id string) (*TaskWithEpic, error) {
 		WHERE t.id = ?
 	`
 
+	ctx, cancel := s.queryContext()
+	defer cancel()
+
 	var t TaskWithEpic
-	err := s.db.QueryRow(query, id).Scan(
+	err := s.reader().DB.QueryRowContext(ctx, query, id).Scan(
 		&t.ID, &t.Title, &t.Description,
 		&t.EpicID, &t.EpicTitle,
 		&t.ParentID, &t.SequenceNumber,
@@ -285,7 +359,9 @@ func (s *Server) getWorkers() ([]WorkerInfo, error) {
 		ORDER BY claimed_at ASC
 	`
 
-	rows, err := s.db.Query(query)
+	ctx, cancel := s.queryContext()
+	defer cancel()
+	rows, err := s.reader().DB.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -321,7 +397,11 @@ func (s *Server) getGraph() (*Graph, error) {
 		ORDER BY created_at ASC
 	`
 
-	rows, err := s.db.Query(nodeQuery)
+	ctx, cancel := s.queryContext()
+	defer cancel()
+	reader := s.reader()
+
+	rows, err := reader.DB.QueryContext(ctx, nodeQuery)
 	if err != nil {
 		return nil, err
 	}
@@ -342,7 +422,7 @@ func (s *Server) getGraph() (*Graph, error) {
 		ORDER BY task_id, blocked_by
 	`
 
-	rows, err = s.db.Query(edgeQuery)
+	rows, err = reader.DB.QueryContext(ctx, edgeQuery)
 	if err != nil {
 		return graph, nil // Return nodes even if edges fail
 	}
@@ -368,24 +448,39 @@ type WorktreeFile struct {
 	Modified int64  `json:"modified,omitempty"`
 }
 
+// errNoWorktree means a task has no worktree on disk to browse: it never got
+// one, or it was merged or cleaned up since
+var errNoWorktree = errors.New("no worktree to browse")
+
+// worktreePath resolves rel inside the worktree recorded for a task, refusing
+// paths that escape it
+func (s *Server) worktreePath(taskID, rel string) (string, error) {
+	wt, err := s.reader().GetWorktree(taskID)
+	if err != nil {
+		return "", err
+	}
+	if wt == nil {
+		return "", fmt.Errorf("%w: task %s never had a worktree", errNoWorktree, taskID)
+	}
+	if wt.Status == git.WorktreeRemoved {
+		return "", fmt.Errorf("%w: the worktree of task %s was removed", errNoWorktree, taskID)
+	}
+
+	full := filepath.Join(wt.Path, rel)
+	within, err := filepath.Rel(wt.Path, full)
+	if err != nil || within == ".." || strings.HasPrefix(within, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid file path")
+	}
+	return full, nil
+}
+
 // getWorktreeFiles lists files in a task's worktree
 func (s *Server) getWorktreeFiles(taskID, path string) ([]WorktreeFile, error) {
-	// Get task info to verify it exists and get worktree details
-	var worktreePath string
-	var taskStatus string
-	err := s.db.QueryRow(`
-		SELECT w.path, t.status
-		FROM worktrees w
-		LEFT JOIN tasks t ON w.task_id = t.id
-		WHERE w.task_id = ?
-	`, taskID).Scan(&worktreePath, &taskStatus)
+	fullPath, err := s.worktreePath(taskID, path)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build full path to requested directory
-	fullPath := filepath.Join(worktreePath, path)
-
 	// Read directory
 	entries, err := os.ReadDir(fullPath)
 	if err != nil {
@@ -418,21 +513,11 @@ func (s *Server) getWorktreeFiles(taskID, path string) ([]WorktreeFile, error) {
 
 // getWorktreeFileContents reads a file's contents from a worktree
 func (s *Server) getWorktreeFileContents(taskID, filePath string) (string, error) {
-	// Get worktree path
-	var worktreePath string
-	err := s.db.QueryRow(`
-		SELECT path FROM worktrees WHERE task_id = ?
-	`, taskID).Scan(&worktreePath)
+	cleanPath, err := s.worktreePath(taskID, filePath)
 	if err != nil {
 		return "", err
 	}
 
-	// Security check: prevent directory traversal
-	cleanPath := filepath.Join(worktreePath, filePath)
-	if !strings.HasPrefix(cleanPath, worktreePath) {
-		return "", fmt.Errorf("invalid file path")
-	}
-
 	// Read file (limit to 1MB for security)
 	content, err := os.ReadFile(cleanPath)
 	if err != nil {
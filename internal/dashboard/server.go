@@ -2,7 +2,6 @@ package dashboard
 
 import (
 	"context"
-	"database/sql"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -19,43 +18,74 @@ import (
 //go:embed static/*
 var staticFS embed.FS
 
+// defaultQueryTimeout bounds dashboard queries when Config.QueryTimeout is unset
+const defaultQueryTimeout = 5 * time.Second
+
 // Server is the dashboard HTTP server
 type Server struct {
-	db     *sql.DB
-	store  *db.Store
-	hub    *Hub
-	addr   string
-	server *http.Server
+	reader       func() *db.Store // Read-only store the dashboard queries, apart from the orchestrator's
+	closeReader  func() error
+	queryTimeout time.Duration
+	store        *db.Store // For the actions that change tasks
+	projectDir   string    // Where attempt logs are kept under .drover/logs
+	hub          *Hub
+	addr         string
+	server       *http.Server
 }
 
 // Config holds server configuration
 type Config struct {
 	Addr        string
 	DatabaseURL string
-	DB          *sql.DB // Pass existing connection
 	Store       *db.Store
+
+	// ProjectDir is the project whose attempt logs the dashboard serves
+	// (empty = none)
+	ProjectDir string
+
+	// Dashboard queries run on a separate read-only connection pool, so they
+	// never hold up claims. QueryTimeout bounds each one (default 5s); with
+	// SnapshotInterval set they read a copy of the database refreshed that
+	// often instead of the live file
+	QueryTimeout     time.Duration
+	SnapshotInterval time.Duration
 }
 
 // New creates a new dashboard server
 func New(cfg Config) (*Server, error) {
-	db := cfg.DB
-	if db == nil {
-		var err error
-		db, err = sql.Open("sqlite3", cfg.DatabaseURL)
+	s := &Server{
+		queryTimeout: cfg.QueryTimeout,
+		store:        cfg.Store,
+		projectDir:   cfg.ProjectDir,
+		hub:          newHub(),
+		addr:         cfg.Addr,
+	}
+	if s.queryTimeout <= 0 {
+		s.queryTimeout = defaultQueryTimeout
+	}
+
+	if cfg.SnapshotInterval > 0 {
+		snapshot, err := db.OpenSnapshot(cfg.DatabaseURL, cfg.SnapshotInterval)
+		if err != nil {
+			return nil, fmt.Errorf("open db snapshot: %w", err)
+		}
+		s.reader, s.closeReader = snapshot.Store, snapshot.Close
+	} else {
+		reader, err := db.OpenReadOnly(cfg.DatabaseURL)
 		if err != nil {
 			return nil, fmt.Errorf("open db: %w", err)
 		}
-	}
-
-	s := &Server{
-		db:    db,
-		store: cfg.Store,
-		hub:   newHub(),
-		addr:  cfg.Addr,
+		s.reader = func() *db.Store { return reader }
+		s.closeReader = reader.Close
 	}
 	return s, nil
 }
 
+// queryContext bounds a dashboard query by the query timeout
+func (s *Server) queryContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.queryTimeout)
+}
+
 // Start starts the HTTP server
 func (s *Server) Start() error {
 	mux := http.NewServeMux()
@@ -89,6 +119,7 @@ func (s *Server) Start() error {
 
 // Shutdown gracefully shuts down the server
 func (s *Server) Shutdown(ctx context.Context) error {
+	defer s.closeReader()
 	if s.server == nil {
 		return nil
 	}
@@ -100,6 +131,16 @@ func (s *Server) Broadcast(eventType string, data any) {
 	s.hub.broadcast <- Event{Type: eventType, Data: data}
 }
 
+// tryBroadcast broadcasts an event unless the broadcast queue is full
+func (s *Server) tryBroadcast(eventType string, data any) bool {
+	select {
+	case s.hub.broadcast <- Event{Type: eventType, Data: data}:
+		return true
+	default:
+		return false
+	}
+}
+
 func (s *Server) broadcastStats() {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
@@ -53,9 +53,11 @@
   function setupFilters() {
     const epicFilter = document.getElementById('filter-epic');
     const statusFilter = document.getElementById('filter-status');
+    const operatorFilter = document.getElementById('filter-operator');
 
     epicFilter.addEventListener('change', () => loadTasks());
     statusFilter.addEventListener('change', () => loadTasks());
+    operatorFilter.addEventListener('change', () => loadTasks());
   }
 
   // WebSocket Connection
@@ -127,6 +129,17 @@
         addActivity(`Guidance added to: ${msg.data.task_id}`, 'info');
         loadInitialData();
         break;
+      case 'task_assigned':
+        addActivity(msg.data.operator ? `Task ${msg.data.task_id} assigned to ${msg.data.operator}` : `Task ${msg.data.task_id} unassigned`, 'info');
+        loadInitialData();
+        break;
+      case 'agent_event':
+        if (msg.data.kind === 'tool_call') {
+          addActivity(`${msg.data.task_id} → ${msg.data.tool}${msg.data.text ? ': ' + truncate(msg.data.text, 80) : ''}`, 'info');
+        } else if (msg.data.kind === 'error') {
+          addActivity(`${msg.data.task_id} ⚠ ${truncate(msg.data.text || 'error', 80)}`, 'warning');
+        }
+        break;
     }
   }
 
@@ -184,6 +197,15 @@
     return res;
   }
 
+  async function assignOperator(taskId) {
+    const task = tasks.find(t => t.id === taskId);
+    const operator = prompt('Assign to operator (leave empty to unassign):', (task && task.operator) || '');
+    if (operator === null) return;
+    const res = await apiPost(`/api/tasks/${taskId}/assign`, { operator: operator.trim() });
+    if (res) loadTasks();
+    return res;
+  }
+
   async function loadInitialData() {
     stats = await api('/api/status');
     epics = await api('/api/epics') || [];
@@ -199,10 +221,12 @@
   async function loadTasks() {
     const epic = document.getElementById('filter-epic').value;
     const status = document.getElementById('filter-status').value;
+    const operator = document.getElementById('filter-operator').value.trim();
 
     let path = '/api/tasks?';
     if (epic) path += `epic=${encodeURIComponent(epic)}&`;
     if (status) path += `status=${status}&`;
+    if (operator) path += `operator=${encodeURIComponent(operator)}&`;
 
     tasks = await api(path) || [];
     renderTasks();
@@ -229,7 +253,7 @@
     epics.forEach(epic => {
       const opt = document.createElement('option');
       opt.value = epic.id;
-      opt.textContent = epic.title;
+      opt.textContent = '\u00a0\u00a0'.repeat(epic.depth || 0) + epic.title;
       select.appendChild(opt);
     });
     select.value = currentValue;
@@ -262,8 +286,9 @@
       return;
     }
 
+    // Sub-epics follow their parent and are indented under it
     container.innerHTML = epics.map(epic => `
-      <div class="epic-card">
+      <div class="epic-card${epic.depth ? ' sub-epic' : ''}" style="margin-left: ${(epic.depth || 0) * 30}px">
         <div class="epic-header">
           <h3>${escapeHtml(epic.title)}</h3>
           <span class="badge ${epic.status}">${epic.status}</span>
@@ -317,7 +342,10 @@
         <div class="task-guidance">
           <input type="text" id="guidance-${task.id}" placeholder="Add guidance..." class="guidance-input">
           <button class="btn-guidance" onclick="submitGuidance('${task.id}')">💡 Send</button>
+          <button class="btn-timeline" onclick="toggleTimeline('${task.id}')">🕘 Timeline</button>
+          <button class="btn-timeline" onclick="assignOperator('${task.id}')">👤 Assign</button>
         </div>
+        <div class="task-timeline" id="timeline-${task.id}" hidden></div>
       </div>
     `;
     }).join('');
@@ -339,6 +367,48 @@
     }
   }
 
+  async function toggleTimeline(taskId) {
+    const container = document.getElementById(`timeline-${taskId}`);
+    if (!container.hidden) {
+      container.hidden = true;
+      return;
+    }
+
+    const [activity, attempts] = await Promise.all([
+      api(`/api/tasks/${taskId}/activity`),
+      api(`/api/tasks/${taskId}/attempts`),
+    ]);
+    container.innerHTML = renderAttempts(attempts || []) + ((activity && activity.length) ? activity.map(entry => `
+      <div class="timeline-entry ${entry.kind}">
+        <span class="timeline-time">${new Date(entry.created_at * 1000).toLocaleString()}</span>
+        <span class="timeline-kind">${escapeHtml(entry.kind)}</span>
+        ${entry.author ? `<span class="timeline-author">${escapeHtml(entry.author)}</span>` : ''}
+        <span class="timeline-body">${escapeHtml(entry.body)}</span>
+      </div>
+    `).join('') : '<div class="empty-state">No activity yet</div>');
+    container.hidden = false;
+  }
+
+  function renderAttempts(attempts) {
+    if (!attempts.length) return '';
+    return '<div class="task-attempts">' + attempts.map(a => {
+      const duration = a.ended_at ? formatDuration(a.ended_at - a.started_at) : 'running';
+      return `
+        <div class="attempt-entry ${escapeHtml(a.outcome || '')}">
+          <span class="attempt-number">#${a.number}</span>
+          <span class="timeline-time">${new Date(a.started_at * 1000).toLocaleString()}</span>
+          <span class="timeline-author">${escapeHtml(a.worker_id)}</span>
+          <span class="attempt-outcome">${escapeHtml(a.outcome || 'running')}</span>
+          <span class="timeline-time">${duration}</span>
+          ${a.verdict ? `<span class="timeline-kind">${escapeHtml(a.verdict)}</span>` : ''}
+          ${a.transcript_size ? `<a class="attempt-output" href="/api/tasks/${encodeURIComponent(a.task_id)}/attempts/${a.number}/transcript" target="_blank">transcript (${formatFileSize(a.transcript_size)})</a>` : ''}
+          ${a.output_path ? `<a class="attempt-output" href="/api/tasks/${encodeURIComponent(a.task_id)}/attempts/${a.number}/log" target="_blank">${escapeHtml(a.output_path)}</a>` : ''}
+          ${a.error ? `<div class="attempt-error">${escapeHtml(a.error)}</div>` : ''}
+        </div>
+      `;
+    }).join('') + '</div>';
+  }
+
   function renderWorkers() {
     const container = document.getElementById('workers-list');
     if (!workers.length) {
@@ -646,6 +716,8 @@
   window.pauseTask = pauseTask;
   window.resumeTask = resumeTask;
   window.submitGuidance = submitGuidance;
+  window.toggleTimeline = toggleTimeline;
+  window.assignOperator = assignOperator;
   window.openWorktreeModal = openWorktreeModal;
   window.closeWorktreeModal = closeWorktreeModal;
   window.navigateToPath = navigateToPath;
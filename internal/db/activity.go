@@ -0,0 +1,125 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// execer is satisfied by both *sql.DB and *sql.Tx so activity can be written
+// in the same transaction as the change it describes
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// AddComment adds a human note to a task's activity timeline
+func (s *Store) AddComment(taskID, author, body string) (*types.TaskActivity, error) {
+	return s.RecordActivity(taskID, types.ActivityComment, author, body)
+}
+
+// RecordActivity appends an entry to a task's activity timeline
+func (s *Store) RecordActivity(taskID string, kind types.ActivityKind, author, body string) (*types.TaskActivity, error) {
+	activity, err := recordActivity(s.DB, taskID, kind, author, body)
+	if err != nil {
+		return nil, fmt.Errorf("recording %s activity: %w", kind, err)
+	}
+	return activity, nil
+}
+
+// ListActivity returns a task's activity timeline, oldest first
+func (s *Store) ListActivity(taskID string) ([]*types.TaskActivity, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, task_id, kind, COALESCE(author, ''), body, created_at
+		FROM task_activity
+		WHERE task_id = ?
+		ORDER BY created_at ASC, rowid ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("querying activity: %w", err)
+	}
+	defer rows.Close()
+
+	var activity []*types.TaskActivity
+	for rows.Next() {
+		var a types.TaskActivity
+		if err := rows.Scan(&a.ID, &a.TaskID, &a.Kind, &a.Author, &a.Body, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning activity: %w", err)
+		}
+		activity = append(activity, &a)
+	}
+	return activity, rows.Err()
+}
+
+// recordActivity inserts a timeline entry through db or an open transaction
+func recordActivity(db execer, taskID string, kind types.ActivityKind, author, body string) (*types.TaskActivity, error) {
+	activity := &types.TaskActivity{
+		ID:        generateID("activity"),
+		TaskID:    taskID,
+		Kind:      kind,
+		Author:    author,
+		Body:      body,
+		CreatedAt: time.Now().Unix(),
+	}
+	_, err := db.Exec(`
+		INSERT INTO task_activity (id, task_id, kind, author, body, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, activity.ID, activity.TaskID, activity.Kind, activity.Author, activity.Body, activity.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return activity, nil
+}
+
+// recordStatusChange adds the transition from a task's current status to to
+// onto its timeline, e.g. "in_progress → failed: tests failed", and appends it
+// to the audit log. It must run in the same transaction as, and before, the
+// UPDATE it describes. With from set, only tasks in one of those statuses are
+// recorded, mirroring the UPDATE's guard; a task already in to is never recorded
+func recordStatusChange(db execer, taskID string, to types.TaskStatus, actor, note string, from ...types.TaskStatus) error {
+	cond := "id = ?"
+	args := []any{taskID}
+	if len(from) > 0 {
+		placeholders := make([]string, len(from))
+		for i, status := range from {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		cond += " AND status IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	return recordStatusChanges(db, to, actor, note, cond, args...)
+}
+
+// recordStatusChanges is recordStatusChange for every task matching cond (a
+// condition on tasks with its args), for bulk updates
+func recordStatusChanges(db execer, to types.TaskStatus, actor, note, cond string, args ...any) error {
+	suffix := " → " + string(to)
+	if note != "" {
+		suffix += ": " + note
+	}
+	activityArgs := append([]any{generateID("activity"), types.ActivityStatus, actor, suffix, time.Now().Unix(), to}, args...)
+	_, err := db.Exec(`
+		INSERT INTO task_activity (id, task_id, kind, author, body, created_at)
+		SELECT ? || '-' || id, id, ?, ?, status || ?, ? FROM tasks
+		WHERE status != ? AND (`+cond+`)`, activityArgs...)
+	if err != nil {
+		return fmt.Errorf("recording status change: %w", err)
+	}
+
+	return auditTransitions(db, actor, to, note, cond, args...)
+}
+
+// recordTransition is recordStatusChange for a transition whose previous
+// status is already known, e.g. because the UPDATE has already run
+func recordTransition(db execer, taskID string, from, to types.TaskStatus, actor, note string) error {
+	body := fmt.Sprintf("%s → %s", from, to)
+	if note != "" {
+		body += ": " + note
+	}
+	if _, err := recordActivity(db, taskID, types.ActivityStatus, actor, body); err != nil {
+		return fmt.Errorf("recording status change: %w", err)
+	}
+	return auditTransition(db, taskID, actor, from, to, note)
+}
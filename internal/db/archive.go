@@ -0,0 +1,301 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// archiveSchema creates the tables finished tasks are moved to by ArchiveTasks
+// They mirror the live tables without foreign keys, plus when each row was
+// archived, so the live tables the orchestrator polls stay small
+const archiveSchema = `
+	CREATE TABLE IF NOT EXISTS archived_tasks (
+		id TEXT PRIMARY KEY,
+		title TEXT NOT NULL,
+		description TEXT,
+		epic_id TEXT,
+		parent_id TEXT,
+		sequence_number INTEGER DEFAULT 0,
+		type TEXT,
+		priority INTEGER DEFAULT 0,
+		status TEXT,
+		attempts INTEGER DEFAULT 0,
+		max_attempts INTEGER DEFAULT 3,
+		last_error TEXT,
+		claimed_by TEXT,
+		claimed_at INTEGER,
+		operator TEXT,
+		verdict TEXT,
+		verdict_reason TEXT,
+		test_mode TEXT,
+		test_scope TEXT,
+		test_command TEXT,
+		commit_author TEXT,
+		commit_sha TEXT,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL,
+		archived_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_archived_tasks_archived ON archived_tasks(archived_at);
+	CREATE INDEX IF NOT EXISTS idx_archived_tasks_epic ON archived_tasks(epic_id);
+
+	CREATE TABLE IF NOT EXISTS archived_task_dependencies (
+		task_id TEXT NOT NULL,
+		blocked_by TEXT NOT NULL,
+		PRIMARY KEY (task_id, blocked_by)
+	);
+
+	CREATE TABLE IF NOT EXISTS archived_task_activity (
+		id TEXT PRIMARY KEY,
+		task_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		author TEXT,
+		body TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_archived_task_activity_task ON archived_task_activity(task_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS archived_events (
+		id TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		timestamp INTEGER NOT NULL,
+		task_id TEXT NOT NULL,
+		epic_id TEXT,
+		data TEXT
+	);
+	CREATE INDEX IF NOT EXISTS idx_archived_events_task ON archived_events(task_id);
+`
+
+// archivedTaskColumns are copied from tasks into archived_tasks
+const archivedTaskColumns = `id, title, description, epic_id, parent_id, sequence_number, type,
+	priority, status, attempts, max_attempts, last_error, claimed_by, claimed_at,
+	operator, verdict, verdict_reason, test_mode, test_scope, test_command,
+	commit_author, commit_sha, input_tokens, output_tokens, cost_usd,
+	retry_policy, mutex_key, repo, env, scheduled_at, due_at, effective_priority,
+	external_ref, created_at, updated_at`
+
+// archivedAttemptColumns are copied from task_attempts into archived_task_attempts
+const archivedAttemptColumns = `id, task_id, number, worker_id, started_at, ended_at, outcome,
+	error, verdict, output_path, transcript, transcript_size, session_id, model`
+
+// terminalStatuses are the task states ArchiveTasks may move out of the live tables
+const terminalStatuses = `('completed', 'failed', 'cancelled')`
+
+// ArchiveResult counts what one ArchiveTasks or PurgeArchive call moved or deleted
+type ArchiveResult struct {
+	Tasks    int
+	Activity int
+	Attempts int
+	Events   int
+}
+
+// ArchiveTasks moves tasks that finished (completed, failed or cancelled) more
+// than olderThan ago into the archive tables, together with their
+// dependencies, activity, attempts and events. Checkpoints, guidance,
+// worktree rows, plans and conversations are deleted with them; the audit log is kept as is.
+// Sub-task trees are archived whole, and only once every task in them has
+// finished; tasks that an unfinished task still depends on are kept
+func (s *Store) ArchiveTasks(olderThan time.Duration) (*ArchiveResult, error) {
+	cutoff := time.Now().Add(-olderThan).Unix()
+	now := time.Now().Unix()
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Copy whole sub-task trees whose every task finished before the cutoff and
+	// that no unfinished task outside the tree is blocked by. Writing first takes
+	// the write lock before anything is read, so a concurrent run can't
+	// invalidate this transaction's snapshot
+	_, err = tx.Exec(`
+		INSERT OR REPLACE INTO archived_tasks (`+archivedTaskColumns+`, archived_at)
+		WITH RECURSIVE tree(root, id) AS (
+			SELECT id, id FROM tasks WHERE COALESCE(parent_id, '') = ''
+			UNION ALL
+			SELECT tree.root, t.id FROM tasks t JOIN tree ON t.parent_id = tree.id
+		)
+		SELECT `+archivedTaskColumns+`, ? FROM tasks
+		WHERE id IN (
+			SELECT tree.id FROM tree
+			WHERE tree.root IN (
+				SELECT tree.root FROM tree JOIN tasks t ON t.id = tree.id
+				GROUP BY tree.root
+				HAVING SUM(t.status NOT IN `+terminalStatuses+`) = 0
+				   AND MAX(t.updated_at) < ?
+				   AND SUM(EXISTS (
+						SELECT 1 FROM task_dependencies d JOIN tasks dep ON dep.id = d.task_id
+						WHERE d.blocked_by = t.id AND dep.status NOT IN `+terminalStatuses+`
+				   )) = 0
+			)
+		)
+	`, now, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("archiving tasks: %w", err)
+	}
+
+	// This batch is whatever was archived just now and is still live
+	if _, err := tx.Exec(`CREATE TEMP TABLE IF NOT EXISTS archive_batch (id TEXT PRIMARY KEY)`); err != nil {
+		return nil, fmt.Errorf("creating archive batch: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM archive_batch`); err != nil {
+		return nil, fmt.Errorf("clearing archive batch: %w", err)
+	}
+	res, err := tx.Exec(`
+		INSERT INTO archive_batch (id)
+		SELECT id FROM archived_tasks WHERE archived_at = ? AND id IN (SELECT id FROM tasks)
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("collecting archive batch: %w", err)
+	}
+	result := &ArchiveResult{Tasks: rowsAffected(res)}
+	if result.Tasks == 0 {
+		return result, tx.Commit()
+	}
+
+	const batch = `(SELECT id FROM archive_batch)`
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO archived_task_dependencies (task_id, blocked_by)
+		SELECT task_id, blocked_by FROM task_dependencies WHERE task_id IN ` + batch); err != nil {
+		return nil, fmt.Errorf("archiving task dependencies: %w", err)
+	}
+	res, err = tx.Exec(`
+		INSERT OR IGNORE INTO archived_task_activity (id, task_id, kind, author, body, created_at)
+		SELECT id, task_id, kind, author, body, created_at FROM task_activity WHERE task_id IN ` + batch)
+	if err != nil {
+		return nil, fmt.Errorf("archiving task activity: %w", err)
+	}
+	result.Activity = rowsAffected(res)
+	res, err = tx.Exec(`
+		INSERT OR IGNORE INTO archived_task_attempts (` + archivedAttemptColumns + `)
+		SELECT ` + archivedAttemptColumns + ` FROM task_attempts WHERE task_id IN ` + batch)
+	if err != nil {
+		return nil, fmt.Errorf("archiving task attempts: %w", err)
+	}
+	result.Attempts = rowsAffected(res)
+
+	// Tables created by migrations may not exist in a freshly initialized database
+	hasEvents, err := tableExists(tx, "events")
+	if err != nil {
+		return nil, err
+	}
+	if hasEvents {
+		res, err := tx.Exec(`
+			INSERT OR IGNORE INTO archived_events (id, type, timestamp, task_id, epic_id, data)
+			SELECT id, type, timestamp, task_id, epic_id, data FROM events WHERE task_id IN ` + batch)
+		if err != nil {
+			return nil, fmt.Errorf("archiving events: %w", err)
+		}
+		result.Events = rowsAffected(res)
+	}
+
+	// Delete the live rows explicitly: foreign keys are only enforced on the
+	// connection that enabled them, so cascades can't be relied on
+	deletes := []string{
+		`DELETE FROM task_dependencies WHERE task_id IN ` + batch + ` OR blocked_by IN ` + batch,
+		`DELETE FROM worktrees WHERE task_id IN ` + batch,
+		`DELETE FROM guidance_queue WHERE task_id IN ` + batch,
+		`DELETE FROM task_checkpoints WHERE task_id IN ` + batch,
+		`DELETE FROM task_attempts WHERE task_id IN ` + batch,
+		`DELETE FROM task_activity WHERE task_id IN ` + batch,
+	}
+	if hasEvents {
+		deletes = append(deletes, `DELETE FROM events WHERE task_id IN `+batch)
+	}
+	if exists, err := tableExists(tx, "plans"); err != nil {
+		return nil, err
+	} else if exists {
+		deletes = append(deletes, `DELETE FROM plans WHERE task_id IN `+batch)
+	}
+	if exists, err := tableExists(tx, "conversations"); err != nil {
+		return nil, err
+	} else if exists {
+		deletes = append(deletes,
+			`DELETE FROM conversation_turns WHERE conversation_id IN (SELECT id FROM conversations WHERE task_id IN `+batch+`)`,
+			`DELETE FROM conversations WHERE task_id IN `+batch)
+	}
+	deletes = append(deletes, `DELETE FROM tasks WHERE id IN `+batch, `DELETE FROM archive_batch`)
+	for _, stmt := range deletes {
+		if _, err := tx.Exec(stmt); err != nil {
+			return nil, fmt.Errorf("deleting archived tasks: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PurgeArchive permanently deletes tasks archived more than olderThan ago,
+// along with their archived dependencies, activity, attempts and events
+func (s *Store) PurgeArchive(olderThan time.Duration) (*ArchiveResult, error) {
+	cutoff := time.Now().Add(-olderThan).Unix()
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	const purged = `SELECT id FROM archived_tasks WHERE archived_at < ?`
+	result := &ArchiveResult{}
+
+	if _, err := tx.Exec(`DELETE FROM archived_task_dependencies WHERE task_id IN (`+purged+`)`, cutoff); err != nil {
+		return nil, fmt.Errorf("purging archived dependencies: %w", err)
+	}
+	res, err := tx.Exec(`DELETE FROM archived_task_activity WHERE task_id IN (`+purged+`)`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("purging archived activity: %w", err)
+	}
+	result.Activity = rowsAffected(res)
+	res, err = tx.Exec(`DELETE FROM archived_task_attempts WHERE task_id IN (`+purged+`)`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("purging archived attempts: %w", err)
+	}
+	result.Attempts = rowsAffected(res)
+	res, err = tx.Exec(`DELETE FROM archived_events WHERE task_id IN (`+purged+`)`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("purging archived events: %w", err)
+	}
+	result.Events = rowsAffected(res)
+	res, err = tx.Exec(`DELETE FROM archived_tasks WHERE archived_at < ?`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("purging archived tasks: %w", err)
+	}
+	result.Tasks = rowsAffected(res)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ArchiveStats returns how many tasks are live and archived
+func (s *Store) ArchiveStats() (live, archived int, err error) {
+	err = s.DB.QueryRow(`
+		SELECT (SELECT COUNT(*) FROM tasks), (SELECT COUNT(*) FROM archived_tasks)
+	`).Scan(&live, &archived)
+	return live, archived, err
+}
+
+// tableExists reports whether a table exists in the database
+func tableExists(tx *sql.Tx, name string) (bool, error) {
+	var exists bool
+	err := tx.QueryRow(`SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("checking for %s table: %w", name, err)
+	}
+	return exists, nil
+}
+
+// rowsAffected returns the affected row count, or 0 if the driver can't tell
+func rowsAffected(res sql.Result) int {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return int(n)
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// StartAttempt records that a worker started executing a task and returns
+// the new attempt, numbered after the task's earlier ones
+func (s *Store) StartAttempt(taskID, workerID string) (*types.TaskAttempt, error) {
+	attempt := &types.TaskAttempt{
+		TaskID:    taskID,
+		WorkerID:  workerID,
+		StartedAt: time.Now().Unix(),
+	}
+	err := s.DB.QueryRow(`
+		INSERT INTO task_attempts (task_id, number, worker_id, started_at)
+		VALUES (?, (SELECT COALESCE(MAX(number), 0) + 1 FROM task_attempts WHERE task_id = ?), ?, ?)
+		RETURNING id, number
+	`, taskID, taskID, workerID, attempt.StartedAt).Scan(&attempt.ID, &attempt.Number)
+	if err != nil {
+		return nil, fmt.Errorf("recording attempt: %w", err)
+	}
+	return attempt, nil
+}
+
+// FinishAttempt ends an attempt, recording the status, error and verdict it
+// left the task with and where the agent's output was saved, if anywhere.
+// errMsg, when set, is recorded instead of the task's last error
+func (s *Store) FinishAttempt(attemptID int64, outputPath, errMsg string) error {
+	_, err := s.DB.Exec(`
+		UPDATE task_attempts
+		SET ended_at = ?,
+		    outcome = t.status,
+		    error = CASE
+		        WHEN ? != '' THEN ?
+		        WHEN t.status = 'completed' THEN NULL
+		        ELSE NULLIF(t.last_error, '')
+		    END,
+		    verdict = NULLIF(t.verdict, 'unknown'),
+		    output_path = NULLIF(?, '')
+		FROM tasks t
+		WHERE task_attempts.id = ? AND t.id = task_attempts.task_id
+	`, time.Now().Unix(), errMsg, errMsg, outputPath, attemptID)
+	if err != nil {
+		return fmt.Errorf("finishing attempt: %w", err)
+	}
+	return nil
+}
+
+// SetAttemptSession records the agent session an attempt ran in, so a
+// retry can resume it
+func (s *Store) SetAttemptSession(attemptID int64, sessionID string) error {
+	_, err := s.DB.Exec(`UPDATE task_attempts SET session_id = NULLIF(?, '') WHERE id = ?`, sessionID, attemptID)
+	if err != nil {
+		return fmt.Errorf("recording attempt session: %w", err)
+	}
+	return nil
+}
+
+// SetAttemptModel records the agent and model that ran an attempt, when
+// the task could fall back to another
+func (s *Store) SetAttemptModel(attemptID int64, model string) error {
+	_, err := s.DB.Exec(`UPDATE task_attempts SET model = NULLIF(?, '') WHERE id = ?`, model, attemptID)
+	if err != nil {
+		return fmt.Errorf("recording attempt model: %w", err)
+	}
+	return nil
+}
+
+// ListAttempts returns a task's attempts, first to last
+func (s *Store) ListAttempts(taskID string) ([]*types.TaskAttempt, error) {
+	return s.queryAttempts(`WHERE task_id = ? ORDER BY number`, taskID)
+}
+
+// ListAttemptsSince returns the attempts of every task started at or after
+// since (Unix seconds), oldest first
+func (s *Store) ListAttemptsSince(since int64) ([]*types.TaskAttempt, error) {
+	return s.queryAttempts(`WHERE started_at >= ? ORDER BY started_at, id`, since)
+}
+
+// queryAttempts returns the attempts matching a WHERE and ORDER BY clause
+func (s *Store) queryAttempts(clause string, args ...any) ([]*types.TaskAttempt, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, task_id, number, worker_id, started_at, ended_at,
+		       COALESCE(outcome, ''), COALESCE(error, ''), COALESCE(verdict, ''), COALESCE(output_path, ''),
+		       transcript_size, COALESCE(session_id, ''), COALESCE(model, '')
+		FROM task_attempts
+		`+clause, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying attempts: %w", err)
+	}
+	defer rows.Close()
+
+	var attempts []*types.TaskAttempt
+	for rows.Next() {
+		var a types.TaskAttempt
+		var endedAt sql.NullInt64
+		if err := rows.Scan(&a.ID, &a.TaskID, &a.Number, &a.WorkerID, &a.StartedAt, &endedAt,
+			&a.Outcome, &a.Error, &a.Verdict, &a.OutputPath, &a.TranscriptSize, &a.SessionID, &a.Model); err != nil {
+			return nil, fmt.Errorf("scanning attempt: %w", err)
+		}
+		a.EndedAt = nullableUnix(endedAt)
+		attempts = append(attempts, &a)
+	}
+	return attempts, rows.Err()
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// auditSchema creates the append-only audit log of task status transitions
+// It has no foreign key to tasks so history outlives the task, and triggers
+// reject any UPDATE or DELETE so recorded events can't be rewritten
+const auditSchema = `
+	CREATE TABLE IF NOT EXISTS task_audit (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		from_status TEXT,
+		to_status TEXT NOT NULL,
+		error TEXT,
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_task_audit_task ON task_audit(task_id);
+
+	CREATE TRIGGER IF NOT EXISTS task_audit_no_update BEFORE UPDATE ON task_audit BEGIN
+		SELECT RAISE(ABORT, 'task_audit is append-only');
+	END;
+	CREATE TRIGGER IF NOT EXISTS task_audit_no_delete BEFORE DELETE ON task_audit BEGIN
+		SELECT RAISE(ABORT, 'task_audit is append-only');
+	END;
+`
+
+// auditActor picks who a transition is attributed to: the explicit actor, then
+// the worker holding the task, then drover itself
+const auditActor = `COALESCE(NULLIF(?, ''), NULLIF(claimed_by, ''), 'drover')`
+
+// SetActor sets who status changes made through this store are attributed to,
+// e.g. the operator running a CLI command. Without one, changes are attributed
+// to the worker that claimed the task, or to drover itself
+func (s *Store) SetActor(actor string) {
+	s.actor = actor
+}
+
+// actingAs returns actor if set, otherwise the store's actor
+func (s *Store) actingAs(actor string) string {
+	if actor != "" {
+		return actor
+	}
+	return s.actor
+}
+
+// ListAudit returns the audit events recorded for a task, oldest first
+// Events outlive the task itself, so this also works for deleted tasks
+func (s *Store) ListAudit(taskID string) ([]*types.AuditEvent, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, task_id, actor, COALESCE(from_status, ''), to_status, COALESCE(error, ''), created_at
+		FROM task_audit
+		WHERE task_id = ?
+		ORDER BY id ASC
+	`, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*types.AuditEvent
+	for rows.Next() {
+		var e types.AuditEvent
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.Actor, &e.From, &e.To, &e.Error, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning audit event: %w", err)
+		}
+		events = append(events, &e)
+	}
+	return events, rows.Err()
+}
+
+// auditTransitions appends an audit event for every task matching cond (a
+// condition on tasks with its args) whose status is about to become to. Like
+// recordStatusChange it must run before the UPDATE, in the same transaction
+func auditTransitions(db execer, actor string, to types.TaskStatus, errMsg, cond string, args ...any) error {
+	args = append([]any{actor, to, errMsg, time.Now().Unix(), to}, args...)
+	_, err := db.Exec(`
+		INSERT INTO task_audit (task_id, actor, from_status, to_status, error, created_at)
+		SELECT id, `+auditActor+`, status, ?, NULLIF(?, ''), ? FROM tasks
+		WHERE status != ? AND (`+cond+`)`, args...)
+	if err != nil {
+		return fmt.Errorf("writing audit event: %w", err)
+	}
+	return nil
+}
+
+// auditTransition appends one audit event whose previous status is already known
+func auditTransition(db execer, taskID, actor string, from, to types.TaskStatus, errMsg string) error {
+	_, err := db.Exec(`
+		INSERT INTO task_audit (task_id, actor, from_status, to_status, error, created_at)
+		SELECT id, `+auditActor+`, ?, ?, NULLIF(?, ''), ? FROM tasks
+		WHERE id = ?`, actor, from, to, errMsg, time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("writing audit event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// NoRef marks a NewTask with no epic or no parent in the batch
+const NoRef = -1
+
+// NewEpic is an epic for CreateTasksBulk to create
+type NewEpic struct {
+	Title       string
+	Description string
+}
+
+// NewTask is a task for CreateTasksBulk to create. Epic, Parent and
+// BlockedBy refer to other entries of the same batch by index
+type NewTask struct {
+	Title       string
+	Description string
+	Priority    int
+	Epic        int   // Index into the batch's epics, or NoRef; sub-tasks take their parent's
+	Parent      int   // Index of the parent task, which must come earlier, or NoRef
+	BlockedBy   []int // Indexes of the tasks this one waits for, in any order
+	TestMode    string
+	TestScope   string
+	MutexKey    string // Tasks sharing the key never run at once
+}
+
+// BulkTasks is a set of epics and tasks to create together
+type BulkTasks struct {
+	Epics []NewEpic
+	Tasks []NewTask
+}
+
+// BulkResult is what CreateTasksBulk created, in the order it was given
+type BulkResult struct {
+	Epics []*types.Epic
+	Tasks []*types.Task
+}
+
+// CreateTasksBulk creates a batch of epics, tasks, sub-tasks and the
+// dependencies between them in one transaction. If any entry is invalid,
+// e.g. refers to an index out of range or closes a dependency cycle, nothing
+// is created
+func (s *Store) CreateTasksBulk(batch BulkTasks) (*BulkResult, error) {
+	if err := batch.validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	result := &BulkResult{}
+
+	for _, e := range batch.Epics {
+		epic := &types.Epic{
+			Title:       e.Title,
+			Description: e.Description,
+			Status:      types.EpicStatusOpen,
+			CreatedAt:   now,
+		}
+		var err error
+		epic.ID, err = s.insertWithID("epics", "epic", func(id string) error {
+			_, err := tx.Exec(`
+				INSERT INTO epics (id, title, description, status, created_at)
+				VALUES (?, ?, ?, ?, ?)
+			`, id, epic.Title, epic.Description, epic.Status, epic.CreatedAt)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating epic %q: %w", e.Title, err)
+		}
+		result.Epics = append(result.Epics, epic)
+	}
+
+	subTasks := make(map[int]int) // Sub-tasks created so far per parent index
+	for i, t := range batch.Tasks {
+		task := &types.Task{
+			Title:       t.Title,
+			Description: t.Description,
+			Priority:    t.Priority,
+			Status:      types.TaskStatusReady,
+			MaxAttempts: 3,
+			TestMode:    t.TestMode,
+			TestScope:   t.TestScope,
+			MutexKey:    strings.TrimSpace(t.MutexKey),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if t.Epic != NoRef {
+			task.EpicID = result.Epics[t.Epic].ID
+		}
+		if t.Parent != NoRef {
+			parent := result.Tasks[t.Parent]
+			subTasks[t.Parent]++
+			task.SequenceNumber = subTasks[t.Parent]
+			task.ID = fmt.Sprintf("%s.%d", parent.ID, task.SequenceNumber)
+			task.ParentID = parent.ID
+			task.EpicID = parent.EpicID
+		}
+		if len(t.BlockedBy) > 0 {
+			task.Status = types.TaskStatusBlocked
+		}
+
+		var epicIDValue, parentIDValue, sequenceValue, mutexKeyValue any
+		if task.EpicID != "" {
+			epicIDValue = task.EpicID
+		}
+		if task.MutexKey != "" {
+			mutexKeyValue = task.MutexKey
+		}
+		if task.ParentID != "" {
+			parentIDValue, sequenceValue = task.ParentID, task.SequenceNumber
+		}
+		insert := func(id string) error {
+			_, err := tx.Exec(`
+				INSERT INTO tasks (id, title, description, epic_id, parent_id, sequence_number, type, priority,
+				                   status, test_mode, test_scope, mutex_key, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, id, task.Title, task.Description, epicIDValue, parentIDValue, sequenceValue, task.Type, task.Priority,
+				task.Status, task.TestMode, task.TestScope, mutexKeyValue, task.CreatedAt, task.UpdatedAt)
+			return err
+		}
+		var err error
+		if task.ParentID != "" {
+			err = insert(task.ID) // Sub-task IDs follow from their parent's
+		} else {
+			task.ID, err = s.insertWithID("tasks", "task", insert)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("creating task %d (%s): %w", i, t.Title, err)
+		}
+		result.Tasks = append(result.Tasks, task)
+	}
+
+	// Dependencies last, so tasks can wait for ones later in the batch
+	for i, t := range batch.Tasks {
+		for _, b := range t.BlockedBy {
+			_, err := tx.Exec(`
+				INSERT OR IGNORE INTO task_dependencies (task_id, blocked_by)
+				VALUES (?, ?)
+			`, result.Tasks[i].ID, result.Tasks[b].ID)
+			if err != nil {
+				return nil, fmt.Errorf("adding dependency of task %d on %d: %w", i, b, err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing transaction: %w", err)
+	}
+	return result, nil
+}
+
+// validate checks every reference in the batch, and that its dependencies
+// don't form a cycle, before anything is written
+func (b BulkTasks) validate() error {
+	for i, e := range b.Epics {
+		if strings.TrimSpace(e.Title) == "" {
+			return fmt.Errorf("epic %d has no title", i)
+		}
+	}
+
+	ids := make([]string, len(b.Tasks))
+	blockers := make(map[string][]string)
+	for i := range b.Tasks {
+		ids[i] = fmt.Sprint(i)
+	}
+	for i, t := range b.Tasks {
+		if strings.TrimSpace(t.Title) == "" {
+			return fmt.Errorf("task %d has no title", i)
+		}
+		if t.Epic != NoRef && (t.Epic < 0 || t.Epic >= len(b.Epics)) {
+			return fmt.Errorf("task %d (%s): epic %d out of range", i, t.Title, t.Epic)
+		}
+		if t.Parent != NoRef {
+			if t.Parent < 0 || t.Parent >= i {
+				return fmt.Errorf("task %d (%s): parent %d must be an earlier task", i, t.Title, t.Parent)
+			}
+			if b.Tasks[t.Parent].Parent != NoRef {
+				return fmt.Errorf("task %d (%s): parent task is already a sub-task (max depth is 2 levels)", i, t.Title)
+			}
+		}
+		for _, dep := range t.BlockedBy {
+			if dep < 0 || dep >= len(b.Tasks) {
+				return fmt.Errorf("task %d (%s): blocked by task %d, out of range", i, t.Title, dep)
+			}
+			if dep == i {
+				return fmt.Errorf("task %d (%s) cannot depend on itself", i, t.Title)
+			}
+			blockers[ids[i]] = append(blockers[ids[i]], ids[dep])
+		}
+	}
+
+	all := func(string) bool { return true }
+	if cycles := findCycles(ids, blockers, all); len(cycles) > 0 {
+		return fmt.Errorf("%w between tasks %s", ErrDependencyCycle, strings.Join(cycles[0], " → "))
+	}
+	return nil
+}
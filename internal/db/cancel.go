@@ -0,0 +1,69 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// RequestCancel asks the worker running a claimed or in-progress task to
+// stop it. The worker kills the agent, releases the worktree and finishes
+// with CancelTask; until then the task keeps its status and claim. The
+// reason becomes the task's last error
+func (s *Store) RequestCancel(taskID, reason string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var status types.TaskStatus
+	err = tx.QueryRow(`SELECT status FROM tasks WHERE id = ?`, taskID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("getting task status: %w", err)
+	}
+	if status != types.TaskStatusClaimed && status != types.TaskStatusInProgress {
+		return fmt.Errorf("cannot request cancellation of task with status %s (only claimed or in_progress tasks are running)", status)
+	}
+
+	now := time.Now().Unix()
+	if _, err := tx.Exec(`
+		UPDATE tasks
+		SET cancel_requested_at = COALESCE(cancel_requested_at, ?), last_error = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, now, reason, now, taskID, status); err != nil {
+		return fmt.Errorf("requesting cancellation: %w", err)
+	}
+
+	body := "Cancellation requested"
+	if reason != "" {
+		body += ": " + reason
+	}
+	if _, err := recordActivity(tx, taskID, types.ActivityComment, s.actingAs(""), body); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// CancelRequested reports whether a task's cancellation was requested and
+// not yet carried out
+func (s *Store) CancelRequested(taskID string) (bool, error) {
+	var requested sql.NullInt64
+	err := s.DB.QueryRow(`SELECT cancel_requested_at FROM tasks WHERE id = ?`, taskID).Scan(&requested)
+	if err == sql.ErrNoRows {
+		return false, fmt.Errorf("task not found: %s", taskID)
+	}
+	if err != nil {
+		return false, fmt.Errorf("getting cancellation request: %w", err)
+	}
+	return requested.Valid, nil
+}
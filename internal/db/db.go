@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -79,7 +80,11 @@ type Plan struct {
 
 // Store manages database operations
 type Store struct {
-	DB *sql.DB
+	DB        *sql.DB
+	actor     string        // Who status changes are attributed to (see SetActor)
+	leaseTTL  time.Duration // How long a claim lasts without renewal (see SetLeaseTTL)
+	idFormat  IDFormat      // How new task and epic IDs look (see SetIDFormat)
+	scheduler Scheduler     // Picks which ready task workers claim first (see SetSchedule)
 }
 
 // ProjectStatus summarizes the current state
@@ -92,10 +97,30 @@ type ProjectStatus struct {
 	Blocked    int
 	Completed  int
 	Failed     int
+	Scheduled  int             // Ready tasks whose scheduled_at is still in the future
+	Overdue    int             // Unfinished tasks past their due_at
+	Exhausted  int             // Failed tasks that used up their max_attempts
+	Usage      Usage           // Tokens and cost of every task so far
+	Epics      []*EpicProgress // Top-level epics, with sub-epics nested under them
 }
 
-// Open opens a SQLite database at the given path
+// Open opens a SQLite database at the given path and migrates its schema to
+// the latest version
 func Open(path string) (*Store, error) {
+	store, err := OpenUnmigrated(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Migrate(); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("migrating schema: %w", err)
+	}
+	return store, nil
+}
+
+// OpenUnmigrated opens a SQLite database without touching its schema, for
+// inspecting or migrating it explicitly
+func OpenUnmigrated(path string) (*Store, error) {
 	db, err := sql.Open("sqlite", path)
 	if err != nil {
 		return nil, fmt.Errorf("opening database: %w", err)
@@ -219,7 +244,39 @@ func (s *Store) QueryEvents(eventTypes []string, epicID, taskID string, since, u
 	return events, nil
 }
 
-// InitSchema creates the database schema
+// CompletedTaskDurations returns how long the most recent completed tasks
+// took, newest first, from their task.completed events (archived ones included)
+func (s *Store) CompletedTaskDurations(limit int) ([]time.Duration, error) {
+	rows, err := s.DB.Query(`
+		SELECT duration FROM (
+			SELECT timestamp, json_extract(data, '$.duration') AS duration
+			FROM events WHERE type = 'task.completed'
+			UNION ALL
+			SELECT timestamp, json_extract(data, '$.duration')
+			FROM archived_events WHERE type = 'task.completed'
+		)
+		WHERE duration > 0
+		ORDER BY timestamp DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying task durations: %w", err)
+	}
+	defer rows.Close()
+
+	var durations []time.Duration
+	for rows.Next() {
+		var ms float64
+		if err := rows.Scan(&ms); err != nil {
+			return nil, fmt.Errorf("scanning task duration: %w", err)
+		}
+		durations = append(durations, time.Duration(ms*float64(time.Millisecond)))
+	}
+	return durations, rows.Err()
+}
+
+// InitSchema creates the database schema as of the migration baseline. It is
+// frozen: schema changes are added under migrations/ instead
 func (s *Store) InitSchema() error {
 	schema := `
 	-- Epics group related tasks
@@ -228,7 +285,10 @@ func (s *Store) InitSchema() error {
 		title TEXT NOT NULL,
 		description TEXT,
 		status TEXT DEFAULT 'open',
-		created_at INTEGER NOT NULL
+		parent_epic_id TEXT,
+		external_ref TEXT,
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (parent_epic_id) REFERENCES epics(id)
 	);
 
 	-- Tasks are the unit of work
@@ -253,6 +313,12 @@ func (s *Store) InitSchema() error {
 		test_mode TEXT DEFAULT 'strict',
 		test_scope TEXT DEFAULT 'diff',
 		test_command TEXT,
+		commit_author TEXT,
+		commit_sha TEXT,
+		scheduled_at INTEGER,
+		due_at INTEGER,
+		effective_priority INTEGER,
+		external_ref TEXT,
 		created_at INTEGER NOT NULL,
 		updated_at INTEGER NOT NULL,
 		FOREIGN KEY (epic_id) REFERENCES epics(id),
@@ -277,6 +343,7 @@ func (s *Store) InitSchema() error {
 		last_used_at INTEGER NOT NULL,
 		status TEXT DEFAULT 'active',
 		disk_size INTEGER DEFAULT 0,
+		setup_ms INTEGER DEFAULT 0,
 		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
 	);
 
@@ -311,6 +378,17 @@ func (s *Store) InitSchema() error {
 		last_active INTEGER
 	);
 
+	-- Activity timeline: comments, guidance, status changes and verdicts
+	CREATE TABLE IF NOT EXISTS task_activity (
+		id TEXT PRIMARY KEY,
+		task_id TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		author TEXT,
+		body TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+	);
+
 	-- Indexes for common queries
 	CREATE INDEX IF NOT EXISTS idx_tasks_status ON tasks(status);
 	CREATE INDEX IF NOT EXISTS idx_tasks_epic ON tasks(epic_id);
@@ -326,14 +404,22 @@ func (s *Store) InitSchema() error {
 	CREATE INDEX IF NOT EXISTS idx_checkpoints_last_heartbeat ON task_checkpoints(last_heartbeat);
 	CREATE INDEX IF NOT EXISTS idx_operators_name ON operators(name);
 	CREATE INDEX IF NOT EXISTS idx_operators_api_key ON operators(api_key);
+	CREATE INDEX IF NOT EXISTS idx_task_activity_task ON task_activity(task_id, created_at);
 	`
 
-	_, err := s.DB.Exec(schema)
+	if _, err := s.DB.Exec(schema); err != nil {
+		return err
+	}
+	if _, err := s.DB.Exec(auditSchema); err != nil {
+		return err
+	}
+	_, err := s.DB.Exec(archiveSchema)
 	return err
 }
 
 // MigrateSchema runs database migrations for existing databases
-// This adds new columns that weren't in the original schema
+// This adds new columns that weren't in the original schema. Like InitSchema
+// it is frozen at the migration baseline
 func (s *Store) MigrateSchema() error {
 	// Check if parent_id column exists
 	var parentIDExists bool
@@ -641,6 +727,106 @@ func (s *Store) MigrateSchema() error {
 		}
 	}
 
+	// Check if commit_author column exists (added for per-agent commit attribution)
+	var commitAuthorExists bool
+	err = s.DB.QueryRow(`
+		SELECT COUNT(*) > 0 FROM pragma_table_info('tasks') WHERE name = 'commit_author'
+	`).Scan(&commitAuthorExists)
+	if err != nil {
+		return fmt.Errorf("checking for commit_author column: %w", err)
+	}
+
+	if !commitAuthorExists {
+		// Record which agent identity authored each task's commit
+		_, err := s.DB.Exec(`
+			ALTER TABLE tasks ADD COLUMN commit_author TEXT;
+			ALTER TABLE tasks ADD COLUMN commit_sha TEXT;
+		`)
+		if err != nil {
+			return fmt.Errorf("adding commit attribution columns: %w", err)
+		}
+	}
+
+	// Check if scheduled_at column exists (added for not-before and due dates)
+	var scheduledAtExists bool
+	err = s.DB.QueryRow(`
+		SELECT COUNT(*) > 0 FROM pragma_table_info('tasks') WHERE name = 'scheduled_at'
+	`).Scan(&scheduledAtExists)
+	if err != nil {
+		return fmt.Errorf("checking for scheduled_at column: %w", err)
+	}
+
+	if !scheduledAtExists {
+		// Tasks may not be claimed before scheduled_at and are overdue after due_at
+		_, err := s.DB.Exec(`
+			ALTER TABLE tasks ADD COLUMN scheduled_at INTEGER;
+			ALTER TABLE tasks ADD COLUMN due_at INTEGER;
+		`)
+		if err != nil {
+			return fmt.Errorf("adding schedule columns: %w", err)
+		}
+	}
+
+	// Check if effective_priority column exists (added for the editable run queue)
+	var effectivePriorityExists bool
+	err = s.DB.QueryRow(`
+		SELECT COUNT(*) > 0 FROM pragma_table_info('tasks') WHERE name = 'effective_priority'
+	`).Scan(&effectivePriorityExists)
+	if err != nil {
+		return fmt.Errorf("checking for effective_priority column: %w", err)
+	}
+
+	if !effectivePriorityExists {
+		// Overrides priority in the claim order when a task is moved in the queue
+		_, err := s.DB.Exec(`ALTER TABLE tasks ADD COLUMN effective_priority INTEGER`)
+		if err != nil {
+			return fmt.Errorf("adding effective_priority column: %w", err)
+		}
+	}
+
+	// Check if external_ref columns exist (added for issue tracker integrations)
+	for _, table := range []string{"tasks", "epics"} {
+		var externalRefExists bool
+		err = s.DB.QueryRow(`
+			SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = 'external_ref'
+		`, table).Scan(&externalRefExists)
+		if err != nil {
+			return fmt.Errorf("checking for %s.external_ref column: %w", table, err)
+		}
+
+		if !externalRefExists {
+			// The issue an imported task or epic came from, e.g. "jira:PROJ-123"
+			_, err := s.DB.Exec(`ALTER TABLE ` + table + ` ADD COLUMN external_ref TEXT`)
+			if err != nil {
+				return fmt.Errorf("adding %s.external_ref column: %w", table, err)
+			}
+		}
+	}
+	// Not in InitSchema: it also runs against databases that predate the columns
+	if _, err := s.DB.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_tasks_external_ref ON tasks(external_ref);
+		CREATE INDEX IF NOT EXISTS idx_epics_external_ref ON epics(external_ref);
+	`); err != nil {
+		return fmt.Errorf("creating external_ref indexes: %w", err)
+	}
+
+	// Check if worktrees.setup_ms column exists (added for worktree lifecycle metrics)
+	var setupMsExists bool
+	err = s.DB.QueryRow(`
+		SELECT COUNT(*) > 0 FROM pragma_table_info('worktrees') WHERE name = 'setup_ms'
+	`).Scan(&setupMsExists)
+	if err != nil {
+		return fmt.Errorf("checking for setup_ms column: %w", err)
+	}
+
+	if !setupMsExists {
+		// Record how long each worktree took to become ready
+		_, err := s.DB.Exec(`ALTER TABLE worktrees ADD COLUMN setup_ms INTEGER DEFAULT 0`)
+		if err != nil {
+			return fmt.Errorf("adding worktree setup_ms column: %w", err)
+		}
+	}
+
 	// Check if conversations table exists (drover-mem-8: Conversation Persistence with FTS5)
 	var conversationsTableExists bool
 	err = s.DB.QueryRow(`
@@ -738,27 +924,106 @@ func (s *Store) MigrateSchema() error {
 		}
 	}
 
+	// Check if task_activity table exists (added for the task activity timeline)
+	var activityTableExists bool
+	err = s.DB.QueryRow(`
+		SELECT COUNT(*) > 0 FROM sqlite_master WHERE type='table' AND name='task_activity'
+	`).Scan(&activityTableExists)
+	if err != nil {
+		return fmt.Errorf("checking for task_activity table: %w", err)
+	}
+
+	if !activityTableExists {
+		_, err := s.DB.Exec(`
+			CREATE TABLE task_activity (
+				id TEXT PRIMARY KEY,
+				task_id TEXT NOT NULL,
+				kind TEXT NOT NULL,
+				author TEXT,
+				body TEXT NOT NULL,
+				created_at INTEGER NOT NULL,
+				FOREIGN KEY (task_id) REFERENCES tasks(id) ON DELETE CASCADE
+			);
+			CREATE INDEX IF NOT EXISTS idx_task_activity_task ON task_activity(task_id, created_at);
+		`)
+		if err != nil {
+			return fmt.Errorf("creating task_activity table: %w", err)
+		}
+	}
+
+	// Audit log (added for append-only status history); idempotent
+	if _, err := s.DB.Exec(auditSchema); err != nil {
+		return fmt.Errorf("creating task_audit table: %w", err)
+	}
+
+	// Check if parent_epic_id column exists (added for sub-epics)
+	var parentEpicExists bool
+	err = s.DB.QueryRow(`
+		SELECT COUNT(*) > 0 FROM pragma_table_info('epics') WHERE name = 'parent_epic_id'
+	`).Scan(&parentEpicExists)
+	if err != nil {
+		return fmt.Errorf("checking for parent_epic_id column: %w", err)
+	}
+
+	if !parentEpicExists {
+		_, err := s.DB.Exec(`
+			ALTER TABLE epics ADD COLUMN parent_epic_id TEXT REFERENCES epics(id);
+		`)
+		if err != nil {
+			return fmt.Errorf("adding parent_epic_id column: %w", err)
+		}
+	}
+	// Not in InitSchema: it also runs against databases that predate the column
+	if _, err := s.DB.Exec(`CREATE INDEX IF NOT EXISTS idx_epics_parent ON epics(parent_epic_id)`); err != nil {
+		return fmt.Errorf("creating parent_epic_id index: %w", err)
+	}
+
+	// Archive tables for finished tasks (added for drover gc); idempotent
+	if _, err := s.DB.Exec(archiveSchema); err != nil {
+		return fmt.Errorf("creating archive tables: %w", err)
+	}
+
 	return nil
 }
 
 // CreateEpic creates a new epic
 func (s *Store) CreateEpic(title, description string) (*types.Epic, error) {
-	id := generateID("epic")
+	return s.CreateSubEpic(title, description, "")
+}
+
+// CreateSubEpic creates a new epic nested under parentEpicID, e.g. one phase
+// of a larger program. An empty parentEpicID creates a top-level epic
+func (s *Store) CreateSubEpic(title, description, parentEpicID string) (*types.Epic, error) {
 	now := time.Now().Unix()
 
 	epic := &types.Epic{
-		ID:          id,
-		Title:       title,
-		Description: description,
-		Status:      types.EpicStatusOpen,
-		CreatedAt:   now,
+		Title:        title,
+		Description:  description,
+		Status:       types.EpicStatusOpen,
+		ParentEpicID: parentEpicID,
+		CreatedAt:    now,
+	}
+
+	var parentValue interface{}
+	if parentEpicID != "" {
+		var exists bool
+		if err := s.DB.QueryRow(`SELECT COUNT(*) > 0 FROM epics WHERE id = ?`, parentEpicID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("checking parent epic: %w", err)
+		}
+		if !exists {
+			return nil, fmt.Errorf("parent epic not found: %s", parentEpicID)
+		}
+		parentValue = parentEpicID
 	}
 
-	_, err := s.DB.Exec(`
-		INSERT INTO epics (id, title, description, status, created_at)
-		VALUES (?, ?, ?, ?, ?)
-	`, epic.ID, epic.Title, epic.Description, epic.Status, epic.CreatedAt)
-
+	var err error
+	epic.ID, err = s.insertWithID("epics", "epic", func(id string) error {
+		_, err := s.DB.Exec(`
+			INSERT INTO epics (id, title, description, status, parent_epic_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, id, epic.Title, epic.Description, epic.Status, parentValue, epic.CreatedAt)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("creating epic: %w", err)
 	}
@@ -778,11 +1043,9 @@ func (s *Store) CreateTaskWithOperator(title, description, epicID string, priori
 
 // CreateTaskWithTestConfig creates a new task with test configuration
 func (s *Store) CreateTaskWithTestConfig(title, description, epicID string, priority int, blockedBy []string, operator, testMode, testScope, testCommand string) (*types.Task, error) {
-	id := generateID("task")
 	now := time.Now().Unix()
 
 	task := &types.Task{
-		ID:          id,
 		Title:       title,
 		Description: description,
 		EpicID:      epicID,
@@ -813,10 +1076,13 @@ func (s *Store) CreateTaskWithTestConfig(title, description, epicID string, prio
 	if epicIDValue == "" {
 		epicIDValue = nil
 	}
-	_, err = tx.Exec(`
-		INSERT INTO tasks (id, title, description, epic_id, type, priority, status, operator, test_mode, test_scope, test_command, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, task.ID, task.Title, task.Description, epicIDValue, task.Type, task.Priority, task.Status, task.Operator, task.TestMode, task.TestScope, task.TestCommand, task.CreatedAt, task.UpdatedAt)
+	task.ID, err = s.insertWithID("tasks", "task", func(id string) error {
+		_, err := tx.Exec(`
+			INSERT INTO tasks (id, title, description, epic_id, type, priority, status, operator, test_mode, test_scope, test_command, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, id, task.Title, task.Description, epicIDValue, task.Type, task.Priority, task.Status, task.Operator, task.TestMode, task.TestScope, task.TestCommand, task.CreatedAt, task.UpdatedAt)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("creating task: %w", err)
 	}
@@ -832,6 +1098,9 @@ func (s *Store) CreateTaskWithTestConfig(title, description, epicID string, prio
 		}
 	}
 
+	if err := rollupEpics(tx); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("committing transaction: %w", err)
 	}
@@ -920,6 +1189,9 @@ func (s *Store) CreateSubTask(title, description, parentID string, priority int,
 		}
 	}
 
+	if err := rollupEpics(tx); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("committing transaction: %w", err)
 	}
@@ -1010,6 +1282,9 @@ func (s *Store) CreateSubTaskWithSequence(title, description, parentID string, s
 		}
 	}
 
+	if err := rollupEpics(tx); err != nil {
+		return nil, err
+	}
 	if err := tx.Commit(); err != nil {
 		return nil, fmt.Errorf("committing transaction: %w", err)
 	}
@@ -1057,80 +1332,116 @@ func (s *Store) GetProjectStatus() (*ProjectStatus, error) {
 	status.Total = status.Ready + status.Claimed + status.InProgress +
 		status.Paused + status.Blocked + status.Completed + status.Failed
 
+	now := time.Now().Unix()
+	err = s.DB.QueryRow(`
+		SELECT
+			COALESCE(SUM(status = 'ready' AND scheduled_at > ?), 0),
+			COALESCE(SUM(status NOT IN ('completed', 'failed', 'cancelled') AND due_at < ?), 0),
+			COALESCE(SUM(status = 'failed' AND attempts >= max_attempts), 0)
+		FROM tasks
+	`, now, now).Scan(&status.Scheduled, &status.Overdue, &status.Exhausted)
+	if err != nil {
+		return nil, fmt.Errorf("counting scheduled tasks: %w", err)
+	}
+
+	status.Usage, err = s.projectUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	status.Epics, err = s.GetEpicProgress()
+	if err != nil {
+		return nil, err
+	}
+
 	return status, nil
 }
 
 // ClaimTask attempts to atomically claim a ready task
 //
-// Uses UPDATE with ORDER BY and LIMIT to atomically find and claim a task
-// in a single operation, avoiding race conditions between SELECT and UPDATE.
+// The task is picked and claimed in a single transaction, so two workers
+// never claim the same one.
 func (s *Store) ClaimTask(workerID string) (*types.Task, error) {
 	return s.ClaimTaskForEpic(workerID, "")
 }
 
+// heldMutexKeys selects the mutex keys of the tasks in flight
+const heldMutexKeys = `SELECT mutex_key FROM tasks
+	WHERE mutex_key IS NOT NULL AND status IN ('claimed', 'in_progress')`
+
 // ClaimTaskForEpic attempts to atomically claim a ready task, optionally filtered by epic
 //
-// Uses UPDATE with ORDER BY and LIMIT to atomically find and claim a task
-// in a single operation, avoiding race conditions between SELECT and UPDATE.
-// If epicID is empty, claims any ready task. If epicID is set, only claims tasks in that epic.
+// If epicID is empty, claims any ready task. If epicID is set, only claims tasks in that epic
+// or its sub-epics. Which ready task is claimed is up to the store's scheduler (see SetSchedule),
+// which picks it within the claim's transaction.
 func (s *Store) ClaimTaskForEpic(workerID, epicID string) (*types.Task, error) {
-	tx, err := s.DB.Begin()
+	return s.ClaimTaskForEpicWithContext(context.Background(), workerID, epicID)
+}
+
+// ClaimTaskForEpicWithContext is ClaimTaskForEpic with a context for the scheduler
+func (s *Store) ClaimTaskForEpicWithContext(ctx context.Context, workerID, epicID string) (*types.Task, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	// Take the write lock before reading what's ready, so another worker's
+	// claim can't land between the scheduler's pick and ours
+	if _, err := tx.Exec(`UPDATE tasks SET id = id WHERE 0`); err != nil {
+		return nil, fmt.Errorf("locking for claim: %w", err)
+	}
+
 	now := time.Now().Unix()
+	candidates, err := readyTasks(tx, epicID, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	scheduler := s.scheduler
+	if scheduler == nil {
+		scheduler = SchedulerFunc(priorityNext)
+	}
+	id, err := scheduler.ClaimNext(ctx, ClaimConstraints{WorkerID: workerID, EpicID: epicID, Ready: candidates, tx: tx})
+	if err != nil {
+		return nil, fmt.Errorf("scheduling claim: %w", err)
+	}
+	if id == "" {
+		return nil, nil
+	}
+	offered := false
+	for _, t := range candidates {
+		offered = offered || t.ID == id
+	}
+	if !offered {
+		return nil, fmt.Errorf("scheduling claim: %s is not one of the ready tasks", id)
+	}
+	next, nextArgs := `SELECT ?`, []any{id}
 
-	// Build the query with optional epic filtering
 	var task types.Task
-	if epicID != "" {
-		// Filter by epic_id and exclude sub-tasks (they run via parent)
-		err = tx.QueryRow(`
-			UPDATE tasks
-			SET status = 'claimed',
-			    claimed_by = ?,
-			    claimed_at = ?,
-			    updated_at = ?
-			WHERE id = (
-				SELECT id FROM tasks
-				WHERE status = 'ready' AND epic_id = ? AND parent_id IS NULL
-				ORDER BY priority DESC, created_at ASC
-				LIMIT 1
-			)
-			RETURNING id, title, COALESCE(description, ''), COALESCE(epic_id, ''),
-			          COALESCE(parent_id, ''), sequence_number,
-			          COALESCE(type, 'other'),
-			          priority, status, attempts, max_attempts,
-			          COALESCE(operator, ''), created_at, updated_at
-		`, workerID, now, now, epicID).Scan(&task.ID, &task.Title, &task.Description, &task.EpicID,
-			&task.ParentID, &task.SequenceNumber,
-			&task.Type,
-			&task.Priority, &task.Status, &task.Attempts, &task.MaxAttempts,
-			&task.Operator, &task.CreatedAt, &task.UpdatedAt)
-	} else {
-		// No epic filtering, exclude sub-tasks (they run via parent)
-		err = tx.QueryRow(`
-			UPDATE tasks
-			SET status = 'claimed',
-			    claimed_by = ?,
-			    claimed_at = ?,
-			    updated_at = ?
-			WHERE id = (
-				SELECT id FROM tasks
-				WHERE status = 'ready' AND parent_id IS NULL
-				ORDER BY priority DESC, created_at ASC
-				LIMIT 1
-			)
-			RETURNING id, title, COALESCE(description, ''), COALESCE(epic_id, ''),
-			          COALESCE(parent_id, ''), sequence_number,
-			          priority, status, attempts, max_attempts,
-			          COALESCE(operator, ''), created_at, updated_at
-		`, workerID, now, now).Scan(&task.ID, &task.Title, &task.Description, &task.EpicID,
-			&task.ParentID, &task.SequenceNumber,
-			&task.Priority, &task.Status, &task.Attempts, &task.MaxAttempts,
-			&task.Operator, &task.CreatedAt, &task.UpdatedAt)
-	}
+	var effectivePriority sql.NullInt64
+	args := append([]any{workerID, now, s.leaseExpiry(now), now}, nextArgs...)
+	err = tx.QueryRow(`
+		UPDATE tasks
+		SET status = 'claimed',
+		    claimed_by = ?,
+		    claimed_at = ?,
+		    lease_expires_at = ?,
+		    updated_at = ?
+		WHERE id = (`+next+`) AND status = 'ready'
+		  AND (mutex_key IS NULL OR mutex_key NOT IN (`+heldMutexKeys+`))
+		RETURNING id, title, COALESCE(description, ''), COALESCE(epic_id, ''),
+		          COALESCE(parent_id, ''), sequence_number,
+		          COALESCE(type, 'other'),
+		          priority, effective_priority, status, attempts, max_attempts,
+		          COALESCE(operator, ''), COALESCE(repo, ''), created_at, updated_at
+	`, args...).Scan(&task.ID, &task.Title, &task.Description, &task.EpicID,
+		&task.ParentID, &task.SequenceNumber,
+		&task.Type,
+		&task.Priority, &effectivePriority, &task.Status, &task.Attempts, &task.MaxAttempts,
+		&task.Operator, &task.Repo, &task.CreatedAt, &task.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		// No tasks were claimed - either no ready tasks exist, or another worker
@@ -1138,64 +1449,353 @@ func (s *Store) ClaimTaskForEpic(workerID, epicID string) (*types.Task, error) {
 		// Either way, returning nil is the correct behavior.
 		return nil, nil
 	}
-	if err != nil {
-		return nil, fmt.Errorf("claiming task: %w", err)
+	if err != nil {
+		return nil, fmt.Errorf("claiming task: %w", err)
+	}
+
+	if effectivePriority.Valid {
+		priority := int(effectivePriority.Int64)
+		task.EffectivePriority = &priority
+	}
+	task.Status = types.TaskStatusClaimed
+	task.ClaimedBy = workerID
+	task.ClaimedAt = &now
+
+	if err := recordTransition(tx, task.ID, types.TaskStatusReady, types.TaskStatusClaimed, workerID, ""); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing claim: %w", err)
+	}
+
+	return &task, nil
+}
+
+// GetTaskStatus returns the current status of a task
+func (s *Store) GetTaskStatus(taskID string) (types.TaskStatus, error) {
+	var status string
+	err := s.DB.QueryRow(`
+		SELECT status FROM tasks WHERE id = ?
+	`, taskID).Scan(&status)
+	if err != nil {
+		return "", err
+	}
+	return types.TaskStatus(status), nil
+}
+
+// UpdateTaskStatus updates a task's status and records the transition on its
+// timeline. A task leaving claimed or in_progress drops any pending
+// cancellation request
+func (s *Store) UpdateTaskStatus(taskID string, status types.TaskStatus, lastError string) error {
+	return s.updateTaskStatus(taskID, "", status, lastError)
+}
+
+// updateTaskStatus updates a task's status, only while claim holds the task
+// when set
+func (s *Store) updateTaskStatus(taskID, claim string, status types.TaskStatus, lastError string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordStatusChange(tx, taskID, status, s.actingAs(""), lastError); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	res, err := tx.Exec(`
+		UPDATE tasks
+		SET status = ?, last_error = ?, updated_at = ?,
+		    cancel_requested_at = CASE WHEN ? IN ('claimed', 'in_progress') THEN cancel_requested_at END
+		WHERE id = ? AND (? = '' OR claimed_by = ?)
+	`, status, lastError, now, status, taskID, claim, claim)
+	if err != nil {
+		return err
+	}
+	if claim != "" && rowsAffected(res) == 0 {
+		return fmt.Errorf("%w on task %s", ErrLeaseLost, taskID)
+	}
+	if err := rollupEpics(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetTaskVerdict sets the structured verdict for a task
+func (s *Store) SetTaskVerdict(taskID string, verdict types.TaskVerdict, reason string) error {
+	now := time.Now().Unix()
+	_, err := s.DB.Exec(`
+		UPDATE tasks
+		SET verdict = ?, verdict_reason = ?, updated_at = ?
+		WHERE id = ?
+	`, verdict, reason, now, taskID)
+	if err != nil {
+		return err
+	}
+
+	body := string(verdict)
+	if reason != "" {
+		body += ": " + reason
+	}
+	if _, err := recordActivity(s.DB, taskID, types.ActivityVerdict, "", body); err != nil {
+		return fmt.Errorf("recording verdict: %w", err)
+	}
+	return nil
+}
+
+// SetTaskTestConfig updates the test configuration for a task
+func (s *Store) SetTaskTestConfig(taskID, testMode, testScope, testCommand string) error {
+	now := time.Now().Unix()
+	_, err := s.DB.Exec(`
+		UPDATE tasks
+		SET test_mode = ?, test_scope = ?, test_command = ?, updated_at = ?
+		WHERE id = ?
+	`, testMode, testScope, testCommand, now, taskID)
+	return err
+}
+
+// SetTaskRetryPolicy sets the retry policy overrides of a task; empty clears
+// them, so the global policy applies
+func (s *Store) SetTaskRetryPolicy(taskID, policy string) error {
+	res, err := s.DB.Exec(`
+		UPDATE tasks
+		SET retry_policy = NULLIF(?, ''), updated_at = ?
+		WHERE id = ?
+	`, policy, time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("setting retry policy: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
+}
+
+// SetTaskEpic moves a task to an epic; empty takes it out of its epic
+func (s *Store) SetTaskEpic(taskID, epicID string) error {
+	res, err := s.DB.Exec(`
+		UPDATE tasks
+		SET epic_id = NULLIF(?, ''), updated_at = ?
+		WHERE id = ?
+	`, epicID, time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("setting epic: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
+}
+
+// SetTaskMutexKey sets the key a task shares with the tasks it must not run
+// alongside; empty clears it
+func (s *Store) SetTaskMutexKey(taskID, key string) error {
+	res, err := s.DB.Exec(`
+		UPDATE tasks
+		SET mutex_key = NULLIF(?, ''), updated_at = ?
+		WHERE id = ?
+	`, strings.TrimSpace(key), time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("setting mutex key: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
+}
+
+// SetTaskEnv sets the environment variables a task's agent runs with; an
+// empty map clears them
+func (s *Store) SetTaskEnv(taskID string, env map[string]string) error {
+	var encoded string
+	if len(env) > 0 {
+		data, err := json.Marshal(env)
+		if err != nil {
+			return fmt.Errorf("encoding environment: %w", err)
+		}
+		encoded = string(data)
+	}
+	res, err := s.DB.Exec(`
+		UPDATE tasks
+		SET env = NULLIF(?, ''), updated_at = ?
+		WHERE id = ?
+	`, encoded, time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("setting environment: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
+}
+
+// GetTaskEnv returns the environment variables a task's agent runs with
+func (s *Store) GetTaskEnv(taskID string) (map[string]string, error) {
+	var env sql.NullString
+	if err := s.DB.QueryRow(`SELECT env FROM tasks WHERE id = ?`, taskID).Scan(&env); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("task not found: %s", taskID)
+		}
+		return nil, fmt.Errorf("reading environment: %w", err)
+	}
+	return decodeEnv(env.String)
+}
+
+// decodeEnv parses a task's env column
+func decodeEnv(encoded string) (map[string]string, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+	var env map[string]string
+	if err := json.Unmarshal([]byte(encoded), &env); err != nil {
+		return nil, fmt.Errorf("decoding environment: %w", err)
+	}
+	return env, nil
+}
+
+// SetTaskRepo sets the repository a task works in, by its name under
+// [repos] in .drover.toml; empty is the project's own
+func (s *Store) SetTaskRepo(taskID, repo string) error {
+	res, err := s.DB.Exec(`
+		UPDATE tasks
+		SET repo = NULLIF(?, ''), updated_at = ?
+		WHERE id = ?
+	`, strings.TrimSpace(repo), time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("setting repository: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
+}
+
+// RequeueTask returns a failed attempt's task to the queue, counting the
+// attempt. Workers don't claim it again before notBefore; a zero time lets
+// them claim it at once
+func (s *Store) RequeueTask(taskID, lastError string, notBefore time.Time) error {
+	return s.requeueTask(taskID, "", lastError, notBefore)
+}
+
+// requeueTask requeues a task, only while claim holds it when set
+func (s *Store) requeueTask(taskID, claim, lastError string, notBefore time.Time) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordStatusChange(tx, taskID, types.TaskStatusReady, s.actingAs(""), lastError); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE tasks
+		SET status = 'ready', attempts = attempts + 1, last_error = ?,
+		    scheduled_at = ?, updated_at = ?
+		WHERE id = ? AND (? = '' OR claimed_by = ?)
+	`, lastError, unixOrNull(notBefore), time.Now().Unix(), taskID, claim, claim)
+	if err != nil {
+		return fmt.Errorf("requeueing task: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		if claim != "" {
+			return fmt.Errorf("%w on task %s", ErrLeaseLost, taskID)
+		}
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	if err := rollupEpics(tx); err != nil {
+		return err
 	}
+	return tx.Commit()
+}
 
-	task.Status = types.TaskStatusClaimed
-	task.ClaimedBy = workerID
-	task.ClaimedAt = &now
-
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("committing claim: %w", err)
+// SetTaskSchedule sets when a task may first be claimed and when it is due
+// A zero time clears the corresponding date
+func (s *Store) SetTaskSchedule(taskID string, scheduledAt, dueAt time.Time) error {
+	res, err := s.DB.Exec(`
+		UPDATE tasks
+		SET scheduled_at = ?, due_at = ?, updated_at = ?
+		WHERE id = ?
+	`, unixOrNull(scheduledAt), unixOrNull(dueAt), time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("setting task schedule: %w", err)
 	}
-
-	return &task, nil
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
 }
 
-// GetTaskStatus returns the current status of a task
-func (s *Store) GetTaskStatus(taskID string) (types.TaskStatus, error) {
-	var status string
-	err := s.DB.QueryRow(`
-		SELECT status FROM tasks WHERE id = ?
-	`, taskID).Scan(&status)
+// ListOverdueTasks returns unfinished tasks past their due date, most overdue first
+func (s *Store) ListOverdueTasks() ([]*types.Task, error) {
+	tasks, err := s.listTasks(`WHERE status NOT IN ('completed', 'failed', 'cancelled') AND due_at < ?`, time.Now().Unix())
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return types.TaskStatus(status), nil
+	sort.SliceStable(tasks, func(i, j int) bool { return *tasks[i].DueAt < *tasks[j].DueAt })
+	return tasks, nil
 }
 
-// UpdateTaskStatus updates a task's status
-func (s *Store) UpdateTaskStatus(taskID string, status types.TaskStatus, lastError string) error {
-	now := time.Now().Unix()
-	_, err := s.DB.Exec(`
-		UPDATE tasks
-		SET status = ?, last_error = ?, updated_at = ?
-		WHERE id = ?
-	`, status, lastError, now, taskID)
-	return err
+// unixOrNull stores a zero time as NULL
+func unixOrNull(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Unix()
 }
 
-// SetTaskVerdict sets the structured verdict for a task
-func (s *Store) SetTaskVerdict(taskID string, verdict types.TaskVerdict, reason string) error {
+// SetTaskCommitAuthor records the author identity and commit a task produced
+func (s *Store) SetTaskCommitAuthor(taskID, author, sha string) error {
 	now := time.Now().Unix()
 	_, err := s.DB.Exec(`
 		UPDATE tasks
-		SET verdict = ?, verdict_reason = ?, updated_at = ?
+		SET commit_author = ?, commit_sha = ?, updated_at = ?
 		WHERE id = ?
-	`, verdict, reason, now, taskID)
+	`, author, sha, now, taskID)
 	return err
 }
 
-// SetTaskTestConfig updates the test configuration for a task
-func (s *Store) SetTaskTestConfig(taskID, testMode, testScope, testCommand string) error {
-	now := time.Now().Unix()
-	_, err := s.DB.Exec(`
+// AssignOperator sets which human or bot is overseeing a task; an empty
+// operator unassigns it. The change is recorded on the task's timeline
+func (s *Store) AssignOperator(taskID, operator string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previous string
+	err = tx.QueryRow(`SELECT COALESCE(operator, '') FROM tasks WHERE id = ?`, taskID).Scan(&previous)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("getting task operator: %w", err)
+	}
+	if previous == operator {
+		return nil
+	}
+
+	_, err = tx.Exec(`
 		UPDATE tasks
-		SET test_mode = ?, test_scope = ?, test_command = ?, updated_at = ?
+		SET operator = ?, updated_at = ?
 		WHERE id = ?
-	`, testMode, testScope, testCommand, now, taskID)
-	return err
+	`, operator, time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("assigning operator: %w", err)
+	}
+
+	body := "assigned to " + operator
+	if operator == "" {
+		body = "unassigned from " + previous
+	}
+	if _, err := recordActivity(tx, taskID, types.ActivityAssignment, s.actingAs(""), body); err != nil {
+		return fmt.Errorf("recording assignment: %w", err)
+	}
+	return tx.Commit()
 }
 
 // IncrementTaskAttempts increments the attempt counter for a task
@@ -1212,6 +1812,7 @@ func (s *Store) IncrementTaskAttempts(taskID string) error {
 // GetTask retrieves a task by ID
 func (s *Store) GetTask(taskID string) (*types.Task, error) {
 	var task types.Task
+	var env string
 	var claimedBy sql.NullString
 	var claimedAt sql.NullInt64
 	var epicID sql.NullString
@@ -1221,18 +1822,27 @@ func (s *Store) GetTask(taskID string) (*types.Task, error) {
 	var testMode sql.NullString
 	var testScope sql.NullString
 	var testCommand sql.NullString
+	var scheduledAt, dueAt sql.NullInt64
+	var effectivePriority sql.NullInt64
 
 	err := s.DB.QueryRow(`
 		SELECT id, title, COALESCE(description, ''), COALESCE(epic_id, ''),
 		       COALESCE(parent_id, ''), sequence_number,
 		       COALESCE(type, 'other'),
 		       priority, status, attempts, max_attempts,
+		       COALESCE(last_error, ''),
 		       COALESCE(claimed_by, ''), COALESCE(claimed_at, 0),
 		       COALESCE(operator, ''), COALESCE(verdict, 'unknown'),
 		       COALESCE(verdict_reason, ''),
 		       COALESCE(test_mode, 'strict'),
 		       COALESCE(test_scope, 'diff'),
 		       COALESCE(test_command, ''),
+		       COALESCE(commit_author, ''), COALESCE(commit_sha, ''),
+		       scheduled_at, due_at, effective_priority,
+		       COALESCE(external_ref, ''),
+		       input_tokens, output_tokens, cost_usd,
+		       COALESCE(retry_policy, ''), COALESCE(mutex_key, ''), COALESCE(repo, ''),
+		       COALESCE(env, ''),
 		       created_at, updated_at
 		FROM tasks
 		WHERE id = ?
@@ -1241,15 +1851,25 @@ func (s *Store) GetTask(taskID string) (*types.Task, error) {
 		&task.ParentID, &task.SequenceNumber,
 		&task.Type,
 		&task.Priority, &task.Status, &task.Attempts, &task.MaxAttempts,
+		&task.LastError,
 		&claimedBy, &claimedAt, &operator,
 		&task.Verdict, &verdictReason,
 		&testMode, &testScope, &testCommand,
+		&task.CommitAuthor, &task.CommitSHA,
+		&scheduledAt, &dueAt, &effectivePriority,
+		&task.ExternalRef,
+		&task.InputTokens, &task.OutputTokens, &task.CostUSD,
+		&task.RetryPolicy, &task.MutexKey, &task.Repo,
+		&env,
 		&task.CreatedAt, &task.UpdatedAt,
 	)
 
 	if err != nil {
 		return nil, err
 	}
+	if task.Env, err = decodeEnv(env); err != nil {
+		return nil, fmt.Errorf("task %s: %w", taskID, err)
+	}
 
 	task.Description = description.String
 	task.EpicID = epicID.String
@@ -1265,12 +1885,32 @@ func (s *Store) GetTask(taskID string) (*types.Task, error) {
 		unix := claimedAt.Int64
 		task.ClaimedAt = &unix
 	}
+	task.ScheduledAt = nullableUnix(scheduledAt)
+	task.DueAt = nullableUnix(dueAt)
+	if effectivePriority.Valid {
+		priority := int(effectivePriority.Int64)
+		task.EffectivePriority = &priority
+	}
 
 	return &task, nil
 }
 
+// nullableUnix returns a nullable timestamp column as a pointer
+func nullableUnix(v sql.NullInt64) *int64 {
+	if !v.Valid {
+		return nil
+	}
+	unix := v.Int64
+	return &unix
+}
+
 // CompleteTask marks a task as completed and unblocks dependents
 func (s *Store) CompleteTask(taskID string) error {
+	return s.completeTask(taskID, "")
+}
+
+// completeTask completes a task, only while claim holds it when set
+func (s *Store) completeTask(taskID, claim string) error {
 	tx, err := s.DB.Begin()
 	if err != nil {
 		return err
@@ -1278,15 +1918,21 @@ func (s *Store) CompleteTask(taskID string) error {
 	defer tx.Rollback()
 
 	// Mark as completed
+	if err := recordStatusChange(tx, taskID, types.TaskStatusCompleted, s.actingAs(""), ""); err != nil {
+		return err
+	}
 	now := time.Now().Unix()
-	_, err = tx.Exec(`
+	res, err := tx.Exec(`
 		UPDATE tasks
 		SET status = 'completed', claimed_by = NULL, updated_at = ?
-		WHERE id = ?
-	`, now, taskID)
+		WHERE id = ? AND (? = '' OR claimed_by = ?)
+	`, now, taskID, claim, claim)
 	if err != nil {
 		return err
 	}
+	if claim != "" && rowsAffected(res) == 0 {
+		return fmt.Errorf("%w on task %s", ErrLeaseLost, taskID)
+	}
 
 	// Find tasks blocked by this one
 	rows, err := tx.Query(`
@@ -1323,6 +1969,9 @@ func (s *Store) CompleteTask(taskID string) error {
 
 		// If no remaining blockers, mark as ready
 		if remainingCount == 0 {
+			if err := recordStatusChange(tx, depID, types.TaskStatusReady, s.actingAs(""), "unblocked by "+taskID); err != nil {
+				return err
+			}
 			_, err = tx.Exec(`
 				UPDATE tasks
 				SET status = 'ready', updated_at = ?
@@ -1334,6 +1983,9 @@ func (s *Store) CompleteTask(taskID string) error {
 		}
 	}
 
+	if err := rollupEpics(tx); err != nil {
+		return err
+	}
 	return tx.Commit()
 }
 
@@ -1351,6 +2003,17 @@ func (s *Store) ResetTasks(statusesToReset []types.TaskStatus) (int, error) {
 		args[i+1] = string(status)
 	}
 
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cond := fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", "))
+	if err := recordStatusChanges(tx, types.TaskStatusReady, s.actingAs(""), "reset", cond, args[1:]...); err != nil {
+		return 0, err
+	}
+
 	// Reset tasks to ready status
 	query := fmt.Sprintf(`
 		UPDATE tasks
@@ -1359,7 +2022,7 @@ func (s *Store) ResetTasks(statusesToReset []types.TaskStatus) (int, error) {
 		WHERE status IN (%s)
 	`, fmt.Sprintf("%s", strings.Join(placeholders, ", ")))
 
-	result, err := s.DB.Exec(query, args...)
+	result, err := tx.Exec(query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("resetting tasks: %w", err)
 	}
@@ -1369,6 +2032,12 @@ func (s *Store) ResetTasks(statusesToReset []types.TaskStatus) (int, error) {
 		return 0, fmt.Errorf("getting affected rows: %w", err)
 	}
 
+	if err := rollupEpics(tx); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing reset: %w", err)
+	}
 	return int(rowsAffected), nil
 }
 
@@ -1390,6 +2059,17 @@ func (s *Store) ResetTasksByIDs(taskIDs []string) (int, error) {
 		args[i+1] = id
 	}
 
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	cond := fmt.Sprintf("id IN (%s)", strings.Join(placeholders, ", "))
+	if err := recordStatusChanges(tx, types.TaskStatusReady, s.actingAs(""), "reset", cond, args[1:]...); err != nil {
+		return 0, err
+	}
+
 	// Reset tasks to ready status
 	query := fmt.Sprintf(`
 		UPDATE tasks
@@ -1398,7 +2078,7 @@ func (s *Store) ResetTasksByIDs(taskIDs []string) (int, error) {
 		WHERE id IN (%s)
 	`, strings.Join(placeholders, ", "))
 
-	result, err := s.DB.Exec(query, args...)
+	result, err := tx.Exec(query, args...)
 	if err != nil {
 		return 0, fmt.Errorf("resetting tasks by IDs: %w", err)
 	}
@@ -1408,37 +2088,69 @@ func (s *Store) ResetTasksByIDs(taskIDs []string) (int, error) {
 		return 0, fmt.Errorf("getting affected rows: %w", err)
 	}
 
+	if err := rollupEpics(tx); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing reset: %w", err)
+	}
 	return int(rowsAffected), nil
 }
 
-// CancelTask cancels a running or ready task
+// CancelTask cancels a running or ready task. An empty reason keeps the one
+// a pending RequestCancel gave
 func (s *Store) CancelTask(taskID, reason string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordStatusChange(tx, taskID, types.TaskStatusCancelled, s.actingAs(""), reason,
+		types.TaskStatusReady, types.TaskStatusClaimed, types.TaskStatusInProgress); err != nil {
+		return err
+	}
+
 	now := time.Now().Unix()
-	_, err := s.DB.Exec(`
+	_, err = tx.Exec(`
 		UPDATE tasks
 		SET status = 'cancelled',
 		    claimed_by = NULL,
 		    claimed_at = NULL,
-		    last_error = ?,
+		    lease_expires_at = NULL,
+		    last_error = CASE WHEN ? = '' AND cancel_requested_at IS NOT NULL THEN last_error ELSE ? END,
+		    cancel_requested_at = NULL,
 		    updated_at = ?
 		WHERE id = ?
 		    AND status IN ('ready', 'claimed', 'in_progress')
-	`, reason, now, taskID)
+	`, reason, reason, now, taskID)
 	if err != nil {
 		return fmt.Errorf("cancelling task: %w", err)
 	}
-	return nil
+	if err := rollupEpics(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 // RetryTask resets a failed task to ready status for retry
 // If force is true, also resets the attempt counter
 func (s *Store) RetryTask(taskID string, force bool) error {
-	now := time.Now().Unix()
-	var err error
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := recordStatusChange(tx, taskID, types.TaskStatusReady, s.actingAs(""), "retry",
+		types.TaskStatusFailed, types.TaskStatusCancelled); err != nil {
+		return err
+	}
 
+	now := time.Now().Unix()
 	if force {
 		// Reset attempts along with status
-		_, err = s.DB.Exec(`
+		_, err = tx.Exec(`
 			UPDATE tasks
 			SET status = 'ready',
 			    attempts = 0,
@@ -1451,7 +2163,7 @@ func (s *Store) RetryTask(taskID string, force bool) error {
 		`, now, taskID)
 	} else {
 		// Only reset status
-		_, err = s.DB.Exec(`
+		_, err = tx.Exec(`
 			UPDATE tasks
 			SET status = 'ready',
 			    claimed_by = NULL,
@@ -1465,7 +2177,45 @@ func (s *Store) RetryTask(taskID string, force bool) error {
 	if err != nil {
 		return fmt.Errorf("retrying task: %w", err)
 	}
-	return nil
+	return tx.Commit()
+}
+
+// BlockTasksOn makes every ready or blocked task other than blockerID wait for it
+// Used to run a bootstrap task before anything else; returns the number of tasks blocked
+func (s *Store) BlockTasksOn(blockerID string) (int, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT OR IGNORE INTO task_dependencies (task_id, blocked_by)
+		SELECT id, ? FROM tasks
+		WHERE id != ? AND status IN ('ready', 'blocked')
+	`, blockerID, blockerID)
+	if err != nil {
+		return 0, fmt.Errorf("adding dependencies: %w", err)
+	}
+	count, _ := result.RowsAffected()
+
+	if err := recordStatusChanges(tx, types.TaskStatusBlocked, s.actingAs(""), "waiting for "+blockerID,
+		"id != ? AND status = 'ready'", blockerID); err != nil {
+		return 0, err
+	}
+	_, err = tx.Exec(`
+		UPDATE tasks
+		SET status = 'blocked', updated_at = ?
+		WHERE id != ? AND status = 'ready'
+	`, time.Now().Unix(), blockerID)
+	if err != nil {
+		return 0, fmt.Errorf("blocking tasks: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("committing transaction: %w", err)
+	}
+	return int(count), nil
 }
 
 // ResolveTask removes all blockers for a blocked task, setting it to ready
@@ -1486,6 +2236,9 @@ func (s *Store) ResolveTask(taskID string, note string) error {
 	}
 
 	// Update task status to ready
+	if err := recordStatusChange(tx, taskID, types.TaskStatusReady, s.actingAs(""), note, types.TaskStatusBlocked); err != nil {
+		return err
+	}
 	now := time.Now().Unix()
 	_, err = tx.Exec(`
 		UPDATE tasks
@@ -1504,58 +2257,48 @@ func (s *Store) ResolveTask(taskID string, note string) error {
 	return tx.Commit()
 }
 
-// generateID generates a unique ID with the given prefix
-func generateID(prefix string) string {
-	// Simple ID generation - in production use UUID or similar
-	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
-}
-
 // ListTasks returns all tasks in the database
 func (s *Store) ListTasks() ([]*types.Task, error) {
 	return s.ListTasksByEpic("")
 }
 
-// ListTasksByEpic returns tasks filtered by epic ID
+// ListTasksByEpic returns tasks filtered by epic ID, including the tasks of its sub-epics
 // If epicID is empty, returns all tasks
 func (s *Store) ListTasksByEpic(epicID string) ([]*types.Task, error) {
-	var rows *sql.Rows
-	var err error
-
 	if epicID != "" {
-		// Filter by epic ID
-		rows, err = s.DB.Query(`
-			SELECT id, title, COALESCE(description, ''), COALESCE(epic_id, ''),
-			       COALESCE(parent_id, ''), sequence_number,
-			       COALESCE(type, 'other'),
-			       priority, status, attempts, max_attempts,
-			       COALESCE(claimed_by, ''), COALESCE(claimed_at, 0),
-			       COALESCE(operator, ''),
-			       COALESCE(test_mode, 'strict'),
-			       COALESCE(test_scope, 'diff'),
-			       COALESCE(test_command, ''),
-			       created_at, updated_at
-			FROM tasks
-			WHERE epic_id = ?
-			ORDER BY created_at ASC
-		`, epicID)
-	} else {
-		// Return all tasks
-		rows, err = s.DB.Query(`
-			SELECT id, title, COALESCE(description, ''), COALESCE(epic_id, ''),
-			       COALESCE(parent_id, ''), sequence_number,
-			       COALESCE(type, 'other'),
-			       priority, status, attempts, max_attempts,
-			       COALESCE(claimed_by, ''), COALESCE(claimed_at, 0),
-			       COALESCE(operator, ''),
-			       COALESCE(test_mode, 'strict'),
-			       COALESCE(test_scope, 'diff'),
-			       COALESCE(test_command, ''),
-			       created_at, updated_at
-			FROM tasks
-			ORDER BY created_at ASC
-		`)
+		return s.listTasks("WHERE epic_id IN ("+epicSubtree+")", epicID)
 	}
+	return s.listTasks("")
+}
+
+// ListTasksByOperator returns the tasks assigned to an operator
+// If operator is empty, returns the tasks nobody is overseeing
+func (s *Store) ListTasksByOperator(operator string) ([]*types.Task, error) {
+	return s.listTasks("WHERE COALESCE(operator, '') = ?", operator)
+}
 
+// listTasks returns the tasks matching where (an optional WHERE clause), oldest first
+func (s *Store) listTasks(where string, args ...any) ([]*types.Task, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, title, COALESCE(description, ''), COALESCE(epic_id, ''),
+		       COALESCE(parent_id, ''), sequence_number,
+		       COALESCE(type, 'other'),
+		       priority, status, attempts, max_attempts,
+		       COALESCE(last_error, ''),
+		       COALESCE(claimed_by, ''), COALESCE(claimed_at, 0),
+		       COALESCE(operator, ''),
+		       COALESCE(test_mode, 'strict'),
+		       COALESCE(test_scope, 'diff'),
+		       COALESCE(test_command, ''),
+		       scheduled_at, due_at, effective_priority,
+		       COALESCE(external_ref, ''),
+		       input_tokens, output_tokens, cost_usd,
+		       COALESCE(mutex_key, ''), COALESCE(repo, ''),
+		       created_at, updated_at
+		FROM tasks
+		`+where+`
+		ORDER BY created_at ASC
+	`, args...)
 	if err != nil {
 		return nil, fmt.Errorf("querying tasks: %w", err)
 	}
@@ -1573,14 +2316,21 @@ func (s *Store) ListTasksByEpic(epicID string) ([]*types.Task, error) {
 		var testMode sql.NullString
 		var testScope sql.NullString
 		var testCommand sql.NullString
+		var scheduledAt, dueAt sql.NullInt64
+		var effectivePriority sql.NullInt64
 
 		err := rows.Scan(
 			&task.ID, &task.Title, &description, &epicID,
 			&parentID, &task.SequenceNumber,
 			&task.Type,
 			&task.Priority, &task.Status, &task.Attempts, &task.MaxAttempts,
+			&task.LastError,
 			&claimedBy, &claimedAt, &operator,
 			&testMode, &testScope, &testCommand,
+			&scheduledAt, &dueAt, &effectivePriority,
+			&task.ExternalRef,
+			&task.InputTokens, &task.OutputTokens, &task.CostUSD,
+			&task.MutexKey, &task.Repo,
 			&task.CreatedAt, &task.UpdatedAt,
 		)
 		if err != nil {
@@ -1601,6 +2351,12 @@ func (s *Store) ListTasksByEpic(epicID string) ([]*types.Task, error) {
 			unix := claimedAt.Int64
 			task.ClaimedAt = &unix
 		}
+		task.ScheduledAt = nullableUnix(scheduledAt)
+		task.DueAt = nullableUnix(dueAt)
+		if effectivePriority.Valid {
+			priority := int(effectivePriority.Int64)
+			task.EffectivePriority = &priority
+		}
 
 		tasks = append(tasks, &task)
 	}
@@ -1756,7 +2512,7 @@ func (s *Store) GetBlockedBy(taskID string) ([]string, error) {
 // ListEpics returns all epics in the database
 func (s *Store) ListEpics() ([]*types.Epic, error) {
 	rows, err := s.DB.Query(`
-		SELECT id, title, COALESCE(description, ''), status, created_at
+		SELECT id, title, COALESCE(description, ''), status, COALESCE(parent_epic_id, ''), created_at
 		FROM epics
 		ORDER BY created_at ASC
 	`)
@@ -1771,7 +2527,7 @@ func (s *Store) ListEpics() ([]*types.Epic, error) {
 		var description sql.NullString
 
 		err := rows.Scan(
-			&epic.ID, &epic.Title, &description, &epic.Status, &epic.CreatedAt,
+			&epic.ID, &epic.Title, &description, &epic.Status, &epic.ParentEpicID, &epic.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning epic: %w", err)
@@ -1817,6 +2573,7 @@ type WorktreeInfo struct {
 	LastUsedAt  int64
 	Status      string
 	DiskSize    int64
+	SetupMs     int64
 	TaskStatus  string
 	TaskTitle   string
 }
@@ -1834,6 +2591,28 @@ func (s *Store) CreateWorktree(taskID, path, branch string) error {
 	return nil
 }
 
+// RecordWorktree records a worktree assigned to a task, replacing any earlier
+// row for the same task (e.g. from a previous attempt)
+func (s *Store) RecordWorktree(taskID, path, branch string, setup time.Duration) error {
+	now := time.Now().Unix()
+	_, err := s.DB.Exec(`
+		INSERT INTO worktrees (task_id, path, branch, created_at, last_used_at, status, disk_size, setup_ms)
+		VALUES (?, ?, ?, ?, ?, 'active', 0, ?)
+		ON CONFLICT(task_id) DO UPDATE SET
+			path = excluded.path,
+			branch = excluded.branch,
+			created_at = excluded.created_at,
+			last_used_at = excluded.last_used_at,
+			status = excluded.status,
+			disk_size = excluded.disk_size,
+			setup_ms = excluded.setup_ms
+	`, taskID, path, branch, now, now, setup.Milliseconds())
+	if err != nil {
+		return fmt.Errorf("recording worktree: %w", err)
+	}
+	return nil
+}
+
 // UpdateWorktreeStatus updates the status of a worktree
 func (s *Store) UpdateWorktreeStatus(taskID, status string) error {
 	now := time.Now().Unix()
@@ -1880,7 +2659,8 @@ func (s *Store) ListWorktrees() ([]*WorktreeInfo, error) {
 	// Try to query with task information (LEFT JOIN with tasks table)
 	rows, err := s.DB.Query(`
 		SELECT w.task_id, w.path, w.branch, w.created_at, w.last_used_at,
-		       w.status, w.disk_size, COALESCE(t.status, ''), COALESCE(t.title, '')
+		       w.status, w.disk_size, COALESCE(w.setup_ms, 0),
+		       COALESCE(t.status, ''), COALESCE(t.title, '')
 		FROM worktrees w
 		LEFT JOIN tasks t ON w.task_id = t.id
 		ORDER BY w.created_at DESC
@@ -1889,7 +2669,8 @@ func (s *Store) ListWorktrees() ([]*WorktreeInfo, error) {
 	// If the tasks table doesn't exist (DBOS mode), fall back to simpler query
 	if err != nil {
 		rows, err = s.DB.Query(`
-			SELECT task_id, path, branch, created_at, last_used_at, status, disk_size
+			SELECT task_id, path, branch, created_at, last_used_at, status, disk_size,
+			       COALESCE(setup_ms, 0)
 			FROM worktrees
 			ORDER BY created_at DESC
 		`)
@@ -1903,7 +2684,7 @@ func (s *Store) ListWorktrees() ([]*WorktreeInfo, error) {
 			var w WorktreeInfo
 			err := rows.Scan(
 				&w.TaskID, &w.Path, &w.Branch, &w.CreatedAt, &w.LastUsedAt,
-				&w.Status, &w.DiskSize,
+				&w.Status, &w.DiskSize, &w.SetupMs,
 			)
 			if err != nil {
 				return nil, fmt.Errorf("scanning worktree: %w", err)
@@ -1919,7 +2700,7 @@ func (s *Store) ListWorktrees() ([]*WorktreeInfo, error) {
 		var w WorktreeInfo
 		err := rows.Scan(
 			&w.TaskID, &w.Path, &w.Branch, &w.CreatedAt, &w.LastUsedAt,
-			&w.Status, &w.DiskSize, &w.TaskStatus, &w.TaskTitle,
+			&w.Status, &w.DiskSize, &w.SetupMs, &w.TaskStatus, &w.TaskTitle,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning worktree: %w", err)
@@ -1930,6 +2711,30 @@ func (s *Store) ListWorktrees() ([]*WorktreeInfo, error) {
 	return worktrees, nil
 }
 
+// GetWorktree returns the worktree recorded for a task, or nil if the task
+// never had one
+func (s *Store) GetWorktree(taskID string) (*WorktreeInfo, error) {
+	var w WorktreeInfo
+	err := s.DB.QueryRow(`
+		SELECT w.task_id, w.path, w.branch, w.created_at, w.last_used_at,
+		       COALESCE(w.status, ''), COALESCE(w.disk_size, 0), COALESCE(w.setup_ms, 0),
+		       COALESCE(t.status, ''), COALESCE(t.title, '')
+		FROM worktrees w
+		LEFT JOIN tasks t ON w.task_id = t.id
+		WHERE w.task_id = ?
+	`, taskID).Scan(
+		&w.TaskID, &w.Path, &w.Branch, &w.CreatedAt, &w.LastUsedAt,
+		&w.Status, &w.DiskSize, &w.SetupMs, &w.TaskStatus, &w.TaskTitle,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting worktree: %w", err)
+	}
+	return &w, nil
+}
+
 // GetWorktreesForCleanup returns worktrees that can be cleaned up
 func (s *Store) GetWorktreesForCleanup(completedOnly bool) ([]*WorktreeInfo, error) {
 	var rows *sql.Rows
@@ -2218,6 +3023,9 @@ func (s *Store) AddGuidance(taskID, message string) (*types.GuidanceMessage, err
 	if err != nil {
 		return nil, fmt.Errorf("adding guidance: %w", err)
 	}
+	if _, err := recordActivity(s.DB, taskID, types.ActivityGuidance, "", message); err != nil {
+		return nil, fmt.Errorf("recording guidance: %w", err)
+	}
 
 	return guidance, nil
 }
@@ -2288,59 +3096,82 @@ func (s *Store) ClearGuidance(taskID string) error {
 	return nil
 }
 
-// PauseTask pauses a running task, preserving its state
+// PauseTask pauses a claimed or running task, preserving its state. The
+// claim is kept, so the worker can tell its task was paused and park it with
+// its worktree, but the lease stops: paused tasks are never reaped
 func (s *Store) PauseTask(taskID string) error {
-	now := time.Now().Unix()
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	// Check if task is in_progress or claimed (can only pause active tasks)
-	var status string
-	err := s.DB.QueryRow(`SELECT status FROM tasks WHERE id = ?`, taskID).Scan(&status)
+	var status types.TaskStatus
+	err = tx.QueryRow(`SELECT status FROM tasks WHERE id = ?`, taskID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
 	if err != nil {
 		return fmt.Errorf("getting task status: %w", err)
 	}
-
-	if status != string(types.TaskStatusInProgress) && status != string(types.TaskStatusClaimed) {
+	if status != types.TaskStatusInProgress && status != types.TaskStatusClaimed {
 		return fmt.Errorf("cannot pause task with status %s (only in_progress or claimed tasks can be paused)", status)
 	}
 
-	// Update status to paused
-	_, err = s.DB.Exec(`
+	if err := recordStatusChange(tx, taskID, types.TaskStatusPaused, s.actingAs(""), "", status); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
 		UPDATE tasks
-		SET status = 'paused', updated_at = ?
-		WHERE id = ?
-	`, now, taskID)
-	if err != nil {
+		SET status = 'paused', lease_expires_at = NULL, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, time.Now().Unix(), taskID, status); err != nil {
 		return fmt.Errorf("pausing task: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
 	return nil
 }
 
-// ResumeTask resumes a paused task
+// ResumeTask returns a paused task to the queue, releasing its claim so any
+// worker can pick it up
 func (s *Store) ResumeTask(taskID string) error {
-	now := time.Now().Unix()
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	// Check if task is paused
-	var status string
-	err := s.DB.QueryRow(`SELECT status FROM tasks WHERE id = ?`, taskID).Scan(&status)
+	var status types.TaskStatus
+	err = tx.QueryRow(`SELECT status FROM tasks WHERE id = ?`, taskID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
 	if err != nil {
 		return fmt.Errorf("getting task status: %w", err)
 	}
-
-	if status != string(types.TaskStatusPaused) {
+	if status != types.TaskStatusPaused {
 		return fmt.Errorf("cannot resume task with status %s (only paused tasks can be resumed)", status)
 	}
 
+	if err := recordStatusChange(tx, taskID, types.TaskStatusReady, s.actingAs(""), "", status); err != nil {
+		return err
+	}
 	// Reset status to ready so it can be claimed again
-	_, err = s.DB.Exec(`
+	if _, err := tx.Exec(`
 		UPDATE tasks
-		SET status = 'ready', claimed_by = NULL, claimed_at = NULL, updated_at = ?
-		WHERE id = ?
-	`, now, taskID)
-	if err != nil {
+		SET status = 'ready', claimed_by = NULL, claimed_at = NULL, lease_expires_at = NULL, updated_at = ?
+		WHERE id = ? AND status = 'paused'
+	`, time.Now().Unix(), taskID); err != nil {
 		return fmt.Errorf("resuming task: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
 	return nil
 }
 
@@ -2373,9 +3204,9 @@ func (s *Store) ImportSession(session *SessionExport) error {
 		}
 		if exists == 0 {
 			_, err = tx.Exec(`
-				INSERT INTO epics (id, title, description, status, created_at)
-				VALUES (?, ?, ?, ?, ?)
-			`, epic.ID, epic.Title, epic.Description, epic.Status, epic.CreatedAt)
+				INSERT INTO epics (id, title, description, status, parent_epic_id, created_at)
+				VALUES (?, ?, ?, ?, NULLIF(?, ''), ?)
+			`, epic.ID, epic.Title, epic.Description, epic.Status, epic.ParentEpicID, epic.CreatedAt)
 			if err != nil {
 				return fmt.Errorf("importing epic: %w", err)
 			}
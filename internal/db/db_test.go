@@ -2,9 +2,16 @@
 package db_test
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/cloud-shuttle/drover/internal/db"
 	"github.com/cloud-shuttle/drover/pkg/types"
@@ -404,6 +411,133 @@ func TestStore_GetTask(t *testing.T) {
 	}
 }
 
+func TestStore_SetTaskCommitAuthor(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, err := store.CreateTask("Attributed Task", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	author := "drover[claude-sonnet-4] <drover+claude@localhost>"
+	if err := store.SetTaskCommitAuthor(task.ID, author, "abc123"); err != nil {
+		t.Fatalf("SetTaskCommitAuthor failed: %v", err)
+	}
+
+	retrieved, err := store.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if retrieved.CommitAuthor != author {
+		t.Errorf("Expected commit author %q, got %q", author, retrieved.CommitAuthor)
+	}
+	if retrieved.CommitSHA != "abc123" {
+		t.Errorf("Expected commit sha abc123, got %q", retrieved.CommitSHA)
+	}
+}
+
+func TestStore_RecordWorktree(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, err := store.CreateTask("Worktree Task", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	if err := store.RecordWorktree(task.ID, "/tmp/wt-1", "drover-1", 1500*time.Millisecond); err != nil {
+		t.Fatalf("RecordWorktree failed: %v", err)
+	}
+	if err := store.UpdateWorktreeStatus(task.ID, "removed"); err != nil {
+		t.Fatalf("UpdateWorktreeStatus failed: %v", err)
+	}
+
+	// A retry replaces the earlier row instead of failing on the primary key
+	if err := store.RecordWorktree(task.ID, "/tmp/wt-2", "drover-2", 250*time.Millisecond); err != nil {
+		t.Fatalf("RecordWorktree (retry) failed: %v", err)
+	}
+	if err := store.UpdateWorktreeDiskSize(task.ID, 4096); err != nil {
+		t.Fatalf("UpdateWorktreeDiskSize failed: %v", err)
+	}
+
+	worktrees, err := store.ListWorktrees()
+	if err != nil {
+		t.Fatalf("ListWorktrees failed: %v", err)
+	}
+	if len(worktrees) != 1 {
+		t.Fatalf("Expected 1 worktree, got %d", len(worktrees))
+	}
+	w := worktrees[0]
+	if w.Path != "/tmp/wt-2" || w.Branch != "drover-2" || w.Status != "active" {
+		t.Errorf("Unexpected worktree row: %+v", w)
+	}
+	if w.SetupMs != 250 || w.DiskSize != 4096 {
+		t.Errorf("Expected setup 250ms and 4096 bytes, got %dms and %d bytes", w.SetupMs, w.DiskSize)
+	}
+	if w.TaskTitle != "Worktree Task" {
+		t.Errorf("Expected task title to be joined, got %q", w.TaskTitle)
+	}
+
+	got, err := store.GetWorktree(task.ID)
+	if err != nil {
+		t.Fatalf("GetWorktree failed: %v", err)
+	}
+	if got == nil || got.Path != "/tmp/wt-2" || got.TaskTitle != "Worktree Task" {
+		t.Errorf("GetWorktree = %+v, want the current row", got)
+	}
+	if got, err := store.GetWorktree("missing"); err != nil || got != nil {
+		t.Errorf("GetWorktree(missing) = %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestStore_BlockTasksOn(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	ready, err := store.CreateTask("Ready Task", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	blocked, err := store.CreateTask("Blocked Task", "", "", 0, []string{ready.ID})
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	bootstrap, err := store.CreateTask("Bootstrap", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	count, err := store.BlockTasksOn(bootstrap.ID)
+	if err != nil {
+		t.Fatalf("BlockTasksOn failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 tasks blocked, got %d", count)
+	}
+
+	for _, id := range []string{ready.ID, blocked.ID} {
+		task, _ := store.GetTask(id)
+		if task.Status != types.TaskStatusBlocked {
+			t.Errorf("Expected %s to be blocked, got %s", id, task.Status)
+		}
+	}
+	if task, _ := store.GetTask(bootstrap.ID); task.Status != types.TaskStatusReady {
+		t.Errorf("Expected bootstrap task to stay ready, got %s", task.Status)
+	}
+
+	// Completing the bootstrap task releases tasks with no other blockers
+	if err := store.CompleteTask(bootstrap.ID); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+	if task, _ := store.GetTask(ready.ID); task.Status != types.TaskStatusReady {
+		t.Errorf("Expected %s to be ready again, got %s", ready.ID, task.Status)
+	}
+	if task, _ := store.GetTask(blocked.ID); task.Status != types.TaskStatusBlocked {
+		t.Errorf("Expected %s to stay blocked on its own dependency, got %s", blocked.ID, task.Status)
+	}
+}
+
 func TestStore_GetTask_NotFound(t *testing.T) {
 	store, _ := setupTestDB(t)
 	defer store.Close()
@@ -596,3 +730,1693 @@ func TestStore_ResetTasksByIDs_Empty(t *testing.T) {
 		t.Errorf("Expected task status to still be 'completed', got '%s'", status)
 	}
 }
+
+func TestStore_ActivityTimeline(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, err := store.CreateTask("Timeline Task", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	if _, err := store.ClaimTask("worker-1"); err != nil {
+		t.Fatalf("ClaimTask failed: %v", err)
+	}
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusFailed, "tests failed"); err != nil {
+		t.Fatalf("UpdateTaskStatus failed: %v", err)
+	}
+	// Re-setting the same status is not a transition
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusFailed, "tests failed"); err != nil {
+		t.Fatalf("UpdateTaskStatus failed: %v", err)
+	}
+	if err := store.SetTaskVerdict(task.ID, types.TaskVerdictFail, "2 tests failing"); err != nil {
+		t.Fatalf("SetTaskVerdict failed: %v", err)
+	}
+	if _, err := store.AddGuidance(task.ID, "check the fixtures"); err != nil {
+		t.Fatalf("AddGuidance failed: %v", err)
+	}
+	if _, err := store.AddComment(task.ID, "alice", "flaky on CI too"); err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	if err := store.RetryTask(task.ID, false); err != nil {
+		t.Fatalf("RetryTask failed: %v", err)
+	}
+	// Resolving a task that isn't blocked changes nothing and leaves no trace
+	if err := store.ResolveTask(task.ID, "not blocked"); err != nil {
+		t.Fatalf("ResolveTask failed: %v", err)
+	}
+
+	activity, err := store.ListActivity(task.ID)
+	if err != nil {
+		t.Fatalf("ListActivity failed: %v", err)
+	}
+
+	want := []struct {
+		kind   types.ActivityKind
+		author string
+		body   string
+	}{
+		{types.ActivityStatus, "worker-1", "ready → claimed"},
+		{types.ActivityStatus, "", "claimed → failed: tests failed"},
+		{types.ActivityVerdict, "", "fail: 2 tests failing"},
+		{types.ActivityGuidance, "", "check the fixtures"},
+		{types.ActivityComment, "alice", "flaky on CI too"},
+		{types.ActivityStatus, "", "failed → ready: retry"},
+	}
+	if len(activity) != len(want) {
+		for _, a := range activity {
+			t.Logf("%s %q %q", a.Kind, a.Author, a.Body)
+		}
+		t.Fatalf("Expected %d activity entries, got %d", len(want), len(activity))
+	}
+	for i, w := range want {
+		a := activity[i]
+		if a.Kind != w.kind || a.Author != w.author || a.Body != w.body {
+			t.Errorf("activity[%d] = %s %q %q, want %s %q %q", i, a.Kind, a.Author, a.Body, w.kind, w.author, w.body)
+		}
+	}
+}
+
+func TestStore_AuditLog(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, err := store.CreateTask("Audited Task", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	if _, err := store.ClaimTask("worker-1"); err != nil {
+		t.Fatalf("ClaimTask failed: %v", err)
+	}
+	// Without an actor the claiming worker is held responsible
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusFailed, "boom"); err != nil {
+		t.Fatalf("UpdateTaskStatus failed: %v", err)
+	}
+	store.SetActor("alice")
+	if err := store.RetryTask(task.ID, true); err != nil {
+		t.Fatalf("RetryTask failed: %v", err)
+	}
+	store.SetActor("")
+	if err := store.CompleteTask(task.ID); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+
+	want := []types.AuditEvent{
+		{Actor: "worker-1", From: types.TaskStatusReady, To: types.TaskStatusClaimed},
+		{Actor: "worker-1", From: types.TaskStatusClaimed, To: types.TaskStatusFailed, Error: "boom"},
+		{Actor: "alice", From: types.TaskStatusFailed, To: types.TaskStatusReady, Error: "retry"},
+		{Actor: "drover", From: types.TaskStatusReady, To: types.TaskStatusCompleted},
+	}
+	check := func() {
+		t.Helper()
+		events, err := store.ListAudit(task.ID)
+		if err != nil {
+			t.Fatalf("ListAudit failed: %v", err)
+		}
+		if len(events) != len(want) {
+			t.Fatalf("Expected %d audit events, got %d", len(want), len(events))
+		}
+		for i, w := range want {
+			e := events[i]
+			if e.Actor != w.Actor || e.From != w.From || e.To != w.To || e.Error != w.Error {
+				t.Errorf("event[%d] = %s %s→%s %q, want %s %s→%s %q", i, e.Actor, e.From, e.To, e.Error, w.Actor, w.From, w.To, w.Error)
+			}
+		}
+	}
+	check()
+
+	// Events can't be rewritten or removed, and outlive the task
+	if _, err := store.DB.Exec(`UPDATE task_audit SET actor = 'mallory'`); err == nil {
+		t.Error("Expected UPDATE on task_audit to be rejected")
+	}
+	if _, err := store.DB.Exec(`DELETE FROM task_audit`); err == nil {
+		t.Error("Expected DELETE on task_audit to be rejected")
+	}
+	if _, err := store.DB.Exec(`DELETE FROM tasks WHERE id = ?`, task.ID); err != nil {
+		t.Fatalf("Failed to delete task: %v", err)
+	}
+	check()
+}
+
+func TestStore_AssignOperator(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	mine, err := store.CreateTaskWithOperator("Mine", "", "", 0, nil, "alice")
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	other, err := store.CreateTask("Other", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	if err := store.AssignOperator(other.ID, "alice"); err != nil {
+		t.Fatalf("AssignOperator failed: %v", err)
+	}
+	tasks, err := store.ListTasksByOperator("alice")
+	if err != nil {
+		t.Fatalf("ListTasksByOperator failed: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].ID != mine.ID || tasks[1].ID != other.ID {
+		t.Fatalf("Expected alice to oversee both tasks, got %d", len(tasks))
+	}
+
+	if err := store.AssignOperator(mine.ID, ""); err != nil {
+		t.Fatalf("AssignOperator (unassign) failed: %v", err)
+	}
+	unassigned, err := store.ListTasksByOperator("")
+	if err != nil {
+		t.Fatalf("ListTasksByOperator failed: %v", err)
+	}
+	if len(unassigned) != 1 || unassigned[0].ID != mine.ID {
+		t.Errorf("Expected only %s to be unassigned, got %d tasks", mine.ID, len(unassigned))
+	}
+
+	activity, err := store.ListActivity(mine.ID)
+	if err != nil {
+		t.Fatalf("ListActivity failed: %v", err)
+	}
+	if len(activity) != 1 || activity[0].Kind != types.ActivityAssignment || activity[0].Body != "unassigned from alice" {
+		t.Errorf("Expected the unassignment on the timeline, got %+v", activity)
+	}
+
+	if err := store.AssignOperator("task-missing", "alice"); err == nil {
+		t.Error("Expected an error assigning a missing task")
+	}
+}
+
+func TestStore_ArchiveTasks(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	create := func(title string, blockedBy ...string) *types.Task {
+		t.Helper()
+		task, err := store.CreateTask(title, "", "", 0, blockedBy)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		return task
+	}
+	finish := func(id string, status types.TaskStatus, age time.Duration) {
+		t.Helper()
+		if err := store.UpdateTaskStatus(id, status, ""); err != nil {
+			t.Fatalf("UpdateTaskStatus failed: %v", err)
+		}
+		if _, err := store.DB.Exec(`UPDATE tasks SET updated_at = ? WHERE id = ?`, time.Now().Add(-age).Unix(), id); err != nil {
+			t.Fatalf("Failed to age task: %v", err)
+		}
+	}
+	old := 48 * time.Hour
+
+	done := create("Old completed task")
+	if err := store.SetTaskExternalRef(done.ID, "jira:PROJ-1"); err != nil {
+		t.Fatalf("SetTaskExternalRef failed: %v", err)
+	}
+	finish(done.ID, types.TaskStatusCompleted, old)
+	if _, err := store.AddComment(done.ID, "alice", "shipped"); err != nil {
+		t.Fatalf("AddComment failed: %v", err)
+	}
+	attempt, err := store.StartAttempt(done.ID, "worker-1")
+	if err != nil {
+		t.Fatalf("StartAttempt failed: %v", err)
+	}
+	if err := store.FinishAttempt(attempt.ID, "", "first try failed"); err != nil {
+		t.Fatalf("FinishAttempt failed: %v", err)
+	}
+
+	// An old failure that a pending task still waits on stays live
+	blocker := create("Old failed blocker")
+	finish(blocker.ID, types.TaskStatusFailed, old)
+	create("Waiting on blocker", blocker.ID)
+
+	// A finished parent with an unfinished sub-task stays live with it
+	parent := create("Old parent")
+	child, err := store.CreateSubTask("Running child", "", parent.ID, 0, nil)
+	if err != nil {
+		t.Fatalf("CreateSubTask failed: %v", err)
+	}
+	finish(parent.ID, types.TaskStatusCompleted, old)
+	if err := store.UpdateTaskStatus(child.ID, types.TaskStatusInProgress, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus failed: %v", err)
+	}
+
+	recent := create("Recently completed")
+	finish(recent.ID, types.TaskStatusCompleted, time.Minute)
+
+	result, err := store.ArchiveTasks(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("ArchiveTasks failed: %v", err)
+	}
+	if result.Tasks != 1 {
+		t.Fatalf("Expected 1 archived task, got %d", result.Tasks)
+	}
+	if result.Activity == 0 {
+		t.Error("Expected the task's activity to be archived with it")
+	}
+	if result.Attempts != 1 {
+		t.Errorf("Expected the task's attempt to be archived with it, got %d", result.Attempts)
+	}
+	var attemptErr string
+	if err := store.DB.QueryRow(`SELECT error FROM archived_task_attempts WHERE task_id = ?`, done.ID).Scan(&attemptErr); err != nil || attemptErr != "first try failed" {
+		t.Errorf("Expected the archived attempt to keep its error, got %q, %v", attemptErr, err)
+	}
+	if _, err := store.GetTask(done.ID); err == nil {
+		t.Error("Expected the archived task to leave the live table")
+	}
+	for _, id := range []string{blocker.ID, parent.ID, child.ID, recent.ID} {
+		if _, err := store.GetTask(id); err != nil {
+			t.Errorf("Expected task %s to stay live: %v", id, err)
+		}
+	}
+	if events, err := store.ListAudit(done.ID); err != nil || len(events) == 0 {
+		t.Errorf("Expected the audit log to survive archiving, got %v, %v", events, err)
+	}
+	var ref string
+	if err := store.DB.QueryRow(`SELECT external_ref FROM archived_tasks WHERE id = ?`, done.ID).Scan(&ref); err != nil || ref != "jira:PROJ-1" {
+		t.Errorf("Expected the archived task to keep its external ref, got %q, %v", ref, err)
+	}
+
+	live, archived, err := store.ArchiveStats()
+	if err != nil {
+		t.Fatalf("ArchiveStats failed: %v", err)
+	}
+	if live != 5 || archived != 1 {
+		t.Errorf("Expected 5 live and 1 archived task, got %d and %d", live, archived)
+	}
+
+	// Once the sub-task finishes the whole tree goes together
+	finish(child.ID, types.TaskStatusCompleted, old)
+	if result, err = store.ArchiveTasks(24 * time.Hour); err != nil || result.Tasks != 2 {
+		t.Fatalf("Expected the parent and sub-task to be archived, got %+v, %v", result, err)
+	}
+
+	// A negative age puts every archived task past the purge cutoff
+	purged, err := store.PurgeArchive(-time.Hour)
+	if err != nil {
+		t.Fatalf("PurgeArchive failed: %v", err)
+	}
+	if purged.Tasks != 3 || purged.Activity == 0 || purged.Attempts != 1 {
+		t.Errorf("Expected 3 purged tasks with their activity and attempts, got %+v", purged)
+	}
+	if _, archived, _ = store.ArchiveStats(); archived != 0 {
+		t.Errorf("Expected an empty archive after purging, got %d", archived)
+	}
+}
+
+func TestStore_SubEpicRollup(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	program, err := store.CreateEpic("Program", "")
+	if err != nil {
+		t.Fatalf("CreateEpic failed: %v", err)
+	}
+	phase1, err := store.CreateSubEpic("Phase 1", "", program.ID)
+	if err != nil {
+		t.Fatalf("CreateSubEpic failed: %v", err)
+	}
+	phase2, err := store.CreateSubEpic("Phase 2", "", program.ID)
+	if err != nil {
+		t.Fatalf("CreateSubEpic failed: %v", err)
+	}
+	if _, err := store.CreateSubEpic("Orphan", "", "epic-missing"); err == nil {
+		t.Error("Expected an error for a missing parent epic")
+	}
+
+	create := func(epicID string, status types.TaskStatus) *types.Task {
+		t.Helper()
+		task, err := store.CreateTask("Task", "", epicID, 0, nil)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		if status != types.TaskStatusReady {
+			if err := store.UpdateTaskStatus(task.ID, status, ""); err != nil {
+				t.Fatalf("UpdateTaskStatus failed: %v", err)
+			}
+		}
+		return task
+	}
+	create(program.ID, types.TaskStatusCompleted)
+	create(phase1.ID, types.TaskStatusCompleted)
+	create(phase1.ID, types.TaskStatusCompleted)
+	phase2Task := create(phase2.ID, types.TaskStatusReady)
+
+	status, err := store.GetProjectStatus()
+	if err != nil {
+		t.Fatalf("GetProjectStatus failed: %v", err)
+	}
+	if len(status.Epics) != 1 || status.Epics[0].Epic.ID != program.ID {
+		t.Fatalf("Expected the program as the only top-level epic, got %+v", status.Epics)
+	}
+	root := status.Epics[0]
+	if root.Total != 4 || root.Completed != 3 || root.Ready != 1 {
+		t.Errorf("Expected 3/4 completed and 1 ready rolled up, got %+v", root)
+	}
+	if root.Status != "in_progress" || root.Progress() != 75 {
+		t.Errorf("Expected in_progress at 75%%, got %s at %.0f%%", root.Status, root.Progress())
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("Expected 2 sub-epics, got %d", len(root.Children))
+	}
+	if got := root.Children[0]; got.Epic.ID != phase1.ID || got.Status != "completed" || got.Epic.ParentEpicID != program.ID {
+		t.Errorf("Expected phase 1 completed under the program, got %+v", got)
+	}
+
+	// Claiming for the program reaches its phases' tasks
+	claimed, err := store.ClaimTaskForEpic("worker-1", program.ID)
+	if err != nil {
+		t.Fatalf("ClaimTaskForEpic failed: %v", err)
+	}
+	if claimed == nil || claimed.ID != phase2Task.ID {
+		t.Fatalf("Expected to claim the phase 2 task, got %+v", claimed)
+	}
+
+	tasks, err := store.ListTasksByEpic(program.ID)
+	if err != nil {
+		t.Fatalf("ListTasksByEpic failed: %v", err)
+	}
+	if len(tasks) != 4 {
+		t.Errorf("Expected 4 tasks across the program and its phases, got %d", len(tasks))
+	}
+}
+
+func TestStore_ScheduledTasks(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	later, err := store.CreateTask("Later", "", "", 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	now, err := store.CreateTask("Now", "", "", 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	// The higher-priority task is held until tomorrow and is already overdue
+	if err := store.SetTaskSchedule(later.ID, time.Now().Add(24*time.Hour), time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("SetTaskSchedule failed: %v", err)
+	}
+	if err := store.SetTaskSchedule("task-missing", time.Now(), time.Time{}); err == nil {
+		t.Error("Expected an error for a missing task")
+	}
+
+	got, err := store.GetTask(later.ID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if got.ScheduledAt == nil || got.DueAt == nil {
+		t.Fatalf("Expected schedule to be stored, got scheduled_at=%v due_at=%v", got.ScheduledAt, got.DueAt)
+	}
+
+	status, err := store.GetProjectStatus()
+	if err != nil {
+		t.Fatalf("GetProjectStatus failed: %v", err)
+	}
+	if status.Scheduled != 1 || status.Overdue != 1 {
+		t.Errorf("Expected 1 scheduled and 1 overdue task, got %d and %d", status.Scheduled, status.Overdue)
+	}
+	overdue, err := store.ListOverdueTasks()
+	if err != nil {
+		t.Fatalf("ListOverdueTasks failed: %v", err)
+	}
+	if len(overdue) != 1 || overdue[0].ID != later.ID {
+		t.Errorf("Expected %s to be overdue, got %v", later.ID, overdue)
+	}
+
+	claimed, err := store.ClaimTask("worker-1")
+	if err != nil {
+		t.Fatalf("ClaimTask failed: %v", err)
+	}
+	if claimed == nil || claimed.ID != now.ID {
+		t.Fatalf("Expected to claim %s, got %v", now.ID, claimed)
+	}
+	if claimed, err := store.ClaimTask("worker-2"); err != nil || claimed != nil {
+		t.Fatalf("Expected the scheduled task to stay unclaimed, got %v, %v", claimed, err)
+	}
+
+	// Clearing the schedule makes the task claimable
+	if err := store.SetTaskSchedule(later.ID, time.Time{}, time.Time{}); err != nil {
+		t.Fatalf("SetTaskSchedule failed: %v", err)
+	}
+	claimed, err = store.ClaimTask("worker-2")
+	if err != nil || claimed == nil || claimed.ID != later.ID {
+		t.Fatalf("Expected to claim %s after clearing its schedule, got %v, %v", later.ID, claimed, err)
+	}
+}
+
+func TestStore_CompletedTaskDurations(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+	if err := store.MigrateSchema(); err != nil { // Creates the events table
+		t.Fatalf("MigrateSchema: %v", err)
+	}
+	epic, err := store.CreateEpic("Epic", "")
+	if err != nil {
+		t.Fatalf("Failed to create epic: %v", err)
+	}
+	task, err := store.CreateTask("Task", "", epic.ID, 0, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	events := []struct {
+		id, kind, data string
+		at             int64
+	}{
+		{"e1", "task.completed", `{"duration": 60000}`, 100},
+		{"e2", "task.completed", `{"duration": 120000}`, 200},
+		{"e3", "task.failed", `{"duration": 5000}`, 300},
+		{"e4", "task.completed", `{}`, 400},
+	}
+	for _, e := range events {
+		if err := store.RecordEvent(e.id, e.kind, e.at, task.ID, epic.ID, e.data); err != nil {
+			t.Fatalf("RecordEvent: %v", err)
+		}
+	}
+
+	durations, err := store.CompletedTaskDurations(10)
+	if err != nil {
+		t.Fatalf("CompletedTaskDurations: %v", err)
+	}
+	if len(durations) != 2 || durations[0] != 2*time.Minute || durations[1] != time.Minute {
+		t.Errorf("durations = %v, want [2m0s 1m0s]", durations)
+	}
+}
+
+func TestStore_MoveTask(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	var ids []string
+	for _, priority := range []int{5, 3, 1} {
+		task, err := store.CreateTask(fmt.Sprintf("P%d", priority), "", "", priority, nil)
+		if err != nil {
+			t.Fatalf("Failed to create task: %v", err)
+		}
+		ids = append(ids, task.ID)
+	}
+	high, mid, low := ids[0], ids[1], ids[2]
+
+	order := func() []string {
+		t.Helper()
+		tasks, err := store.ListQueue("")
+		if err != nil {
+			t.Fatalf("ListQueue: %v", err)
+		}
+		var got []string
+		for _, task := range tasks {
+			got = append(got, task.ID)
+		}
+		return got
+	}
+	expect := func(want ...string) {
+		t.Helper()
+		if got := order(); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("queue = %v, want %v", got, want)
+		}
+	}
+
+	if err := store.MoveTask(low, db.QueuePin); err != nil {
+		t.Fatalf("pin: %v", err)
+	}
+	if err := store.MoveTask(mid, db.QueueBump); err != nil {
+		t.Fatalf("bump: %v", err)
+	}
+	// A bump stays behind the pinned task
+	expect(low, mid, high)
+
+	if err := store.MoveTask(low, db.QueueDefer); err != nil {
+		t.Fatalf("defer: %v", err)
+	}
+	expect(mid, high, low)
+
+	// Workers claim in the same order
+	claimed, err := store.ClaimTask("worker-1")
+	if err != nil || claimed == nil || claimed.ID != mid {
+		t.Fatalf("ClaimTask = %v, %v; want %s", claimed, err, mid)
+	}
+	if err := store.MoveTask(mid, db.QueueBump); err == nil {
+		t.Error("moving a claimed task should fail")
+	}
+
+	if err := store.MoveTask(low, db.QueueReset); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if task, _ := store.GetTask(low); task.EffectivePriority != nil {
+		t.Errorf("effective priority after reset = %d, want nil", *task.EffectivePriority)
+	}
+	expect(high, low)
+
+	if err := store.MoveTask("missing", db.QueuePin); err == nil {
+		t.Error("moving a missing task should fail")
+	}
+}
+
+func TestStore_FixTaskEscalation(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	first, _ := store.CreateTask("First", "", "", 2, nil)
+	second, _ := store.CreateTask("Second", "", "", 2, nil)
+	other, _ := store.CreateTask("Other", "", "", 6, nil)
+
+	fix, _, err := store.QueueFixTask(first.ID, "Add missing dependency left-pad", "", first.Priority+1)
+	if err != nil {
+		t.Fatalf("QueueFixTask: %v", err)
+	}
+	if escalated, err := store.EscalatedPriorities(); err != nil || len(escalated) != 0 {
+		t.Fatalf("EscalatedPriorities = %v, %v; want none above the fix task's own priority", escalated, err)
+	}
+
+	// Moving the waiting task up the queue takes its fix along
+	if err := store.MoveTask(first.ID, db.QueueBump); err != nil {
+		t.Fatalf("bump: %v", err)
+	}
+	escalated, err := store.EscalatedPriorities()
+	if err != nil || escalated[fix.ID] != 8 {
+		t.Fatalf("EscalatedPriorities = %v, %v; want %s at 8", escalated, err, fix.ID)
+	}
+
+	// Each further waiting task raises it one more
+	if _, _, err := store.QueueFixTask(second.ID, "Add missing dependency left-pad", "", second.Priority+1); err != nil {
+		t.Fatalf("QueueFixTask: %v", err)
+	}
+	if escalated, _ := store.EscalatedPriorities(); escalated[fix.ID] != 9 {
+		t.Errorf("EscalatedPriorities = %v, want %s at 9", escalated, fix.ID)
+	}
+
+	queue, err := store.ListQueue("")
+	if err != nil || len(queue) != 4 || queue[0].ID != fix.ID {
+		t.Fatalf("ListQueue = %v, %v; want %s first", queue, err, fix.ID)
+	}
+	ready, err := store.ReadyTasks("")
+	if err != nil || len(ready) != 2 || ready[0].ID != fix.ID || ready[0].Priority != 9 || ready[1].ID != other.ID {
+		t.Fatalf("ReadyTasks = %+v, %v; want %s at 9 ahead of %s", ready, err, fix.ID, other.ID)
+	}
+
+	// A queue move overrides the escalation
+	if err := store.MoveTask(fix.ID, db.QueueDefer); err != nil {
+		t.Fatalf("defer: %v", err)
+	}
+	if escalated, _ := store.EscalatedPriorities(); len(escalated) != 0 {
+		t.Errorf("EscalatedPriorities = %v, want none for a moved fix task", escalated)
+	}
+	claimed, err := store.ClaimTask("worker-1")
+	if err != nil || claimed == nil || claimed.ID != other.ID {
+		t.Fatalf("ClaimTask = %v, %v; want %s", claimed, err, other.ID)
+	}
+}
+
+func TestStore_TaskUsage(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	program, _ := store.CreateEpic("Program", "")
+	phase, _ := store.CreateSubEpic("Phase", "", program.ID)
+	first, _ := store.CreateTask("First", "", program.ID, 0, nil)
+	second, _ := store.CreateTask("Second", "", phase.ID, 0, nil)
+
+	// Two attempts at the first task, one at the second
+	for _, rec := range []struct {
+		id    string
+		usage db.Usage
+	}{
+		{first.ID, db.Usage{InputTokens: 1000, OutputTokens: 200, CostUSD: 0.10}},
+		{first.ID, db.Usage{InputTokens: 500, OutputTokens: 100, CostUSD: 0.05}},
+		{second.ID, db.Usage{InputTokens: 2000, OutputTokens: 400, CostUSD: 0.25}},
+	} {
+		if err := store.AddTaskUsage(rec.id, rec.usage); err != nil {
+			t.Fatalf("AddTaskUsage(%s): %v", rec.id, err)
+		}
+	}
+	if err := store.AddTaskUsage("missing", db.Usage{CostUSD: 1}); err == nil {
+		t.Error("recording usage of a missing task should fail")
+	}
+
+	task, err := store.GetTask(first.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if task.InputTokens != 1500 || task.OutputTokens != 300 || math.Abs(task.CostUSD-0.15) > 1e-9 {
+		t.Errorf("first task usage = %+v, want both attempts summed", db.TaskUsage(task))
+	}
+
+	status, err := store.GetProjectStatus()
+	if err != nil {
+		t.Fatalf("GetProjectStatus: %v", err)
+	}
+	if status.Usage.InputTokens != 3500 || math.Abs(status.Usage.CostUSD-0.40) > 1e-9 {
+		t.Errorf("project usage = %+v, want 3500 tokens in, $0.40", status.Usage)
+	}
+	root := status.Epics[0]
+	if root.Usage.OutputTokens != 700 || math.Abs(root.Children[0].Usage.CostUSD-0.25) > 1e-9 {
+		t.Errorf("epic usage = %+v, phase %+v; want the phase rolled up into the program", root.Usage, root.Children[0].Usage)
+	}
+}
+
+func TestStore_LeaseExpiry(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+	store.SetLeaseTTL(time.Minute)
+
+	task, _ := store.CreateTask("Leased", "", "", 0, nil)
+	claimed, err := store.ClaimTask("worker-1")
+	if err != nil || claimed == nil {
+		t.Fatalf("ClaimTask = %v, %v", claimed, err)
+	}
+	if err := store.RenewLease(task.ID, "worker-1"); err != nil {
+		t.Fatalf("RenewLease: %v", err)
+	}
+	if err := store.RenewLease(task.ID, "worker-2"); !errors.Is(err, db.ErrLeaseLost) {
+		t.Errorf("renewing another worker's lease = %v, want ErrLeaseLost", err)
+	}
+
+	// A live lease is left alone
+	if reaped, err := store.ReapExpiredLeases(); err != nil || len(reaped) != 0 {
+		t.Fatalf("ReapExpiredLeases = %v, %v; want nothing reaped", reaped, err)
+	}
+
+	expire := func() {
+		t.Helper()
+		if _, err := store.DB.Exec(`UPDATE tasks SET lease_expires_at = ? WHERE id = ?`, time.Now().Add(-time.Second).Unix(), task.ID); err != nil {
+			t.Fatalf("expiring lease: %v", err)
+		}
+	}
+	expire()
+	reaped, err := store.ReapExpiredLeases()
+	if err != nil {
+		t.Fatalf("ReapExpiredLeases: %v", err)
+	}
+	if len(reaped) != 1 || reaped[0].Status != types.TaskStatusReady || reaped[0].Attempts != 1 || reaped[0].ClaimedBy != "worker-1" {
+		t.Fatalf("reaped = %+v, want the task back in ready after 1 attempt", reaped)
+	}
+	if got, _ := store.GetTask(task.ID); got.Status != types.TaskStatusReady || got.ClaimedBy != "" || got.Attempts != 1 {
+		t.Errorf("task after reaping = %s, claimed by %q, %d attempts", got.Status, got.ClaimedBy, got.Attempts)
+	}
+	if err := store.RenewLease(task.ID, "worker-1"); !errors.Is(err, db.ErrLeaseLost) {
+		t.Errorf("renewing a reaped lease = %v, want ErrLeaseLost", err)
+	}
+
+	// Out of attempts, the task fails instead of cycling
+	if _, err := store.DB.Exec(`UPDATE tasks SET attempts = max_attempts - 1 WHERE id = ?`, task.ID); err != nil {
+		t.Fatalf("setting attempts: %v", err)
+	}
+	if claimed, _ := store.ClaimTask("worker-2"); claimed == nil {
+		t.Fatal("reaped task could not be claimed again")
+	}
+	expire()
+	reaped, err = store.ReapExpiredLeases()
+	if err != nil || len(reaped) != 1 || reaped[0].Status != types.TaskStatusFailed {
+		t.Fatalf("ReapExpiredLeases = %+v, %v; want the task failed", reaped, err)
+	}
+}
+
+func TestStore_ClaimedTaskUpdates(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+	store.SetLeaseTTL(time.Minute)
+
+	task, _ := store.CreateTask("Leased", "", "", 0, nil)
+	if claimed, _ := store.ClaimTask("worker-1"); claimed == nil {
+		t.Fatal("ClaimTask claimed nothing")
+	}
+	// The lease expires and another worker takes the task
+	if _, err := store.DB.Exec(`UPDATE tasks SET lease_expires_at = ? WHERE id = ?`, time.Now().Add(-time.Second).Unix(), task.ID); err != nil {
+		t.Fatalf("expiring lease: %v", err)
+	}
+	if _, err := store.ReapExpiredLeases(); err != nil {
+		t.Fatalf("ReapExpiredLeases: %v", err)
+	}
+	if claimed, _ := store.ClaimTask("worker-2"); claimed == nil {
+		t.Fatal("reaped task could not be claimed again")
+	}
+
+	// The first worker can no longer finish it
+	if err := store.CompleteClaimedTask(task.ID, "worker-1"); !errors.Is(err, db.ErrLeaseLost) {
+		t.Errorf("completing a lost claim = %v, want ErrLeaseLost", err)
+	}
+	if err := store.FailClaimedTask(task.ID, "worker-1", "boom"); !errors.Is(err, db.ErrLeaseLost) {
+		t.Errorf("failing a lost claim = %v, want ErrLeaseLost", err)
+	}
+	if err := store.RequeueClaimedTask(task.ID, "worker-1", "boom", time.Time{}); !errors.Is(err, db.ErrLeaseLost) {
+		t.Errorf("requeueing a lost claim = %v, want ErrLeaseLost", err)
+	}
+	if got, _ := store.GetTask(task.ID); got.Status != types.TaskStatusClaimed || got.ClaimedBy != "worker-2" {
+		t.Errorf("task = %s claimed by %q, want it left to worker-2", got.Status, got.ClaimedBy)
+	}
+
+	if err := store.CompleteClaimedTask(task.ID, "worker-2"); err != nil {
+		t.Fatalf("completing the held claim: %v", err)
+	}
+	if got, _ := store.GetTask(task.ID); got.Status != types.TaskStatusCompleted {
+		t.Errorf("task = %s, want completed", got.Status)
+	}
+}
+
+func TestStore_TaskAttempts(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, _ := store.CreateTask("Flaky", "", "", 0, nil)
+
+	first, err := store.StartAttempt(task.ID, "worker-1")
+	if err != nil {
+		t.Fatalf("StartAttempt: %v", err)
+	}
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusFailed, "tests failed"); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	if err := store.SetAttemptSession(first.ID, "session-1"); err != nil {
+		t.Fatalf("SetAttemptSession: %v", err)
+	}
+	if err := store.FinishAttempt(first.ID, ".drover/logs/"+task.ID+"/1.log", ""); err != nil {
+		t.Fatalf("FinishAttempt: %v", err)
+	}
+
+	second, err := store.StartAttempt(task.ID, "worker-2")
+	if err != nil {
+		t.Fatalf("StartAttempt: %v", err)
+	}
+	if second.Number != 2 {
+		t.Errorf("second attempt number = %d, want 2", second.Number)
+	}
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusCompleted, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	if err := store.SetAttemptModel(second.ID, "claude/haiku"); err != nil {
+		t.Fatalf("SetAttemptModel: %v", err)
+	}
+	if err := store.FinishAttempt(second.ID, "", ""); err != nil {
+		t.Fatalf("FinishAttempt: %v", err)
+	}
+
+	attempts, err := store.ListAttempts(task.ID)
+	if err != nil {
+		t.Fatalf("ListAttempts: %v", err)
+	}
+	if len(attempts) != 2 {
+		t.Fatalf("got %d attempts, want 2", len(attempts))
+	}
+	if a := attempts[0]; a.Number != 1 || a.WorkerID != "worker-1" || a.Outcome != types.TaskStatusFailed ||
+		a.Error != "tests failed" || a.OutputPath == "" || a.EndedAt == nil || a.SessionID != "session-1" {
+		t.Errorf("first attempt = %+v, want the failure it ended with kept", a)
+	}
+	if a := attempts[1]; a.Outcome != types.TaskStatusCompleted || a.Error != "" || a.SessionID != "" || a.Model != "claude/haiku" {
+		t.Errorf("second attempt = %+v, want completed without error", a)
+	}
+}
+
+func TestStore_AddRemoveDependency(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	a, _ := store.CreateTask("A", "", "", 0, nil)
+	b, _ := store.CreateTask("B", "", "", 0, nil)
+	c, _ := store.CreateTask("C", "", "", 0, nil)
+
+	statusOf := func(id string) types.TaskStatus {
+		task, err := store.GetTask(id)
+		if err != nil {
+			t.Fatalf("GetTask(%s): %v", id, err)
+		}
+		return task.Status
+	}
+
+	if err := store.AddDependency(b.ID, a.ID); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+	if err := store.AddDependency(b.ID, a.ID); err != nil {
+		t.Errorf("adding an existing dependency should be a no-op, got %v", err)
+	}
+	if got := statusOf(b.ID); got != types.TaskStatusBlocked {
+		t.Errorf("B after depending on A = %s, want blocked", got)
+	}
+	if err := store.AddDependency(b.ID, b.ID); err == nil {
+		t.Error("a task depending on itself should fail")
+	}
+	if err := store.AddDependency(b.ID, "missing"); err == nil {
+		t.Error("depending on a missing task should fail")
+	}
+
+	// A dependency on a completed task doesn't block
+	if err := store.CompleteTask(c.ID); err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	d, _ := store.CreateTask("D", "", "", 0, nil)
+	if err := store.AddDependency(d.ID, c.ID); err != nil {
+		t.Fatalf("AddDependency: %v", err)
+	}
+	if got := statusOf(d.ID); got != types.TaskStatusReady {
+		t.Errorf("D after depending on completed C = %s, want ready", got)
+	}
+
+	if err := store.RemoveDependency(b.ID, a.ID); err != nil {
+		t.Fatalf("RemoveDependency: %v", err)
+	}
+	if got := statusOf(b.ID); got != types.TaskStatusReady {
+		t.Errorf("B after removing its only blocker = %s, want ready", got)
+	}
+	if blockers, _ := store.GetBlockedBy(b.ID); len(blockers) != 0 {
+		t.Errorf("B still blocked by %v", blockers)
+	}
+	if err := store.RemoveDependency(b.ID, a.ID); err == nil {
+		t.Error("removing a missing dependency should fail")
+	}
+}
+
+func TestStore_DependencyCycles(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	a, _ := store.CreateTask("A", "", "", 0, nil)
+	b, _ := store.CreateTask("B", "", "", 0, []string{a.ID})
+	c, _ := store.CreateTask("C", "", "", 0, []string{b.ID})
+
+	err := store.AddDependency(a.ID, c.ID)
+	if !errors.Is(err, db.ErrDependencyCycle) {
+		t.Fatalf("AddDependency closing A → C → B → A = %v, want ErrDependencyCycle", err)
+	}
+	if report, err := store.ValidateGraph(); err != nil || !report.OK() {
+		t.Fatalf("ValidateGraph = %+v, %v; want a clean graph", report, err)
+	}
+
+	// Cycles can still arrive through imports, bypassing AddDependency
+	if _, err := store.DB.Exec(`INSERT INTO task_dependencies (task_id, blocked_by) VALUES (?, ?)`, a.ID, c.ID); err != nil {
+		t.Fatalf("inserting cycle: %v", err)
+	}
+	d, _ := store.CreateTask("D", "", "", 0, []string{c.ID})
+	failed, _ := store.CreateTask("Failed", "", "", 0, nil)
+	if err := store.UpdateTaskStatus(failed.ID, types.TaskStatusFailed, "gave up"); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	e, _ := store.CreateTask("E", "", "", 0, []string{failed.ID})
+	f, _ := store.CreateTask("F", "", "", 0, nil)
+
+	report, err := store.ValidateGraph()
+	if err != nil {
+		t.Fatalf("ValidateGraph: %v", err)
+	}
+	if len(report.Cycles) != 1 || len(report.Cycles[0]) != 3 {
+		t.Errorf("cycles = %v, want the one through A, B and C", report.Cycles)
+	}
+	stuck := make(map[string]string)
+	for _, s := range report.Stuck {
+		stuck[s.TaskID] = s.Reason
+	}
+	for _, id := range []string{a.ID, b.ID, c.ID, d.ID, e.ID} {
+		if stuck[id] == "" {
+			t.Errorf("%s not reported stuck; got %v", id, report.Stuck)
+		}
+	}
+	if stuck[f.ID] != "" || stuck[failed.ID] != "" {
+		t.Errorf("only unfinished tasks behind a cycle or failure are stuck; got %v", report.Stuck)
+	}
+}
+
+func TestStore_CreateTasksBulk(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	result, err := store.CreateTasksBulk(db.BulkTasks{
+		Epics: []db.NewEpic{{Title: "Parser"}},
+		Tasks: []db.NewTask{
+			{Title: "Lexer", Epic: 0, Parent: db.NoRef, BlockedBy: []int{2}},
+			{Title: "Tokens", Epic: db.NoRef, Parent: 0},
+			{Title: "Grammar", Epic: 0, Parent: db.NoRef},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateTasksBulk: %v", err)
+	}
+	lexer, tokens, grammar := result.Tasks[0], result.Tasks[1], result.Tasks[2]
+	if lexer.Status != types.TaskStatusBlocked || lexer.EpicID != result.Epics[0].ID {
+		t.Errorf("lexer = %+v, want blocked in the new epic", lexer)
+	}
+	if tokens.ParentID != lexer.ID || tokens.ID != lexer.ID+".1" || tokens.EpicID != lexer.EpicID {
+		t.Errorf("sub-task = %+v, want %s.1 in its parent's epic", tokens, lexer.ID)
+	}
+	if blockers, _ := store.GetBlockedBy(lexer.ID); len(blockers) != 1 || blockers[0] != grammar.ID {
+		t.Errorf("lexer blocked by %v, want the later grammar task", blockers)
+	}
+
+	countTasks := func() int {
+		var n int
+		store.DB.QueryRow(`SELECT COUNT(*) FROM tasks`).Scan(&n)
+		return n
+	}
+	before := countTasks()
+	for name, batch := range map[string]db.BulkTasks{
+		"cycle": {Tasks: []db.NewTask{
+			{Title: "A", Epic: db.NoRef, Parent: db.NoRef, BlockedBy: []int{1}},
+			{Title: "B", Epic: db.NoRef, Parent: db.NoRef, BlockedBy: []int{0}},
+		}},
+		"bad reference": {Tasks: []db.NewTask{
+			{Title: "A", Epic: db.NoRef, Parent: db.NoRef},
+			{Title: "B", Epic: db.NoRef, Parent: db.NoRef, BlockedBy: []int{5}},
+		}},
+		"missing epic": {Tasks: []db.NewTask{{Title: "A", Epic: 0, Parent: db.NoRef}}},
+	} {
+		if _, err := store.CreateTasksBulk(batch); err == nil {
+			t.Errorf("%s: CreateTasksBulk should fail", name)
+		}
+	}
+	if after := countTasks(); after != before {
+		t.Errorf("failed batches created %d tasks, want none", after-before)
+	}
+}
+
+func TestStore_EpicRollup(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	program, _ := store.CreateEpic("Program", "")
+	phase, _ := store.CreateSubEpic("Phase", "", program.ID)
+	first, _ := store.CreateTask("First", "", phase.ID, 0, nil)
+	second, _ := store.CreateTask("Second", "", program.ID, 0, nil)
+
+	epicStatus := func(id string) types.EpicStatus {
+		var status types.EpicStatus
+		if err := store.DB.QueryRow(`SELECT status FROM epics WHERE id = ?`, id).Scan(&status); err != nil {
+			t.Fatalf("reading epic %s: %v", id, err)
+		}
+		return status
+	}
+
+	if err := store.CompleteTask(first.ID); err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if epicStatus(phase.ID) != types.EpicStatusClosed || epicStatus(program.ID) != types.EpicStatusOpen {
+		t.Errorf("after the phase's last task: phase %s, program %s; want closed, open",
+			epicStatus(phase.ID), epicStatus(program.ID))
+	}
+
+	if err := store.CompleteTask(second.ID); err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	if epicStatus(program.ID) != types.EpicStatusClosed {
+		t.Errorf("program = %s after its last task completed, want closed", epicStatus(program.ID))
+	}
+
+	if _, err := store.ResetTasksByIDs([]string{first.ID}); err != nil {
+		t.Fatalf("ResetTasksByIDs: %v", err)
+	}
+	if epicStatus(phase.ID) != types.EpicStatusOpen || epicStatus(program.ID) != types.EpicStatusOpen {
+		t.Errorf("after a reset: phase %s, program %s; want both reopened", epicStatus(phase.ID), epicStatus(program.ID))
+	}
+
+	status, err := store.GetProjectStatus()
+	if err != nil {
+		t.Fatalf("GetProjectStatus: %v", err)
+	}
+	if got := status.Epics[0].Progress(); got != 50 {
+		t.Errorf("program progress = %.0f%%, want 50%%", got)
+	}
+}
+
+func TestStore_Transcripts(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, _ := store.CreateTask("Chatty", "", "", 0, nil)
+	short, _ := store.StartAttempt(task.ID, "worker-1")
+	long, _ := store.StartAttempt(task.ID, "worker-1")
+	silent, _ := store.StartAttempt(task.ID, "worker-1")
+
+	if err := store.SaveTranscript(short.ID, "edited main.go\nall tests pass\n"); err != nil {
+		t.Fatalf("SaveTranscript: %v", err)
+	}
+	got, err := store.GetTranscript(task.ID, short.Number)
+	if err != nil || got != "edited main.go\nall tests pass\n" {
+		t.Errorf("GetTranscript = %q, %v; want the output back", got, err)
+	}
+
+	output := strings.Repeat("x", db.MaxTranscriptBytes) + "the end"
+	if err := store.SaveTranscript(long.ID, output); err != nil {
+		t.Fatalf("SaveTranscript: %v", err)
+	}
+	got, _ = store.GetTranscript(task.ID, long.Number)
+	if !strings.HasSuffix(got, "the end") || len(got) > db.MaxTranscriptBytes+100 {
+		t.Errorf("capped transcript is %d bytes ending %q, want its end kept", len(got), got[len(got)-10:])
+	}
+	attempts, _ := store.ListAttempts(task.ID)
+	if attempts[1].TranscriptSize != int64(len(output)) {
+		t.Errorf("transcript size = %d, want the full output's %d", attempts[1].TranscriptSize, len(output))
+	}
+
+	if got, err := store.GetTranscript(task.ID, silent.Number); err != nil || got != "" {
+		t.Errorf("GetTranscript without output = %q, %v; want empty", got, err)
+	}
+	if _, err := store.GetTranscript(task.ID, 9); err == nil {
+		t.Error("GetTranscript of a missing attempt should fail")
+	}
+}
+
+func TestStore_PauseResume(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+	store.SetLeaseTTL(time.Minute)
+
+	task, _ := store.CreateTask("Pausable", "", "", 0, nil)
+	if err := store.PauseTask(task.ID); err == nil {
+		t.Error("pausing a ready task should fail")
+	}
+	if err := store.PauseTask("missing"); err == nil {
+		t.Error("pausing a missing task should fail")
+	}
+
+	if claimed, err := store.ClaimTask("worker-1"); err != nil || claimed == nil {
+		t.Fatalf("ClaimTask = %v, %v", claimed, err)
+	}
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusInProgress, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	if err := store.PauseTask(task.ID); err != nil {
+		t.Fatalf("PauseTask: %v", err)
+	}
+
+	// The claim stays with the worker that parks the task, but its lease ends
+	got, _ := store.GetTask(task.ID)
+	if got.Status != types.TaskStatusPaused || got.ClaimedBy != "worker-1" {
+		t.Errorf("task after pausing = %s, claimed by %q; want paused, claimed by worker-1", got.Status, got.ClaimedBy)
+	}
+	if err := store.RenewLease(task.ID, "worker-1"); !errors.Is(err, db.ErrLeaseLost) {
+		t.Errorf("renewing a paused task's lease = %v, want ErrLeaseLost", err)
+	}
+	if reaped, err := store.ReapExpiredLeases(); err != nil || len(reaped) != 0 {
+		t.Errorf("ReapExpiredLeases = %v, %v; paused tasks should not be reaped", reaped, err)
+	}
+	if claimed, _ := store.ClaimTask("worker-2"); claimed != nil {
+		t.Errorf("claimed paused task %s", claimed.ID)
+	}
+	if err := store.PauseTask(task.ID); err == nil {
+		t.Error("pausing a paused task should fail")
+	}
+
+	if err := store.ResumeTask(task.ID); err != nil {
+		t.Fatalf("ResumeTask: %v", err)
+	}
+	got, _ = store.GetTask(task.ID)
+	if got.Status != types.TaskStatusReady || got.ClaimedBy != "" {
+		t.Errorf("task after resuming = %s, claimed by %q; want ready and unclaimed", got.Status, got.ClaimedBy)
+	}
+	if err := store.ResumeTask(task.ID); err == nil {
+		t.Error("resuming a ready task should fail")
+	}
+	if claimed, _ := store.ClaimTask("worker-2"); claimed == nil || claimed.ID != task.ID {
+		t.Errorf("ClaimTask after resuming = %v, want %s", claimed, task.ID)
+	}
+
+	activity, err := store.ListActivity(task.ID)
+	if err != nil {
+		t.Fatalf("ListActivity: %v", err)
+	}
+	var transitions []string
+	for _, a := range activity {
+		if a.Kind == types.ActivityStatus {
+			transitions = append(transitions, a.Body)
+		}
+	}
+	for _, want := range []string{"in_progress → paused", "paused → ready"} {
+		if !strings.Contains(strings.Join(transitions, "\n"), want) {
+			t.Errorf("status activity %q missing %q", transitions, want)
+		}
+	}
+}
+
+func TestStore_IDFormats(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"", `^task-\d{19}$`},
+		{"ulid", `^task-[0-9A-HJKMNP-TV-Z]{26}$`},
+		{"uuid", `^task-[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`},
+		{"short", `^task-[0-9a-hjkmnp-tv-z]{6}$`},
+	}
+	for _, tt := range tests {
+		format, err := db.ParseIDFormat(tt.format)
+		if err != nil {
+			t.Fatalf("ParseIDFormat(%q): %v", tt.format, err)
+		}
+		store.SetIDFormat(format)
+
+		seen := make(map[string]bool)
+		for i := 0; i < 20; i++ {
+			task, err := store.CreateTask(fmt.Sprintf("%s %d", tt.format, i), "", "", 0, nil)
+			if err != nil {
+				t.Fatalf("CreateTask with %q IDs: %v", tt.format, err)
+			}
+			if !regexp.MustCompile(tt.want).MatchString(task.ID) {
+				t.Errorf("%q ID %s doesn't match %s", tt.format, task.ID, tt.want)
+			}
+			if seen[task.ID] {
+				t.Errorf("%q ID %s generated twice", tt.format, task.ID)
+			}
+			seen[task.ID] = true
+		}
+	}
+
+	epic, err := store.CreateEpic("Short", "")
+	if err != nil || !strings.HasPrefix(epic.ID, "epic-") || len(epic.ID) != len("epic-")+6 {
+		t.Errorf("CreateEpic with short IDs = %v, %v", epic, err)
+	}
+	if _, err := db.ParseIDFormat("sequential"); err == nil {
+		t.Error("ParseIDFormat should reject unknown formats")
+	}
+}
+
+func TestStore_ReadOnly(t *testing.T) {
+	store, path := setupTestDB(t)
+	defer store.Close()
+	task, _ := store.CreateTask("Visible", "", "", 0, nil)
+
+	reader, err := db.OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly: %v", err)
+	}
+	defer reader.Close()
+	if got, err := reader.GetTask(task.ID); err != nil || got.Title != "Visible" {
+		t.Fatalf("GetTask through the read-only store = %v, %v", got, err)
+	}
+	if _, err := reader.CreateTask("Written", "", "", 0, nil); err == nil {
+		t.Error("writing through the read-only store should fail")
+	}
+
+	// A snapshot only sees writes made before its latest refresh
+	snapshot, err := db.OpenSnapshot(path, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenSnapshot: %v", err)
+	}
+	defer snapshot.Close()
+	later, _ := store.CreateTask("Later", "", "", 0, nil)
+	if got, _ := snapshot.Store().GetTask(later.ID); got != nil {
+		t.Error("snapshot saw a task created after it was taken")
+	}
+	if err := snapshot.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got, err := snapshot.Store().GetTask(later.ID); err != nil || got == nil {
+		t.Errorf("refreshed snapshot GetTask = %v, %v", got, err)
+	}
+}
+
+func TestStore_CriticalPathSchedule(t *testing.T) {
+	// solo is older but unblocks nothing; head starts a chain of three
+	setup := func(t *testing.T) (*db.Store, *types.Task, *types.Task) {
+		store, _ := setupTestDB(t)
+		solo, _ := store.CreateTask("Solo", "", "", 0, nil)
+		head, _ := store.CreateTask("Head", "", "", 0, nil)
+		mid, _ := store.CreateTask("Mid", "", "", 0, []string{head.ID})
+		if _, err := store.CreateTask("Tail", "", "", 0, []string{mid.ID}); err != nil {
+			t.Fatalf("CreateTask: %v", err)
+		}
+		if _, err := store.DB.Exec(`UPDATE tasks SET created_at = created_at - 60 WHERE id = ?`, solo.ID); err != nil {
+			t.Fatalf("ageing task: %v", err)
+		}
+		return store, solo, head
+	}
+
+	store, solo, _ := setup(t)
+	defer store.Close()
+	if got, err := store.ClaimTask("worker"); err != nil || got == nil || got.ID != solo.ID {
+		t.Errorf("priority schedule claimed %v, %v; want the older %s", got, err, solo.ID)
+	}
+
+	store, solo, head := setup(t)
+	defer store.Close()
+	schedule, err := db.ParseSchedule("critical-path")
+	if err != nil {
+		t.Fatalf("ParseSchedule: %v", err)
+	}
+	store.SetSchedule(schedule)
+	if got, err := store.ClaimTask("worker"); err != nil || got == nil || got.ID != head.ID {
+		t.Errorf("critical-path schedule claimed %v, %v; want the chain head %s", got, err, head.ID)
+	}
+	if got, err := store.ClaimTask("worker"); err != nil || got == nil || got.ID != solo.ID {
+		t.Errorf("second critical-path claim = %v, %v; want %s", got, err, solo.ID)
+	}
+	if got, err := store.ClaimTask("worker"); err != nil || got != nil {
+		t.Errorf("claim with nothing ready = %v, %v; want nil", got, err)
+	}
+
+	// Priority still comes first
+	store, solo, _ = setup(t)
+	defer store.Close()
+	store.SetSchedule(schedule)
+	if _, err := store.DB.Exec(`UPDATE tasks SET priority = 5 WHERE id = ?`, solo.ID); err != nil {
+		t.Fatalf("raising priority: %v", err)
+	}
+	if got, err := store.ClaimTask("worker"); err != nil || got == nil || got.ID != solo.ID {
+		t.Errorf("critical-path schedule claimed %v, %v; want the higher-priority %s", got, err, solo.ID)
+	}
+
+	if _, err := db.ParseSchedule("random"); err == nil {
+		t.Error("ParseSchedule should reject unknown schedules")
+	}
+}
+
+func TestStore_RequeueTask(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, _ := store.CreateTask("Flaky", "", "", 0, nil)
+	if err := store.SetTaskRetryPolicy(task.ID, "backoff=1m"); err != nil {
+		t.Fatalf("SetTaskRetryPolicy: %v", err)
+	}
+	if _, err := store.ClaimTask("worker"); err != nil {
+		t.Fatalf("ClaimTask: %v", err)
+	}
+
+	notBefore := time.Now().Add(time.Hour)
+	if err := store.RequeueTask(task.ID, "agent crashed", notBefore); err != nil {
+		t.Fatalf("RequeueTask: %v", err)
+	}
+	got, err := store.GetTask(task.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.Status != types.TaskStatusReady || got.Attempts != 1 || got.LastError != "agent crashed" {
+		t.Errorf("requeued task = %s, %d attempt(s), %q; want ready, 1, agent crashed", got.Status, got.Attempts, got.LastError)
+	}
+	if got.ScheduledAt == nil || *got.ScheduledAt != notBefore.Unix() {
+		t.Errorf("requeued task scheduled at %v, want %d", got.ScheduledAt, notBefore.Unix())
+	}
+	if got.RetryPolicy != "backoff=1m" {
+		t.Errorf("retry policy = %q, want backoff=1m", got.RetryPolicy)
+	}
+	if claimed, err := store.ClaimTask("worker"); err != nil || claimed != nil {
+		t.Errorf("claim during the backoff = %v, %v; want nothing", claimed, err)
+	}
+
+	if err := store.RequeueTask("task-missing", "", time.Time{}); err == nil {
+		t.Error("RequeueTask of a missing task should fail")
+	}
+}
+
+func TestStore_DeadLetters(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	failed, _ := store.CreateTask("Broken", "", "", 0, nil)
+	healthy, _ := store.CreateTask("Fine", "", "", 0, nil)
+	if err := store.UpdateTaskStatus(failed.ID, types.TaskStatusFailed, "tests failed: 3 errors"); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+
+	dead, err := store.ListDeadLetters("")
+	if err != nil {
+		t.Fatalf("ListDeadLetters: %v", err)
+	}
+	if len(dead) != 1 || dead[0].ID != failed.ID || dead[0].LastError != "tests failed: 3 errors" {
+		t.Fatalf("dead letters = %v, want only %s with its last error", dead, failed.ID)
+	}
+	status, err := store.GetProjectStatus()
+	if err != nil {
+		t.Fatalf("GetProjectStatus: %v", err)
+	}
+	if status.Failed != 1 {
+		t.Errorf("failed = %d, want 1", status.Failed)
+	}
+
+	if err := store.RequeueDeadLetter(healthy.ID, ""); err == nil {
+		t.Error("requeueing a task that isn't failed should fail")
+	}
+	if err := store.RequeueDeadLetter(failed.ID, "Run the tests before finishing"); err != nil {
+		t.Fatalf("RequeueDeadLetter: %v", err)
+	}
+	got, err := store.GetTask(failed.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if got.Status != types.TaskStatusReady || got.Attempts != 0 || got.LastError != "" {
+		t.Errorf("requeued task = %s, %d attempt(s), %q; want ready, 0, no error", got.Status, got.Attempts, got.LastError)
+	}
+	guidance, err := store.GetPendingGuidance(failed.ID)
+	if err != nil {
+		t.Fatalf("GetPendingGuidance: %v", err)
+	}
+	if len(guidance) != 1 || guidance[0].Message != "Run the tests before finishing" {
+		t.Errorf("pending guidance = %v, want the requeue guidance", guidance)
+	}
+	if dead, _ := store.ListDeadLetters(""); len(dead) != 0 {
+		t.Errorf("dead letters after requeue = %d, want 0", len(dead))
+	}
+}
+
+func TestStore_MutexKey(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	first, _ := store.CreateTask("Migrate users", "", "", 10, nil)
+	second, _ := store.CreateTask("Migrate orders", "", "", 9, nil)
+	other, _ := store.CreateTask("Update docs", "", "", 1, nil)
+	for _, id := range []string{first.ID, second.ID} {
+		if err := store.SetTaskMutexKey(id, "schema.sql"); err != nil {
+			t.Fatalf("SetTaskMutexKey: %v", err)
+		}
+	}
+
+	claimed, err := store.ClaimTask("worker-1")
+	if err != nil || claimed == nil || claimed.ID != first.ID {
+		t.Fatalf("first claim = %v, %v; want %s", claimed, err, first.ID)
+	}
+	// The second schema task waits for the first, so the lower-priority one runs
+	claimed, err = store.ClaimTask("worker-2")
+	if err != nil || claimed == nil || claimed.ID != other.ID {
+		t.Fatalf("second claim = %v, %v; want %s", claimed, err, other.ID)
+	}
+	if claimed, err := store.ClaimTask("worker-3"); err != nil || claimed != nil {
+		t.Fatalf("claim while the key is held = %v, %v; want nothing", claimed, err)
+	}
+
+	if err := store.UpdateTaskStatus(first.ID, types.TaskStatusCompleted, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	claimed, err = store.ClaimTask("worker-3")
+	if err != nil || claimed == nil || claimed.ID != second.ID {
+		t.Fatalf("claim after the key is released = %v, %v; want %s", claimed, err, second.ID)
+	}
+	if got, _ := store.GetTask(second.ID); got.MutexKey != "schema.sql" {
+		t.Errorf("mutex key = %q, want schema.sql", got.MutexKey)
+	}
+
+	if err := store.SetTaskMutexKey("task-missing", "x"); err == nil {
+		t.Error("SetTaskMutexKey of a missing task should fail")
+	}
+}
+
+func TestStore_RequestCancel(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+	store.SetLeaseTTL(time.Minute)
+
+	task, _ := store.CreateTask("Long running", "", "", 0, nil)
+	if err := store.RequestCancel(task.ID, "wrong approach"); err == nil {
+		t.Error("RequestCancel of a ready task should fail; it can be cancelled at once")
+	}
+	if _, err := store.ClaimTask("worker-1"); err != nil {
+		t.Fatalf("ClaimTask: %v", err)
+	}
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusInProgress, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	if requested, _ := store.CancelRequested(task.ID); requested {
+		t.Fatal("cancellation requested before RequestCancel")
+	}
+
+	if err := store.RequestCancel(task.ID, "wrong approach"); err != nil {
+		t.Fatalf("RequestCancel: %v", err)
+	}
+	got, _ := store.GetTask(task.ID)
+	if requested, _ := store.CancelRequested(task.ID); !requested || got.Status != types.TaskStatusInProgress {
+		t.Fatalf("after RequestCancel: requested %v, status %s; want requested and still in_progress", requested, got.Status)
+	}
+
+	// The worker finishes the cancellation, keeping the requested reason
+	if err := store.CancelTask(task.ID, ""); err != nil {
+		t.Fatalf("CancelTask: %v", err)
+	}
+	got, _ = store.GetTask(task.ID)
+	if got.Status != types.TaskStatusCancelled || got.LastError != "wrong approach" || got.ClaimedBy != "" {
+		t.Errorf("cancelled task = %s, last error %q, claimed by %q", got.Status, got.LastError, got.ClaimedBy)
+	}
+	if requested, _ := store.CancelRequested(task.ID); requested {
+		t.Error("cancellation still requested after CancelTask")
+	}
+
+	// A worker that dies before finishing the cancellation has its task cancelled when reaped
+	orphan, _ := store.CreateTask("Orphaned", "", "", 0, nil)
+	if _, err := store.ClaimTask("worker-2"); err != nil {
+		t.Fatalf("ClaimTask: %v", err)
+	}
+	if err := store.RequestCancel(orphan.ID, "stop"); err != nil {
+		t.Fatalf("RequestCancel: %v", err)
+	}
+	if _, err := store.DB.Exec(`UPDATE tasks SET lease_expires_at = ? WHERE id = ?`, time.Now().Add(-time.Second).Unix(), orphan.ID); err != nil {
+		t.Fatalf("expiring lease: %v", err)
+	}
+	reaped, err := store.ReapExpiredLeases()
+	if err != nil || len(reaped) != 1 || reaped[0].Status != types.TaskStatusCancelled {
+		t.Fatalf("ReapExpiredLeases = %+v, %v; want the task cancelled", reaped, err)
+	}
+	if got, _ := store.GetTask(orphan.ID); got.Status != types.TaskStatusCancelled || got.LastError != "stop" {
+		t.Errorf("reaped task = %s, last error %q", got.Status, got.LastError)
+	}
+
+	if err := store.RequestCancel("task-missing", ""); err == nil {
+		t.Error("RequestCancel of a missing task should fail")
+	}
+}
+
+func TestStore_RecoverTask(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, _ := store.CreateTask("Crashed", "", "", 0, nil)
+	unstarted, _ := store.CreateTask("Claimed only", "", "", 0, nil)
+	for _, worker := range []string{"worker-1", "worker-2"} {
+		if _, err := store.ClaimTask(worker); err != nil {
+			t.Fatalf("ClaimTask: %v", err)
+		}
+	}
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusInProgress, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	if err := store.CreateCheckpoint(&types.TaskCheckpoint{
+		TaskID: task.ID, State: types.TaskStatusInProgress, WorkerPID: 4242, StartedAt: 100, LastHeartbeat: 100, Attempt: 1,
+	}); err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+
+	stranded, err := store.StrandedTasks()
+	if err != nil || len(stranded) != 2 {
+		t.Fatalf("StrandedTasks = %+v, %v; want both claimed tasks", stranded, err)
+	}
+	for _, s := range stranded {
+		switch s.TaskID {
+		case task.ID:
+			if s.Checkpoint == nil || s.Checkpoint.WorkerPID != 4242 || s.Status != types.TaskStatusInProgress {
+				t.Errorf("stranded %s = %+v, want in_progress with the checkpoint of pid 4242", s.TaskID, s)
+			}
+		case unstarted.ID:
+			if s.Checkpoint != nil || s.ClaimedBy == "" {
+				t.Errorf("stranded %s = %+v, want claimed without a checkpoint", s.TaskID, s)
+			}
+		}
+	}
+
+	ok, err := store.RecoverTask(task.ID, types.TaskStatusReady, 1, "worker pid 4242 is gone")
+	if err != nil || !ok {
+		t.Fatalf("RecoverTask = %v, %v", ok, err)
+	}
+	got, _ := store.GetTask(task.ID)
+	if got.Status != types.TaskStatusReady || got.ClaimedBy != "" || got.Attempts != 1 || got.LastError != "worker pid 4242 is gone" {
+		t.Errorf("recovered task = %s, claimed by %q, %d attempts, last error %q", got.Status, got.ClaimedBy, got.Attempts, got.LastError)
+	}
+	if checkpoint, _ := store.GetCheckpoint(task.ID); checkpoint != nil {
+		t.Error("checkpoint still exists after recovery")
+	}
+
+	// Already recovered: nothing left to take back
+	if ok, err := store.RecoverTask(task.ID, types.TaskStatusFailed, 1, "again"); err != nil || ok {
+		t.Errorf("recovering a ready task = %v, %v; want false", ok, err)
+	}
+	if _, err := store.RecoverTask(unstarted.ID, types.TaskStatusCompleted, 1, ""); err == nil {
+		t.Error("RecoverTask to completed should fail")
+	}
+}
+
+func TestStore_Schedulers(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	backend, _ := store.CreateEpic("Backend", "")
+	frontend, _ := store.CreateEpic("Frontend", "")
+	b1, _ := store.CreateTask("Backend 1", "", backend.ID, 5, nil)
+	if _, err := store.CreateTask("Backend 2", "", backend.ID, 4, nil); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	f1, _ := store.CreateTask("Frontend 1", "", frontend.ID, 1, nil)
+	if _, err := store.DB.Exec(`UPDATE tasks SET created_at = created_at - 60 WHERE id = ?`, f1.ID); err != nil {
+		t.Fatalf("ageing task: %v", err)
+	}
+
+	claim := func(want string) {
+		t.Helper()
+		got, err := store.ClaimTask("worker")
+		if err != nil || got == nil || got.ID != want {
+			t.Fatalf("claimed %v, %v; want %s", got, err, want)
+		}
+		if _, err := store.DB.Exec(`UPDATE tasks SET status = 'ready', claimed_by = NULL WHERE id = ?`, want); err != nil {
+			t.Fatalf("unclaiming: %v", err)
+		}
+	}
+
+	// FIFO ignores priority
+	store.SetSchedule(db.ScheduleFIFO)
+	claim(f1.ID)
+
+	// Round-robin alternates epics, best task of each first
+	store.SetSchedule(db.ScheduleRoundRobin)
+	first, second := b1.ID, f1.ID
+	if frontend.ID < backend.ID {
+		first, second = f1.ID, b1.ID
+	}
+	claim(first)
+	claim(second)
+	claim(first)
+
+	// A registered scheduler is picked by name
+	db.RegisterScheduler("lowest-priority", func() db.Scheduler {
+		return db.SchedulerFunc(func(_ context.Context, c db.ClaimConstraints) (string, error) {
+			return c.Ready[len(c.Ready)-1].ID, nil
+		})
+	})
+	schedule, err := db.ParseSchedule("lowest-priority")
+	if err != nil {
+		t.Fatalf("ParseSchedule: %v", err)
+	}
+	store.SetSchedule(schedule)
+	claim(f1.ID)
+
+	// Schedulers may only pick tasks they were offered
+	store.SetScheduler(db.SchedulerFunc(func(context.Context, db.ClaimConstraints) (string, error) {
+		return "task-missing", nil
+	}))
+	if got, err := store.ClaimTask("worker"); err == nil || got != nil {
+		t.Errorf("claim of an unoffered task = %v, %v; want an error", got, err)
+	}
+	store.SetScheduler(nil)
+	claim(b1.ID)
+}
+
+func TestStore_TaskRepo(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, _ := store.CreateTask("Show the new field", "", "", 0, nil)
+	if err := store.SetTaskRepo(task.ID, " frontend "); err != nil {
+		t.Fatalf("SetTaskRepo: %v", err)
+	}
+	if err := store.SetTaskRepo("task-missing", "frontend"); err == nil {
+		t.Error("Expected an error for an unknown task")
+	}
+
+	got, err := store.GetTask(task.ID)
+	if err != nil || got.Repo != "frontend" {
+		t.Fatalf("GetTask = %+v, %v; want repo frontend", got, err)
+	}
+	claimed, err := store.ClaimTask("worker-1")
+	if err != nil || claimed == nil || claimed.Repo != "frontend" {
+		t.Fatalf("ClaimTask = %+v, %v; want repo frontend", claimed, err)
+	}
+	stranded, err := store.StrandedTasks()
+	if err != nil || len(stranded) != 1 || stranded[0].Repo != "frontend" {
+		t.Fatalf("StrandedTasks = %+v, %v; want repo frontend", stranded, err)
+	}
+
+	if err := store.SetTaskRepo(task.ID, ""); err != nil {
+		t.Fatalf("SetTaskRepo: %v", err)
+	}
+	if got, _ := store.GetTask(task.ID); got.Repo != "" {
+		t.Errorf("Expected the repo cleared, got %q", got.Repo)
+	}
+}
+
+func TestStore_TaskEnv(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	task, _ := store.CreateTask("Run the integration tests", "", "", 0, nil)
+	if env, err := store.GetTaskEnv(task.ID); err != nil || len(env) != 0 {
+		t.Fatalf("GetTaskEnv = %v, %v; want none", env, err)
+	}
+
+	want := map[string]string{"DATABASE_URL": "postgres://localhost/test", "FEATURE_X": "on"}
+	if err := store.SetTaskEnv(task.ID, want); err != nil {
+		t.Fatalf("SetTaskEnv: %v", err)
+	}
+	if err := store.SetTaskEnv("task-missing", want); err == nil {
+		t.Error("Expected an error for an unknown task")
+	}
+
+	got, err := store.GetTask(task.ID)
+	if err != nil || len(got.Env) != 2 || got.Env["FEATURE_X"] != "on" {
+		t.Fatalf("GetTask = %+v, %v; want env %v", got, err, want)
+	}
+	env, err := store.GetTaskEnv(task.ID)
+	if err != nil || env["DATABASE_URL"] != "postgres://localhost/test" {
+		t.Fatalf("GetTaskEnv = %v, %v; want %v", env, err, want)
+	}
+
+	if err := store.SetTaskEnv(task.ID, nil); err != nil {
+		t.Fatalf("SetTaskEnv: %v", err)
+	}
+	if got, _ := store.GetTask(task.ID); len(got.Env) != 0 {
+		t.Errorf("Expected the env cleared, got %v", got.Env)
+	}
+}
+
+func TestStore_QueueLimits(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	if limits, err := store.GetQueueLimits(); err != nil || limits != nil {
+		t.Fatalf("GetQueueLimits = %+v, %v; want none set", limits, err)
+	}
+
+	worker, rate, period := 4, 10, time.Minute
+	if err := store.SetQueueLimits(db.QueueLimits{WorkerConcurrency: &worker, RateLimit: &rate, RatePeriod: &period}); err != nil {
+		t.Fatalf("SetQueueLimits: %v", err)
+	}
+	// A later change keeps the limits it doesn't set
+	global := 0
+	if err := store.SetQueueLimits(db.QueueLimits{GlobalConcurrency: &global}); err != nil {
+		t.Fatalf("SetQueueLimits: %v", err)
+	}
+
+	limits, err := store.GetQueueLimits()
+	if err != nil || limits == nil {
+		t.Fatalf("GetQueueLimits = %+v, %v", limits, err)
+	}
+	if limits.WorkerConcurrency == nil || *limits.WorkerConcurrency != 4 {
+		t.Errorf("Expected worker concurrency 4, got %v", limits.WorkerConcurrency)
+	}
+	if limits.GlobalConcurrency == nil || *limits.GlobalConcurrency != 0 {
+		t.Errorf("Expected global concurrency 0, got %v", limits.GlobalConcurrency)
+	}
+	if limits.RateLimit == nil || *limits.RateLimit != 10 || limits.RatePeriod == nil || *limits.RatePeriod != time.Minute {
+		t.Errorf("Expected a rate of 10 per minute, got %v per %v", limits.RateLimit, limits.RatePeriod)
+	}
+
+	if err := store.ResetQueueLimits(); err != nil {
+		t.Fatalf("ResetQueueLimits: %v", err)
+	}
+	if limits, err := store.GetQueueLimits(); err != nil || limits != nil {
+		t.Errorf("GetQueueLimits after reset = %+v, %v; want none set", limits, err)
+	}
+}
+
+func TestStore_CountRunning(t *testing.T) {
+	store, _ := setupTestDB(t)
+	defer store.Close()
+
+	store.CreateTask("One", "", "", 0, nil)
+	store.CreateTask("Two", "", "", 0, nil)
+	if n, err := store.CountRunning(); err != nil || n != 0 {
+		t.Fatalf("CountRunning = %d, %v; want 0", n, err)
+	}
+	if _, err := store.ClaimTask("worker-1"); err != nil {
+		t.Fatalf("ClaimTask: %v", err)
+	}
+	if n, err := store.CountRunning(); err != nil || n != 1 {
+		t.Errorf("CountRunning = %d, %v; want 1", n, err)
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// ListDeadLetters returns the dead-letter queue: failed tasks, which no run
+// will pick up again until they are requeued, most recently failed first.
+// With epicID, only tasks in that epic or its sub-epics are listed
+func (s *Store) ListDeadLetters(epicID string) ([]*types.Task, error) {
+	var tasks []*types.Task
+	var err error
+	if epicID == "" {
+		tasks, err = s.listTasks("WHERE status = 'failed'")
+	} else {
+		tasks, err = s.listTasks("WHERE status = 'failed' AND epic_id IN ("+epicSubtree+")", epicID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].UpdatedAt > tasks[j].UpdatedAt
+	})
+	return tasks, nil
+}
+
+// RequeueDeadLetter moves a failed task back to ready with a fresh set of
+// attempts. Its last error is cleared; guidance, when given, is queued for
+// the agent's next attempt so it can avoid failing the same way
+func (s *Store) RequeueDeadLetter(taskID, guidance string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status types.TaskStatus
+	if err := tx.QueryRow(`SELECT status FROM tasks WHERE id = ?`, taskID).Scan(&status); err != nil {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	if status != types.TaskStatusFailed {
+		return fmt.Errorf("task %s is %s, not in the dead-letter queue", taskID, status)
+	}
+
+	if err := recordStatusChange(tx, taskID, types.TaskStatusReady, s.actingAs(""), "requeued from the dead-letter queue", types.TaskStatusFailed); err != nil {
+		return err
+	}
+
+	now := time.Now().Unix()
+	if _, err := tx.Exec(`
+		UPDATE tasks
+		SET status = 'ready', attempts = 0, last_error = NULL,
+		    claimed_by = NULL, claimed_at = NULL, lease_expires_at = NULL,
+		    scheduled_at = NULL, updated_at = ?
+		WHERE id = ? AND status = 'failed'
+	`, now, taskID); err != nil {
+		return fmt.Errorf("requeueing task: %w", err)
+	}
+
+	if guidance != "" {
+		if _, err := tx.Exec(`
+			INSERT INTO guidance_queue (id, task_id, message, created_at, delivered)
+			VALUES (?, ?, ?, ?, 0)
+		`, generateID("guidance"), taskID, guidance, now); err != nil {
+			return fmt.Errorf("adding guidance: %w", err)
+		}
+		if _, err := recordActivity(tx, taskID, types.ActivityGuidance, "", guidance); err != nil {
+			return fmt.Errorf("recording guidance: %w", err)
+		}
+	}
+
+	if err := rollupEpics(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// AddDependency makes taskID wait for blockerID to complete. A ready task
+// with a blocker still to finish becomes blocked; a task already running
+// keeps running. Adding a dependency that exists is a no-op, and one that
+// would close a cycle fails with ErrDependencyCycle
+func (s *Store) AddDependency(taskID, blockerID string) error {
+	if taskID == blockerID {
+		return fmt.Errorf("task %s cannot depend on itself", taskID)
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, id := range []string{taskID, blockerID} {
+		if err := requireTask(tx, id); err != nil {
+			return err
+		}
+	}
+
+	// Refuse to close a loop: blockerID must not already wait for taskID
+	path, err := dependencyPath(tx, blockerID, taskID)
+	if err != nil {
+		return err
+	}
+	if path != nil {
+		return fmt.Errorf("%w: %s would wait for itself (%s)",
+			ErrDependencyCycle, taskID, strings.Join(append([]string{taskID}, path...), " → "))
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO task_dependencies (task_id, blocked_by)
+		VALUES (?, ?)
+	`, taskID, blockerID); err != nil {
+		return fmt.Errorf("adding dependency: %w", err)
+	}
+	if err := s.refreshBlocked(tx, taskID, "waiting for "+blockerID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// RemoveDependency stops taskID waiting for blockerID. A blocked task left
+// with no unfinished blockers becomes ready
+func (s *Store) RemoveDependency(taskID, blockerID string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		DELETE FROM task_dependencies
+		WHERE task_id = ? AND blocked_by = ?
+	`, taskID, blockerID)
+	if err != nil {
+		return fmt.Errorf("removing dependency: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("task %s does not depend on %s", taskID, blockerID)
+	}
+	if err := s.refreshBlocked(tx, taskID, "no longer waiting for "+blockerID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// refreshBlocked moves a task between ready and blocked to match whether any
+// of its blockers has yet to complete. Tasks in other statuses are left alone
+func (s *Store) refreshBlocked(tx *sql.Tx, taskID, note string) error {
+	var status types.TaskStatus
+	var remaining int
+	err := tx.QueryRow(`
+		SELECT t.status, (
+			SELECT COUNT(*)
+			FROM task_dependencies td
+			JOIN tasks b ON td.blocked_by = b.id
+			WHERE td.task_id = t.id AND b.status != 'completed'
+		)
+		FROM tasks t
+		WHERE t.id = ?
+	`, taskID).Scan(&status, &remaining)
+	if err != nil {
+		return fmt.Errorf("checking blockers of %s: %w", taskID, err)
+	}
+
+	var to types.TaskStatus
+	switch {
+	case status == types.TaskStatusReady && remaining > 0:
+		to = types.TaskStatusBlocked
+	case status == types.TaskStatusBlocked && remaining == 0:
+		to = types.TaskStatusReady
+	default:
+		return nil
+	}
+
+	if err := recordStatusChange(tx, taskID, to, s.actingAs(""), note, status); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE tasks SET status = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, to, time.Now().Unix(), taskID, status); err != nil {
+		return fmt.Errorf("updating task status: %w", err)
+	}
+	return nil
+}
+
+// requireTask returns a "task not found" error unless the task exists
+func requireTask(tx *sql.Tx, taskID string) error {
+	var exists bool
+	err := tx.QueryRow(`SELECT COUNT(*) > 0 FROM tasks WHERE id = ?`, taskID).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("looking up task %s: %w", taskID, err)
+	}
+	if !exists {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
+}
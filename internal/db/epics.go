@@ -0,0 +1,202 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// epicSubtree selects the ID of the epic bound to its single parameter and of
+// every epic nested under it
+const epicSubtree = `
+	WITH RECURSIVE subtree(id) AS (
+		SELECT ?
+		UNION
+		SELECT e.id FROM epics e JOIN subtree ON e.parent_epic_id = subtree.id
+	)
+	SELECT id FROM subtree`
+
+// epicTree pairs every epic (root) with itself and every epic nested under
+// it (id), for rolling task counts up to each level
+const epicTree = `
+	WITH RECURSIVE tree(root, id) AS (
+		SELECT id, id FROM epics
+		UNION
+		SELECT tree.root, e.id FROM epics e JOIN tree ON e.parent_epic_id = tree.id
+	)`
+
+// rollupEpics closes open epics once every task in them, sub-epics included,
+// is done (completed, or cancelled alongside at least one completed task),
+// and reopens closed epics that have unfinished tasks again, e.g. after a
+// reset or a new task. Run it in the transaction that changed the tasks
+func rollupEpics(db execer) error {
+	_, err := db.Exec(epicTree + `
+		UPDATE epics SET status = 'closed'
+		WHERE status = 'open' AND id IN (
+			SELECT tree.root FROM tree JOIN tasks t ON t.epic_id = tree.id
+			GROUP BY tree.root
+			HAVING SUM(t.status NOT IN ('completed', 'cancelled')) = 0 AND SUM(t.status = 'completed') > 0
+		)`)
+	if err != nil {
+		return fmt.Errorf("closing finished epics: %w", err)
+	}
+	_, err = db.Exec(epicTree + `
+		UPDATE epics SET status = 'open'
+		WHERE status = 'closed' AND id IN (
+			SELECT tree.root FROM tree JOIN tasks t ON t.epic_id = tree.id
+			WHERE t.status NOT IN ('completed', 'cancelled')
+		)`)
+	if err != nil {
+		return fmt.Errorf("reopening epics: %w", err)
+	}
+	return nil
+}
+
+// EpicProgress summarizes an epic's tasks together with those of its sub-epics
+type EpicProgress struct {
+	Epic      *types.Epic
+	Status    string // Rolled-up status, see rollupStatus
+	Total     int
+	Ready     int
+	Active    int // Claimed or in progress
+	Blocked   int // Blocked or paused
+	Completed int
+	Failed    int
+	Usage     Usage // Tokens and cost of the tasks
+	Children  []*EpicProgress
+}
+
+// Progress is the percentage of the epic's tasks, sub-epics included, that completed
+func (p *EpicProgress) Progress() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return float64(p.Completed) / float64(p.Total) * 100
+}
+
+// add accumulates another epic's counts into p
+func (p *EpicProgress) add(o *EpicProgress) {
+	p.Total += o.Total
+	p.Ready += o.Ready
+	p.Active += o.Active
+	p.Blocked += o.Blocked
+	p.Completed += o.Completed
+	p.Failed += o.Failed
+	p.Usage.Add(o.Usage)
+}
+
+// rollupStatus derives an epic's status from its rolled-up counts:
+// "completed" once every task completed, "closed" for other closed epics,
+// e.g. ones finished with cancelled tasks, "empty" with no tasks, "failed"
+// when failures are all that is left, "in_progress" once any work started
+// and "open" otherwise
+func (p *EpicProgress) rollupStatus() string {
+	switch {
+	case p.Total > 0 && p.Completed == p.Total:
+		return "completed"
+	case p.Epic.Status == types.EpicStatusClosed:
+		return string(types.EpicStatusClosed)
+	case p.Total == 0:
+		return "empty"
+	case p.Failed > 0 && p.Completed+p.Failed == p.Total:
+		return "failed"
+	case p.Active > 0 || p.Completed > 0 || p.Failed > 0:
+		return "in_progress"
+	}
+	return string(types.EpicStatusOpen)
+}
+
+// GetEpicProgress returns the top-level epics with their sub-epics nested
+// under them. Each epic's counts include the tasks of all its sub-epics
+func (s *Store) GetEpicProgress() ([]*EpicProgress, error) {
+	epics, err := s.ListEpics()
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*EpicProgress, len(epics))
+	for _, epic := range epics {
+		byID[epic.ID] = &EpicProgress{Epic: epic}
+	}
+
+	// Count each epic's own tasks
+	rows, err := s.DB.Query(`
+		SELECT epic_id, status, COUNT(*),
+		       SUM(input_tokens), SUM(output_tokens), SUM(cost_usd)
+		FROM tasks
+		WHERE COALESCE(epic_id, '') != ''
+		GROUP BY epic_id, status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying epic tasks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var epicID, taskStatus string
+		var count int
+		var usage Usage
+		if err := rows.Scan(&epicID, &taskStatus, &count, &usage.InputTokens, &usage.OutputTokens, &usage.CostUSD); err != nil {
+			return nil, fmt.Errorf("scanning epic tasks: %w", err)
+		}
+		p, ok := byID[epicID]
+		if !ok {
+			continue
+		}
+		p.Total += count
+		p.Usage.Add(usage)
+		switch types.TaskStatus(taskStatus) {
+		case types.TaskStatusReady:
+			p.Ready += count
+		case types.TaskStatusClaimed, types.TaskStatusInProgress:
+			p.Active += count
+		case types.TaskStatusBlocked, types.TaskStatusPaused:
+			p.Blocked += count
+		case types.TaskStatusCompleted:
+			p.Completed += count
+		case types.TaskStatusFailed:
+			p.Failed += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading epic tasks: %w", err)
+	}
+
+	var roots []*EpicProgress
+	for _, epic := range epics {
+		p := byID[epic.ID]
+		if parent, ok := byID[epic.ParentEpicID]; ok && epic.ParentEpicID != epic.ID {
+			parent.Children = append(parent.Children, p)
+		} else {
+			roots = append(roots, p)
+		}
+	}
+
+	// Roll counts up from the leaves; visited guards against parent cycles,
+	// which leave their epics unreachable from any root
+	visited := make(map[string]bool, len(epics))
+	var rollup func(p *EpicProgress)
+	rollup = func(p *EpicProgress) {
+		visited[p.Epic.ID] = true
+		for _, child := range p.Children {
+			if visited[child.Epic.ID] {
+				continue
+			}
+			rollup(child)
+			p.add(child)
+		}
+		p.Status = p.rollupStatus()
+	}
+	for _, root := range roots {
+		rollup(root)
+	}
+	for _, epic := range epics {
+		if p := byID[epic.ID]; !visited[epic.ID] {
+			p.Children = nil
+			rollup(p)
+			roots = append(roots, p)
+		}
+	}
+
+	return roots, nil
+}
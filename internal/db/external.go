@@ -0,0 +1,66 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// SetTaskExternalRef records the issue a task was imported from, e.g.
+// "jira:PROJ-123", so re-imports skip it and status changes can be pushed back
+func (s *Store) SetTaskExternalRef(taskID, ref string) error {
+	result, err := s.DB.Exec(`
+		UPDATE tasks
+		SET external_ref = NULLIF(?, ''), updated_at = ?
+		WHERE id = ?
+	`, ref, time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("setting external ref: %w", err)
+	}
+	if rowsAffected(result) == 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
+}
+
+// FindTaskByExternalRef returns the task imported from the given issue, or
+// nil if there is none
+func (s *Store) FindTaskByExternalRef(ref string) (*types.Task, error) {
+	var taskID string
+	err := s.DB.QueryRow(`SELECT id FROM tasks WHERE external_ref = ? LIMIT 1`, ref).Scan(&taskID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("finding task for %s: %w", ref, err)
+	}
+	return s.GetTask(taskID)
+}
+
+// SetEpicExternalRef records the issue an epic was imported from
+func (s *Store) SetEpicExternalRef(epicID, ref string) error {
+	result, err := s.DB.Exec(`UPDATE epics SET external_ref = NULLIF(?, '') WHERE id = ?`, ref, epicID)
+	if err != nil {
+		return fmt.Errorf("setting external ref: %w", err)
+	}
+	if rowsAffected(result) == 0 {
+		return fmt.Errorf("epic not found: %s", epicID)
+	}
+	return nil
+}
+
+// FindEpicByExternalRef returns the ID of the epic imported from the given
+// issue, or "" if there is none
+func (s *Store) FindEpicByExternalRef(ref string) (string, error) {
+	var epicID string
+	err := s.DB.QueryRow(`SELECT id FROM epics WHERE external_ref = ? LIMIT 1`, ref).Scan(&epicID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("finding epic for %s: %w", ref, err)
+	}
+	return epicID, nil
+}
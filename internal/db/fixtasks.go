@@ -0,0 +1,138 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// QueueFixTask queues a fix task, of type fix, for a blocker that failed
+// blockedID and makes blockedID wait for it. The fix task goes in the blocked
+// task's epic and repository. A fix task still open under the same title is
+// reused instead, so tasks failing on the same blocker wait for one fix; it is
+// raised to priority if it was lower. The blocked task's failed attempt is
+// counted, so a blocker that keeps coming back still runs it out of attempts.
+// Returns the fix task and whether it was created
+func (s *Store) QueueFixTask(blockedID, title, description string, priority int) (*types.Task, bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var epicID, repo sql.NullString
+	err = tx.QueryRow(`SELECT epic_id, repo FROM tasks WHERE id = ?`, blockedID).Scan(&epicID, &repo)
+	if err == sql.ErrNoRows {
+		return nil, false, fmt.Errorf("task not found: %s", blockedID)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("fetching task %s: %w", blockedID, err)
+	}
+
+	now := time.Now().Unix()
+	var fixID string
+	err = tx.QueryRow(`
+		SELECT id FROM tasks
+		WHERE type = ? AND title = ? AND COALESCE(repo, '') = ?
+		  AND status NOT IN ('completed', 'failed', 'cancelled')
+		ORDER BY created_at ASC
+		LIMIT 1
+	`, types.TaskTypeFix, title, repo.String).Scan(&fixID)
+	created := err == sql.ErrNoRows
+	switch {
+	case created:
+		fixID, err = s.insertWithID("tasks", "task", func(id string) error {
+			_, err := tx.Exec(`
+				INSERT INTO tasks (id, title, description, epic_id, type, priority, status, repo, created_at, updated_at)
+				VALUES (?, ?, ?, ?, ?, ?, 'ready', ?, ?, ?)
+			`, id, title, description, epicID, types.TaskTypeFix, priority, repo, now, now)
+			return err
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("creating fix task: %w", err)
+		}
+	case err != nil:
+		return nil, false, fmt.Errorf("finding fix task: %w", err)
+	default:
+		if _, err := tx.Exec(`
+			UPDATE tasks SET priority = MAX(priority, ?), updated_at = ?
+			WHERE id = ?
+		`, priority, now, fixID); err != nil {
+			return nil, false, fmt.Errorf("raising fix task priority: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO task_dependencies (task_id, blocked_by)
+		VALUES (?, ?)
+	`, blockedID, fixID); err != nil {
+		return nil, false, fmt.Errorf("adding dependency: %w", err)
+	}
+
+	note := "waiting for fix task " + fixID
+	if err := recordStatusChange(tx, blockedID, types.TaskStatusBlocked, s.actingAs(""), note); err != nil {
+		return nil, false, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE tasks
+		SET status = 'blocked', attempts = attempts + 1, last_error = ?,
+		    claimed_by = NULL, claimed_at = NULL, updated_at = ?
+		WHERE id = ?
+	`, note, now, blockedID); err != nil {
+		return nil, false, fmt.Errorf("blocking task: %w", err)
+	}
+
+	if err := rollupEpics(tx); err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("committing transaction: %w", err)
+	}
+
+	fix, err := s.GetTask(fixID)
+	if err != nil {
+		return nil, false, err
+	}
+	return fix, created, nil
+}
+
+// escalatedPriority is the priority a task is claimed by unless it was moved
+// in the queue. A fix task outranks the unfinished tasks waiting on it: it
+// takes the highest of their claim priorities plus one per waiting task, so
+// it runs ahead of the tasks it unblocks and fixes blocking more tasks run
+// first. It never drops below the fix task's own priority. Used in queries
+// over the tasks table, unaliased
+const escalatedPriority = `CASE WHEN type = 'fix' THEN MAX(priority, COALESCE((
+		SELECT MAX(COALESCE(w.effective_priority, w.priority)) + COUNT(*)
+		FROM task_dependencies d
+		JOIN tasks w ON w.id = d.task_id
+		WHERE d.blocked_by = tasks.id AND w.status NOT IN ('completed', 'failed', 'cancelled')
+	), priority)) ELSE priority END`
+
+// EscalatedPriorities maps the queued fix tasks that outrank their own
+// priority, because tasks wait on them, to the priority they are claimed by
+func (s *Store) EscalatedPriorities() (map[string]int, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, priority, `+escalatedPriority+` FROM tasks
+		WHERE type = ? AND effective_priority IS NULL AND `+queuedTasks+`
+	`, types.TaskTypeFix)
+	if err != nil {
+		return nil, fmt.Errorf("listing fix task priorities: %w", err)
+	}
+	defer rows.Close()
+
+	escalated := make(map[string]int)
+	for rows.Next() {
+		var id string
+		var own, priority int
+		if err := rows.Scan(&id, &own, &priority); err != nil {
+			return nil, fmt.Errorf("scanning fix task priority: %w", err)
+		}
+		if priority > own {
+			escalated[id] = priority
+		}
+	}
+	return escalated, rows.Err()
+}
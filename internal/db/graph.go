@@ -0,0 +1,255 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// ErrDependencyCycle means a dependency would make tasks wait for each other
+// forever
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// dependencyPath returns the chain of dependencies by which from waits for
+// to, e.g. [from, x, to], or nil if it doesn't
+func dependencyPath(tx *sql.Tx, from, to string) ([]string, error) {
+	// Breadth-first over blockers, remembering who led to each task
+	via := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+
+		rows, err := tx.Query(`SELECT blocked_by FROM task_dependencies WHERE task_id = ?`, id)
+		if err != nil {
+			return nil, fmt.Errorf("checking for dependency cycles: %w", err)
+		}
+		var next []string
+		for rows.Next() {
+			var b string
+			if err := rows.Scan(&b); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("checking for dependency cycles: %w", err)
+			}
+			next = append(next, b)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("checking for dependency cycles: %w", err)
+		}
+
+		for _, b := range next {
+			if _, seen := via[b]; seen {
+				continue
+			}
+			via[b] = id
+			if b == to {
+				path := []string{to}
+				for at := id; at != ""; at = via[at] {
+					path = append([]string{at}, path...)
+				}
+				return path, nil
+			}
+			queue = append(queue, b)
+		}
+	}
+	return nil, nil
+}
+
+// StuckTask is an unfinished task that can never become ready
+type StuckTask struct {
+	TaskID string
+	Reason string // e.g. "waits for task-b, which failed"
+}
+
+// GraphReport lists what in the dependency graph would deadlock a run
+type GraphReport struct {
+	Cycles [][]string  // Each cycle's tasks, each waiting for the next and the last for the first
+	Stuck  []StuckTask // Unfinished tasks that can never become ready, cycles included
+}
+
+// OK reports whether every unfinished task can eventually run
+func (r *GraphReport) OK() bool {
+	return len(r.Cycles) == 0 && len(r.Stuck) == 0
+}
+
+// ValidateGraph checks the dependencies of unfinished tasks for cycles, and
+// for tasks that wait, directly or through other tasks, for a task that
+// failed, was cancelled or is on a cycle
+func (s *Store) ValidateGraph() (*GraphReport, error) {
+	rows, err := s.DB.Query(`SELECT id, status FROM tasks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("querying tasks: %w", err)
+	}
+	status := make(map[string]types.TaskStatus)
+	var ids []string
+	for rows.Next() {
+		var id string
+		var st types.TaskStatus
+		if err := rows.Scan(&id, &st); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning task: %w", err)
+		}
+		status[id] = st
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading tasks: %w", err)
+	}
+
+	deps, err := s.ListAllDependencies()
+	if err != nil {
+		return nil, err
+	}
+	blockers := make(map[string][]string)
+	for _, d := range deps {
+		blockers[d.TaskID] = append(blockers[d.TaskID], d.BlockedBy)
+	}
+
+	unfinished := func(id string) bool {
+		st, ok := status[id]
+		return ok && st != types.TaskStatusCompleted && st != types.TaskStatusFailed && st != types.TaskStatusCancelled
+	}
+
+	report := &GraphReport{}
+	onCycle := make(map[string]bool)
+	for _, cycle := range findCycles(ids, blockers, unfinished) {
+		report.Cycles = append(report.Cycles, cycle)
+		for _, id := range cycle {
+			onCycle[id] = true
+		}
+	}
+
+	// Walk tasks until no more turn out stuck; each pass can only add tasks
+	stuck := make(map[string]string)
+	for changed := true; changed; {
+		changed = false
+		for _, id := range ids {
+			if !unfinished(id) || stuck[id] != "" {
+				continue
+			}
+			reason := ""
+			if onCycle[id] {
+				reason = "on a dependency cycle"
+			}
+			for _, b := range blockers[id] {
+				if reason != "" {
+					break
+				}
+				switch {
+				case status[b] == types.TaskStatusFailed:
+					reason = fmt.Sprintf("waits for %s, which failed", b)
+				case status[b] == types.TaskStatusCancelled:
+					reason = fmt.Sprintf("waits for %s, which was cancelled", b)
+				case stuck[b] != "":
+					reason = fmt.Sprintf("waits for %s, which can never run", b)
+				}
+			}
+			if reason != "" {
+				stuck[id] = reason
+				changed = true
+			}
+		}
+	}
+	for _, id := range ids {
+		if reason := stuck[id]; reason != "" {
+			report.Stuck = append(report.Stuck, StuckTask{TaskID: id, Reason: reason})
+		}
+	}
+	return report, nil
+}
+
+// findCycles returns one cycle through each group of tasks that wait for each
+// other (strongly connected component), considering only tasks keep allows
+func findCycles(ids []string, blockers map[string][]string, keep func(string) bool) [][]string {
+	// Tarjan's algorithm
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var groups [][]string
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = len(index)
+		low[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, b := range blockers[id] {
+			if !keep(b) {
+				continue
+			}
+			if _, seen := index[b]; !seen {
+				visit(b)
+				low[id] = min(low[id], low[b])
+			} else if onStack[b] {
+				low[id] = min(low[id], index[b])
+			}
+		}
+
+		if low[id] == index[id] {
+			var group []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				group = append(group, top)
+				if top == id {
+					break
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+	for _, id := range ids {
+		if _, seen := index[id]; !seen && keep(id) {
+			visit(id)
+		}
+	}
+
+	var cycles [][]string
+	for _, group := range groups {
+		if len(group) == 1 && !slices.Contains(blockers[group[0]], group[0]) {
+			continue
+		}
+		cycles = append(cycles, cycleWithin(group, blockers))
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// cycleWithin follows dependencies inside a strongly connected group, from its
+// smallest ID, until it comes back around, returning the loop it found
+func cycleWithin(group []string, blockers map[string][]string) []string {
+	members := make(map[string]bool, len(group))
+	start := group[0]
+	for _, id := range group {
+		members[id] = true
+		if id < start {
+			start = id
+		}
+	}
+
+	pos := make(map[string]int)
+	var path []string
+	for id := start; ; {
+		if i, seen := pos[id]; seen {
+			return path[i:]
+		}
+		pos[id] = len(path)
+		path = append(path, id)
+		next := ""
+		for _, b := range blockers[id] {
+			if members[b] && (next == "" || b < next) {
+				next = b
+			}
+		}
+		id = next
+	}
+}
@@ -0,0 +1,142 @@
+package db
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IDFormat selects how new task and epic IDs are generated
+type IDFormat string
+
+const (
+	// IDFormatTimestamp is the prefix and the creation time in nanoseconds,
+	// e.g. task-1718000000000000000. The default
+	IDFormatTimestamp IDFormat = "timestamp"
+	// IDFormatULID is the prefix and a ULID, which sorts by creation time,
+	// e.g. task-01J0ABCDEFGHJKMNPQRSTVWXYZ
+	IDFormatULID IDFormat = "ulid"
+	// IDFormatUUID is the prefix and a time-ordered UUIDv7
+	IDFormatUUID IDFormat = "uuid"
+	// IDFormatShort is the prefix and six random characters, e.g. task-k3m9qz,
+	// easy to read out and type; collisions are retried
+	IDFormatShort IDFormat = "short"
+)
+
+// maxIDAttempts is how many IDs are drawn for a row before giving up on
+// finding one that isn't taken
+const maxIDAttempts = 5
+
+// crockford is Crockford's base32 alphabet, without the easily confused I, L,
+// O and U
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ParseIDFormat validates an id_format setting; empty means the default
+func ParseIDFormat(s string) (IDFormat, error) {
+	switch f := IDFormat(strings.ToLower(strings.TrimSpace(s))); f {
+	case "":
+		return IDFormatTimestamp, nil
+	case IDFormatTimestamp, IDFormatULID, IDFormatUUID, IDFormatShort:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown ID format %q (want timestamp, ulid, uuid or short)", s)
+}
+
+// SetIDFormat sets how the IDs of tasks and epics created from now on are
+// generated. IDs that already exist are kept
+func (s *Store) SetIDFormat(format IDFormat) {
+	s.idFormat = format
+}
+
+// newID returns a new ID in the store's format
+func (s *Store) newID(prefix string) string {
+	switch s.idFormat {
+	case IDFormatULID:
+		return prefix + "-" + newULID(time.Now())
+	case IDFormatUUID:
+		id, err := uuid.NewV7()
+		if err != nil {
+			id = uuid.New()
+		}
+		return prefix + "-" + id.String()
+	case IDFormatShort:
+		return prefix + "-" + strings.ToLower(randomBase32(6))
+	}
+	return generateID(prefix)
+}
+
+// insertWithID runs insert with a new ID for a row of table, drawing another
+// ID while the one it got is already taken, and returns the ID it used
+func (s *Store) insertWithID(table, prefix string, insert func(id string) error) (string, error) {
+	for attempt := 1; ; attempt++ {
+		id := s.newID(prefix)
+		err := insert(id)
+		if err == nil || !idTaken(err, table) || attempt == maxIDAttempts {
+			return id, err
+		}
+	}
+}
+
+// idTaken reports whether err is an insert into table failing on an ID
+// that's already taken
+func idTaken(err error, table string) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed: "+table+".id")
+}
+
+// lastIDNanos is the timestamp of the last generateID, so IDs generated in
+// the same nanosecond, e.g. by parallel inserts, still differ
+var lastIDNanos atomic.Int64
+
+// generateID returns the prefix and a timestamp in nanoseconds that's unique
+// within the process
+func generateID(prefix string) string {
+	for {
+		last := lastIDNanos.Load()
+		now := max(time.Now().UnixNano(), last+1)
+		if lastIDNanos.CompareAndSwap(last, now) {
+			return fmt.Sprintf("%s-%d", prefix, now)
+		}
+	}
+}
+
+// newULID returns a ULID: 48 bits of milliseconds and 80 random bits, as 26
+// characters of Crockford base32
+func newULID(now time.Time) string {
+	var b [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(now.UnixMilli()))
+	copy(b[:6], ms[2:])
+	_, _ = rand.Read(b[6:])
+
+	// 130 bits of characters for 128 of data: the first character holds 3
+	bit := func(i int) byte {
+		if i < 0 {
+			return 0
+		}
+		return (b[i/8] >> (7 - i%8)) & 1
+	}
+	out := make([]byte, 26)
+	for c := range out {
+		var v byte
+		for i := c*5 - 2; i < c*5+3; i++ {
+			v = v<<1 | bit(i)
+		}
+		out[c] = crockford[v]
+	}
+	return string(out)
+}
+
+// randomBase32 returns n random characters of Crockford base32
+func randomBase32(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	for i := range buf {
+		buf[i] = crockford[buf[i]%32]
+	}
+	return string(buf)
+}
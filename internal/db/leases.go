@@ -0,0 +1,169 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// leaseReaper is who reaped claims are attributed to in the audit log
+const leaseReaper = "lease-reaper"
+
+// ErrLeaseLost means a claim could not be renewed: it expired and was
+// reaped, or the task is no longer claimed by the worker renewing it
+var ErrLeaseLost = errors.New("lease lost")
+
+// ReapedTask is a claim ReapExpiredLeases took back from a worker
+type ReapedTask struct {
+	TaskID    string
+	ClaimedBy string           // Worker whose lease expired
+	Status    types.TaskStatus // Ready to retry, failed once out of attempts, or cancelled
+	Attempts  int              // Attempts including the abandoned one
+}
+
+// SetLeaseTTL makes claims taken through this store expire unless renewed
+// within ttl, so ReapExpiredLeases can return the tasks of workers that died.
+// Zero, the default, makes claims last until the task leaves claimed or
+// in_progress
+func (s *Store) SetLeaseTTL(ttl time.Duration) {
+	s.leaseTTL = ttl
+}
+
+// LeaseTTL returns how long claims last without renewal; zero when they
+// don't expire
+func (s *Store) LeaseTTL() time.Duration {
+	return s.leaseTTL
+}
+
+// leaseExpiry returns the lease_expires_at of a claim taken at now
+func (s *Store) leaseExpiry(now int64) any {
+	if s.leaseTTL <= 0 {
+		return nil
+	}
+	return now + int64(s.leaseTTL.Seconds())
+}
+
+// RenewLease extends a worker's claim on a task by the lease TTL
+func (s *Store) RenewLease(taskID, workerID string) error {
+	if s.leaseTTL <= 0 {
+		return nil
+	}
+	now := time.Now().Unix()
+	res, err := s.DB.Exec(`
+		UPDATE tasks SET lease_expires_at = ?
+		WHERE id = ? AND claimed_by = ? AND status IN ('claimed', 'in_progress')
+	`, s.leaseExpiry(now), taskID, workerID)
+	if err != nil {
+		return fmt.Errorf("renewing lease: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("%w on task %s", ErrLeaseLost, taskID)
+	}
+	return nil
+}
+
+// CompleteClaimedTask completes a task like CompleteTask, as long as workerID
+// still holds its claim; ErrLeaseLost when it doesn't, so a worker whose
+// task went to another can't finish it. An empty workerID skips the check
+func (s *Store) CompleteClaimedTask(taskID, workerID string) error {
+	return s.completeTask(taskID, workerID)
+}
+
+// FailClaimedTask marks a task failed, as long as workerID still holds its
+// claim; ErrLeaseLost when it doesn't. An empty workerID skips the check
+func (s *Store) FailClaimedTask(taskID, workerID, lastError string) error {
+	return s.updateTaskStatus(taskID, workerID, types.TaskStatusFailed, lastError)
+}
+
+// RequeueClaimedTask requeues a task like RequeueTask, as long as workerID
+// still holds its claim; ErrLeaseLost when it doesn't. An empty workerID
+// skips the check
+func (s *Store) RequeueClaimedTask(taskID, workerID, lastError string, notBefore time.Time) error {
+	return s.requeueTask(taskID, workerID, lastError, notBefore)
+}
+
+// ReapExpiredLeases returns claimed and in-progress tasks whose lease expired
+// to the queue. The abandoned run counts as an attempt, so a task that keeps
+// killing its worker fails once it runs out of attempts instead of cycling
+// forever. A task whose cancellation was requested is cancelled instead
+func (s *Store) ReapExpiredLeases() ([]ReapedTask, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	rows, err := tx.Query(`
+		SELECT id, COALESCE(claimed_by, ''), status, attempts, max_attempts,
+		       cancel_requested_at IS NOT NULL, COALESCE(last_error, '')
+		FROM tasks
+		WHERE status IN ('claimed', 'in_progress') AND lease_expires_at < ?
+	`, now)
+	if err != nil {
+		return nil, fmt.Errorf("finding expired leases: %w", err)
+	}
+	type expired struct {
+		ReapedTask
+		from        types.TaskStatus
+		maxAttempts int
+		cancel      bool   // Cancellation was requested; the worker died before finishing it
+		lastError   string // The cancellation reason, kept when cancelling
+	}
+	var found []expired
+	for rows.Next() {
+		var e expired
+		if err := rows.Scan(&e.TaskID, &e.ClaimedBy, &e.from, &e.Attempts, &e.maxAttempts, &e.cancel, &e.lastError); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scanning expired lease: %w", err)
+		}
+		found = append(found, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading expired leases: %w", err)
+	}
+
+	var reaped []ReapedTask
+	for _, e := range found {
+		e.Attempts++
+		e.Status = types.TaskStatusReady
+		note := fmt.Sprintf("lease of %s expired (attempt %d/%d)", e.ClaimedBy, e.Attempts, e.maxAttempts)
+		lastError := note
+		switch {
+		case e.cancel:
+			e.Status = types.TaskStatusCancelled
+			note = fmt.Sprintf("lease of %s expired with cancellation requested", e.ClaimedBy)
+			lastError = e.lastError
+		case e.Attempts >= e.maxAttempts:
+			e.Status = types.TaskStatusFailed
+			note = fmt.Sprintf("lease of %s expired, out of attempts (%d/%d)", e.ClaimedBy, e.Attempts, e.maxAttempts)
+			lastError = note
+		}
+
+		// The lease condition again, in case the worker renewed it meanwhile
+		res, err := tx.Exec(`
+			UPDATE tasks
+			SET status = ?, attempts = ?, last_error = ?, claimed_by = NULL, claimed_at = NULL,
+			    lease_expires_at = NULL, cancel_requested_at = NULL, updated_at = ?
+			WHERE id = ? AND status = ? AND lease_expires_at < ?
+		`, e.Status, e.Attempts, lastError, now, e.TaskID, e.from, now)
+		if err != nil {
+			return nil, fmt.Errorf("reaping %s: %w", e.TaskID, err)
+		}
+		if rowsAffected(res) == 0 {
+			continue
+		}
+		if err := recordTransition(tx, e.TaskID, e.from, e.Status, leaseReaper, note); err != nil {
+			return nil, err
+		}
+		reaped = append(reaped, e.ReapedTask)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("committing reaped leases: %w", err)
+	}
+	return reaped, nil
+}
@@ -0,0 +1,303 @@
+package db
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema changes are versioned migrations: numbered SQL files under
+// migrations/, applied in order and recorded in the schema_version table.
+// Version 1 is the baseline, the schema InitSchema and MigrateSchema built
+// before migrations existed; those two are frozen, and every later change is
+// a new migrations/NNNN_name.up.sql with a matching .down.sql.
+
+//go:embed migrations
+var embeddedMigrations embed.FS
+
+// migrationSource holds the migration files; tests swap it for their own
+var migrationSource fs.FS = mustSub(embeddedMigrations, "migrations")
+
+// BaselineVersion is the schema version of the legacy bootstrap
+const BaselineVersion = 1
+
+// ErrSchemaTooNew means the database was migrated by a newer drover
+var ErrSchemaTooNew = errors.New("database schema is newer than this drover supports")
+
+const schemaVersionTable = `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at INTEGER NOT NULL
+	);
+`
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration is one step of the schema history
+type Migration struct {
+	Version    int
+	Name       string
+	Reversible bool       // Has a down migration
+	Applied    bool       // Recorded in schema_version
+	AppliedAt  *time.Time // When it was applied, if it was
+	Unknown    bool       // Applied by a newer drover; this binary lacks it
+
+	up   string
+	down string
+}
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// loadMigrations returns the baseline and the migration files, by version
+func loadMigrations() ([]*Migration, error) {
+	byVersion := map[int]*Migration{
+		BaselineVersion: {Version: BaselineVersion, Name: "baseline"},
+	}
+	entries, err := fs.ReadDir(migrationSource, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading migrations: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0002_add_column.up.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version <= BaselineVersion {
+			return nil, fmt.Errorf("migration %s: versions up to %d are reserved for the baseline", entry.Name(), BaselineVersion)
+		}
+		content, err := fs.ReadFile(migrationSource, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("reading migration %s: %w", entry.Name(), err)
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+			m.Reversible = true
+		}
+	}
+
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+		if m.Version != BaselineVersion && m.up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no .up.sql", m.Version, m.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// LatestSchemaVersion returns the newest schema version this drover knows
+func LatestSchemaVersion() (int, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return 0, err
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+// SchemaVersion returns the version the database is migrated to; 0 for a
+// database no migration has run against yet
+func (s *Store) SchemaVersion() (int, error) {
+	if _, err := s.DB.Exec(schemaVersionTable); err != nil {
+		return 0, fmt.Errorf("creating schema_version table: %w", err)
+	}
+	var version int
+	if err := s.DB.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("reading schema version: %w", err)
+	}
+	return version, nil
+}
+
+// Migrations lists every migration this drover knows, plus any a newer one
+// applied, with whether each is applied
+func (s *Store) Migrations() ([]*Migration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.DB.Exec(schemaVersionTable); err != nil {
+		return nil, fmt.Errorf("creating schema_version table: %w", err)
+	}
+	rows, err := s.DB.Query(`SELECT version, name, applied_at FROM schema_version ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema versions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			version   int
+			name      string
+			appliedAt int64
+		)
+		if err := rows.Scan(&version, &name, &appliedAt); err != nil {
+			return nil, fmt.Errorf("scanning schema version: %w", err)
+		}
+		at := time.Unix(appliedAt, 0)
+		if version <= len(migrations) {
+			migrations[version-1].Applied = true
+			migrations[version-1].AppliedAt = &at
+			continue
+		}
+		migrations = append(migrations, &Migration{Version: version, Name: name, Applied: true, AppliedAt: &at, Unknown: true})
+	}
+	return migrations, rows.Err()
+}
+
+// Migrate applies every pending migration. Open calls it, so a database is
+// always at the latest version once opened
+func (s *Store) Migrate() error {
+	latest, err := LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+	return s.MigrateTo(latest)
+}
+
+// MigrateTo migrates the database up or down to target. Migrating down runs
+// each newer migration's .down.sql, newest first; the baseline can't be
+// undone
+func (s *Store) MigrateTo(target int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	latest := migrations[len(migrations)-1].Version
+	if target < BaselineVersion || target > latest {
+		return fmt.Errorf("schema version %d out of range: %d to %d", target, BaselineVersion, latest)
+	}
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if current > latest {
+		return fmt.Errorf("%w: at version %d, this drover knows up to %d; upgrade drover", ErrSchemaTooNew, current, latest)
+	}
+
+	for i := current; i < target; i++ {
+		if err := s.applyMigration(migrations[i]); err != nil {
+			return err
+		}
+	}
+	for i := current; i > target; i-- {
+		if err := s.revertMigration(migrations[i-1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it. The version row is
+// written first, so a second process migrating the same database waits on
+// the write lock and then finds the migration already applied
+func (s *Store) applyMigration(m *Migration) error {
+	if m.Version == BaselineVersion {
+		if err := s.applyBaseline(); err != nil {
+			return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+		}
+		_, err := s.DB.Exec(`INSERT OR IGNORE INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+			m.Version, m.Name, time.Now().Unix())
+		return err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`INSERT OR IGNORE INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.Version, m.Name, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("recording migration %d: %w", m.Version, err)
+	}
+	if rowsAffected(res) == 0 {
+		return nil // Applied concurrently
+	}
+	if _, err := tx.Exec(m.up); err != nil {
+		return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, err)
+	}
+	return tx.Commit()
+}
+
+// revertMigration runs one migration's down script and forgets it
+func (s *Store) revertMigration(m *Migration) error {
+	if m.Version == BaselineVersion {
+		return fmt.Errorf("the baseline schema can't be migrated down")
+	}
+	if !m.Reversible {
+		return fmt.Errorf("migration %d_%s has no .down.sql and can't be migrated down", m.Version, m.Name)
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM schema_version WHERE version = ?`, m.Version)
+	if err != nil {
+		return fmt.Errorf("forgetting migration %d: %w", m.Version, err)
+	}
+	if rowsAffected(res) == 0 {
+		return nil // Reverted concurrently
+	}
+	if _, err := tx.Exec(m.down); err != nil {
+		return fmt.Errorf("reverting migration %d_%s: %w", m.Version, m.Name, err)
+	}
+	return tx.Commit()
+}
+
+// applyBaseline brings a database to the schema drover had before versioned
+// migrations. A database from before then only needs the columns it lacks,
+// and InitSchema's indexes fail on those columns, so MigrateSchema runs
+// first; a new database is the other way round
+func (s *Store) applyBaseline() error {
+	var legacy bool
+	err := s.DB.QueryRow(`SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'tasks'`).Scan(&legacy)
+	if err != nil {
+		return fmt.Errorf("checking for an existing schema: %w", err)
+	}
+	if legacy {
+		if err := s.MigrateSchema(); err != nil {
+			return err
+		}
+		return s.InitSchema()
+	}
+	if err := s.InitSchema(); err != nil {
+		return err
+	}
+	return s.MigrateSchema()
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMigrate_UpAndDown(t *testing.T) {
+	defer func(src fs.FS) { migrationSource = src }(migrationSource)
+	migrationSource = fstest.MapFS{
+		"0002_add_task_cost.up.sql":   {Data: []byte(`ALTER TABLE tasks ADD COLUMN cost REAL DEFAULT 0;`)},
+		"0002_add_task_cost.down.sql": {Data: []byte(`ALTER TABLE tasks DROP COLUMN cost;`)},
+		"0003_add_notes.up.sql":       {Data: []byte(`CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT);`)},
+		"0003_add_notes.down.sql":     {Data: []byte(`DROP TABLE notes;`)},
+		"README.md":                   {Data: []byte("ignored")},
+	}
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer store.Close()
+
+	if v, _ := store.SchemaVersion(); v != 3 {
+		t.Fatalf("version after Open = %d, want 3", v)
+	}
+	if _, err := store.DB.Exec(`UPDATE tasks SET cost = 1; INSERT INTO notes (body) VALUES ('x')`); err != nil {
+		t.Fatalf("using migrated schema: %v", err)
+	}
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("second Migrate: %v", err)
+	}
+
+	if err := store.MigrateTo(BaselineVersion); err != nil {
+		t.Fatalf("MigrateTo(1): %v", err)
+	}
+	if v, _ := store.SchemaVersion(); v != 1 {
+		t.Errorf("version after down = %d, want 1", v)
+	}
+	if _, err := store.DB.Exec(`SELECT cost FROM tasks`); err == nil {
+		t.Error("cost column still exists after migrating down")
+	}
+	if err := store.MigrateTo(0); err == nil {
+		t.Error("migrating below the baseline succeeded")
+	}
+
+	migrations, err := store.Migrations()
+	if err != nil {
+		t.Fatalf("Migrations: %v", err)
+	}
+	if len(migrations) != 3 || !migrations[0].Applied || migrations[1].Applied || !migrations[2].Reversible {
+		t.Errorf("migrations = %+v", migrations)
+	}
+}
+
+func TestMigrate_RefusesNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	store, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := store.DB.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (99, 'from_the_future', 0)`); err != nil {
+		t.Fatalf("recording version: %v", err)
+	}
+	store.Close()
+
+	if _, err := Open(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("Open = %v, want ErrSchemaTooNew", err)
+	}
+
+	store, err = OpenUnmigrated(path)
+	if err != nil {
+		t.Fatalf("OpenUnmigrated: %v", err)
+	}
+	defer store.Close()
+	migrations, err := store.Migrations()
+	if err != nil {
+		t.Fatalf("Migrations: %v", err)
+	}
+	if last := migrations[len(migrations)-1]; last.Version != 99 || !last.Unknown {
+		t.Errorf("last migration = %+v, want unknown version 99", last)
+	}
+}
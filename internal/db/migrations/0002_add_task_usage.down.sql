@@ -0,0 +1,7 @@
+ALTER TABLE archived_tasks DROP COLUMN cost_usd;
+ALTER TABLE archived_tasks DROP COLUMN output_tokens;
+ALTER TABLE archived_tasks DROP COLUMN input_tokens;
+
+ALTER TABLE tasks DROP COLUMN cost_usd;
+ALTER TABLE tasks DROP COLUMN output_tokens;
+ALTER TABLE tasks DROP COLUMN input_tokens;
@@ -0,0 +1,8 @@
+-- Tokens and cost agents report per task, summed over attempts
+ALTER TABLE tasks ADD COLUMN input_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN output_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tasks ADD COLUMN cost_usd REAL NOT NULL DEFAULT 0;
+
+ALTER TABLE archived_tasks ADD COLUMN input_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE archived_tasks ADD COLUMN output_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE archived_tasks ADD COLUMN cost_usd REAL NOT NULL DEFAULT 0;
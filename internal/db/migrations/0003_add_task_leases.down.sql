@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_lease;
+ALTER TABLE tasks DROP COLUMN lease_expires_at;
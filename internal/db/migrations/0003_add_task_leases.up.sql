@@ -0,0 +1,3 @@
+-- Claims expire unless the worker holding them renews them
+ALTER TABLE tasks ADD COLUMN lease_expires_at INTEGER;
+CREATE INDEX IF NOT EXISTS idx_tasks_lease ON tasks(status, lease_expires_at);
@@ -0,0 +1,14 @@
+-- One row per execution of a task, so earlier attempts' errors survive retries
+CREATE TABLE task_attempts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id TEXT NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+	number INTEGER NOT NULL,
+	worker_id TEXT NOT NULL,
+	started_at INTEGER NOT NULL,
+	ended_at INTEGER,
+	outcome TEXT,
+	error TEXT,
+	verdict TEXT,
+	output_path TEXT,
+	UNIQUE (task_id, number)
+);
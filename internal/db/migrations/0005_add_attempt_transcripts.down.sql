@@ -0,0 +1,2 @@
+ALTER TABLE task_attempts DROP COLUMN transcript_size;
+ALTER TABLE task_attempts DROP COLUMN transcript;
@@ -0,0 +1,3 @@
+-- The agent's output per attempt, gzipped and capped, with its full size
+ALTER TABLE task_attempts ADD COLUMN transcript BLOB;
+ALTER TABLE task_attempts ADD COLUMN transcript_size INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE archived_tasks DROP COLUMN retry_policy;
+
+ALTER TABLE tasks DROP COLUMN retry_policy;
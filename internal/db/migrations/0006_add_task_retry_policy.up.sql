@@ -0,0 +1,4 @@
+-- Per-task retry policy overriding the global one, e.g. "backoff=1m,on=agent|timeout"
+ALTER TABLE tasks ADD COLUMN retry_policy TEXT;
+
+ALTER TABLE archived_tasks ADD COLUMN retry_policy TEXT;
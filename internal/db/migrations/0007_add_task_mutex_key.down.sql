@@ -0,0 +1,5 @@
+ALTER TABLE archived_tasks DROP COLUMN mutex_key;
+
+DROP INDEX idx_tasks_mutex_key;
+
+ALTER TABLE tasks DROP COLUMN mutex_key;
@@ -0,0 +1,6 @@
+-- Tasks sharing a mutex key never run at the same time, e.g. two that both edit schema.sql
+ALTER TABLE tasks ADD COLUMN mutex_key TEXT;
+
+CREATE INDEX idx_tasks_mutex_key ON tasks(mutex_key) WHERE mutex_key IS NOT NULL;
+
+ALTER TABLE archived_tasks ADD COLUMN mutex_key TEXT;
@@ -0,0 +1 @@
+ALTER TABLE tasks DROP COLUMN cancel_requested_at;
@@ -0,0 +1,2 @@
+-- Set when a task is cancelled while a worker runs it; the worker stops the agent and finishes the cancellation
+ALTER TABLE tasks ADD COLUMN cancel_requested_at INTEGER;
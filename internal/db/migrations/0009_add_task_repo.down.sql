@@ -0,0 +1,3 @@
+ALTER TABLE archived_tasks DROP COLUMN repo;
+
+ALTER TABLE tasks DROP COLUMN repo;
@@ -0,0 +1,4 @@
+-- The repository a task works in, by its name under [repos] in .drover.toml; NULL is the project's own
+ALTER TABLE tasks ADD COLUMN repo TEXT;
+
+ALTER TABLE archived_tasks ADD COLUMN repo TEXT;
@@ -0,0 +1 @@
+DROP TABLE queue_limits;
@@ -0,0 +1,11 @@
+-- Limits set on a live deployment with 'drover queue limits'; running DBOS
+-- orchestrators pick them up in place of the ones they started with. NULL
+-- keeps the started value
+CREATE TABLE queue_limits (
+	id INTEGER PRIMARY KEY CHECK (id = 1),
+	worker_concurrency INTEGER,
+	global_concurrency INTEGER,
+	rate_limit INTEGER,
+	rate_period_ms INTEGER,
+	updated_at INTEGER NOT NULL
+);
@@ -0,0 +1,3 @@
+ALTER TABLE archived_tasks DROP COLUMN env;
+
+ALTER TABLE tasks DROP COLUMN env;
@@ -0,0 +1,4 @@
+-- Environment variables a task's agent runs with, as a JSON object; NULL for none
+ALTER TABLE tasks ADD COLUMN env TEXT;
+
+ALTER TABLE archived_tasks ADD COLUMN env TEXT;
@@ -0,0 +1 @@
+ALTER TABLE task_attempts DROP COLUMN session_id;
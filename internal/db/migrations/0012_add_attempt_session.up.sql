@@ -0,0 +1,2 @@
+-- Claude Code session an attempt ran in, for the next attempt to resume; NULL for none
+ALTER TABLE task_attempts ADD COLUMN session_id TEXT;
@@ -0,0 +1,2 @@
+-- Agent and model that ran an attempt when the task could fall back to others; NULL otherwise
+ALTER TABLE task_attempts ADD COLUMN model TEXT;
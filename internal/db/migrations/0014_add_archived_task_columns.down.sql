@@ -0,0 +1,7 @@
+ALTER TABLE archived_tasks DROP COLUMN external_ref;
+
+ALTER TABLE archived_tasks DROP COLUMN effective_priority;
+
+ALTER TABLE archived_tasks DROP COLUMN due_at;
+
+ALTER TABLE archived_tasks DROP COLUMN scheduled_at;
@@ -0,0 +1,8 @@
+-- Task columns from before migrations that archived_tasks was missing
+ALTER TABLE archived_tasks ADD COLUMN scheduled_at INTEGER;
+
+ALTER TABLE archived_tasks ADD COLUMN due_at INTEGER;
+
+ALTER TABLE archived_tasks ADD COLUMN effective_priority INTEGER;
+
+ALTER TABLE archived_tasks ADD COLUMN external_ref TEXT;
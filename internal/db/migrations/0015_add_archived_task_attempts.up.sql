@@ -0,0 +1,19 @@
+-- Attempts of archived tasks, kept with them instead of deleted
+CREATE TABLE archived_task_attempts (
+	id INTEGER PRIMARY KEY,
+	task_id TEXT NOT NULL,
+	number INTEGER NOT NULL,
+	worker_id TEXT NOT NULL,
+	started_at INTEGER NOT NULL,
+	ended_at INTEGER,
+	outcome TEXT,
+	error TEXT,
+	verdict TEXT,
+	output_path TEXT,
+	transcript BLOB,
+	transcript_size INTEGER NOT NULL DEFAULT 0,
+	session_id TEXT,
+	model TEXT
+);
+
+CREATE INDEX idx_archived_task_attempts_task ON archived_task_attempts(task_id, number);
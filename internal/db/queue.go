@@ -0,0 +1,239 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// QueueMove is a change to a task's place in the run queue
+type QueueMove string
+
+const (
+	QueueBump  QueueMove = "bump"  // Ahead of every unpinned queued task
+	QueuePin   QueueMove = "pin"   // Run next, ahead of every other queued task
+	QueueDefer QueueMove = "defer" // Behind every other queued task
+	QueueReset QueueMove = "reset" // Back to the task's own priority
+)
+
+// PinnedPriority is the lowest effective priority of a pinned task; tasks
+// bumped to the front of the queue stay below it
+const PinnedPriority = 1 << 30
+
+// claimPriority is the priority workers claim tasks by: the task's place in
+// the queue if it was moved, else its priority, escalated for fix tasks
+const claimPriority = `COALESCE(effective_priority, ` + escalatedPriority + `)`
+
+// queuedTasks matches the top-level tasks still waiting to be claimed
+const queuedTasks = `status IN ('ready', 'blocked', 'paused') AND parent_id IS NULL`
+
+// MoveTask reorders a queued task by setting its effective priority, which
+// workers claim by in place of its priority. Running orchestrators pick the
+// new order up on their next claim
+func (s *Store) MoveTask(taskID string, move QueueMove) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status types.TaskStatus
+	var parentID sql.NullString
+	err = tx.QueryRow(`SELECT status, parent_id FROM tasks WHERE id = ?`, taskID).Scan(&status, &parentID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	if err != nil {
+		return fmt.Errorf("getting task: %w", err)
+	}
+	if parentID.Valid && parentID.String != "" {
+		return fmt.Errorf("task %s is a sub-task; it runs in sequence under %s", taskID, parentID.String)
+	}
+	switch status {
+	case types.TaskStatusReady, types.TaskStatusBlocked, types.TaskStatusPaused:
+	default:
+		return fmt.Errorf("task %s is %s; only queued tasks can be moved", taskID, status)
+	}
+
+	var effective sql.NullInt64
+	switch move {
+	case QueueBump:
+		err = tx.QueryRow(`
+			SELECT MAX(`+claimPriority+`) + 1 FROM tasks
+			WHERE `+queuedTasks+` AND id != ? AND `+claimPriority+` < ?
+		`, taskID, PinnedPriority).Scan(&effective)
+	case QueuePin:
+		err = tx.QueryRow(`
+			SELECT MAX(MAX(`+claimPriority+`) + 1, ?) FROM tasks
+			WHERE `+queuedTasks+` AND id != ?
+		`, PinnedPriority, taskID).Scan(&effective)
+	case QueueDefer:
+		err = tx.QueryRow(`
+			SELECT MIN(`+claimPriority+`) - 1 FROM tasks
+			WHERE `+queuedTasks+` AND id != ?
+		`, taskID).Scan(&effective)
+	case QueueReset:
+	default:
+		return fmt.Errorf("unknown queue move %q", move)
+	}
+	if err != nil {
+		return fmt.Errorf("finding queue position: %w", err)
+	}
+	if move == QueuePin && !effective.Valid {
+		effective = sql.NullInt64{Int64: PinnedPriority, Valid: true}
+	}
+	if move != QueueReset && !effective.Valid {
+		// The only queued task; it keeps its place at the front
+		if err := tx.QueryRow(`SELECT priority FROM tasks WHERE id = ?`, taskID).Scan(&effective.Int64); err != nil {
+			return fmt.Errorf("getting task priority: %w", err)
+		}
+		effective.Valid = true
+	}
+
+	_, err = tx.Exec(`
+		UPDATE tasks
+		SET effective_priority = ?, updated_at = ?
+		WHERE id = ?
+	`, effective, time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("moving task: %w", err)
+	}
+
+	body := map[QueueMove]string{
+		QueueBump:  "bumped to the front of the queue",
+		QueuePin:   "pinned to run next",
+		QueueDefer: "deferred to the back of the queue",
+		QueueReset: "returned to its own priority in the queue",
+	}[move]
+	if _, err := recordActivity(tx, taskID, types.ActivityQueue, s.actingAs(""), body); err != nil {
+		return fmt.Errorf("recording queue move: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListQueue returns the queued top-level tasks, optionally limited to an epic
+// and its sub-epics, in the order workers claim them once they are ready
+func (s *Store) ListQueue(epicID string) ([]*types.Task, error) {
+	var tasks []*types.Task
+	var err error
+	if epicID != "" {
+		tasks, err = s.listTasks(`WHERE `+queuedTasks+` AND epic_id IN (`+epicSubtree+`)`, epicID)
+	} else {
+		tasks, err = s.listTasks(`WHERE ` + queuedTasks)
+	}
+	if err != nil {
+		return nil, err
+	}
+	escalated, err := s.EscalatedPriorities()
+	if err != nil {
+		return nil, err
+	}
+	priority := func(t *types.Task) int {
+		if p, ok := escalated[t.ID]; ok {
+			return p
+		}
+		return t.ClaimPriority()
+	}
+	// Tasks are listed oldest first, which breaks ties the way claims do
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return priority(tasks[i]) > priority(tasks[j])
+	})
+	return tasks, nil
+}
+
+// QueueLimits are limits of the DBOS task queue changed on a live
+// deployment. A nil field keeps the value runs started with; 0 lifts the
+// limit
+type QueueLimits struct {
+	WorkerConcurrency *int           // Tasks one orchestrator runs at once
+	GlobalConcurrency *int           // Tasks running at once across orchestrators
+	RateLimit         *int           // Task starts per RatePeriod
+	RatePeriod        *time.Duration // Set together with RateLimit
+	UpdatedAt         time.Time
+}
+
+// GetQueueLimits returns the queue limits set on the live deployment, or nil
+// when none are
+func (s *Store) GetQueueLimits() (*QueueLimits, error) {
+	var worker, global, rate, periodMS sql.NullInt64
+	var updatedAt int64
+	err := s.DB.QueryRow(`
+		SELECT worker_concurrency, global_concurrency, rate_limit, rate_period_ms, updated_at
+		FROM queue_limits WHERE id = 1
+	`).Scan(&worker, &global, &rate, &periodMS, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting queue limits: %w", err)
+	}
+
+	limits := &QueueLimits{UpdatedAt: time.Unix(updatedAt, 0)}
+	limits.WorkerConcurrency = intFromNull(worker)
+	limits.GlobalConcurrency = intFromNull(global)
+	limits.RateLimit = intFromNull(rate)
+	if periodMS.Valid {
+		period := time.Duration(periodMS.Int64) * time.Millisecond
+		limits.RatePeriod = &period
+	}
+	return limits, nil
+}
+
+// SetQueueLimits changes the queue limits of running DBOS orchestrators.
+// Nil fields keep what was set before
+func (s *Store) SetQueueLimits(limits QueueLimits) error {
+	var periodMS any
+	if limits.RatePeriod != nil {
+		periodMS = limits.RatePeriod.Milliseconds()
+	}
+	_, err := s.DB.Exec(`
+		INSERT INTO queue_limits (id, worker_concurrency, global_concurrency, rate_limit, rate_period_ms, updated_at)
+		VALUES (1, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			worker_concurrency = COALESCE(excluded.worker_concurrency, worker_concurrency),
+			global_concurrency = COALESCE(excluded.global_concurrency, global_concurrency),
+			rate_limit = COALESCE(excluded.rate_limit, rate_limit),
+			rate_period_ms = COALESCE(excluded.rate_period_ms, rate_period_ms),
+			updated_at = excluded.updated_at
+	`, intOrNull(limits.WorkerConcurrency), intOrNull(limits.GlobalConcurrency), intOrNull(limits.RateLimit), periodMS, time.Now().Unix())
+	if err != nil {
+		return fmt.Errorf("setting queue limits: %w", err)
+	}
+	return nil
+}
+
+// ResetQueueLimits drops the limits set on the live deployment, so running
+// orchestrators go back to the ones they started with
+func (s *Store) ResetQueueLimits() error {
+	if _, err := s.DB.Exec(`DELETE FROM queue_limits`); err != nil {
+		return fmt.Errorf("resetting queue limits: %w", err)
+	}
+	return nil
+}
+
+// CountRunning returns how many tasks are claimed or in progress
+func (s *Store) CountRunning() (int, error) {
+	var n int
+	if err := s.DB.QueryRow(`SELECT COUNT(*) FROM tasks WHERE status IN ('claimed', 'in_progress')`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("counting running tasks: %w", err)
+	}
+	return n, nil
+}
+
+func intFromNull(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	n := int(v.Int64)
+	return &n
+}
+
+func intOrNull(n *int) any {
+	if n == nil {
+		return nil
+	}
+	return *n
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// readOnlyConns is the size of a read-only pool: enough for a few dashboard
+// requests at once, without holding many readers open against the live file
+const readOnlyConns = 4
+
+// readOnlyBusyTimeout is how long a read-only query waits on a lock before
+// failing, kept short so readers give up long before a claim would
+const readOnlyBusyTimeout = time.Second
+
+// OpenReadOnly opens a database for reporting queries, in its own small pool
+// of connections that can't write. Queries wait at most readOnlyBusyTimeout
+// for a lock. The schema isn't migrated; open the database with Open first
+func OpenReadOnly(path string) (*Store, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolving database path: %w", err)
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("mode", "ro")
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", readOnlyBusyTimeout.Milliseconds()))
+	db, err := sql.Open("sqlite", (&url.URL{Scheme: "file", Path: abs, RawQuery: params.Encode()}).String())
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	db.SetMaxOpenConns(readOnlyConns)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+	return &Store{DB: db}, nil
+}
+
+// Snapshot is a read-only copy of a database, refreshed periodically, so
+// readers never take locks on the live file at all
+type Snapshot struct {
+	source *Store // Read-only pool on the live database, used to take copies
+	dir    string // Where the copies are written
+	seq    int    // Number of the latest copy
+	path   string // File of the copy readers are on
+
+	current atomic.Pointer[Store]
+	mu      sync.Mutex // Serializes refreshes and Close
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// OpenSnapshot copies a database and refreshes the copy every interval until
+// closed. Readers get the latest copy from Store
+func OpenSnapshot(path string, interval time.Duration) (*Snapshot, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("snapshot interval must be positive, got %s", interval)
+	}
+	source, err := OpenReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "drover-snapshot-")
+	if err != nil {
+		source.Close()
+		return nil, fmt.Errorf("creating snapshot directory: %w", err)
+	}
+
+	s := &Snapshot{source: source, dir: dir, done: make(chan struct{})}
+	if err := s.Refresh(); err != nil {
+		source.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				if err := s.Refresh(); err != nil {
+					log.Printf("[snapshot] warning: %v", err)
+				}
+			}
+		}
+	}()
+	return s, nil
+}
+
+// Store returns a read-only store on the latest copy
+func (s *Snapshot) Store() *Store {
+	return s.current.Load()
+}
+
+// Refresh takes a new copy of the database and switches readers to it
+func (s *Snapshot) Refresh() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	path := filepath.Join(s.dir, fmt.Sprintf("snapshot-%d.db", s.seq))
+	// VACUUM INTO writes a consistent copy without blocking writers
+	if _, err := s.source.DB.Exec(`VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("copying database: %w", err)
+	}
+	copied, err := OpenReadOnly(path)
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	if old := s.current.Swap(copied); old != nil {
+		// Readers may have just taken the old copy; give their queries a
+		// moment before closing it
+		go func(old *Store, path string) {
+			time.Sleep(readOnlyBusyTimeout)
+			old.Close()
+			os.Remove(path)
+		}(old, s.path)
+	}
+	s.path = path
+	return nil
+}
+
+// Close stops refreshing and removes the copies
+func (s *Snapshot) Close() error {
+	close(s.done)
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current := s.current.Load(); current != nil {
+		current.Close()
+	}
+	err := s.source.Close()
+	os.RemoveAll(s.dir)
+	return err
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// crashRecovery is who recovered tasks are attributed to in the audit log
+const crashRecovery = "crash-recovery"
+
+// StrandedTask is a claimed or in-progress task, with the crash-recovery
+// checkpoint its worker wrote when it started executing it
+type StrandedTask struct {
+	TaskID      string
+	Title       string
+	Status      types.TaskStatus
+	ClaimedBy   string
+	Repo        string // Repository the task works in; empty is the project's own
+	Attempts    int
+	MaxAttempts int
+	Checkpoint  *types.TaskCheckpoint // Nil if the worker never got as far as writing one
+}
+
+// StrandedTasks lists the claimed and in-progress tasks, which a worker is
+// running or was running when its process died
+func (s *Store) StrandedTasks() ([]StrandedTask, error) {
+	rows, err := s.DB.Query(`
+		SELECT t.id, t.title, t.status, COALESCE(t.claimed_by, ''), COALESCE(t.repo, ''), t.attempts, t.max_attempts,
+		       c.state, c.worker_pid, c.started_at, c.last_heartbeat, c.attempt
+		FROM tasks t
+		LEFT JOIN task_checkpoints c ON c.task_id = t.id
+		WHERE t.status IN ('claimed', 'in_progress')
+		ORDER BY t.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing stranded tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var stranded []StrandedTask
+	for rows.Next() {
+		var t StrandedTask
+		var state sql.NullString
+		var pid, startedAt, heartbeat, attempt sql.NullInt64
+		if err := rows.Scan(&t.TaskID, &t.Title, &t.Status, &t.ClaimedBy, &t.Repo, &t.Attempts, &t.MaxAttempts,
+			&state, &pid, &startedAt, &heartbeat, &attempt); err != nil {
+			return nil, fmt.Errorf("scanning stranded task: %w", err)
+		}
+		if state.Valid {
+			t.Checkpoint = &types.TaskCheckpoint{
+				TaskID:        t.TaskID,
+				State:         types.TaskStatus(state.String),
+				WorkerPID:     int(pid.Int64),
+				StartedAt:     startedAt.Int64,
+				LastHeartbeat: heartbeat.Int64,
+				Attempt:       int(attempt.Int64),
+			}
+		}
+		stranded = append(stranded, t)
+	}
+	return stranded, rows.Err()
+}
+
+// RecoverTask takes a claimed or in-progress task back from a worker that
+// died: to is ready to run it again or failed once it is out of attempts.
+// The abandoned run counts as attempt number attempt, the claim and lease
+// are released and the task's checkpoint is deleted. Reports whether the
+// task was still stranded; a worker that finished it meanwhile wins
+func (s *Store) RecoverTask(taskID string, to types.TaskStatus, attempt int, note string) (bool, error) {
+	if to != types.TaskStatusReady && to != types.TaskStatusFailed {
+		return false, fmt.Errorf("cannot recover a task to %s (want ready or failed)", to)
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := recordStatusChange(tx, taskID, to, crashRecovery, note,
+		types.TaskStatusClaimed, types.TaskStatusInProgress); err != nil {
+		return false, err
+	}
+	res, err := tx.Exec(`
+		UPDATE tasks
+		SET status = ?, attempts = MAX(attempts, ?), last_error = ?,
+		    claimed_by = NULL, claimed_at = NULL, lease_expires_at = NULL, updated_at = ?
+		WHERE id = ? AND status IN ('claimed', 'in_progress')
+	`, to, attempt, note, time.Now().Unix(), taskID)
+	if err != nil {
+		return false, fmt.Errorf("recovering %s: %w", taskID, err)
+	}
+	if rowsAffected(res) == 0 {
+		return false, nil
+	}
+	if _, err := tx.Exec(`DELETE FROM task_checkpoints WHERE task_id = ?`, taskID); err != nil {
+		return false, fmt.Errorf("deleting checkpoint of %s: %w", taskID, err)
+	}
+	if err := rollupEpics(tx); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("committing recovery: %w", err)
+	}
+	return true, nil
+}
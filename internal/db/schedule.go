@@ -0,0 +1,359 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schedule names the Scheduler that picks which ready task a worker claims
+// next
+type Schedule string
+
+const (
+	// SchedulePriority claims the highest-priority ready task, oldest first.
+	// The default
+	SchedulePriority Schedule = "priority"
+	// ScheduleFIFO claims the oldest ready task, whatever its priority
+	ScheduleFIFO Schedule = "fifo"
+	// ScheduleCriticalPath claims the highest-priority ready task too, but
+	// among equal priorities prefers the one with the longest chain of
+	// unfinished tasks waiting on it, then the one blocking the most tasks,
+	// so the critical path of a dependency-heavy backlog starts first
+	ScheduleCriticalPath Schedule = "critical-path"
+	// ScheduleRoundRobin takes turns between the epics with ready tasks,
+	// claiming the highest-priority one of each epic's, so one large epic
+	// can't keep the others waiting
+	ScheduleRoundRobin Schedule = "round-robin"
+)
+
+// ReadyTask is a task a worker may claim: ready, past its not-before time,
+// within the claim's epic and free of any held mutex key
+type ReadyTask struct {
+	ID        string
+	EpicID    string
+	Priority  int   // The priority it is claimed by: its queue position, else its own, escalated for fix tasks
+	CreatedAt int64 // Unix seconds
+}
+
+// ClaimPriority returns the priority the task is claimed by, as
+// types.Task.ClaimPriority does for a task it has loaded
+func (t ReadyTask) ClaimPriority() int {
+	return t.Priority
+}
+
+// ClaimConstraints is what a Scheduler picks the next task under
+type ClaimConstraints struct {
+	WorkerID string
+	EpicID   string      // Claims are limited to this epic and its sub-epics; "" for any
+	Ready    []ReadyTask // The tasks the worker may claim, highest priority first, then oldest
+	tx       *sql.Tx
+}
+
+// Dependents maps each task to the unfinished tasks that wait on it, for
+// schedulers that order by the dependency graph
+func (c ClaimConstraints) Dependents() (map[string][]string, error) {
+	return unfinishedDependents(c.tx)
+}
+
+// Scheduler picks which ready task a worker claims next. ClaimNext runs
+// inside the claim's transaction, so no other worker can take the task it
+// picks first; it returns the ID of one of c.Ready, or "" to claim nothing.
+// Schedulers are shared by every worker of a store and must be safe for
+// concurrent use
+type Scheduler interface {
+	ClaimNext(ctx context.Context, c ClaimConstraints) (string, error)
+}
+
+// SchedulerFunc adapts a function to the Scheduler interface
+type SchedulerFunc func(ctx context.Context, c ClaimConstraints) (string, error)
+
+// ClaimNext calls f
+func (f SchedulerFunc) ClaimNext(ctx context.Context, c ClaimConstraints) (string, error) {
+	return f(ctx, c)
+}
+
+var (
+	schedulersMu sync.RWMutex
+	schedulers   = map[Schedule]func() Scheduler{
+		SchedulePriority:     func() Scheduler { return SchedulerFunc(priorityNext) },
+		ScheduleFIFO:         func() Scheduler { return SchedulerFunc(fifoNext) },
+		ScheduleCriticalPath: func() Scheduler { return SchedulerFunc(criticalPathNext) },
+		ScheduleRoundRobin:   func() Scheduler { return &roundRobin{} },
+	}
+)
+
+// RegisterScheduler makes a custom schedule available by name, to
+// --schedule and .drover.toml alike. newScheduler is called once per store
+// the schedule is set on. Registering a name again replaces it
+func RegisterScheduler(name Schedule, newScheduler func() Scheduler) {
+	schedulersMu.Lock()
+	defer schedulersMu.Unlock()
+	schedulers[name] = newScheduler
+}
+
+// Schedules lists the registered schedule names, sorted
+func Schedules() []Schedule {
+	schedulersMu.RLock()
+	defer schedulersMu.RUnlock()
+	names := make([]Schedule, 0, len(schedulers))
+	for name := range schedulers {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
+
+// ParseSchedule validates a schedule setting against the registered
+// schedules; empty means the default
+func ParseSchedule(s string) (Schedule, error) {
+	sched := Schedule(strings.ToLower(strings.TrimSpace(s)))
+	if sched == "" {
+		return SchedulePriority, nil
+	}
+	schedulersMu.RLock()
+	_, ok := schedulers[sched]
+	schedulersMu.RUnlock()
+	if !ok {
+		var names []string
+		for _, name := range Schedules() {
+			names = append(names, string(name))
+		}
+		return "", fmt.Errorf("unknown schedule %q (want one of %s)", s, strings.Join(names, ", "))
+	}
+	return sched, nil
+}
+
+// SetSchedule makes claims through this store pick tasks with the named
+// registered schedule. Unknown names fall back to the default
+func (s *Store) SetSchedule(schedule Schedule) {
+	schedulersMu.RLock()
+	newScheduler, ok := schedulers[schedule]
+	schedulersMu.RUnlock()
+	if !ok {
+		newScheduler = schedulers[SchedulePriority]
+	}
+	s.SetScheduler(newScheduler())
+}
+
+// SetScheduler makes claims through this store pick tasks with scheduler;
+// nil restores the default priority schedule
+func (s *Store) SetScheduler(scheduler Scheduler) {
+	s.scheduler = scheduler
+}
+
+// ReadyTasks lists the tasks a worker could claim now, optionally only those
+// in an epic and its sub-epics, highest claim priority first, then oldest
+func (s *Store) ReadyTasks(epicID string) ([]ReadyTask, error) {
+	return readyTasks(s.DB, epicID, time.Now().Unix())
+}
+
+// querier runs queries in or out of a transaction
+type querier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+// readyTasks lists the tasks claimable at now, highest claim priority first,
+// then oldest: ready top-level tasks, optionally filtered by epic; sub-tasks
+// run via their parent. A task waits while another with its mutex key is in
+// flight
+func readyTasks(q querier, epicID string, now int64) ([]ReadyTask, error) {
+	ready := `status = 'ready' AND parent_id IS NULL AND COALESCE(scheduled_at, 0) <= ?
+		AND (mutex_key IS NULL OR mutex_key NOT IN (` + heldMutexKeys + `))`
+	readyArgs := []any{now}
+	if epicID != "" {
+		ready += ` AND epic_id IN (` + epicSubtree + `)`
+		readyArgs = append(readyArgs, epicID)
+	}
+
+	rows, err := q.Query(`SELECT id, COALESCE(epic_id, ''), `+claimPriority+`, created_at FROM tasks
+		WHERE `+ready+`
+		ORDER BY `+claimPriority+` DESC, created_at ASC, id ASC`, readyArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("listing ready tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []ReadyTask
+	for rows.Next() {
+		var t ReadyTask
+		if err := rows.Scan(&t.ID, &t.EpicID, &t.Priority, &t.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning ready task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// priorityNext claims the highest-priority ready task, oldest first
+func priorityNext(_ context.Context, c ClaimConstraints) (string, error) {
+	if len(c.Ready) == 0 {
+		return "", nil
+	}
+	return c.Ready[0].ID, nil
+}
+
+// fifoNext claims the oldest ready task
+func fifoNext(_ context.Context, c ClaimConstraints) (string, error) {
+	if len(c.Ready) == 0 {
+		return "", nil
+	}
+	oldest := c.Ready[0]
+	for _, t := range c.Ready[1:] {
+		if t.CreatedAt < oldest.CreatedAt || (t.CreatedAt == oldest.CreatedAt && t.ID < oldest.ID) {
+			oldest = t
+		}
+	}
+	return oldest.ID, nil
+}
+
+// roundRobin serves the epics with ready tasks in turn, in epic ID order,
+// claiming each one's highest-priority task. Tasks outside any epic take a
+// turn as if they were an epic of their own
+type roundRobin struct {
+	mu   sync.Mutex
+	last string // Epic served by the previous claim
+	any  bool   // Whether there was a previous claim
+}
+
+func (r *roundRobin) ClaimNext(_ context.Context, c ClaimConstraints) (string, error) {
+	if len(c.Ready) == 0 {
+		return "", nil
+	}
+	// c.Ready is in priority order, so the first task seen per epic is its best
+	best := make(map[string]string)
+	var epics []string
+	for _, t := range c.Ready {
+		if _, ok := best[t.EpicID]; !ok {
+			best[t.EpicID] = t.ID
+			epics = append(epics, t.EpicID)
+		}
+	}
+	sort.Strings(epics)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := epics[0]
+	if r.any {
+		for _, epic := range epics {
+			if epic > r.last {
+				next = epic
+				break
+			}
+		}
+	}
+	r.last, r.any = next, true
+	return best[next], nil
+}
+
+// weight is how much unfinished work waits on a task
+type weight struct {
+	path    int // Tasks on the longest chain of dependents, the task included
+	blocked int // Unfinished tasks waiting on it, directly or through others
+}
+
+// criticalPathNext claims the highest-priority ready task, preferring the
+// one with the most unfinished work waiting on it among equal priorities
+func criticalPathNext(_ context.Context, c ClaimConstraints) (string, error) {
+	if len(c.Ready) == 0 {
+		return "", nil
+	}
+	dependents, err := c.Dependents()
+	if err != nil {
+		return "", err
+	}
+
+	type candidate struct {
+		ReadyTask
+		weight weight
+	}
+	paths := make(map[string]int)
+	candidates := make([]candidate, len(c.Ready))
+	for i, t := range c.Ready {
+		candidates[i] = candidate{ReadyTask: t, weight: downstreamWeight(dependents, paths, t.ID)}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch {
+		case a.Priority != b.Priority:
+			return a.Priority > b.Priority
+		case a.weight.path != b.weight.path:
+			return a.weight.path > b.weight.path
+		case a.weight.blocked != b.weight.blocked:
+			return a.weight.blocked > b.weight.blocked
+		case a.CreatedAt != b.CreatedAt:
+			return a.CreatedAt < b.CreatedAt
+		}
+		return a.ID < b.ID
+	})
+	return candidates[0].ID, nil
+}
+
+// unfinishedDependents maps each task to the unfinished tasks that wait on it
+func unfinishedDependents(tx *sql.Tx) (map[string][]string, error) {
+	rows, err := tx.Query(`
+		SELECT d.blocked_by, d.task_id
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.task_id
+		WHERE t.status NOT IN ('completed', 'failed', 'cancelled')
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	dependents := make(map[string][]string)
+	for rows.Next() {
+		var blocker, task string
+		if err := rows.Scan(&blocker, &task); err != nil {
+			return nil, fmt.Errorf("scanning dependency: %w", err)
+		}
+		dependents[blocker] = append(dependents[blocker], task)
+	}
+	return dependents, rows.Err()
+}
+
+// downstreamWeight works out a task's weight from the dependents map.
+// paths memoizes chain lengths across calls
+func downstreamWeight(dependents map[string][]string, paths map[string]int, id string) weight {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		for _, d := range dependents[next] {
+			if !seen[d] {
+				seen[d] = true
+				queue = append(queue, d)
+			}
+		}
+	}
+	return weight{
+		path:    longestChain(dependents, paths, id, map[string]bool{}),
+		blocked: len(seen) - 1,
+	}
+}
+
+// longestChain is the number of tasks on the longest chain of dependents
+// starting at id. Edges back onto the chain being walked are ignored, so a
+// dependency cycle can't recurse forever
+func longestChain(dependents map[string][]string, paths map[string]int, id string, onChain map[string]bool) int {
+	if n, ok := paths[id]; ok {
+		return n
+	}
+	onChain[id] = true
+	longest := 0
+	for _, d := range dependents[id] {
+		if !onChain[d] {
+			longest = max(longest, longestChain(dependents, paths, d, onChain))
+		}
+	}
+	delete(onChain, id)
+	paths[id] = longest + 1
+	return longest + 1
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// MaxTranscriptBytes caps how much of an attempt's output is kept. Longer
+// output keeps its end, where agents sum up what they did and why they failed
+const MaxTranscriptBytes = 1 << 20
+
+// SaveTranscript keeps an attempt's agent output, gzipped and capped at
+// MaxTranscriptBytes
+func (s *Store) SaveTranscript(attemptID int64, output string) error {
+	kept := output
+	if len(kept) > MaxTranscriptBytes {
+		cut := len(kept) - MaxTranscriptBytes
+		for cut < len(kept) && !utf8.RuneStart(kept[cut]) {
+			cut++
+		}
+		kept = fmt.Sprintf("[... first %d bytes of output dropped ...]\n", cut) + kept[cut:]
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, kept); err != nil {
+		return fmt.Errorf("compressing transcript: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("compressing transcript: %w", err)
+	}
+
+	res, err := s.DB.Exec(`
+		UPDATE task_attempts SET transcript = ?, transcript_size = ?
+		WHERE id = ?
+	`, buf.Bytes(), len(output), attemptID)
+	if err != nil {
+		return fmt.Errorf("saving transcript: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("attempt not found: %d", attemptID)
+	}
+	return nil
+}
+
+// GetTranscript returns the agent output kept for a task's attempt, by
+// attempt number; "" when none was kept
+func (s *Store) GetTranscript(taskID string, number int) (string, error) {
+	var compressed []byte
+	err := s.DB.QueryRow(`
+		SELECT transcript FROM task_attempts WHERE task_id = ? AND number = ?
+	`, taskID, number).Scan(&compressed)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("task %s has no attempt %d", taskID, number)
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading transcript: %w", err)
+	}
+	if len(compressed) == 0 {
+		return "", nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", fmt.Errorf("decompressing transcript: %w", err)
+	}
+	defer zr.Close()
+	out, err := io.ReadAll(zr)
+	if err != nil {
+		return "", fmt.Errorf("decompressing transcript: %w", err)
+	}
+	return string(out), nil
+}
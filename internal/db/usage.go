@@ -0,0 +1,82 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// Usage is the tokens and money agents reported spending on tasks
+type Usage struct {
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// TaskUsage returns what a task spent over all its attempts
+func TaskUsage(task *types.Task) Usage {
+	return Usage{InputTokens: task.InputTokens, OutputTokens: task.OutputTokens, CostUSD: task.CostUSD}
+}
+
+// IsZero reports whether nothing was spent, or no agent reported it
+func (u Usage) IsZero() bool {
+	return u.InputTokens == 0 && u.OutputTokens == 0 && u.CostUSD == 0
+}
+
+// Add accumulates o into u
+func (u *Usage) Add(o Usage) {
+	u.InputTokens += o.InputTokens
+	u.OutputTokens += o.OutputTokens
+	u.CostUSD += o.CostUSD
+}
+
+// String formats usage for summaries, e.g. "12.4k in / 3.1k out tokens, $0.42"
+func (u Usage) String() string {
+	return fmt.Sprintf("%s in / %s out tokens, $%.2f",
+		formatTokens(u.InputTokens), formatTokens(u.OutputTokens), u.CostUSD)
+}
+
+func formatTokens(n int64) string {
+	switch {
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+// AddTaskUsage adds what one execution of a task spent to the task's totals,
+// so a task retried three times shows what all three attempts cost
+func (s *Store) AddTaskUsage(taskID string, usage Usage) error {
+	if usage.IsZero() {
+		return nil
+	}
+	res, err := s.DB.Exec(`
+		UPDATE tasks
+		SET input_tokens = input_tokens + ?, output_tokens = output_tokens + ?,
+		    cost_usd = cost_usd + ?, updated_at = ?
+		WHERE id = ?
+	`, usage.InputTokens, usage.OutputTokens, usage.CostUSD, time.Now().Unix(), taskID)
+	if err != nil {
+		return fmt.Errorf("recording task usage: %w", err)
+	}
+	if rowsAffected(res) == 0 {
+		return fmt.Errorf("task not found: %s", taskID)
+	}
+	return nil
+}
+
+// projectUsage sums the usage of every task in the project
+func (s *Store) projectUsage() (Usage, error) {
+	var u Usage
+	err := s.DB.QueryRow(`
+		SELECT COALESCE(SUM(input_tokens), 0), COALESCE(SUM(output_tokens), 0), COALESCE(SUM(cost_usd), 0)
+		FROM tasks
+	`).Scan(&u.InputTokens, &u.OutputTokens, &u.CostUSD)
+	if err != nil {
+		return Usage{}, fmt.Errorf("summing task usage: %w", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,236 @@
+// Package diagnostics runs fast static checks (go vet, tsc, clippy) in a task's
+// worktree and parses their findings so they can be fed back to the agent
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOutputBytes bounds the raw checker output kept on a Result
+const maxOutputBytes = 16 * 1024
+
+// Diagnostic is one finding reported by a checker
+type Diagnostic struct {
+	File    string
+	Line    int
+	Column  int // 0 when the tool doesn't report one
+	Message string
+}
+
+// String formats the diagnostic as file:line:col: message
+func (d Diagnostic) String() string {
+	if d.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", d.File, d.Line, d.Message)
+}
+
+// Result is the outcome of one diagnostics run
+type Result struct {
+	Tool        string // Command that produced the diagnostics, e.g. "go vet ./..."
+	Diagnostics []Diagnostic
+	Output      string // Raw (truncated) checker output
+}
+
+// Clean reports whether the run found nothing to fix
+func (r *Result) Clean() bool {
+	return r == nil || len(r.Diagnostics) == 0
+}
+
+// Checker runs a project's diagnostics command in a worktree
+type Checker struct {
+	command string        // Overrides the detected command when set
+	timeout time.Duration // Upper bound for one run (0 = no limit)
+}
+
+// NewChecker creates a checker. An empty command detects one from the project
+// files in the worktree
+func NewChecker(command string, timeout time.Duration) *Checker {
+	return &Checker{
+		command: command,
+		timeout: timeout,
+	}
+}
+
+// Check runs diagnostics in dir with extra KEY=VALUE env entries (e.g. a
+// shared GOCACHE). It returns nil when no command applies to the
+// project, or a Go project has no Go changes to vet, and an error when the command could not run or failed without
+// reporting anything parseable
+func (c *Checker) Check(ctx context.Context, dir string, env []string) (*Result, error) {
+	var name string
+	var args []string
+	if parts := strings.Fields(c.command); len(parts) > 0 {
+		name, args = parts[0], parts[1:]
+	} else {
+		name, args = detectCommand(dir)
+		if name == "" {
+			return nil, nil
+		}
+	}
+	tool := strings.TrimSpace(name + " " + strings.Join(args, " "))
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	runErr := cmd.Run()
+
+	output := out.String()
+	result := &Result{
+		Tool:        tool,
+		Diagnostics: Parse(output),
+		Output:      truncate(output, maxOutputBytes),
+	}
+
+	if runErr != nil && len(result.Diagnostics) == 0 {
+		if _, ok := runErr.(*exec.ExitError); !ok || ctx.Err() != nil {
+			return nil, fmt.Errorf("running %s: %w", tool, runErr)
+		}
+		return nil, fmt.Errorf("%s failed without reporting diagnostics: %w\n%s", tool, runErr, result.Output)
+	}
+	return result, nil
+}
+
+// detectCommand picks the checker for the project in dir, or "" if none applies
+func detectCommand(dir string) (string, []string) {
+	switch {
+	case hasFile(dir, "go.mod"):
+		pkgs := changedGoPackages(dir)
+		if len(pkgs) == 0 {
+			return "", nil
+		}
+		return "go", append([]string{"vet"}, pkgs...)
+	case hasFile(dir, "tsconfig.json"):
+		return "npx", []string{"--no-install", "tsc", "--noEmit", "--pretty", "false"}
+	case hasFile(dir, "Cargo.toml"):
+		return "cargo", []string{"clippy", "--quiet", "--message-format=short"}
+	}
+	return "", nil
+}
+
+// changedGoPackages returns the packages with Go files changed or added in
+// dir's git worktree, so go vet reports on the task's work rather than on
+// problems the repository already had. It falls back to ./... when git
+// can't tell
+func changedGoPackages(dir string) []string {
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--untracked-files=all").Output()
+	if err != nil {
+		return []string{"./..."}
+	}
+	var pkgs []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		if len(line) < 4 {
+			continue
+		}
+		file := line[3:]
+		if _, renamed, ok := strings.Cut(file, " -> "); ok {
+			file = renamed
+		}
+		file = strings.Trim(file, `"`)
+		pkg := path.Dir(file)
+		if !strings.HasSuffix(file, ".go") || seen[pkg] || ignoredGoDir(pkg) {
+			continue
+		}
+		seen[pkg] = true
+		// A package whose files were all deleted is gone
+		if info, err := os.Stat(filepath.Join(dir, pkg)); err != nil || !info.IsDir() {
+			continue
+		}
+		if pkg == "." {
+			pkgs = append(pkgs, ".")
+		} else {
+			pkgs = append(pkgs, "./"+pkg)
+		}
+	}
+	return pkgs
+}
+
+// ignoredGoDir reports whether the go tool leaves the directory out of ./...
+func ignoredGoDir(dir string) bool {
+	for _, elem := range strings.Split(dir, "/") {
+		if elem == "testdata" || elem == "vendor" || (elem != "." && (strings.HasPrefix(elem, ".") || strings.HasPrefix(elem, "_"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasFile(dir, name string) bool {
+	_, err := os.Stat(filepath.Join(dir, name))
+	return err == nil
+}
+
+var (
+	// file:line[:col]: message, as printed by go vet, the Go compiler and clippy --message-format=short
+	colonPattern = regexp.MustCompile(`^(?:vet: )?(?:\./)?([^\s:()][^:()]*):(\d+)(?::(\d+))?: (.+)$`)
+	// file(line,col): message, as printed by tsc --pretty false
+	parenPattern = regexp.MustCompile(`^([^\s()][^()]*)\((\d+),(\d+)\): (.+)$`)
+)
+
+// Parse extracts diagnostics from checker output, ignoring summary lines
+func Parse(output string) []Diagnostic {
+	var diags []Diagnostic
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		m := colonPattern.FindStringSubmatch(line)
+		if m == nil {
+			m = parenPattern.FindStringSubmatch(line)
+		}
+		if m == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		d := Diagnostic{File: m[1], Line: lineNo, Column: col, Message: strings.TrimSpace(m[4])}
+		if key := d.String(); !seen[key] {
+			seen[key] = true
+			diags = append(diags, d)
+		}
+	}
+	return diags
+}
+
+// Prompt renders the diagnostics as an instruction for the agent, listing at
+// most limit findings (0 = all)
+func (r *Result) Prompt(limit int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "`%s` reported %d problem(s) in your changes. Fix them without changing the task's intended behavior, then re-check:\n",
+		r.Tool, len(r.Diagnostics))
+	for i, d := range r.Diagnostics {
+		if limit > 0 && i == limit {
+			fmt.Fprintf(&b, "... and %d more\n", len(r.Diagnostics)-limit)
+			break
+		}
+		fmt.Fprintf(&b, "- %s\n", d)
+	}
+	return b.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "\n... (truncated)"
+}
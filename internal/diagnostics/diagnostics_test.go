@@ -0,0 +1,167 @@
+package diagnostics
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name: "go vet",
+			output: "# example.com/app\n" +
+				"./main.go:12:2: fmt.Printf format %d has arg name of wrong type string\n" +
+				"vet: ./util/util.go:3:8: \"os\" imported and not used\n",
+			want: []string{
+				"main.go:12:2: fmt.Printf format %d has arg name of wrong type string",
+				"util/util.go:3:8: \"os\" imported and not used",
+			},
+		},
+		{
+			name: "tsc",
+			output: "src/app.ts(4,7): error TS2322: Type 'string' is not assignable to type 'number'.\n" +
+				"Found 1 error.\n",
+			want: []string{
+				"src/app.ts:4:7: error TS2322: Type 'string' is not assignable to type 'number'.",
+			},
+		},
+		{
+			name: "clippy",
+			output: "src/main.rs:2:9: warning: unused variable: `x`\n" +
+				"src/main.rs:2:9: warning: unused variable: `x`\n" +
+				"warning: `app` (bin \"app\") generated 1 warning\n",
+			want: []string{
+				"src/main.rs:2:9: warning: unused variable: `x`",
+			},
+		},
+		{
+			name:   "nothing to report",
+			output: "ok  \texample.com/app\t0.01s\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := Parse(tt.output)
+			var got []string
+			for _, d := range diags {
+				got = append(got, d.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("Parse() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestChecker_Check(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the diagnostics command")
+	}
+	dir := t.TempDir()
+
+	// No project files and no command: nothing to check
+	result, err := NewChecker("", 0).Check(context.Background(), dir, nil)
+	if err != nil || result != nil {
+		t.Fatalf("expected no check for an unknown project, got %v, %v", result, err)
+	}
+
+	script := filepath.Join(dir, "check.sh")
+	write := func(body string) {
+		if err := os.WriteFile(script, []byte("#!/bin/sh\n"+body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checker := NewChecker(script, 0)
+
+	write("echo \"./a.go:1:1: $CHECK_MSG\"\nexit 1\n")
+	result, err = checker.Check(context.Background(), dir, []string{"CHECK_MSG=bad thing"})
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Clean() || result.Diagnostics[0].Message != "bad thing" {
+		t.Fatalf("expected one diagnostic with the env-provided message, got %+v", result.Diagnostics)
+	}
+	if prompt := result.Prompt(0); !strings.Contains(prompt, "- a.go:1:1: bad thing") {
+		t.Errorf("prompt missing diagnostic:\n%s", prompt)
+	}
+
+	write("exit 0\n")
+	if result, err = checker.Check(context.Background(), dir, nil); err != nil || !result.Clean() {
+		t.Fatalf("expected a clean result, got %+v, %v", result, err)
+	}
+
+	// A failing checker with nothing parseable is an error, not a clean run
+	write("echo 'cannot find module'\nexit 2\n")
+	if _, err = checker.Check(context.Background(), dir, nil); err == nil {
+		t.Fatal("expected an error when the checker fails without diagnostics")
+	}
+}
+
+func TestChangedGoPackages(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, body string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("go.mod", "module example.com/app\n")
+	write("main.go", "package main\n")
+	write("old/old.go", "package old\n")
+	write("lib/lib.go", "package lib\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "base")
+
+	// Nothing changed: nothing to vet
+	if name, args := detectCommand(dir); name != "" {
+		t.Fatalf("expected no check without changes, got %s %v", name, args)
+	}
+
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("lib/sub/new.go", "package sub\n")
+	write("lib/testdata/x.go", "package x\n")
+	write("README.md", "docs\n")
+	if err := os.RemoveAll(filepath.Join(dir, "old")); err != nil {
+		t.Fatal(err)
+	}
+	name, args := detectCommand(dir)
+	if got := name + " " + strings.Join(args, " "); got != "go vet . ./lib/sub" {
+		t.Errorf("detectCommand() = %q, want the changed packages only", got)
+	}
+}
+
+func TestResult_PromptLimit(t *testing.T) {
+	result := &Result{Tool: "go vet ./..."}
+	for i := 0; i < 5; i++ {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{File: "a.go", Line: i + 1, Message: "x"})
+	}
+	prompt := result.Prompt(2)
+	if !strings.Contains(prompt, "reported 5 problem(s)") || !strings.Contains(prompt, "... and 3 more") {
+		t.Errorf("unexpected prompt:\n%s", prompt)
+	}
+}
@@ -0,0 +1,256 @@
+// Package dod evaluates a project's "definition of done" checklist against a
+// finished task. Well-known items are checked mechanically from the task's
+// diff; any other item is put to the agent, which reports on it in its output
+package dod
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Items checked mechanically from the diff
+const (
+	ItemTestsAdded  = "tests_added"  // The change adds or modifies a test file
+	ItemDocsUpdated = "docs_updated" // The change adds or modifies documentation
+	ItemNoTODOs     = "no_todos"     // No added line introduces a TODO, FIXME or XXX
+)
+
+// Policies for unmet items
+const (
+	PolicyBlock    = "block"     // Fail the task; nothing is merged
+	PolicyFollowUp = "follow_up" // Merge, and create a follow-up task per unmet item
+)
+
+var (
+	todoPattern   = regexp.MustCompile(`\b(TODO|FIXME|XXX)\b`)
+	reportPattern = regexp.MustCompile(`(?im)^[ \t*>#-]*DoD:[ \t]*(.+?):[ \t]*(met|unmet)\b[ \t:-]*(.*)$`)
+)
+
+// Checklist is a project's definition of done
+type Checklist struct {
+	Items  []string
+	Policy string
+}
+
+// New returns the checklist for the configured items, or nil when there are
+// none. An empty policy means PolicyBlock
+func New(items []string, policy string) (*Checklist, error) {
+	c := &Checklist{Policy: policy}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			c.Items = append(c.Items, item)
+		}
+	}
+	if len(c.Items) == 0 {
+		return nil, nil
+	}
+	switch c.Policy {
+	case "":
+		c.Policy = PolicyBlock
+	case PolicyBlock, PolicyFollowUp:
+	default:
+		return nil, fmt.Errorf("unknown dod_policy %q (valid: %s, %s)", policy, PolicyBlock, PolicyFollowUp)
+	}
+	return c, nil
+}
+
+// IsMechanical reports whether an item is checked from the diff
+func IsMechanical(item string) bool {
+	switch item {
+	case ItemTestsAdded, ItemDocsUpdated, ItemNoTODOs:
+		return true
+	}
+	return false
+}
+
+// Guidance returns the instructions asking the agent to report on the items
+// that can't be checked mechanically, or "" when there are none
+func (c *Checklist) Guidance() string {
+	if c == nil {
+		return ""
+	}
+	var items []string
+	for _, item := range c.Items {
+		if !IsMechanical(item) {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("This project has a definition of done. Before you finish, make sure each item below holds, ")
+	b.WriteString("then end your response with one line per item in exactly this form:\n")
+	b.WriteString("DoD: <item>: met\nDoD: <item>: unmet - <why>\n\nItems:\n")
+	for _, item := range items {
+		fmt.Fprintf(&b, "- %s\n", item)
+	}
+	return b.String()
+}
+
+// Result is the evaluation of one checklist item
+type Result struct {
+	Item   string
+	Met    bool
+	Reason string // Why the item is unmet
+}
+
+// String formats the result as "item: met" or "item: unmet (reason)"
+func (r Result) String() string {
+	if r.Met {
+		return r.Item + ": met"
+	}
+	return fmt.Sprintf("%s: unmet (%s)", r.Item, r.Reason)
+}
+
+// Evaluate checks every item against the task's unified diff and the agent's
+// final output. Items the agent didn't report on count as unmet
+func (c *Checklist) Evaluate(diff, agentOutput string) []Result {
+	if c == nil {
+		return nil
+	}
+	files, added := parseDiff(diff)
+	reports := parseReports(agentOutput)
+
+	results := make([]Result, 0, len(c.Items))
+	for _, item := range c.Items {
+		r := Result{Item: item, Met: true}
+		switch item {
+		case ItemTestsAdded:
+			if !anyFile(files, isTestFile) {
+				r.Met, r.Reason = false, "no test files changed"
+			}
+		case ItemDocsUpdated:
+			if !anyFile(files, isDocFile) {
+				r.Met, r.Reason = false, "no documentation changed"
+			}
+		case ItemNoTODOs:
+			if n := countTODOs(added); n > 0 {
+				r.Met, r.Reason = false, fmt.Sprintf("%d added line(s) contain TODO, FIXME or XXX", n)
+			}
+		default:
+			report, ok := reports[strings.ToLower(item)]
+			switch {
+			case !ok:
+				r.Met, r.Reason = false, "not confirmed by the agent"
+			case !report.Met:
+				r.Met, r.Reason = false, report.Reason
+				if r.Reason == "" {
+					r.Reason = "reported unmet by the agent"
+				}
+			}
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// Unmet returns the results whose item is not met
+func Unmet(results []Result) []Result {
+	var unmet []Result
+	for _, r := range results {
+		if !r.Met {
+			unmet = append(unmet, r)
+		}
+	}
+	return unmet
+}
+
+// UnmetError reports checklist items a task did not meet
+type UnmetError struct {
+	Results []Result
+}
+
+// Error implements error
+func (e *UnmetError) Error() string {
+	parts := make([]string, len(e.Results))
+	for i, r := range e.Results {
+		parts[i] = r.String()
+	}
+	return "definition of done not met: " + strings.Join(parts, "; ")
+}
+
+// parseDiff returns the files a unified diff changes (deletions excluded)
+// and the lines it adds
+func parseDiff(diff string) (files, added []string) {
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++ "):
+			if file := strings.TrimPrefix(line, "+++ "); file != "/dev/null" {
+				files = append(files, strings.TrimPrefix(file, "b/"))
+			}
+		case strings.HasPrefix(line, "+"):
+			added = append(added, line[1:])
+		}
+	}
+	return files, added
+}
+
+// parseReports extracts the agent's "DoD: <item>: met|unmet" lines, keyed by
+// lower-cased item. A later report on the same item wins
+func parseReports(output string) map[string]Result {
+	reports := make(map[string]Result)
+	for _, m := range reportPattern.FindAllStringSubmatch(output, -1) {
+		item := strings.TrimSpace(m[1])
+		reports[strings.ToLower(item)] = Result{
+			Item:   item,
+			Met:    strings.EqualFold(m[2], "met"),
+			Reason: strings.TrimSpace(m[3]),
+		}
+	}
+	return reports
+}
+
+func anyFile(files []string, match func(string) bool) bool {
+	for _, f := range files {
+		if match(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTestFile recognizes test files of the common Go, JS/TS, Python and Rust layouts
+func isTestFile(file string) bool {
+	base := path.Base(file)
+	switch {
+	case strings.HasSuffix(base, "_test.go"),
+		strings.Contains(base, ".test."), strings.Contains(base, ".spec."),
+		strings.HasPrefix(base, "test_") && strings.HasSuffix(base, ".py"),
+		strings.HasSuffix(base, "_test.py"):
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" {
+			return true
+		}
+	}
+	return false
+}
+
+// isDocFile recognizes markup files and anything under a docs directory
+func isDocFile(file string) bool {
+	switch strings.ToLower(path.Ext(file)) {
+	case ".md", ".mdx", ".rst", ".adoc":
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(file), "/") {
+		if dir == "docs" || dir == "doc" {
+			return true
+		}
+	}
+	return false
+}
+
+func countTODOs(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		if todoPattern.MatchString(line) {
+			n++
+		}
+	}
+	return n
+}
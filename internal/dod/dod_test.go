@@ -0,0 +1,80 @@
+package dod
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleDiff = `diff --git a/internal/app/app.go b/internal/app/app.go
+--- a/internal/app/app.go
++++ b/internal/app/app.go
+@@ -1,3 +1,4 @@
+ package app
++// TODO: handle the error
+diff --git a/README.md b/README.md
+deleted file mode 100644
+--- a/README.md
++++ /dev/null
+@@ -1 +0,0 @@
+-# App
+`
+
+func TestNew(t *testing.T) {
+	if c, err := New([]string{" ", ""}, ""); c != nil || err != nil {
+		t.Errorf("New(blank items) = %v, %v; want nil, nil", c, err)
+	}
+	c, err := New([]string{ItemNoTODOs}, "")
+	if err != nil || c.Policy != PolicyBlock {
+		t.Errorf("New default policy = %v, %v; want %s", c, err, PolicyBlock)
+	}
+	if _, err := New([]string{ItemNoTODOs}, "ignore"); err == nil {
+		t.Error("New accepted an unknown policy")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	c, err := New([]string{ItemTestsAdded, ItemDocsUpdated, ItemNoTODOs, "Changelog entry added", "API reviewed"}, PolicyFollowUp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := "Done.\nDoD: changelog entry added: met\n- DoD: API reviewed: unmet - no reviewer available\n"
+
+	got := map[string]Result{}
+	for _, r := range c.Evaluate(sampleDiff, output) {
+		got[r.Item] = r
+	}
+
+	want := map[string]bool{
+		ItemTestsAdded:          false,
+		ItemDocsUpdated:         false, // Deleting the README doesn't count
+		ItemNoTODOs:             false,
+		"Changelog entry added": true,
+		"API reviewed":          false,
+	}
+	for item, met := range want {
+		if got[item].Met != met {
+			t.Errorf("%s: met = %v, want %v (%s)", item, got[item].Met, met, got[item].Reason)
+		}
+	}
+	if reason := got["API reviewed"].Reason; reason != "no reviewer available" {
+		t.Errorf("API reviewed reason = %q", reason)
+	}
+
+	// The same checklist passes once tests and docs change and the agent confirms everything
+	diff := "+++ b/internal/app/app_test.go\n+func TestApp(t *testing.T) {}\n+++ b/docs/app.md\n+# App\n"
+	output = "DoD: Changelog entry added: met\nDoD: API reviewed: met\n"
+	if unmet := Unmet(c.Evaluate(diff, output)); len(unmet) != 0 {
+		t.Errorf("unexpected unmet items: %v", unmet)
+	}
+}
+
+func TestGuidance(t *testing.T) {
+	c, _ := New([]string{ItemTestsAdded, "Changelog entry added"}, "")
+	guidance := c.Guidance()
+	if !strings.Contains(guidance, "- Changelog entry added") || strings.Contains(guidance, ItemTestsAdded) {
+		t.Errorf("guidance should list only agent-checked items:\n%s", guidance)
+	}
+	if c, _ := New([]string{ItemNoTODOs}, ""); c.Guidance() != "" {
+		t.Error("mechanical-only checklist should need no guidance")
+	}
+}
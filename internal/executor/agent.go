@@ -3,6 +3,9 @@ package executor
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"time"
 
 	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
@@ -33,12 +36,22 @@ type Agent interface {
 
 // AgentConfig contains configuration for creating an agent
 type AgentConfig struct {
-	// Type is the agent type: "claude", "codex", "amp", "opencode", or "worker"
+	// Type is the agent type: "claude", "codex", "amp", "opencode", "aider", "goose", "custom", "llm", or "worker"
 	Type string
 
 	// Path is the path to the agent binary (for claude/codex/amp CLIs)
 	Path string
 
+	// Model is the model the agent runs (for every type but "amp" and "worker"; empty = the agent's default)
+	Model string
+
+	// Fallbacks are the models, of this agent or another, that a task falls
+	// back to in turn when the one before is rate limited (empty = none)
+	Fallbacks []ModelFallback
+
+	// Provider is the LLM provider the model comes from (for type="goose"; empty = goose's configured one)
+	Provider string
+
 	// Timeout is the maximum duration to wait for task completion
 	Timeout time.Duration
 
@@ -56,6 +69,65 @@ type AgentConfig struct {
 
 	// WorkerMemoryLimit is the memory limit for worker processes (for type="worker")
 	WorkerMemoryLimit string
+
+	// WorkerProtocol is how to talk to worker processes, WorkerProtocolGRPC
+	// (the default) or WorkerProtocolJSON (for type="worker")
+	WorkerProtocol string
+
+	// RemoteWorkers are the hosts workers run tasks on over SSH (for
+	// type="worker"; nil = locally)
+	RemoteWorkers *RemoteWorkers
+
+	// WorkerPoolSize is how many long-lived workers run tasks one after
+	// another (for type="worker", 0 = a worker process per task)
+	WorkerPoolSize int
+
+	// WorkerPoolMaxTasks is how many tasks a pooled worker runs before it's
+	// replaced (0 = DefaultWorkerPoolMaxTasks)
+	WorkerPoolMaxTasks int
+
+	// CPULimit constrains the CPU every process the agent runs for a task
+	// gets, such as its niceness and quota (zero = unconstrained)
+	CPULimit CPULimit
+
+	// OpenCodeURL is a running opencode server to attach to (for type="opencode")
+	OpenCodeURL string
+
+	// Custom is the command line and output patterns of an in-house agent
+	// (for type="custom")
+	Custom CustomAgentConfig
+
+	// LLMURL is the OpenAI-compatible chat API to call, such as the drover
+	// proxy (for type="llm"; empty = DefaultLLMURL)
+	LLMURL string
+
+	// LLMAPIKey is the API key sent to LLMURL (for type="llm")
+	LLMAPIKey string
+
+	// StreamEvents has agents that can report their work as JSON events
+	// (claude, codex and amp) do so, broadcasting each to the dashboard
+	StreamEvents bool
+
+	// PromptTemplates renders the agent's task prompts (nil = its built-in
+	// prompt)
+	PromptTemplates *PromptTemplates
+
+	// Sandbox confines every process the agent runs for a task, such as a
+	// DockerSandbox (nil = they run on the host)
+	Sandbox Sandbox
+
+	// Resume has a retry resume the Claude session of the task's previous
+	// attempt (for type="claude")
+	Resume bool
+
+	// OpenCodeServers is how many warm opencode servers to launch and
+	// load-balance across (for type="opencode", 0 = none)
+	OpenCodeServers int
+
+	// MaxOutput is how much of each output stream of an agent run is kept in
+	// memory; a run over it spills its full output to a file (0 =
+	// DefaultMaxOutput)
+	MaxOutput int64
 }
 
 // NewAgent creates a new Agent based on the provided configuration
@@ -74,14 +146,64 @@ func NewAgent(cfg *AgentConfig) (Agent, error) {
 		if wa, ok := agent.(*WorkerAgent); ok && cfg.WorkerMemoryLimit != "" {
 			wa.SetMemoryLimit(cfg.WorkerMemoryLimit)
 		}
+		switch cfg.WorkerProtocol {
+		case "", WorkerProtocolGRPC, WorkerProtocolJSON:
+		default:
+			return nil, fmt.Errorf("unknown worker protocol %q (want %q or %q)", cfg.WorkerProtocol, WorkerProtocolGRPC, WorkerProtocolJSON)
+		}
+		if wa, ok := agent.(*WorkerAgent); ok {
+			wa.SetProtocol(cfg.WorkerProtocol)
+			wa.SetRemote(cfg.RemoteWorkers)
+		}
+		// Pooled workers are local and talk gRPC, running task after task
+		if cfg.WorkerPoolSize > 0 {
+			if cfg.RemoteWorkers != nil {
+				return nil, fmt.Errorf("a worker pool can't run remote workers; run without DROVER_WORKER_POOL or DROVER_REMOTE_WORKERS")
+			}
+			if cfg.WorkerProtocol == WorkerProtocolJSON {
+				return nil, fmt.Errorf("pooled workers talk the %q protocol, not %q", WorkerProtocolGRPC, WorkerProtocolJSON)
+			}
+			if wa, ok := agent.(*WorkerAgent); ok {
+				pool := NewWorkerPool(workerPath, cfg.WorkerPoolSize, cfg.WorkerPoolMaxTasks)
+				pool.SetCPULimit(cfg.CPULimit)
+				wa.SetPool(pool)
+			}
+		}
 	case "claude":
-		agent = NewClaudeAgent(cfg.Path, cfg.Timeout)
+		claude := NewClaudeAgent(cfg.Path, cfg.Timeout)
+		claude.SetModel(cfg.Model)
+		agent = claude
 	case "codex":
-		agent = NewCodexAgent(cfg.Path, cfg.Timeout)
+		codex := NewCodexAgent(cfg.Path, cfg.Timeout)
+		codex.SetModel(cfg.Model)
+		agent = codex
 	case "amp":
 		agent = NewAmpAgent(cfg.Path, cfg.Timeout)
+	case "aider":
+		aider := NewAiderAgent(cfg.Path, cfg.Timeout)
+		aider.SetModel(cfg.Model)
+		agent = aider
+	case "goose":
+		goose := NewGooseAgent(cfg.Path, cfg.Timeout)
+		goose.SetModel(cfg.Provider, cfg.Model)
+		agent = goose
+	case "custom":
+		custom, err := NewCustomAgent(cfg.Custom, cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		custom.SetModel(cfg.Model)
+		agent = custom
+	case "llm":
+		agent = NewLLMAgent(cfg.LLMURL, cfg.LLMAPIKey, cfg.Model, cfg.Timeout)
 	case "opencode":
-		agent = NewOpenCodeAgent(cfg.Path, cfg.Timeout)
+		oc := NewOpenCodeAgent(cfg.Path, cfg.Timeout)
+		oc.SetModel(cfg.Model)
+		oc.SetServerURL(cfg.OpenCodeURL)
+		if cfg.OpenCodeServers > 0 {
+			oc.SetServerPool(NewOpenCodeServerPool(cfg.Path, cfg.OpenCodeServers))
+		}
+		agent = oc
 	default:
 		// Default to Claude for backwards compatibility
 		agent = NewClaudeAgent(cfg.Path, cfg.Timeout)
@@ -98,10 +220,65 @@ func NewAgent(cfg *AgentConfig) (Agent, error) {
 		agent.SetContextManager(ctxManager)
 	}
 
+	// Render prompts from the project's templates where the agent can
+	if p, ok := agent.(promptTemplater); ok && cfg.PromptTemplates != nil {
+		p.SetPromptTemplates(cfg.PromptTemplates)
+	}
+
 	// Set verbose mode
 	if cfg.Verbose {
 		agent.SetVerbose(true)
 	}
 
+	// Stream events where the agent can
+	if s, ok := agent.(eventStreamer); ok && cfg.StreamEvents {
+		s.SetStreamEvents(true)
+	}
+	if claude, ok := agent.(*ClaudeAgent); ok && cfg.Resume {
+		claude.SetResume(true)
+	}
+
+	if cfg.Sandbox != nil {
+		// A remote worker runs on a host of its own, outside any sandbox
+		if cfg.Type == "worker" && cfg.RemoteWorkers != nil {
+			return nil, fmt.Errorf("a sandbox can't confine remote workers; run without a sandbox or DROVER_REMOTE_WORKERS")
+		}
+		// A pooled worker outlives the task the sandbox is set up for
+		if cfg.Type == "worker" && cfg.WorkerPoolSize > 0 {
+			return nil, fmt.Errorf("a sandbox can't confine pooled workers; run without a sandbox or DROVER_WORKER_POOL")
+		}
+		// An opencode server does the work itself, outside any sandbox
+		if cfg.Type == "opencode" && (cfg.OpenCodeURL != "" || cfg.OpenCodeServers > 0) {
+			return nil, fmt.Errorf("a sandbox can't confine opencode servers; run opencode without --opencode-servers or an attach URL")
+		}
+		agent = NewSandboxedAgent(agent, cfg.Sandbox)
+	}
+	if !cfg.CPULimit.IsZero() {
+		agent = NewCPULimitedAgent(agent, cfg.CPULimit)
+	}
+	agent = NewOutputLimitedAgent(agent, cfg.MaxOutput)
+
+	if len(cfg.Fallbacks) > 0 {
+		return newFallbackChain(cfg, agent)
+	}
 	return agent, nil
 }
+
+// CloseAgent releases resources an agent holds for the whole run, such as
+// managed opencode servers. Agents without any are left alone
+func CloseAgent(agent Agent) error {
+	if c, ok := agent.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// commandEnv returns the environment for an agent process: the drover process
+// environment plus any per-task entries from the execution context (such as a
+// shared GOCACHE). Returns nil, meaning inherit, when there is nothing to add
+func commandEnv(task *types.Task) []string {
+	if task == nil || task.ExecutionContext == nil || len(task.ExecutionContext.Env) == 0 {
+		return nil
+	}
+	return append(os.Environ(), task.ExecutionContext.Env...)
+}
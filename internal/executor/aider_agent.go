@@ -0,0 +1,295 @@
+// Package executor provides Aider agent implementation
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/outcome"
+	"github.com/cloud-shuttle/drover/internal/taskcontext"
+	"github.com/cloud-shuttle/drover/pkg/telemetry"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// aiderExclude keeps the files Aider leaves in a repository, such as its tag
+// cache, out of task commits
+const aiderExclude = ".aider*"
+
+// AiderAgent runs tasks using the Aider CLI
+// See: https://aider.chat/docs/scripting.html
+type AiderAgent struct {
+	aiderPath         string
+	model             string // Model Aider runs; empty for Aider's default
+	timeout           time.Duration
+	verbose           bool
+	projectGuidelines string
+	contextManager    *ctxmngr.Manager
+	recentTasks       []*types.Task
+	taskContextCount  int
+	prompts           *PromptTemplates // Templates the prompt comes from; nil for the built-in prompt
+}
+
+// NewAiderAgent creates a new Aider agent
+func NewAiderAgent(aiderPath string, timeout time.Duration) *AiderAgent {
+	return &AiderAgent{
+		aiderPath: aiderPath,
+		timeout:   timeout,
+		verbose:   false,
+	}
+}
+
+// SetModel sets the model Aider runs, e.g. "sonnet" or "gpt-4o"
+func (a *AiderAgent) SetModel(model string) {
+	a.model = model
+}
+
+// SetVerbose enables or disables verbose logging
+func (a *AiderAgent) SetVerbose(v bool) {
+	a.verbose = v
+}
+
+// SetPromptTemplates has the agent's prompts rendered from templates
+func (a *AiderAgent) SetPromptTemplates(prompts *PromptTemplates) {
+	a.prompts = prompts
+}
+
+// SetProjectGuidelines sets project-specific guidelines for the agent
+func (a *AiderAgent) SetProjectGuidelines(guidelines string) {
+	a.projectGuidelines = guidelines
+}
+
+// SetContextManager sets the context window manager for the agent
+func (a *AiderAgent) SetContextManager(manager *ctxmngr.Manager) {
+	a.contextManager = manager
+}
+
+// SetTaskContext sets recent completed tasks for context carrying
+func (a *AiderAgent) SetTaskContext(recentTasks []*types.Task, taskContextCount int) {
+	a.recentTasks = recentTasks
+	a.taskContextCount = taskContextCount
+}
+
+// ExecuteWithContext runs a task with a context and returns the execution result
+func (a *AiderAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *ExecutionResult {
+	// Start telemetry span for agent execution
+	var agentCtx context.Context
+	var span trace.Span
+	if len(parentSpan) > 0 && parentSpan[0] != nil {
+		model := a.model
+		if model == "" {
+			model = "unknown"
+		}
+		agentCtx, span = telemetry.StartAgentSpan(ctx, telemetry.AgentTypeAider, model,
+			attribute.String(telemetry.KeyTaskID, task.ID),
+			attribute.String(telemetry.KeyTaskTitle, task.Title),
+		)
+		defer span.End()
+	} else {
+		agentCtx = ctx
+		span = trace.SpanFromContext(ctx)
+	}
+
+	// Record agent prompt
+	telemetry.RecordAgentPrompt(agentCtx, telemetry.AgentTypeAider)
+
+	// Build the prompt
+	prompt := a.buildPrompt(task)
+
+	// Log what we're sending to Aider (verbose only)
+	if a.verbose {
+		log.Printf("🤖 Sending prompt to Aider (length: %d chars)", len(prompt))
+		log.Printf("📝 Prompt preview: %s", truncateString(prompt, 200))
+	}
+
+	if err := excludeFromCommits(worktreePath, aiderExclude); err != nil && a.verbose {
+		log.Printf("⚠️  Could not keep Aider's files out of git: %v", err)
+	}
+
+	cmd := exec.CommandContext(ctx, a.aiderPath, a.args(prompt)...)
+	cmd.Env = commandEnv(task)
+	detach(cmd)
+	cmd.Dir = worktreePath
+	sandboxCmd(ctx, cmd)
+	cpuLimitCmd(ctx, cmd)
+
+	// Capture output while also streaming to stdout/stderr for real-time viewing
+	output := newRunOutput(ctx, task)
+	cmd.Stdout = output.redact(io.MultiWriter(os.Stdout, output.stdout))
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
+
+	start := time.Now()
+	if a.verbose {
+		log.Printf("⏱️  Aider execution started at %s", start.Format("15:04:05"))
+	}
+	err := cmd.Run()
+	duration := time.Since(start)
+
+	// Combine stdout and stderr for the result
+	output.close()
+	fullOutput := output.String()
+
+	// Log exit code regardless of success/failure
+	if err != nil {
+		exitCode := 1
+		if exitError, ok := err.(*exec.ExitError); ok {
+			exitCode = exitError.ExitCode()
+		}
+		if a.verbose {
+			log.Printf("❌ Aider exited with code %d after %v", exitCode, duration)
+		}
+
+		// Record error
+		telemetry.RecordAgentError(agentCtx, telemetry.AgentTypeAider, "execution_failed")
+
+		if ctx.Err() == context.DeadlineExceeded {
+			telemetry.RecordError(span, err, "TimeoutError", telemetry.ErrorCategoryTimeout)
+			telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeAider, duration)
+			return &ExecutionResult{
+				Success:  false,
+				Output:   fullOutput,
+				Error:    fmt.Errorf("aider timed out after %v", duration),
+				Duration: duration,
+			}
+		}
+		telemetry.RecordError(span, err, "ExecutionError", telemetry.ErrorCategoryAgent)
+		telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeAider, duration)
+		return &ExecutionResult{
+			Success:  false,
+			Output:   fullOutput,
+			Error:    fmt.Errorf("aider exited with code %d after %v: %w", exitCode, duration, err),
+			Duration: duration,
+		}
+	}
+
+	if a.verbose {
+		log.Printf("✅ Aider completed successfully in %v", duration)
+	}
+
+	// Record successful completion
+	telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeAider, duration)
+
+	return readVerdictBlock(&ExecutionResult{
+		Success:  true,
+		Output:   fullOutput,
+		Error:    nil,
+		Duration: duration,
+	}, "")
+}
+
+// args builds the Aider command line for one non-interactive run of prompt.
+// Drover commits the task's work itself, so Aider doesn't commit, and its
+// chat history isn't written into the worktree
+func (a *AiderAgent) args(prompt string) []string {
+	args := []string{
+		"--message", prompt, // Run the prompt, then exit
+		"--yes-always", // Accept every file addition and edit without asking
+		"--no-auto-commits",
+		"--no-dirty-commits",
+		"--no-gitignore", // Leave the project's .gitignore alone
+		"--no-pretty",
+		"--no-check-update",
+		"--chat-history-file", os.DevNull,
+		"--input-history-file", os.DevNull,
+	}
+	if a.model != "" {
+		args = append(args, "--model", a.model)
+	}
+	return args
+}
+
+// CheckInstalled verifies Aider CLI is available
+func (a *AiderAgent) CheckInstalled() error {
+	cmd := exec.Command(a.aiderPath, "--version")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("aider not found at %s: %w\n%s", a.aiderPath, err, output)
+	}
+	return nil
+}
+
+// excludeFromCommits adds a pattern, such as Aider's files, to the
+// repository's info/exclude, so what it matches never ends up in a task's
+// commit
+func excludeFromCommits(worktreePath, pattern string) error {
+	out, err := exec.Command("git", "-C", worktreePath, "rev-parse", "--git-path", "info/exclude").Output()
+	if err != nil {
+		return fmt.Errorf("locating info/exclude: %w", err)
+	}
+	path := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(worktreePath, path)
+	}
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entry := pattern + "\n"
+	if len(existing) > 0 && !strings.HasSuffix(string(existing), "\n") {
+		entry = "\n" + entry
+	}
+	_, err = f.WriteString(entry)
+	return err
+}
+
+// buildPrompt creates the Aider prompt for a task
+func (a *AiderAgent) buildPrompt(task *types.Task) string {
+	if prompt, ok := a.prompts.render(task, a.projectGuidelines, a.recentTasks, a.taskContextCount); ok {
+		return prompt
+	}
+
+	var prompt strings.Builder
+
+	// Start with project guidelines if configured
+	if a.projectGuidelines != "" {
+		prompt.WriteString("=== PROJECT GUIDELINES ===\n")
+		prompt.WriteString(a.projectGuidelines)
+		prompt.WriteString("\n============================\n\n")
+	}
+
+	// Inject recent task context if available
+	if a.taskContextCount > 0 && len(a.recentTasks) > 0 {
+		taskContext := taskcontext.BuildContext(a.recentTasks, task, a.taskContextCount)
+		if taskContext != "" {
+			prompt.WriteString(taskContext)
+		}
+	}
+
+	prompt.WriteString(fmt.Sprintf("Task: %s\n", task.Title))
+
+	if task.Description != "" {
+		prompt.WriteString(fmt.Sprintf("Description: %s\n", task.Description))
+	}
+
+	prompt.WriteString("\nPlease implement this task completely, adding whichever files it needs to the chat.")
+
+	if len(task.EpicID) > 0 {
+		prompt.WriteString(fmt.Sprintf("\n\nThis task is part of epic: %s", task.EpicID))
+	}
+
+	prompt.WriteString("\n\n" + outcome.VerdictInstructions)
+
+	return prompt.String()
+}
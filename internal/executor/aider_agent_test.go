@@ -0,0 +1,87 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// createMockAiderScript creates a script that records its arguments, one per
+// line, and exits with exitCode
+func createMockAiderScript(t *testing.T, dir string, exitCode int) (script, argsFile string) {
+	t.Helper()
+	script = filepath.Join(dir, "mock-aider.sh")
+	argsFile = filepath.Join(dir, "args.txt")
+	content := fmt.Sprintf(`#!/bin/bash
+# Mock Aider script for testing
+printf '%%s\n' "$@" > %s
+echo 'Applied edit to main.go'
+exit %d
+`, argsFile, exitCode)
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to create mock aider script: %v", err)
+	}
+	return script, argsFile
+}
+
+func TestAiderAgent_Execute(t *testing.T) {
+	worktree := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", worktree).CombinedOutput(); err != nil {
+		t.Skipf("git init: %v\n%s", err, out)
+	}
+	script, argsFile := createMockAiderScript(t, t.TempDir(), 0)
+
+	agent := executor.NewAiderAgent(script, time.Minute)
+	agent.SetModel("sonnet")
+	task := &types.Task{ID: "task-1", Title: "Add a health endpoint"}
+
+	result := agent.ExecuteWithContext(context.Background(), worktree, task)
+	if !result.Success {
+		t.Fatalf("Execute failed: %v", result.Error)
+	}
+	if !strings.Contains(result.Output, "Applied edit to main.go") {
+		t.Errorf("Expected Aider's output captured, got %q", result.Output)
+	}
+
+	recorded, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("Reading recorded args: %v", err)
+	}
+	args := string(recorded)
+	for _, want := range []string{"--message\n", "--yes-always\n", "--no-auto-commits\n", "--model\nsonnet\n", "Task: Add a health endpoint"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Expected %q in the arguments, got:\n%s", want, args)
+		}
+	}
+
+	exclude, err := os.ReadFile(filepath.Join(worktree, ".git", "info", "exclude"))
+	if err != nil || !strings.Contains(string(exclude), ".aider*") {
+		t.Errorf("Expected .aider* excluded from git, got %q, %v", exclude, err)
+	}
+	// Running again doesn't add it twice
+	agent.ExecuteWithContext(context.Background(), worktree, task)
+	if exclude, _ := os.ReadFile(filepath.Join(worktree, ".git", "info", "exclude")); strings.Count(string(exclude), ".aider*") != 1 {
+		t.Errorf("Expected .aider* excluded once, got %q", exclude)
+	}
+}
+
+func TestAiderAgent_ExecuteFailure(t *testing.T) {
+	script, _ := createMockAiderScript(t, t.TempDir(), 2)
+
+	agent := executor.NewAiderAgent(script, time.Minute)
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1", Title: "Break things"})
+	if result.Success || result.Error == nil {
+		t.Fatal("Expected a failed result for a non-zero exit")
+	}
+	if !strings.Contains(result.Error.Error(), "exited with code 2") {
+		t.Errorf("Expected the exit code in the error, got %v", result.Error)
+	}
+}
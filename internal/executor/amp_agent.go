@@ -12,6 +12,7 @@ import (
 	"time"
 
 	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/outcome"
 	"github.com/cloud-shuttle/drover/internal/taskcontext"
 	"github.com/cloud-shuttle/drover/pkg/telemetry"
 	"github.com/cloud-shuttle/drover/pkg/types"
@@ -25,10 +26,12 @@ type AmpAgent struct {
 	ampPath           string
 	timeout           time.Duration
 	verbose           bool
+	streamEvents      bool // Run with --stream-json, broadcasting each event to the dashboard
 	projectGuidelines string
 	contextManager    *ctxmngr.Manager
 	recentTasks       []*types.Task
 	taskContextCount  int
+	prompts           *PromptTemplates // Templates the prompt comes from; nil for the built-in prompt
 }
 
 // NewAmpAgent creates a new Amp agent
@@ -45,6 +48,17 @@ func (a *AmpAgent) SetVerbose(v bool) {
 	a.verbose = v
 }
 
+// SetStreamEvents makes Amp report its work as JSON events, which are
+// broadcast to the dashboard as they arrive
+func (a *AmpAgent) SetStreamEvents(v bool) {
+	a.streamEvents = v
+}
+
+// SetPromptTemplates has the agent's prompts rendered from templates
+func (a *AmpAgent) SetPromptTemplates(prompts *PromptTemplates) {
+	a.prompts = prompts
+}
+
 // SetProjectGuidelines sets project-specific guidelines for the agent
 func (a *AmpAgent) SetProjectGuidelines(guidelines string) {
 	a.projectGuidelines = guidelines
@@ -95,16 +109,28 @@ func (a *AmpAgent) ExecuteWithContext(ctx context.Context, worktreePath string,
 	args := []string{
 		"-x", // --execute: run in execute mode (non-interactive)
 		"--dangerously-allow-all",
-		prompt,
 	}
+	if a.streamEvents {
+		args = append(args, "--stream-json")
+	}
+	args = append(args, prompt)
 
 	cmd := exec.CommandContext(ctx, a.ampPath, args...)
+	cmd.Env = commandEnv(task)
+	detach(cmd)
 	cmd.Dir = worktreePath
+	sandboxCmd(ctx, cmd)
+	cpuLimitCmd(ctx, cmd)
 
 	// Capture output while also streaming to stdout/stderr for real-time viewing
-	var outputBuf, errBuf strings.Builder
-	cmd.Stdout = io.MultiWriter(os.Stdout, &outputBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
+	output := newRunOutput(ctx, task)
+	cmd.Stdout = output.redact(io.MultiWriter(os.Stdout, output.stdout))
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
+	var events *eventWriter
+	if a.streamEvents {
+		events = newEventWriter(cmd.Stdout, task.ID, telemetry.AgentTypeAmp)
+		cmd.Stdout = events
+	}
 
 	start := time.Now()
 	if a.verbose {
@@ -112,9 +138,13 @@ func (a *AmpAgent) ExecuteWithContext(ctx context.Context, worktreePath string,
 	}
 	err := cmd.Run()
 	duration := time.Since(start)
+	if events != nil {
+		events.Flush()
+	}
 
 	// Combine stdout and stderr for the result
-	fullOutput := outputBuf.String() + errBuf.String()
+	output.close()
+	fullOutput := output.String()
 
 	// Log exit code regardless of success/failure
 	if err != nil {
@@ -154,12 +184,12 @@ func (a *AmpAgent) ExecuteWithContext(ctx context.Context, worktreePath string,
 	// Record successful completion
 	telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeAmp, duration)
 
-	return &ExecutionResult{
+	return readVerdictBlock(&ExecutionResult{
 		Success: true,
 		Output:  fullOutput,
 		Error:   nil,
 		Duration: duration,
-	}
+	}, "")
 }
 
 // CheckInstalled verifies Amp CLI is available
@@ -174,6 +204,10 @@ func (a *AmpAgent) CheckInstalled() error {
 
 // buildPrompt creates the Amp prompt for a task
 func (a *AmpAgent) buildPrompt(task *types.Task) string {
+	if prompt, ok := a.prompts.render(task, a.projectGuidelines, a.recentTasks, a.taskContextCount); ok {
+		return prompt
+	}
+
 	var prompt strings.Builder
 
 	// Start with project guidelines if configured
@@ -203,5 +237,7 @@ func (a *AmpAgent) buildPrompt(task *types.Task) string {
 		prompt.WriteString(fmt.Sprintf("\n\nThis task is part of epic: %s", task.EpicID))
 	}
 
+	prompt.WriteString("\n\n" + outcome.VerdictInstructions)
+
 	return prompt.String()
 }
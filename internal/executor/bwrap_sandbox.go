@@ -0,0 +1,196 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// bwrapSystemDirs are mounted read-only so programs in the sandbox run; the
+// ones a system lacks are skipped
+var bwrapSystemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/nix/store"}
+
+// bwrapBaseEnv are the host variables a sandboxed process keeps besides the
+// agents' API keys; everything else, credentials included, stays outside
+var bwrapBaseEnv = []string{"PATH", "HOME", "USER", "LOGNAME", "LANG", "LC_ALL", "TERM", "TZ", "TMPDIR", "SSL_CERT_FILE", "SSL_CERT_DIR"}
+
+// BwrapSandboxConfig is the policy of a bubblewrap sandbox
+type BwrapSandboxConfig struct {
+	// Host paths the agent may read, and read and write, besides the task's
+	// worktree and caches; a leading ~ is the home directory
+	ReadOnly  []string
+	ReadWrite []string
+
+	// Host variables passed in besides the agents' API keys
+	Env []string
+
+	// NoNetwork runs the agent without network access
+	NoNetwork bool
+
+	// Bwrap is the bubblewrap program to run (empty = "bwrap" on the PATH)
+	Bwrap string
+}
+
+// BwrapSandbox runs each agent process under bubblewrap, for hosts without
+// Docker. The process sees the system's programs and libraries read-only, an
+// empty home directory and /tmp, and only the task's worktree, its git
+// directory and caches of the host's files, so dotfiles and credentials are
+// out of reach
+type BwrapSandbox struct {
+	cfg       BwrapSandboxConfig
+	bwrap     string
+	readOnly  []string
+	readWrite []string
+}
+
+// NewBwrapSandbox creates a bubblewrap sandbox, checking its policy
+func NewBwrapSandbox(cfg BwrapSandboxConfig) (*BwrapSandbox, error) {
+	s := &BwrapSandbox{cfg: cfg, bwrap: cfg.Bwrap}
+	if s.bwrap == "" {
+		s.bwrap = "bwrap"
+	}
+	var err error
+	if s.readOnly, err = sandboxPaths(cfg.ReadOnly); err != nil {
+		return nil, err
+	}
+	if s.readWrite, err = sandboxPaths(cfg.ReadWrite); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// sandboxPaths expands a leading ~ in paths, which must then be absolute
+func sandboxPaths(paths []string) ([]string, error) {
+	home, _ := os.UserHomeDir()
+	expanded := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if home != "" && (path == "~" || strings.HasPrefix(path, "~/")) {
+			path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+		}
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("sandbox path %q must be absolute or start with ~/", path)
+		}
+		expanded = append(expanded, filepath.Clean(path))
+	}
+	return expanded, nil
+}
+
+// Check verifies bubblewrap is installed and can create a sandbox, which
+// takes unprivileged user namespaces
+func (s *BwrapSandbox) Check() error {
+	out, err := exec.Command(s.bwrap, "--ro-bind", "/", "/", "--", "true").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("bwrap not usable at %s: %w\n%s", s.bwrap, err, out)
+	}
+	return nil
+}
+
+// Wrap rewrites cmd into a bwrap run of it
+func (s *BwrapSandbox) Wrap(cmd *exec.Cmd, worktreePath string) error {
+	bwrap, err := exec.LookPath(s.bwrap)
+	if err != nil {
+		return fmt.Errorf("bwrap not found: %w", err)
+	}
+	if cmd.Err != nil {
+		// Unlike a container, the sandbox runs the host's own program
+		return cmd.Err
+	}
+	program, err := filepath.Abs(cmd.Path)
+	if err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(worktreePath)
+	if err != nil {
+		return err
+	}
+	workdir := dir
+	if cmd.Dir != "" {
+		if workdir, err = filepath.Abs(cmd.Dir); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"--die-with-parent", "--unshare-all"}
+	if !s.cfg.NoNetwork {
+		args = append(args, "--share-net")
+	}
+	for _, path := range bwrapSystemDirs {
+		args = append(args, "--ro-bind-try", path, path)
+	}
+	args = append(args, "--proc", "/proc", "--dev", "/dev", "--tmpfs", "/tmp")
+	// Binds below land on top of the empty home, so the worktree can live in it
+	if home, err := os.UserHomeDir(); err == nil {
+		args = append(args, "--tmpfs", home)
+	}
+
+	// The agent's own program, wherever it's installed
+	if resolved, err := filepath.EvalSymlinks(program); err == nil {
+		args = append(args, "--ro-bind", filepath.Dir(resolved), filepath.Dir(resolved))
+		if filepath.Dir(program) != filepath.Dir(resolved) {
+			args = append(args, "--ro-bind", program, program)
+		}
+	}
+	for _, path := range s.readOnly {
+		args = append(args, "--ro-bind-try", path, path)
+	}
+	for _, file := range tempFileArgs(cmd.Args[1:]) {
+		args = append(args, "--ro-bind", file, file)
+	}
+
+	args = append(args, "--bind", dir, dir)
+	// A worktree's git metadata lives in the main repository. It is read-only,
+	// so the agent can't plant hooks or config that drover's git runs on the host
+	if common := gitCommonDir(dir); common != "" && !within(common, dir) {
+		args = append(args, "--ro-bind", common, common)
+	}
+	env := s.env(cmd.Env)
+	for _, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		if sandboxCacheEnv[key] && filepath.IsAbs(value) {
+			args = append(args, "--bind-try", value, value)
+		}
+	}
+	for _, path := range s.readWrite {
+		args = append(args, "--bind-try", path, path)
+	}
+	args = append(args, "--chdir", workdir, "--", program)
+	args = append(args, cmd.Args[1:]...)
+
+	cmd.Path = bwrap
+	cmd.Args = append([]string{bwrap}, args...)
+	cmd.Env = env
+	return nil
+}
+
+// env is the environment a sandboxed process keeps of env, or of drover's
+// own when env is nil: the basics, the agents' API keys, the variables the
+// policy names and the task's own, but nothing else of the host's
+func (s *BwrapSandbox) env(env []string) []string {
+	own := os.Environ()
+	if env == nil {
+		env = own
+	}
+	keep := make(map[string]bool)
+	for _, key := range bwrapBaseEnv {
+		keep[key] = true
+	}
+	for _, key := range sandboxPassEnv {
+		keep[key] = true
+	}
+	for _, key := range s.cfg.Env {
+		keep[key] = true
+	}
+	for _, entry := range extraEnv(env, own) {
+		key, _, _ := strings.Cut(entry, "=")
+		keep[key] = true
+	}
+	kept := []string{}
+	for _, entry := range env {
+		if key, _, ok := strings.Cut(entry, "="); ok && keep[key] {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
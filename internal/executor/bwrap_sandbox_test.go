@@ -0,0 +1,92 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestBwrapSandbox_RestrictsView(t *testing.T) {
+	dir := t.TempDir()
+	// The mock bwrap prints its arguments, one per line, and the environment
+	// it was given
+	bwrap := filepath.Join(dir, "bwrap")
+	if err := os.WriteFile(bwrap, []byte("#!/bin/bash\nprintf '%s\\n' \"$@\"\necho ---\nenv\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tool := filepath.Join(dir, "tool.sh")
+	if err := os.WriteFile(tool, []byte("#!/bin/bash\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ANTHROPIC_API_KEY", "sk-test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "host-only")
+	t.Setenv("NPM_TOKEN", "npm-test")
+
+	sandbox, err := executor.NewBwrapSandbox(executor.BwrapSandboxConfig{
+		ReadOnly:  []string{"/opt/toolchain"},
+		ReadWrite: []string{"~/.claude"},
+		Env:       []string{"NPM_TOKEN"},
+		NoNetwork: true,
+		Bwrap:     bwrap,
+	})
+	if err != nil {
+		t.Fatalf("NewBwrapSandbox: %v", err)
+	}
+	custom, err := executor.NewCustomAgent(executor.CustomAgentConfig{Command: tool + " --prompt {{.PromptFile}}"}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewCustomAgent: %v", err)
+	}
+	agent := executor.NewSandboxedAgent(custom, sandbox)
+
+	worktree := t.TempDir()
+	task := &types.Task{
+		ID:               "task-1",
+		Title:            "Add a health endpoint",
+		ExecutionContext: &types.TaskExecutionContext{Env: []string{"FEATURE_FLAG=on"}},
+	}
+	result := agent.ExecuteWithContext(context.Background(), worktree, task)
+	if !result.Success {
+		t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+	}
+
+	argOutput, envOutput, _ := strings.Cut(result.Output, "---\n")
+	args := strings.Join(strings.Split(strings.TrimSpace(argOutput), "\n"), " ")
+	home, _ := os.UserHomeDir()
+	for _, want := range []string{
+		"--die-with-parent --unshare-all --ro-bind-try /usr /usr",
+		"--tmpfs /tmp",
+		"--tmpfs " + home,
+		"--ro-bind-try /opt/toolchain /opt/toolchain",
+		"--bind " + worktree + " " + worktree,
+		"--bind-try " + filepath.Join(home, ".claude"),
+		"--chdir " + worktree + " -- " + tool + " --prompt",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Expected %q in the bwrap command, got: %s", want, args)
+		}
+	}
+	if strings.Contains(args, "--share-net") {
+		t.Errorf("Expected no network with no_network, got: %s", args)
+	}
+
+	for _, want := range []string{"ANTHROPIC_API_KEY=sk-test", "NPM_TOKEN=npm-test", "FEATURE_FLAG=on", "PATH="} {
+		if !strings.Contains(envOutput, want) {
+			t.Errorf("Expected %s in the sandbox environment, got:\n%s", want, envOutput)
+		}
+	}
+	if strings.Contains(envOutput, "AWS_SECRET_ACCESS_KEY") {
+		t.Errorf("Expected host credentials kept out of the sandbox, got:\n%s", envOutput)
+	}
+}
+
+func TestNewBwrapSandbox_RelativePath(t *testing.T) {
+	if _, err := executor.NewBwrapSandbox(executor.BwrapSandboxConfig{ReadOnly: []string{"relative/dir"}}); err == nil {
+		t.Error("Expected an error for a relative policy path")
+	}
+}
@@ -30,6 +30,47 @@ type ExecutionResult struct {
 	WorkerPID    int   `json:"worker_pid,omitempty"`    // PID of the worker process
 	PeakRSSBytes int64 `json:"peak_rss_bytes,omitempty"` // Peak RSS during execution
 	FinalRSSBytes int64 `json:"final_rss_bytes,omitempty"` // Final RSS at completion
+
+	// Usage the agent reported for this execution; zero when it reports none
+	InputTokens  int64   `json:"input_tokens,omitempty"`
+	OutputTokens int64   `json:"output_tokens,omitempty"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+
+	// Verdict the agent reported on its own work and why; empty when it
+	// reports none, leaving the outcome to the exit status
+	Verdict       types.TaskVerdict `json:"verdict,omitempty"`
+	VerdictReason string            `json:"verdict_reason,omitempty"`
+
+	// Files the agent reported changing and the follow-up work it suggests,
+	// from the verdict block its reply ends with
+	FilesChanged []string `json:"files_changed,omitempty"`
+	Followups    []string `json:"followups,omitempty"`
+
+	// SessionID is the agent session the execution ran in, which a retry
+	// can resume; empty when the agent doesn't report one
+	SessionID string `json:"session_id,omitempty"`
+
+	// Model is the agent and model that produced the result when a task can
+	// fall back to others, e.g. "claude/haiku"; empty otherwise
+	Model string `json:"model,omitempty"`
+
+	// OutputTruncated is set when the agent's output went over the output
+	// limit, and Output keeps only its start and end; OutputLog is then the
+	// file with all of it, empty when it couldn't be written
+	OutputTruncated bool   `json:"output_truncated,omitempty"`
+	OutputLog       string `json:"output_log,omitempty"`
+
+	// GuidanceDelivered is the guidance from the task's guidance file the
+	// agent was given while it ran, by ID
+	GuidanceDelivered []string `json:"guidance_delivered,omitempty"`
+}
+
+// AddUsage adds the usage of an earlier execution of the same task, such as
+// the run a diagnostics fix-it round follows up on
+func (r *ExecutionResult) AddUsage(earlier *ExecutionResult) {
+	r.InputTokens += earlier.InputTokens
+	r.OutputTokens += earlier.OutputTokens
+	r.CostUSD += earlier.CostUSD
 }
 
 // Executor runs tasks using Claude Code
@@ -66,12 +107,15 @@ func (e *Executor) Execute(worktreePath string, task *types.Task) *ExecutionResu
 
 	// Capture output while also streaming to stdout/stderr
 	var outputBuf, errBuf strings.Builder
-	cmd.Stdout = io.MultiWriter(os.Stdout, &outputBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
+	stdout := newRedactingWriter(io.MultiWriter(os.Stdout, &outputBuf), taskSecrets(task))
+	stderr := newRedactingWriter(io.MultiWriter(os.Stderr, &errBuf), taskSecrets(task))
+	cmd.Stdout, cmd.Stderr = stdout, stderr
 
 	start := time.Now()
 	err := cmd.Run()
 	duration := time.Since(start)
+	stdout.flush()
+	stderr.flush()
 
 	// Combine stdout and stderr for the result
 	fullOutput := outputBuf.String() + errBuf.String()
@@ -152,12 +196,14 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, worktreePath string,
 	// Use -p for non-interactive mode and pass prompt as argument
 	// Add --dangerously-skip-permissions to avoid hanging on permission prompts
 	cmd := exec.CommandContext(ctx, e.claudePath, "-p", prompt, "--dangerously-skip-permissions")
+	cmd.Env = commandEnv(task)
+	detach(cmd)
 	cmd.Dir = worktreePath
 
 	// Capture output while also streaming to stdout/stderr for real-time viewing
-	var outputBuf, errBuf strings.Builder
-	cmd.Stdout = io.MultiWriter(os.Stdout, &outputBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
+	output := newRunOutput(ctx, task)
+	cmd.Stdout = output.redact(io.MultiWriter(os.Stdout, output.stdout))
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
 
 	start := time.Now()
 	if e.verbose {
@@ -165,9 +211,10 @@ func (e *Executor) ExecuteWithContext(ctx context.Context, worktreePath string,
 	}
 	err := cmd.Run()
 	duration := time.Since(start)
+	output.close()
 
 	// Combine stdout and stderr for the result
-	fullOutput := outputBuf.String() + errBuf.String()
+	fullOutput := output.String()
 
 	// Log exit code regardless of success/failure
 	if err != nil {
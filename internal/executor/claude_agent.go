@@ -2,7 +2,9 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -12,6 +14,7 @@ import (
 	"time"
 
 	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/outcome"
 	"github.com/cloud-shuttle/drover/internal/taskcontext"
 	"github.com/cloud-shuttle/drover/pkg/telemetry"
 	"github.com/cloud-shuttle/drover/pkg/types"
@@ -22,12 +25,16 @@ import (
 // ClaudeAgent runs tasks using Claude Code CLI
 type ClaudeAgent struct {
 	claudePath        string
+	model             string // Model Claude runs, e.g. "sonnet"; empty = Claude's default
 	timeout           time.Duration
 	verbose           bool
+	streamEvents      bool // Run with stream-json output, broadcasting each event to the dashboard
+	resume            bool // Resume the previous attempt's session on a retry
 	projectGuidelines string
 	contextManager    *ctxmngr.Manager
 	recentTasks       []*types.Task
 	taskContextCount  int
+	prompts           *PromptTemplates // Templates the prompt comes from; nil for the built-in prompt
 }
 
 // NewClaudeAgent creates a new Claude Code agent
@@ -39,11 +46,34 @@ func NewClaudeAgent(claudePath string, timeout time.Duration) *ClaudeAgent {
 	}
 }
 
+// SetModel sets the model Claude runs, e.g. "sonnet" or "haiku"
+func (a *ClaudeAgent) SetModel(model string) {
+	a.model = model
+}
+
 // SetVerbose enables or disables verbose logging
 func (a *ClaudeAgent) SetVerbose(v bool) {
 	a.verbose = v
 }
 
+// SetStreamEvents makes Claude report its work as JSON events, which are
+// broadcast to the dashboard as they arrive
+func (a *ClaudeAgent) SetStreamEvents(v bool) {
+	a.streamEvents = v
+}
+
+// SetResume makes a retry resume the Claude session of the task's previous
+// attempt, so Claude remembers what it already tried. Sessions are read from
+// Claude's stream-json output, which this turns on
+func (a *ClaudeAgent) SetResume(v bool) {
+	a.resume = v
+}
+
+// SetPromptTemplates has the agent's prompts rendered from templates
+func (a *ClaudeAgent) SetPromptTemplates(prompts *PromptTemplates) {
+	a.prompts = prompts
+}
+
 // SetProjectGuidelines sets project-specific guidelines for the agent
 func (a *ClaudeAgent) SetProjectGuidelines(guidelines string) {
 	a.projectGuidelines = guidelines
@@ -88,16 +118,65 @@ func (a *ClaudeAgent) ExecuteWithContext(ctx context.Context, worktreePath strin
 		log.Printf("📝 Prompt preview: %s", truncateString(prompt, 200))
 	}
 
+	resumeID := ""
+	if a.resume && task.ExecutionContext != nil {
+		resumeID = task.ExecutionContext.ResumeSession
+	}
+	result := a.run(ctx, agentCtx, span, worktreePath, task, prompt, resumeID)
+	// Claude keeps sessions per directory and for a limited time; one it no
+	// longer has leaves a fresh session as the only way on
+	if resumeID != "" && !result.Success && strings.Contains(result.Output, "No conversation found") {
+		log.Printf("⚠️  Claude session %s of task %s is gone, starting a new one", resumeID, task.ID)
+		fresh := a.run(ctx, agentCtx, span, worktreePath, task, prompt, "")
+		fresh.AddUsage(result)
+		result = fresh
+	}
+	return result
+}
+
+// run executes Claude once, resuming sessionID when set
+func (a *ClaudeAgent) run(ctx, agentCtx context.Context, span trace.Span, worktreePath string, task *types.Task, prompt, sessionID string) *ExecutionResult {
 	// Run Claude Code with prompt as positional argument in print mode
 	// Use -p for non-interactive mode and pass prompt as argument
 	// Add --dangerously-skip-permissions to avoid hanging on permission prompts
-	cmd := exec.CommandContext(ctx, a.claudePath, "-p", prompt, "--dangerously-skip-permissions")
+	args := []string{"-p", prompt, "--dangerously-skip-permissions"}
+	if a.model != "" {
+		args = append(args, "--model", a.model)
+	}
+	if sessionID != "" {
+		args = append(args, "--resume", sessionID)
+		if a.verbose {
+			log.Printf("🔁 Resuming Claude session %s", sessionID)
+		}
+	}
+	if task.ExecutionContext != nil && task.ExecutionContext.MCPConfig != "" {
+		args = append(args, "--mcp-config", task.ExecutionContext.MCPConfig)
+	}
+	streamEvents := a.streamEvents || a.resume
+	if streamEvents {
+		args = append(args, "--output-format", "stream-json", "--verbose")
+	} else {
+		// One JSON object once Claude is done: its reply and what the run used
+		args = append(args, "--output-format", "json")
+	}
+	cmd := exec.CommandContext(ctx, a.claudePath, args...)
+	cmd.Env = commandEnv(task)
+	detach(cmd)
 	cmd.Dir = worktreePath
+	sandboxCmd(ctx, cmd)
+	cpuLimitCmd(ctx, cmd)
 
 	// Capture output while also streaming to stdout/stderr for real-time viewing
-	var outputBuf, errBuf strings.Builder
-	cmd.Stdout = io.MultiWriter(os.Stdout, &outputBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
+	output := newRunOutput(ctx, task)
+	cmd.Stdout = output.redact(output.stdout)
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
+	var events *eventWriter
+	var run claudeRun
+	if streamEvents {
+		events = newEventWriter(output.redact(io.MultiWriter(os.Stdout, output.stdout)), task.ID, telemetry.AgentTypeClaudeCode)
+		events.parse = run.parse
+		cmd.Stdout = events
+	}
 
 	start := time.Now()
 	if a.verbose {
@@ -105,9 +184,19 @@ func (a *ClaudeAgent) ExecuteWithContext(ctx context.Context, worktreePath strin
 	}
 	err := cmd.Run()
 	duration := time.Since(start)
+	if events != nil {
+		events.Flush()
+	}
+	output.close()
+	stdout := output.stdout.String()
+	if events == nil {
+		// The reply reads as Claude's plain text output would
+		stdout = run.readReply(stdout)
+		io.WriteString(os.Stdout, stdout)
+	}
 
 	// Combine stdout and stderr for the result
-	fullOutput := outputBuf.String() + errBuf.String()
+	fullOutput := stdout + output.stderr.String()
 
 	// Log exit code regardless of success/failure
 	if err != nil {
@@ -125,19 +214,19 @@ func (a *ClaudeAgent) ExecuteWithContext(ctx context.Context, worktreePath strin
 		if ctx.Err() == context.DeadlineExceeded {
 			telemetry.RecordError(span, err, "TimeoutError", telemetry.ErrorCategoryTimeout)
 			telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeClaudeCode, duration)
-			return &ExecutionResult{
+			return run.result(&ExecutionResult{
 				Success: false,
 				Output:  fullOutput,
 				Error:   fmt.Errorf("claude timed out after %v", duration),
-			}
+			})
 		}
 		telemetry.RecordError(span, err, "ExecutionError", telemetry.ErrorCategoryAgent)
 		telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeClaudeCode, duration)
-		return &ExecutionResult{
+		return run.result(&ExecutionResult{
 			Success: false,
 			Output:  fullOutput,
 			Error:   fmt.Errorf("claude failed after %v: %w", duration, err),
-		}
+		})
 	}
 
 	if a.verbose {
@@ -147,12 +236,96 @@ func (a *ClaudeAgent) ExecuteWithContext(ctx context.Context, worktreePath strin
 	// Record successful completion
 	telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeClaudeCode, duration)
 
-	return &ExecutionResult{
-		Success: true,
-		Output:  fullOutput,
-		Error:   nil,
+	return run.result(&ExecutionResult{
+		Success:  true,
+		Output:   fullOutput,
+		Error:    nil,
 		Duration: duration,
+	})
+}
+
+// claudeResult is the closing event of Claude's stream-json output, and the
+// whole of its json output: the reply, the session and what the run used
+type claudeResult struct {
+	Type         string  `json:"type"`
+	Subtype      string  `json:"subtype"`
+	IsError      bool    `json:"is_error"`
+	Result       string  `json:"result"`
+	SessionID    string  `json:"session_id"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	Usage        struct {
+		InputTokens              int64 `json:"input_tokens"`
+		CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		OutputTokens             int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// claudeRun collects what Claude's JSON output reports about a run: the
+// session it ran in, for a retry to resume, its reply and its token usage
+// and cost
+type claudeRun struct {
+	sessionID    string
+	reply        string
+	inputTokens  int64
+	outputTokens int64
+	costUSD      float64
+}
+
+// read records what a line of Claude's JSON output reports; ok is false
+// when the line isn't its closing result
+func (r *claudeRun) read(line []byte) (result *claudeResult, ok bool) {
+	if err := json.Unmarshal(bytes.TrimSpace(line), &result); err != nil || result == nil {
+		return nil, false
+	}
+	if result.SessionID != "" {
+		r.sessionID = result.SessionID
+	}
+	if result.Type != "result" {
+		return nil, false
+	}
+	r.reply = result.Result
+	// Cached prompt tokens are still tokens sent
+	u := result.Usage
+	r.inputTokens += u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	r.outputTokens += u.OutputTokens
+	r.costUSD += result.TotalCostUSD
+	return result, true
+}
+
+// parse reads a line of Claude's stream-json output for an eventWriter
+func (r *claudeRun) parse(line []byte) ([]agentEvent, bool) {
+	events, ok := parseAgentEvents(line)
+	if !ok {
+		return nil, false
+	}
+	r.read(line)
+	return events, true
+}
+
+// readReply reads Claude's json output, returning the reply in it. Output
+// that isn't Claude's JSON, such as an error it printed instead, is returned
+// as it is
+func (r *claudeRun) readReply(stdout string) string {
+	result, ok := r.read([]byte(stdout))
+	if !ok {
+		return stdout
+	}
+	reply := strings.TrimSpace(result.Result)
+	if reply == "" && result.IsError {
+		reply = "Claude ended with " + result.Subtype
 	}
+	return reply + "\n"
+}
+
+// result adds what the run reported to an execution result, including the
+// verdict block its reply ends with
+func (r *claudeRun) result(result *ExecutionResult) *ExecutionResult {
+	result.SessionID = r.sessionID
+	result.InputTokens = r.inputTokens
+	result.OutputTokens = r.outputTokens
+	result.CostUSD = r.costUSD
+	return readVerdictBlock(result, r.reply)
 }
 
 // CheckInstalled verifies Claude Code is available
@@ -167,6 +340,10 @@ func (a *ClaudeAgent) CheckInstalled() error {
 
 // buildPrompt creates the Claude prompt for a task
 func (a *ClaudeAgent) buildPrompt(task *types.Task) string {
+	if prompt, ok := a.prompts.render(task, a.projectGuidelines, a.recentTasks, a.taskContextCount); ok {
+		return prompt
+	}
+
 	var prompt strings.Builder
 
 	// Start with project guidelines if configured
@@ -218,5 +395,7 @@ func (a *ClaudeAgent) buildPrompt(task *types.Task) string {
 		prompt.WriteString(fmt.Sprintf("\n\nThis task is part of epic: %s", task.EpicID))
 	}
 
+	prompt.WriteString("\n\n" + outcome.VerdictInstructions)
+
 	return prompt.String()
 }
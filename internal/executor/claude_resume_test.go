@@ -0,0 +1,116 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// createResumableClaudeScript creates a mock Claude that logs its arguments,
+// one run per line, streams a session's events and knows only the session
+// "known"
+func createResumableClaudeScript(t *testing.T, dir string) (script, argsLog string) {
+	t.Helper()
+	argsLog = filepath.Join(dir, "args.log")
+	script = filepath.Join(dir, "mock-claude.sh")
+	content := `#!/bin/bash
+echo "$*" | tr '\n' ' ' >> ` + argsLog + `
+echo >> ` + argsLog + `
+session=new-session
+while [ $# -gt 0 ]; do
+  if [ "$1" = "--resume" ]; then
+    if [ "$2" != "known" ]; then
+      echo "No conversation found with session ID: $2" >&2
+      exit 1
+    fi
+    session=$2
+  fi
+  shift
+done
+echo '{"type":"system","subtype":"init","session_id":"'$session'"}'
+echo '{"type":"assistant","session_id":"'$session'","message":{"content":[{"type":"text","text":"Done."}]}}'
+echo '{"type":"result","subtype":"success","is_error":false,"result":"Done.","session_id":"'$session'"}'
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to create mock claude script: %v", err)
+	}
+	return script, argsLog
+}
+
+func TestClaudeAgent_ResumesSession(t *testing.T) {
+	dir := t.TempDir()
+	script, argsLog := createResumableClaudeScript(t, dir)
+
+	agent := executor.NewClaudeAgent(script, time.Minute)
+	agent.SetResume(true)
+	task := &types.Task{
+		ID:               "task-1",
+		Title:            "Fix the build",
+		ExecutionContext: &types.TaskExecutionContext{ResumeSession: "known"},
+	}
+	result := agent.ExecuteWithContext(context.Background(), dir, task)
+	if !result.Success {
+		t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+	}
+	if result.SessionID != "known" {
+		t.Errorf("SessionID = %q, want the resumed session", result.SessionID)
+	}
+	if strings.Contains(result.Output, `"type"`) {
+		t.Errorf("Expected plain text output, got:\n%s", result.Output)
+	}
+	args, _ := os.ReadFile(argsLog)
+	if !strings.Contains(string(args), "--resume known") || !strings.Contains(string(args), "stream-json") {
+		t.Errorf("Expected a stream-json run resuming the session, got: %s", args)
+	}
+}
+
+func TestClaudeAgent_StartsOverWhenSessionIsGone(t *testing.T) {
+	dir := t.TempDir()
+	script, argsLog := createResumableClaudeScript(t, dir)
+
+	agent := executor.NewClaudeAgent(script, time.Minute)
+	agent.SetResume(true)
+	task := &types.Task{
+		ID:               "task-1",
+		Title:            "Fix the build",
+		ExecutionContext: &types.TaskExecutionContext{ResumeSession: "expired"},
+	}
+	result := agent.ExecuteWithContext(context.Background(), dir, task)
+	if !result.Success {
+		t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+	}
+	if result.SessionID != "new-session" {
+		t.Errorf("SessionID = %q, want the new session", result.SessionID)
+	}
+	args, _ := os.ReadFile(argsLog)
+	runs := strings.Split(strings.TrimSpace(string(args)), "\n")
+	if len(runs) != 2 || strings.Contains(runs[1], "--resume") {
+		t.Errorf("Expected a second run without --resume, got:\n%s", args)
+	}
+}
+
+func TestClaudeAgent_ResumeOff(t *testing.T) {
+	dir := t.TempDir()
+	script, argsLog := createResumableClaudeScript(t, dir)
+
+	agent := executor.NewClaudeAgent(script, time.Minute)
+	task := &types.Task{
+		ID:               "task-1",
+		Title:            "Fix the build",
+		ExecutionContext: &types.TaskExecutionContext{ResumeSession: "known"},
+	}
+	result := agent.ExecuteWithContext(context.Background(), dir, task)
+	if !result.Success {
+		t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+	}
+	args, _ := os.ReadFile(argsLog)
+	if strings.Contains(string(args), "--resume") {
+		t.Errorf("Expected no --resume without SetResume, got: %s", args)
+	}
+}
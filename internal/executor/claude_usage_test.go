@@ -0,0 +1,59 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// createUsageClaudeScript creates a mock Claude that reports its usage in
+// whichever JSON output format it's asked for
+func createUsageClaudeScript(t *testing.T, dir string) string {
+	t.Helper()
+	script := filepath.Join(dir, "mock-claude.sh")
+	content := `#!/bin/bash
+result='{"type":"result","subtype":"success","is_error":false,"result":"Added the endpoint.","session_id":"s-1","total_cost_usd":0.37,"usage":{"input_tokens":120,"cache_creation_input_tokens":2000,"cache_read_input_tokens":30000,"output_tokens":1500}}'
+case "$*" in
+  *stream-json*)
+    echo '{"type":"assistant","session_id":"s-1","message":{"content":[{"type":"text","text":"Adding the endpoint."}]}}'
+    echo "$result"
+    ;;
+  *"--output-format json"*)
+    echo "$result"
+    ;;
+  *)
+    echo "Added the endpoint."
+    ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to create mock claude script: %v", err)
+	}
+	return script
+}
+
+func TestClaudeAgent_Usage(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		dir := t.TempDir()
+		agent := executor.NewClaudeAgent(createUsageClaudeScript(t, dir), time.Minute)
+		agent.SetStreamEvents(stream)
+
+		result := agent.ExecuteWithContext(context.Background(), dir, &types.Task{ID: "task-1", Title: "Add an endpoint"})
+		if !result.Success {
+			t.Fatalf("stream=%v: Execute failed: %v\n%s", stream, result.Error, result.Output)
+		}
+		if result.InputTokens != 32120 || result.OutputTokens != 1500 || result.CostUSD != 0.37 {
+			t.Errorf("stream=%v: usage = %d in, %d out, $%v; want 32120 in, 1500 out, $0.37",
+				stream, result.InputTokens, result.OutputTokens, result.CostUSD)
+		}
+		if strings.Contains(result.Output, `"type"`) || !strings.Contains(result.Output, "endpoint") {
+			t.Errorf("stream=%v: expected Claude's reply as plain text, got:\n%s", stream, result.Output)
+		}
+	}
+}
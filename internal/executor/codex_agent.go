@@ -12,6 +12,7 @@ import (
 	"time"
 
 	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/outcome"
 	"github.com/cloud-shuttle/drover/internal/taskcontext"
 	"github.com/cloud-shuttle/drover/pkg/telemetry"
 	"github.com/cloud-shuttle/drover/pkg/types"
@@ -23,12 +24,15 @@ import (
 // See: https://developers.openai.com/codex/cli/
 type CodexAgent struct {
 	codexPath        string
+	model             string // Model Codex runs, e.g. "gpt-5-codex"; empty = Codex's default
 	timeout           time.Duration
 	verbose           bool
+	streamEvents      bool // Run with --json, broadcasting each event to the dashboard
 	projectGuidelines string
 	contextManager    *ctxmngr.Manager
 	recentTasks       []*types.Task
 	taskContextCount  int
+	prompts           *PromptTemplates // Templates the prompt comes from; nil for the built-in prompt
 }
 
 // NewCodexAgent creates a new Codex agent
@@ -40,11 +44,27 @@ func NewCodexAgent(codexPath string, timeout time.Duration) *CodexAgent {
 	}
 }
 
+// SetModel sets the model Codex runs
+func (a *CodexAgent) SetModel(model string) {
+	a.model = model
+}
+
 // SetVerbose enables or disables verbose logging
 func (a *CodexAgent) SetVerbose(v bool) {
 	a.verbose = v
 }
 
+// SetStreamEvents makes Codex report its work as JSON events, which are
+// broadcast to the dashboard as they arrive
+func (a *CodexAgent) SetStreamEvents(v bool) {
+	a.streamEvents = v
+}
+
+// SetPromptTemplates has the agent's prompts rendered from templates
+func (a *CodexAgent) SetPromptTemplates(prompts *PromptTemplates) {
+	a.prompts = prompts
+}
+
 // SetProjectGuidelines sets project-specific guidelines for the agent
 func (a *CodexAgent) SetProjectGuidelines(guidelines string) {
 	a.projectGuidelines = guidelines
@@ -97,15 +117,30 @@ func (a *CodexAgent) ExecuteWithContext(ctx context.Context, worktreePath string
 		"exec",
 		"--cd", worktreePath,
 		"--full-auto",
-		prompt,
 	}
+	if a.model != "" {
+		args = append(args, "--model", a.model)
+	}
+	if a.streamEvents {
+		args = append(args, "--json")
+	}
+	args = append(args, prompt)
 
 	cmd := exec.CommandContext(ctx, a.codexPath, args...)
+	cmd.Env = commandEnv(task)
+	detach(cmd)
+	sandboxCmd(ctx, cmd)
+	cpuLimitCmd(ctx, cmd)
 
 	// Capture output while also streaming to stdout/stderr for real-time viewing
-	var outputBuf, errBuf strings.Builder
-	cmd.Stdout = io.MultiWriter(os.Stdout, &outputBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
+	output := newRunOutput(ctx, task)
+	cmd.Stdout = output.redact(io.MultiWriter(os.Stdout, output.stdout))
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
+	var events *eventWriter
+	if a.streamEvents {
+		events = newEventWriter(cmd.Stdout, task.ID, telemetry.AgentTypeCodex)
+		cmd.Stdout = events
+	}
 
 	start := time.Now()
 	if a.verbose {
@@ -113,9 +148,13 @@ func (a *CodexAgent) ExecuteWithContext(ctx context.Context, worktreePath string
 	}
 	err := cmd.Run()
 	duration := time.Since(start)
+	if events != nil {
+		events.Flush()
+	}
 
 	// Combine stdout and stderr for the result
-	fullOutput := outputBuf.String() + errBuf.String()
+	output.close()
+	fullOutput := output.String()
 
 	// Log exit code regardless of success/failure
 	if err != nil {
@@ -155,12 +194,12 @@ func (a *CodexAgent) ExecuteWithContext(ctx context.Context, worktreePath string
 	// Record successful completion
 	telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeCodex, duration)
 
-	return &ExecutionResult{
+	return readVerdictBlock(&ExecutionResult{
 		Success: true,
 		Output:  fullOutput,
 		Error:   nil,
 		Duration: duration,
-	}
+	}, "")
 }
 
 // CheckInstalled verifies Codex CLI is available
@@ -175,6 +214,10 @@ func (a *CodexAgent) CheckInstalled() error {
 
 // buildPrompt creates the Codex prompt for a task
 func (a *CodexAgent) buildPrompt(task *types.Task) string {
+	if prompt, ok := a.prompts.render(task, a.projectGuidelines, a.recentTasks, a.taskContextCount); ok {
+		return prompt
+	}
+
 	var prompt strings.Builder
 
 	// Start with project guidelines if configured
@@ -204,5 +247,7 @@ func (a *CodexAgent) buildPrompt(task *types.Task) string {
 		prompt.WriteString(fmt.Sprintf("\n\nThis task is part of epic: %s", task.EpicID))
 	}
 
+	prompt.WriteString("\n\n" + outcome.VerdictInstructions)
+
 	return prompt.String()
 }
@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CPULimit constrains the CPU the processes an agent runs for a task get, so
+// a runaway build in one worktree doesn't starve the other tasks or the rest
+// of the machine
+type CPULimit struct {
+	// Nice is the niceness the processes run at, 1-19 (0 = drover's own)
+	Nice int
+
+	// MaxProcs is set as GOMAXPROCS for the processes, so the Go tools the
+	// agent runs use that many threads (0 = unset)
+	MaxProcs int
+
+	// CPUs is how much CPU time a task's processes get together, in CPUs,
+	// enforced with a cgroup v2 cpu.max on Linux (0 = unlimited)
+	CPUs float64
+
+	// Cgroup is the cgroup v2 directory each task's cgroup is created in,
+	// which drover must be able to write (empty = drover's own cgroup)
+	Cgroup string
+}
+
+// IsZero reports whether the limit constrains nothing
+func (l CPULimit) IsZero() bool {
+	return l.Nice == 0 && l.MaxProcs == 0 && l.CPUs == 0
+}
+
+// cpuLimitKey carries the CPU limit of an execution in its context
+type cpuLimitKey struct{}
+
+// cpuLimited is the CPU limit an execution runs under, and the cgroup its
+// processes go in, if any
+type cpuLimited struct {
+	limit CPULimit
+	group *cpuCgroup
+}
+
+// cpuLimiter is a sandbox that enforces a CPU limit itself, because the
+// process drover starts for it isn't the one the agent runs in
+type cpuLimiter interface {
+	limitCPU(cmd *exec.Cmd, limit CPULimit)
+}
+
+// cpuLimitCmd has cmd run under the CPU limit of the execution ctx belongs
+// to, if any. It goes after sandboxCmd, so the limit covers the sandbox as a
+// whole rather than the host path of a program the sandbox runs
+func cpuLimitCmd(ctx context.Context, cmd *exec.Cmd) {
+	l, ok := ctx.Value(cpuLimitKey{}).(cpuLimited)
+	if !ok {
+		return
+	}
+	if s, ok := ctx.Value(sandboxKey{}).(sandboxed); ok {
+		if limiter, ok := s.sandbox.(cpuLimiter); ok {
+			limiter.limitCPU(cmd, l.limit)
+			return
+		}
+	}
+	applyCPULimit(cmd, l.limit, l.group)
+}
+
+// applyCPULimit has cmd run at the limit's niceness and GOMAXPROCS, in group
+// when it isn't nil. Children of cmd inherit all three
+func applyCPULimit(cmd *exec.Cmd, limit CPULimit, group *cpuCgroup) {
+	if cmd.Err != nil {
+		return
+	}
+	if limit.MaxProcs > 0 {
+		env := cmd.Env
+		if env == nil {
+			env = os.Environ()
+		}
+		cmd.Env = append(env, "GOMAXPROCS="+strconv.Itoa(limit.MaxProcs))
+	}
+	if limit.Nice > 0 {
+		if nice, err := exec.LookPath("nice"); err == nil {
+			cmd.Args = append([]string{"nice", "-n", strconv.Itoa(limit.Nice), cmd.Path}, cmd.Args[1:]...)
+			cmd.Path = nice
+		} else {
+			warnCPULimit.Do(func() { log.Printf("⚠️  Agents run at drover's own priority, nice isn't available: %v", err) })
+		}
+	}
+	if group != nil {
+		group.attach(cmd)
+	}
+}
+
+// warnCPULimit logs a CPU limit that can't be applied once per run
+var warnCPULimit sync.Once
+
+// CPULimitedAgent runs another agent with every process it starts for a
+// task under a CPU limit, each task's processes in a cgroup of their own
+type CPULimitedAgent struct {
+	Agent
+	limit CPULimit
+}
+
+// NewCPULimitedAgent wraps agent so its processes run under limit
+func NewCPULimitedAgent(agent Agent, limit CPULimit) *CPULimitedAgent {
+	return &CPULimitedAgent{Agent: agent, limit: limit}
+}
+
+// ExecuteWithContext runs the task with the wrapped agent, under the limit
+func (a *CPULimitedAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *ExecutionResult {
+	l := cpuLimited{limit: a.limit}
+	if a.limit.CPUs > 0 {
+		group, err := newCPUCgroup(a.limit, "task-"+task.ID)
+		if err != nil {
+			warnCPULimit.Do(func() { log.Printf("⚠️  Agents run without a CPU quota: %v", err) })
+		} else {
+			defer group.remove()
+			l.group = group
+		}
+	}
+	return a.Agent.ExecuteWithContext(context.WithValue(ctx, cpuLimitKey{}, l), worktreePath, task, parentSpan...)
+}
+
+// CheckInstalled verifies the wrapped agent, and that a CPU quota can be
+// enforced; without cgroup v2 agents run without one
+func (a *CPULimitedAgent) CheckInstalled() error {
+	if a.limit.CPUs > 0 {
+		group, err := newCPUCgroup(a.limit, "check")
+		if err != nil {
+			warnCPULimit.Do(func() { log.Printf("⚠️  Agents run without a CPU quota: %v", err) })
+		} else {
+			group.remove()
+		}
+	}
+	return a.Agent.CheckInstalled()
+}
+
+// Close releases what the wrapped agent holds for the run
+func (a *CPULimitedAgent) Close() error {
+	return CloseAgent(a.Agent)
+}
+
+// cpuShares returns the CPU weight of a niceness, relative to the default of
+// 1024, as the kernel weighs it: each step of niceness is a quarter less
+func cpuShares(nice int) int {
+	return max(int(1024/math.Pow(1.25, float64(nice))), 2)
+}
+
+// cpuMax returns the cgroup cpu.max of a quota of cpus CPUs
+func cpuMax(cpus float64) string {
+	const period = 100000
+	quota := int64(cpus * period)
+	if quota < 1000 {
+		quota = 1000 // The kernel's minimum
+	}
+	return fmt.Sprintf("%d %d", quota, period)
+}
@@ -0,0 +1,115 @@
+//go:build linux
+
+package executor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// cgroupRoot is where the cgroup v2 hierarchy is mounted
+const cgroupRoot = "/sys/fs/cgroup"
+
+// cpuCgroupSeq keeps the names of the cgroups a run creates apart
+var cpuCgroupSeq atomic.Int64
+
+// cpuCgroup is a cgroup v2 with a cpu.max the processes of a task start in
+type cpuCgroup struct {
+	dir string
+	fd  *os.File
+}
+
+// newCPUCgroup creates a cgroup for name's processes under the limit's
+// parent, with the limit's quota
+func newCPUCgroup(limit CPULimit, name string) (*cpuCgroup, error) {
+	parent := limit.Cgroup
+	if parent == "" {
+		own, err := ownCgroup()
+		if err != nil {
+			return nil, err
+		}
+		parent = filepath.Join(cgroupRoot, own)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "cgroup.controllers")); err != nil {
+		return nil, fmt.Errorf("%s isn't a cgroup v2 directory: %w", parent, err)
+	}
+	// A cgroup with processes of its own can't hand controllers down, so this
+	// fails where drover runs in a cgroup it doesn't manage; the parent
+	// setting points at one it does
+	if err := os.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+cpu"), 0644); err != nil {
+		return nil, fmt.Errorf("enabling the cpu controller in %s: %w", parent, err)
+	}
+
+	name = containerNameUnsafe.ReplaceAllString(name, "-")
+	dir := filepath.Join(parent, fmt.Sprintf("drover-%d-%d-%s", os.Getpid(), cpuCgroupSeq.Add(1), name))
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return nil, fmt.Errorf("creating a cgroup: %w", err)
+	}
+	group := &cpuCgroup{dir: dir}
+	if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte(cpuMax(limit.CPUs)), 0644); err != nil {
+		group.remove()
+		return nil, fmt.Errorf("setting %s/cpu.max: %w", dir, err)
+	}
+	fd, err := os.Open(dir)
+	if err != nil {
+		group.remove()
+		return nil, err
+	}
+	group.fd = fd
+	return group, nil
+}
+
+// attach has cmd start in the cgroup
+func (g *cpuCgroup) attach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(g.fd.Fd())
+}
+
+// remove kills what's left in the cgroup, such as a server the agent left
+// running, and removes it
+func (g *cpuCgroup) remove() {
+	if g.fd != nil {
+		g.fd.Close()
+	}
+	os.WriteFile(filepath.Join(g.dir, "cgroup.kill"), []byte("1"), 0644)
+	// Killed processes leave the cgroup shortly after
+	for i := 0; ; i++ {
+		err := os.Remove(g.dir)
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			return
+		}
+		if i == 20 {
+			log.Printf("⚠️  Removing cgroup %s: %v", g.dir, err)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// ownCgroup returns drover's cgroup v2, relative to cgroupRoot
+func ownCgroup() (string, error) {
+	f, err := os.Open("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if path, ok := strings.CutPrefix(scanner.Text(), "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", errors.New("drover isn't in a cgroup v2 hierarchy")
+}
@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCPUMax(t *testing.T) {
+	tests := map[float64]string{
+		1.5:   "150000 100000",
+		4:     "400000 100000",
+		0.001: "1000 100000", // The kernel's minimum
+	}
+	for cpus, want := range tests {
+		if got := cpuMax(cpus); got != want {
+			t.Errorf("cpuMax(%v) = %q, want %q", cpus, got, want)
+		}
+	}
+}
+
+func TestCPUCgroup(t *testing.T) {
+	group, err := newCPUCgroup(CPULimit{CPUs: 0.5}, "task-1")
+	if err != nil {
+		t.Skipf("no cgroup v2 drover may manage here: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(group.dir, "cpu.max")); strings.TrimSpace(string(data)) != "50000 100000" {
+		t.Errorf("Expected the quota in cpu.max, got %q", data)
+	}
+
+	// A child the command leaves running is in the cgroup too, and goes
+	// with it
+	cmd := exec.CommandContext(context.Background(), "sh", "-c", "sleep 30 >/dev/null 2>&1 & cat /proc/self/cgroup")
+	applyCPULimit(cmd, CPULimit{CPUs: 0.5}, group)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), filepath.Base(group.dir)) {
+		t.Errorf("Expected the command in %s, got %s", group.dir, out)
+	}
+	group.remove()
+	if _, err := os.Stat(group.dir); !os.IsNotExist(err) {
+		t.Errorf("Expected the cgroup removed, got %v", err)
+	}
+}
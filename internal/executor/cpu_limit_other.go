@@ -0,0 +1,20 @@
+//go:build !linux
+
+package executor
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// cpuCgroup is a CPU quota's cgroup, which only Linux has
+type cpuCgroup struct{}
+
+// newCPUCgroup fails on this platform, so agents run without a quota
+func newCPUCgroup(limit CPULimit, name string) (*cpuCgroup, error) {
+	return nil, errors.New("CPU quotas need Linux cgroup v2")
+}
+
+func (g *cpuCgroup) attach(cmd *exec.Cmd) {}
+
+func (g *cpuCgroup) remove() {}
@@ -0,0 +1,126 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestCPULimitedAgent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the agent")
+	}
+	if _, err := exec.LookPath("nice"); err != nil {
+		t.Skip("nice not available")
+	}
+	// The tool prints its niceness and GOMAXPROCS, from a child of its own
+	tool := filepath.Join(t.TempDir(), "tool.sh")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\nsh -c 'echo \"nice=$(nice) procs=$GOMAXPROCS\"'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := executor.NewCustomAgent(executor.CustomAgentConfig{Command: tool}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewCustomAgent: %v", err)
+	}
+	agent := executor.NewCPULimitedAgent(custom, executor.CPULimit{Nice: 7, MaxProcs: 3})
+
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1", Title: "Build it"})
+	if !result.Success {
+		t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+	}
+	if !strings.Contains(result.Output, "procs=3") {
+		t.Errorf("Expected GOMAXPROCS=3 for the agent's children, got: %s", result.Output)
+	}
+	// Niceness adds up, so it's relative to the test's own
+	out, err := exec.Command("nice").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var own, got int
+	fmt.Sscan(string(out), &own)
+	_, after, _ := strings.Cut(result.Output, "nice=")
+	if _, err := fmt.Sscan(after, &got); err != nil {
+		t.Fatalf("Expected the niceness in the output, got: %s", result.Output)
+	}
+	if want := min(own+7, 19); got != want {
+		t.Errorf("Expected niceness %d for the agent's children, got %d", want, got)
+	}
+}
+
+func TestCPULimitedAgent_Sandboxed(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as the sandbox")
+	}
+	if _, err := exec.LookPath("nice"); err != nil {
+		t.Skip("nice not available")
+	}
+	dir := t.TempDir()
+	// Both mocks print their arguments, one per line, then GOMAXPROCS
+	wrapper := "#!/bin/bash\nprintf '%s\\n' \"$@\"\necho \"procs=$GOMAXPROCS\"\n"
+	for _, name := range []string{"docker", "bwrap"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(wrapper), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tool := filepath.Join(dir, "tool.sh")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	limit := executor.CPULimit{Nice: 7, MaxProcs: 3, CPUs: 1.5}
+
+	t.Run("docker", func(t *testing.T) {
+		sandbox, err := executor.NewDockerSandbox(executor.DockerSandboxConfig{Image: "drover-agent:latest", Docker: filepath.Join(dir, "docker")})
+		if err != nil {
+			t.Fatalf("NewDockerSandbox: %v", err)
+		}
+		custom, err := executor.NewCustomAgent(executor.CustomAgentConfig{Command: "in-image-tool"}, time.Minute)
+		if err != nil {
+			t.Fatalf("NewCustomAgent: %v", err)
+		}
+		agent := executor.NewSandboxedAgent(executor.NewCPULimitedAgent(custom, limit), sandbox)
+		result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1"})
+		if !result.Success {
+			t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+		}
+		// docker enforces the limit on the container, not on its client
+		joined := strings.Join(strings.Fields(result.Output), " ")
+		for _, want := range []string{"run --cpus 1.5 --cpu-shares 214 -e GOMAXPROCS", "drover-agent:latest in-image-tool", "procs=3"} {
+			if !strings.Contains(joined, want) {
+				t.Errorf("Expected %q in the docker command, got: %s", want, joined)
+			}
+		}
+		if strings.Contains(joined, "nice") {
+			t.Errorf("Expected the container's program run as is, got: %s", joined)
+		}
+	})
+
+	t.Run("bwrap", func(t *testing.T) {
+		sandbox, err := executor.NewBwrapSandbox(executor.BwrapSandboxConfig{Bwrap: filepath.Join(dir, "bwrap")})
+		if err != nil {
+			t.Fatalf("NewBwrapSandbox: %v", err)
+		}
+		custom, err := executor.NewCustomAgent(executor.CustomAgentConfig{Command: tool}, time.Minute)
+		if err != nil {
+			t.Fatalf("NewCustomAgent: %v", err)
+		}
+		agent := executor.NewSandboxedAgent(executor.NewCPULimitedAgent(custom, executor.CPULimit{Nice: 7, MaxProcs: 3}), sandbox)
+		result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1"})
+		if !result.Success {
+			t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+		}
+		// bwrap itself runs niced, so the program inside is the agent's own
+		_, inside, _ := strings.Cut(result.Output, "\n--\n")
+		if !strings.HasPrefix(inside, tool+"\n") || !strings.Contains(inside, "procs=3") {
+			t.Errorf("Expected the agent's program run in the sandbox with GOMAXPROCS, got: %s", result.Output)
+		}
+	})
+}
@@ -0,0 +1,473 @@
+// Package executor provides the custom command agent implementation
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/outcome"
+	"github.com/cloud-shuttle/drover/internal/taskcontext"
+	"github.com/cloud-shuttle/drover/pkg/telemetry"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CustomAgentConfig describes an in-house agent run as a command line
+type CustomAgentConfig struct {
+	// Command is the command line, a Go template over CustomAgentData split
+	// into arguments like a shell would once rendered. A field's value stays
+	// part of the word it is in, e.g.
+	// "mytool --prompt {{.PromptFile}} --cwd {{.Worktree}}"
+	Command string
+
+	// SuccessPattern, when set, must match the output for a run to succeed
+	SuccessPattern string
+
+	// FailurePattern, when set, fails a run whose output it matches
+	FailurePattern string
+
+	// VerdictPattern reads the agent's verdict from its output: its
+	// "verdict" group is pass, fail or blocked, its optional "reason" group
+	// why. The last match counts
+	VerdictPattern string
+}
+
+// CustomAgentData is what a custom agent's command is rendered with
+type CustomAgentData struct {
+	Prompt      string // The prompt drover built for the task
+	PromptFile  string // A file holding the prompt, removed after the run
+	Worktree    string // The task's worktree, which the command also runs in
+	TaskID      string
+	Title       string
+	Description string
+	EpicID      string
+	Attempt     int    // 1 for the task's first attempt
+	Model       string // DROVER_AGENT_MODEL, if set
+}
+
+// CustomAgent runs tasks with a user-configured command
+type CustomAgent struct {
+	program           string // The command's program; empty when it is itself a template
+	command           *template.Template
+	success           *regexp.Regexp
+	failure           *regexp.Regexp
+	verdict           *regexp.Regexp
+	model             string
+	timeout           time.Duration
+	verbose           bool
+	projectGuidelines string
+	contextManager    *ctxmngr.Manager
+	recentTasks       []*types.Task
+	taskContextCount  int
+	prompts           *PromptTemplates // Templates the prompt comes from; nil for the built-in prompt
+}
+
+// NewCustomAgent creates an agent running cfg's command, checking its
+// templates and patterns up front
+func NewCustomAgent(cfg CustomAgentConfig, timeout time.Duration) (*CustomAgent, error) {
+	words := strings.Fields(cfg.Command)
+	if len(words) == 0 {
+		return nil, errors.New("the custom agent needs a command (command under [custom_agent] in .drover.toml)")
+	}
+	tmpl, err := template.New("command").Parse(cfg.Command)
+	if err != nil {
+		return nil, fmt.Errorf("custom agent command: %w", err)
+	}
+	a := &CustomAgent{command: tmpl, timeout: timeout}
+
+	// Catch fields that don't exist and unbalanced quotes now rather than on
+	// the first task
+	args, err := a.render(CustomAgentData{})
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("the custom agent command renders to nothing")
+	}
+	if !strings.Contains(words[0], "{{") {
+		a.program = args[0]
+	}
+
+	if a.success, err = compilePattern("success_pattern", cfg.SuccessPattern); err != nil {
+		return nil, err
+	}
+	if a.failure, err = compilePattern("failure_pattern", cfg.FailurePattern); err != nil {
+		return nil, err
+	}
+	if a.verdict, err = compilePattern("verdict_pattern", cfg.VerdictPattern); err != nil {
+		return nil, err
+	}
+	if a.verdict != nil && a.verdict.SubexpIndex("verdict") < 0 {
+		return nil, errors.New("custom agent verdict_pattern needs a (?P<verdict>...) group")
+	}
+	return a, nil
+}
+
+// compilePattern compiles a custom agent's pattern; an empty one is nil
+func compilePattern(name, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("custom agent %s: %w", name, err)
+	}
+	return re, nil
+}
+
+// SetModel sets the model passed to the command as {{.Model}}
+func (a *CustomAgent) SetModel(model string) {
+	a.model = model
+}
+
+// SetVerbose enables or disables verbose logging
+func (a *CustomAgent) SetVerbose(v bool) {
+	a.verbose = v
+}
+
+// SetPromptTemplates has the agent's prompts rendered from templates
+func (a *CustomAgent) SetPromptTemplates(prompts *PromptTemplates) {
+	a.prompts = prompts
+}
+
+// SetProjectGuidelines sets project-specific guidelines for the agent
+func (a *CustomAgent) SetProjectGuidelines(guidelines string) {
+	a.projectGuidelines = guidelines
+}
+
+// SetContextManager sets the context window manager for the agent
+func (a *CustomAgent) SetContextManager(manager *ctxmngr.Manager) {
+	a.contextManager = manager
+}
+
+// SetTaskContext sets recent completed tasks for context carrying
+func (a *CustomAgent) SetTaskContext(recentTasks []*types.Task, taskContextCount int) {
+	a.recentTasks = recentTasks
+	a.taskContextCount = taskContextCount
+}
+
+// ExecuteWithContext runs a task with a context and returns the execution result
+func (a *CustomAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *ExecutionResult {
+	// Start telemetry span for agent execution
+	var agentCtx context.Context
+	var span trace.Span
+	if len(parentSpan) > 0 && parentSpan[0] != nil {
+		agentCtx, span = telemetry.StartAgentSpan(ctx, telemetry.AgentTypeCustom, "unknown",
+			attribute.String(telemetry.KeyTaskID, task.ID),
+			attribute.String(telemetry.KeyTaskTitle, task.Title),
+		)
+		defer span.End()
+	} else {
+		agentCtx = ctx
+		span = trace.SpanFromContext(ctx)
+	}
+
+	// Record agent prompt
+	telemetry.RecordAgentPrompt(agentCtx, telemetry.AgentTypeCustom)
+
+	prompt := a.buildPrompt(task)
+	promptFile, err := writePromptFile(prompt)
+	if err != nil {
+		return &ExecutionResult{Success: false, Error: err}
+	}
+	defer os.Remove(promptFile)
+
+	args, err := a.render(CustomAgentData{
+		Prompt:      prompt,
+		PromptFile:  promptFile,
+		Worktree:    worktreePath,
+		TaskID:      task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		EpicID:      task.EpicID,
+		Attempt:     task.Attempts + 1,
+		Model:       a.model,
+	})
+	if err != nil {
+		return &ExecutionResult{Success: false, Error: err}
+	}
+
+	if a.verbose {
+		log.Printf("🤖 Running custom agent: %s (prompt: %d chars)", args[0], len(prompt))
+	}
+
+	runCtx := ctx
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(runCtx, args[0], args[1:]...)
+	cmd.Env = commandEnv(task)
+	detach(cmd)
+	cmd.Dir = worktreePath
+	sandboxCmd(ctx, cmd)
+	cpuLimitCmd(ctx, cmd)
+
+	// Capture output while also streaming to stdout/stderr for real-time viewing
+	output := newRunOutput(ctx, task)
+	cmd.Stdout = output.redact(io.MultiWriter(os.Stdout, output.stdout))
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
+
+	start := time.Now()
+	err = cmd.Run()
+	duration := time.Since(start)
+
+	// Combine stdout and stderr for the result
+	output.close()
+	fullOutput := output.String()
+	result := &ExecutionResult{Output: fullOutput, Duration: duration}
+	result.Verdict, result.VerdictReason = a.readVerdict(fullOutput)
+
+	if err != nil {
+		exitCode := 1
+		if exitError, ok := err.(*exec.ExitError); ok {
+			exitCode = exitError.ExitCode()
+		}
+		if a.verbose {
+			log.Printf("❌ Custom agent exited with code %d after %v", exitCode, duration)
+		}
+		telemetry.RecordAgentError(agentCtx, telemetry.AgentTypeCustom, "execution_failed")
+		telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeCustom, duration)
+
+		if runCtx.Err() == context.DeadlineExceeded {
+			telemetry.RecordError(span, err, "TimeoutError", telemetry.ErrorCategoryTimeout)
+			result.Error = fmt.Errorf("%s timed out after %v", args[0], duration)
+			return result
+		}
+		telemetry.RecordError(span, err, "ExecutionError", telemetry.ErrorCategoryAgent)
+		result.Error = fmt.Errorf("%s exited with code %d after %v: %w", args[0], exitCode, duration, err)
+		return result
+	}
+
+	telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeCustom, duration)
+	if a.failure != nil {
+		if match := a.failure.FindString(fullOutput); match != "" {
+			telemetry.RecordAgentError(agentCtx, telemetry.AgentTypeCustom, "failure_pattern")
+			result.Error = fmt.Errorf("%s output matched failure_pattern: %s", args[0], strings.TrimSpace(match))
+			return result
+		}
+	}
+	if a.success != nil && !a.success.MatchString(fullOutput) {
+		telemetry.RecordAgentError(agentCtx, telemetry.AgentTypeCustom, "success_pattern")
+		result.Error = fmt.Errorf("%s output didn't match success_pattern", args[0])
+		return result
+	}
+
+	if a.verbose {
+		log.Printf("✅ Custom agent completed successfully in %v", duration)
+	}
+	result.Success = true
+	return readVerdictBlock(result, "")
+}
+
+// render renders the command line for a run and splits it into arguments
+func (a *CustomAgent) render(data CustomAgentData) ([]string, error) {
+	// The fields are rendered escaped, so their spaces and quotes don't split
+	// or quote the command line
+	data.Prompt = escapeArg(data.Prompt)
+	data.PromptFile = escapeArg(data.PromptFile)
+	data.Worktree = escapeArg(data.Worktree)
+	data.TaskID = escapeArg(data.TaskID)
+	data.Title = escapeArg(data.Title)
+	data.Description = escapeArg(data.Description)
+	data.EpicID = escapeArg(data.EpicID)
+	data.Model = escapeArg(data.Model)
+
+	var line strings.Builder
+	if err := a.command.Execute(&line, data); err != nil {
+		return nil, fmt.Errorf("rendering custom agent command: %w", err)
+	}
+	args, err := splitArgs(line.String())
+	if err != nil {
+		return nil, fmt.Errorf("custom agent command: %w", err)
+	}
+	return args, nil
+}
+
+// argEscape marks the rune after it as literal wherever it is in a command
+// line. A NUL can't be part of an argument, so it can't clash with one
+const argEscape = '\x00'
+
+// escapeArg escapes the runes of a field value that splitArgs would treat as
+// syntax
+func escapeArg(s string) string {
+	if !strings.ContainsAny(s, " \t\n\r'\"\\") {
+		return s
+	}
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case ' ', '\t', '\n', '\r', '\'', '"', '\\':
+			b.WriteRune(argEscape)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// splitArgs splits a command line into arguments the way a shell would:
+// whitespace separates them, single quotes keep text as is, double quotes
+// keep it but for backslash escapes of " and \, and a backslash outside
+// quotes escapes the next character. There are no expansions
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == argEscape:
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			switch {
+			case r == '"':
+				quote = 0
+			case r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+				i++
+				word.WriteRune(runes[i])
+			default:
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
+
+// readVerdict reads the verdict the output reports through the verdict
+// pattern, if any
+func (a *CustomAgent) readVerdict(output string) (types.TaskVerdict, string) {
+	if a.verdict == nil {
+		return "", ""
+	}
+	matches := a.verdict.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return "", ""
+	}
+	last := matches[len(matches)-1]
+	verdict := types.TaskVerdict(strings.ToLower(strings.TrimSpace(last[a.verdict.SubexpIndex("verdict")])))
+	switch verdict {
+	case types.TaskVerdictPass, types.TaskVerdictFail, types.TaskVerdictBlocked:
+	default:
+		return "", ""
+	}
+	reason := ""
+	if i := a.verdict.SubexpIndex("reason"); i >= 0 {
+		reason = strings.TrimSpace(last[i])
+	}
+	return verdict, reason
+}
+
+// writePromptFile writes the prompt to a temporary file for {{.PromptFile}}
+func writePromptFile(prompt string) (string, error) {
+	f, err := os.CreateTemp("", "drover-prompt-*.md")
+	if err != nil {
+		return "", fmt.Errorf("writing prompt file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(prompt); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("writing prompt file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// CheckInstalled verifies the command's program can be found, unless it is
+// itself a template
+func (a *CustomAgent) CheckInstalled() error {
+	if a.program == "" {
+		return nil
+	}
+	if _, err := exec.LookPath(a.program); err != nil {
+		return fmt.Errorf("custom agent %s not found: %w", a.program, err)
+	}
+	return nil
+}
+
+// buildPrompt creates the prompt for a task
+func (a *CustomAgent) buildPrompt(task *types.Task) string {
+	if prompt, ok := a.prompts.render(task, a.projectGuidelines, a.recentTasks, a.taskContextCount); ok {
+		return prompt
+	}
+
+	var prompt strings.Builder
+
+	// Start with project guidelines if configured
+	if a.projectGuidelines != "" {
+		prompt.WriteString("=== PROJECT GUIDELINES ===\n")
+		prompt.WriteString(a.projectGuidelines)
+		prompt.WriteString("\n============================\n\n")
+	}
+
+	// Inject recent task context if available
+	if a.taskContextCount > 0 && len(a.recentTasks) > 0 {
+		taskContext := taskcontext.BuildContext(a.recentTasks, task, a.taskContextCount)
+		if taskContext != "" {
+			prompt.WriteString(taskContext)
+		}
+	}
+
+	prompt.WriteString(fmt.Sprintf("Task: %s\n", task.Title))
+
+	if task.Description != "" {
+		prompt.WriteString(fmt.Sprintf("Description: %s\n", task.Description))
+	}
+
+	prompt.WriteString("\nPlease implement this task completely.")
+
+	if len(task.EpicID) > 0 {
+		prompt.WriteString(fmt.Sprintf("\n\nThis task is part of epic: %s", task.EpicID))
+	}
+
+	prompt.WriteString("\n\n" + outcome.VerdictInstructions)
+
+	return prompt.String()
+}
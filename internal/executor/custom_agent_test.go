@@ -0,0 +1,126 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// createMockCustomScript creates a script that records its arguments and the
+// prompt file it's given as its second one, prints output and exits with
+// exitCode
+func createMockCustomScript(t *testing.T, dir, output string, exitCode int) (script, recordFile string) {
+	t.Helper()
+	script = filepath.Join(dir, "mytool.sh")
+	recordFile = filepath.Join(dir, "record.txt")
+	content := fmt.Sprintf(`#!/bin/bash
+# Mock in-house agent for testing
+printf '%%s\n' "$@" > %[1]s
+cat "$2" >> %[1]s
+printf '%%b\n' %[2]q
+exit %[3]d
+`, recordFile, output, exitCode)
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to create mock custom agent script: %v", err)
+	}
+	return script, recordFile
+}
+
+func TestCustomAgent_Execute(t *testing.T) {
+	script, recordFile := createMockCustomScript(t, t.TempDir(), "all done\nVERDICT: blocked - needs an API key", 0)
+
+	agent, err := executor.NewCustomAgent(executor.CustomAgentConfig{
+		Command:        script + ` --prompt {{ .PromptFile }} --cwd {{.Worktree}} --title {{.Title}} --attempt {{.Attempt}} --label "two words" {{if .Model}}--model {{.Model}}{{end}} --id '{{.TaskID}}'`,
+		SuccessPattern: "all done",
+		VerdictPattern: `VERDICT: (?P<verdict>\w+)(?: - (?P<reason>.*))?`,
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewCustomAgent failed: %v", err)
+	}
+	worktree := t.TempDir()
+	task := &types.Task{ID: "task-7", Title: `Add a "health" endpoint`, Attempts: 2}
+
+	result := agent.ExecuteWithContext(context.Background(), worktree, task)
+	if !result.Success {
+		t.Fatalf("Execute failed: %v", result.Error)
+	}
+	if result.Verdict != types.TaskVerdictBlocked || result.VerdictReason != "needs an API key" {
+		t.Errorf("Expected a blocked verdict, got %q (%q)", result.Verdict, result.VerdictReason)
+	}
+
+	recorded, err := os.ReadFile(recordFile)
+	if err != nil {
+		t.Fatalf("Reading recorded invocation: %v", err)
+	}
+	got := string(recorded)
+	for _, want := range []string{"--cwd\n" + worktree + "\n", "--title\nAdd a \"health\" endpoint\n", "--attempt\n3\n", "--label\ntwo words\n--id\ntask-7\n", `Task: Add a "health" endpoint`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in the invocation, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "--model") {
+		t.Errorf("Expected no --model without a model, got:\n%s", got)
+	}
+}
+
+func TestCustomAgent_Patterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		exitCode int
+		success  string
+		failure  string
+		wantErr  string
+	}{
+		{name: "exit code", output: "ok", exitCode: 2, wantErr: "exited with code 2"},
+		{name: "success pattern missing", output: "gave up", success: "^DONE", wantErr: "didn't match success_pattern"},
+		{name: "failure pattern", output: "DONE\nFATAL: disk full", success: "^DONE", failure: "FATAL.*", wantErr: "FATAL: disk full"},
+		{name: "success", output: "DONE", success: "(?m)^DONE$", failure: "FATAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			script, _ := createMockCustomScript(t, t.TempDir(), tt.output, tt.exitCode)
+			agent, err := executor.NewCustomAgent(executor.CustomAgentConfig{
+				Command:        script + " --prompt {{.PromptFile}}",
+				SuccessPattern: tt.success,
+				FailurePattern: tt.failure,
+			}, time.Minute)
+			if err != nil {
+				t.Fatalf("NewCustomAgent failed: %v", err)
+			}
+			result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-7", Title: "Fix it"})
+			if tt.wantErr == "" {
+				if !result.Success {
+					t.Fatalf("Expected success, got %v", result.Error)
+				}
+				return
+			}
+			if result.Success || result.Error == nil || !strings.Contains(result.Error.Error(), tt.wantErr) {
+				t.Fatalf("Expected an error containing %q, got success=%v error=%v", tt.wantErr, result.Success, result.Error)
+			}
+		})
+	}
+}
+
+func TestNewCustomAgent_Invalid(t *testing.T) {
+	for _, cfg := range []executor.CustomAgentConfig{
+		{},
+		{Command: "mytool {{.Nope}}"},
+		{Command: "mytool {{.Title"},
+		{Command: `mytool --title "{{.Title}}`},
+		{Command: "{{if .Model}}mytool{{end}}"},
+		{Command: "mytool", SuccessPattern: "("},
+		{Command: "mytool", VerdictPattern: "VERDICT: (pass|fail)"},
+	} {
+		if _, err := executor.NewCustomAgent(cfg, time.Minute); err == nil {
+			t.Errorf("Expected %+v to be rejected", cfg)
+		}
+	}
+}
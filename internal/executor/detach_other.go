@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package executor
+
+import "os/exec"
+
+// detach leaves cmd in drover's process group on this platform; only the
+// agent itself is killed when its context ends
+func detach(cmd *exec.Cmd) {}
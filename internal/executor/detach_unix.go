@@ -0,0 +1,20 @@
+//go:build linux || darwin
+
+package executor
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detach starts cmd in its own process group, so the Ctrl-C a terminal sends
+// to drover's group doesn't also kill agents that a drain lets finish. When
+// cmd's context ends, the whole group is killed, so a cancelled or timed-out
+// agent doesn't leave the shells and servers it started running. cmd must
+// come from exec.CommandContext
+func detach(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
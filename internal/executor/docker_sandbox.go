@@ -0,0 +1,249 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sandboxPassEnv are the host variables agents need for their API access,
+// passed into a sandbox when set
+var sandboxPassEnv = []string{
+	"ANTHROPIC_API_KEY", "ANTHROPIC_BASE_URL", "CLAUDE_CODE_OAUTH_TOKEN",
+	"OPENAI_API_KEY", "OPENAI_BASE_URL", "GEMINI_API_KEY", "OPENROUTER_API_KEY", "AMP_API_KEY",
+}
+
+// sandboxCacheEnv are variables pointing at caches on the host, which are
+// mounted into a sandbox along with them
+var sandboxCacheEnv = map[string]bool{"GOCACHE": true, "GOMODCACHE": true}
+
+// containerNameUnsafe matches what a container name may not contain
+var containerNameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// DockerSandboxConfig is the container agents run in
+type DockerSandboxConfig struct {
+	// Image the container runs, which must have the agent installed
+	Image string
+
+	// Extra volumes, "host:container[:ro]"; a leading ~ is the home directory
+	Mounts []string
+
+	// Host variables passed into the container besides the agents' API keys
+	Env []string
+
+	// CPU and memory limits in docker's notation, e.g. "2" and "4g" (empty = none)
+	CPUs   string
+	Memory string
+
+	// NoNetwork runs the container without network access
+	NoNetwork bool
+
+	// Docker is the docker CLI to run (empty = "docker" on the PATH)
+	Docker string
+}
+
+// DockerSandbox runs each agent process in a fresh container with the
+// task's worktree mounted at its own path, so the paths agents and drover
+// see agree
+type DockerSandbox struct {
+	cfg    DockerSandboxConfig
+	docker string
+	mounts []string
+}
+
+// NewDockerSandbox creates a docker sandbox, checking its settings
+func NewDockerSandbox(cfg DockerSandboxConfig) (*DockerSandbox, error) {
+	if strings.TrimSpace(cfg.Image) == "" {
+		return nil, fmt.Errorf("docker sandbox needs an image")
+	}
+	s := &DockerSandbox{cfg: cfg, docker: cfg.Docker}
+	if s.docker == "" {
+		s.docker = "docker"
+	}
+	home, _ := os.UserHomeDir()
+	for _, mount := range cfg.Mounts {
+		host, rest, ok := strings.Cut(mount, ":")
+		if !ok || host == "" || rest == "" {
+			return nil, fmt.Errorf("invalid sandbox mount %q (want host:container[:ro])", mount)
+		}
+		if home != "" && (host == "~" || strings.HasPrefix(host, "~/")) {
+			host = filepath.Join(home, strings.TrimPrefix(host, "~"))
+		}
+		s.mounts = append(s.mounts, host+":"+rest)
+	}
+	return s, nil
+}
+
+// Check verifies the docker daemon is reachable
+func (s *DockerSandbox) Check() error {
+	out, err := exec.Command(s.docker, "version", "--format", "{{.Server.Version}}").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("docker not available at %s: %w\n%s", s.docker, err, out)
+	}
+	return nil
+}
+
+// Wrap rewrites cmd into a 'docker run' of it. The container is named, so a
+// cancelled or timed-out agent's container is killed along with the client
+func (s *DockerSandbox) Wrap(cmd *exec.Cmd, worktreePath string) error {
+	docker, err := exec.LookPath(s.docker)
+	if err != nil {
+		return fmt.Errorf("docker not found: %w", err)
+	}
+	dir, err := filepath.Abs(worktreePath)
+	if err != nil {
+		return err
+	}
+	workdir := dir
+	if cmd.Dir != "" {
+		if workdir, err = filepath.Abs(cmd.Dir); err != nil {
+			return err
+		}
+	}
+	name := fmt.Sprintf("drover-%s-%d", containerNameUnsafe.ReplaceAllString(filepath.Base(dir), "-"), time.Now().UnixNano())
+
+	args := []string{"run", "--rm", "--init", "--name", name, "-v", dir + ":" + dir, "-w", workdir}
+	if cmd.Stdin != nil {
+		args = append(args, "-i")
+	}
+	if uid := os.Getuid(); uid >= 0 {
+		// Files the agent writes stay the host user's
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, os.Getgid()))
+	}
+	// A worktree's git metadata lives in the main repository. It is read-only,
+	// so the agent can't plant hooks or config that drover's git runs on the host
+	if common := gitCommonDir(dir); common != "" && !within(common, dir) {
+		args = append(args, "-v", common+":"+common+":ro")
+	}
+	for _, mount := range s.mounts {
+		args = append(args, "-v", mount)
+	}
+	// Files handed to the agent from outside the worktree, such as a prompt file
+	for _, file := range tempFileArgs(cmd.Args[1:]) {
+		args = append(args, "-v", file+":"+file+":ro")
+	}
+
+	// Variables are passed by name, so their values stay out of the process
+	// list; docker reads them from its own environment, which is cmd's
+	passed := make(map[string]bool)
+	pass := func(key string) {
+		if !passed[key] {
+			passed[key] = true
+			args = append(args, "-e", key)
+		}
+	}
+	for _, entry := range extraEnv(cmd.Env, os.Environ()) {
+		key, value, _ := strings.Cut(entry, "=")
+		pass(key)
+		if sandboxCacheEnv[key] && filepath.IsAbs(value) {
+			args = append(args, "-v", value+":"+value)
+		}
+	}
+	for _, key := range append(sandboxPassEnv, s.cfg.Env...) {
+		if _, ok := lookupEnv(cmd.Env, key); ok {
+			pass(key)
+		}
+	}
+
+	if s.cfg.CPUs != "" {
+		args = append(args, "--cpus", s.cfg.CPUs)
+	}
+	if s.cfg.Memory != "" {
+		args = append(args, "--memory", s.cfg.Memory)
+	}
+	if s.cfg.NoNetwork {
+		args = append(args, "--network", "none")
+	}
+	args = append(args, s.cfg.Image)
+	args = append(args, cmd.Args...)
+
+	cancel := cmd.Cancel
+	cmd.Path = docker
+	cmd.Args = append([]string{docker}, args...)
+	cmd.Err = nil // The agent's program only has to exist in the image
+	cmd.Cancel = func() error {
+		_ = exec.Command(docker, "kill", name).Run()
+		if cancel != nil {
+			return cancel()
+		}
+		return cmd.Process.Kill()
+	}
+	return nil
+}
+
+// limitCPU has the container cmd runs under limit, which docker enforces:
+// the quota as --cpus, unless the sandbox sets its own, the niceness as
+// --cpu-shares, and GOMAXPROCS set inside it
+func (s *DockerSandbox) limitCPU(cmd *exec.Cmd, limit CPULimit) {
+	if cmd.Err != nil || len(cmd.Args) < 2 {
+		return
+	}
+	var flags []string
+	if limit.CPUs > 0 && s.cfg.CPUs == "" {
+		flags = append(flags, "--cpus", strconv.FormatFloat(limit.CPUs, 'f', -1, 64))
+	}
+	if limit.Nice > 0 {
+		flags = append(flags, "--cpu-shares", strconv.Itoa(cpuShares(limit.Nice)))
+	}
+	if limit.MaxProcs > 0 {
+		env := cmd.Env
+		if env == nil {
+			env = os.Environ()
+		}
+		cmd.Env = append(env, "GOMAXPROCS="+strconv.Itoa(limit.MaxProcs))
+		flags = append(flags, "-e", "GOMAXPROCS")
+	}
+	// The flags go right after 'run', ahead of the image
+	cmd.Args = slices.Concat(cmd.Args[:2], flags, cmd.Args[2:])
+}
+
+// tempFileArgs returns the arguments naming files in the temporary directory
+func tempFileArgs(args []string) []string {
+	tmp := os.TempDir()
+	var files []string
+	for _, arg := range args {
+		if !filepath.IsAbs(arg) || !within(arg, tmp) {
+			continue
+		}
+		if info, err := os.Stat(arg); err == nil && info.Mode().IsRegular() {
+			files = append(files, arg)
+		}
+	}
+	return files
+}
+
+// gitCommonDir returns the absolute path of the git directory a worktree
+// shares with its main repository; empty when dir isn't in a repository
+func gitCommonDir(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--path-format=absolute", "--git-common-dir").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// within reports whether path is dir or inside it
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// lookupEnv looks a variable up in env, or in drover's own environment when
+// env is nil
+func lookupEnv(env []string, key string) (string, bool) {
+	if env == nil {
+		return os.LookupEnv(key)
+	}
+	for i := len(env) - 1; i >= 0; i-- {
+		if k, v, ok := strings.Cut(env[i], "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
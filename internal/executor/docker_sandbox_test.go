@@ -0,0 +1,93 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// createMockDocker creates a docker CLI that prints its arguments, one per
+// line, instead of running anything
+func createMockDocker(t *testing.T, dir string) string {
+	t.Helper()
+	script := filepath.Join(dir, "docker")
+	if err := os.WriteFile(script, []byte("#!/bin/bash\nprintf '%s\\n' \"$@\"\n"), 0755); err != nil {
+		t.Fatalf("Failed to create mock docker: %v", err)
+	}
+	return script
+}
+
+func TestSandboxedAgent_RunsInContainer(t *testing.T) {
+	docker := createMockDocker(t, t.TempDir())
+	sandbox, err := executor.NewDockerSandbox(executor.DockerSandboxConfig{
+		Image:     "drover-agent:latest",
+		Mounts:    []string{"/srv/cache:/cache:ro"},
+		CPUs:      "2",
+		Memory:    "1g",
+		NoNetwork: true,
+		Docker:    docker,
+	})
+	if err != nil {
+		t.Fatalf("NewDockerSandbox: %v", err)
+	}
+	// The agent's program only exists in the image
+	custom, err := executor.NewCustomAgent(executor.CustomAgentConfig{Command: "in-image-tool --prompt {{.PromptFile}}"}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewCustomAgent: %v", err)
+	}
+	agent := executor.NewSandboxedAgent(custom, sandbox)
+	if err := agent.CheckInstalled(); err != nil {
+		t.Errorf("CheckInstalled: %v", err)
+	}
+
+	worktree := t.TempDir()
+	task := &types.Task{
+		ID:               "task-1",
+		Title:            "Add a health endpoint",
+		ExecutionContext: &types.TaskExecutionContext{Env: []string{"TASK_SECRET=hunter22"}},
+	}
+	result := agent.ExecuteWithContext(context.Background(), worktree, task)
+	if !result.Success {
+		t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+	}
+
+	args := strings.Split(strings.TrimSpace(result.Output), "\n")
+	joined := strings.Join(args, " ")
+	for _, want := range []string{
+		"run --rm --init",
+		"-v " + worktree + ":" + worktree + " -w " + worktree,
+		"-v /srv/cache:/cache:ro",
+		"-e TASK_SECRET",
+		"--cpus 2",
+		"--memory 1g",
+		"--network none",
+		"drover-agent:latest in-image-tool --prompt",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected %q in the docker command, got: %s", want, joined)
+		}
+	}
+	if strings.Contains(joined, "hunter22") {
+		t.Errorf("Expected variables passed by name only, got: %s", joined)
+	}
+	// The prompt file lives outside the worktree, so it's mounted
+	promptFile := args[len(args)-1]
+	if !strings.Contains(joined, "-v "+promptFile+":"+promptFile+":ro") {
+		t.Errorf("Expected the prompt file %s mounted, got: %s", promptFile, joined)
+	}
+}
+
+func TestNewDockerSandbox_Validates(t *testing.T) {
+	if _, err := executor.NewDockerSandbox(executor.DockerSandboxConfig{}); err == nil {
+		t.Error("Expected an error without an image")
+	}
+	if _, err := executor.NewDockerSandbox(executor.DockerSandboxConfig{Image: "img", Mounts: []string{"/only-host"}}); err == nil {
+		t.Error("Expected an error for a mount without a container path")
+	}
+}
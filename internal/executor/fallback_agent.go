@@ -0,0 +1,248 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/worker"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fallbackCooldown is how long a rate limited model is passed over, so the
+// tasks that follow go straight to the next one instead of hitting the
+// limit again
+const fallbackCooldown = 5 * time.Minute
+
+// fallbackAgentTypes are the agents a fallback may name
+var fallbackAgentTypes = map[string]bool{
+	"claude": true, "codex": true, "amp": true, "opencode": true,
+	"aider": true, "goose": true, "custom": true, "llm": true,
+}
+
+// rateLimitPatterns are what agents print when a model's rate limit or
+// usage cap stops them
+var rateLimitPatterns = []string{"rate limit", "rate_limit", "too many requests", "429", "usage limit", "overloaded"}
+
+// ModelFallback is a model a task moves on to when the one before it is
+// rate limited
+type ModelFallback struct {
+	Type  string // Agent that runs the model; empty = the primary agent
+	Model string // Empty = the agent's default model
+}
+
+// String returns the fallback as written in a fallback list
+func (f ModelFallback) String() string {
+	switch {
+	case f.Type == "":
+		return f.Model
+	case f.Model == "":
+		return f.Type
+	}
+	return f.Type + "/" + f.Model
+}
+
+// ParseModelFallback parses a comma-separated fallback list such as
+// "haiku,opencode/openai/gpt-4o". An entry is a model of the primary agent,
+// or an agent type and optionally "/model" for a model another agent runs
+func ParseModelFallback(spec string) ([]ModelFallback, error) {
+	var fallbacks []ModelFallback
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		agent, model, _ := strings.Cut(entry, "/")
+		if !fallbackAgentTypes[agent] {
+			fallbacks = append(fallbacks, ModelFallback{Model: entry})
+			continue
+		}
+		if strings.TrimSpace(model) == "" && strings.Contains(entry, "/") {
+			return nil, fmt.Errorf("model fallback %q names no model", entry)
+		}
+		fallbacks = append(fallbacks, ModelFallback{Type: agent, Model: model})
+	}
+	return fallbacks, nil
+}
+
+// fallbackLink is an agent of a fallback chain and the model it runs
+type fallbackLink struct {
+	agent        Agent
+	name         string    // "agent/model", recorded on the results it produces
+	limitedUntil time.Time // Passed over until then after a rate limit
+}
+
+// FallbackAgent runs a task with the first model of a chain that isn't rate
+// limited, moving on to the next when a run ends in a rate limit. Agent CLIs
+// retry a rate limited request on their own, so a run that still ends in
+// one means the limit persists
+type FallbackAgent struct {
+	mu       sync.Mutex
+	chain    []*fallbackLink
+	cooldown time.Duration
+}
+
+// newFallbackChain puts primary, created from cfg, in front of an agent for
+// each of cfg's fallbacks
+func newFallbackChain(cfg *AgentConfig, primary Agent) (*FallbackAgent, error) {
+	primaryType := cfg.Type
+	if primaryType == "" {
+		primaryType = "claude"
+	}
+	a := &FallbackAgent{cooldown: fallbackCooldown}
+	a.chain = append(a.chain, &fallbackLink{agent: primary, name: ModelFallback{Type: primaryType, Model: cfg.Model}.String()})
+
+	for _, fallback := range cfg.Fallbacks {
+		fallbackCfg := *cfg
+		fallbackCfg.Fallbacks = nil
+		fallbackCfg.Model = fallback.Model
+		fallbackCfg.OpenCodeServers = 0 // Only the primary agent runs a server pool
+		if fallback.Type != "" && fallback.Type != primaryType {
+			fallbackCfg.Type = fallback.Type
+			fallbackCfg.Path = fallback.Type // The agent's CLI on the PATH
+			fallbackCfg.Provider = ""
+		}
+		if fallback.Model != "" && (fallbackCfg.Type == "amp" || fallbackCfg.Type == "worker") {
+			return nil, fmt.Errorf("model fallback %s: %s agents can't run another model; name an agent that can, e.g. opencode/%s",
+				fallback, fallbackCfg.Type, fallback.Model)
+		}
+		agent, err := NewAgent(&fallbackCfg)
+		if err != nil {
+			return nil, fmt.Errorf("model fallback %s: %w", fallback, err)
+		}
+		fallbackType := fallbackCfg.Type
+		if fallbackType == "" {
+			fallbackType = "claude"
+		}
+		a.chain = append(a.chain, &fallbackLink{agent: agent, name: ModelFallback{Type: fallbackType, Model: fallback.Model}.String()})
+	}
+	return a, nil
+}
+
+// ExecuteWithContext runs the task down the chain until a model isn't rate
+// limited, recording the one that produced the result in its Model. When
+// every model is, the last run's result is returned, signalled as rate
+// limited
+func (a *FallbackAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *ExecutionResult {
+	var earlier *ExecutionResult
+	for _, link := range a.available() {
+		if earlier != nil {
+			log.Printf("↪️  Task %s falls back to %s", task.ID, link.name)
+		}
+		result := link.agent.ExecuteWithContext(ctx, worktreePath, task, parentSpan...)
+		result.Model = link.name
+		if earlier != nil {
+			result.AddUsage(earlier)
+		}
+		if !rateLimited(result) || ctx.Err() != nil {
+			return result
+		}
+
+		log.Printf("⏳ %s is rate limited on task %s; passing it over for %v", link.name, task.ID, a.cooldown)
+		a.mu.Lock()
+		link.limitedUntil = time.Now().Add(a.cooldown)
+		a.mu.Unlock()
+		result.Signal = worker.SignalRateLimited
+		earlier = result
+	}
+	return earlier
+}
+
+// available returns the links that aren't cooling down from a rate limit, in
+// order; all of them when every one is
+func (a *FallbackAgent) available() []*fallbackLink {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	var links []*fallbackLink
+	for _, link := range a.chain {
+		if now.After(link.limitedUntil) {
+			links = append(links, link)
+		}
+	}
+	if len(links) == 0 {
+		return a.chain
+	}
+	return links
+}
+
+// rateLimited reports whether a run ended because of a rate limit
+func rateLimited(result *ExecutionResult) bool {
+	if result.Signal == worker.SignalRateLimited {
+		return true
+	}
+	if result.Success {
+		return false
+	}
+	// The limit is what stopped the agent, so it shows at the end
+	text := result.Output
+	if len(text) > 4096 {
+		text = text[len(text)-4096:]
+	}
+	if result.Error != nil {
+		text += "\n" + result.Error.Error()
+	}
+	text = strings.ToLower(text)
+	for _, pattern := range rateLimitPatterns {
+		if strings.Contains(text, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckInstalled verifies every agent of the chain is available
+func (a *FallbackAgent) CheckInstalled() error {
+	for i, link := range a.chain {
+		if err := link.agent.CheckInstalled(); err != nil {
+			if i == 0 {
+				return err
+			}
+			return fmt.Errorf("model fallback %s: %w", link.name, err)
+		}
+	}
+	return nil
+}
+
+// SetVerbose enables or disables verbose logging
+func (a *FallbackAgent) SetVerbose(v bool) {
+	for _, link := range a.chain {
+		link.agent.SetVerbose(v)
+	}
+}
+
+// SetProjectGuidelines sets project-specific guidelines for the agents
+func (a *FallbackAgent) SetProjectGuidelines(guidelines string) {
+	for _, link := range a.chain {
+		link.agent.SetProjectGuidelines(guidelines)
+	}
+}
+
+// SetContextManager sets the context window manager for the agents
+func (a *FallbackAgent) SetContextManager(manager *ctxmngr.Manager) {
+	for _, link := range a.chain {
+		link.agent.SetContextManager(manager)
+	}
+}
+
+// SetTaskContext sets recent completed tasks for context carrying
+func (a *FallbackAgent) SetTaskContext(recentTasks []*types.Task, taskContextCount int) {
+	for _, link := range a.chain {
+		link.agent.SetTaskContext(recentTasks, taskContextCount)
+	}
+}
+
+// Close releases what the agents hold for the run
+func (a *FallbackAgent) Close() error {
+	var errs []error
+	for _, link := range a.chain {
+		errs = append(errs, CloseAgent(link.agent))
+	}
+	return errors.Join(errs...)
+}
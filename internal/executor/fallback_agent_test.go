@@ -0,0 +1,159 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/worker"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestParseModelFallback(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []executor.ModelFallback
+	}{
+		{"", nil},
+		{"haiku", []executor.ModelFallback{{Model: "haiku"}}},
+		{"sonnet, haiku,opencode/openai/gpt-4o", []executor.ModelFallback{
+			{Model: "sonnet"}, {Model: "haiku"}, {Type: "opencode", Model: "openai/gpt-4o"},
+		}},
+		{"anthropic/claude-haiku,codex", []executor.ModelFallback{
+			{Model: "anthropic/claude-haiku"}, {Type: "codex"},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := executor.ParseModelFallback(tt.spec)
+		if err != nil {
+			t.Errorf("ParseModelFallback(%q): %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseModelFallback(%q) = %+v, want %+v", tt.spec, got, tt.want)
+		}
+	}
+
+	if _, err := executor.ParseModelFallback("opencode/"); err == nil {
+		t.Error("Expected an error for an agent without a model")
+	}
+}
+
+// createRateLimitedScript creates a custom agent script, run with the model
+// as its argument, that's rate limited on limitedModel and fails with
+// failure on failingModel. Each run's model is appended to the returned log
+func createRateLimitedScript(t *testing.T, limitedModel, failingModel string) (script, runLog string) {
+	t.Helper()
+	dir := t.TempDir()
+	script = filepath.Join(dir, "agent.sh")
+	runLog = filepath.Join(dir, "runs.log")
+	content := fmt.Sprintf(`#!/bin/bash
+echo "$1" >> %s
+if [ "$1" = %q ]; then
+  echo "API Error: 429 Too Many Requests"
+  exit 1
+fi
+if [ "$1" = %q ]; then
+  echo "compile error"
+  exit 1
+fi
+echo "done with $1"
+`, runLog, limitedModel, failingModel)
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return script, runLog
+}
+
+// readRuns returns the models a script ran with, in order
+func readRuns(t *testing.T, runLog string) []string {
+	t.Helper()
+	data, err := os.ReadFile(runLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(string(data))
+}
+
+func TestFallbackAgent_RateLimited(t *testing.T) {
+	script, runLog := createRateLimitedScript(t, "big", "")
+	agent, err := executor.NewAgent(&executor.AgentConfig{
+		Type:      "custom",
+		Model:     "big",
+		Fallbacks: []executor.ModelFallback{{Model: "small"}},
+		Custom:    executor.CustomAgentConfig{Command: script + " {{.Model}}"},
+		Timeout:   time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewAgent: %v", err)
+	}
+
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1", Title: "First"})
+	if !result.Success {
+		t.Fatalf("Expected the fallback to complete the task, got %v\n%s", result.Error, result.Output)
+	}
+	if result.Model != "custom/small" {
+		t.Errorf("Expected the result to record custom/small, got %q", result.Model)
+	}
+	if runs := readRuns(t, runLog); !reflect.DeepEqual(runs, []string{"big", "small"}) {
+		t.Errorf("Expected big then small, got %v", runs)
+	}
+
+	// The limited model is passed over for the tasks that follow
+	result = agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-2", Title: "Second"})
+	if !result.Success || result.Model != "custom/small" {
+		t.Errorf("Expected task-2 to run on custom/small, got %q: %v", result.Model, result.Error)
+	}
+	if runs := readRuns(t, runLog); !reflect.DeepEqual(runs, []string{"big", "small", "small"}) {
+		t.Errorf("Expected the rate limited model passed over, got %v", runs)
+	}
+}
+
+func TestFallbackAgent_OtherFailuresDontFallBack(t *testing.T) {
+	script, runLog := createRateLimitedScript(t, "small", "big")
+	agent, err := executor.NewAgent(&executor.AgentConfig{
+		Type:      "custom",
+		Model:     "big",
+		Fallbacks: []executor.ModelFallback{{Model: "small"}},
+		Custom:    executor.CustomAgentConfig{Command: script + " {{.Model}}"},
+		Timeout:   time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewAgent: %v", err)
+	}
+
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1", Title: "First"})
+	if result.Success || result.Model != "custom/big" {
+		t.Errorf("Expected custom/big's failure, got %q (success %v)", result.Model, result.Success)
+	}
+	if result.Signal == worker.SignalRateLimited {
+		t.Error("Expected a failure that isn't a rate limit")
+	}
+	if runs := readRuns(t, runLog); !reflect.DeepEqual(runs, []string{"big"}) {
+		t.Errorf("Expected no fallback, got %v", runs)
+	}
+}
+
+func TestFallbackAgent_AllRateLimited(t *testing.T) {
+	script, _ := createRateLimitedScript(t, "big", "")
+	agent, err := executor.NewAgent(&executor.AgentConfig{
+		Type:      "custom",
+		Model:     "big",
+		Fallbacks: []executor.ModelFallback{{Model: "big"}},
+		Custom:    executor.CustomAgentConfig{Command: script + " {{.Model}}"},
+		Timeout:   time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewAgent: %v", err)
+	}
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1", Title: "First"})
+	if result.Success || result.Signal != worker.SignalRateLimited {
+		t.Errorf("Expected a rate limited failure, got success %v, signal %q", result.Success, result.Signal)
+	}
+}
@@ -0,0 +1,264 @@
+// Package executor provides Goose agent implementation
+package executor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/outcome"
+	"github.com/cloud-shuttle/drover/internal/taskcontext"
+	"github.com/cloud-shuttle/drover/pkg/telemetry"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GooseAgent runs tasks using Block's goose CLI
+// See: https://block.github.io/goose/docs/guides/goose-cli-commands
+type GooseAgent struct {
+	goosePath         string
+	provider          string // LLM provider goose uses, e.g. "openai"; empty for goose's configured one
+	model             string // Model goose runs; empty for goose's configured one
+	timeout           time.Duration
+	verbose           bool
+	projectGuidelines string
+	contextManager    *ctxmngr.Manager
+	recentTasks       []*types.Task
+	taskContextCount  int
+	prompts           *PromptTemplates // Templates the prompt comes from; nil for the built-in prompt
+}
+
+// NewGooseAgent creates a new Goose agent
+func NewGooseAgent(goosePath string, timeout time.Duration) *GooseAgent {
+	return &GooseAgent{
+		goosePath: goosePath,
+		timeout:   timeout,
+		verbose:   false,
+	}
+}
+
+// SetModel sets the provider and model goose runs; empty keeps goose's own
+// configuration
+func (a *GooseAgent) SetModel(provider, model string) {
+	a.provider = provider
+	a.model = model
+}
+
+// SetVerbose enables or disables verbose logging
+func (a *GooseAgent) SetVerbose(v bool) {
+	a.verbose = v
+}
+
+// SetPromptTemplates has the agent's prompts rendered from templates
+func (a *GooseAgent) SetPromptTemplates(prompts *PromptTemplates) {
+	a.prompts = prompts
+}
+
+// SetProjectGuidelines sets project-specific guidelines for the agent
+func (a *GooseAgent) SetProjectGuidelines(guidelines string) {
+	a.projectGuidelines = guidelines
+}
+
+// SetContextManager sets the context window manager for the agent
+func (a *GooseAgent) SetContextManager(manager *ctxmngr.Manager) {
+	a.contextManager = manager
+}
+
+// SetTaskContext sets recent completed tasks for context carrying
+func (a *GooseAgent) SetTaskContext(recentTasks []*types.Task, taskContextCount int) {
+	a.recentTasks = recentTasks
+	a.taskContextCount = taskContextCount
+}
+
+// ExecuteWithContext runs a task with a context and returns the execution result
+func (a *GooseAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *ExecutionResult {
+	// Start telemetry span for agent execution
+	var agentCtx context.Context
+	var span trace.Span
+	if len(parentSpan) > 0 && parentSpan[0] != nil {
+		model := a.model
+		if model == "" {
+			model = "unknown"
+		}
+		agentCtx, span = telemetry.StartAgentSpan(ctx, telemetry.AgentTypeGoose, model,
+			attribute.String(telemetry.KeyTaskID, task.ID),
+			attribute.String(telemetry.KeyTaskTitle, task.Title),
+		)
+		defer span.End()
+	} else {
+		agentCtx = ctx
+		span = trace.SpanFromContext(ctx)
+	}
+
+	// Record agent prompt
+	telemetry.RecordAgentPrompt(agentCtx, telemetry.AgentTypeGoose)
+
+	// Build the prompt
+	prompt := a.buildPrompt(task)
+	session := gooseSession(task)
+
+	// Log what we're sending to goose (verbose only)
+	if a.verbose {
+		log.Printf("🤖 Sending prompt to goose session %s (length: %d chars)", session, len(prompt))
+		log.Printf("📝 Prompt preview: %s", truncateString(prompt, 200))
+	}
+
+	// goose runs until the model stops calling tools, which can be a long
+	// time; bound it by the task timeout
+	runCtx := ctx
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(runCtx, a.goosePath, "run", "--name", session, "--text", prompt)
+	cmd.Env = a.env(task)
+	detach(cmd)
+	cmd.Dir = worktreePath
+	sandboxCmd(ctx, cmd)
+	cpuLimitCmd(ctx, cmd)
+
+	// Capture output while also streaming to stdout/stderr for real-time viewing
+	output := newRunOutput(ctx, task)
+	cmd.Stdout = output.redact(io.MultiWriter(os.Stdout, output.stdout))
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
+
+	start := time.Now()
+	if a.verbose {
+		log.Printf("⏱️  goose execution started at %s", start.Format("15:04:05"))
+	}
+	err := cmd.Run()
+	duration := time.Since(start)
+
+	// Combine stdout and stderr for the result
+	output.close()
+	fullOutput := output.String()
+
+	// Log exit code regardless of success/failure
+	if err != nil {
+		exitCode := 1
+		if exitError, ok := err.(*exec.ExitError); ok {
+			exitCode = exitError.ExitCode()
+		}
+		if a.verbose {
+			log.Printf("❌ goose exited with code %d after %v", exitCode, duration)
+		}
+
+		// Record error
+		telemetry.RecordAgentError(agentCtx, telemetry.AgentTypeGoose, "execution_failed")
+
+		if runCtx.Err() == context.DeadlineExceeded {
+			telemetry.RecordError(span, err, "TimeoutError", telemetry.ErrorCategoryTimeout)
+			telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeGoose, duration)
+			return &ExecutionResult{
+				Success:  false,
+				Output:   fullOutput,
+				Error:    fmt.Errorf("goose timed out after %v (session %s)", duration, session),
+				Duration: duration,
+			}
+		}
+		telemetry.RecordError(span, err, "ExecutionError", telemetry.ErrorCategoryAgent)
+		telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeGoose, duration)
+		return &ExecutionResult{
+			Success:  false,
+			Output:   fullOutput,
+			Error:    fmt.Errorf("goose exited with code %d after %v (session %s): %w", exitCode, duration, session, err),
+			Duration: duration,
+		}
+	}
+
+	if a.verbose {
+		log.Printf("✅ goose completed successfully in %v", duration)
+	}
+
+	// Record successful completion
+	telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeGoose, duration)
+
+	return readVerdictBlock(&ExecutionResult{
+		Success:  true,
+		Output:   fullOutput,
+		Error:    nil,
+		Duration: duration,
+	}, "")
+}
+
+// gooseSession names the goose session of a task's attempt, so it can be
+// looked at afterwards with 'goose session --resume --name'
+func gooseSession(task *types.Task) string {
+	return fmt.Sprintf("drover-%s-%d", task.ID, task.Attempts+1)
+}
+
+// env is the environment goose runs with: the task's, plus the provider and
+// model, and approval-free tool use since nobody is there to approve
+func (a *GooseAgent) env(task *types.Task) []string {
+	env := commandEnv(task)
+	if env == nil {
+		env = os.Environ()
+	}
+	env = append(env, "GOOSE_MODE=auto")
+	if a.provider != "" {
+		env = append(env, "GOOSE_PROVIDER="+a.provider)
+	}
+	if a.model != "" {
+		env = append(env, "GOOSE_MODEL="+a.model)
+	}
+	return env
+}
+
+// CheckInstalled verifies goose CLI is available
+func (a *GooseAgent) CheckInstalled() error {
+	cmd := exec.Command(a.goosePath, "--version")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("goose not found at %s: %w\n%s", a.goosePath, err, output)
+	}
+	return nil
+}
+
+// buildPrompt creates the goose prompt for a task
+func (a *GooseAgent) buildPrompt(task *types.Task) string {
+	if prompt, ok := a.prompts.render(task, a.projectGuidelines, a.recentTasks, a.taskContextCount); ok {
+		return prompt
+	}
+
+	var prompt strings.Builder
+
+	// Start with project guidelines if configured
+	if a.projectGuidelines != "" {
+		prompt.WriteString("=== PROJECT GUIDELINES ===\n")
+		prompt.WriteString(a.projectGuidelines)
+		prompt.WriteString("\n============================\n\n")
+	}
+
+	// Inject recent task context if available
+	if a.taskContextCount > 0 && len(a.recentTasks) > 0 {
+		taskContext := taskcontext.BuildContext(a.recentTasks, task, a.taskContextCount)
+		if taskContext != "" {
+			prompt.WriteString(taskContext)
+		}
+	}
+
+	prompt.WriteString(fmt.Sprintf("Task: %s\n", task.Title))
+
+	if task.Description != "" {
+		prompt.WriteString(fmt.Sprintf("Description: %s\n", task.Description))
+	}
+
+	prompt.WriteString("\nPlease implement this task completely. Don't commit; the changes are committed for you.")
+
+	if len(task.EpicID) > 0 {
+		prompt.WriteString(fmt.Sprintf("\n\nThis task is part of epic: %s", task.EpicID))
+	}
+
+	prompt.WriteString("\n\n" + outcome.VerdictInstructions)
+
+	return prompt.String()
+}
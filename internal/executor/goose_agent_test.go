@@ -0,0 +1,71 @@
+package executor_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// createMockGooseScript creates a script that records its arguments and the
+// GOOSE_ variables it sees, then sleeps and exits with exitCode
+func createMockGooseScript(t *testing.T, dir string, exitCode int, sleep string) (script, recordFile string) {
+	t.Helper()
+	script = filepath.Join(dir, "mock-goose.sh")
+	recordFile = filepath.Join(dir, "record.txt")
+	content := fmt.Sprintf(`#!/bin/bash
+# Mock goose script for testing
+printf '%%s\n' "$@" > %[1]s
+env | grep '^GOOSE_' >> %[1]s
+sleep %[2]s
+exit %[3]d
+`, recordFile, sleep, exitCode)
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to create mock goose script: %v", err)
+	}
+	return script, recordFile
+}
+
+func TestGooseAgent_Execute(t *testing.T) {
+	script, recordFile := createMockGooseScript(t, t.TempDir(), 0, "0")
+
+	agent := executor.NewGooseAgent(script, time.Minute)
+	agent.SetModel("openai", "gpt-4o")
+	task := &types.Task{ID: "task-7", Title: "Add a health endpoint", Attempts: 1}
+
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), task)
+	if !result.Success {
+		t.Fatalf("Execute failed: %v", result.Error)
+	}
+
+	recorded, err := os.ReadFile(recordFile)
+	if err != nil {
+		t.Fatalf("Reading recorded invocation: %v", err)
+	}
+	got := string(recorded)
+	for _, want := range []string{"run\n--name\ndrover-task-7-2\n--text\n", "Task: Add a health endpoint", "GOOSE_MODE=auto", "GOOSE_PROVIDER=openai", "GOOSE_MODEL=gpt-4o"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in the invocation, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestGooseAgent_Timeout(t *testing.T) {
+	script, _ := createMockGooseScript(t, t.TempDir(), 0, "5")
+
+	agent := executor.NewGooseAgent(script, 100*time.Millisecond)
+	start := time.Now()
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-7", Title: "Loop forever"})
+	if result.Success || result.Error == nil || !strings.Contains(result.Error.Error(), "timed out") {
+		t.Fatalf("Expected a timeout, got success=%v error=%v", result.Success, result.Error)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Expected goose stopped at the timeout, took %v", elapsed)
+	}
+}
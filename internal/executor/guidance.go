@@ -0,0 +1,56 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// guidanceFile is the file drover-worker watches in a worktree for guidance
+// added while its task runs
+const guidanceFile = ".drover-guidance.jsonl"
+
+// guidanceLine is a line of the guidance file
+type guidanceLine struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// CreateGuidanceFile creates an empty guidance file in the worktree, for
+// guidance added while its task runs, and returns its path. The file is kept
+// out of task commits
+func CreateGuidanceFile(worktreePath string) (string, error) {
+	path := filepath.Join(worktreePath, guidanceFile)
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		return "", fmt.Errorf("writing %s: %w", guidanceFile, err)
+	}
+	if err := excludeFromCommits(worktreePath, "/"+guidanceFile); err != nil {
+		return "", fmt.Errorf("excluding %s from commits: %w", guidanceFile, err)
+	}
+	return path, nil
+}
+
+// AppendGuidance adds guidance to a guidance file, one line each, for the
+// agent watching it to pick up
+func AppendGuidance(path string, guidance []*types.GuidanceMessage) error {
+	var data []byte
+	for _, g := range guidance {
+		line, err := json.Marshal(guidanceLine{ID: g.ID, Message: g.Message})
+		if err != nil {
+			return err
+		}
+		data = append(append(data, line...), '\n')
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,326 @@
+// Package executor provides the direct LLM API agent implementation
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/llmproxy"
+	llmclient "github.com/cloud-shuttle/drover/internal/llmproxy/client"
+	"github.com/cloud-shuttle/drover/internal/taskcontext"
+	"github.com/cloud-shuttle/drover/pkg/telemetry"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// DefaultLLMURL is the API the llm agent calls when none is configured:
+	// a local 'drover proxy serve'
+	DefaultLLMURL = "http://localhost:8080"
+
+	// defaultLLMModel is the model the llm agent asks when none is configured
+	defaultLLMModel = "gpt-4o-mini"
+
+	// llmMaxRounds bounds how often the model is asked again after its diff
+	// didn't apply
+	llmMaxRounds = 3
+
+	// llmMaxFiles is how many of the repository's files the prompt lists
+	llmMaxFiles = 500
+
+	// llmMaxFileBytes and llmMaxContextBytes bound the file contents sent
+	llmMaxFileBytes    = 64 * 1024
+	llmMaxContextBytes = 256 * 1024
+)
+
+const llmSystemPrompt = "You are a careful software engineer making a small change to a repository. " +
+	"Reply with the complete change as a single unified diff (as produced by 'git diff') in a ```diff block, " +
+	"with paths relative to the repository root, a/ and b/ prefixes, and enough context lines to apply cleanly. " +
+	"Use /dev/null as the old path to create a file. Keep any explanation short and outside the block."
+
+// diffBlock matches a fenced diff in a model's reply
+var diffBlock = regexp.MustCompile("(?s)```(?:diff|patch)[^\\n]*\\n(.*?)```")
+
+// LLMAgent runs simple tasks, such as docs and small patches, by asking an
+// OpenAI-compatible chat API for a diff and applying it, with no agent CLI
+type LLMAgent struct {
+	client            *llmclient.Client
+	url               string
+	model             string
+	timeout           time.Duration
+	verbose           bool
+	projectGuidelines string
+	contextManager    *ctxmngr.Manager
+	recentTasks       []*types.Task
+	taskContextCount  int
+}
+
+// NewLLMAgent creates an agent calling the chat API at url, such as the
+// drover proxy or any OpenAI-compatible endpoint
+func NewLLMAgent(url, apiKey, model string, timeout time.Duration) *LLMAgent {
+	if url == "" {
+		url = DefaultLLMURL
+	}
+	if model == "" {
+		model = defaultLLMModel
+	}
+	return &LLMAgent{
+		client:  llmclient.NewClient(llmclient.Config{BaseURL: url, APIKey: apiKey, Timeout: timeout}),
+		url:     url,
+		model:   model,
+		timeout: timeout,
+	}
+}
+
+// SetVerbose enables or disables verbose logging
+func (a *LLMAgent) SetVerbose(v bool) {
+	a.verbose = v
+}
+
+// SetProjectGuidelines sets project-specific guidelines for the agent
+func (a *LLMAgent) SetProjectGuidelines(guidelines string) {
+	a.projectGuidelines = guidelines
+}
+
+// SetContextManager sets the context window manager for the agent
+func (a *LLMAgent) SetContextManager(manager *ctxmngr.Manager) {
+	a.contextManager = manager
+}
+
+// SetTaskContext sets recent completed tasks for context carrying
+func (a *LLMAgent) SetTaskContext(recentTasks []*types.Task, taskContextCount int) {
+	a.recentTasks = recentTasks
+	a.taskContextCount = taskContextCount
+}
+
+// ExecuteWithContext runs a task with a context and returns the execution result
+func (a *LLMAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *ExecutionResult {
+	// Start telemetry span for agent execution
+	var agentCtx context.Context
+	var span trace.Span
+	if len(parentSpan) > 0 && parentSpan[0] != nil {
+		agentCtx, span = telemetry.StartAgentSpan(ctx, telemetry.AgentTypeLLM, a.model,
+			attribute.String(telemetry.KeyTaskID, task.ID),
+			attribute.String(telemetry.KeyTaskTitle, task.Title),
+		)
+		defer span.End()
+	} else {
+		agentCtx = ctx
+		span = trace.SpanFromContext(ctx)
+	}
+
+	// Record agent prompt
+	telemetry.RecordAgentPrompt(agentCtx, telemetry.AgentTypeLLM)
+
+	if a.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+	}
+
+	messages := []llmproxy.Message{
+		{Role: llmproxy.RoleSystem, Content: llmSystemPrompt},
+		{Role: llmproxy.RoleUser, Content: a.buildPrompt(worktreePath, task)},
+	}
+	if a.verbose {
+		log.Printf("🤖 Asking %s at %s for a diff (prompt: %d chars)", a.model, a.url, len(messages[1].Content))
+	}
+
+	result := &ExecutionResult{}
+	var transcript strings.Builder
+	start := time.Now()
+	defer func() {
+		result.Output = transcript.String()
+		result.Duration = time.Since(start)
+		telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeLLM, result.Duration)
+	}()
+
+	for round := 1; ; round++ {
+		resp, err := a.client.Chat(ctx, &llmproxy.ChatRequest{Model: a.model, Messages: messages, Temperature: 0.2})
+		if err != nil {
+			telemetry.RecordAgentError(agentCtx, telemetry.AgentTypeLLM, "execution_failed")
+			if ctx.Err() == context.DeadlineExceeded {
+				telemetry.RecordError(span, err, "TimeoutError", telemetry.ErrorCategoryTimeout)
+				result.Error = fmt.Errorf("%s timed out after %v", a.model, time.Since(start))
+			} else {
+				telemetry.RecordError(span, err, "ExecutionError", telemetry.ErrorCategoryAgent)
+				result.Error = fmt.Errorf("calling %s: %w", a.model, err)
+			}
+			return result
+		}
+		result.InputTokens += int64(resp.Usage.PromptTokens)
+		result.OutputTokens += int64(resp.Usage.CompletionTokens)
+		if len(resp.Choices) == 0 {
+			result.Error = fmt.Errorf("%s returned no reply", a.model)
+			return result
+		}
+		reply := resp.Choices[0].Message.Content
+		transcript.WriteString(reply)
+		transcript.WriteString("\n")
+		if a.verbose {
+			log.Printf("📝 Reply from %s (round %d): %s", a.model, round, truncateString(reply, 200))
+		}
+
+		err = applyDiff(ctx, worktreePath, extractDiff(reply))
+		if err == nil {
+			break
+		}
+		fmt.Fprintf(&transcript, "⚠️  %v\n", err)
+		if round == llmMaxRounds {
+			telemetry.RecordAgentError(agentCtx, telemetry.AgentTypeLLM, "diff_rejected")
+			result.Error = fmt.Errorf("%s's diff didn't apply after %d rounds: %w", a.model, round, err)
+			return result
+		}
+		messages = append(messages,
+			llmproxy.Message{Role: llmproxy.RoleAssistant, Content: reply},
+			llmproxy.Message{Role: llmproxy.RoleUser, Content: fmt.Sprintf("The diff could not be applied: %v\nReply with a corrected, complete diff against the original files.", err)},
+		)
+	}
+
+	if a.verbose {
+		log.Printf("✅ Applied %s's diff in %v", a.model, time.Since(start))
+	}
+	result.Success = true
+	return result
+}
+
+// extractDiff returns the unified diff in a model's reply: its fenced diff
+// blocks, or the reply itself when it is a bare diff
+func extractDiff(reply string) string {
+	var diff strings.Builder
+	for _, m := range diffBlock.FindAllStringSubmatch(reply, -1) {
+		diff.WriteString(m[1])
+	}
+	if diff.Len() > 0 {
+		return diff.String()
+	}
+	trimmed := strings.TrimSpace(reply)
+	if strings.HasPrefix(trimmed, "diff --git") || strings.HasPrefix(trimmed, "--- ") {
+		return trimmed + "\n"
+	}
+	return ""
+}
+
+// applyDiff applies a unified diff to the worktree; git applies all of it
+// or nothing
+func applyDiff(ctx context.Context, worktreePath, diff string) error {
+	if strings.TrimSpace(diff) == "" {
+		return errors.New("the reply contained no diff")
+	}
+	cmd := exec.CommandContext(ctx, "git", "apply", "--recount", "--whitespace=nowarn", "-")
+	cmd.Dir = worktreePath
+	cmd.Stdin = strings.NewReader(diff)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git apply: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CheckInstalled verifies the chat API answers
+func (a *LLMAgent) CheckInstalled() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := a.client.GetModels(ctx); err != nil {
+		return fmt.Errorf("LLM API not available at %s (start one with 'drover proxy serve' or set DROVER_LLM_PROXY_URL): %w", a.url, err)
+	}
+	return nil
+}
+
+// buildPrompt creates the prompt for a task: the task, the repository's
+// files, and the contents of the ones it mentions
+func (a *LLMAgent) buildPrompt(worktreePath string, task *types.Task) string {
+	var prompt strings.Builder
+
+	// Start with project guidelines if configured
+	if a.projectGuidelines != "" {
+		prompt.WriteString("=== PROJECT GUIDELINES ===\n")
+		prompt.WriteString(a.projectGuidelines)
+		prompt.WriteString("\n============================\n\n")
+	}
+
+	// Inject recent task context if available
+	if a.taskContextCount > 0 && len(a.recentTasks) > 0 {
+		taskContext := taskcontext.BuildContext(a.recentTasks, task, a.taskContextCount)
+		if taskContext != "" {
+			prompt.WriteString(taskContext)
+		}
+	}
+
+	prompt.WriteString(fmt.Sprintf("Task: %s\n", task.Title))
+
+	if task.Description != "" {
+		prompt.WriteString(fmt.Sprintf("Description: %s\n", task.Description))
+	}
+
+	if len(task.EpicID) > 0 {
+		prompt.WriteString(fmt.Sprintf("\nThis task is part of epic: %s\n", task.EpicID))
+	}
+
+	files := trackedFiles(worktreePath)
+	if len(files) > 0 {
+		prompt.WriteString("\n=== REPOSITORY FILES ===\n")
+		for i, file := range files {
+			if i == llmMaxFiles {
+				prompt.WriteString(fmt.Sprintf("... and %d more\n", len(files)-llmMaxFiles))
+				break
+			}
+			prompt.WriteString(file + "\n")
+		}
+	}
+
+	mentioned := task.Title + "\n" + task.Description
+	budget := llmMaxContextBytes
+	for _, file := range files {
+		if !mentionsFile(mentioned, file) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(worktreePath, file))
+		if err != nil || len(content) > llmMaxFileBytes || len(content) > budget {
+			continue
+		}
+		budget -= len(content)
+		prompt.WriteString(fmt.Sprintf("\n=== %s ===\n%s", file, content))
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			prompt.WriteString("\n")
+		}
+	}
+
+	prompt.WriteString("\nReply with a unified diff implementing this task completely.")
+
+	return prompt.String()
+}
+
+// trackedFiles lists the files git tracks in the worktree
+func trackedFiles(worktreePath string) []string {
+	out, err := exec.Command("git", "-C", worktreePath, "ls-files").Output()
+	if err != nil {
+		return nil
+	}
+	var files []string
+	for _, file := range strings.Split(string(out), "\n") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// mentionsFile reports whether text names file, by its path or, for names
+// distinctive enough to tell apart, its base name
+func mentionsFile(text, file string) bool {
+	if strings.Contains(text, file) {
+		return true
+	}
+	base := filepath.Base(file)
+	return strings.Contains(base, ".") && len(base) > 4 && strings.Contains(text, base)
+}
@@ -0,0 +1,122 @@
+package executor_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/llmproxy"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// newLLMRepo creates a git repository holding a README
+func newLLMRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Demo\n\nUsage: run it.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init", "-q"}, {"add", "README.md"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return dir
+}
+
+// newMockChatAPI serves the replies in turn as chat completions, recording
+// the requests it gets
+func newMockChatAPI(t *testing.T, replies ...string) (*httptest.Server, *[]llmproxy.ChatRequest) {
+	t.Helper()
+	var requests []llmproxy.ChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req llmproxy.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requests = append(requests, req)
+		reply := replies[min(len(requests), len(replies))-1]
+		json.NewEncoder(w).Encode(llmproxy.ChatResponse{
+			Choices: []llmproxy.Choice{{Message: llmproxy.Message{Role: llmproxy.RoleAssistant, Content: reply}}},
+			Usage:   llmproxy.Usage{PromptTokens: 100, CompletionTokens: 20},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+const readmeDiff = "Here is the change:\n```diff\n" +
+	"--- a/README.md\n+++ b/README.md\n@@ -1,3 +1,5 @@\n # Demo\n \n Usage: run it.\n+\n+License: MIT\n" +
+	"```\n"
+
+func TestLLMAgent_AppliesDiff(t *testing.T) {
+	repo := newLLMRepo(t)
+	server, requests := newMockChatAPI(t, readmeDiff)
+
+	agent := executor.NewLLMAgent(server.URL, "key", "test-model", time.Minute)
+	result := agent.ExecuteWithContext(context.Background(), repo, &types.Task{ID: "task-1", Title: "Add a license line to README.md"})
+	if !result.Success {
+		t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+	}
+
+	readme, _ := os.ReadFile(filepath.Join(repo, "README.md"))
+	if !strings.Contains(string(readme), "License: MIT") {
+		t.Errorf("Expected the diff applied, README is:\n%s", readme)
+	}
+	if result.InputTokens != 100 || result.OutputTokens != 20 {
+		t.Errorf("Expected the reply's usage recorded, got %d in / %d out", result.InputTokens, result.OutputTokens)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("Expected one request, got %d", len(*requests))
+	}
+	req := (*requests)[0]
+	if req.Model != "test-model" {
+		t.Errorf("Expected model test-model, got %q", req.Model)
+	}
+	prompt := req.Messages[len(req.Messages)-1].Content
+	if !strings.Contains(prompt, "=== README.md ===\n# Demo") {
+		t.Errorf("Expected the mentioned file's contents in the prompt, got:\n%s", prompt)
+	}
+}
+
+func TestLLMAgent_RetriesRejectedDiff(t *testing.T) {
+	repo := newLLMRepo(t)
+	bad := "```diff\n--- a/README.md\n+++ b/README.md\n@@ -1,1 +1,1 @@\n-# Nope\n+# Demo app\n```"
+	server, requests := newMockChatAPI(t, "I can't see the file.", bad, readmeDiff)
+
+	agent := executor.NewLLMAgent(server.URL, "", "test-model", time.Minute)
+	result := agent.ExecuteWithContext(context.Background(), repo, &types.Task{ID: "task-1", Title: "Add a license line to README.md"})
+	if !result.Success {
+		t.Fatalf("Execute failed: %v\n%s", result.Error, result.Output)
+	}
+	if len(*requests) != 3 {
+		t.Fatalf("Expected three rounds, got %d", len(*requests))
+	}
+	feedback := (*requests)[2].Messages[len((*requests)[2].Messages)-1].Content
+	if !strings.Contains(feedback, "git apply") {
+		t.Errorf("Expected git's error fed back to the model, got %q", feedback)
+	}
+}
+
+func TestLLMAgent_GivesUp(t *testing.T) {
+	repo := newLLMRepo(t)
+	server, requests := newMockChatAPI(t, "No diff from me.")
+
+	agent := executor.NewLLMAgent(server.URL, "", "test-model", time.Minute)
+	result := agent.ExecuteWithContext(context.Background(), repo, &types.Task{ID: "task-1", Title: "Do something"})
+	if result.Success || result.Error == nil || !strings.Contains(result.Error.Error(), "no diff") {
+		t.Fatalf("Expected a failure for the missing diff, got success=%v error=%v", result.Success, result.Error)
+	}
+	if len(*requests) != 3 {
+		t.Errorf("Expected three rounds before giving up, got %d", len(*requests))
+	}
+}
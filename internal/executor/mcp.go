@@ -0,0 +1,79 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// MCP server config files drover writes into a worktree. A repository that
+// tracks its own .mcp.json keeps it untouched, and gets the merged servers
+// in mcpDroverFile instead
+const (
+	mcpConfigFile = ".mcp.json"
+	mcpDroverFile = ".mcp.drover.json"
+)
+
+// MCPServer is an MCP server an agent starts for a task, such as database
+// access or a ticket lookup
+type MCPServer struct {
+	Command string            `json:"command"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+// mcpConfig is the .mcp.json format Claude Code reads
+type mcpConfig struct {
+	MCPServers map[string]json.RawMessage `json:"mcpServers"`
+}
+
+// WriteMCPConfig materializes servers into the worktree's .mcp.json, next to
+// any servers the repository configures there itself, and returns the path
+// of the file written. Servers drover configures replace the repository's of
+// the same name. The file is kept out of task commits
+func WriteMCPConfig(worktreePath string, servers map[string]MCPServer) (string, error) {
+	config := mcpConfig{MCPServers: map[string]json.RawMessage{}}
+	existing, err := os.ReadFile(filepath.Join(worktreePath, mcpConfigFile))
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("reading %s: %w", mcpConfigFile, err)
+	}
+	if len(existing) > 0 {
+		if err := json.Unmarshal(existing, &config); err != nil {
+			return "", fmt.Errorf("parsing %s: %w", mcpConfigFile, err)
+		}
+		if config.MCPServers == nil {
+			config.MCPServers = map[string]json.RawMessage{}
+		}
+	}
+	for name, server := range servers {
+		raw, err := json.Marshal(server)
+		if err != nil {
+			return "", fmt.Errorf("MCP server %s: %w", name, err)
+		}
+		config.MCPServers[name] = raw
+	}
+
+	file := mcpConfigFile
+	if tracked(worktreePath, mcpConfigFile) {
+		file = mcpDroverFile
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(worktreePath, file)
+	if err := os.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return "", fmt.Errorf("writing %s: %w", file, err)
+	}
+	if err := excludeFromCommits(worktreePath, "/"+file); err != nil {
+		return "", fmt.Errorf("excluding %s from commits: %w", file, err)
+	}
+	return path, nil
+}
+
+// tracked reports whether git tracks a file of the worktree
+func tracked(worktreePath, file string) bool {
+	return exec.Command("git", "-C", worktreePath, "ls-files", "--error-unmatch", "--", file).Run() == nil
+}
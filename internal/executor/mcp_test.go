@@ -0,0 +1,88 @@
+package executor_test
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+)
+
+// readMCPServers reads the servers of an .mcp.json file
+func readMCPServers(t *testing.T, path string) map[string]executor.MCPServer {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config struct {
+		MCPServers map[string]executor.MCPServer `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatalf("parsing %s: %v", path, err)
+	}
+	return config.MCPServers
+}
+
+var testMCPServers = map[string]executor.MCPServer{
+	"tickets": {Command: "ticket-mcp", Args: []string{"--project", "demo"}, Env: map[string]string{"TICKETS_URL": "http://localhost:9000"}},
+}
+
+func TestWriteMCPConfig_MergesRepoServers(t *testing.T) {
+	repo := newLLMRepo(t)
+	existing := `{"mcpServers":{"docs":{"command":"docs-mcp"},"tickets":{"command":"old"}}}`
+	if err := os.WriteFile(filepath.Join(repo, ".mcp.json"), []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := executor.WriteMCPConfig(repo, testMCPServers)
+	if err != nil {
+		t.Fatalf("WriteMCPConfig: %v", err)
+	}
+	if path != filepath.Join(repo, ".mcp.json") {
+		t.Errorf("Expected .mcp.json written, got %s", path)
+	}
+	servers := readMCPServers(t, path)
+	if servers["docs"].Command != "docs-mcp" {
+		t.Errorf("Expected the repository's own server kept, got %+v", servers)
+	}
+	if tickets := servers["tickets"]; tickets.Command != "ticket-mcp" || tickets.Env["TICKETS_URL"] == "" {
+		t.Errorf("Expected drover's server to replace the repository's, got %+v", tickets)
+	}
+
+	status, err := exec.Command("git", "-C", repo, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(status), ".mcp.json") {
+		t.Errorf("Expected .mcp.json kept out of commits, git status:\n%s", status)
+	}
+}
+
+func TestWriteMCPConfig_LeavesTrackedConfigAlone(t *testing.T) {
+	repo := newLLMRepo(t)
+	existing := `{"mcpServers":{"docs":{"command":"docs-mcp"}}}`
+	if err := os.WriteFile(filepath.Join(repo, ".mcp.json"), []byte(existing), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("git", "-C", repo, "add", ".mcp.json").CombinedOutput(); err != nil {
+		t.Fatalf("git add: %v\n%s", err, out)
+	}
+
+	path, err := executor.WriteMCPConfig(repo, testMCPServers)
+	if err != nil {
+		t.Fatalf("WriteMCPConfig: %v", err)
+	}
+	if filepath.Base(path) != ".mcp.drover.json" {
+		t.Fatalf("Expected the tracked .mcp.json left alone, got %s", path)
+	}
+	if data, _ := os.ReadFile(filepath.Join(repo, ".mcp.json")); string(data) != existing {
+		t.Errorf("Tracked .mcp.json changed to:\n%s", data)
+	}
+	if servers := readMCPServers(t, path); len(servers) != 2 {
+		t.Errorf("Expected both servers in %s, got %+v", path, servers)
+	}
+}
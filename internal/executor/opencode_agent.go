@@ -12,6 +12,7 @@ import (
 	"time"
 
 	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/outcome"
 	"github.com/cloud-shuttle/drover/internal/taskcontext"
 	"github.com/cloud-shuttle/drover/pkg/telemetry"
 	"github.com/cloud-shuttle/drover/pkg/types"
@@ -22,12 +23,16 @@ import (
 // OpenCodeAgent runs tasks using OpenCode CLI
 type OpenCodeAgent struct {
 	opencodePath      string
+	model             string // Model OpenCode runs, as provider/model; empty = OpenCode's configured one
 	timeout           time.Duration
 	verbose           bool
 	projectGuidelines string
 	contextManager    *ctxmngr.Manager
 	recentTasks       []*types.Task
 	taskContextCount  int
+	prompts           *PromptTemplates // Templates the prompt comes from; nil for the built-in prompt
+	serverURL         string              // Running opencode server to attach to (opencode run --attach)
+	servers           *OpenCodeServerPool // Managed warm servers; takes precedence over serverURL
 }
 
 // NewOpenCodeAgent creates a new OpenCode agent
@@ -39,11 +44,39 @@ func NewOpenCodeAgent(opencodePath string, timeout time.Duration) *OpenCodeAgent
 	}
 }
 
+// SetModel sets the model OpenCode runs, e.g. "openai/gpt-4o"
+func (a *OpenCodeAgent) SetModel(model string) {
+	a.model = model
+}
+
+// SetServerURL attaches every execution to an already running opencode server
+func (a *OpenCodeAgent) SetServerURL(url string) {
+	a.serverURL = url
+}
+
+// SetServerPool attaches executions to the least busy server in a managed pool
+func (a *OpenCodeAgent) SetServerPool(pool *OpenCodeServerPool) {
+	a.servers = pool
+}
+
+// Close stops the managed server pool, if any
+func (a *OpenCodeAgent) Close() error {
+	if a.servers != nil {
+		a.servers.Stop()
+	}
+	return nil
+}
+
 // SetVerbose enables or disables verbose logging
 func (a *OpenCodeAgent) SetVerbose(v bool) {
 	a.verbose = v
 }
 
+// SetPromptTemplates has the agent's prompts rendered from templates
+func (a *OpenCodeAgent) SetPromptTemplates(prompts *PromptTemplates) {
+	a.prompts = prompts
+}
+
 // SetProjectGuidelines sets project-specific guidelines for the agent
 func (a *OpenCodeAgent) SetProjectGuidelines(guidelines string) {
 	a.projectGuidelines = guidelines
@@ -88,15 +121,46 @@ func (a *OpenCodeAgent) ExecuteWithContext(ctx context.Context, worktreePath str
 		log.Printf("📝 Prompt preview: %s", truncateString(prompt, 200))
 	}
 
+	// Attach to a warm server when one is available to skip CLI startup
+	serverURL := a.serverURL
+	if a.servers != nil {
+		url, release := a.servers.Acquire(ctx)
+		defer release()
+		if url != "" {
+			serverURL = url
+		}
+	}
+	args := []string{"run"}
+	if serverURL != "" {
+		args = append(args, "--attach", serverURL)
+		if a.verbose {
+			log.Printf("🔗 Attaching to opencode server %s", serverURL)
+		}
+	}
+
 	// Run OpenCode with run subcommand and prompt as argument
-	// Use --format default for human-readable output
-	cmd := exec.CommandContext(ctx, a.opencodePath, "run", prompt)
+	// Use --format json for its events, which are read as they arrive into
+	// tool call metrics, dashboard events and a plain text output
+	args = append(args, "--format", "json")
+	if a.model != "" {
+		args = append(args, "--model", a.model)
+	}
+	cmd := exec.CommandContext(ctx, a.opencodePath, append(args, prompt)...)
+	cmd.Env = commandEnv(task)
+	detach(cmd)
 	cmd.Dir = worktreePath
+	sandboxCmd(ctx, cmd)
+	cpuLimitCmd(ctx, cmd)
 
 	// Capture output while also streaming to stdout/stderr for real-time viewing
-	var outputBuf, errBuf strings.Builder
-	cmd.Stdout = io.MultiWriter(os.Stdout, &outputBuf)
-	cmd.Stderr = io.MultiWriter(os.Stderr, &errBuf)
+	output := newRunOutput(ctx, task)
+	run := &openCodeRun{onToolCall: func(tool string) {
+		telemetry.RecordAgentToolCall(agentCtx, telemetry.AgentTypeOpenCode, tool)
+	}}
+	events := newEventWriter(output.redact(io.MultiWriter(os.Stdout, output.stdout)), task.ID, telemetry.AgentTypeOpenCode)
+	events.parse = run.parse
+	cmd.Stdout = events
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
 
 	start := time.Now()
 	if a.verbose {
@@ -104,9 +168,11 @@ func (a *OpenCodeAgent) ExecuteWithContext(ctx context.Context, worktreePath str
 	}
 	err := cmd.Run()
 	duration := time.Since(start)
+	events.Flush()
 
 	// Combine stdout and stderr for the result
-	fullOutput := outputBuf.String() + errBuf.String()
+	output.close()
+	fullOutput := output.String()
 
 	// Log exit code regardless of success/failure
 	if err != nil {
@@ -124,34 +190,63 @@ func (a *OpenCodeAgent) ExecuteWithContext(ctx context.Context, worktreePath str
 		if ctx.Err() == context.DeadlineExceeded {
 			telemetry.RecordError(span, err, "TimeoutError", telemetry.ErrorCategoryTimeout)
 			telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeOpenCode, duration)
-			return &ExecutionResult{
+			return run.usage(&ExecutionResult{
 				Success: false,
 				Output:  fullOutput,
 				Error:   fmt.Errorf("opencode timed out after %v", duration),
-			}
+			})
 		}
 		telemetry.RecordError(span, err, "ExecutionError", telemetry.ErrorCategoryAgent)
 		telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeOpenCode, duration)
-		return &ExecutionResult{
+		if run.err != nil {
+			return run.usage(&ExecutionResult{
+				Success: false,
+				Output:  fullOutput,
+				Error:   fmt.Errorf("opencode failed after %v: %s: %w", duration, run.failure(), err),
+				Signal:  run.err.signal(),
+			})
+		}
+		return run.usage(&ExecutionResult{
 			Success: false,
 			Output:  fullOutput,
 			Error:   fmt.Errorf("opencode failed after %v: %w", duration, err),
+		})
+	}
+
+	// opencode can exit cleanly from a session that ended in an error, such
+	// as a provider rejecting its credentials
+	if run.err != nil {
+		if a.verbose {
+			log.Printf("❌ OpenCode ended in an error after %v: %v", duration, run.err)
 		}
+		telemetry.RecordAgentError(agentCtx, telemetry.AgentTypeOpenCode, "session_error")
+		telemetry.RecordError(span, run.err, "SessionError", telemetry.ErrorCategoryAgent)
+		telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeOpenCode, duration)
+		return run.usage(&ExecutionResult{
+			Success:  false,
+			Output:   fullOutput,
+			Error:    fmt.Errorf("opencode ended after %v: %s", duration, run.failure()),
+			Duration: duration,
+			Signal:   run.err.signal(),
+		})
 	}
 
 	if a.verbose {
-		log.Printf("✅ OpenCode completed successfully in %v", duration)
+		log.Printf("✅ OpenCode completed successfully in %v (%d tool calls)", duration, run.toolCalls)
+		if run.finalText != "" {
+			log.Printf("📝 OpenCode's last words: %s", truncateString(run.finalText, 200))
+		}
 	}
 
 	// Record successful completion
 	telemetry.RecordAgentDuration(agentCtx, telemetry.AgentTypeOpenCode, duration)
 
-	return &ExecutionResult{
+	return readVerdictBlock(run.usage(&ExecutionResult{
 		Success: true,
 		Output:  fullOutput,
 		Error:   nil,
 		Duration: duration,
-	}
+	}), run.finalText)
 }
 
 // CheckInstalled verifies OpenCode is available
@@ -166,6 +261,10 @@ func (a *OpenCodeAgent) CheckInstalled() error {
 
 // buildPrompt creates the OpenCode prompt for a task
 func (a *OpenCodeAgent) buildPrompt(task *types.Task) string {
+	if prompt, ok := a.prompts.render(task, a.projectGuidelines, a.recentTasks, a.taskContextCount); ok {
+		return prompt
+	}
+
 	var prompt strings.Builder
 
 	// Start with project guidelines if configured
@@ -195,5 +294,7 @@ func (a *OpenCodeAgent) buildPrompt(task *types.Task) string {
 		prompt.WriteString(fmt.Sprintf("\n\nThis task is part of epic: %s", task.EpicID))
 	}
 
+	prompt.WriteString("\n\n" + outcome.VerdictInstructions)
+
 	return prompt.String()
 }
@@ -0,0 +1,168 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/worker"
+)
+
+// openCodeEvent is one line of 'opencode run --format json' output
+type openCodeEvent struct {
+	Type string `json:"type"` // step_start, text, tool_use, step_finish or error
+	Part struct {
+		Type  string `json:"type"`
+		Text  string `json:"text"`
+		Tool  string `json:"tool"`
+		State struct {
+			Status string          `json:"status"` // completed or error
+			Input  json.RawMessage `json:"input"`
+			Title  string          `json:"title"`
+			Error  string          `json:"error"`
+		} `json:"state"`
+		// What a step used, on step_finish
+		Tokens struct {
+			Input     int64 `json:"input"`
+			Output    int64 `json:"output"`
+			Reasoning int64 `json:"reasoning"`
+			Cache     struct {
+				Read  int64 `json:"read"`
+				Write int64 `json:"write"`
+			} `json:"cache"`
+		} `json:"tokens"`
+		Cost float64 `json:"cost"`
+	} `json:"part"`
+	Error *openCodeError `json:"error"`
+}
+
+// openCodeError is the error of a session opencode gave up on, such as an
+// APIError or ProviderAuthError
+type openCodeError struct {
+	Name string `json:"name"`
+	Data struct {
+		Message    string `json:"message"`
+		StatusCode int    `json:"statusCode"`
+	} `json:"data"`
+}
+
+func (e *openCodeError) Error() string {
+	if e.Data.Message == "" {
+		return e.Name
+	}
+	return e.Name + ": " + e.Data.Message
+}
+
+// signal tells a rate limit apart from other API errors, so the failure is
+// retried, and backed off from, as one
+func (e *openCodeError) signal() worker.WorkerSignal {
+	msg := strings.ToLower(e.Data.Message)
+	if e.Data.StatusCode == 429 || strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests") {
+		return worker.SignalRateLimited
+	}
+	if e.Name == "APIError" || e.Name == "ProviderAuthError" {
+		return worker.SignalAPIError
+	}
+	return worker.SignalOK
+}
+
+// parseOpenCodeEvent parses a line of opencode's JSON output; ok is false
+// when the line isn't an event
+func parseOpenCodeEvent(line []byte) (event *openCodeEvent, ok bool) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	if err := json.Unmarshal(trimmed, &event); err != nil || event.Type == "" {
+		return nil, false
+	}
+	return event, true
+}
+
+// agentEvents is what an opencode event reports, in the form every agent's
+// events are broadcast and rendered in
+func (e *openCodeEvent) agentEvents() []agentEvent {
+	switch e.Type {
+	case "text":
+		if text := strings.TrimSpace(e.Part.Text); text != "" {
+			return []agentEvent{{Kind: EventKindMessage, Text: text}}
+		}
+	case "tool_use":
+		summary := summarizeToolInput(e.Part.State.Input)
+		if summary == "" {
+			summary = e.Part.State.Title
+		}
+		events := []agentEvent{{Kind: EventKindToolCall, Tool: e.Part.Tool, Text: summary}}
+		if e.Part.State.Status == "error" {
+			events = append(events, agentEvent{Kind: EventKindError, Tool: e.Part.Tool, Text: e.Part.State.Error})
+		}
+		return events
+	case "error":
+		if e.Error != nil {
+			return []agentEvent{{Kind: EventKindError, Text: e.Error.Error()}}
+		}
+		return []agentEvent{{Kind: EventKindError, Text: "opencode reported an error"}}
+	}
+	return nil
+}
+
+// openCodeRun collects what an opencode run reports as it goes: the tool
+// calls, its final text, what its steps used and the error it gave up on,
+// if any
+type openCodeRun struct {
+	onToolCall   func(tool string)
+	toolCalls    int
+	finalText    string
+	inputTokens  int64
+	outputTokens int64
+	costUSD      float64
+	err          *openCodeError
+}
+
+// parse reads a line of the run's output for an eventWriter
+func (r *openCodeRun) parse(line []byte) ([]agentEvent, bool) {
+	event, ok := parseOpenCodeEvent(line)
+	if !ok {
+		return nil, false
+	}
+	switch event.Type {
+	case "text":
+		if text := strings.TrimSpace(event.Part.Text); text != "" {
+			r.finalText = text
+		}
+	case "tool_use":
+		r.toolCalls++
+		if r.onToolCall != nil {
+			r.onToolCall(event.Part.Tool)
+		}
+	case "step_finish":
+		// Cached prompt tokens are still tokens sent, and reasoning is output
+		tokens := event.Part.Tokens
+		r.inputTokens += tokens.Input + tokens.Cache.Read + tokens.Cache.Write
+		r.outputTokens += tokens.Output + tokens.Reasoning
+		r.costUSD += event.Part.Cost
+	case "error":
+		r.err = event.Error
+		if r.err == nil {
+			r.err = &openCodeError{Name: "UnknownError"}
+		}
+	}
+	return event.agentEvents(), true
+}
+
+// usage adds what the run's steps used to an execution result
+func (r *openCodeRun) usage(result *ExecutionResult) *ExecutionResult {
+	result.InputTokens = r.inputTokens
+	result.OutputTokens = r.outputTokens
+	result.CostUSD = r.costUSD
+	return result
+}
+
+// failure describes the error opencode reported, for a failed execution
+func (r *openCodeRun) failure() string {
+	if r.err == nil {
+		return ""
+	}
+	return fmt.Sprintf("opencode reported %v", r.err)
+}
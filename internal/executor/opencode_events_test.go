@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/worker"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// openCodeStream is what 'opencode run --format json' prints for a short
+// session that edits a file and then hits a rate limit
+const openCodeStream = `{"type":"step_start","sessionID":"ses_1","part":{"type":"step-start"}}
+{"type":"text","sessionID":"ses_1","part":{"type":"text","text":"I'll update the README."}}
+{"type":"tool_use","sessionID":"ses_1","part":{"type":"tool","tool":"edit","state":{"status":"completed","input":{"filePath":"README.md"},"title":"README.md"}}}
+{"type":"tool_use","sessionID":"ses_1","part":{"type":"tool","tool":"bash","state":{"status":"error","input":{"command":"make lint"},"error":"make: *** No rule to make target 'lint'"}}}
+{"type":"step_finish","sessionID":"ses_1","part":{"type":"step-finish","reason":"tool-calls","cost":0.0125,"tokens":{"input":1200,"output":300,"reasoning":50,"cache":{"read":4000,"write":0}}}}
+{"type":"error","sessionID":"ses_1","error":{"name":"APIError","data":{"message":"Rate limit reached for requests","statusCode":429}}}
+`
+
+func TestOpenCodeRun_Parse(t *testing.T) {
+	var tools []string
+	run := &openCodeRun{onToolCall: func(tool string) { tools = append(tools, tool) }}
+	var out strings.Builder
+	w := newEventWriter(&out, "task-1", "opencode")
+	w.parse = run.parse
+	if _, err := w.Write([]byte(openCodeStream + "plain line\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "I'll update the README.\n" +
+		"🔧 edit: README.md\n" +
+		"🔧 bash: make lint\n" +
+		"⚠️  make: *** No rule to make target 'lint'\n" +
+		"⚠️  APIError: Rate limit reached for requests\n" +
+		"plain line\n"
+	if out.String() != want {
+		t.Errorf("Rendered output = %q, want %q", out.String(), want)
+	}
+	if strings.Join(tools, ",") != "edit,bash" || run.toolCalls != 2 {
+		t.Errorf("Expected the edit and bash tool calls counted, got %v (%d)", tools, run.toolCalls)
+	}
+	if run.finalText != "I'll update the README." {
+		t.Errorf("Expected the last text as the final text, got %q", run.finalText)
+	}
+	if run.inputTokens != 5200 || run.outputTokens != 350 || run.costUSD != 0.0125 {
+		t.Errorf("Expected the step's usage, got %d in, %d out, $%v", run.inputTokens, run.outputTokens, run.costUSD)
+	}
+	if run.err == nil || run.err.signal() != worker.SignalRateLimited {
+		t.Errorf("Expected a rate limit error, got %+v", run.err)
+	}
+}
+
+func TestOpenCodeError_Signal(t *testing.T) {
+	tests := []struct {
+		err  openCodeError
+		want worker.WorkerSignal
+	}{
+		{openCodeError{Name: "APIError"}, worker.SignalAPIError},
+		{openCodeError{Name: "ProviderAuthError"}, worker.SignalAPIError},
+		{openCodeError{Name: "UnknownError"}, worker.SignalOK},
+	}
+	for _, tt := range tests {
+		if got := tt.err.signal(); got != tt.want {
+			t.Errorf("%s.signal() = %v, want %v", tt.err.Name, got, tt.want)
+		}
+	}
+}
+
+func TestOpenCodeAgent_SessionError(t *testing.T) {
+	dir := t.TempDir()
+	events := filepath.Join(dir, "events.jsonl")
+	if err := os.WriteFile(events, []byte(openCodeStream), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// opencode exits cleanly even though the session ended in an error
+	script := filepath.Join(dir, "mock-opencode.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/bash\ncat "+events+"\nexit 0\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	agent := NewOpenCodeAgent(script, time.Minute)
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1", Title: "Update the README"})
+	if result.Success {
+		t.Fatal("Expected the session error to fail the execution")
+	}
+	if result.Signal != worker.SignalRateLimited {
+		t.Errorf("Expected a rate limit signal, got %v", result.Signal)
+	}
+	if !strings.Contains(result.Error.Error(), "Rate limit reached") {
+		t.Errorf("Expected opencode's error in the failure, got %v", result.Error)
+	}
+	if strings.Contains(result.Output, `"type"`) {
+		t.Errorf("Expected plain text output, got:\n%s", result.Output)
+	}
+}
@@ -0,0 +1,320 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Supervision settings for managed opencode servers
+const (
+	openCodeServerStartTimeout = 30 * time.Second
+	openCodeHealthInterval     = 10 * time.Second
+	openCodeHealthTimeout      = 3 * time.Second
+	openCodeMaxHealthFailures  = 3 // Consecutive failed checks before a restart
+)
+
+// openCodeServer is one supervised `opencode serve` process
+type openCodeServer struct {
+	url      string
+	cmd      *exec.Cmd
+	exited   chan struct{} // Closed when the process exits
+	healthy  bool
+	draining bool // Due a restart once its executions finish
+	failures int  // Consecutive failed health checks
+	inFlight int  // Task executions currently attached
+}
+
+// OpenCodeServerPool launches and supervises warm `opencode serve` instances
+// so task executions can attach to them (opencode run --attach) instead of
+// paying the CLI's startup cost every time. Servers are started on first use;
+// unhealthy or exited servers are restarted on a new port
+type OpenCodeServerPool struct {
+	opencodePath string
+	size         int
+
+	startOnce sync.Once
+	startErr  error
+
+	mu      sync.Mutex
+	servers []*openCodeServer
+	started bool
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewOpenCodeServerPool creates a pool of size servers run from opencodePath
+func NewOpenCodeServerPool(opencodePath string, size int) *OpenCodeServerPool {
+	return &OpenCodeServerPool{
+		opencodePath: opencodePath,
+		size:         size,
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start launches the servers and the supervisor. It is called by the first
+// Acquire; calling it again waits for and returns the first call's result
+func (p *OpenCodeServerPool) Start(ctx context.Context) error {
+	p.startOnce.Do(func() { p.startErr = p.start(ctx) })
+	return p.startErr
+}
+
+// start launches the servers and waits for them to answer without holding
+// the pool's lock, so releases and Stop aren't held up meanwhile
+func (p *OpenCodeServerPool) start(ctx context.Context) error {
+	var servers []*openCodeServer
+	for i := 0; i < p.size; i++ {
+		srv, err := p.launch()
+		if err != nil {
+			log.Printf("⚠️  [opencode] server %d failed to start: %v", i+1, err)
+			srv = &openCodeServer{exited: closedChan()}
+		}
+		servers = append(servers, srv)
+	}
+	healthy := 0
+	for _, srv := range servers {
+		if srv.url != "" && waitHealthy(ctx, srv, openCodeServerStartTimeout) {
+			srv.healthy = true
+			healthy++
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.servers = servers
+	p.started = true
+
+	var err error
+	if healthy == 0 {
+		err = fmt.Errorf("no opencode server became healthy")
+		log.Printf("⚠️  [opencode] %v; running without --attach until one recovers", err)
+	} else {
+		log.Printf("🔥 [opencode] %d/%d warm servers ready", healthy, p.size)
+	}
+
+	p.wg.Add(1)
+	go p.supervise()
+	return err
+}
+
+// Acquire returns the URL of the healthy server with the fewest attached
+// executions, and a func to call when the execution finishes. It returns ""
+// when no server is healthy, and callers then run without --attach
+func (p *OpenCodeServerPool) Acquire(ctx context.Context) (string, func()) {
+	// A failed start is logged; the supervisor keeps restarting the servers
+	p.Start(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *openCodeServer
+	for _, srv := range p.servers {
+		if srv.healthy && (best == nil || srv.inFlight < best.inFlight) {
+			best = srv
+		}
+	}
+	if best == nil {
+		return "", func() {}
+	}
+
+	best.inFlight++
+	var once sync.Once
+	return best.url, func() {
+		once.Do(func() {
+			p.mu.Lock()
+			best.inFlight--
+			p.mu.Unlock()
+		})
+	}
+}
+
+// Stop stops the supervisor and kills every server, waiting for a Start in
+// progress to finish first; the pool isn't started after it
+func (p *OpenCodeServerPool) Stop() {
+	p.startOnce.Do(func() {})
+	p.mu.Lock()
+	if !p.started {
+		p.mu.Unlock()
+		return
+	}
+	select {
+	case <-p.stop:
+		p.mu.Unlock()
+		return
+	default:
+		close(p.stop)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, srv := range p.servers {
+		srv.kill()
+	}
+}
+
+// supervise health-checks the servers periodically until Stop
+func (p *OpenCodeServerPool) supervise() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(openCodeHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.checkHealth()
+		}
+	}
+}
+
+// checkHealth probes every server and restarts those that exited or failed
+// openCodeMaxHealthFailures checks in a row. A failing server that still has
+// executions attached gets no new ones, and is restarted once they finish
+func (p *OpenCodeServerPool) checkHealth() {
+	p.mu.Lock()
+	servers := append([]*openCodeServer(nil), p.servers...)
+	p.mu.Unlock()
+
+	for i, srv := range servers {
+		exited := isClosed(srv.exited)
+		ok := !exited && probe(srv.url, openCodeHealthTimeout)
+
+		p.mu.Lock()
+		if ok && !srv.draining {
+			srv.healthy = true
+			srv.failures = 0
+			p.mu.Unlock()
+			continue
+		}
+		if !ok {
+			srv.failures++
+		}
+		restart := exited || srv.draining || srv.failures >= openCodeMaxHealthFailures
+		if restart {
+			srv.healthy = false
+		}
+		busy := restart && !exited && srv.inFlight > 0
+		if busy && !srv.draining {
+			srv.draining = true
+			log.Printf("⏳ [opencode] server %s is unhealthy; restarting it once its %d execution(s) finish", srv.url, srv.inFlight)
+		}
+		p.mu.Unlock()
+		if !restart || busy {
+			continue
+		}
+
+		log.Printf("🔄 [opencode] restarting unhealthy server %s", srv.url)
+		srv.kill()
+		replacement, err := p.launch()
+		if err != nil {
+			log.Printf("⚠️  [opencode] restarting server: %v", err)
+			continue
+		}
+		replacement.healthy = waitHealthy(context.Background(), replacement, openCodeServerStartTimeout)
+
+		p.mu.Lock()
+		// Executions still attached to an exited server release into it, harmlessly
+		p.servers[i] = replacement
+		p.mu.Unlock()
+	}
+}
+
+// launch starts one `opencode serve` on a free local port
+func (p *OpenCodeServerPool) launch() (*openCodeServer, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, fmt.Errorf("finding a free port: %w", err)
+	}
+
+	// Servers live until killed; the context only satisfies detach
+	cmd := exec.CommandContext(context.Background(), p.opencodePath, "serve", "--hostname", "127.0.0.1", "--port", fmt.Sprint(port))
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting opencode serve: %w", err)
+	}
+
+	srv := &openCodeServer{
+		url:    fmt.Sprintf("http://127.0.0.1:%d", port),
+		cmd:    cmd,
+		exited: make(chan struct{}),
+	}
+	go func() {
+		cmd.Wait()
+		close(srv.exited)
+	}()
+	return srv, nil
+}
+
+// kill stops the server process and waits for it to exit
+func (s *openCodeServer) kill() {
+	if s.cmd == nil || s.cmd.Process == nil {
+		return
+	}
+	if !isClosed(s.exited) {
+		s.cmd.Process.Kill()
+	}
+	<-s.exited
+}
+
+// waitHealthy polls the server until it answers, it exits, or timeout passes
+func waitHealthy(ctx context.Context, srv *openCodeServer, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if probe(srv.url, openCodeHealthTimeout) {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-srv.exited:
+			return false
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	return false
+}
+
+// probe reports whether the server answers HTTP requests without a server error
+func probe(url string, timeout time.Duration) bool {
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < 500
+}
+
+// freePort asks the kernel for an unused local TCP port
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func closedChan() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}
@@ -0,0 +1,100 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestOpenCodeServeHelper stands in for `opencode serve` when the server pool
+// tests run the test binary as the opencode CLI
+func TestOpenCodeServeHelper(t *testing.T) {
+	if os.Getenv("DROVER_FAKE_OPENCODE") != "1" {
+		t.Skip("helper process for the opencode server pool tests")
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--port" && i+1 < len(args) {
+			http.ListenAndServe("127.0.0.1:"+args[i+1], http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, "ok")
+			}))
+		}
+	}
+	os.Exit(2)
+}
+
+// fakeOpenCode writes a script that re-runs the test binary as the helper server
+func fakeOpenCode(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the opencode binary")
+	}
+	script := filepath.Join(t.TempDir(), "opencode")
+	body := fmt.Sprintf("#!/bin/sh\nDROVER_FAKE_OPENCODE=1 exec %q -test.run=TestOpenCodeServeHelper -- \"$@\"\n", os.Args[0])
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestOpenCodeServerPool(t *testing.T) {
+	pool := NewOpenCodeServerPool(fakeOpenCode(t), 2)
+	defer pool.Stop()
+
+	url1, release1 := pool.Acquire(context.Background())
+	url2, release2 := pool.Acquire(context.Background())
+	if url1 == "" || url2 == "" {
+		t.Fatalf("Expected healthy servers, got %q and %q", url1, url2)
+	}
+	if url1 == url2 {
+		t.Errorf("Expected executions spread across servers, both got %s", url1)
+	}
+	release1()
+	release1() // Releasing twice must not skew the load count
+	if url, release := pool.Acquire(context.Background()); url != url1 {
+		t.Errorf("Expected the idle server %s, got %s", url1, url)
+	} else {
+		release()
+	}
+	release2()
+
+	// A server that dies is replaced on the next health check
+	dead := pool.servers[0]
+	dead.kill()
+	pool.checkHealth()
+	if replaced := pool.servers[0]; replaced == dead || !replaced.healthy || replaced.url == dead.url {
+		t.Errorf("Expected the dead server to be replaced by a healthy one, got %+v", replaced)
+	}
+
+	// A failing server with an execution attached is drained before it's
+	// restarted
+	busy := pool.servers[1]
+	_, release1 = pool.Acquire(context.Background())
+	url, release := pool.Acquire(context.Background())
+	release1()
+	if url != busy.url {
+		t.Fatalf("Expected an execution on %s, got %s", busy.url, url)
+	}
+	pool.mu.Lock()
+	busy.url = "http://127.0.0.1:1" // Stops answering
+	busy.failures = openCodeMaxHealthFailures - 1
+	pool.mu.Unlock()
+	pool.checkHealth()
+	if pool.servers[1] != busy || busy.healthy {
+		t.Fatalf("Expected the busy server kept but taking no new executions, got %+v", pool.servers[1])
+	}
+	if url, release := pool.Acquire(context.Background()); url == busy.url {
+		t.Errorf("Expected no new execution on the draining server")
+	} else {
+		release()
+	}
+	release()
+	pool.checkHealth()
+	if replaced := pool.servers[1]; replaced == busy || !replaced.healthy {
+		t.Errorf("Expected the drained server to be replaced by a healthy one, got %+v", replaced)
+	}
+}
@@ -0,0 +1,347 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/cloud-shuttle/drover/internal/memory"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RedactedValue stands in for a secret in an agent's output
+const RedactedValue = "[redacted]"
+
+// DefaultMaxOutput is how much of each of an agent run's output streams is
+// kept in memory unless configured otherwise
+const DefaultMaxOutput int64 = 32 << 20
+
+// outputKey carries the output limit of an execution in its context
+type outputKey struct{}
+
+// outputCapture is the output limit of an execution, and the runs of it
+// that went over
+type outputCapture struct {
+	max       int64
+	mu        sync.Mutex
+	truncated bool
+	log       string // Full output of the last run that went over
+}
+
+// withOutputLimit returns a context whose agent runs keep up to max bytes of
+// each output stream
+func withOutputLimit(ctx context.Context, max int64) (context.Context, *outputCapture) {
+	capture := &outputCapture{max: max}
+	return context.WithValue(ctx, outputKey{}, capture), capture
+}
+
+// OutputLimitedAgent runs another agent with the output of every run it
+// makes for a task kept to a limit, recording on the result when a run
+// went over and where its full output is
+type OutputLimitedAgent struct {
+	Agent
+	max int64
+}
+
+// NewOutputLimitedAgent wraps agent so its runs keep up to max bytes of each
+// output stream (0 = DefaultMaxOutput)
+func NewOutputLimitedAgent(agent Agent, max int64) *OutputLimitedAgent {
+	if max <= 0 {
+		max = DefaultMaxOutput
+	}
+	return &OutputLimitedAgent{Agent: agent, max: max}
+}
+
+// ExecuteWithContext runs the task with the wrapped agent, keeping its output
+// to the limit
+func (a *OutputLimitedAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *ExecutionResult {
+	ctx, capture := withOutputLimit(ctx, a.max)
+	result := a.Agent.ExecuteWithContext(ctx, worktreePath, task, parentSpan...)
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+	if capture.truncated {
+		result.OutputTruncated = true
+		result.OutputLog = capture.log
+	}
+	return result
+}
+
+// Close releases what the wrapped agent holds for the run
+func (a *OutputLimitedAgent) Close() error {
+	return CloseAgent(a.Agent)
+}
+
+// runOutput collects the stdout and stderr of an agent run. A stream that
+// goes over the limit keeps its start and a rolling tail, where agents
+// report how the run ended, and the run's whole output spills to a file
+type runOutput struct {
+	mu      sync.Mutex
+	max     int64
+	taskID  string
+	capture *outputCapture // nil outside an OutputLimitedAgent
+	stdout  *outputStream
+	stderr  *outputStream
+	secrets []string           // The task's secret values
+	writers []*redactingWriter // Flushed when the run ends
+
+	spill       *os.File
+	spillFailed bool
+}
+
+// newRunOutput creates the output collector of a run for a task, kept to the
+// limit of the execution ctx belongs to
+func newRunOutput(ctx context.Context, task *types.Task) *runOutput {
+	r := &runOutput{max: DefaultMaxOutput, taskID: task.ID, secrets: taskSecrets(task)}
+	if capture, ok := ctx.Value(outputKey{}).(*outputCapture); ok {
+		r.max, r.capture = capture.max, capture
+	}
+	r.stdout = &outputStream{run: r}
+	r.stderr = &outputStream{run: r}
+	return r
+}
+
+// taskSecrets returns the values to redact from a task's output
+func taskSecrets(task *types.Task) []string {
+	if task.ExecutionContext == nil {
+		return nil
+	}
+	return task.ExecutionContext.Secrets
+}
+
+// redact returns a writer passing what the run's process outputs on to w, the
+// console and the run's streams included, with the task's secrets redacted
+func (r *runOutput) redact(w io.Writer) io.Writer {
+	if len(r.secrets) == 0 {
+		return w
+	}
+	rw := newRedactingWriter(w, r.secrets)
+	r.mu.Lock()
+	r.writers = append(r.writers, rw)
+	r.mu.Unlock()
+	return rw
+}
+
+// String returns the run's stdout followed by its stderr, each truncated in
+// the middle when it went over the limit
+func (r *runOutput) String() string {
+	return r.stdout.String() + r.stderr.String()
+}
+
+// close ends the run's spill file, if it has one, reporting where the full
+// output is. Call it once the run's process has exited
+func (r *runOutput) close() {
+	r.mu.Lock()
+	writers := r.writers
+	r.mu.Unlock()
+	for _, rw := range writers {
+		rw.flush()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.stdout.truncated() && !r.stderr.truncated() {
+		return
+	}
+	path := ""
+	if r.spill != nil {
+		path = r.spill.Name()
+		if err := r.spill.Close(); err != nil {
+			log.Printf("⚠️  Task %s: writing its full output: %v", r.taskID, err)
+		}
+		r.spill = nil
+		log.Printf("✂️  Task %s's output went over %s and was truncated; the full output is in %s",
+			r.taskID, memory.FormatBytes(r.max), path)
+	}
+	if r.capture != nil {
+		r.capture.mu.Lock()
+		r.capture.truncated = true
+		if path != "" {
+			r.capture.log = path
+		}
+		r.capture.mu.Unlock()
+	}
+}
+
+// startSpill writes what the run output so far to a spill file, which gets
+// the rest of it as it comes. Called with r.mu held, as the first stream
+// goes over the limit, so neither stream has lost anything yet
+func (r *runOutput) startSpill() {
+	if r.spill != nil || r.spillFailed {
+		return
+	}
+	spill, err := os.CreateTemp("", "drover-output-"+containerNameUnsafe.ReplaceAllString(r.taskID, "-")+"-*.log")
+	if err != nil {
+		log.Printf("⚠️  Task %s: its output is over %s and can't be kept in full: %v", r.taskID, memory.FormatBytes(r.max), err)
+		r.spillFailed = true
+		return
+	}
+	r.spill = spill
+	r.writeSpill(r.stdout.head)
+	r.writeSpill(r.stderr.head)
+}
+
+// writeSpill adds p to the spill file, if the run has one
+func (r *runOutput) writeSpill(p []byte) {
+	if r.spill == nil {
+		return
+	}
+	if _, err := r.spill.Write(p); err != nil {
+		log.Printf("⚠️  Task %s: writing its full output: %v", r.taskID, err)
+		r.spill.Close()
+		os.Remove(r.spill.Name())
+		r.spill, r.spillFailed = nil, true
+	}
+}
+
+// outputStream is one output stream of a run: the whole of it while it's
+// within the limit, then its first quarter and a ring of the rest of the
+// limit's worth from its end
+type outputStream struct {
+	run     *runOutput
+	head    []byte
+	tail    []byte // nil until the stream goes over the limit
+	pos     int    // Where the ring continues in tail
+	full    bool   // Whether the ring has wrapped around
+	dropped int64  // Bytes that fell out between head and tail
+}
+
+// Write adds p to the stream
+func (s *outputStream) Write(p []byte) (int, error) {
+	r := s.run
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s.tail == nil && int64(len(s.head)+len(p)) <= r.max {
+		s.head = append(s.head, p...)
+		r.writeSpill(p)
+		return len(p), nil
+	}
+
+	n := len(p)
+	if s.tail == nil {
+		r.startSpill()
+	}
+	r.writeSpill(p)
+	if s.tail == nil {
+		headSize := int(r.max / 4)
+		s.tail = make([]byte, r.max-int64(headSize))
+		if fill := headSize - len(s.head); fill > 0 {
+			s.head = append(s.head, p[:fill]...)
+			p = p[fill:]
+		}
+		s.ring(s.head[headSize:])
+		s.head = s.head[:headSize:headSize]
+	}
+	s.ring(p)
+	return n, nil
+}
+
+// ring adds p to the tail, dropping what falls out of it
+func (s *outputStream) ring(p []byte) {
+	size := len(s.tail)
+	if len(p) >= size {
+		s.dropped += s.kept() + int64(len(p)-size)
+		copy(s.tail, p[len(p)-size:])
+		s.pos, s.full = 0, true
+		return
+	}
+	if s.full {
+		s.dropped += int64(len(p))
+	} else if over := s.pos + len(p) - size; over > 0 {
+		s.dropped += int64(over)
+	}
+	n := copy(s.tail[s.pos:], p)
+	if n < len(p) {
+		copy(s.tail, p[n:])
+		s.full = true
+	}
+	s.pos = (s.pos + len(p)) % size
+	if s.pos == 0 && len(p) > 0 {
+		s.full = true
+	}
+}
+
+// kept returns how many bytes the tail holds
+func (s *outputStream) kept() int64 {
+	if s.full {
+		return int64(len(s.tail))
+	}
+	return int64(s.pos)
+}
+
+// truncated reports whether the stream went over the limit
+func (s *outputStream) truncated() bool {
+	return s.tail != nil
+}
+
+// String returns the stream, with a note where it was truncated
+func (s *outputStream) String() string {
+	s.run.mu.Lock()
+	defer s.run.mu.Unlock()
+	if s.tail == nil {
+		return string(s.head)
+	}
+	tail := s.tail[:s.pos]
+	if s.full {
+		tail = append(append([]byte{}, s.tail[s.pos:]...), s.tail[:s.pos]...)
+	}
+	if s.dropped == 0 {
+		return string(s.head) + string(tail)
+	}
+	return fmt.Sprintf("%s\n\n[... %s of output truncated ...]\n\n%s", s.head, memory.FormatBytes(s.dropped), tail)
+}
+
+// redactingWriter replaces secrets in what it passes on. A secret can be
+// split across writes, so the bytes that could start one are held back until
+// the next write or the flush
+type redactingWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	secrets [][]byte
+	hold    int // Length of the longest secret, less one
+	pending []byte
+}
+
+// newRedactingWriter creates a writer replacing secrets in what it passes to w
+func newRedactingWriter(w io.Writer, secrets []string) *redactingWriter {
+	rw := &redactingWriter{w: w}
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+		rw.secrets = append(rw.secrets, []byte(secret))
+		rw.hold = max(rw.hold, len(secret)-1)
+	}
+	return rw
+}
+
+// Write passes p on, secrets redacted, but for what could start a secret
+func (rw *redactingWriter) Write(p []byte) (int, error) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	rw.pending = append(rw.pending, p...)
+	for _, secret := range rw.secrets {
+		rw.pending = bytes.ReplaceAll(rw.pending, secret, []byte(RedactedValue))
+	}
+	if n := len(rw.pending) - rw.hold; n > 0 {
+		if _, err := rw.w.Write(rw.pending[:n]); err != nil {
+			return 0, err
+		}
+		rw.pending = append(rw.pending[:0], rw.pending[n:]...)
+	}
+	return len(p), nil
+}
+
+// flush passes on what's held back
+func (rw *redactingWriter) flush() {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	if len(rw.pending) > 0 {
+		rw.w.Write(rw.pending)
+		rw.pending = nil
+	}
+}
@@ -0,0 +1,129 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestAgent_OutputLimit(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "chatty.sh")
+	content := "#!/bin/bash\necho FIRST-LINE\nfor i in $(seq 1 2000); do echo \"line $i of chatter\"; done\necho LAST-LINE\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := executor.NewAgent(&executor.AgentConfig{
+		Type:      "custom",
+		Custom:    executor.CustomAgentConfig{Command: script},
+		Timeout:   time.Minute,
+		MaxOutput: 4096,
+	})
+	if err != nil {
+		t.Fatalf("NewAgent failed: %v", err)
+	}
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-7", Title: "Talk a lot"})
+	if !result.Success {
+		t.Fatalf("Execute failed: %v", result.Error)
+	}
+	if !result.OutputTruncated || result.OutputLog == "" {
+		t.Fatalf("Expected truncated output with a full log, got truncated=%v log=%q", result.OutputTruncated, result.OutputLog)
+	}
+	defer os.Remove(result.OutputLog)
+
+	if len(result.Output) > 4096+100 {
+		t.Errorf("Expected about 4096 bytes of output, got %d", len(result.Output))
+	}
+	for _, want := range []string{"FIRST-LINE", "of output truncated", "LAST-LINE"} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("Expected %q in the truncated output", want)
+		}
+	}
+
+	full, err := os.ReadFile(result.OutputLog)
+	if err != nil {
+		t.Fatalf("Reading the full output: %v", err)
+	}
+	if !strings.HasPrefix(string(full), "FIRST-LINE\n") || !strings.Contains(string(full), "line 1000 of chatter\n") || !strings.HasSuffix(string(full), "LAST-LINE\n") {
+		t.Errorf("Expected the whole output in %s, got %d bytes", result.OutputLog, len(full))
+	}
+}
+
+func TestAgent_OutputWithinLimit(t *testing.T) {
+	script, _ := createMockCustomScript(t, t.TempDir(), "all done", 0)
+	agent, err := executor.NewAgent(&executor.AgentConfig{
+		Type:    "custom",
+		Custom:  executor.CustomAgentConfig{Command: script + " --prompt {{.PromptFile}}"},
+		Timeout: time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("NewAgent failed: %v", err)
+	}
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-7", Title: "Say little"})
+	if !result.Success || result.OutputTruncated || result.OutputLog != "" || strings.TrimSpace(result.Output) != "all done" {
+		t.Errorf("Expected the whole output untouched, got %+v", result)
+	}
+}
+
+func TestAgent_OutputRedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "leaky.sh")
+	// The secret arrives in two writes, and the output goes over the limit
+	content := "#!/bin/bash\nprintf 'token is hunt'\nsleep 0.2\nprintf 'er2-secret\\n'\nfor i in $(seq 1 500); do echo \"line $i of chatter\"; done\necho hunter2-secret >&2\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	agent, err := executor.NewAgent(&executor.AgentConfig{
+		Type:      "custom",
+		Custom:    executor.CustomAgentConfig{Command: script},
+		Timeout:   time.Minute,
+		MaxOutput: 4096,
+	})
+	if err != nil {
+		t.Fatalf("NewAgent failed: %v", err)
+	}
+
+	console, err := os.CreateTemp(dir, "console")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = console, console
+	task := &types.Task{ID: "task-8", Title: "Leak", ExecutionContext: &types.TaskExecutionContext{
+		Env:     []string{"API_TOKEN=hunter2-secret"},
+		Secrets: []string{"hunter2-secret"},
+	}}
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), task)
+	os.Stdout, os.Stderr = stdout, stderr
+	console.Close()
+	if !result.Success || result.OutputLog == "" {
+		t.Fatalf("Expected a run with its full output spilled, got %+v", result)
+	}
+	defer os.Remove(result.OutputLog)
+
+	shown, _ := os.ReadFile(console.Name())
+	full, _ := os.ReadFile(result.OutputLog)
+	for where, text := range map[string]string{"console": string(shown), "result": result.Output, "spill file": string(full)} {
+		if strings.Contains(text, "hunter2") || !strings.Contains(text, "token is "+executor.RedactedValue+"\n") {
+			t.Errorf("Expected the secret redacted from the %s, got %q", where, firstBytes(text, 200))
+		}
+	}
+	if !strings.Contains(string(full), executor.RedactedValue+"\n") || !strings.Contains(string(full), "line 500 of chatter") {
+		t.Errorf("Expected the whole output, redacted, in the spill file")
+	}
+}
+
+// firstBytes returns up to n bytes of s
+func firstBytes(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,181 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/cloud-shuttle/drover/internal/outcome"
+	"github.com/cloud-shuttle/drover/internal/taskcontext"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// DefaultPromptTemplate is the template for tasks of a type without one of
+// its own
+const DefaultPromptTemplate = "default"
+
+// promptTemplateNames are the templates a prompts directory may hold: one
+// per task type and the default
+var promptTemplateNames = []string{
+	DefaultPromptTemplate,
+	string(types.TaskTypeFeature), string(types.TaskTypeBug), string(types.TaskTypeRefactor),
+	string(types.TaskTypeTest), string(types.TaskTypeDocs), string(types.TaskTypeResearch),
+	string(types.TaskTypeFix), string(types.TaskTypeOther),
+}
+
+// PromptData is what a prompt template renders
+type PromptData struct {
+	Task        *types.Task
+	Title       string
+	Description string
+	Type        string
+	EpicID      string
+	Attempt     int      // 1 for the first attempt
+	Guidelines  string   // The project's guidelines; empty when there are none
+	Context     string   // Recently completed tasks, formatted; empty when context carrying is off
+	Guidance    []string // Guidance messages for this attempt
+
+	// VerdictInstructions asks the agent to end its reply with the verdict
+	// block drover reads its outcome from; templates should include it
+	VerdictInstructions string
+}
+
+// promptTemplater is implemented by agents whose prompts can come from
+// templates
+type promptTemplater interface {
+	SetPromptTemplates(*PromptTemplates)
+}
+
+// PromptTemplates renders task prompts from Go templates, such as
+// .drover/prompts/bug.tmpl for bug tasks and default.tmpl for the rest, in
+// place of the agents' built-in prompts
+type PromptTemplates struct {
+	templates map[string]*template.Template
+}
+
+// LoadPromptTemplates parses the *.tmpl files in dir, checking each renders;
+// nil when dir has none
+func LoadPromptTemplates(dir string) (*PromptTemplates, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	p := &PromptTemplates{templates: make(map[string]*template.Template)}
+	sample := samplePromptData()
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".tmpl")
+		if !isPromptTemplateName(name) {
+			return nil, fmt.Errorf("%s: no task type %q (templates are named %s.tmpl)", file, name, strings.Join(promptTemplateNames, ".tmpl, "))
+		}
+		text, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", file, err)
+		}
+		// Catch references to fields that don't exist now, not mid-run
+		if err := tmpl.Execute(io.Discard, sample); err != nil {
+			return nil, fmt.Errorf("checking %s: %w", file, err)
+		}
+		p.templates[name] = tmpl
+	}
+	return p, nil
+}
+
+// isPromptTemplateName reports whether name is a task type or the default
+func isPromptTemplateName(name string) bool {
+	for _, valid := range promptTemplateNames {
+		if name == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// samplePromptData is a task with every field set, for checking templates
+func samplePromptData() *PromptData {
+	task := &types.Task{
+		ID:          "task-sample",
+		Title:       "Sample task",
+		Description: "What the sample task is about",
+		Type:        types.TaskTypeFeature,
+		EpicID:      "epic-sample",
+	}
+	return &PromptData{
+		Task:        task,
+		Title:       task.Title,
+		Description: task.Description,
+		Type:        string(task.Type),
+		EpicID:      task.EpicID,
+		Attempt:     1,
+		Guidelines:  "Sample guidelines",
+		Context:     "Sample context",
+		Guidance:    []string{"Sample guidance"},
+
+		VerdictInstructions: outcome.VerdictInstructions,
+	}
+}
+
+// Names returns the names of the loaded templates
+func (p *PromptTemplates) Names() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.templates))
+	for name := range p.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// render renders the prompt for a task from the template of its type, or
+// the default one; ok is false when neither exists, or rendering fails, and
+// the agent's built-in prompt should be used
+func (p *PromptTemplates) render(task *types.Task, guidelines string, recentTasks []*types.Task, taskContextCount int) (prompt string, ok bool) {
+	if p == nil {
+		return "", false
+	}
+	tmpl, found := p.templates[string(task.Type)]
+	if !found {
+		if tmpl, found = p.templates[DefaultPromptTemplate]; !found {
+			return "", false
+		}
+	}
+
+	data := &PromptData{
+		Task:        task,
+		Title:       task.Title,
+		Description: task.Description,
+		Type:        string(task.Type),
+		EpicID:      task.EpicID,
+		Attempt:     task.Attempts + 1,
+		Guidelines:  guidelines,
+
+		VerdictInstructions: outcome.VerdictInstructions,
+	}
+	if taskContextCount > 0 && len(recentTasks) > 0 {
+		data.Context = taskcontext.BuildContext(recentTasks, task, taskContextCount)
+	}
+	if task.ExecutionContext != nil {
+		for _, g := range task.ExecutionContext.Guidance {
+			data.Guidance = append(data.Guidance, g.Message)
+		}
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		log.Printf("⚠️  Prompt template %s failed for task %s, using the built-in prompt: %v", tmpl.Name(), task.ID, err)
+		return "", false
+	}
+	return out.String(), true
+}
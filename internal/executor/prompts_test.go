@@ -0,0 +1,106 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// writePromptTemplates writes templates, keyed by file name, into a prompts
+// directory
+func writePromptTemplates(t *testing.T, templates map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range templates {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadPromptTemplates_None(t *testing.T) {
+	prompts, err := executor.LoadPromptTemplates(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || prompts != nil {
+		t.Errorf("Expected no templates and no error, got %v, %v", prompts, err)
+	}
+}
+
+func TestLoadPromptTemplates_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		templates map[string]string
+		want      string
+	}{
+		{"unknown task type", map[string]string{"chore.tmpl": "{{.Title}}"}, "no task type"},
+		{"syntax error", map[string]string{"default.tmpl": "{{.Title"}, "parsing"},
+		{"unknown field", map[string]string{"bug.tmpl": "{{.Titel}}"}, "checking"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := executor.LoadPromptTemplates(writePromptTemplates(t, tt.templates))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Expected an error mentioning %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestPromptTemplates_SelectedByTaskType(t *testing.T) {
+	prompts, err := executor.LoadPromptTemplates(writePromptTemplates(t, map[string]string{
+		"default.tmpl": "Generic: {{.Title}}",
+		"bug.tmpl": "Reproduce first. Bug {{.Task.ID}} in epic {{.EpicID}}, attempt {{.Attempt}}: {{.Title}}\n" +
+			"{{range .Guidance}}Note: {{.}}\n{{end}}",
+	}))
+	if err != nil {
+		t.Fatalf("LoadPromptTemplates: %v", err)
+	}
+	if names := prompts.Names(); strings.Join(names, ",") != "bug,default" {
+		t.Errorf("Expected bug and default templates, got %v", names)
+	}
+
+	script, recordFile := createMockCustomScript(t, t.TempDir(), "done", 0)
+	agent, err := executor.NewAgent(&executor.AgentConfig{
+		Type:            "custom",
+		Custom:          executor.CustomAgentConfig{Command: script + " --prompt {{.PromptFile}}"},
+		Timeout:         time.Minute,
+		PromptTemplates: prompts,
+	})
+	if err != nil {
+		t.Fatalf("NewAgent: %v", err)
+	}
+
+	tests := []struct {
+		task *types.Task
+		want string
+	}{
+		{
+			&types.Task{ID: "task-3", Title: "Fix the crash", Type: types.TaskTypeBug, EpicID: "epic-1", Attempts: 1,
+				ExecutionContext: &types.TaskExecutionContext{Guidance: []*types.GuidanceMessage{{Message: "Check the nil map"}}}},
+			"Reproduce first. Bug task-3 in epic epic-1, attempt 2: Fix the crash\nNote: Check the nil map\n",
+		},
+		{&types.Task{ID: "task-4", Title: "Add a flag", Type: types.TaskTypeFeature}, "Generic: Add a flag"},
+	}
+	for _, tt := range tests {
+		result := agent.ExecuteWithContext(context.Background(), t.TempDir(), tt.task)
+		if !result.Success {
+			t.Fatalf("Execute %s failed: %v", tt.task.ID, result.Error)
+		}
+		recorded, err := os.ReadFile(recordFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(recorded), tt.want) {
+			t.Errorf("Expected the %s prompt %q, got:\n%s", tt.task.Type, tt.want, recorded)
+		}
+		if strings.Contains(string(recorded), "Task: ") {
+			t.Errorf("Expected the built-in prompt replaced, got:\n%s", recorded)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// DefaultRemoteWorkerDir is where task worktrees are synced to on a remote
+// worker host, relative to the SSH user's home directory
+const DefaultRemoteWorkerDir = ".drover/remote"
+
+// remoteSyncTimeout bounds syncing a worktree back from a remote host, which
+// runs after the task even when it was cancelled, so its work isn't lost
+const remoteSyncTimeout = 10 * time.Minute
+
+// remoteSyncExcludes are left out when syncing a worktree either way: its
+// .git file points into the local repository, and the guidance file is the
+// orchestrator's
+var remoteSyncExcludes = []string{"/.git", "/" + guidanceFile}
+
+// sshOptions keep ssh from prompting, which would hang a task
+var sshOptions = []string{"-o", "BatchMode=yes"}
+
+// RemoteWorkers are the hosts drover-worker runs tasks on over SSH. Each
+// task's worktree is synced to the host with rsync, the worker runs there,
+// and the worktree is synced back for the task to be committed locally as
+// usual. A task goes to the host running the fewest
+type RemoteWorkers struct {
+	hosts  []string
+	dir    string
+	binary string
+
+	mu      sync.Mutex
+	running map[string]int
+}
+
+// NewRemoteWorkers creates the remote workers on hosts, each an SSH
+// destination such as user@host or a Host of ~/.ssh/config. Worktrees go to
+// dir on a host (empty = DefaultRemoteWorkerDir) and binary is drover-worker
+// there (empty = "drover-worker")
+func NewRemoteWorkers(hosts []string, dir, binary string) *RemoteWorkers {
+	if dir == "" {
+		dir = DefaultRemoteWorkerDir
+	}
+	if binary == "" {
+		binary = "drover-worker"
+	}
+	return &RemoteWorkers{hosts: hosts, dir: dir, binary: binary, running: map[string]int{}}
+}
+
+// acquire picks the host for a task; release once the task is done there
+func (r *RemoteWorkers) acquire() (host string, release func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range r.hosts {
+		if host == "" || r.running[h] < r.running[host] {
+			host = h
+		}
+	}
+	r.running[host]++
+	var once sync.Once
+	return host, func() {
+		once.Do(func() {
+			r.mu.Lock()
+			r.running[host]--
+			r.mu.Unlock()
+		})
+	}
+}
+
+// remoteTask is a task's worktree on a remote host
+type remoteTask struct {
+	workers  *RemoteWorkers
+	host     string
+	dir      string // The worktree on the host
+	worktree string // The local worktree
+	release  func()
+}
+
+// start picks a host for the task and syncs its worktree there
+func (r *RemoteWorkers) start(ctx context.Context, task *types.Task, worktreePath string) (*remoteTask, error) {
+	host, release := r.acquire()
+	// Unique, so a task doesn't land in the directory of another drover's
+	// attempt at it, or of an earlier attempt left behind
+	name := fmt.Sprintf("%s-%d", containerNameUnsafe.ReplaceAllString(task.ID, "-"), time.Now().UnixNano())
+	t := &remoteTask{
+		workers:  r,
+		host:     host,
+		dir:      path.Join(r.dir, name),
+		worktree: worktreePath,
+		release:  release,
+	}
+	if err := t.ssh(ctx, "mkdir", "-p", t.dir).Run(); err != nil {
+		release()
+		return nil, fmt.Errorf("reaching %s: %w", host, err)
+	}
+	if err := t.rsync(ctx, strings.TrimSuffix(worktreePath, "/")+"/", host+":"+t.dir+"/"); err != nil {
+		release()
+		return nil, fmt.Errorf("syncing the worktree to %s: %w", host, err)
+	}
+	return t, nil
+}
+
+// command returns the command running drover-worker with args on the host
+func (t *remoteTask) command(ctx context.Context, args ...string) *exec.Cmd {
+	return t.ssh(ctx, append([]string{t.workers.binary}, args...)...)
+}
+
+// finish syncs the worktree back from the host, whether the task succeeded
+// or not, so a paused or retried task keeps its work, then removes it there
+func (t *remoteTask) finish() error {
+	defer t.release()
+	ctx, cancel := context.WithTimeout(context.Background(), remoteSyncTimeout)
+	defer cancel()
+	if err := t.rsync(ctx, t.host+":"+t.dir+"/", strings.TrimSuffix(t.worktree, "/")+"/"); err != nil {
+		return fmt.Errorf("syncing the worktree back from %s (it's kept there in %s): %w", t.host, t.dir, err)
+	}
+	if output, err := t.ssh(ctx, "rm", "-rf", t.dir).CombinedOutput(); err != nil {
+		log.Printf("⚠️  Removing %s from %s: %v\n%s", t.dir, t.host, err, output)
+	}
+	return nil
+}
+
+// ssh returns the command running a command on the host
+func (t *remoteTask) ssh(ctx context.Context, command ...string) *exec.Cmd {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		quoted[i] = shellQuote(arg)
+	}
+	args := append(append([]string{}, sshOptions...), t.host, strings.Join(quoted, " "))
+	return exec.CommandContext(ctx, "ssh", args...)
+}
+
+// rsync copies the worktree from src to dst, removing what's gone from src
+func (t *remoteTask) rsync(ctx context.Context, src, dst string) error {
+	args := []string{"-az", "--delete", "-e", "ssh " + strings.Join(sshOptions, " ")}
+	for _, pattern := range remoteSyncExcludes {
+		args = append(args, "--exclude="+pattern)
+	}
+	output, err := exec.CommandContext(ctx, "rsync", append(args, src, dst)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w\n%s", err, output)
+	}
+	return nil
+}
+
+// shellQuote quotes an argument for the remote shell ssh runs commands in
+func shellQuote(arg string) string {
+	if arg != "" && strings.Trim(arg, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:@") == "" {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// remoteEnv returns the task's environment entries for a remote host. The
+// shared Go build cache is a local path, so the host keeps its own
+func remoteEnv(task *types.Task) []string {
+	if task.ExecutionContext == nil {
+		return nil
+	}
+	var env []string
+	for _, entry := range task.ExecutionContext.Env {
+		if !strings.HasPrefix(entry, "GOCACHE=") {
+			env = append(env, entry)
+		}
+	}
+	return env
+}
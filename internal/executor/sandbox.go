@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Sandbox confines the processes an agent runs, so the code a task runs
+// can't touch the host
+type Sandbox interface {
+	// Wrap rewrites cmd, about to work on the task in worktreePath, to run
+	// inside the sandbox
+	Wrap(cmd *exec.Cmd, worktreePath string) error
+
+	// Check verifies the sandbox can run, such as that its tool is installed
+	Check() error
+}
+
+// sandboxKey carries the sandbox of an execution in its context
+type sandboxKey struct{}
+
+// sandboxed is the sandbox an execution runs in, and the worktree it works on
+type sandboxed struct {
+	sandbox      Sandbox
+	worktreePath string
+}
+
+// withSandbox returns a context whose agent processes run in sandbox,
+// working on the task in worktreePath
+func withSandbox(ctx context.Context, sandbox Sandbox, worktreePath string) context.Context {
+	return context.WithValue(ctx, sandboxKey{}, sandboxed{sandbox: sandbox, worktreePath: worktreePath})
+}
+
+// sandboxCmd moves cmd into the sandbox of the execution ctx belongs to, if
+// any. When it can't, cmd is left to fail to start with the reason
+func sandboxCmd(ctx context.Context, cmd *exec.Cmd) {
+	s, ok := ctx.Value(sandboxKey{}).(sandboxed)
+	if !ok {
+		return
+	}
+	if err := s.sandbox.Wrap(cmd, s.worktreePath); err != nil {
+		cmd.Err = fmt.Errorf("sandbox: %w", err)
+	}
+}
+
+// SandboxedAgent runs another agent with every process it starts for a task
+// inside a sandbox. The agent's program has to exist where the sandbox runs
+// it, such as in the container image
+type SandboxedAgent struct {
+	Agent
+	sandbox Sandbox
+}
+
+// NewSandboxedAgent wraps agent so its processes run in sandbox
+func NewSandboxedAgent(agent Agent, sandbox Sandbox) *SandboxedAgent {
+	return &SandboxedAgent{Agent: agent, sandbox: sandbox}
+}
+
+// ExecuteWithContext runs the task with the wrapped agent, inside the sandbox
+func (a *SandboxedAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *ExecutionResult {
+	return a.Agent.ExecuteWithContext(withSandbox(ctx, a.sandbox, worktreePath), worktreePath, task, parentSpan...)
+}
+
+// CheckInstalled verifies the sandbox can run; the agent itself only needs to
+// exist inside it
+func (a *SandboxedAgent) CheckInstalled() error {
+	return a.sandbox.Check()
+}
+
+// Close releases what the wrapped agent holds for the run
+func (a *SandboxedAgent) Close() error {
+	return CloseAgent(a.Agent)
+}
+
+// extraEnv returns the entries of env that aren't drover's own environment,
+// such as a task's variables; nil env means drover's own environment
+func extraEnv(env []string, own []string) []string {
+	if env == nil {
+		return nil
+	}
+	inherited := make(map[string]bool, len(own))
+	for _, entry := range own {
+		inherited[entry] = true
+	}
+	var extra []string
+	for _, entry := range env {
+		if !inherited[entry] && strings.Contains(entry, "=") {
+			extra = append(extra, entry)
+		}
+	}
+	return extra
+}
@@ -0,0 +1,296 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cloud-shuttle/drover/internal/dashboard"
+)
+
+// maxEventText bounds the text of an event broadcast to the dashboard
+const maxEventText = 500
+
+// Kinds of agent events
+const (
+	EventKindMessage    = "message"
+	EventKindToolCall   = "tool_call"
+	EventKindToolResult = "tool_result"
+	EventKindResult     = "result"
+	EventKindError      = "error"
+)
+
+// eventStreamer is implemented by agents that can report their work as JSON
+// events while they run
+type eventStreamer interface {
+	SetStreamEvents(bool)
+}
+
+// eventWriter parses the JSON events an agent writes, one per line, as they
+// arrive. It broadcasts each to the dashboard and writes a readable version
+// of it to out, so the task's output stays plain text; lines that aren't
+// events pass through as they are
+type eventWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	taskID string
+	agent  string
+	parse  func(line []byte) ([]agentEvent, bool) // Reads a line of the agent's event format
+	buf    []byte
+}
+
+// newEventWriter creates an event writer for a task's agent, reading the
+// event formats of Claude Code, Amp and Codex
+func newEventWriter(out io.Writer, taskID, agent string) *eventWriter {
+	return &eventWriter{out: out, taskID: taskID, agent: agent, parse: parseAgentEvents}
+}
+
+// Write handles the complete lines in p, keeping a partial one for later
+func (w *eventWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		line := w.buf[:i+1]
+		w.buf = w.buf[i+1:]
+		if err := w.handle(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush handles what's left of the last line once the agent exits
+func (w *eventWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := append(w.buf, '\n')
+	w.buf = nil
+	return w.handle(line)
+}
+
+func (w *eventWriter) handle(line []byte) error {
+	events, ok := w.parse(line)
+	if !ok {
+		_, err := w.out.Write(line)
+		return err
+	}
+	for _, event := range events {
+		dashboard.BroadcastAgentEvent(dashboard.AgentEvent{
+			TaskID: w.taskID,
+			Agent:  w.agent,
+			Kind:   event.Kind,
+			Tool:   event.Tool,
+			Text:   truncateString(event.Text, maxEventText),
+		})
+		if text := renderAgentEvent(event); text != "" {
+			if _, err := io.WriteString(w.out, text+"\n"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// agentEvent is one step an agent reported
+type agentEvent struct {
+	Kind string
+	Tool string
+	Text string
+}
+
+// renderAgentEvent is how an event reads in the task's output
+func renderAgentEvent(e agentEvent) string {
+	switch e.Kind {
+	case EventKindMessage:
+		return e.Text
+	case EventKindToolCall:
+		if e.Text == "" {
+			return "🔧 " + e.Tool
+		}
+		return fmt.Sprintf("🔧 %s: %s", e.Tool, e.Text)
+	case EventKindError:
+		return "⚠️  " + e.Text
+	}
+	// Tool output and the closing result repeat what the messages and the
+	// worktree already show
+	return ""
+}
+
+// streamLine is the union of the fields of the JSON event formats agents
+// stream: Claude Code's and Amp's stream-json, and Codex's exec --json
+type streamLine struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	IsError bool   `json:"is_error"`
+	Result  string `json:"result"`
+	Message struct {
+		Content []struct {
+			Type    string          `json:"type"`
+			Text    string          `json:"text"`
+			Name    string          `json:"name"`
+			Input   json.RawMessage `json:"input"`
+			Content json.RawMessage `json:"content"`
+			IsError bool            `json:"is_error"`
+		} `json:"content"`
+	} `json:"message"`
+	Item struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		Command  string `json:"command"`
+		Output   string `json:"aggregated_output"`
+		ExitCode *int   `json:"exit_code"`
+		Message  string `json:"message"`
+		Changes  []struct {
+			Path string `json:"path"`
+			Kind string `json:"kind"`
+		} `json:"changes"`
+		Server string `json:"server"`
+		Tool   string `json:"tool"`
+		Query  string `json:"query"`
+	} `json:"item"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// parseAgentEvents parses a line of an agent's JSON event stream; ok is
+// false when the line isn't one
+func parseAgentEvents(line []byte) (events []agentEvent, ok bool) {
+	trimmed := bytes.TrimSpace(line)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, false
+	}
+	var l streamLine
+	if err := json.Unmarshal(trimmed, &l); err != nil || l.Type == "" {
+		return nil, false
+	}
+
+	switch l.Type {
+	// Claude Code and Amp
+	case "assistant":
+		for _, c := range l.Message.Content {
+			switch c.Type {
+			case "text":
+				if text := strings.TrimSpace(c.Text); text != "" {
+					events = append(events, agentEvent{Kind: EventKindMessage, Text: text})
+				}
+			case "tool_use":
+				events = append(events, agentEvent{Kind: EventKindToolCall, Tool: c.Name, Text: summarizeToolInput(c.Input)})
+			}
+		}
+	case "user":
+		for _, c := range l.Message.Content {
+			if c.Type != "tool_result" {
+				continue
+			}
+			kind := EventKindToolResult
+			if c.IsError {
+				kind = EventKindError
+			}
+			events = append(events, agentEvent{Kind: kind, Text: toolResultText(c.Content)})
+		}
+	case "result":
+		kind := EventKindResult
+		if l.IsError {
+			kind = EventKindError
+		}
+		events = append(events, agentEvent{Kind: kind, Text: strings.TrimSpace(l.Result)})
+
+	// Codex
+	case "item.started":
+		switch l.Item.Type {
+		case "command_execution":
+			events = append(events, agentEvent{Kind: EventKindToolCall, Tool: "shell", Text: l.Item.Command})
+		case "mcp_tool_call":
+			events = append(events, agentEvent{Kind: EventKindToolCall, Tool: l.Item.Server + "." + l.Item.Tool})
+		case "web_search":
+			events = append(events, agentEvent{Kind: EventKindToolCall, Tool: "web_search", Text: l.Item.Query})
+		}
+	case "item.completed":
+		switch l.Item.Type {
+		case "agent_message":
+			events = append(events, agentEvent{Kind: EventKindMessage, Text: strings.TrimSpace(l.Item.Text)})
+		case "command_execution":
+			kind := EventKindToolResult
+			if l.Item.ExitCode != nil && *l.Item.ExitCode != 0 {
+				kind = EventKindError
+			}
+			events = append(events, agentEvent{Kind: kind, Tool: "shell", Text: strings.TrimSpace(l.Item.Output)})
+		case "file_change":
+			var changes []string
+			for _, c := range l.Item.Changes {
+				changes = append(changes, c.Kind+" "+c.Path)
+			}
+			events = append(events, agentEvent{Kind: EventKindToolCall, Tool: "edit", Text: strings.Join(changes, ", ")})
+		case "error":
+			events = append(events, agentEvent{Kind: EventKindError, Text: l.Item.Message})
+		}
+	case "error", "turn.failed":
+		text := l.Error.Message
+		if text == "" {
+			text = string(trimmed)
+		}
+		events = append(events, agentEvent{Kind: EventKindError, Text: text})
+
+	case "system", "thread.started", "turn.started", "turn.completed":
+		// Bookkeeping, nothing to show
+	default:
+		return nil, false
+	}
+	return events, true
+}
+
+// summarizeToolInput picks the telling part of a tool call's input, such as
+// the command run or the file edited
+func summarizeToolInput(input json.RawMessage) string {
+	var fields map[string]any
+	if err := json.Unmarshal(input, &fields); err != nil || len(fields) == 0 {
+		return ""
+	}
+	for _, key := range []string{"command", "file_path", "filePath", "path", "pattern", "url", "query", "description", "prompt"} {
+		if v, ok := fields[key].(string); ok && v != "" {
+			return v
+		}
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
+
+// toolResultText is the text of a tool result, which is either a string or
+// a list of content blocks
+func toolResultText(content json.RawMessage) string {
+	var text string
+	if err := json.Unmarshal(content, &text); err == nil {
+		return strings.TrimSpace(text)
+	}
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(content, &blocks); err != nil {
+		return ""
+	}
+	var parts []string
+	for _, b := range blocks {
+		if b.Type == "text" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEventWriter_RendersClaudeStream(t *testing.T) {
+	stream := `{"type":"system","subtype":"init","session_id":"abc"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Let me run the tests."},{"type":"tool_use","id":"t1","name":"Bash","input":{"command":"go test ./...","description":"Run tests"}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","tool_use_id":"t1","content":"FAIL","is_error":true}]}}
+not json at all
+{"type":"result","subtype":"success","is_error":false,"result":"Done."}`
+
+	var out strings.Builder
+	w := newEventWriter(&out, "task-1", "claude-code")
+	// Write in odd-sized pieces, as a pipe would deliver it
+	for i := 0; i < len(stream); i += 7 {
+		end := min(i+7, len(stream))
+		if _, err := w.Write([]byte(stream[i:end])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "Let me run the tests.\n🔧 Bash: go test ./...\n⚠️  FAIL\nnot json at all\n"
+	if out.String() != want {
+		t.Errorf("Rendered output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestParseAgentEvents_Codex(t *testing.T) {
+	tests := []struct {
+		line string
+		want []agentEvent
+	}{
+		{
+			line: `{"type":"item.started","item":{"id":"item_1","type":"command_execution","command":"bash -lc ls","status":"in_progress"}}`,
+			want: []agentEvent{{Kind: EventKindToolCall, Tool: "shell", Text: "bash -lc ls"}},
+		},
+		{
+			line: `{"type":"item.completed","item":{"id":"item_1","type":"command_execution","command":"ls","aggregated_output":"oops\n","exit_code":2}}`,
+			want: []agentEvent{{Kind: EventKindError, Tool: "shell", Text: "oops"}},
+		},
+		{
+			line: `{"type":"item.completed","item":{"type":"file_change","changes":[{"path":"main.go","kind":"update"}]}}`,
+			want: []agentEvent{{Kind: EventKindToolCall, Tool: "edit", Text: "update main.go"}},
+		},
+		{
+			line: `{"type":"item.completed","item":{"type":"agent_message","text":"All set."}}`,
+			want: []agentEvent{{Kind: EventKindMessage, Text: "All set."}},
+		},
+		{line: `{"type":"turn.completed","usage":{"input_tokens":10}}`},
+	}
+	for _, tt := range tests {
+		got, ok := parseAgentEvents([]byte(tt.line))
+		if !ok {
+			t.Errorf("parseAgentEvents(%s) didn't recognize the event", tt.line)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseAgentEvents(%s) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{`{"name":"not an event"}`, `{"type":"something.else"}`, `[1,2]`, `plain text`} {
+		if _, ok := parseAgentEvents([]byte(line)); ok {
+			t.Errorf("parseAgentEvents(%s) took a non-event for an event", line)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package executor
+
+import (
+	"github.com/cloud-shuttle/drover/internal/outcome"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// readVerdictBlock records the verdict block a successful run ends its reply
+// with: its verdict and reason, the files it changed and the follow-ups it
+// suggests. reply is the agent's reply when it's apart from its output; a
+// verdict the agent reported another way stands
+func readVerdictBlock(result *ExecutionResult, reply string) *ExecutionResult {
+	if !result.Success || result.Verdict != "" {
+		return result
+	}
+	if reply == "" {
+		reply = result.Output
+	}
+	block, ok := outcome.ParseVerdictBlock(reply)
+	if !ok {
+		return result
+	}
+	result.Verdict = types.TaskVerdict(block.Verdict)
+	result.VerdictReason = block.Reason
+	result.FilesChanged = block.FilesChanged
+	result.Followups = block.Followups
+	return result
+}
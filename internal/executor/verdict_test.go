@@ -0,0 +1,52 @@
+package executor_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestAgent_VerdictBlock(t *testing.T) {
+	dir := t.TempDir()
+	reply := filepath.Join(dir, "reply.txt")
+	prompt := filepath.Join(dir, "prompt.txt")
+	if err := os.WriteFile(reply, []byte("Added the endpoint.\n\n```drover-verdict\n"+
+		`{"verdict": "fail", "reason": "tests still fail", "files_changed": ["api/health.go"], "followups": ["fix the flaky test"]}`+
+		"\n```\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "mytool.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/bash\ncp \"$1\" "+prompt+"\ncat "+reply+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	agent, err := executor.NewCustomAgent(executor.CustomAgentConfig{Command: script + " {{.PromptFile}}"}, time.Minute)
+	if err != nil {
+		t.Fatalf("NewCustomAgent failed: %v", err)
+	}
+	result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-7", Title: "Add a health endpoint"})
+	if !result.Success {
+		t.Fatalf("Execute failed: %v", result.Error)
+	}
+	if result.Verdict != types.TaskVerdictFail || result.VerdictReason != "tests still fail" {
+		t.Errorf("Expected a fail verdict, got %q (%q)", result.Verdict, result.VerdictReason)
+	}
+	if !reflect.DeepEqual(result.FilesChanged, []string{"api/health.go"}) || !reflect.DeepEqual(result.Followups, []string{"fix the flaky test"}) {
+		t.Errorf("Expected the block's files and follow-ups, got %v and %v", result.FilesChanged, result.Followups)
+	}
+
+	sent, err := os.ReadFile(prompt)
+	if err != nil {
+		t.Fatalf("Reading the prompt: %v", err)
+	}
+	if !strings.Contains(string(sent), "```drover-verdict") {
+		t.Errorf("Expected the prompt to ask for a verdict block, got:\n%s", sent)
+	}
+}
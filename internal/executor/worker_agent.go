@@ -12,13 +12,28 @@ import (
 	"strings"
 	"time"
 
-	"github.com/cloud-shuttle/drover/internal/backpressure"
 	"github.com/cloud-shuttle/drover/internal/memory"
 	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/worker"
 	"github.com/cloud-shuttle/drover/pkg/types"
 	"go.opentelemetry.io/otel/trace"
 )
 
+// Protocols the orchestrator and drover-worker talk
+const (
+	// WorkerProtocolGRPC runs the task over the worker's gRPC service,
+	// which streams heartbeats, output and memory stats and can cancel it
+	WorkerProtocolGRPC = "grpc"
+
+	// WorkerProtocolJSON writes the task to the worker's stdin as JSON and
+	// reads its result from stdout, for workers without the gRPC service
+	WorkerProtocolJSON = "json"
+)
+
+// workerCancelGrace is how long a cancelled task's worker has to report the
+// result of the stopped task before it's killed
+const workerCancelGrace = 10 * time.Second
+
 // WorkerAgent executes tasks via the drover-worker subprocess
 type WorkerAgent struct {
 	workerBinary  string
@@ -26,6 +41,9 @@ type WorkerAgent struct {
 	timeout       time.Duration
 	memoryLimit   string
 	verbose       bool
+	protocol      string
+	remote        *RemoteWorkers // nil = workers run locally
+	pool          *WorkerPool    // nil = a worker process per task
 }
 
 // NewWorkerAgent creates a new worker subprocess agent
@@ -40,6 +58,35 @@ func NewWorkerAgent(workerBinary, claudePath string, timeout time.Duration) *Wor
 // SetVerbose enables or disables verbose logging
 func (a *WorkerAgent) SetVerbose(v bool) {
 	a.verbose = v
+	if a.pool != nil {
+		a.pool.SetVerbose(v)
+	}
+}
+
+// SetProtocol sets the protocol to talk to worker processes in,
+// WorkerProtocolGRPC (the default) or WorkerProtocolJSON
+func (a *WorkerAgent) SetProtocol(protocol string) {
+	a.protocol = protocol
+}
+
+// SetRemote has workers run on remote hosts over SSH rather than locally
+func (a *WorkerAgent) SetRemote(remote *RemoteWorkers) {
+	a.remote = remote
+}
+
+// SetPool has tasks run on the long-lived workers of a pool rather than a
+// worker process each
+func (a *WorkerAgent) SetPool(pool *WorkerPool) {
+	a.pool = pool
+	pool.SetVerbose(a.verbose)
+}
+
+// Close stops the worker pool, if any
+func (a *WorkerAgent) Close() error {
+	if a.pool != nil {
+		a.pool.Stop()
+	}
+	return nil
 }
 
 // SetMemoryLimit sets the memory limit for worker processes
@@ -82,18 +129,16 @@ func (a *WorkerAgent) ExecuteWithContext(ctx context.Context, worktreePath strin
 	start := time.Now()
 
 	// Build task input for worker
-	input := map[string]interface{}{
-		"id":          task.ID,
-		"title":       task.Title,
-		"description": task.Description,
-		"worktree":    worktreePath,
-		"timeout":     a.timeout.String(),
-		"claude_path": a.claudePath,
-		"verbose":     a.verbose,
-	}
-
-	if task.EpicID != "" {
-		input["epic_id"] = task.EpicID
+	input := &worker.TaskInput{
+		ID:          task.ID,
+		Title:       task.Title,
+		Description: task.Description,
+		EpicID:      task.EpicID,
+		Worktree:    worktreePath,
+		Timeout:     a.timeout.String(),
+		ClaudePath:  a.claudePath,
+		Verbose:     a.verbose,
+		MemoryLimit: a.memoryLimit,
 	}
 
 	// Add guidance if available
@@ -102,52 +147,212 @@ func (a *WorkerAgent) ExecuteWithContext(ctx context.Context, worktreePath strin
 		for i, g := range task.ExecutionContext.Guidance {
 			guidance[i] = g.Message
 		}
-		input["guidance"] = guidance
+		input.Guidance = guidance
+	}
+	// Guidance added while the task runs is handed over through a file
+	if task.ExecutionContext != nil && task.ExecutionContext.GuidanceFile != "" {
+		input.GuidanceFile = task.ExecutionContext.GuidanceFile
+	}
+
+	// On a remote host the worker runs in the worktree synced there
+	var remote *remoteTask
+	if a.remote != nil {
+		var err error
+		remote, err = a.remote.start(ctx, task, worktreePath)
+		if err != nil {
+			return &ExecutionResult{
+				Success:  false,
+				Error:    fmt.Errorf("remote worker: %w", err),
+				Duration: time.Since(start),
+			}
+		}
+		log.Printf("🛰️  Task %s running on %s", task.ID, remote.host)
+		input.Worktree = remote.dir
+		input.GuidanceFile = "" // Local; guidance added mid-run waits for the next attempt
+		input.Env = remoteEnv(task)
+	}
+
+	var run *workerRun
+	if a.pool != nil {
+		run = a.runPooled(ctx, task, input)
+	} else if a.protocol == WorkerProtocolJSON {
+		run = a.runJSON(ctx, task, input, remote)
+	} else {
+		run = a.runGRPC(ctx, task, input, remote)
+	}
+	// Work that doesn't make it back is lost, so the task fails
+	if remote != nil {
+		if err := remote.finish(); err != nil && run.err == nil {
+			run.err = fmt.Errorf("remote worker: %w", err)
+			run.output = run.result.Output
+		}
+	}
+	duration := time.Since(start)
+	if run.err != nil {
+		return &ExecutionResult{
+			Success:       false,
+			Output:        run.output,
+			Error:         run.err,
+			Duration:      duration,
+			WorkerPID:     run.pid,
+			PeakRSSBytes:  run.peakRSS,
+			FinalRSSBytes: run.finalRSS,
+		}
+	}
+	result := run.result
+
+	// Log memory usage if verbose
+	if a.verbose && (run.peakRSS > 0 || run.finalRSS > 0) {
+		log.Printf("[memory] worker %d: peak=%s, final=%s",
+			run.pid, memory.FormatBytes(run.peakRSS), memory.FormatBytes(run.finalRSS))
+	}
+
+	// Return execution result
+	execResult := &ExecutionResult{
+		Success:       result.Success,
+		Output:        result.Output,
+		Duration:      duration,
+		Signal:        result.Signal, // Populate signal from worker result
+		Verdict:       types.TaskVerdict(result.Verdict),
+		VerdictReason: result.VerdictReason,
+		FilesChanged:  result.FilesChanged,
+		Followups:     result.Followups,
+		InputTokens:   result.InputTokens,
+		OutputTokens:  result.OutputTokens,
+		CostUSD:       result.CostUSD,
+		GuidanceDelivered: result.GuidanceDelivered,
+		WorkerPID:     run.pid,
+		PeakRSSBytes:  run.peakRSS,
+		FinalRSSBytes: run.finalRSS,
+	}
+
+	if !result.Success {
+		if result.Error != "" {
+			execResult.Error = fmt.Errorf("worker error: %s", result.Error)
+		} else {
+			execResult.Error = fmt.Errorf("worker exited with error")
+		}
+	}
+
+	return execResult
+}
+
+// workerRun is how a run of the worker process went
+type workerRun struct {
+	result   *worker.TaskResult
+	err      error  // Set when the worker produced no result
+	output   string // What the worker printed, for a run without a result
+	pid      int
+	peakRSS  int64
+	finalRSS int64
+}
+
+// runGRPC runs the task on a 'drover-worker serve' process, over gRPC on its
+// stdin and stdout. Cancelling ctx cancels the task, and the worker is
+// killed if it hasn't stopped once workerCancelGrace has passed
+func (a *WorkerAgent) runGRPC(ctx context.Context, task *types.Task, input *worker.TaskInput, remote *remoteTask) *workerRun {
+	killCtx, kill := context.WithCancel(context.Background())
+	defer kill()
+	go func() {
+		select {
+		case <-ctx.Done():
+			select {
+			case <-time.After(workerCancelGrace):
+				kill()
+			case <-killCtx.Done():
+			}
+		case <-killCtx.Done():
+		}
+	}()
+
+	cmd := a.workerCommand(killCtx, task, remote, "serve")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return &workerRun{err: fmt.Errorf("failed to start worker: %w", err)}
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return &workerRun{err: fmt.Errorf("failed to start worker: %w", err)}
+	}
+	if remote == nil {
+		sandboxCmd(ctx, cmd)
+		cpuLimitCmd(ctx, cmd)
+	}
+
+	// Claude's output arrives on the stream; the worker's own stderr is
+	// kept for when it fails
+	output := newRunOutput(ctx, task)
+	stderr := output.redact(io.MultiWriter(os.Stderr, output.stderr))
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return &workerRun{err: fmt.Errorf("failed to start worker: %w", err)}
 	}
 
-	if a.memoryLimit != "" {
-		input["memory_limit"] = a.memoryLimit
+	run := &workerRun{pid: cmd.Process.Pid}
+	result, err := worker.ExecuteTask(ctx, stdout, stdin, input, func(event *worker.ExecuteEvent) {
+		switch {
+		case event.Output != nil:
+			io.WriteString(stderr, event.Output.Data)
+		case event.Memory != nil:
+			run.peakRSS = max(run.peakRSS, event.Memory.PeakRSSBytes)
+			run.finalRSS = event.Memory.RSSBytes
+		case event.Progress != nil && a.verbose:
+			log.Printf("[worker] %s: %s", event.Progress.TaskID, event.Progress.Message)
+		}
+	})
+	stdin.Close()
+	waitErr := cmd.Wait()
+	output.close()
+	if err != nil {
+		if waitErr != nil {
+			err = fmt.Errorf("%w (%v)", waitErr, err)
+		}
+		run.err = fmt.Errorf("worker failed: %w", err)
+		run.output = output.stderr.String()
+		return run
 	}
+	run.result = result
+	return run
+}
 
+// runJSON runs the task on a 'drover-worker execute -' process, which reads
+// the task from stdin as JSON and prints its result to stdout the same way
+func (a *WorkerAgent) runJSON(ctx context.Context, task *types.Task, input *worker.TaskInput, remote *remoteTask) *workerRun {
 	// Marshal input to JSON
 	inputJSON, err := json.Marshal(input)
 	if err != nil {
-		return &ExecutionResult{
-			Success: false,
-			Output:  "",
-			Error:   fmt.Errorf("failed to marshal task input: %w", err),
-			Duration: time.Since(start),
-		}
+		return &workerRun{err: fmt.Errorf("failed to marshal task input: %w", err)}
 	}
 
 	// Build command
-	args := []string{"execute", "-"}
-	cmd := exec.CommandContext(ctx, a.workerBinary, args...)
+	cmd := a.workerCommand(ctx, task, remote, "execute", "-")
 
 	// Set up stdin with JSON input
 	cmd.Stdin = strings.NewReader(string(inputJSON))
+	if remote == nil {
+		sandboxCmd(ctx, cmd)
+		cpuLimitCmd(ctx, cmd)
+	}
 
 	// Capture stdout (result JSON) and stream stderr (heartbeats, debug output)
-	var stdoutBuf, stderrBuf strings.Builder
+	var stdoutBuf strings.Builder
+	output := newRunOutput(ctx, task)
 	cmd.Stdout = &stdoutBuf
-	cmd.Stderr = io.MultiWriter(os.Stderr, &stderrBuf)
+	cmd.Stderr = output.redact(io.MultiWriter(os.Stderr, output.stderr))
 
 	// Start the worker process
 	if err := cmd.Start(); err != nil {
-		return &ExecutionResult{
-			Success: false,
-			Output:  "",
-			Error:   fmt.Errorf("failed to start worker: %w", err),
-			Duration: time.Since(start),
-		}
+		return &workerRun{err: fmt.Errorf("failed to start worker: %w", err)}
 	}
 
-	workerPID := cmd.Process.Pid
+	run := &workerRun{pid: cmd.Process.Pid}
 
 	// Start memory sampling goroutine
 	memSampleDone := make(chan struct{})
+	memSampled := make(chan struct{})
 	var peakRSS int64
 	go func() {
+		defer close(memSampled)
 		ticker := time.NewTicker(1 * time.Second)
 		defer ticker.Stop()
 		for {
@@ -155,7 +360,7 @@ func (a *WorkerAgent) ExecuteWithContext(ctx context.Context, worktreePath strin
 			case <-memSampleDone:
 				return
 			case <-ticker.C:
-				if mem, err := memory.GetProcessMemory(workerPID); err == nil {
+				if mem, err := memory.GetProcessMemory(run.pid); err == nil {
 					if mem.RSSBytes > peakRSS {
 						peakRSS = mem.RSSBytes
 					}
@@ -166,81 +371,45 @@ func (a *WorkerAgent) ExecuteWithContext(ctx context.Context, worktreePath strin
 
 	// Wait for the worker to complete
 	err = cmd.Wait()
-	duration := time.Since(start)
+	output.close()
 	close(memSampleDone) // Stop memory sampling
+	<-memSampled
+	run.peakRSS = peakRSS
 
 	// Get final memory reading
-	var finalRSS int64
-	if mem, err := memory.GetProcessMemory(workerPID); err == nil {
-		finalRSS = mem.RSSBytes
+	if mem, err := memory.GetProcessMemory(run.pid); err == nil {
+		run.finalRSS = mem.RSSBytes
 	}
 
 	// Parse result from stdout
-	var result struct {
-		Success       bool   `json:"success"`
-		TaskID        string `json:"task_id"`
-		Output        string `json:"output"`
-		Error         string `json:"error,omitempty"`
-		DurationMs    int64  `json:"duration_ms"`
-		Signal        string `json:"signal"`
-		Verdict       string `json:"verdict,omitempty"`
-		VerdictReason string `json:"verdict_reason,omitempty"`
-	}
-
 	resultJSON := stdoutBuf.String()
 	if resultJSON == "" {
 		// Worker failed without producing output
-		errMsg := err.Error()
-		if stderrBuf.String() != "" {
-			errMsg += ": " + stderrBuf.String()
-		}
-		return &ExecutionResult{
-			Success:       false,
-			Output:        stderrBuf.String(),
-			Error:         fmt.Errorf("worker failed: %w", err),
-			Duration:      duration,
-			WorkerPID:     workerPID,
-			PeakRSSBytes:  peakRSS,
-			FinalRSSBytes: finalRSS,
-		}
+		run.err = fmt.Errorf("worker failed: %w", err)
+		run.output = output.stderr.String()
+		return run
 	}
 
+	var result worker.TaskResult
 	if err := json.Unmarshal([]byte(resultJSON), &result); err != nil {
-		return &ExecutionResult{
-			Success:       false,
-			Output:        resultJSON,
-			Error:         fmt.Errorf("failed to parse worker result: %w", err),
-			Duration:      duration,
-			WorkerPID:     workerPID,
-			PeakRSSBytes:  peakRSS,
-			FinalRSSBytes: finalRSS,
-		}
-	}
-
-	// Log memory usage if verbose
-	if a.verbose && (peakRSS > 0 || finalRSS > 0) {
-		log.Printf("[memory] worker %d: peak=%s, final=%s",
-			workerPID, memory.FormatBytes(peakRSS), memory.FormatBytes(finalRSS))
-	}
-
-	// Return execution result
-	execResult := &ExecutionResult{
-		Success:       result.Success,
-		Output:        result.Output,
-		Duration:      duration,
-		Signal:        backpressure.WorkerSignal(result.Signal), // Populate signal from worker result
-		WorkerPID:     workerPID,
-		PeakRSSBytes:  peakRSS,
-		FinalRSSBytes: finalRSS,
+		run.err = fmt.Errorf("failed to parse worker result: %w", err)
+		run.output = resultJSON
+		return run
 	}
+	run.result = &result
+	return run
+}
 
-	if !result.Success {
-		if result.Error != "" {
-			execResult.Error = fmt.Errorf("worker error: %s", result.Error)
-		} else {
-			execResult.Error = fmt.Errorf("worker exited with error")
-		}
+// workerCommand returns the command running drover-worker with args, over
+// SSH when the task runs on a remote host
+func (a *WorkerAgent) workerCommand(ctx context.Context, task *types.Task, remote *remoteTask, args ...string) *exec.Cmd {
+	var cmd *exec.Cmd
+	if remote != nil {
+		cmd = remote.command(ctx, args...)
+	} else {
+		cmd = exec.CommandContext(ctx, a.workerBinary, args...)
+		cmd.Env = commandEnv(task)
 	}
-
-	return execResult
+	detach(cmd)
+	return cmd
 }
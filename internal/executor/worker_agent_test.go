@@ -0,0 +1,218 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/worker"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// TestWorkerHelper stands in for drover-worker when the worker agent tests
+// run the test binary as it
+func TestWorkerHelper(t *testing.T) {
+	if os.Getenv("DROVER_FAKE_WORKER") != "1" {
+		t.Skip("helper process for the worker agent tests")
+	}
+	for i, arg := range os.Args {
+		if arg == "--" {
+			os.Args = append([]string{"drover-worker"}, os.Args[i+1:]...)
+			break
+		}
+	}
+	if err := worker.NewCLI().Execute(); err != nil {
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
+
+// fakeWorker writes scripts that re-run the test binary as drover-worker,
+// and stand in for Claude with a reply ending in a pass verdict after
+// sleeping for claudeSleep
+func fakeWorker(t *testing.T, claudeSleep string) (workerPath, claudePath string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as the worker and claude binaries")
+	}
+	dir := t.TempDir()
+	workerPath = filepath.Join(dir, "drover-worker")
+	body := fmt.Sprintf("#!/bin/sh\nDROVER_FAKE_WORKER=1 exec %q -test.run=TestWorkerHelper -- \"$@\"\n", os.Args[0])
+	if err := os.WriteFile(workerPath, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	claudePath = filepath.Join(dir, "claude")
+	reply := `{"type":"result","subtype":"success","result":"done\n` + "```drover-verdict" + `\n{\"verdict\":\"pass\"}\n` + "```" + `","total_cost_usd":0.5}`
+	body = fmt.Sprintf("#!/bin/sh\nsleep %s\necho 'working' >&2\nprintf '%%s\\n' '%s'\n", claudeSleep, reply)
+	if err := os.WriteFile(claudePath, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return workerPath, claudePath
+}
+
+func TestWorkerAgent_Protocols(t *testing.T) {
+	workerPath, claudePath := fakeWorker(t, "0")
+	for _, protocol := range []string{WorkerProtocolGRPC, WorkerProtocolJSON} {
+		t.Run(protocol, func(t *testing.T) {
+			agent := NewWorkerAgent(workerPath, claudePath, time.Minute)
+			agent.SetProtocol(protocol)
+			result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1", Title: "Add a flag"})
+			if !result.Success {
+				t.Fatalf("Expected success, got %v\n%s", result.Error, result.Output)
+			}
+			if result.Verdict != types.TaskVerdictPass || result.CostUSD != 0.5 {
+				t.Errorf("Expected the worker's verdict and cost, got %q and %v", result.Verdict, result.CostUSD)
+			}
+			if !strings.Contains(result.Output, "done") || result.WorkerPID == 0 {
+				t.Errorf("Expected Claude's reply and the worker's PID, got %q (pid %d)", result.Output, result.WorkerPID)
+			}
+		})
+	}
+}
+
+func TestWorkerAgent_GRPCCancel(t *testing.T) {
+	workerPath, claudePath := fakeWorker(t, "30")
+	agent := NewWorkerAgent(workerPath, claudePath, time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	result := agent.ExecuteWithContext(ctx, t.TempDir(), &types.Task{ID: "task-1", Title: "Add a flag"})
+	if result.Success {
+		t.Fatal("Expected the cancelled task to fail")
+	}
+	// The worker reports the stopped run rather than being killed
+	if elapsed := time.Since(start); elapsed >= workerCancelGrace {
+		t.Errorf("Expected the worker to stop the task, it took %v", elapsed)
+	}
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "worker error") {
+		t.Errorf("Expected the worker's result for the stopped task, got %v", result.Error)
+	}
+}
+
+func TestWorkerAgent_Remote(t *testing.T) {
+	workerPath, claudePath := fakeWorker(t, "0")
+	// Claude leaves a file in the worktree it runs in, on the host
+	script, _ := os.ReadFile(claudePath)
+	script = []byte(strings.Replace(string(script), "sleep 0\n", "sleep 0\necho built > artifact.txt\n", 1))
+	if err := os.WriteFile(claudePath, script, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// ssh runs the command in a directory standing in for the host's home,
+	// and rsync copies between it and the local worktree
+	home := t.TempDir()
+	bin := t.TempDir()
+	ssh := "#!/bin/sh\nwhile [ \"$1\" = -o ]; do shift 2; done\nshift\ncd \"$FAKE_HOME\" && exec sh -c \"$*\"\n"
+	rsync := "#!/bin/sh\nfor arg; do src=$dst; dst=$arg; done\n" +
+		"src=$(echo \"$src\" | sed \"s|^[^/]*:|$FAKE_HOME/|\"); dst=$(echo \"$dst\" | sed \"s|^[^/]*:|$FAKE_HOME/|\")\n" +
+		"mkdir -p \"$dst\" && cp -R \"$src\". \"$dst\"\n"
+	for name, body := range map[string]string{"ssh": ssh, "rsync": rsync} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(body), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("FAKE_HOME", home)
+
+	worktree := t.TempDir()
+	if err := os.WriteFile(filepath.Join(worktree, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	remote := NewRemoteWorkers([]string{"builder@big-box"}, "", workerPath)
+	agent := NewWorkerAgent("drover-worker", claudePath, time.Minute)
+	agent.SetRemote(remote)
+	result := agent.ExecuteWithContext(context.Background(), worktree, &types.Task{ID: "task-1", Title: "Build it"})
+	if !result.Success || result.Verdict != types.TaskVerdictPass {
+		t.Fatalf("Expected the remote run to pass, got %v\n%s", result.Error, result.Output)
+	}
+	if data, err := os.ReadFile(filepath.Join(worktree, "artifact.txt")); err != nil || string(data) != "built\n" {
+		t.Errorf("Expected the host's changes synced back, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(home, DefaultRemoteWorkerDir, "task-1")); !os.IsNotExist(err) {
+		t.Errorf("Expected the worktree removed from the host, got %v", err)
+	}
+	if remote.running["builder@big-box"] != 0 {
+		t.Errorf("Expected the host released, %d tasks still count against it", remote.running["builder@big-box"])
+	}
+}
+
+func TestRemoteWorkers_Acquire(t *testing.T) {
+	remote := NewRemoteWorkers([]string{"a", "b"}, "", "")
+	first, releaseFirst := remote.acquire()
+	second, _ := remote.acquire()
+	if first == second {
+		t.Errorf("Expected tasks spread across hosts, both went to %s", first)
+	}
+	releaseFirst()
+	releaseFirst() // Releasing twice must not skew the count
+	if host, _ := remote.acquire(); host != first {
+		t.Errorf("Expected the idle host %s, got %s", first, host)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := map[string]string{
+		"drover-worker":      "drover-worker",
+		".drover/remote/t-1": ".drover/remote/t-1",
+		"it's here":          `'it'\''s here'`,
+		"":                   "''",
+		"$(rm -rf ~)":        "'$(rm -rf ~)'",
+	}
+	for arg, want := range tests {
+		if got := shellQuote(arg); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", arg, got, want)
+		}
+	}
+}
+
+func TestWorkerAgent_Pool(t *testing.T) {
+	workerPath, claudePath := fakeWorker(t, "0")
+	pool := NewWorkerPool(workerPath, 1, 2)
+	agent := NewWorkerAgent(workerPath, claudePath, time.Minute)
+	agent.SetPool(pool)
+	defer agent.Close()
+
+	run := func() int {
+		t.Helper()
+		result := agent.ExecuteWithContext(context.Background(), t.TempDir(), &types.Task{ID: "task-1", Title: "Add a flag"})
+		if !result.Success || result.Verdict != types.TaskVerdictPass {
+			t.Fatalf("Expected the pooled run to pass, got %v\n%s", result.Error, result.Output)
+		}
+		return result.WorkerPID
+	}
+	first, second, third := run(), run(), run()
+	if first != second {
+		t.Errorf("Expected the worker reused, got pids %d and %d", first, second)
+	}
+	if third == second {
+		t.Errorf("Expected the worker recycled after 2 tasks, pid %d ran a third", third)
+	}
+
+	// A worker that crashes while idle is replaced
+	pool.mu.Lock()
+	idle := pool.idle[0]
+	pool.mu.Unlock()
+	idle.kill()
+	<-idle.exited
+	if pid := run(); pid == third {
+		t.Errorf("Expected a new worker after the crash, pid %d ran the task", pid)
+	}
+
+	pool.mu.Lock()
+	workers := append([]*pooledWorker{}, pool.idle...)
+	pool.mu.Unlock()
+	pool.Stop()
+	for _, w := range workers {
+		select {
+		case <-w.exited:
+		case <-time.After(5 * time.Second):
+			t.Errorf("Expected worker %d to exit once the pool stopped", w.pid)
+		}
+	}
+}
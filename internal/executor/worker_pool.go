@@ -0,0 +1,335 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/worker"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// DefaultWorkerPoolMaxTasks is how many tasks a pooled worker runs before
+// it's replaced, unless configured otherwise
+const DefaultWorkerPoolMaxTasks = 25
+
+// WorkerPool keeps drover-worker processes running, each serving task after
+// task over the gRPC protocol, so a task doesn't wait for a worker to start.
+// A worker is recycled after its task limit, and one that exits is replaced
+type WorkerPool struct {
+	binary   string
+	size     int
+	maxTasks int
+	verbose  bool
+	cpuLimit CPULimit
+
+	mu      sync.Mutex
+	idle    []*pooledWorker
+	live    int // Workers started and not yet exited
+	started bool
+	closed  bool
+}
+
+// NewWorkerPool creates a pool of size workers running binary, each
+// recycled after maxTasks tasks (0 = DefaultWorkerPoolMaxTasks, <0 = never).
+// No worker starts until Start or the first task acquires one
+func NewWorkerPool(binary string, size, maxTasks int) *WorkerPool {
+	if size < 1 {
+		size = 1
+	}
+	if maxTasks == 0 {
+		maxTasks = DefaultWorkerPoolMaxTasks
+	}
+	return &WorkerPool{binary: binary, size: size, maxTasks: maxTasks}
+}
+
+// SetVerbose enables logging each worker's start and stop
+func (p *WorkerPool) SetVerbose(v bool) {
+	p.verbose = v
+}
+
+// SetCPULimit has the workers run under limit, each in a cgroup of its own
+// for a quota, as it runs one task at a time
+func (p *WorkerPool) SetCPULimit(limit CPULimit) {
+	p.cpuLimit = limit
+}
+
+// pooledWorker is a worker process of the pool
+type pooledWorker struct {
+	cmd    *exec.Cmd
+	client *worker.Client
+	group  *cpuCgroup // nil = no CPU quota
+	pid    int
+	tasks  int           // Tasks it has run
+	exited chan struct{} // Closed once the process has exited
+}
+
+// Start starts the pool's workers, returning the error of the first that
+// fails to start. It's called by the first task when not called before
+func (p *WorkerPool) Start() error {
+	p.mu.Lock()
+	p.started = true
+	p.mu.Unlock()
+	for {
+		p.mu.Lock()
+		full := p.closed || p.live >= p.size
+		if !full {
+			p.live++
+		}
+		p.mu.Unlock()
+		if full {
+			return nil
+		}
+		w, err := p.spawn()
+		if err != nil {
+			p.mu.Lock()
+			p.live--
+			p.mu.Unlock()
+			return err
+		}
+		p.mu.Lock()
+		p.idle = append(p.idle, w)
+		p.mu.Unlock()
+	}
+}
+
+// spawn starts a worker process
+func (p *WorkerPool) spawn() (*pooledWorker, error) {
+	// Not bound to a task; kill stops it
+	cmd := exec.CommandContext(context.Background(), p.binary, "serve", "--pool")
+	detach(cmd)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = os.Stderr
+	var group *cpuCgroup
+	if p.cpuLimit.CPUs > 0 {
+		if group, err = newCPUCgroup(p.cpuLimit, "worker"); err != nil {
+			warnCPULimit.Do(func() { log.Printf("⚠️  Agents run without a CPU quota: %v", err) })
+			group = nil
+		}
+	}
+	applyCPULimit(cmd, p.cpuLimit, group)
+	if err := cmd.Start(); err != nil {
+		if group != nil {
+			group.remove()
+		}
+		return nil, fmt.Errorf("failed to start worker: %w", err)
+	}
+	client, err := worker.NewClient(stdout, stdin)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		if group != nil {
+			group.remove()
+		}
+		return nil, err
+	}
+	w := &pooledWorker{cmd: cmd, client: client, group: group, pid: cmd.Process.Pid, exited: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		if w.group != nil {
+			w.group.remove()
+		}
+		close(w.exited)
+		p.exited(w)
+	}()
+	if p.verbose {
+		log.Printf("[worker-pool] started worker %d", w.pid)
+	}
+	return w, nil
+}
+
+// acquire returns an idle worker for a task, starting one when none is
+func (p *WorkerPool) acquire() (*pooledWorker, error) {
+	p.mu.Lock()
+	started := p.started
+	p.mu.Unlock()
+	if !started {
+		if err := p.Start(); err != nil {
+			return nil, err
+		}
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errors.New("the worker pool is closed")
+	}
+	for len(p.idle) > 0 {
+		w := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if !w.dead() {
+			p.mu.Unlock()
+			return w, nil
+		}
+	}
+	p.live++
+	p.mu.Unlock()
+
+	w, err := p.spawn()
+	if err != nil {
+		p.mu.Lock()
+		p.live--
+		p.mu.Unlock()
+		return nil, err
+	}
+	return w, nil
+}
+
+// release returns a worker after a task. One past its task limit, or beyond
+// the pool's size, is stopped and replaced as needed; a dead one is
+// replaced when it's noticed exiting
+func (p *WorkerPool) release(w *pooledWorker) {
+	w.tasks++
+	if w.dead() {
+		return
+	}
+	p.mu.Lock()
+	recycle := p.maxTasks > 0 && w.tasks >= p.maxTasks
+	if !recycle && !p.closed && len(p.idle) < p.size {
+		p.idle = append(p.idle, w)
+		p.mu.Unlock()
+		return
+	}
+	p.mu.Unlock()
+	if recycle {
+		log.Printf("♻️  Recycling worker %d after %d tasks", w.pid, w.tasks)
+	}
+	p.stop(w)
+}
+
+// exited forgets a worker whose process exited, starting a replacement
+// unless the pool is closed or has enough
+func (p *WorkerPool) exited(w *pooledWorker) {
+	p.mu.Lock()
+	p.live--
+	for i, idle := range p.idle {
+		if idle == w {
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			break
+		}
+	}
+	replace := !p.closed && p.live < p.size
+	p.mu.Unlock()
+	if p.verbose {
+		log.Printf("[worker-pool] worker %d exited after %d tasks", w.pid, w.tasks)
+	}
+	if replace {
+		go func() {
+			if err := p.Start(); err != nil {
+				log.Printf("⚠️  Replacing worker %d: %v", w.pid, err)
+			}
+		}()
+	}
+}
+
+// stop closes a worker's connection, which has it exit, killing it if it
+// hasn't once workerCancelGrace has passed
+func (p *WorkerPool) stop(w *pooledWorker) {
+	w.client.Close()
+	select {
+	case <-w.exited:
+	case <-time.After(workerCancelGrace):
+		w.kill()
+		<-w.exited
+	}
+}
+
+// Stop stops the pool's idle workers; those running a task stop once it's
+// done
+func (p *WorkerPool) Stop() {
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+	for _, w := range idle {
+		p.stop(w)
+	}
+}
+
+// dead reports whether the worker's process has exited
+func (w *pooledWorker) dead() bool {
+	select {
+	case <-w.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+// kill kills the worker's process group
+func (w *pooledWorker) kill() {
+	if w.cmd.Cancel != nil {
+		w.cmd.Cancel()
+	} else {
+		w.cmd.Process.Kill()
+	}
+}
+
+// runPooled runs the task on a worker of the pool. Cancelling ctx cancels
+// the task, and the worker is killed, and replaced, if it hasn't stopped
+// once workerCancelGrace has passed
+func (a *WorkerAgent) runPooled(ctx context.Context, task *types.Task, input *worker.TaskInput) *workerRun {
+	w, err := a.pool.acquire()
+	if err != nil {
+		return &workerRun{err: fmt.Errorf("worker pool: %w", err)}
+	}
+	defer a.pool.release(w)
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			select {
+			case <-time.After(workerCancelGrace):
+				w.kill()
+			case <-done:
+			}
+		case <-done:
+		}
+	}()
+
+	// The worker doesn't inherit the task's environment
+	if task.ExecutionContext != nil {
+		input.Env = task.ExecutionContext.Env
+	}
+	output := newRunOutput(ctx, task)
+	stderr := output.redact(io.MultiWriter(os.Stderr, output.stderr))
+	run := &workerRun{pid: w.pid}
+	result, err := w.client.ExecuteTask(ctx, input, func(event *worker.ExecuteEvent) {
+		switch {
+		case event.Output != nil:
+			io.WriteString(stderr, event.Output.Data)
+		case event.Memory != nil:
+			run.peakRSS = max(run.peakRSS, event.Memory.PeakRSSBytes)
+			run.finalRSS = event.Memory.RSSBytes
+		case event.Progress != nil && a.verbose:
+			log.Printf("[worker] %s: %s", event.Progress.TaskID, event.Progress.Message)
+		}
+	})
+	output.close()
+	if err != nil {
+		// A worker that broke off the task may be in any state
+		w.kill()
+		<-w.exited
+		run.err = fmt.Errorf("worker failed: %w", err)
+		run.output = output.stderr.String()
+		return run
+	}
+	run.result = result
+	return run
+}
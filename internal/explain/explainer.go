@@ -0,0 +1,152 @@
+package explain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/llmproxy"
+	llmclient "github.com/cloud-shuttle/drover/internal/llmproxy/client"
+)
+
+const systemPrompt = "You are a senior engineer triaging tasks run unattended by AI coding agents. " +
+	"You read a task's recorded history and explain concisely what happened and what the operator should do next."
+
+// Explainer asks an LLM to explain a task's history
+type Explainer struct {
+	client       *llmclient.Client
+	model        string
+	apiKey       string
+	useDirectAPI bool
+}
+
+// NewExplainer creates an explainer that calls the LLM through the proxy
+func NewExplainer(llmClient *llmclient.Client, model string) *Explainer {
+	return &Explainer{
+		client: llmClient,
+		model:  model,
+	}
+}
+
+// NewExplainerWithDirectAPI creates an explainer that uses the Anthropic API directly
+func NewExplainerWithDirectAPI(apiKey, model string) *Explainer {
+	return &Explainer{
+		apiKey:       apiKey,
+		model:        model,
+		useDirectAPI: true,
+	}
+}
+
+// Explain returns a short natural-language explanation of the task's history
+func (e *Explainer) Explain(ctx context.Context, h *History) (string, error) {
+	prompt := BuildPrompt(h)
+	if e.useDirectAPI {
+		return e.callAnthropicDirect(ctx, prompt)
+	}
+	return e.callViaProxy(ctx, prompt)
+}
+
+// BuildPrompt creates the prompt asking for an explanation of the history
+func BuildPrompt(h *History) string {
+	return fmt.Sprintf(`Below is everything drover recorded about one task: its status transitions,
+activity timeline, events, the tail of the agent's output and the changes it made.
+
+%s
+
+## Your Task
+
+Explain in a few short paragraphs, for someone triaging overnight failures:
+
+1. **What happened**: the task's path through its attempts, and the outcome
+2. **Why**: the most likely root cause of any failure or block, quoting the relevant error
+3. **What to do next**: concrete next steps, naming drover commands where they apply
+   (e.g. "drover retry %s", "drover hint %s \"...\"", "drover resolve %s", "drover edit %s")
+
+Base the explanation only on the history above; say so if it is not enough to tell.
+Keep it under 250 words and do not restate the history verbatim.`,
+		h.Render(), h.Task.ID, h.Task.ID, h.Task.ID, h.Task.ID)
+}
+
+// callViaProxy calls the LLM through the proxy server
+func (e *Explainer) callViaProxy(ctx context.Context, prompt string) (string, error) {
+	req := &llmproxy.ChatRequest{
+		Model: e.model,
+		Messages: []llmproxy.Message{
+			{Role: llmproxy.RoleSystem, Content: systemPrompt},
+			{Role: llmproxy.RoleUser, Content: prompt},
+		},
+		Temperature: 0.2,
+		MaxTokens:   1500,
+	}
+
+	resp, err := e.client.Chat(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("calling AI via proxy: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from AI")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// callAnthropicDirect calls the Anthropic API directly
+func (e *Explainer) callAnthropicDirect(ctx context.Context, prompt string) (string, error) {
+	type anthropicMessage struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	type anthropicRequest struct {
+		Model     string             `json:"model"`
+		MaxTokens int                `json:"max_tokens"`
+		Messages  []anthropicMessage `json:"messages"`
+		System    string             `json:"system,omitempty"`
+	}
+
+	jsonBody, err := json.Marshal(anthropicRequest{
+		Model:     e.model,
+		MaxTokens: 1500,
+		Messages:  []anthropicMessage{{Role: "user", Content: prompt}},
+		System:    systemPrompt,
+	})
+	if err != nil {
+		return "", fmt.Errorf("marshaling request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", "https://api.anthropic.com/v1/messages", bytes.NewReader(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("creating request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", e.apiKey)
+	httpReq.Header.Set("anthropic-version", "2023-06-01")
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("calling Anthropic API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("Anthropic API error: status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("decoding response: %w", err)
+	}
+	if len(result.Content) == 0 {
+		return "", fmt.Errorf("empty response from Anthropic API")
+	}
+	return result.Content[0].Text, nil
+}
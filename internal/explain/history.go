@@ -0,0 +1,181 @@
+// Package explain assembles a task's history and asks an LLM to explain what
+// happened to it and what to do next
+package explain
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// Limits keeping the assembled history within a reasonable prompt size
+const (
+	maxEvents      = 200
+	maxOutputBytes = 8 * 1024
+	maxDiffBytes   = 16 * 1024
+)
+
+// History is everything drover recorded about a task
+type History struct {
+	Task       *types.Task
+	Activity   []*types.TaskActivity
+	Audit      []*types.AuditEvent
+	Events     []map[string]any
+	Checkpoint *types.TaskCheckpoint
+	Branch     string // Worktree branch the agent worked on, if known
+	Diff       string // Changes on Branch relative to the project HEAD
+}
+
+// Gather collects a task's history from the store and, when its worktree
+// branch still exists in projectDir, the changes the agent made
+func Gather(store *db.Store, projectDir, taskID string) (*History, error) {
+	task, err := store.GetTask(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("task not found: %s", taskID)
+	}
+
+	h := &History{Task: task}
+	if h.Activity, err = store.ListActivity(taskID); err != nil {
+		return nil, fmt.Errorf("listing activity: %w", err)
+	}
+	if h.Audit, err = store.ListAudit(taskID); err != nil {
+		return nil, fmt.Errorf("listing audit log: %w", err)
+	}
+	if h.Events, err = store.QueryEvents(nil, "", taskID, 0, 0, maxEvents); err != nil {
+		return nil, fmt.Errorf("querying events: %w", err)
+	}
+	// The checkpoint only exists while a task runs or after it crashed
+	h.Checkpoint, _ = store.GetCheckpoint(taskID)
+
+	worktrees, err := store.ListWorktrees()
+	if err != nil {
+		return nil, fmt.Errorf("listing worktrees: %w", err)
+	}
+	for _, wt := range worktrees {
+		if wt.TaskID == taskID && wt.Branch != "" {
+			h.Branch = wt.Branch
+			h.Diff = branchDiff(projectDir, wt.Branch)
+			break
+		}
+	}
+
+	return h, nil
+}
+
+// branchDiff returns the changes on branch since it forked from HEAD, or ""
+// if the branch no longer exists
+func branchDiff(projectDir, branch string) string {
+	cmd := exec.Command("git", "diff", "--stat", "--patch", "HEAD..."+branch)
+	cmd.Dir = projectDir
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return truncate(string(out), maxDiffBytes)
+}
+
+// Render formats the history as plain text, oldest entries first
+func (h *History) Render() string {
+	var b strings.Builder
+	t := h.Task
+
+	fmt.Fprintf(&b, "# Task %s: %s\n\n", t.ID, t.Title)
+	if t.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", t.Description)
+	}
+	fmt.Fprintf(&b, "Status: %s\n", t.Status)
+	fmt.Fprintf(&b, "Attempts: %d of %d\n", t.Attempts, t.MaxAttempts)
+	if t.Verdict != "" && t.Verdict != types.TaskVerdictUnknown {
+		fmt.Fprintf(&b, "Verdict: %s", t.Verdict)
+		if t.VerdictReason != "" {
+			fmt.Fprintf(&b, " (%s)", t.VerdictReason)
+		}
+		b.WriteString("\n")
+	}
+	if t.LastError != "" {
+		fmt.Fprintf(&b, "Last error: %s\n", t.LastError)
+	}
+	if t.EpicID != "" {
+		fmt.Fprintf(&b, "Epic: %s\n", t.EpicID)
+	}
+
+	if len(h.Audit) > 0 {
+		b.WriteString("\n## Status transitions\n\n")
+		for _, e := range h.Audit {
+			fmt.Fprintf(&b, "- %s %s: %s → %s", formatTime(e.CreatedAt), e.Actor, e.From, e.To)
+			if e.Error != "" {
+				fmt.Fprintf(&b, " (%s)", e.Error)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	// Status changes are already listed with their actors above
+	var activity []*types.TaskActivity
+	for _, a := range h.Activity {
+		if a.Kind != types.ActivityStatus || len(h.Audit) == 0 {
+			activity = append(activity, a)
+		}
+	}
+	if len(activity) > 0 {
+		b.WriteString("\n## Activity\n\n")
+		for _, a := range activity {
+			fmt.Fprintf(&b, "- %s [%s]", formatTime(a.CreatedAt), a.Kind)
+			if a.Author != "" {
+				fmt.Fprintf(&b, " %s:", a.Author)
+			}
+			fmt.Fprintf(&b, " %s\n", a.Body)
+		}
+	}
+
+	if len(h.Events) > 0 {
+		b.WriteString("\n## Events\n\n")
+		for _, e := range h.Events {
+			ts, _ := e["timestamp"].(int64)
+			fmt.Fprintf(&b, "- %s %v", formatTime(ts), e["type"])
+			if data, ok := e["data"].(string); ok && data != "" && data != "{}" {
+				fmt.Fprintf(&b, " %s", data)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if h.Checkpoint != nil && h.Checkpoint.Output != "" {
+		fmt.Fprintf(&b, "\n## Agent output (attempt %d)\n\n```\n%s\n```\n",
+			h.Checkpoint.Attempt, tail(h.Checkpoint.Output, maxOutputBytes))
+	}
+
+	if h.Diff != "" {
+		fmt.Fprintf(&b, "\n## Changes on %s\n\n```diff\n%s\n```\n", h.Branch, h.Diff)
+	}
+
+	return b.String()
+}
+
+// formatTime renders a Unix timestamp, or "-" when unset
+func formatTime(ts int64) string {
+	if ts == 0 {
+		return "-"
+	}
+	return time.Unix(ts, 0).Format("2006-01-02 15:04:05")
+}
+
+// truncate keeps the first n bytes of s
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "\n... (truncated)"
+}
+
+// tail keeps the last n bytes of s, where agent failures usually show up
+func tail(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return "(truncated) ...\n" + s[len(s)-n:]
+}
@@ -0,0 +1,81 @@
+package explain
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestGather_AssemblesTaskHistory(t *testing.T) {
+	tmpDir := t.TempDir()
+	store, err := db.Open(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test store: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to init schema: %v", err)
+	}
+	// The events table is only created by migrations
+	if err := store.MigrateSchema(); err != nil {
+		t.Fatalf("Failed to migrate schema: %v", err)
+	}
+
+	task, err := store.CreateTask("Add login page", "Build the login form", "", 0, nil)
+	if err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	if _, err := store.ClaimTask("worker-1"); err != nil {
+		t.Fatalf("ClaimTask: %v", err)
+	}
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusFailed, "go test: undefined: LoginHandler"); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	if _, err := store.AddComment(task.ID, "alice", "probably needs the auth package first"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	if err := store.CreateCheckpoint(&types.TaskCheckpoint{
+		TaskID:  task.ID,
+		State:   types.TaskStatusInProgress,
+		Attempt: 1,
+		Output:  strings.Repeat("x", maxOutputBytes) + "FAIL: TestLogin",
+	}); err != nil {
+		t.Fatalf("CreateCheckpoint: %v", err)
+	}
+
+	// The worktree branch is gone, so there is no diff to include
+	h, err := Gather(store, tmpDir, task.ID)
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	if h.Diff != "" {
+		t.Errorf("expected no diff without a worktree branch, got %q", h.Diff)
+	}
+
+	rendered := h.Render()
+	for _, want := range []string{
+		"Add login page",
+		"Status: failed",
+		"Last error: go test: undefined: LoginHandler",
+		"ready → claimed",
+		"alice: probably needs the auth package first",
+		"FAIL: TestLogin",
+		"(truncated)",
+	} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendered history missing %q:\n%s", want, rendered)
+		}
+	}
+
+	prompt := BuildPrompt(h)
+	if !strings.Contains(prompt, rendered) || !strings.Contains(prompt, "drover retry "+task.ID) {
+		t.Errorf("prompt should embed the history and suggest drover commands:\n%s", prompt)
+	}
+
+	if _, err := Gather(store, tmpDir, "task-missing"); err == nil {
+		t.Error("expected an error for an unknown task")
+	}
+}
@@ -0,0 +1,161 @@
+package fleet
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/workflow"
+)
+
+// statusInterval is how often the fleet logs what each project is doing
+const statusInterval = time.Minute
+
+// Fleet is a pool of workers serving several projects
+type Fleet struct {
+	workers   int
+	poll      time.Duration // How long a project without ready tasks is skipped
+	scheduler *Scheduler
+	tenants   map[string]*tenant
+	order     []string // Project names in registration order, for status lines
+}
+
+// tenant is a project with its database and orchestrator
+type tenant struct {
+	project Project
+	store   *db.Store
+	orch    *workflow.Orchestrator
+}
+
+// New opens every project and creates an orchestrator for it. Each project is
+// configured from cfg, with its own .drover.toml applied as in 'drover run'
+func New(cfg *config.Config, projects []Project, workers int) (*Fleet, error) {
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no projects registered (add one with 'drover fleet add <dir>')")
+	}
+	if workers < 1 {
+		return nil, fmt.Errorf("workers must be at least 1")
+	}
+
+	f := &Fleet{
+		workers:   workers,
+		poll:      cfg.PollInterval,
+		scheduler: NewScheduler(projects),
+		tenants:   make(map[string]*tenant, len(projects)),
+	}
+	for _, p := range projects {
+		t, err := openTenant(cfg, p)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("project %s: %w", p.Name, err)
+		}
+		f.tenants[p.Name] = t
+		f.order = append(f.order, p.Name)
+	}
+	return f, nil
+}
+
+func openTenant(cfg *config.Config, p Project) (*tenant, error) {
+	store, err := db.Open(filepath.Join(p.Dir, ".drover", "drover.db"))
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	projectCfg := *cfg
+	projectCfg.ProjectDir = p.Dir
+	orch, err := workflow.NewOrchestrator(&projectCfg, store, p.Dir)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return &tenant{project: p, store: store, orch: orch}, nil
+}
+
+// Run serves the projects until the context is cancelled. Unlike 'drover
+// run' it keeps going when the queues empty, picking up tasks as they are added
+func (f *Fleet) Run(ctx context.Context) error {
+	log.Printf("🚚 Fleet starting with %d workers across %d projects", f.workers, len(f.tenants))
+	for _, name := range f.order {
+		t := f.tenants[name]
+		log.Printf("   %s: %s (quota %s, weight %d)", name, t.project.Dir, quotaString(t.project.Quota), max(t.project.Weight, 1))
+		t.orch.Start()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < f.workers; i++ {
+		wg.Add(1)
+		go f.worker(ctx, i, &wg)
+	}
+
+	ticker := time.NewTicker(statusInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🛑 Fleet stopping, waiting for running tasks...")
+			wg.Wait()
+			return nil
+		case <-ticker.C:
+			f.logStatus()
+		}
+	}
+}
+
+// worker serves whichever project the scheduler picks, one task at a time
+func (f *Fleet) worker(ctx context.Context, id int, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for ctx.Err() == nil {
+		name, ok := f.scheduler.Acquire(time.Now())
+		if !ok {
+			sleep(ctx, f.poll)
+			continue
+		}
+		claimed, err := f.tenants[name].orch.RunNext(ctx, id)
+		if err != nil {
+			log.Printf("Fleet worker %d: error claiming task in %s: %v", id, name, err)
+		}
+		f.scheduler.Release(name, claimed, time.Now().Add(f.poll))
+	}
+}
+
+func (f *Fleet) logStatus() {
+	running := f.scheduler.Running()
+	parts := make([]string, 0, len(f.order))
+	for _, name := range f.order {
+		status, err := f.tenants[name].store.GetProjectStatus()
+		if err != nil {
+			parts = append(parts, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s %d running, %d ready", name, running[name], status.Ready))
+	}
+	log.Printf("🚚 Fleet: %s", strings.Join(parts, " | "))
+}
+
+// Close releases every project's orchestrator and database
+func (f *Fleet) Close() {
+	for _, t := range f.tenants {
+		t.orch.Close()
+		t.store.Close()
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+func quotaString(quota int) string {
+	if quota == 0 {
+		return "unlimited"
+	}
+	return fmt.Sprint(quota)
+}
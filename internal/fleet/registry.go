@@ -0,0 +1,103 @@
+// Package fleet runs one long-lived pool of workers across several registered
+// drover projects, sharing agent capacity between them fairly
+package fleet
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Project is a drover project served by the fleet
+type Project struct {
+	Name   string `json:"name"`
+	Dir    string `json:"dir"`              // Project directory, containing .drover
+	Quota  int    `json:"quota,omitempty"`  // Most tasks run at once; 0 means no limit
+	Weight int    `json:"weight,omitempty"` // Share of the workers relative to other projects; 0 means 1
+}
+
+// Registry is the set of projects the fleet serves
+type Registry struct {
+	Projects []Project `json:"projects"`
+}
+
+// DefaultRegistryPath returns ~/.drover/fleet.json
+func DefaultRegistryPath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("getting home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".drover", "fleet.json"), nil
+}
+
+// LoadRegistry reads a registry file. A missing file is an empty registry
+func LoadRegistry(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Registry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading fleet registry: %w", err)
+	}
+	var r Registry
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parsing fleet registry %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Save writes the registry file, creating its directory if needed
+func (r *Registry) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating registry directory: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling fleet registry: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("writing fleet registry: %w", err)
+	}
+	return nil
+}
+
+// Add registers a project. The directory must be an initialized drover
+// project, and names and directories must be unique
+func (r *Registry) Add(p Project) error {
+	dir, err := filepath.Abs(p.Dir)
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", p.Dir, err)
+	}
+	p.Dir = dir
+	if p.Name == "" {
+		p.Name = filepath.Base(dir)
+	}
+	if p.Quota < 0 || p.Weight < 0 {
+		return fmt.Errorf("quota and weight must not be negative")
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".drover", "drover.db")); err != nil {
+		return fmt.Errorf("%s is not a drover project (run 'drover init' there first)", dir)
+	}
+	for _, existing := range r.Projects {
+		if existing.Name == p.Name {
+			return fmt.Errorf("project %q is already registered", p.Name)
+		}
+		if existing.Dir == p.Dir {
+			return fmt.Errorf("%s is already registered as %q", p.Dir, existing.Name)
+		}
+	}
+	r.Projects = append(r.Projects, p)
+	return nil
+}
+
+// Remove unregisters a project by name
+func (r *Registry) Remove(name string) error {
+	for i, p := range r.Projects {
+		if p.Name == name {
+			r.Projects = append(r.Projects[:i], r.Projects[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("project %q is not registered", name)
+}
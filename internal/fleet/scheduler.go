@@ -0,0 +1,100 @@
+package fleet
+
+import (
+	"sync"
+	"time"
+)
+
+// Scheduler decides which project a free worker serves next. Among the
+// projects below their quota and not known to be idle, it picks the one with
+// the fewest running tasks per unit of weight, breaking ties in favor of the
+// project served least recently
+type Scheduler struct {
+	mu       sync.Mutex
+	projects []*share
+	served   uint64 // Acquisitions so far, orders lastServed
+}
+
+// share is a project's standing in the scheduler
+type share struct {
+	name       string
+	quota      int
+	weight     int
+	running    int
+	lastServed uint64
+	idleUntil  time.Time // Found without ready tasks; skipped until then
+}
+
+// NewScheduler creates a scheduler for the projects
+func NewScheduler(projects []Project) *Scheduler {
+	s := &Scheduler{}
+	for _, p := range projects {
+		s.projects = append(s.projects, &share{name: p.Name, quota: p.Quota, weight: max(p.Weight, 1)})
+	}
+	return s
+}
+
+// Acquire picks the project a worker should claim from next and counts the
+// worker as running there. Returns false when every project is at its quota
+// or idle
+func (s *Scheduler) Acquire(now time.Time) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var best *share
+	for _, p := range s.projects {
+		if p.quota > 0 && p.running >= p.quota {
+			continue
+		}
+		if now.Before(p.idleUntil) {
+			continue
+		}
+		if best == nil || p.before(best) {
+			best = p
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	s.served++
+	best.running++
+	best.lastServed = s.served
+	return best.name, true
+}
+
+// Release returns a worker acquired for a project. When the worker found no
+// task to claim, the project is skipped until idleUntil so others get a turn
+func (s *Scheduler) Release(name string, claimed bool, idleUntil time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.projects {
+		if p.name == name {
+			p.running--
+			if !claimed {
+				p.idleUntil = idleUntil
+			}
+			return
+		}
+	}
+}
+
+// Running returns the number of workers busy on each project
+func (s *Scheduler) Running() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	running := make(map[string]int, len(s.projects))
+	for _, p := range s.projects {
+		running[p.name] = p.running
+	}
+	return running
+}
+
+// before reports whether p should be served ahead of q
+func (p *share) before(q *share) bool {
+	// Compare running/weight without dividing
+	a, b := p.running*q.weight, q.running*p.weight
+	if a != b {
+		return a < b
+	}
+	return p.lastServed < q.lastServed
+}
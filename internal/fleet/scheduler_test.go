@@ -0,0 +1,103 @@
+package fleet
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScheduler_Weights(t *testing.T) {
+	s := NewScheduler([]Project{{Name: "a", Weight: 2}, {Name: "b"}})
+	now := time.Now()
+
+	counts := map[string]int{}
+	for i := 0; i < 6; i++ {
+		name, ok := s.Acquire(now)
+		if !ok {
+			t.Fatalf("Acquire %d found no project", i)
+		}
+		counts[name]++
+	}
+	if counts["a"] != 4 || counts["b"] != 2 {
+		t.Errorf("workers = %v, want a:4 b:2", counts)
+	}
+}
+
+func TestScheduler_QuotaAndIdle(t *testing.T) {
+	s := NewScheduler([]Project{{Name: "a", Quota: 1}, {Name: "b"}})
+	now := time.Now()
+
+	first, _ := s.Acquire(now)
+	second, _ := s.Acquire(now)
+	third, _ := s.Acquire(now)
+	if first != "a" || second != "b" || third != "b" {
+		t.Fatalf("acquired %s %s %s, want a b b (a is at its quota)", first, second, third)
+	}
+
+	// b found nothing to claim, so it is skipped until the idle period ends
+	s.Release("b", false, now.Add(time.Second))
+	s.Release("b", true, now)
+	if name, ok := s.Acquire(now); ok {
+		t.Errorf("Acquire = %s, want none (a at quota, b idle)", name)
+	}
+	if name, ok := s.Acquire(now.Add(2 * time.Second)); !ok || name != "b" {
+		t.Errorf("Acquire after idle = %s %v, want b", name, ok)
+	}
+
+	if running := s.Running(); running["a"] != 1 || running["b"] != 1 {
+		t.Errorf("running = %v, want a:1 b:1", running)
+	}
+}
+
+func TestScheduler_TieBreak(t *testing.T) {
+	s := NewScheduler([]Project{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+	now := time.Now()
+
+	var order []string
+	for i := 0; i < 3; i++ {
+		name, _ := s.Acquire(now)
+		s.Release(name, true, now)
+		order = append(order, name)
+	}
+	if order[0] != "a" || order[1] != "b" || order[2] != "c" {
+		t.Errorf("order = %v, want round robin a b c", order)
+	}
+}
+
+func TestRegistry_AddRemove(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "api")
+	if err := os.MkdirAll(filepath.Join(project, ".drover"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ".drover", "drover.db"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var r Registry
+	if err := r.Add(Project{Dir: filepath.Join(dir, "missing")}); err == nil {
+		t.Error("Add accepted a directory without a drover project")
+	}
+	if err := r.Add(Project{Dir: project, Quota: 2}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := r.Add(Project{Dir: project, Name: "other"}); err == nil {
+		t.Error("Add accepted the same directory twice")
+	}
+
+	path := filepath.Join(dir, "fleet.json")
+	if err := r.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := LoadRegistry(path)
+	if err != nil {
+		t.Fatalf("LoadRegistry: %v", err)
+	}
+	if len(loaded.Projects) != 1 || loaded.Projects[0].Name != "api" || loaded.Projects[0].Quota != 2 {
+		t.Fatalf("loaded = %+v, want api with quota 2", loaded.Projects)
+	}
+	if err := loaded.Remove("api"); err != nil || len(loaded.Projects) != 0 {
+		t.Errorf("Remove: %v, %d left", err, len(loaded.Projects))
+	}
+}
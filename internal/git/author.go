@@ -0,0 +1,82 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultAuthorName identifies the agent (or model, when known) that produced a commit
+const DefaultAuthorName = "drover[{model}]"
+
+// DefaultAuthorEmail keeps per-agent commits distinguishable without a real mailbox
+const DefaultAuthorEmail = "drover+{agent}@localhost"
+
+// CommitAuthor is the identity task commits are authored as
+// The committer stays the local git identity so blame shows who produced the
+// change while the log still shows who ran drover
+type CommitAuthor struct {
+	Name  string
+	Email string
+}
+
+// IsZero reports whether no author override is configured
+func (a CommitAuthor) IsZero() bool {
+	return a.Name == "" && a.Email == ""
+}
+
+// String formats the author as "Name <email>"
+func (a CommitAuthor) String() string {
+	if a.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s <%s>", a.Name, a.Email)
+}
+
+// env returns the GIT_AUTHOR_* variables for this author appended to the process environment
+func (a CommitAuthor) env() []string {
+	env := os.Environ()
+	if a.Name != "" {
+		env = append(env, "GIT_AUTHOR_NAME="+a.Name)
+	}
+	if a.Email != "" {
+		env = append(env, "GIT_AUTHOR_EMAIL="+a.Email)
+	}
+	return env
+}
+
+// RenderCommitAuthor expands {agent} and {model} in the name and email templates
+// {model} falls back to the agent type when no model is configured. Empty
+// templates disable the override so commits use the local git identity
+func RenderCommitAuthor(nameTemplate, emailTemplate, agent, model string) CommitAuthor {
+	if agent == "" {
+		agent = "agent"
+	}
+	if model == "" {
+		model = agent
+	}
+	r := strings.NewReplacer("{agent}", agent, "{model}", model)
+	return CommitAuthor{
+		Name:  strings.TrimSpace(r.Replace(nameTemplate)),
+		Email: strings.TrimSpace(r.Replace(emailTemplate)),
+	}
+}
+
+// SetCommitAuthor sets the author identity used for task commits
+func (wm *WorktreeManager) SetCommitAuthor(author CommitAuthor) {
+	wm.author = author
+}
+
+// CommitAuthor returns the author identity used for task commits
+func (wm *WorktreeManager) CommitAuthor() CommitAuthor {
+	return wm.author
+}
+
+// CommitSHA returns the commit currently checked out in a task's worktree
+func (wm *WorktreeManager) CommitSHA(taskID string) (string, error) {
+	sha := headCommit(wm.Path(taskID))
+	if sha == "" {
+		return "", fmt.Errorf("resolving HEAD for task %s", taskID)
+	}
+	return sha, nil
+}
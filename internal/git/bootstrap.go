@@ -0,0 +1,79 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// emptyTreeSHA is git's well-known hash of the empty tree
+const emptyTreeSHA = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+// rootCommitMessage is the message of the root commit InitEmptyRepo creates
+const rootCommitMessage = "drover: initialize repository"
+
+// IsEmptyRepo reports whether repoDir is a git repository with no commits yet
+func IsEmptyRepo(repoDir string) bool {
+	cmd := exec.Command("git", "rev-parse", "--git-dir")
+	cmd.Dir = repoDir
+	if err := cmd.Run(); err != nil {
+		return false // Not a repository at all
+	}
+
+	cmd = exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD")
+	cmd.Dir = repoDir
+	return cmd.Run() != nil
+}
+
+// IsBootstrapRoot reports whether repoDir's history is still only the root
+// commit InitEmptyRepo created, so nothing has landed on it yet
+func IsBootstrapRoot(repoDir string) bool {
+	cmd := exec.Command("git", "log", "-1", "--format=%P%x00%T%x00%s", "HEAD")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return false
+	}
+	fields := strings.Split(strings.TrimSuffix(string(output), "\n"), "\x00")
+	return len(fields) == 3 && fields[0] == "" && fields[1] == emptyTreeSHA && fields[2] == rootCommitMessage
+}
+
+// InitEmptyRepo gives an empty repository a root commit on branch so worktrees
+// can be created from it. The commit has an empty tree and is written without
+// touching the index or working directory, so files the user already has in
+// place are left alone. An empty branch keeps the name HEAD already points at.
+// Returns the branch the root commit was created on
+func InitEmptyRepo(repoDir, branch string) (string, error) {
+	if branch == "" {
+		cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD")
+		cmd.Dir = repoDir
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("reading unborn HEAD: %w", err)
+		}
+		branch = strings.TrimSpace(string(output))
+	}
+
+	cmd := exec.Command("git", "commit-tree", emptyTreeSHA, "-m", rootCommitMessage)
+	cmd.Dir = repoDir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("creating root commit: %w\n%s", err, output)
+	}
+	commit := strings.TrimSpace(string(output))
+
+	// Only create the branch if nothing else did in the meantime
+	cmd = exec.Command("git", "update-ref", "refs/heads/"+branch, commit, "")
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("creating branch %s: %w\n%s", branch, err, output)
+	}
+
+	cmd = exec.Command("git", "symbolic-ref", "HEAD", "refs/heads/"+branch)
+	cmd.Dir = repoDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("pointing HEAD at %s: %w\n%s", branch, err, output)
+	}
+
+	return branch, nil
+}
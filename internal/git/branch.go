@@ -0,0 +1,167 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// DefaultBranchTemplate reproduces the historical drover-<taskID> branch names
+const DefaultBranchTemplate = "{prefix}-{id}"
+
+// maxBranchSequence bounds the collision suffix search (name-2, name-3, ...)
+const maxBranchSequence = 100
+
+// maxSlugLength keeps slugified titles from producing unwieldy branch names
+const maxSlugLength = 40
+
+var (
+	slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+	branchRepeats    = regexp.MustCompile(`([-/_.])[-/_.]+`)
+)
+
+// SetBranchTemplate configures how task branch names are built
+// Placeholders: {prefix}, {id}, {epic}, {slug} (slugified title), {date} (YYYYMMDD)
+func (wm *WorktreeManager) SetBranchTemplate(prefix, template string) {
+	if prefix == "" {
+		prefix = "drover"
+	}
+	if template == "" {
+		template = DefaultBranchTemplate
+	}
+	wm.branchPrefix = prefix
+	wm.branchTemplate = template
+}
+
+// BranchName returns the branch used by a task's worktree
+// It prefers the name chosen at Create time, then the branch checked out in
+// an existing worktree (e.g. after a restart), then the default naming
+func (wm *WorktreeManager) BranchName(taskID string) string {
+	wm.branchMu.Lock()
+	name, ok := wm.branches[taskID]
+	wm.branchMu.Unlock()
+	if ok {
+		return name
+	}
+
+	worktreePath := wm.Path(taskID)
+	if _, err := os.Stat(worktreePath); err == nil {
+		cmd := worktreeGit(context.Background(), worktreePath, "symbolic-ref", "--short", "-q", "HEAD")
+		if output, err := cmd.Output(); err == nil {
+			if head := strings.TrimSpace(string(output)); head != "" {
+				return head
+			}
+		}
+	}
+
+	return fmt.Sprintf("%s-%s", wm.prefix(), taskID)
+}
+
+// prefix returns the configured branch prefix
+func (wm *WorktreeManager) prefix() string {
+	if wm.branchPrefix == "" {
+		return "drover"
+	}
+	return wm.branchPrefix
+}
+
+// pooledBranchName returns the branch of a pooled worktree warmed before
+// any task is assigned to it, under the configured prefix
+func (wm *WorktreeManager) pooledBranchName(worktreeID string) string {
+	return fmt.Sprintf("%s-%s", wm.prefix(), worktreeID)
+}
+
+// rememberBranch records the branch chosen for a task
+func (wm *WorktreeManager) rememberBranch(taskID, branch string) {
+	wm.branchMu.Lock()
+	defer wm.branchMu.Unlock()
+	if wm.branches == nil {
+		wm.branches = make(map[string]string)
+	}
+	wm.branches[taskID] = branch
+}
+
+// forgetBranch drops the recorded branch for a task
+func (wm *WorktreeManager) forgetBranch(taskID string) {
+	wm.branchMu.Lock()
+	defer wm.branchMu.Unlock()
+	delete(wm.branches, taskID)
+}
+
+// renderBranchName expands the branch template for a task
+func (wm *WorktreeManager) renderBranchName(task *types.Task, now time.Time) string {
+	template := wm.branchTemplate
+	if template == "" {
+		template = DefaultBranchTemplate
+	}
+
+	name := strings.NewReplacer(
+		"{prefix}", wm.prefix(),
+		"{id}", task.ID,
+		"{epic}", task.EpicID,
+		"{slug}", Slugify(task.Title),
+		"{date}", now.Format("20060102"),
+	).Replace(template)
+
+	// Placeholders that expanded to nothing leave doubled separators behind
+	name = branchRepeats.ReplaceAllString(name, "$1")
+	name = strings.Trim(name, "-/_.")
+
+	if name == "" || !validBranchName(name) {
+		return fmt.Sprintf("%s-%s", wm.prefix(), task.ID)
+	}
+	return name
+}
+
+// Slugify converts a title into a lowercase, dash-separated branch fragment
+func Slugify(title string) string {
+	slug := slugInvalidChars.ReplaceAllString(strings.ToLower(title), "-")
+	slug = strings.Trim(slug, "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
+
+// validBranchName asks git whether name is a legal branch name
+func validBranchName(name string) bool {
+	cmd := exec.Command("git", "check-ref-format", "--branch", name)
+	return cmd.Run() == nil
+}
+
+// branchExists reports whether a local or remote-tracking branch with this name exists
+func (wm *WorktreeManager) branchExists(name string) bool {
+	cmd := exec.Command("git", "for-each-ref", "--format=%(refname)",
+		"refs/heads/"+name, "refs/remotes/*/"+name)
+	cmd.Dir = wm.baseDir
+	output, err := cmd.Output()
+	return err == nil && strings.TrimSpace(string(output)) != ""
+}
+
+// resolveBranchName picks a collision-free branch name for a task
+// Templates containing {id} are unique per task, so an existing branch can only
+// be this task's leftover from an interrupted run and is reused (deleted by the
+// caller); otherwise a numeric suffix is appended until the name is free
+func (wm *WorktreeManager) resolveBranchName(task *types.Task) (string, error) {
+	name := wm.renderBranchName(task, time.Now())
+	if strings.Contains(wm.branchTemplate, "{id}") || wm.branchTemplate == "" {
+		return name, nil
+	}
+
+	if !wm.branchExists(name) {
+		return name, nil
+	}
+	for seq := 2; seq <= maxBranchSequence; seq++ {
+		candidate := fmt.Sprintf("%s-%d", name, seq)
+		if !wm.branchExists(candidate) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free branch name for %q after %d attempts", name, maxBranchSequence)
+}
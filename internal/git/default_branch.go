@@ -0,0 +1,90 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// conventionalBranches are tried in order when origin/HEAD is not set and the
+// repository has more than one local branch
+var conventionalBranches = []string{"main", "master", "trunk", "develop"}
+
+// DetectDefaultBranch determines the branch task work should merge into
+// It prefers origin/HEAD, then the only local branch, then a conventional name
+// that exists locally. Task branches under branchPrefix ("" = drover) are
+// never picked
+func DetectDefaultBranch(repoDir, branchPrefix string) (string, error) {
+	if branchPrefix == "" {
+		branchPrefix = "drover"
+	}
+	cmd := exec.Command("git", "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD")
+	cmd.Dir = repoDir
+	if output, err := cmd.Output(); err == nil {
+		name := strings.TrimPrefix(strings.TrimSpace(string(output)), "origin/")
+		if name != "" && localBranchExists(repoDir, name) {
+			return name, nil
+		}
+	}
+
+	cmd = exec.Command("git", "for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("listing branches: %w", err)
+	}
+	var branches []string
+	for _, b := range strings.Fields(string(output)) {
+		// Ignore drover's own task branches
+		if !strings.HasPrefix(b, branchPrefix+"-") && !strings.HasPrefix(b, branchPrefix+"/") {
+			branches = append(branches, b)
+		}
+	}
+	if len(branches) == 1 {
+		return branches[0], nil
+	}
+
+	for _, name := range conventionalBranches {
+		for _, b := range branches {
+			if b == name {
+				return name, nil
+			}
+		}
+	}
+
+	if len(branches) == 0 {
+		return "", fmt.Errorf("repository has no branches")
+	}
+	return "", fmt.Errorf("cannot determine default branch among %s; set target_branch", strings.Join(branches, ", "))
+}
+
+// ResolveTargetBranch returns the merge target: override if set (and valid), otherwise the detected default branch
+func ResolveTargetBranch(repoDir, override, branchPrefix string) (string, error) {
+	if override != "" {
+		if !localBranchExists(repoDir, override) {
+			return "", fmt.Errorf("target branch %q does not exist", override)
+		}
+		return override, nil
+	}
+	return DetectDefaultBranch(repoDir, branchPrefix)
+}
+
+// localBranchExists reports whether refs/heads/name exists
+func localBranchExists(repoDir, name string) bool {
+	cmd := exec.Command("git", "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	cmd.Dir = repoDir
+	return cmd.Run() == nil
+}
+
+// SetTargetBranch sets the branch task work is merged into
+func (wm *WorktreeManager) SetTargetBranch(name string) {
+	wm.targetBranch = name
+}
+
+// TargetBranch returns the branch task work is merged into ("main" unless configured)
+func (wm *WorktreeManager) TargetBranch() string {
+	if wm.targetBranch == "" {
+		return "main"
+	}
+	return wm.targetBranch
+}
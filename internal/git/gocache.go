@@ -0,0 +1,193 @@
+package git
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Go build cache modes
+const (
+	// GoCacheGlobal shares one GOCACHE across all worktrees
+	GoCacheGlobal = "global"
+	// GoCacheLockHash keys GOCACHE by the worktree's go.sum hash so dependency
+	// upgrades start from a fresh cache instead of growing the shared one
+	GoCacheLockHash = "lockhash"
+)
+
+// defaultGoCacheCleanInterval is how often the build cache policy is evaluated
+const defaultGoCacheCleanInterval = time.Hour
+
+// goCacheUsedMarker is touched in a GOCACHE each time it's handed out; a
+// directory's own modification time only changes when an entry is added to
+// or removed from it, which a cache serving hits doesn't do
+const goCacheUsedMarker = ".drover-last-used"
+
+// initGoBuildCache prepares the shared GOCACHE root under the pool cache directory
+func (p *WorktreePool) initGoBuildCache(cacheDir string) error {
+	if !p.config.GoBuildCache {
+		return nil
+	}
+	p.sharedGoCache = filepath.Join(cacheDir, "gocache")
+	if err := os.MkdirAll(p.sharedGoCache, 0755); err != nil {
+		return fmt.Errorf("creating shared GOCACHE: %w", err)
+	}
+	p.lastGoCacheClean = time.Now()
+	return nil
+}
+
+// goCacheDir returns the GOCACHE directory commands in worktreePath should use
+func (p *WorktreePool) goCacheDir(worktreePath string) string {
+	if p.sharedGoCache == "" {
+		return ""
+	}
+	if p.config.GoBuildCacheMode != GoCacheLockHash {
+		return p.sharedGoCache
+	}
+
+	hash, err := p.computeFileHash(filepath.Join(worktreePath, "go.sum"))
+	if err != nil || hash == "" {
+		return filepath.Join(p.sharedGoCache, "nosum")
+	}
+	return filepath.Join(p.sharedGoCache, hash[:12])
+}
+
+// GoCacheEnv returns the environment entries that point Go builds and tests run
+// in worktreePath at the shared build cache. It is applied per command rather
+// than process-wide so lock-hash caches can differ between worktrees
+func (p *WorktreePool) GoCacheEnv(worktreePath string) []string {
+	dir := p.goCacheDir(worktreePath)
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("⚠️  Failed to create GOCACHE %s: %v", dir, err)
+		return nil
+	}
+	touchGoCache(dir)
+	return []string{"GOCACHE=" + dir}
+}
+
+// GetSharedGoCachePath returns the root of the shared Go build cache
+func (p *WorktreePool) GetSharedGoCachePath() string {
+	return p.sharedGoCache
+}
+
+// maintainGoBuildCache applies the clean policy once per GoBuildCacheCleanInterval:
+// lock-hash caches that no longer match the base repo and saw no use during the
+// last interval are removed, and any cache above GoBuildCacheMaxMB is cleared
+// with `go clean -cache`
+func (p *WorktreePool) maintainGoBuildCache() {
+	if p.sharedGoCache == "" {
+		return
+	}
+	interval := p.config.GoBuildCacheCleanInterval
+	if interval <= 0 {
+		interval = defaultGoCacheCleanInterval
+	}
+	if time.Since(p.lastGoCacheClean) < interval {
+		return
+	}
+	p.lastGoCacheClean = time.Now()
+
+	dirs := []string{p.sharedGoCache}
+	if p.config.GoBuildCacheMode == GoCacheLockHash {
+		dirs = nil
+		current := p.goCacheDir(p.manager.baseDir)
+		entries, err := os.ReadDir(p.sharedGoCache)
+		if err != nil {
+			log.Printf("⚠️  Failed to list GOCACHE directories: %v", err)
+			return
+		}
+		for _, e := range entries {
+			if !e.IsDir() {
+				continue
+			}
+			dir := filepath.Join(p.sharedGoCache, e.Name())
+			lastUsed, err := goCacheLastUsed(dir)
+			if dir != current && err == nil && time.Since(lastUsed) > interval {
+				if err := os.RemoveAll(dir); err != nil {
+					log.Printf("⚠️  Failed to remove stale GOCACHE %s: %v", dir, err)
+				} else {
+					log.Printf("🧹 Removed stale Go build cache %s", e.Name())
+				}
+				continue
+			}
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if p.config.GoBuildCacheMaxMB <= 0 {
+		return
+	}
+	for _, dir := range dirs {
+		size, err := dirSize(dir)
+		if err != nil {
+			continue
+		}
+		if size/(1024*1024) < p.config.GoBuildCacheMaxMB {
+			continue
+		}
+		if err := cleanGoCache(dir); err != nil {
+			log.Printf("⚠️  Failed to clean Go build cache %s: %v", dir, err)
+			continue
+		}
+		log.Printf("🧹 Cleaned Go build cache %s (%d MB over %d MB cap)", dir, size/(1024*1024), p.config.GoBuildCacheMaxMB)
+	}
+}
+
+// touchGoCache records that a GOCACHE was just handed out
+func touchGoCache(dir string) {
+	marker := filepath.Join(dir, goCacheUsedMarker)
+	now := time.Now()
+	if err := os.Chtimes(marker, now, now); err == nil {
+		return
+	}
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		log.Printf("⚠️  Failed to mark GOCACHE %s used: %v", dir, err)
+	}
+}
+
+// goCacheLastUsed returns when a GOCACHE was last handed out, or for one
+// never marked, when it was last changed
+func goCacheLastUsed(dir string) (time.Time, error) {
+	info, err := os.Stat(filepath.Join(dir, goCacheUsedMarker))
+	if err != nil {
+		info, err = os.Stat(dir)
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return info.ModTime(), nil
+}
+
+// cleanGoCache runs `go clean -cache` against a specific GOCACHE
+func cleanGoCache(dir string) error {
+	cmd := exec.Command("go", "clean", "-cache")
+	cmd.Env = append(os.Environ(), "GOCACHE="+dir)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w\n%s", err, output)
+	}
+	return nil
+}
+
+// dirSize returns the total size of regular files under dir
+func dirSize(dir string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Entries can vanish while go trims the cache
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,185 @@
+package git
+
+import (
+	"context"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// localityWindow is how long a touched directory counts towards locality
+const localityWindow = 2 * time.Hour
+
+// maxRecentPaths bounds the per-worktree locality set
+const maxRecentPaths = 256
+
+// pathToken matches repo-relative path-like words in task text (e.g. internal/git/pool.go)
+var (
+	pathToken = regexp.MustCompile(`[A-Za-z0-9_.\-]+(?:/[A-Za-z0-9_.\-]+)+/?`)
+	urlToken  = regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.\-]*://\S+`)
+)
+
+// epicAffinityWeight is what a worktree gains for having last run a task of
+// the same epic: as much as sharing a few directories, since related tasks
+// tend to build the same packages
+const epicAffinityWeight = 3
+
+// AffinityHints describe a task to the pool, so it can hand over the warm
+// worktree whose build caches suit the task best
+type AffinityHints struct {
+	EpicID string   // Worktrees that last ran a task of this epic are preferred
+	Paths  []string // Repo-relative paths the task mentions
+}
+
+// TaskAffinity returns the affinity hints for a task
+func TaskAffinity(epicID, title, description string) AffinityHints {
+	return AffinityHints{EpicID: epicID, Paths: TaskPaths(title, description)}
+}
+
+// TaskPaths extracts the repo-relative paths a task mentions in its title or description
+// Only tokens containing a slash are considered so prose doesn't produce false matches
+func TaskPaths(title, description string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, text := range []string{title, description} {
+		text = urlToken.ReplaceAllString(text, " ")
+		for _, token := range pathToken.FindAllString(text, -1) {
+			token = strings.Trim(strings.TrimPrefix(token, "./"), "/.")
+			if token == "" || seen[token] {
+				continue
+			}
+			seen[token] = true
+			paths = append(paths, token)
+		}
+	}
+	return paths
+}
+
+// localityDirs returns the directories covering the given files and all their ancestors
+// A file path contributes its parent directory; paths without an extension are
+// treated as directories themselves
+func localityDirs(files []string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, f := range files {
+		dir := f
+		if path.Ext(f) != "" {
+			dir = path.Dir(f)
+		}
+		for dir != "" && dir != "." && dir != "/" {
+			if seen[dir] {
+				break
+			}
+			seen[dir] = true
+			dirs = append(dirs, dir)
+			dir = path.Dir(dir)
+		}
+	}
+	return dirs
+}
+
+// localityScore rates how much of the task's directory set a worktree has recently built
+// Deeper directories weigh more, so a worktree that touched internal/git beats one
+// that only shares the top-level internal/ directory
+func (wt *PooledWorktree) localityScore(taskDirs []string, now time.Time) int {
+	score := 0
+	for _, dir := range taskDirs {
+		if touched, ok := wt.RecentPaths[dir]; ok && now.Sub(touched) <= localityWindow {
+			score += strings.Count(dir, "/") + 1
+		}
+	}
+	return score
+}
+
+// affinityScore rates how well a worktree suits a task: its locality score,
+// plus a bonus when its last task, released within the locality window, was
+// of the task's epic
+func (wt *PooledWorktree) affinityScore(epicID string, taskDirs []string, now time.Time) int {
+	score := wt.localityScore(taskDirs, now)
+	if epicID != "" && wt.LastEpicID == epicID && now.Sub(wt.LastEpicAt) <= localityWindow {
+		score += epicAffinityWeight
+	}
+	return score
+}
+
+// recordPaths adds the directories covering files to the worktree's locality set
+// Caller must hold wt.mu
+func (wt *PooledWorktree) recordPaths(files []string, now time.Time) {
+	if wt.RecentPaths == nil {
+		wt.RecentPaths = make(map[string]time.Time)
+	}
+	for _, dir := range localityDirs(files) {
+		wt.RecentPaths[dir] = now
+	}
+
+	// Expire stale entries, then trim the oldest if still over the limit
+	for dir, touched := range wt.RecentPaths {
+		if now.Sub(touched) > localityWindow {
+			delete(wt.RecentPaths, dir)
+		}
+	}
+	for len(wt.RecentPaths) > maxRecentPaths {
+		var oldest string
+		var oldestAt time.Time
+		for dir, touched := range wt.RecentPaths {
+			if oldest == "" || touched.Before(oldestAt) {
+				oldest, oldestAt = dir, touched
+			}
+		}
+		delete(wt.RecentPaths, oldest)
+	}
+}
+
+// headCommit returns the commit checked out in a worktree, or "" if unknown
+func headCommit(worktreePath string) string {
+	cmd := worktreeGit(context.Background(), worktreePath, "rev-parse", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// changedFiles lists files a task touched in a worktree since baseCommit,
+// including uncommitted changes
+func changedFiles(worktreePath, baseCommit string) []string {
+	var files []string
+	if baseCommit != "" {
+		cmd := worktreeGit(context.Background(), worktreePath, "diff", "--name-only", "--no-renames", "-z", baseCommit+"..HEAD")
+		if output, err := cmd.Output(); err == nil {
+			files = append(files, splitNUL(output)...)
+		}
+	}
+
+	cmd := worktreeGit(context.Background(), worktreePath, "status", "--porcelain", "--no-renames", "--untracked-files=all", "-z")
+	if output, err := cmd.Output(); err == nil {
+		for _, entry := range splitNUL(output) {
+			if len(entry) > 3 {
+				files = append(files, entry[3:])
+			}
+		}
+	}
+	return files
+}
+
+// RecentPaths returns the directories a pooled worktree has recently built or tested
+func (p *WorktreePool) RecentPaths(worktreeID string) []string {
+	p.mu.RLock()
+	wt, exists := p.worktrees[worktreeID]
+	p.mu.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	now := time.Now()
+	var dirs []string
+	for dir, touched := range wt.RecentPaths {
+		if now.Sub(touched) <= localityWindow {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
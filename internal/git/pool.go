@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/cloud-shuttle/drover/pkg/telemetry"
+	"github.com/cloud-shuttle/drover/pkg/types"
 )
 
 // WorktreeState represents the current state of a worktree in the pool
@@ -67,6 +68,14 @@ type PooledWorktree struct {
 	LastFetchStatus   string        // Status of last fetch ("", "ok", "error")
 	LastFetchError    string        // Error message if fetch failed
 	IsReadOnly        bool          // True when sync is in progress
+	SyncedAt          time.Time     // When the checkout was last brought up to date with the base branch
+	// Claim locality: directories recently built/tested in this worktree
+	RecentPaths       map[string]time.Time // directory -> last touched
+	baseCommit        string               // HEAD when the current task was assigned
+	// Epic affinity: the epic of the last task released from this worktree
+	LastEpicID        string
+	LastEpicAt        time.Time
+	epicID            string // Epic of the current task
 }
 
 // PoolConfig holds configuration for the worktree pool
@@ -79,6 +88,22 @@ type PoolConfig struct {
 	EnableSymlinks  bool          // Enable shared node_modules via symlinks
 	GoModCache      bool          // Enable Go module cache sharing
 	CargoTargetDir  bool          // Enable shared Cargo target directory for Rust projects
+	// Go build/test cache sharing (GOCACHE), applied per executed command
+	GoBuildCache              bool          // Enable a shared GOCACHE for worktree commands
+	GoBuildCacheMode          string        // "global" (default) or "lockhash" (one cache per go.sum)
+	GoBuildCacheMaxMB         int64         // Run `go clean -cache` when a cache exceeds this size (0 = no cap)
+	GoBuildCacheCleanInterval time.Duration // How often the clean policy runs (default 1h)
+	// Scheduled background fetch and staleness policy
+	FetchInterval  time.Duration // How often to fetch all worktrees (0 disables the scheduler)
+	FetchJitter    time.Duration // Random extra delay added to each fetch round
+	FetchSkipInUse bool          // Don't fetch worktrees assigned to a running task
+	StaleAfter     time.Duration // Warm worktrees older than this are refreshed before acquisition (0 disables)
+	StalePolicy    string        // "rebase" (default) or "recycle"
+	// Adaptive sizing from the measured per-worktree footprint
+	AdaptiveMaxSize bool          // Lower MaxSize to what the host's free disk and memory can hold
+	DiskReserveMB   int64         // Free disk to leave untouched on the worktree filesystem
+	MemoryReserveMB int64         // Available memory to leave untouched
+	SizingInterval  time.Duration // How often the adaptive MaxSize is re-evaluated (default 30s)
 }
 
 // DefaultPoolConfig returns sensible defaults for the pool
@@ -92,6 +117,13 @@ func DefaultPoolConfig() *PoolConfig {
 		EnableSymlinks:     true,
 		GoModCache:         true,
 		CargoTargetDir:     true,
+		GoBuildCache:       true,
+		GoBuildCacheMode:   GoCacheGlobal,
+		FetchSkipInUse:     true,
+		StalePolicy:        StalePolicyRebase,
+		AdaptiveMaxSize:    true,
+		DiskReserveMB:      2048,
+		MemoryReserveMB:    1024,
 	}
 }
 
@@ -112,6 +144,20 @@ type WorktreePool struct {
 	sharedNodeModules  string // Path to shared node_modules
 	sharedGoModCache   string // Path to Go module cache (GOMODCACHE)
 	sharedCargoTarget  string // Path to shared Cargo target directory
+	sharedGoCache      string // Root of shared Go build cache (GOCACHE)
+	lastGoCacheClean   time.Time
+
+	// Adaptive MaxSize state
+	sizingMu   sync.Mutex        // Protects footprint
+	footprint  worktreeFootprint // Largest per-worktree cost measured after warmup
+	sizedMax   int               // Adaptive limit, valid once sized (protected by mu)
+	sized      bool
+	lastSizing time.Time
+
+	idle bool // Scaled down while there is nothing to run: no warm worktrees are kept (protected by mu)
+
+	workers  func() int      // The run's worker count, which adaptive sizing never goes below (protected by mu)
+	overflow map[string]bool // Tasks given a worktree outside the pool while it was full (protected by mu)
 }
 
 // NewWorktreePool creates a new worktree pool
@@ -126,6 +172,7 @@ func NewWorktreePool(manager *WorktreeManager, config *PoolConfig) *WorktreePool
 		manager:    manager,
 		config:     config,
 		worktrees:  make(map[string]*PooledWorktree),
+		overflow:   make(map[string]bool),
 		ctx:        ctx,
 		cancel:     cancel,
 		shutdownCh: make(chan struct{}),
@@ -161,6 +208,12 @@ func (p *WorktreePool) Start() error {
 		p.wg.Add(1)
 		go p.replenishLoop()
 
+		// Start the scheduled fetch loop
+		if p.config.FetchInterval > 0 {
+			p.wg.Add(1)
+			go p.fetchLoop()
+		}
+
 		// Initial warmup
 		if err := p.ensureMinWarmWorktrees(p.ctx); err != nil {
 			startErr = fmt.Errorf("initial warmup: %w", err)
@@ -213,82 +266,218 @@ func (p *WorktreePool) Stop() error {
 // Acquire acquires a warm worktree from the pool for a task
 // Returns the worktree path, or an error if no worktree is available
 func (p *WorktreePool) Acquire(taskID string) (string, error) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	return p.AcquireWithAffinity(taskID, AffinityHints{})
+}
 
-	// Find a warm worktree that's not in use and not in read-only mode
-	for _, wt := range p.worktrees {
-		wt.mu.Lock()
-		// Skip worktrees that are syncing (read-only mode)
-		if wt.IsReadOnly {
-			wt.mu.Unlock()
-			continue
+// AcquireForPaths acquires a warm worktree for a task, preferring the one whose
+// recently built/tested directories overlap the task's paths so incremental
+// build caches are reused. With no paths any available worktree is taken
+func (p *WorktreePool) AcquireForPaths(taskID string, paths []string) (string, error) {
+	return p.AcquireWithAffinity(taskID, AffinityHints{Paths: paths})
+}
+
+// AcquireWithAffinity acquires a warm worktree for a task, preferring the one
+// that scores best on the task's hints: recently built/tested directories
+// overlapping its paths, and a last task of the same epic. Without hints any
+// available worktree is taken
+func (p *WorktreePool) AcquireWithAffinity(taskID string, hints AffinityHints) (string, error) {
+	// Workers queue here for the pool lock and, when nothing is warm, for a new worktree
+	defer p.manager.observeWait(p.ctx, WaitPoolAcquire, time.Now())
+
+	taskDirs := localityDirs(hints.Paths)
+	for {
+		best, bestScore, stale, held := p.pick(taskID, hints.EpicID, taskDirs)
+		if held != nil {
+			log.Printf("♻️  Reusing worktree %s held by paused task %s", held.ID, taskID)
+			return held.Path, nil
+		}
+		if best == nil {
+			break
 		}
-		if wt.State == StateWarm && wt.TaskID == "" {
-			// Found a warm, available worktree
-			wt.State = StateInUse
-			wt.TaskID = taskID
-			wt.AssignedAt = time.Now()
-			wt.mu.Unlock()
 
-			log.Printf("🎯 Acquired worktree %s for task %s", wt.ID, taskID)
-			return wt.Path, nil
+		// Git runs with the worktree marked in use, not under the pool lock.
+		// A stale worktree that can't be refreshed is recycled; try another
+		if stale && !p.refreshStale(best) {
+			continue
 		}
-		wt.mu.Unlock()
+		p.hand(taskID, best, hints.EpicID)
+		if bestScore > 0 {
+			log.Printf("🎯 Acquired worktree %s for task %s (affinity score %d)", best.ID, taskID, bestScore)
+		} else {
+			log.Printf("🎯 Acquired worktree %s for task %s", best.ID, taskID)
+		}
+		return best.Path, nil
 	}
 
 	// No warm worktrees available, check if we can create a new one
-	if len(p.worktrees) < p.config.MaxSize {
-		p.mu.Unlock()
-		// Create and warm a new worktree
+	p.mu.RLock()
+	room := len(p.worktrees) < p.maxSize()
+	p.mu.RUnlock()
+	if room {
 		if err := p.createAndWarmWorktree(taskID); err != nil {
-			p.mu.Lock()
 			return "", fmt.Errorf("creating warm worktree: %w", err)
 		}
-		p.mu.Lock()
-
-		// Find the newly created worktree
-		for _, wt := range p.worktrees {
-			if wt.TaskID == taskID {
-				log.Printf("🎯 Created and acquired worktree %s for task %s", wt.ID, taskID)
-				return wt.Path, nil
-			}
+		p.mu.RLock()
+		wt := p.worktrees[taskID]
+		p.mu.RUnlock()
+		if wt != nil {
+			p.hand(taskID, wt, hints.EpicID)
+			log.Printf("🎯 Created and acquired worktree %s for task %s", wt.ID, taskID)
+			return wt.Path, nil
 		}
 	}
 
-	return "", fmt.Errorf("no warm worktrees available (pool size: %d/%d)", p.countByState(StateWarm), p.config.MaxSize)
+	// The pool is full: rather than fail the task, give it a worktree of its
+	// own outside the pool, removed again on release
+	p.mu.RLock()
+	warm, limit := p.countByState(StateWarm), p.maxSize()
+	p.mu.RUnlock()
+	log.Printf("⚠️  No warm worktrees available (pool size: %d/%d), creating one outside the pool for task %s", warm, limit, taskID)
+	path, err := p.manager.CreateWithContext(p.ctx, &types.Task{ID: taskID})
+	if err != nil {
+		return "", fmt.Errorf("creating worktree outside the full pool: %w", err)
+	}
+	p.mu.Lock()
+	p.overflow[taskID] = true
+	p.mu.Unlock()
+	return path, nil
 }
 
-// Release releases a worktree back to the pool after task completion
-func (p *WorktreePool) Release(taskID string, retain bool) error {
+// pick chooses the warm worktree that's not in use, not in read-only mode,
+// and has the best affinity score, and marks it in use by the task so no one
+// else takes it once the pool lock is released. It reports whether the
+// worktree is stale, and returns the worktree a paused task still holds
+// instead, if any
+func (p *WorktreePool) pick(taskID, epicID string, taskDirs []string) (best *PooledWorktree, bestScore int, stale bool, held *PooledWorktree) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// Find the worktree assigned to this task
+	// A task parked by a pause still holds its worktree; hand it back as it was
 	for _, wt := range p.worktrees {
 		wt.mu.Lock()
-		if wt.TaskID == taskID && wt.State == StateInUse {
-			wt.TaskID = ""
-			wt.AssignedAt = time.Time{}
-
-			if retain {
-				// Return to pool as warm
-				wt.State = StateWarm
-				wt.WarmedAt = time.Now()
-				wt.mu.Unlock()
-				log.Printf("↩️  Released worktree %s back to pool (warm)", wt.ID)
-			} else {
-				// Mark for draining - will be removed by replenish loop
-				wt.State = StateDraining
-				wt.mu.Unlock()
-				log.Printf("🗑️  Released worktree %s for cleanup", wt.ID)
+		holds := wt.State == StateInUse && wt.TaskID == taskID
+		wt.mu.Unlock()
+		if holds {
+			return nil, 0, false, wt
+		}
+	}
+
+	now := time.Now()
+	bestScore = -1
+	for _, wt := range p.worktrees {
+		wt.mu.Lock()
+		// Skip worktrees that are syncing (read-only mode)
+		if !wt.IsReadOnly && wt.State == StateWarm && wt.TaskID == "" {
+			score := wt.affinityScore(epicID, taskDirs, now)
+			if score > bestScore {
+				best, bestScore = wt, score
 			}
-			return nil
 		}
 		wt.mu.Unlock()
 	}
+	if best == nil {
+		return nil, 0, false, nil
+	}
+
+	best.mu.Lock()
+	stale = p.isStale(best, now)
+	best.State = StateInUse
+	best.TaskID = taskID
+	best.AssignedAt = now
+	best.epicID = epicID
+	best.mu.Unlock()
+	return best, bestScore, stale, nil
+}
+
+// hand lends a worktree picked for a task to it, recording the commit it
+// starts from so Release can tell what the task touched
+func (p *WorktreePool) hand(taskID string, wt *PooledWorktree, epicID string) {
+	base := headCommit(wt.Path)
+	wt.mu.Lock()
+	wt.baseCommit = base
+	wt.epicID = epicID
+	path, branch, setup := wt.Path, wt.Branch, wt.WarmedAt.Sub(wt.CreatedAt)
+	wt.mu.Unlock()
+	p.manager.lend(taskID, path, branch)
+	p.manager.recordCreated(taskID, path, branch, setup)
+}
+
+// FollowWorkers has adaptive sizing keep room for the run's worker count
+// as workers reports it, so every worker can hold a pooled worktree
+func (p *WorktreePool) FollowWorkers(workers func() int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.workers = workers
+}
+
+// Release releases a worktree back to the pool after task completion. With
+// retain the worktree is reset to the target branch, which holds the task's
+// merged work, and goes back to the pool warm, build caches and all;
+// otherwise it is drained
+func (p *WorktreePool) Release(taskID string, retain bool) error {
+	p.mu.Lock()
+	if p.overflow[taskID] {
+		delete(p.overflow, taskID)
+		p.mu.Unlock()
+		return p.manager.Remove(taskID)
+	}
+
+	// Find the worktree assigned to this task. It stays in use, so no one
+	// takes it while git runs below without the pool lock
+	var wt *PooledWorktree
+	var baseCommit, epicID string
+	for _, candidate := range p.worktrees {
+		candidate.mu.Lock()
+		if candidate.TaskID == taskID && candidate.State == StateInUse {
+			wt = candidate
+			candidate.TaskID = ""
+			candidate.AssignedAt = time.Time{}
+			baseCommit, epicID = candidate.baseCommit, candidate.epicID
+			candidate.baseCommit, candidate.epicID = "", ""
+		}
+		candidate.mu.Unlock()
+		if wt != nil {
+			break
+		}
+	}
+	p.mu.Unlock()
+	if wt == nil {
+		return fmt.Errorf("worktree for task %s not found", taskID)
+	}
+
+	// Remember what the task built so later related tasks land here
+	changed := changedFiles(wt.Path, baseCommit)
+	p.manager.recordDiskUsage(taskID, wt.Path)
+	p.manager.unlend(taskID)
+	if retain {
+		if err := p.resetWorktree(wt.Path); err != nil {
+			log.Printf("⚠️  Failed to reset worktree %s for reuse, draining it: %v", wt.ID, err)
+			retain = false
+		}
+	}
 
-	return fmt.Errorf("worktree for task %s not found", taskID)
+	now := time.Now()
+	wt.mu.Lock()
+	wt.recordPaths(changed, now)
+	if epicID != "" {
+		wt.LastEpicID, wt.LastEpicAt = epicID, now
+	}
+	if retain {
+		// Return to pool as warm
+		wt.State = StateWarm
+		wt.WarmedAt = now
+		wt.SyncedAt = now // Reset to the target branch
+		wt.mu.Unlock()
+		p.manager.recordStatus(taskID, WorktreeReleased)
+		log.Printf("↩️  Released worktree %s back to pool (warm)", wt.ID)
+	} else {
+		// Mark for draining - will be removed by replenish loop
+		wt.State = StateDraining
+		wt.mu.Unlock()
+		p.manager.recordRemoved(taskID)
+		log.Printf("🗑️  Released worktree %s for cleanup", wt.ID)
+	}
+	return nil
 }
 
 // Stats returns pool statistics
@@ -304,7 +493,7 @@ func (p *WorktreePool) Stats() PoolStats {
 		InUse:      p.countByState(StateInUse),
 		Draining:   p.countByState(StateDraining),
 		MinSize:    p.config.MinSize,
-		MaxSize:    p.config.MaxSize,
+		MaxSize:    p.maxSize(),
 	}
 }
 
@@ -317,7 +506,7 @@ type PoolStats struct {
 	InUse    int
 	Draining int
 	MinSize  int
-	MaxSize  int
+	MaxSize  int // Effective maximum, lowered by adaptive sizing
 }
 
 // FetchSyncResult represents the result of an async git fetch operation
@@ -337,7 +526,10 @@ func (p *WorktreePool) FetchAll() <-chan FetchSyncResult {
 	worktreeCount := len(p.worktrees)
 	worktreesCopy := make([]*PooledWorktree, 0, worktreeCount)
 	for _, wt := range p.worktrees {
-		if wt.Path != "" && wt.State != StateDraining {
+		wt.mu.Lock()
+		eligible := wt.Path != "" && wt.State != StateDraining
+		wt.mu.Unlock()
+		if eligible {
 			worktreesCopy = append(worktreesCopy, wt)
 		}
 	}
@@ -345,11 +537,17 @@ func (p *WorktreePool) FetchAll() <-chan FetchSyncResult {
 
 	resultCh := make(chan FetchSyncResult, worktreeCount)
 
+	// Track this batch separately: p.wg also covers the long-running pool loops,
+	// so waiting on it would hold the channel open until the pool stops
+	var batch sync.WaitGroup
+
 	// Launch fetch for each worktree in parallel
 	for _, wt := range worktreesCopy {
 		p.wg.Add(1)
+		batch.Add(1)
 		go func(wt *PooledWorktree) {
 			defer p.wg.Done()
+			defer batch.Done()
 			result := p.fetchWorktree(wt)
 			resultCh <- result
 		}(wt)
@@ -357,7 +555,7 @@ func (p *WorktreePool) FetchAll() <-chan FetchSyncResult {
 
 	// Close channel when all fetches complete
 	go func() {
-		p.wg.Wait()
+		batch.Wait()
 		close(resultCh)
 	}()
 
@@ -401,10 +599,27 @@ func (p *WorktreePool) fetchWorktree(wt *PooledWorktree) FetchSyncResult {
 		wt.mu.Unlock()
 	}()
 
-	// Perform git fetch in the worktree
-	cmd := exec.Command("git", "fetch", "origin")
-	cmd.Dir = wt.Path
+	// Perform git fetch in the worktree, bounded by the network timeout
+	ctx, cancel := p.manager.networkContext(p.ctx)
+	cmd := worktreeGit(ctx, wt.Path, "fetch", "origin")
 	output, err := cmd.CombinedOutput()
+	cancel()
+
+	// An idle worktree is brought up to date with the target branch while it
+	// is still read-only; one in use is left to its task
+	synced := false
+	if err == nil {
+		wt.mu.Lock()
+		idle := wt.State == StateWarm && wt.TaskID == ""
+		wt.mu.Unlock()
+		if idle {
+			if rebaseErr := p.rebaseWorktree(wt.Path); rebaseErr != nil {
+				log.Printf("⚠️  Failed to rebase worktree %s after fetching: %v", wt.ID, rebaseErr)
+			} else {
+				synced = true
+			}
+		}
+	}
 
 	wt.mu.Lock()
 	defer wt.mu.Unlock()
@@ -430,6 +645,9 @@ func (p *WorktreePool) fetchWorktree(wt *PooledWorktree) FetchSyncResult {
 	// Update success status
 	wt.LastFetchStatus = "ok"
 	wt.LastFetchError = ""
+	if synced {
+		wt.SyncedAt = time.Now()
+	}
 	duration := time.Since(startTime)
 
 	log.Printf("✅ Git fetch completed for worktree %s in %v", wt.ID, duration)
@@ -498,6 +716,9 @@ func (p *WorktreePool) replenishLoop() {
 				}
 			}
 
+			// Re-evaluate the adaptive MaxSize before topping up
+			p.resize()
+
 			// Ensure minimum warm worktrees
 			if err := p.ensureMinWarmWorktrees(p.ctx); err != nil {
 				log.Printf("⚠️  Failed to maintain pool: %v", err)
@@ -505,6 +726,9 @@ func (p *WorktreePool) replenishLoop() {
 
 			// Clean up draining worktrees
 			p.cleanupDrainingWorktrees()
+
+			// Apply the Go build cache size/staleness policy
+			p.maintainGoBuildCache()
 		}
 	}
 }
@@ -514,15 +738,19 @@ func (p *WorktreePool) ensureMinWarmWorktrees(ctx context.Context) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	minSize := p.config.MinSize
+	if p.idle {
+		minSize = 0
+	}
 	warmCount := p.countByState(StateWarm)
-	if warmCount >= p.config.MinSize {
+	if warmCount >= minSize {
 		return nil
 	}
 
 	// Need to create more warm worktrees
-	needed := p.config.MinSize - warmCount
+	needed := minSize - warmCount
 	for i := 0; i < needed; i++ {
-		if len(p.worktrees) >= p.config.MaxSize {
+		if len(p.worktrees) >= p.maxSize() {
 			break
 		}
 
@@ -548,11 +776,37 @@ func (p *WorktreePool) ensureMinWarmWorktrees(ctx context.Context) error {
 	return nil
 }
 
+// SetIdle scales the pool down while there is nothing to run: idle warm
+// worktrees are drained and none are warmed until SetIdle(false) brings the
+// pool back up to MinSize. Worktrees in use are left alone, and a task
+// acquiring one meanwhile still gets one
+func (p *WorktreePool) SetIdle(idle bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.idle == idle {
+		return
+	}
+	p.idle = idle
+	if !idle {
+		return // The replenish loop warms MinSize worktrees again
+	}
+	for _, wt := range p.worktrees {
+		wt.mu.Lock()
+		if wt.State == StateWarm && wt.TaskID == "" {
+			wt.State = StateDraining
+		}
+		wt.mu.Unlock()
+	}
+}
+
 // createAndWarmWorktree creates a worktree specifically for a task and warms it up
 func (p *WorktreePool) createAndWarmWorktree(taskID string) error {
 	// Create worktree path using task ID
 	worktreePath := filepath.Join(p.manager.worktreeDir, taskID)
-	branchName := fmt.Sprintf("drover-%s", taskID)
+	branchName, err := p.manager.resolveBranchName(&types.Task{ID: taskID})
+	if err != nil {
+		return err
+	}
 
 	// Ensure directory exists
 	if err := os.MkdirAll(p.manager.worktreeDir, 0755); err != nil {
@@ -560,15 +814,15 @@ func (p *WorktreePool) createAndWarmWorktree(taskID string) error {
 	}
 
 	// Clean up any existing worktree
-	p.manager.cleanUpWorktree(taskID)
+	p.manager.cleanUpWorktree(p.ctx, taskID)
 
 	// Delete the branch if it exists
-	cmd := exec.Command("git", "branch", "-D", branchName)
+	cmd := exec.CommandContext(p.ctx, "git", "branch", "-D", branchName)
 	cmd.Dir = p.manager.baseDir
 	_, _ = cmd.CombinedOutput() // Ignore errors - branch may not exist
 
 	// Create the worktree
-	cmd = exec.Command("git", "worktree", "add", "-b", branchName, worktreePath)
+	cmd = exec.CommandContext(p.ctx, "git", "worktree", "add", "-b", branchName, worktreePath)
 	cmd.Dir = p.manager.baseDir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -586,6 +840,7 @@ func (p *WorktreePool) createAndWarmWorktree(taskID string) error {
 		CreatedAt: time.Now(),
 		WarmedAt:  time.Now(),
 		AssignedAt: time.Now(),
+		SyncedAt:  time.Now(),
 	}
 	p.worktrees[wt.ID] = wt
 	p.mu.Unlock()
@@ -594,6 +849,7 @@ func (p *WorktreePool) createAndWarmWorktree(taskID string) error {
 	if err := p.setupDependencies(worktreePath); err != nil {
 		log.Printf("⚠️  Failed to setup dependencies for worktree %s: %v", wt.ID, err)
 	}
+	p.recordFootprint(worktreePath, peakRSS(cmd.ProcessState))
 
 	return nil
 }
@@ -606,7 +862,7 @@ func (p *WorktreePool) warmupWorktree(ctx context.Context, wt *PooledWorktree) {
 
 	// Create a temporary worktree path
 	worktreePath := filepath.Join(p.manager.worktreeDir, wt.ID)
-	branchName := fmt.Sprintf("drover-%s", wt.ID)
+	branchName := p.manager.pooledBranchName(wt.ID)
 
 	// Ensure directory exists
 	if err := os.MkdirAll(p.manager.worktreeDir, 0755); err != nil {
@@ -618,10 +874,10 @@ func (p *WorktreePool) warmupWorktree(ctx context.Context, wt *PooledWorktree) {
 	}
 
 	// Clean up any existing worktree
-	p.manager.cleanUpWorktree(wt.ID)
+	p.manager.cleanUpWorktree(ctx, wt.ID)
 
 	// Create the worktree
-	cmd := exec.Command("git", "worktree", "add", "-b", branchName, worktreePath)
+	cmd := exec.CommandContext(ctx, "git", "worktree", "add", "-b", branchName, worktreePath)
 	cmd.Dir = p.manager.baseDir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -645,8 +901,11 @@ func (p *WorktreePool) warmupWorktree(ctx context.Context, wt *PooledWorktree) {
 	wt.mu.Lock()
 	wt.State = StateWarm
 	wt.WarmedAt = time.Now()
+	wt.SyncedAt = wt.WarmedAt
 	wt.mu.Unlock()
 
+	p.recordFootprint(worktreePath, peakRSS(cmd.ProcessState))
+
 	log.Printf("✅ Worktree %s is warm and ready", wt.ID)
 }
 
@@ -710,6 +969,11 @@ func (p *WorktreePool) initDependencyCaches() error {
 		}
 	}
 
+	// Set up shared Go build cache (applied per command via GoCacheEnv)
+	if err := p.initGoBuildCache(cacheDir); err != nil {
+		return err
+	}
+
 	log.Printf("📦 Dependency caches initialized (node_modules: %s, gomodcache: %s, cargo_target: %s)",
 		p.sharedNodeModules, p.sharedGoModCache, p.sharedCargoTarget)
 
@@ -0,0 +1,157 @@
+package git
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/memory"
+)
+
+// defaultPoolSizingInterval is how often the adaptive MaxSize is re-evaluated
+const defaultPoolSizingInterval = 30 * time.Second
+
+// worktreeFootprint is the largest per-worktree cost observed after warmup
+type worktreeFootprint struct {
+	diskBytes int64 // Size of the checkout on disk
+	memBytes  int64 // Peak RSS of the warmup commands
+}
+
+// hostResources is what the host has left; negative values mean unknown
+type hostResources struct {
+	diskFree     int64
+	memAvailable int64
+}
+
+// recordFootprint folds a freshly warmed worktree into the footprint estimate
+// The largest observation wins so the pool errs on the side of fewer worktrees
+func (p *WorktreePool) recordFootprint(path string, peakRSS int64) {
+	if !p.config.AdaptiveMaxSize {
+		return
+	}
+	size, err := dirSize(path)
+	if err != nil {
+		return
+	}
+
+	p.sizingMu.Lock()
+	defer p.sizingMu.Unlock()
+	if size > p.footprint.diskBytes {
+		p.footprint.diskBytes = size
+	}
+	if peakRSS > p.footprint.memBytes {
+		p.footprint.memBytes = peakRSS
+	}
+}
+
+// maxSize returns the current upper bound on pooled worktrees: the configured
+// MaxSize, lowered by the adaptive limit once one has been computed
+// Caller must hold p.mu
+func (p *WorktreePool) maxSize() int {
+	if p.sized && p.sizedMax < p.config.MaxSize {
+		return p.sizedMax
+	}
+	return p.config.MaxSize
+}
+
+// resize re-evaluates the adaptive MaxSize against the host's free disk and
+// available memory, and drains idle warm worktrees when the pool is over it
+func (p *WorktreePool) resize() {
+	if !p.config.AdaptiveMaxSize {
+		return
+	}
+	interval := p.config.SizingInterval
+	if interval <= 0 {
+		interval = defaultPoolSizingInterval
+	}
+	if time.Since(p.lastSizing) < interval {
+		return
+	}
+
+	p.sizingMu.Lock()
+	fp := p.footprint
+	p.sizingMu.Unlock()
+	if fp.diskBytes == 0 {
+		return // Nothing warmed yet, keep the configured MaxSize
+	}
+	p.lastSizing = time.Now()
+
+	host := hostResources{diskFree: -1, memAvailable: -1}
+	if free, ok := freeDiskBytes(p.manager.worktreeDir); ok {
+		host.diskFree = free
+	}
+	if mem, err := memory.GetSystemMemory(); err == nil {
+		host.memAvailable = mem.AvailableMB << 20
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	owned := len(p.worktrees)
+	workers := 0
+	if p.workers != nil {
+		workers = p.workers()
+	}
+	limit, reason := adaptiveMaxSize(p.config.MaxSize, owned, workers, fp, host,
+		p.config.DiskReserveMB<<20, p.config.MemoryReserveMB<<20)
+	if !p.sized || limit != p.sizedMax {
+		log.Printf("📏 Worktree pool max size %d/%d (%s)", limit, p.config.MaxSize, reason)
+	}
+	p.sizedMax, p.sized = limit, true
+
+	// Shrink: idle warm worktrees go first, running tasks keep theirs
+	excess := owned - limit
+	for _, wt := range p.worktrees {
+		if excess <= 0 {
+			break
+		}
+		wt.mu.Lock()
+		if wt.State == StateWarm && wt.TaskID == "" {
+			wt.State = StateDraining
+			excess--
+		} else if wt.State == StateDraining {
+			excess--
+		}
+		wt.mu.Unlock()
+	}
+}
+
+// adaptiveMaxSize computes how many worktrees the host can hold: the ones the
+// pool already owns plus as many more as fit in the disk and memory left above
+// the reserves (fewer when the host is already below a reserve), capped at the
+// configured MaxSize. It never goes below the worker count, as a worker
+// without a worktree can't run its task. The reason names the binding
+// constraint
+func adaptiveMaxSize(configured, owned, workers int, fp worktreeFootprint, host hostResources, diskReserve, memReserve int64) (int, string) {
+	limit, reason := configured, "configured maximum"
+	if fp.diskBytes > 0 && host.diskFree >= 0 {
+		if n := owned + floorDiv(host.diskFree-diskReserve, fp.diskBytes); n < limit {
+			limit = n
+			reason = fmt.Sprintf("disk: %s free, %s per worktree", formatBytes(host.diskFree), formatBytes(fp.diskBytes))
+		}
+	}
+	if fp.memBytes > 0 && host.memAvailable >= 0 {
+		if n := owned + floorDiv(host.memAvailable-memReserve, fp.memBytes); n < limit {
+			limit = n
+			reason = fmt.Sprintf("memory: %s available, %s per worktree", formatBytes(host.memAvailable), formatBytes(fp.memBytes))
+		}
+	}
+	if floor := min(workers, configured); limit < floor {
+		limit = floor
+		reason += fmt.Sprintf(", kept at %d workers", floor)
+	}
+	if limit < 0 {
+		limit = 0
+	}
+	return limit, reason
+}
+
+// floorDiv divides rounding towards negative infinity, so a deficit of even a
+// fraction of a worktree counts as a whole one
+func floorDiv(a, b int64) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return int(q)
+}
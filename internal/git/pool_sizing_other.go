@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package git
+
+import "os"
+
+// freeDiskBytes is not measured on this platform, so disk never limits the pool
+func freeDiskBytes(path string) (int64, bool) {
+	return 0, false
+}
+
+// peakRSS is not measured on this platform, so memory never limits the pool
+func peakRSS(state *os.ProcessState) int64 {
+	return 0
+}
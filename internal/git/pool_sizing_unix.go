@@ -0,0 +1,34 @@
+//go:build linux || darwin
+
+package git
+
+import (
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// freeDiskBytes returns the space available to unprivileged users on the
+// filesystem holding path
+func freeDiskBytes(path string) (int64, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, false
+	}
+	return int64(st.Bavail) * int64(st.Bsize), true
+}
+
+// peakRSS returns the peak resident set size of a finished command in bytes
+func peakRSS(state *os.ProcessState) int64 {
+	if state == nil {
+		return 0
+	}
+	ru, ok := state.SysUsage().(*syscall.Rusage)
+	if !ok {
+		return 0
+	}
+	if runtime.GOOS == "darwin" {
+		return int64(ru.Maxrss) // Already bytes on macOS
+	}
+	return int64(ru.Maxrss) << 10 // Kilobytes on Linux
+}
@@ -0,0 +1,135 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// Stale worktree policies applied at acquisition
+const (
+	// StalePolicyRebase rebases a stale warm worktree onto the base branch, recycling it if that fails
+	StalePolicyRebase = "rebase"
+	// StalePolicyRecycle discards a stale warm worktree and lets the pool replace it
+	StalePolicyRecycle = "recycle"
+)
+
+// fetchLoop runs FetchAll-style syncs on a schedule until the pool stops
+// Each round waits FetchInterval plus a random jitter so many drover processes
+// sharing a remote don't fetch in lockstep
+func (p *WorktreePool) fetchLoop() {
+	defer p.wg.Done()
+
+	for {
+		delay := p.config.FetchInterval
+		if p.config.FetchJitter > 0 {
+			delay += rand.N(p.config.FetchJitter)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-p.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			p.fetchScheduled()
+		}
+	}
+}
+
+// fetchScheduled fetches every eligible worktree once
+// In-use worktrees are skipped when FetchSkipInUse is set so a running task
+// never has its repository changed underneath it
+func (p *WorktreePool) fetchScheduled() {
+	p.mu.RLock()
+	var targets []*PooledWorktree
+	for _, wt := range p.worktrees {
+		wt.mu.Lock()
+		eligible := wt.Path != "" && !wt.IsReadOnly &&
+			(wt.State == StateWarm || (wt.State == StateInUse && !p.config.FetchSkipInUse))
+		wt.mu.Unlock()
+		if eligible {
+			targets = append(targets, wt)
+		}
+	}
+	p.mu.RUnlock()
+
+	failed := 0
+	for _, wt := range targets {
+		if p.ctx.Err() != nil {
+			return
+		}
+		if result := p.fetchWorktree(wt); !result.Success {
+			failed++
+		}
+	}
+	if len(targets) > 0 {
+		log.Printf("🔄 Scheduled fetch: %d worktrees, %d failed", len(targets), failed)
+	}
+}
+
+// isStale reports whether a warm worktree's checkout is older than StaleAfter
+// Caller must hold wt.mu
+func (p *WorktreePool) isStale(wt *PooledWorktree, now time.Time) bool {
+	if p.config.StaleAfter <= 0 || wt.SyncedAt.IsZero() {
+		return false
+	}
+	return now.Sub(wt.SyncedAt) > p.config.StaleAfter
+}
+
+// refreshStale brings a stale warm worktree up to date before it is handed out
+// Returns false if the worktree was recycled (marked draining) instead
+// The caller has marked the worktree in use and holds neither p.mu nor wt.mu
+func (p *WorktreePool) refreshStale(wt *PooledWorktree) bool {
+	if p.config.StalePolicy != StalePolicyRecycle {
+		err := p.rebaseWorktree(wt.Path)
+		if err == nil {
+			wt.mu.Lock()
+			wt.SyncedAt = time.Now()
+			wt.mu.Unlock()
+			log.Printf("🔄 Rebased stale worktree %s onto base branch", wt.ID)
+			return true
+		}
+		log.Printf("⚠️  Failed to rebase stale worktree %s, recycling: %v", wt.ID, err)
+	}
+
+	wt.mu.Lock()
+	wt.State = StateDraining
+	wt.TaskID = ""
+	wt.AssignedAt = time.Time{}
+	wt.epicID = ""
+	wt.mu.Unlock()
+	log.Printf("♻️  Recycled stale worktree %s", wt.ID)
+	return false
+}
+
+// rebaseWorktree rebases a worktree's branch onto the target branch, which
+// holds the merged work of earlier tasks, whatever the base repository has
+// checked out. Pooled worktrees share the base's refs, so it is always present
+func (p *WorktreePool) rebaseWorktree(worktreePath string) error {
+	target := p.manager.TargetBranch()
+	cmd := worktreeGit(context.Background(), worktreePath, "rebase", "--quiet", target)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		abort := worktreeGit(context.Background(), worktreePath, "rebase", "--abort")
+		_ = abort.Run()
+		return fmt.Errorf("%w\n%s", err, output)
+	}
+	return nil
+}
+
+// resetWorktree brings a worktree a task is done with back to a clean
+// checkout of the target branch, keeping ignored files such as build output
+func (p *WorktreePool) resetWorktree(worktreePath string) error {
+	cmd := worktreeGit(context.Background(), worktreePath, "reset", "--hard", "--quiet", p.manager.TargetBranch())
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("resetting to %s: %w\n%s", p.manager.TargetBranch(), err, output)
+	}
+
+	cmd = worktreeGit(context.Background(), worktreePath, "clean", "-fdq")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("removing untracked files: %w\n%s", err, output)
+	}
+	return nil
+}
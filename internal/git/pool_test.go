@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"testing"
 	"time"
 )
@@ -227,6 +228,76 @@ func TestWorktreePool_MultipleAcquire(t *testing.T) {
 	pool.Release(taskID2, false)
 }
 
+// TestWorktreePool_BranchPrefix verifies pooled worktrees use the configured
+// branch prefix
+func TestWorktreePool_BranchPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+	manager.SetVerbose(false)
+	manager.SetBranchTemplate("agent", "")
+	pool := NewWorktreePool(manager, &PoolConfig{MinSize: 0, MaxSize: 1, WarmupTimeout: 5 * time.Second, CleanupOnExit: true})
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Failed to start pool: %v", err)
+	}
+	defer pool.Stop()
+
+	if _, err := pool.Acquire("task-1"); err != nil {
+		t.Fatalf("Failed to acquire worktree: %v", err)
+	}
+	defer pool.Release("task-1", false)
+	if branch := manager.BranchName("task-1"); branch != "agent-task-1" {
+		t.Errorf("Expected branch agent-task-1, got %s", branch)
+	}
+	if branch := manager.pooledBranchName("pool-1"); branch != "agent-pool-1" {
+		t.Errorf("Expected warm worktree branch agent-pool-1, got %s", branch)
+	}
+}
+
+// TestWorktreePool_Overflow verifies a full pool hands out a worktree of its
+// own instead of failing the task
+func TestWorktreePool_Overflow(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+	manager.SetVerbose(false)
+	pool := NewWorktreePool(manager, &PoolConfig{MinSize: 0, MaxSize: 1, WarmupTimeout: 5 * time.Second, CleanupOnExit: true})
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Failed to start pool: %v", err)
+	}
+	defer pool.Stop()
+
+	if _, err := pool.Acquire("task-1"); err != nil {
+		t.Fatalf("Failed to acquire worktree: %v", err)
+	}
+	defer pool.Release("task-1", false)
+	path, err := pool.Acquire("task-2")
+	if err != nil {
+		t.Fatalf("Expected a worktree outside the full pool, got %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected the overflow worktree at %s: %v", path, err)
+	}
+	if stats := pool.Stats(); stats.Total != 1 {
+		t.Errorf("Expected the overflow worktree outside the pool, got %d pooled", stats.Total)
+	}
+
+	if err := pool.Release("task-2", true); err != nil {
+		t.Fatalf("Failed to release the overflow worktree: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the overflow worktree removed on release, got %v", err)
+	}
+}
+
 // TestWorktreePool_IsEnabled verifies IsEnabled method
 func TestWorktreePool_IsEnabled(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "pool-test-*")
@@ -471,6 +542,419 @@ func TestWorktreePool_FetchAll(t *testing.T) {
 	}
 }
 
+// TestWorktreePool_AcquireForPaths verifies claims prefer worktrees with overlapping recent paths
+func TestWorktreePool_AcquireForPaths(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "pool-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+	manager.SetVerbose(false)
+	pool := NewWorktreePool(manager, &PoolConfig{MinSize: 0, MaxSize: 2})
+
+	now := time.Now()
+	pool.worktrees["pool-a"] = &PooledWorktree{
+		ID:          "pool-a",
+		Path:        gitDir,
+		State:       StateWarm,
+		RecentPaths: map[string]time.Time{"web": now, "web/src": now},
+	}
+	pool.worktrees["pool-b"] = &PooledWorktree{
+		ID:          "pool-b",
+		Path:        gitDir,
+		State:       StateWarm,
+		RecentPaths: map[string]time.Time{"internal": now, "internal/git": now},
+	}
+
+	if _, err := pool.AcquireForPaths("task-1", []string{"internal/git/pool.go"}); err != nil {
+		t.Fatalf("AcquireForPaths failed: %v", err)
+	}
+	if got := pool.worktrees["pool-b"].TaskID; got != "task-1" {
+		t.Errorf("Expected task-1 on pool-b (overlapping paths), got %q", got)
+	}
+
+	// Files the task touches are remembered on release
+	if err := os.MkdirAll(filepath.Join(gitDir, "cmd", "drover"), 0755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "cmd", "drover", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := pool.Release("task-1", true); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+
+	recent := make(map[string]bool)
+	for _, dir := range pool.RecentPaths("pool-b") {
+		recent[dir] = true
+	}
+	for _, want := range []string{"cmd", "cmd/drover", "internal/git"} {
+		if !recent[want] {
+			t.Errorf("Expected %q in recent paths, got %v", want, pool.RecentPaths("pool-b"))
+		}
+	}
+}
+
+func TestWorktreePool_AcquireWithAffinity(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "pool-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	// Retained worktrees are reset to the target branch
+	if err := runCommand(gitDir, "git", "branch", "-M", "main"); err != nil {
+		t.Fatalf("Failed to rename branch: %v", err)
+	}
+
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+	manager.SetVerbose(false)
+	pool := NewWorktreePool(manager, &PoolConfig{MinSize: 0, MaxSize: 3})
+
+	now := time.Now()
+	pool.worktrees["pool-a"] = &PooledWorktree{
+		ID:          "pool-a",
+		Path:        gitDir,
+		State:       StateWarm,
+		RecentPaths: map[string]time.Time{"internal": now},
+	}
+	pool.worktrees["pool-b"] = &PooledWorktree{
+		ID:         "pool-b",
+		Path:       gitDir,
+		State:      StateWarm,
+		LastEpicID: "epic-1",
+		LastEpicAt: now,
+	}
+	pool.worktrees["pool-c"] = &PooledWorktree{
+		ID:         "pool-c",
+		Path:       gitDir,
+		State:      StateWarm,
+		LastEpicID: "epic-2",
+		LastEpicAt: now.Add(-2 * localityWindow), // Too long ago to count
+	}
+
+	// The epic outweighs a shared top-level directory
+	if _, err := pool.AcquireWithAffinity("task-1", AffinityHints{EpicID: "epic-1", Paths: []string{"internal/api/handler.go"}}); err != nil {
+		t.Fatalf("AcquireWithAffinity failed: %v", err)
+	}
+	if got := pool.worktrees["pool-b"].TaskID; got != "task-1" {
+		t.Errorf("Expected task-1 on pool-b (same epic), got %q", got)
+	}
+
+	// An expired epic counts for nothing against overlapping paths
+	if _, err := pool.AcquireWithAffinity("task-2", AffinityHints{EpicID: "epic-2", Paths: []string{"internal/api/handler.go"}}); err != nil {
+		t.Fatalf("AcquireWithAffinity failed: %v", err)
+	}
+	if got := pool.worktrees["pool-a"].TaskID; got != "task-2" {
+		t.Errorf("Expected task-2 on pool-a (overlapping paths), got %q", got)
+	}
+
+	// Release remembers the epic, so the next task of it lands on the same worktree
+	if err := pool.Release("task-2", true); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if got := pool.worktrees["pool-a"].LastEpicID; got != "epic-2" {
+		t.Errorf("Expected pool-a to remember epic-2, got %q", got)
+	}
+	if _, err := pool.AcquireWithAffinity("task-3", AffinityHints{EpicID: "epic-2"}); err != nil {
+		t.Fatalf("AcquireWithAffinity failed: %v", err)
+	}
+	if got := pool.worktrees["pool-a"].TaskID; got != "task-3" {
+		t.Errorf("Expected task-3 back on pool-a (same epic), got %q", got)
+	}
+}
+
+// TestWorktreePool_GoCacheEnv verifies shared GOCACHE selection and stale cache cleanup
+func TestWorktreePool_GoCacheEnv(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "pool-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+
+	// Global mode: every worktree shares one cache
+	pool := NewWorktreePool(manager, &PoolConfig{MaxSize: 1, GoBuildCache: true})
+	if err := pool.initGoBuildCache(filepath.Join(tmpDir, "cache")); err != nil {
+		t.Fatalf("initGoBuildCache failed: %v", err)
+	}
+	want := "GOCACHE=" + filepath.Join(tmpDir, "cache", "gocache")
+	if env := pool.GoCacheEnv(gitDir); len(env) != 1 || env[0] != want {
+		t.Errorf("GoCacheEnv() = %v, want [%s]", env, want)
+	}
+
+	// Lock-hash mode: caches are keyed by go.sum
+	pool = NewWorktreePool(manager, &PoolConfig{
+		MaxSize:                   1,
+		GoBuildCache:              true,
+		GoBuildCacheMode:          GoCacheLockHash,
+		GoBuildCacheCleanInterval: time.Minute,
+	})
+	if err := pool.initGoBuildCache(filepath.Join(tmpDir, "cache-lock")); err != nil {
+		t.Fatalf("initGoBuildCache failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "go.sum"), []byte("example.com/a v1.0.0 h1:x\n"), 0644); err != nil {
+		t.Fatalf("Failed to write go.sum: %v", err)
+	}
+	env := pool.GoCacheEnv(gitDir)
+	if len(env) != 1 || filepath.Dir(env[0][len("GOCACHE="):]) != pool.GetSharedGoCachePath() {
+		t.Fatalf("GoCacheEnv() = %v, want a per-lock-hash cache under %s", env, pool.GetSharedGoCachePath())
+	}
+	current := env[0][len("GOCACHE="):]
+
+	// A cache for an old go.sum that hasn't been used recently is removed
+	stale := filepath.Join(pool.GetSharedGoCachePath(), "0123456789ab")
+	if err := os.MkdirAll(stale, 0755); err != nil {
+		t.Fatalf("Failed to create stale cache: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatalf("Failed to age stale cache: %v", err)
+	}
+	pool.lastGoCacheClean = old
+	pool.maintainGoBuildCache()
+
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Expected stale cache %s to be removed", stale)
+	}
+	if _, err := os.Stat(current); err != nil {
+		t.Errorf("Expected current cache to be kept: %v", err)
+	}
+
+	// One handed out recently is kept, however old the directory itself is
+	if err := os.WriteFile(filepath.Join(gitDir, "go.sum"), []byte("example.com/a v1.1.0 h1:y\n"), 0644); err != nil {
+		t.Fatalf("Failed to write go.sum: %v", err)
+	}
+	used := pool.GoCacheEnv(gitDir)[0][len("GOCACHE="):]
+	if used == current {
+		t.Fatalf("Expected a new cache for the new go.sum, got %s", used)
+	}
+	if err := os.Chtimes(used, old, old); err != nil {
+		t.Fatalf("Failed to age used cache: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(gitDir, "go.sum"), []byte("example.com/a v1.0.0 h1:x\n"), 0644); err != nil {
+		t.Fatalf("Failed to write go.sum: %v", err)
+	}
+	pool.lastGoCacheClean = old
+	pool.maintainGoBuildCache()
+	if _, err := os.Stat(used); err != nil {
+		t.Errorf("Expected the recently used cache %s to be kept: %v", used, err)
+	}
+}
+
+// TestWorktreePool_StaleWorktree verifies stale warm worktrees are rebased or recycled on acquire
+func TestWorktreePool_StaleWorktree(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "pool-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+
+	addWarm := func(pool *WorktreePool, id string) string {
+		path := filepath.Join(tmpDir, "worktrees", id)
+		if err := runCommand(gitDir, "git", "worktree", "add", "-q", "-b", "drover-"+id, path); err != nil {
+			t.Fatalf("Failed to add worktree: %v", err)
+		}
+		pool.worktrees[id] = &PooledWorktree{ID: id, Path: path, State: StateWarm, SyncedAt: time.Now().Add(-time.Hour)}
+		return path
+	}
+
+	rebasePool := NewWorktreePool(manager, &PoolConfig{MaxSize: 1, StaleAfter: time.Minute, StalePolicy: StalePolicyRebase})
+	path := addWarm(rebasePool, "pool-rebase")
+
+	// Base moves on after the worktree was warmed
+	if err := os.WriteFile(filepath.Join(gitDir, "new.txt"), []byte("new\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := runCommand(gitDir, "git", "add", "new.txt"); err != nil {
+		t.Fatalf("Failed to stage: %v", err)
+	}
+	if err := runCommand(gitDir, "git", "commit", "-q", "-m", "advance base"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	manager.SetTargetBranch(checkedOutBranch(gitDir))
+	target := headCommit(gitDir)
+	// Whatever the base has checked out, the target branch is what counts
+	if err := runCommand(gitDir, "git", "checkout", "-q", "-b", "side"); err != nil {
+		t.Fatalf("Failed to check out a side branch: %v", err)
+	}
+	if err := runCommand(gitDir, "git", "commit", "-q", "--allow-empty", "-m", "side work"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	got, err := rebasePool.Acquire("task-rebase")
+	if err != nil || got != path {
+		t.Fatalf("Acquire() = %q, %v; want %q", got, err, path)
+	}
+	if headCommit(path) != target {
+		t.Error("Expected stale worktree to be rebased onto the target branch")
+	}
+
+	// A fetch brings an idle worktree up to date, so it isn't stale anymore
+	if err := runCommand(gitDir, "git", "remote", "add", "origin", gitDir); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+	addWarm(rebasePool, "pool-fetch")
+	fetched := rebasePool.worktrees["pool-fetch"]
+	if result := rebasePool.fetchWorktree(fetched); !result.Success {
+		t.Fatalf("fetchWorktree() failed: %s", result.Error)
+	}
+	if rebasePool.isStale(fetched, time.Now()) {
+		t.Error("Expected a fetched worktree not to be stale")
+	}
+
+	recyclePool := NewWorktreePool(manager, &PoolConfig{MaxSize: 2, StaleAfter: time.Minute, StalePolicy: StalePolicyRecycle})
+	stalePath := addWarm(recyclePool, "pool-recycle")
+	got, err = recyclePool.Acquire("task-recycle")
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	if got == stalePath {
+		t.Error("Expected stale worktree to be recycled, not handed out")
+	}
+	if state := recyclePool.worktrees["pool-recycle"].State; state != StateDraining {
+		t.Errorf("Expected recycled worktree to be draining, got %s", state)
+	}
+}
+
+// TestWorktreePool_AdaptiveMaxSize verifies MaxSize follows the measured footprint
+func TestWorktreePool_AdaptiveMaxSize(t *testing.T) {
+	const mb = int64(1) << 20
+	fp := worktreeFootprint{diskBytes: 100 * mb, memBytes: 50 * mb}
+	unknown := hostResources{diskFree: -1, memAvailable: -1}
+
+	tests := []struct {
+		name  string
+		owned int
+		host  hostResources
+		want  int
+	}{
+		{"unknown host keeps configured", 2, unknown, 10},
+		{"plenty of room caps at configured", 2, hostResources{diskFree: 100000 * mb, memAvailable: 100000 * mb}, 10},
+		{"disk bound", 2, hostResources{diskFree: 550 * mb, memAvailable: -1}, 6},
+		{"memory bound", 1, hostResources{diskFree: 100000 * mb, memAvailable: 200 * mb}, 4},
+		{"below reserve shrinks", 4, hostResources{diskFree: 30 * mb, memAvailable: -1}, 3},
+		{"never negative", 0, hostResources{diskFree: 0, memAvailable: -1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := adaptiveMaxSize(10, tt.owned, 0, fp, tt.host, 100*mb, 50*mb)
+			if got != tt.want {
+				t.Errorf("adaptiveMaxSize() = %d (%s), want %d", got, reason, tt.want)
+			}
+		})
+	}
+
+	// Never below the worker count, and never above the configured maximum
+	if got, _ := adaptiveMaxSize(10, 1, 4, fp, hostResources{diskFree: 30 * mb, memAvailable: -1}, 100*mb, 50*mb); got != 4 {
+		t.Errorf("adaptiveMaxSize() with 4 workers = %d, want 4", got)
+	}
+	if got, _ := adaptiveMaxSize(3, 1, 4, fp, hostResources{diskFree: 30 * mb, memAvailable: -1}, 100*mb, 50*mb); got != 3 {
+		t.Errorf("adaptiveMaxSize() with 4 workers and a maximum of 3 = %d, want 3", got)
+	}
+
+	// Shrinking drains idle warm worktrees but leaves running tasks alone
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+	if err := os.MkdirAll(manager.worktreeDir, 0755); err != nil {
+		t.Fatalf("Failed to create worktree dir: %v", err)
+	}
+	pool := NewWorktreePool(manager, &PoolConfig{MaxSize: 4, AdaptiveMaxSize: true, DiskReserveMB: 1 << 40})
+	pool.worktrees["busy"] = &PooledWorktree{ID: "busy", TaskID: "task-1", State: StateInUse}
+	pool.worktrees["idle"] = &PooledWorktree{ID: "idle", State: StateWarm}
+
+	pool.resize()
+	if pool.sized {
+		t.Fatal("Expected no adaptive limit before any worktree was measured")
+	}
+
+	pool.recordFootprint(gitDir, 0)
+	pool.resize()
+	if got := pool.Stats().MaxSize; got >= 2 {
+		t.Errorf("Expected MaxSize below the owned count with an impossible disk reserve, got %d", got)
+	}
+	if state := pool.worktrees["idle"].State; state != StateDraining {
+		t.Errorf("Expected idle worktree to be draining, got %s", state)
+	}
+	if state := pool.worktrees["busy"].State; state != StateInUse {
+		t.Errorf("Expected busy worktree to stay in use, got %s", state)
+	}
+	// Over its adaptive MaxSize, the pool hands out a worktree of its own
+	if _, err := pool.Acquire("task-2"); err != nil || len(pool.worktrees) != 2 || !pool.overflow["task-2"] {
+		t.Errorf("Expected a worktree outside the pool while it is over its adaptive MaxSize, got %v", err)
+	}
+	pool.Release("task-2", false)
+}
+
+// TestTaskPaths verifies path extraction from task text
+func TestTaskPaths(t *testing.T) {
+	got := TaskPaths("Fix internal/git/pool.go", "See ./cmd/drover/ and https://example.com/x, not this one. Also internal/git/pool.go")
+	want := []string{"internal/git/pool.go", "cmd/drover"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("TaskPaths() = %v, want %v", got, want)
+	}
+}
+
+// TestChangedFiles verifies paths with spaces and non-ASCII characters are
+// read whole
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := initGitRepo(dir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	base := headCommit(dir)
+
+	for _, name := range []string{"dir one/a b.go", "café/x.go", "new dir/c.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := runCommand(dir, "git", "add", "dir one", "café"); err != nil {
+		t.Fatalf("Failed to stage: %v", err)
+	}
+	if err := runCommand(dir, "git", "commit", "-q", "-m", "task work"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	got := changedFiles(dir, base)
+	want := []string{"café/x.go", "dir one/a b.go", "new dir/c.go"}
+	sort.Strings(got)
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("changedFiles() = %q, want %q", got, want)
+	}
+}
+
 // Helper function to initialize a git repository for testing
 func initGitRepo(dir string) error {
 	// Create directory
@@ -522,3 +1006,112 @@ func runCommand(dir string, name string, args ...string) error {
 	}
 	return nil
 }
+
+// TestWorktreePool_SetIdle verifies an idle pool drains its warm worktrees
+// and stops warming new ones until it is active again
+func TestWorktreePool_SetIdle(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+	pool := NewWorktreePool(manager, &PoolConfig{MinSize: 2, MaxSize: 4})
+	pool.worktrees["busy"] = &PooledWorktree{ID: "busy", TaskID: "task-1", State: StateInUse}
+	pool.worktrees["warm"] = &PooledWorktree{ID: "warm", State: StateWarm}
+
+	pool.SetIdle(true)
+	if state := pool.worktrees["warm"].State; state != StateDraining {
+		t.Errorf("Expected the warm worktree to be draining, got %s", state)
+	}
+	if state := pool.worktrees["busy"].State; state != StateInUse {
+		t.Errorf("Expected the busy worktree to stay in use, got %s", state)
+	}
+	pool.cleanupDrainingWorktrees()
+	if err := pool.ensureMinWarmWorktrees(context.Background()); err != nil {
+		t.Fatalf("ensureMinWarmWorktrees: %v", err)
+	}
+	if len(pool.worktrees) != 1 {
+		t.Errorf("Expected no worktrees warmed while idle, have %d", len(pool.worktrees))
+	}
+
+	pool.SetIdle(false)
+	pool.mu.Lock()
+	idle := pool.idle
+	pool.mu.Unlock()
+	if idle {
+		t.Error("Expected the pool active again")
+	}
+}
+
+// TestWorktreePool_LentWorktree verifies a task's work in a warm pool
+// worktree commits and merges under the task's ID, and that retaining the
+// worktree resets it to the target branch for the next task
+func TestWorktreePool_LentWorktree(t *testing.T) {
+	tmpDir := t.TempDir()
+	gitDir := filepath.Join(tmpDir, "repo")
+	if err := initGitRepo(gitDir); err != nil {
+		t.Fatalf("Failed to init git repo: %v", err)
+	}
+	if err := runCommand(gitDir, "git", "branch", "-M", "main"); err != nil {
+		t.Fatalf("Failed to rename branch: %v", err)
+	}
+	manager := NewWorktreeManager(gitDir, filepath.Join(tmpDir, "worktrees"))
+	pool := NewWorktreePool(manager, &PoolConfig{MinSize: 1, MaxSize: 2, WarmupTimeout: 5 * time.Second, CleanupOnExit: true})
+	if err := pool.Start(); err != nil {
+		t.Fatalf("Failed to start pool: %v", err)
+	}
+	defer pool.Stop()
+
+	for deadline := time.Now().Add(10 * time.Second); pool.Stats().Warm == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for a warm worktree")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	taskID := "task-lent"
+	path, err := pool.Acquire(taskID)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if filepath.Base(path) == taskID {
+		t.Fatalf("Expected a warm pool worktree, got %s", path)
+	}
+	if got := manager.Path(taskID); got != path {
+		t.Errorf("Path(%s) = %s, want the lent worktree %s", taskID, got, path)
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "feature.txt"), []byte("work\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if hasChanges, err := manager.Commit(taskID, "task work"); err != nil || !hasChanges {
+		t.Fatalf("Commit = %v, %v; want the task's changes committed", hasChanges, err)
+	}
+	if err := manager.MergeToMain(taskID); err != nil {
+		t.Fatalf("MergeToMain: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(gitDir, "feature.txt")); err != nil {
+		t.Errorf("Expected the task's work merged into main: %v", err)
+	}
+
+	// Untracked leftovers must not survive into the next task
+	if err := os.WriteFile(filepath.Join(path, "leftover.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := pool.Release(taskID, true); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if got := manager.Path(taskID); got == path {
+		t.Errorf("Path(%s) still points at the released worktree", taskID)
+	}
+	if stats := pool.Stats(); stats.InUse != 0 || stats.Warm == 0 {
+		t.Errorf("Expected the retained worktree back warm, got %+v", stats)
+	}
+	if head, main := headCommit(path), headCommit(gitDir); head != main {
+		t.Errorf("Retained worktree is at %s, want main's %s", head, main)
+	}
+	if _, err := os.Stat(filepath.Join(path, "leftover.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected untracked files removed from the retained worktree (stat: %v)", err)
+	}
+}
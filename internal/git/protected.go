@@ -0,0 +1,55 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ProtectedPathError reports changes that touched protected paths
+// The offending changes were unstaged and are not part of the task commit
+type ProtectedPathError struct {
+	Paths []string
+}
+
+// Error implements error
+func (e *ProtectedPathError) Error() string {
+	return fmt.Sprintf("policy violation: changes to protected paths: %s", strings.Join(e.Paths, ", "))
+}
+
+// SetProtectedPaths configures paths that task commits may not modify
+// Entries are repo-relative: "dir/" or "dir" protects a whole tree, glob
+// patterns such as "*.lock" are matched against each path and its base name
+func (wm *WorktreeManager) SetProtectedPaths(paths []string) {
+	wm.protectedPaths = nil
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			wm.protectedPaths = append(wm.protectedPaths, strings.TrimPrefix(p, "./"))
+		}
+	}
+}
+
+// ProtectedPaths returns the configured protected paths
+func (wm *WorktreeManager) ProtectedPaths() []string {
+	return wm.protectedPaths
+}
+
+// isProtected reports whether a repo-relative file path matches any protected pattern
+func isProtected(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		dir := strings.TrimSuffix(pattern, "/")
+		if file == dir || strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(file)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package git
+
+import (
+	"log"
+	"time"
+)
+
+// Worktree lifecycle states persisted through a WorktreeRecorder
+const (
+	WorktreeActive   = "active"
+	WorktreeReleased = "released"
+	WorktreeMerged   = "merged"
+	WorktreeRemoved  = "removed"
+)
+
+// WorktreeRecorder persists worktree lifecycle events so the dashboard and
+// `drover worktree list` don't have to reconstruct them from disk
+// Implemented by *db.Store
+type WorktreeRecorder interface {
+	// RecordWorktree upserts the row for a task's worktree in the active state
+	RecordWorktree(taskID, path, branch string, setup time.Duration) error
+	UpdateWorktreeStatus(taskID, status string) error
+	UpdateWorktreeDiskSize(taskID string, size int64) error
+}
+
+// SetRecorder sets where worktree lifecycle events are persisted (nil disables recording)
+func (wm *WorktreeManager) SetRecorder(r WorktreeRecorder) {
+	wm.recorder = r
+}
+
+// recordCreated persists a newly assigned worktree
+// Recording is best-effort: failures are logged and never fail the task
+func (wm *WorktreeManager) recordCreated(taskID, path, branch string, setup time.Duration) {
+	if wm.recorder == nil {
+		return
+	}
+	if err := wm.recorder.RecordWorktree(taskID, path, branch, setup); err != nil {
+		log.Printf("⚠️  Failed to record worktree for task %s: %v", taskID, err)
+	}
+}
+
+// recordStatus persists a worktree state transition
+func (wm *WorktreeManager) recordStatus(taskID, status string) {
+	if wm.recorder == nil {
+		return
+	}
+	if err := wm.recorder.UpdateWorktreeStatus(taskID, status); err != nil {
+		log.Printf("⚠️  Failed to record worktree status for task %s: %v", taskID, err)
+	}
+}
+
+// recordDiskUsage measures and persists the disk usage of the worktree at path
+func (wm *WorktreeManager) recordDiskUsage(taskID, path string) {
+	if wm.recorder == nil {
+		return
+	}
+	size, err := wm.getDirectorySize(path)
+	if err != nil {
+		return
+	}
+	if err := wm.recorder.UpdateWorktreeDiskSize(taskID, size); err != nil {
+		log.Printf("⚠️  Failed to record worktree disk usage for task %s: %v", taskID, err)
+	}
+}
+
+// recordRemoved persists that a task's worktree no longer exists on disk
+func (wm *WorktreeManager) recordRemoved(taskID string) {
+	if wm.recorder == nil {
+		return
+	}
+	wm.recordStatus(taskID, WorktreeRemoved)
+	if err := wm.recorder.UpdateWorktreeDiskSize(taskID, 0); err != nil {
+		log.Printf("⚠️  Failed to record worktree disk usage for task %s: %v", taskID, err)
+	}
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// RepoManager keeps one worktree manager per repository a project drives.
+// The project's own repository is the primary one, named ""; tasks reach
+// the others by the name they are configured under, each with its own
+// worktrees and merge target
+type RepoManager struct {
+	mu    sync.RWMutex
+	repos map[string]*WorktreeManager
+}
+
+// NewRepoManager returns a manager with primary as the project's repository
+func NewRepoManager(primary *WorktreeManager) *RepoManager {
+	return &RepoManager{repos: map[string]*WorktreeManager{"": primary}}
+}
+
+// Add registers the worktree manager of another repository under name
+func (rm *RepoManager) Add(name string, wm *WorktreeManager) error {
+	if name == "" {
+		return fmt.Errorf("repository name cannot be empty")
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	if _, ok := rm.repos[name]; ok {
+		return fmt.Errorf("repository %q is already registered", name)
+	}
+	rm.repos[name] = wm
+	return nil
+}
+
+// Primary returns the worktree manager of the project's own repository
+func (rm *RepoManager) Primary() *WorktreeManager {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.repos[""]
+}
+
+// Get returns the worktree manager of the named repository; "" is the
+// primary one
+func (rm *RepoManager) Get(name string) (*WorktreeManager, error) {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	wm, ok := rm.repos[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown repository %q (configure it under [repos] in .drover.toml)", name)
+	}
+	return wm, nil
+}
+
+// Names returns the names of the repositories besides the primary one, sorted
+func (rm *RepoManager) Names() []string {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	names := make([]string, 0, len(rm.repos)-1)
+	for name := range rm.repos {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Cleanup removes the drover worktrees of every repository
+func (rm *RepoManager) Cleanup() error {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	for name, wm := range rm.repos {
+		if err := wm.Cleanup(); err != nil {
+			return fmt.Errorf("cleaning up worktrees of %q: %w", name, err)
+		}
+	}
+	return nil
+}
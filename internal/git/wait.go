@@ -0,0 +1,34 @@
+package git
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/telemetry"
+)
+
+// Serialization points where workers queue behind each other
+const (
+	// WaitMerge is time spent waiting for the per-branch merge lock
+	WaitMerge = "merge"
+	// WaitPoolAcquire is time spent obtaining a pooled worktree
+	WaitPoolAcquire = "pool_acquire"
+)
+
+// WaitObserver is told how long a worker queued at a serialization point
+// It is called from worker goroutines and must be safe for concurrent use
+type WaitObserver func(point string, wait time.Duration)
+
+// SetWaitObserver registers fn to receive serialization wait times (nil disables)
+func (wm *WorktreeManager) SetWaitObserver(fn WaitObserver) {
+	wm.waitObserver = fn
+}
+
+// observeWait reports the time queued at point since start
+func (wm *WorktreeManager) observeWait(ctx context.Context, point string, start time.Time) {
+	wait := time.Since(start)
+	telemetry.RecordSerializationWait(ctx, point, wait)
+	if wm.waitObserver != nil {
+		wm.waitObserver(point, wait)
+	}
+}
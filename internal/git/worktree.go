@@ -2,6 +2,8 @@
 package git
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io/fs"
 	"log"
@@ -10,19 +12,71 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/cloud-shuttle/drover/pkg/types"
 )
 
-// Global mutex to serialize MergeToMain operations across all workers
-// Multiple workers checking out and merging to main simultaneously causes git index lock conflicts
-var mergeMutex sync.Mutex
+// mergeLocks serializes MergeToMain per target branch across all workers
+// Two merges into the same branch race on its index and ref; merges into
+// different branches don't share either and may run concurrently
+// Each lock is a one-slot channel so waiting for it can be cancelled
+var mergeLocks sync.Map // repoDir + "\x00" + branch -> chan struct{}
+
+// mergeLock returns the lock guarding merges into branch in repoDir
+func mergeLock(repoDir, branch string) chan struct{} {
+	lock, _ := mergeLocks.LoadOrStore(repoDir+"\x00"+branch, make(chan struct{}, 1))
+	return lock.(chan struct{})
+}
+
+// IsolationMode controls how each task's working copy is created
+type IsolationMode string
+
+const (
+	// IsolationWorktree uses linked git worktrees (default)
+	IsolationWorktree IsolationMode = "worktree"
+	// IsolationClone uses a local `git clone --shared` per task, for tools
+	// that misbehave when .git is a file rather than a directory
+	IsolationClone IsolationMode = "clone"
+)
+
+// ParseIsolationMode validates an isolation mode string
+// An empty string selects the default worktree mode
+func ParseIsolationMode(s string) (IsolationMode, error) {
+	switch IsolationMode(s) {
+	case "", IsolationWorktree:
+		return IsolationWorktree, nil
+	case IsolationClone:
+		return IsolationClone, nil
+	default:
+		return "", fmt.Errorf("unknown isolation mode %q (expected worktree or clone)", s)
+	}
+}
 
 // WorktreeManager creates and manages git worktrees
 type WorktreeManager struct {
-	baseDir     string // Base repository directory
-	worktreeDir string // Where worktrees are created (.drover/worktrees)
-	verbose     bool   // Enable verbose logging
+	baseDir     string        // Base repository directory
+	worktreeDir string        // Where worktrees are created (.drover/worktrees)
+	verbose     bool          // Enable verbose logging
+	mode        IsolationMode // Worktree or full clone per task
+
+	protectedPaths []string // Paths task commits may not modify
+
+	branchPrefix   string            // Prefix for task branches (default "drover")
+	branchTemplate string            // Branch name template (see SetBranchTemplate)
+	branchMu       sync.Mutex        // Protects branches and lent
+	branches       map[string]string // taskID -> branch chosen at Create time
+	lent           map[string]string // taskID -> pool worktree lent to the task (see lend)
+
+	author CommitAuthor // Author identity for task commits (zero keeps the git config identity)
+
+	targetBranch string // Branch task work merges into (see SetTargetBranch)
+
+	recorder WorktreeRecorder // Persists lifecycle events (nil disables recording)
+
+	networkTimeout time.Duration // Upper bound for git commands that talk to a remote (0 = none)
+
+	waitObserver WaitObserver // Receives serialization wait times (see SetWaitObserver)
 }
 
 // NewWorktreeManager creates a new worktree manager
@@ -31,6 +85,12 @@ func NewWorktreeManager(baseDir, worktreeDir string) *WorktreeManager {
 		baseDir:     baseDir,
 		worktreeDir: worktreeDir,
 		verbose:     false,
+		mode:        IsolationWorktree,
+
+		branchPrefix:   "drover",
+		branchTemplate: DefaultBranchTemplate,
+		branches:       make(map[string]string),
+		lent:           make(map[string]string),
 	}
 }
 
@@ -39,10 +99,42 @@ func (wm *WorktreeManager) SetVerbose(v bool) {
 	wm.verbose = v
 }
 
+// SetIsolationMode selects between linked worktrees and full clones
+func (wm *WorktreeManager) SetIsolationMode(mode IsolationMode) {
+	if mode == "" {
+		mode = IsolationWorktree
+	}
+	wm.mode = mode
+}
+
+// IsolationMode returns the configured isolation mode
+func (wm *WorktreeManager) IsolationMode() IsolationMode {
+	return wm.mode
+}
+
+// SetNetworkTimeout bounds git commands that talk to a remote (push, fetch)
+// so an unreachable remote can't hold a worker indefinitely
+func (wm *WorktreeManager) SetNetworkTimeout(d time.Duration) {
+	wm.networkTimeout = d
+}
+
+// networkContext derives the context for a git network operation from ctx
+func (wm *WorktreeManager) networkContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if wm.networkTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, wm.networkTimeout)
+}
+
 // Create creates a new worktree for a task
 func (wm *WorktreeManager) Create(task *types.Task) (string, error) {
+	return wm.CreateWithContext(context.Background(), task)
+}
+
+// CreateWithContext creates a new worktree for a task, killing git if ctx is done
+func (wm *WorktreeManager) CreateWithContext(ctx context.Context, task *types.Task) (string, error) {
+	start := time.Now()
 	worktreePath := filepath.Join(wm.worktreeDir, task.ID)
-	branchName := fmt.Sprintf("drover-%s", task.ID)
 
 	// Ensure worktree directory exists
 	if err := os.MkdirAll(wm.worktreeDir, 0755); err != nil {
@@ -51,35 +143,96 @@ func (wm *WorktreeManager) Create(task *types.Task) (string, error) {
 
 	// Clean up any existing worktree/branch at this path first
 	// This handles stale worktrees from interrupted runs
-	wm.cleanUpWorktree(task.ID)
+	wm.cleanUpWorktree(ctx, task.ID)
+
+	branchName, err := wm.resolveBranchName(task)
+	if err != nil {
+		return "", err
+	}
+	wm.rememberBranch(task.ID, branchName)
 
 	// Delete the branch if it already exists from a previous failed run
-	cmd := exec.Command("git", "branch", "-D", branchName)
+	cmd := exec.CommandContext(ctx, "git", "branch", "-D", branchName)
 	cmd.Dir = wm.baseDir
 	_, _ = cmd.CombinedOutput() // Ignore errors - branch may not exist
 
+	if wm.mode == IsolationClone {
+		clonePath, err := wm.createClone(ctx, worktreePath, branchName)
+		if err == nil {
+			wm.recordCreated(task.ID, clonePath, branchName, time.Since(start))
+		}
+		return clonePath, err
+	}
+
 	// Create the worktree with a new branch
 	// Using -b ensures the worktree has its own branch from the start
 	// This avoids detached HEAD issues and makes merging more reliable
-	cmd = exec.Command("git", "worktree", "add", "-b", branchName, worktreePath)
+	cmd = exec.CommandContext(ctx, "git", "worktree", "add", "-b", branchName, worktreePath)
 	cmd.Dir = wm.baseDir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		wm.forgetBranch(task.ID)
 		return "", fmt.Errorf("creating worktree: %w\n%s", err, output)
 	}
 
+	wm.recordCreated(task.ID, worktreePath, branchName, time.Since(start))
 	return worktreePath, nil
 }
 
+// createClone creates a standalone clone sharing the base repository's object store
+func (wm *WorktreeManager) createClone(ctx context.Context, clonePath, branchName string) (string, error) {
+	// --shared borrows objects from the base repo via alternates, so the
+	// clone is cheap but still has a real .git directory
+	cmd := exec.CommandContext(ctx, "git", "clone", "--shared", "--quiet", wm.baseDir, clonePath)
+	cmd.Dir = wm.baseDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("creating clone: %w\n%s", err, output)
+	}
+
+	// Carry over the base repo's identity so commits look the same as in worktree mode
+	for _, key := range []string{"user.name", "user.email"} {
+		cmd = exec.CommandContext(ctx, "git", "config", "--get", key)
+		cmd.Dir = wm.baseDir
+		value, err := cmd.Output()
+		if err != nil || strings.TrimSpace(string(value)) == "" {
+			continue
+		}
+		cmd = worktreeGit(ctx, clonePath, "config", key, strings.TrimSpace(string(value)))
+		_ = cmd.Run()
+	}
+
+	cmd = worktreeGit(ctx, clonePath, "checkout", "-b", branchName)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		_ = os.RemoveAll(clonePath)
+		return "", fmt.Errorf("creating clone branch: %w\n%s", err, output)
+	}
+
+	return clonePath, nil
+}
+
+// isClone reports whether path is a standalone clone (its .git is a directory)
+func isClone(path string) bool {
+	info, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil && info.IsDir()
+}
+
 // GetWorktreePath returns the path to a worktree for a task, if it exists
 func (wm *WorktreeManager) GetWorktreePath(taskID string) (string, error) {
-	worktreePath := filepath.Join(wm.worktreeDir, taskID)
+	worktreePath := wm.Path(taskID)
 
 	// Check if worktree directory exists
 	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
 		return "", fmt.Errorf("worktree does not exist")
 	}
 
+	// Clones are not registered with the base repo; a .git directory is enough
+	if wm.mode == IsolationClone {
+		if isClone(worktreePath) {
+			return worktreePath, nil
+		}
+		return "", fmt.Errorf("clone is not a git repository")
+	}
+
 	// Verify it's a valid git worktree
 	cmd := exec.Command("git", "worktree", "list", "--porcelain")
 	cmd.Dir = wm.baseDir
@@ -103,17 +256,18 @@ func (wm *WorktreeManager) GetWorktreePath(taskID string) (string, error) {
 }
 
 // cleanUpWorktree removes any existing worktree registration, branch, and directory for a task
-func (wm *WorktreeManager) cleanUpWorktree(taskID string) {
+func (wm *WorktreeManager) cleanUpWorktree(ctx context.Context, taskID string) {
 	worktreePath := filepath.Join(wm.worktreeDir, taskID)
-	branchName := fmt.Sprintf("drover-%s", taskID)
+	branchName := wm.BranchName(taskID)
+	defer wm.forgetBranch(taskID)
 
 	// Step 1: Try to remove the worktree via git (handles registered worktrees)
-	cmd := exec.Command("git", "worktree", "remove", "--force", worktreePath)
+	cmd := exec.CommandContext(ctx, "git", "worktree", "remove", "--force", worktreePath)
 	cmd.Dir = wm.baseDir
 	_ = cmd.Run() // Ignore errors
 
 	// Step 2: Remove the branch if it exists (from previous runs)
-	cmd = exec.Command("git", "branch", "-D", branchName)
+	cmd = exec.CommandContext(ctx, "git", "branch", "-D", branchName)
 	cmd.Dir = wm.baseDir
 	_ = cmd.Run() // Ignore errors
 
@@ -123,39 +277,63 @@ func (wm *WorktreeManager) cleanUpWorktree(taskID string) {
 	}
 
 	// Step 4: Prune all stale worktree registrations globally
-	cmd = exec.Command("git", "worktree", "prune")
+	cmd = exec.CommandContext(ctx, "git", "worktree", "prune")
 	cmd.Dir = wm.baseDir
 	_ = cmd.Run() // Ignore errors
 }
 
 // PruneStale removes stale git worktree registrations and branch for a specific task
 func (wm *WorktreeManager) PruneStale(taskID string) {
+	wm.PruneStaleWithContext(context.Background(), taskID)
+}
+
+// PruneStaleWithContext is PruneStale bounded by ctx
+func (wm *WorktreeManager) PruneStaleWithContext(ctx context.Context, taskID string) {
 	worktreePath := filepath.Join(wm.worktreeDir, taskID)
-	branchName := fmt.Sprintf("drover-%s", taskID)
+	branchName := wm.BranchName(taskID)
+	defer wm.forgetBranch(taskID)
 
 	// First, try force remove if the worktree is registered but directory is missing
-	cmd := exec.Command("git", "worktree", "remove", "--force", worktreePath)
+	cmd := exec.CommandContext(ctx, "git", "worktree", "remove", "--force", worktreePath)
 	cmd.Dir = wm.baseDir
 	_ = cmd.Run() // Ignore errors
 
 	// Also remove the branch if it exists (from previous runs)
-	cmd = exec.Command("git", "branch", "-D", branchName)
+	cmd = exec.CommandContext(ctx, "git", "branch", "-D", branchName)
 	cmd.Dir = wm.baseDir
 	_ = cmd.Run() // Ignore errors
 
 	// Then prune all stale worktree registrations (globally, not per-worktree)
-	cmd = exec.Command("git", "worktree", "prune")
+	cmd = exec.CommandContext(ctx, "git", "worktree", "prune")
 	cmd.Dir = wm.baseDir
 	_ = cmd.Run() // Ignore errors, this is best-effort cleanup
 }
 
 // Remove removes a worktree and its associated branch
 func (wm *WorktreeManager) Remove(taskID string) error {
+	return wm.RemoveWithContext(context.Background(), taskID)
+}
+
+// RemoveWithContext is Remove bounded by ctx
+func (wm *WorktreeManager) RemoveWithContext(ctx context.Context, taskID string) error {
 	worktreePath := filepath.Join(wm.worktreeDir, taskID)
-	branchName := fmt.Sprintf("drover-%s", taskID)
+	branchName := wm.BranchName(taskID)
+	defer wm.forgetBranch(taskID)
+
+	// Clones are plain directories as far as the base repo is concerned
+	if isClone(worktreePath) {
+		if err := os.RemoveAll(worktreePath); err != nil {
+			return fmt.Errorf("removing clone: %w", err)
+		}
+		cmd := exec.CommandContext(ctx, "git", "branch", "-D", branchName)
+		cmd.Dir = wm.baseDir
+		_, _ = cmd.CombinedOutput() // Ignore errors - branch may not exist
+		wm.recordRemoved(taskID)
+		return nil
+	}
 
 	// Remove the worktree
-	cmd := exec.Command("git", "worktree", "remove", worktreePath)
+	cmd := exec.CommandContext(ctx, "git", "worktree", "remove", worktreePath)
 	cmd.Dir = wm.baseDir
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -171,21 +349,28 @@ func (wm *WorktreeManager) Remove(taskID string) error {
 	}
 
 	// Clean up the associated branch
-	cmd = exec.Command("git", "branch", "-D", branchName)
+	cmd = exec.CommandContext(ctx, "git", "branch", "-D", branchName)
 	cmd.Dir = wm.baseDir
 	_, _ = cmd.CombinedOutput() // Ignore errors - branch may not exist
 
+	wm.recordRemoved(taskID)
 	return nil
 }
 
 // Commit commits all changes in the worktree
 // Returns (hasChanges, error) - hasChanges is true if changes were committed
+// Changes to protected paths are unstaged and reported as a *ProtectedPathError
+// alongside the result of committing the remaining changes
 func (wm *WorktreeManager) Commit(taskID, message string) (bool, error) {
-	worktreePath := filepath.Join(wm.worktreeDir, taskID)
+	return wm.CommitWithContext(context.Background(), taskID, message)
+}
+
+// CommitWithContext is Commit bounded by ctx
+func (wm *WorktreeManager) CommitWithContext(ctx context.Context, taskID, message string) (bool, error) {
+	worktreePath := wm.Path(taskID)
 
 	// Check if there are any changes to commit
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = worktreePath
+	cmd := worktreeGit(ctx, worktreePath, "status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return false, fmt.Errorf("checking status: %w", err)
@@ -212,15 +397,34 @@ func (wm *WorktreeManager) Commit(taskID, message string) (bool, error) {
 	}
 
 	// Stage all changes
-	cmd = exec.Command("git", "add", "-A")
-	cmd.Dir = worktreePath
+	cmd = worktreeGit(ctx, worktreePath, "add", "-A")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return false, fmt.Errorf("staging changes: %w\n%s", err, output)
 	}
 
-	// Commit
-	cmd = exec.Command("git", "commit", "-m", message)
-	cmd.Dir = worktreePath
+	// Unstage anything touching protected paths so it never reaches main
+	var violation *ProtectedPathError
+	if len(wm.protectedPaths) > 0 {
+		var err error
+		violation, err = wm.unstageProtected(ctx, worktreePath)
+		if err != nil {
+			return false, err
+		}
+		if violation != nil {
+			log.Printf("🚫 Task %s modified protected paths: %s", taskID, strings.Join(violation.Paths, ", "))
+
+			cmd = worktreeGit(ctx, worktreePath, "diff", "--cached", "--quiet")
+			if err := cmd.Run(); err == nil {
+				return false, violation // Only protected changes, nothing left to commit
+			}
+		}
+	}
+
+	// Commit, attributed to the agent that produced the change
+	cmd = worktreeGit(ctx, worktreePath, "commit", "-m", message)
+	if !wm.author.IsZero() {
+		cmd.Env = wm.author.env()
+	}
 	if output, err := cmd.CombinedOutput(); err != nil {
 		// If git commit says "nothing to commit", treat it as success
 		// This can happen if the working tree changes between the check and the commit
@@ -238,28 +442,176 @@ func (wm *WorktreeManager) Commit(taskID, message string) (bool, error) {
 		log.Printf("✅ Committed changes for task %s", taskID)
 	}
 
+	// The worktree is at its largest once the task's work is committed
+	wm.recordDiskUsage(taskID, worktreePath)
+
+	if violation != nil {
+		return true, violation
+	}
 	return true, nil
 }
 
-// MergeToMain merges the worktree changes to main branch
+// unstageProtected removes staged changes under protected paths from the index
+// Returns nil if no protected path was touched
+func (wm *WorktreeManager) unstageProtected(ctx context.Context, worktreePath string) (*ProtectedPathError, error) {
+	// --no-renames so a rename out of a protected directory shows up as a deletion there
+	cmd := worktreeGit(ctx, worktreePath, "diff", "--cached", "--name-only", "--no-renames", "-z")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing staged changes: %w", err)
+	}
+
+	var protected []string
+	for _, file := range splitNUL(output) {
+		if isProtected(file, wm.protectedPaths) {
+			protected = append(protected, file)
+		}
+	}
+	if len(protected) == 0 {
+		return nil, nil
+	}
+
+	args := append([]string{"reset", "-q", "--"}, protected...)
+	cmd = worktreeGit(ctx, worktreePath, args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("unstaging protected paths: %w\n%s", err, output)
+	}
+
+	return &ProtectedPathError{Paths: protected}, nil
+}
+
+// TaskDiff returns the unified diff of a task's committed work against the
+// point where its branch left the target branch. When that point can't be
+// found (e.g. a clone without the target branch) it falls back to the last commit
+func (wm *WorktreeManager) TaskDiff(ctx context.Context, taskID string) (string, error) {
+	worktreePath := wm.Path(taskID)
+
+	base := "HEAD~1"
+	cmd := worktreeGit(ctx, worktreePath, "merge-base", "HEAD", wm.TargetBranch())
+	if output, err := cmd.Output(); err == nil {
+		base = strings.TrimSpace(string(output))
+	}
+
+	cmd = worktreeGit(ctx, worktreePath, "diff", "--no-renames", "--no-color", base, "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("diffing task %s: %w", taskID, err)
+	}
+	return string(output), nil
+}
+
+// ChangedFiles lists the files a task's committed work changed against the
+// point where its branch left the target branch. Once the branch has merged
+// that point is its head, so it falls back to the last commit
+func (wm *WorktreeManager) ChangedFiles(ctx context.Context, taskID string) ([]string, error) {
+	worktreePath := wm.Path(taskID)
+
+	base := "HEAD~1"
+	cmd := worktreeGit(ctx, worktreePath, "merge-base", "HEAD", wm.TargetBranch())
+	if output, err := cmd.Output(); err == nil && strings.TrimSpace(string(output)) != headCommit(worktreePath) {
+		base = strings.TrimSpace(string(output))
+	}
+
+	cmd = worktreeGit(ctx, worktreePath, "diff", "--name-only", "--no-renames", "-z", base, "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing files changed by task %s: %w", taskID, err)
+	}
+	return splitNUL(output), nil
+}
+
+// AdoptWorktree replaces the uncommitted work in a task's worktree with the
+// work done in another worktree of the same repository, e.g. by a second
+// attempt of the task that finished first. Both must have started from the
+// commit the task's worktree is at
+func (wm *WorktreeManager) AdoptWorktree(ctx context.Context, taskID, fromPath string) error {
+	worktreePath := wm.Path(taskID)
+	head := headCommit(worktreePath)
+	if head == "" {
+		return fmt.Errorf("reading HEAD of task %s's worktree", taskID)
+	}
+
+	cmd := worktreeGit(ctx, fromPath, "add", "-A")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("staging work to adopt: %w\n%s", err, output)
+	}
+	cmd = worktreeGit(ctx, fromPath, "diff", "--cached", "--binary", "--no-color", head)
+	patch, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("diffing work to adopt: %w", err)
+	}
+
+	// Drop what the task's own worktree did so far, then apply the other's
+	for _, args := range [][]string{{"reset", "--hard", "-q"}, {"clean", "-fdq"}} {
+		cmd = worktreeGit(ctx, worktreePath, args...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("discarding work of task %s: %w\n%s", taskID, err, output)
+		}
+	}
+	if len(patch) == 0 {
+		return nil
+	}
+	cmd = worktreeGit(ctx, worktreePath, "apply", "--binary", "-")
+	cmd.Stdin = bytes.NewReader(patch)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("applying adopted work to task %s: %w\n%s", taskID, err, output)
+	}
+	return nil
+}
+
+// MergeToMain merges the worktree changes into the target branch (see SetTargetBranch)
+// If the target is checked out in the base repository the merge happens there;
+// otherwise it happens in a scratch worktree so the base checkout is never
+// switched underneath a concurrent merge into another branch
 func (wm *WorktreeManager) MergeToMain(taskID string) error {
-	// Serialize merge operations to prevent git index lock conflicts
-	mergeMutex.Lock()
-	defer mergeMutex.Unlock()
+	return wm.MergeToMainWithContext(context.Background(), taskID)
+}
 
-	branchName := fmt.Sprintf("drover-%s", taskID)
+// MergeToMainWithContext is MergeToMain bounded by ctx
+// Waiting for another merge into the same branch also stops when ctx is done,
+// and a merge interrupted in the base repository is aborted so it stays clean
+func (wm *WorktreeManager) MergeToMainWithContext(ctx context.Context, taskID string) error {
+	target := wm.TargetBranch()
+
+	// Serialize merges into the same branch to prevent git index lock conflicts
+	lock := mergeLock(wm.baseDir, target)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("waiting to merge into %s: %w", target, err)
+	}
+	waitStart := time.Now()
+	select {
+	case lock <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("waiting to merge into %s: %w", target, ctx.Err())
+	}
+	defer func() { <-lock }()
+	wm.observeWait(ctx, WaitMerge, waitStart)
+
+	branchName := wm.BranchName(taskID)
+
+	// In clone mode the task branch lives in the clone; bring it into the base repo first
+	if clonePath := filepath.Join(wm.worktreeDir, taskID); isClone(clonePath) {
+		cmd := exec.CommandContext(ctx, "git", "fetch", "--quiet", clonePath, "+"+branchName+":"+branchName)
+		cmd.Dir = wm.baseDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("fetching branch from clone: %w\n%s", err, output)
+		}
+	}
 
 	// Check if the branch exists (worktree was created successfully)
-	cmd := exec.Command("git", "rev-parse", "--verify", branchName)
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", branchName)
 	cmd.Dir = wm.baseDir
 	if _, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("checking branch %s: %w", branchName, ctx.Err())
+		}
 		// Branch doesn't exist, nothing to merge
 		// This can happen if the worktree was never created or was cleaned up
 		return nil
 	}
 
-	// Check if worktree has any commits ahead of main
-	cmd = exec.Command("git", "rev-list", "main.."+branchName, "--count")
+	// Check if worktree has any commits ahead of the target branch
+	cmd = exec.CommandContext(ctx, "git", "rev-list", target+".."+branchName, "--count")
 	cmd.Dir = wm.baseDir
 	output, err := cmd.Output()
 	if err != nil {
@@ -271,26 +623,154 @@ func (wm *WorktreeManager) MergeToMain(taskID string) error {
 		return nil
 	}
 
-	// Switch to main in base repo
-	cmd = exec.Command("git", "checkout", "main")
+	message := fmt.Sprintf("drover: Merge %s", taskID)
+	if checkedOutBranch(wm.baseDir) == target {
+		// Merge the branch
+		cmd = exec.CommandContext(ctx, "git", "merge", "--no-ff", branchName, "-m", message)
+		cmd.Dir = wm.baseDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			if ctx.Err() != nil {
+				// Killed mid-merge: don't leave MERGE_HEAD behind in the user's checkout
+				abort := exec.Command("git", "merge", "--abort")
+				abort.Dir = wm.baseDir
+				_ = abort.Run()
+			}
+			return fmt.Errorf("merging: %w\n%s", err, output)
+		}
+	} else if err := wm.mergeDetached(ctx, target, branchName, message); err != nil {
+		return err
+	}
+
+	// Delete the branch after successful merge (-D: it need not be merged into the base HEAD)
+	cmd = exec.CommandContext(ctx, "git", "branch", "-D", branchName)
 	cmd.Dir = wm.baseDir
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("checking out main: %w\n%s", err, output)
+	_, _ = cmd.CombinedOutput() // Ignore errors on branch delete
+
+	wm.recordStatus(taskID, WorktreeMerged)
+	return nil
+}
+
+// mergeDetached merges branchName into target inside a scratch worktree
+// detached at target, then advances target only if nobody moved it meanwhile
+// Caller must hold the merge lock for target
+func (wm *WorktreeManager) mergeDetached(ctx context.Context, target, branchName, message string) error {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "refs/heads/"+target)
+	cmd.Dir = wm.baseDir
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", target, err)
 	}
+	oldHead := strings.TrimSpace(string(output))
 
-	// Merge the branch
-	cmd = exec.Command("git", "merge", "--no-ff", branchName, "-m", fmt.Sprintf("drover: Merge %s", taskID))
+	scratch := filepath.Join(wm.worktreeDir, ".merge-"+strings.ReplaceAll(target, "/", "-"))
+	wm.removeScratch(scratch)
+	defer wm.removeScratch(scratch)
+
+	cmd = exec.CommandContext(ctx, "git", "worktree", "add", "--detach", scratch, oldHead)
 	cmd.Dir = wm.baseDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("creating merge worktree for %s: %w\n%s", target, err, output)
+	}
+
+	cmd = worktreeGit(ctx, scratch, "merge", "--no-ff", branchName, "-m", message)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("merging: %w\n%s", err, output)
 	}
 
-	// Delete the branch after successful merge
-	cmd = exec.Command("git", "branch", "-d", branchName)
+	cmd = worktreeGit(ctx, scratch, "update-ref", "-m", message, "refs/heads/"+target, "HEAD", oldHead)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("advancing %s: %w\n%s", target, err, output)
+	}
+	return nil
+}
+
+// removeScratch force-removes a scratch merge worktree, ignoring errors
+func (wm *WorktreeManager) removeScratch(path string) {
+	cmd := exec.Command("git", "worktree", "remove", "--force", path)
 	cmd.Dir = wm.baseDir
-	_, _ = cmd.CombinedOutput() // Ignore errors on branch delete
+	_, _ = cmd.CombinedOutput()
+	_ = os.RemoveAll(path)
+}
 
-	return nil
+// splitNUL splits the -z output of git into its paths, which -z leaves
+// unquoted whatever characters they hold
+func splitNUL(output []byte) []string {
+	var paths []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// worktreeGit is a git command run in a worktree or clone an agent worked in.
+// Hooks and fsmonitor are turned off, so nothing the agent wrote into the
+// repository's hooks or config runs on the host
+func worktreeGit(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-c", "core.hooksPath=/dev/null", "-c", "core.fsmonitor=false"}, args...)...)
+	cmd.Dir = dir
+	return cmd
+}
+
+// checkedOutBranch returns the branch checked out in dir, or "" if HEAD is detached
+func checkedOutBranch(dir string) string {
+	cmd := worktreeGit(context.Background(), dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// PushBranch pushes the task branch to the given remote instead of merging it locally
+// Used in PR mode; returns the pushed commit SHA
+func (wm *WorktreeManager) PushBranch(taskID, remote string) (string, error) {
+	return wm.PushBranchWithContext(context.Background(), taskID, remote)
+}
+
+// PushBranchWithContext is PushBranch bounded by ctx and the network timeout
+// (see SetNetworkTimeout)
+func (wm *WorktreeManager) PushBranchWithContext(ctx context.Context, taskID, remote string) (string, error) {
+	ctx, cancel := wm.networkContext(ctx)
+	defer cancel()
+
+	worktreePath := wm.Path(taskID)
+	branchName := wm.BranchName(taskID)
+	if remote == "" {
+		remote = "origin"
+	}
+
+	cmd := worktreeGit(ctx, worktreePath, "rev-parse", "HEAD")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("resolving branch head: %w", err)
+	}
+	sha := strings.TrimSpace(string(output))
+
+	// In clone mode "origin" is the local base repo, so push to the base repo's remote URL instead
+	target := remote
+	if isClone(worktreePath) {
+		cmd = exec.CommandContext(ctx, "git", "remote", "get-url", remote)
+		cmd.Dir = wm.baseDir
+		url, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("resolving remote %s: %w", remote, err)
+		}
+		target = strings.TrimSpace(string(url))
+	}
+
+	// Force is safe: drover owns drover-* branches and retries rebuild them from scratch
+	cmd = worktreeGit(ctx, worktreePath, "push", "--force", target, branchName+":"+branchName)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("pushing branch: %w\n%s", err, output)
+	}
+
+	if wm.verbose {
+		log.Printf("📤 Pushed %s (%s) to %s", branchName, sha, remote)
+	}
+
+	return sha, nil
 }
 
 // Cleanup removes all worktrees
@@ -322,14 +802,76 @@ func (wm *WorktreeManager) Cleanup() error {
 		}
 	}
 
+	// Clones are not listed by git worktree, remove them directly
+	onDisk, _ := wm.ListWorktreesOnDisk()
+	for _, taskID := range onDisk {
+		if clonePath := filepath.Join(wm.worktreeDir, taskID); isClone(clonePath) {
+			_ = os.RemoveAll(clonePath)
+		}
+	}
+
 	return nil
 }
 
-// Path returns the worktree path for a task
+// Path returns the worktree path for a task: the pool worktree lent to it,
+// if any, else its own
 func (wm *WorktreeManager) Path(taskID string) string {
+	wm.branchMu.Lock()
+	path, ok := wm.lent[taskID]
+	wm.branchMu.Unlock()
+	if ok {
+		return path
+	}
 	return filepath.Join(wm.worktreeDir, taskID)
 }
 
+// lend records that a task works in a pool worktree, so committing, merging
+// and pushing its work find the worktree and its branch
+func (wm *WorktreeManager) lend(taskID, path, branch string) {
+	wm.branchMu.Lock()
+	defer wm.branchMu.Unlock()
+	if wm.lent == nil {
+		wm.lent = make(map[string]string)
+	}
+	wm.lent[taskID] = path
+	if wm.branches == nil {
+		wm.branches = make(map[string]string)
+	}
+	wm.branches[taskID] = branch
+}
+
+// unlend forgets the pool worktree lent to a task once the pool has it back
+func (wm *WorktreeManager) unlend(taskID string) {
+	wm.branchMu.Lock()
+	defer wm.branchMu.Unlock()
+	if _, ok := wm.lent[taskID]; !ok {
+		return
+	}
+	delete(wm.lent, taskID)
+	delete(wm.branches, taskID)
+}
+
+// UncommittedFiles counts the files with uncommitted changes in a task's
+// worktree, including untracked ones. A missing worktree has none
+func (wm *WorktreeManager) UncommittedFiles(taskID string) (int, error) {
+	path := wm.Path(taskID)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return 0, nil
+	}
+	cmd := worktreeGit(context.Background(), path, "status", "--porcelain", "--untracked-files=all")
+	output, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("checking worktree status: %w", err)
+	}
+	count := 0
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) != "" {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // Directories to clean up aggressively (build artifacts and dependencies)
 // These can consume massive amounts of disk space
 var aggressiveCleanupDirs = []string{
@@ -400,6 +942,7 @@ func (wm *WorktreeManager) RemoveAggressive(taskID string) (int64, error) {
 	cmd.Dir = wm.baseDir
 	_ = cmd.Run() // Ignore errors
 
+	wm.recordRemoved(taskID)
 	return sizeFreed, nil
 }
 
@@ -454,7 +997,7 @@ func (wm *WorktreeManager) getDirectorySize(path string) (int64, error) {
 
 // GetDiskUsage returns the disk usage of a specific worktree
 func (wm *WorktreeManager) GetDiskUsage(taskID string) (int64, error) {
-	worktreePath := filepath.Join(wm.worktreeDir, taskID)
+	worktreePath := wm.Path(taskID)
 	return wm.getDirectorySize(worktreePath)
 }
 
@@ -534,6 +1077,10 @@ func (wm *WorktreeManager) ListOrphaned() ([]string, error) {
 	var orphaned []string
 	for _, taskID := range onDisk {
 		worktreePath := filepath.Join(wm.worktreeDir, taskID)
+		// Clones are never registered; they are tracked by the orchestrator instead
+		if isClone(worktreePath) {
+			continue
+		}
 		// If not registered, it's orphaned
 		if !registeredPaths[worktreePath] {
 			orphaned = append(orphaned, taskID)
@@ -654,7 +1201,7 @@ func formatBytes(bytes int64) string {
 
 // GetBuildArtifactSizes returns the sizes of common build artifact directories
 func (wm *WorktreeManager) GetBuildArtifactSizes(taskID string) (map[string]int64, error) {
-	worktreePath := filepath.Join(wm.worktreeDir, taskID)
+	worktreePath := wm.Path(taskID)
 	sizes := make(map[string]int64)
 
 	for _, dirName := range aggressiveCleanupDirs {
@@ -2,11 +2,15 @@
 package git_test
 
 import (
+	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/cloud-shuttle/drover/internal/git"
 	"github.com/cloud-shuttle/drover/pkg/types"
@@ -197,6 +201,156 @@ func TestWorktreeManager_Commit_WithChanges(t *testing.T) {
 	}
 }
 
+// TestWorktreeManager_Commit_IgnoresHooks verifies a hook planted in the
+// repository doesn't run when drover commits a task's work
+func TestWorktreeManager_Commit_IgnoresHooks(t *testing.T) {
+	repoDir, wm := setupTestRepo(t)
+
+	task := &types.Task{ID: "task-hook", Title: "Test Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+
+	ran := filepath.Join(t.TempDir(), "hook-ran")
+	hook := filepath.Join(repoDir, ".git", "hooks", "pre-commit")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\ntouch "+ran+"\nexit 1\n"), 0755); err != nil {
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "test.txt"), []byte("test content\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	hasChanges, err := wm.Commit(task.ID, "test commit")
+	if err != nil || !hasChanges {
+		t.Fatalf("Expected the commit to succeed, got %v, %v", hasChanges, err)
+	}
+	if _, err := os.Stat(ran); err == nil {
+		t.Error("Expected the pre-commit hook not to run")
+	}
+}
+
+// TestWorktreeManager_ChangedFiles verifies paths with spaces and non-ASCII
+// characters are listed whole
+func TestWorktreeManager_ChangedFiles(t *testing.T) {
+	_, wm := setupTestRepo(t)
+
+	task := &types.Task{ID: "task-changed", Title: "Test Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+
+	for _, name := range []string{"docs/read me.md", "naïve.txt"} {
+		path := filepath.Join(worktreePath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("content\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if _, err := wm.Commit(task.ID, "test commit"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	got, err := wm.ChangedFiles(context.Background(), task.ID)
+	if err != nil {
+		t.Fatalf("ChangedFiles() error = %v", err)
+	}
+	if want := []string{"docs/read me.md", "naïve.txt"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("ChangedFiles() = %q, want %q", got, want)
+	}
+}
+
+// TestWorktreeManager_UncommittedFiles verifies uncommitted changes are counted
+func TestWorktreeManager_UncommittedFiles(t *testing.T) {
+	_, wm := setupTestRepo(t)
+
+	task := &types.Task{
+		ID:    "task-dirty",
+		Title: "Test Task",
+	}
+
+	// A worktree that doesn't exist has nothing uncommitted
+	if n, err := wm.UncommittedFiles(task.ID); err != nil || n != 0 {
+		t.Fatalf("UncommittedFiles before create = %d, %v; want 0, nil", n, err)
+	}
+
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(worktreePath, name), []byte("work\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	if n, err := wm.UncommittedFiles(task.ID); err != nil || n != 2 {
+		t.Errorf("UncommittedFiles = %d, %v; want 2, nil", n, err)
+	}
+
+	if _, err := wm.Commit(task.ID, "save work"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if n, err := wm.UncommittedFiles(task.ID); err != nil || n != 0 {
+		t.Errorf("UncommittedFiles after commit = %d, %v; want 0, nil", n, err)
+	}
+}
+
+// TestWorktreeManager_AdoptWorktree verifies a task's worktree takes over the
+// work of another worktree, discarding its own
+func TestWorktreeManager_AdoptWorktree(t *testing.T) {
+	_, wm := setupTestRepo(t)
+
+	task := &types.Task{ID: "task-slow", Title: "Test Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+	hedge := &types.Task{ID: "task-slow-hedge", Title: "Test Task"}
+	hedgePath, err := wm.Create(hedge)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(hedge.ID)
+
+	// The task's own, abandoned work
+	if err := os.WriteFile(filepath.Join(worktreePath, "half-done.txt"), []byte("wip\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	// The other worktree's finished work: a new file and an edit
+	if err := os.WriteFile(filepath.Join(hedgePath, "done.txt"), []byte("done\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(hedgePath, "README.md"), []byte("# Edited\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := wm.AdoptWorktree(context.Background(), task.ID, hedgePath); err != nil {
+		t.Fatalf("AdoptWorktree: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktreePath, "half-done.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected the task's own work discarded, got %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(worktreePath, "done.txt")); err != nil || string(data) != "done\n" {
+		t.Errorf("Expected the adopted new file, got %q (%v)", data, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(worktreePath, "README.md")); string(data) != "# Edited\n" {
+		t.Errorf("Expected the adopted edit, got %q", data)
+	}
+
+	hasChanges, err := wm.Commit(task.ID, "adopted work")
+	if err != nil || !hasChanges {
+		t.Errorf("Commit = %v, %v; want the adopted work committed", hasChanges, err)
+	}
+}
+
 // TestWorktreeManager_Commit_NoChanges verifies that committing without changes succeeds
 func TestWorktreeManager_Commit_NoChanges(t *testing.T) {
 	_, wm := setupTestRepo(t)
@@ -425,3 +579,716 @@ func TestWorktreeManager_Cleanup(t *testing.T) {
 		}
 	}
 }
+
+// TestWorktreeManager_CloneMode verifies clone isolation has the same Commit/MergeToMain semantics
+func TestWorktreeManager_CloneMode(t *testing.T) {
+	baseDir, wm := setupTestRepo(t)
+	wm.SetIsolationMode(git.IsolationClone)
+
+	task := &types.Task{
+		ID:    "task-clone",
+		Title: "Test Task",
+	}
+
+	clonePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create clone: %v", err)
+	}
+	defer wm.Remove(task.ID)
+
+	// A clone has a real .git directory, not a gitdir file
+	info, err := os.Stat(filepath.Join(clonePath, ".git"))
+	if err != nil || !info.IsDir() {
+		t.Fatalf("Expected .git directory in clone, got %v", err)
+	}
+
+	if path, err := wm.GetWorktreePath(task.ID); err != nil || path != clonePath {
+		t.Errorf("GetWorktreePath() = %q, %v; want %q", path, err, clonePath)
+	}
+
+	if err := os.WriteFile(filepath.Join(clonePath, "clone.txt"), []byte("from clone\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	hasChanges, err := wm.Commit(task.ID, "clone commit")
+	if err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if !hasChanges {
+		t.Fatal("Expected changes to be committed")
+	}
+
+	if err := wm.MergeToMain(task.ID); err != nil {
+		t.Fatalf("Failed to merge to main: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(baseDir, "clone.txt")); os.IsNotExist(err) {
+		t.Error("File was not merged to main branch")
+	}
+
+	// Clones must not be reported as orphaned worktrees
+	orphaned, err := wm.ListOrphaned()
+	if err != nil {
+		t.Fatalf("Failed to list orphaned: %v", err)
+	}
+	if len(orphaned) != 0 {
+		t.Errorf("Expected no orphaned worktrees, got %v", orphaned)
+	}
+
+	if err := wm.Remove(task.ID); err != nil {
+		t.Fatalf("Failed to remove clone: %v", err)
+	}
+	if _, err := os.Stat(clonePath); !os.IsNotExist(err) {
+		t.Error("Clone directory still exists after removal")
+	}
+}
+
+// TestParseIsolationMode verifies isolation mode validation
+func TestParseIsolationMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    git.IsolationMode
+		wantErr bool
+	}{
+		{"", git.IsolationWorktree, false},
+		{"worktree", git.IsolationWorktree, false},
+		{"clone", git.IsolationClone, false},
+		{"docker", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := git.ParseIsolationMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseIsolationMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseIsolationMode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestWorktreeManager_PushBranch verifies PR mode pushes the task branch to the remote
+func TestWorktreeManager_PushBranch(t *testing.T) {
+	baseDir, wm := setupTestRepo(t)
+
+	remoteDir := t.TempDir()
+	cmd := exec.Command("git", "init", "--bare", remoteDir)
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to init bare remote: %v", err)
+	}
+	cmd = exec.Command("git", "remote", "add", "origin", remoteDir)
+	cmd.Dir = baseDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to add remote: %v", err)
+	}
+
+	task := &types.Task{ID: "task-push", Title: "Test Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+
+	if err := os.WriteFile(filepath.Join(worktreePath, "push.txt"), []byte("push\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := wm.Commit(task.ID, "push commit"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	sha, err := wm.PushBranch(task.ID, "")
+	if err != nil {
+		t.Fatalf("PushBranch() error = %v", err)
+	}
+
+	cmd = exec.Command("git", "rev-parse", "drover-task-push")
+	cmd.Dir = remoteDir
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Branch not found on remote: %v", err)
+	}
+	if got := strings.TrimSpace(string(output)); got != sha {
+		t.Errorf("remote branch at %s, want %s", got, sha)
+	}
+
+	// Main must be untouched in PR mode
+	if _, err := os.Stat(filepath.Join(baseDir, "push.txt")); !os.IsNotExist(err) {
+		t.Error("File should not be merged to main when pushing")
+	}
+}
+
+// TestWorktreeManager_Commit_ProtectedPaths verifies protected changes are unstaged and reported
+func TestWorktreeManager_Commit_ProtectedPaths(t *testing.T) {
+	_, wm := setupTestRepo(t)
+	wm.SetProtectedPaths([]string{".github/workflows/", "*.lock"})
+
+	task := &types.Task{ID: "task-protected", Title: "Test Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+
+	files := map[string]string{
+		".github/workflows/ci.yml":      "on: push\n",
+		".github/workflows/dé ploy.yml": "on: push\n", // Quoted by git without -z
+		"deps/Cargo.lock":               "lock\n",
+		"src/main.go":                   "package main\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(worktreePath, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	hasChanges, err := wm.Commit(task.ID, "mixed changes")
+	var violation *git.ProtectedPathError
+	if !errors.As(err, &violation) {
+		t.Fatalf("Commit() error = %v, want ProtectedPathError", err)
+	}
+	if !hasChanges {
+		t.Error("Expected unprotected changes to be committed")
+	}
+	if len(violation.Paths) != 3 {
+		t.Errorf("violation paths = %v, want 3 entries", violation.Paths)
+	}
+
+	cmd := exec.Command("git", "show", "--name-only", "--format=", "HEAD")
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to show commit: %v", err)
+	}
+	committed := strings.TrimSpace(string(output))
+	if committed != "src/main.go" {
+		t.Errorf("committed files = %q, want only src/main.go", committed)
+	}
+}
+
+// TestWorktreeManager_Commit_OnlyProtectedPaths verifies nothing is committed when every change is protected
+func TestWorktreeManager_Commit_OnlyProtectedPaths(t *testing.T) {
+	_, wm := setupTestRepo(t)
+	wm.SetProtectedPaths([]string{"README.md"})
+
+	task := &types.Task{ID: "task-only-protected", Title: "Test Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+
+	if err := os.WriteFile(filepath.Join(worktreePath, "README.md"), []byte("# Changed\n"), 0644); err != nil {
+		t.Fatalf("Failed to modify README: %v", err)
+	}
+
+	hasChanges, err := wm.Commit(task.ID, "protected only")
+	var violation *git.ProtectedPathError
+	if !errors.As(err, &violation) {
+		t.Fatalf("Commit() error = %v, want ProtectedPathError", err)
+	}
+	if hasChanges {
+		t.Error("Expected no commit when only protected paths changed")
+	}
+}
+
+// TestWorktreeManager_BranchTemplate verifies templated branch names and collision sequencing
+func TestWorktreeManager_BranchTemplate(t *testing.T) {
+	baseDir, wm := setupTestRepo(t)
+	wm.SetBranchTemplate("agents", "{prefix}/{epic}/{slug}")
+
+	first := &types.Task{ID: "task-a", EpicID: "epic-1", Title: "Fix Login: Button!"}
+	if _, err := wm.Create(first); err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(first.ID)
+
+	if got, want := wm.BranchName(first.ID), "agents/epic-1/fix-login-button"; got != want {
+		t.Errorf("BranchName() = %q, want %q", got, want)
+	}
+
+	// Same title in a later run must not clash with the surviving branch
+	second := &types.Task{ID: "task-b", EpicID: "epic-1", Title: "Fix login button"}
+	if _, err := wm.Create(second); err != nil {
+		t.Fatalf("Failed to create second worktree: %v", err)
+	}
+	defer wm.Remove(second.ID)
+
+	if got, want := wm.BranchName(second.ID), "agents/epic-1/fix-login-button-2"; got != want {
+		t.Errorf("BranchName() = %q, want %q", got, want)
+	}
+
+	// Empty placeholders collapse instead of leaving doubled separators
+	third := &types.Task{ID: "task-c", Title: "Docs"}
+	if _, err := wm.Create(third); err != nil {
+		t.Fatalf("Failed to create third worktree: %v", err)
+	}
+	defer wm.Remove(third.ID)
+
+	if got, want := wm.BranchName(third.ID), "agents/docs"; got != want {
+		t.Errorf("BranchName() = %q, want %q", got, want)
+	}
+
+	// Merging uses the templated branch and deletes it afterwards
+	worktreePath := wm.Path(third.ID)
+	if err := os.WriteFile(filepath.Join(worktreePath, "docs.md"), []byte("docs\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := wm.Commit(third.ID, "docs"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if err := wm.MergeToMain(third.ID); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(baseDir, "docs.md")); err != nil {
+		t.Errorf("Templated branch was not merged: %v", err)
+	}
+}
+
+// TestWorktreeManager_BranchName_Default verifies the historical naming is unchanged
+func TestWorktreeManager_BranchName_Default(t *testing.T) {
+	_, wm := setupTestRepo(t)
+
+	if got := wm.BranchName("task-1"); got != "drover-task-1" {
+		t.Errorf("BranchName() = %q, want drover-task-1", got)
+	}
+}
+
+// TestSlugify verifies titles are converted into branch-safe fragments
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Add New York variant":      "add-new-york-variant",
+		"  Fix: crash (on start)! ": "fix-crash-on-start",
+		"Ünïcode title":             "n-code-title",
+		strings.Repeat("word ", 20): "word-word-word-word-word-word-word-word",
+	}
+	for input, want := range tests {
+		if got := git.Slugify(input); got != want {
+			t.Errorf("Slugify(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+// TestWorktreeManager_CommitAuthor verifies task commits are authored as the agent
+func TestWorktreeManager_CommitAuthor(t *testing.T) {
+	_, wm := setupTestRepo(t)
+	wm.SetCommitAuthor(git.RenderCommitAuthor(git.DefaultAuthorName, git.DefaultAuthorEmail, "claude", "claude-sonnet-4"))
+
+	task := &types.Task{ID: "task-author", Title: "Test Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+
+	if err := os.WriteFile(filepath.Join(worktreePath, "agent.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := wm.Commit(task.ID, "agent change"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	cmd := exec.Command("git", "log", "-1", "--format=%an <%ae>|%cn")
+	cmd.Dir = worktreePath
+	output, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("Failed to read commit: %v", err)
+	}
+	got := strings.TrimSpace(string(output))
+	if want := "drover[claude-sonnet-4] <drover+claude@localhost>|Test User"; got != want {
+		t.Errorf("author|committer = %q, want %q", got, want)
+	}
+
+	sha, err := wm.CommitSHA(task.ID)
+	if err != nil || len(sha) != 40 {
+		t.Errorf("CommitSHA() = %q, %v", sha, err)
+	}
+}
+
+// memRecorder records worktree lifecycle events in memory
+type memRecorder struct {
+	events []string
+	setup  time.Duration
+	size   int64
+}
+
+func (r *memRecorder) RecordWorktree(taskID, path, branch string, setup time.Duration) error {
+	r.events = append(r.events, "create:"+taskID+":"+branch)
+	r.setup = setup
+	return nil
+}
+
+func (r *memRecorder) UpdateWorktreeStatus(taskID, status string) error {
+	r.events = append(r.events, status+":"+taskID)
+	return nil
+}
+
+func (r *memRecorder) UpdateWorktreeDiskSize(taskID string, size int64) error {
+	r.size = size
+	return nil
+}
+
+func TestWorktreeManager_Recorder(t *testing.T) {
+	_, wm := setupTestRepo(t)
+	rec := &memRecorder{}
+	wm.SetRecorder(rec)
+
+	task := &types.Task{ID: "task-rec", Title: "Recorded Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	if rec.setup <= 0 {
+		t.Errorf("Expected a positive setup duration, got %v", rec.setup)
+	}
+
+	if err := os.WriteFile(filepath.Join(worktreePath, "work.txt"), []byte("work\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := wm.Commit(task.ID, "work"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if rec.size <= 0 {
+		t.Errorf("Expected disk usage to be recorded after commit, got %d", rec.size)
+	}
+
+	if err := wm.MergeToMain(task.ID); err != nil {
+		t.Fatalf("MergeToMain() error = %v", err)
+	}
+	if err := wm.Remove(task.ID); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	want := []string{"create:task-rec:" + wm.BranchName(task.ID), "merged:task-rec", "removed:task-rec"}
+	if strings.Join(rec.events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", rec.events, want)
+	}
+	if rec.size != 0 {
+		t.Errorf("Expected disk usage to be cleared on removal, got %d", rec.size)
+	}
+}
+
+func TestRenderCommitAuthor(t *testing.T) {
+	a := git.RenderCommitAuthor(git.DefaultAuthorName, git.DefaultAuthorEmail, "codex", "")
+	if a.String() != "drover[codex] <drover+codex@localhost>" {
+		t.Errorf("RenderCommitAuthor() = %q", a.String())
+	}
+	if !git.RenderCommitAuthor("", "", "claude", "").IsZero() {
+		t.Error("Expected empty templates to disable the author override")
+	}
+}
+
+func TestDetectDefaultBranch(t *testing.T) {
+	repoDir, _ := setupTestRepo(t)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+
+	// Only local branch wins, whatever its name
+	run("branch", "-M", "trunk")
+	if got, err := git.DetectDefaultBranch(repoDir, ""); err != nil || got != "trunk" {
+		t.Errorf("DetectDefaultBranch() = %q, %v; want trunk", got, err)
+	}
+	// Task branches of the configured prefix don't count
+	run("branch", "agent-task-0")
+	if got, err := git.DetectDefaultBranch(repoDir, "agent"); err != nil || got != "trunk" {
+		t.Errorf("DetectDefaultBranch() = %q, %v; want trunk", got, err)
+	}
+	run("branch", "-D", "agent-task-0")
+
+	// With several branches a conventional name is chosen; drover branches are ignored
+	run("branch", "feature")
+	run("branch", "drover-task-1")
+	run("branch", "master")
+	if got, err := git.DetectDefaultBranch(repoDir, ""); err != nil || got != "master" {
+		t.Errorf("DetectDefaultBranch() = %q, %v; want master", got, err)
+	}
+
+	// origin/HEAD takes precedence
+	run("update-ref", "refs/remotes/origin/feature", "HEAD")
+	run("symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/feature")
+	if got, err := git.DetectDefaultBranch(repoDir, ""); err != nil || got != "feature" {
+		t.Errorf("DetectDefaultBranch() = %q, %v; want feature", got, err)
+	}
+
+	// Overrides are validated
+	if _, err := git.ResolveTargetBranch(repoDir, "nope", ""); err == nil {
+		t.Error("Expected error for missing target branch override")
+	}
+	if got, err := git.ResolveTargetBranch(repoDir, "trunk", ""); err != nil || got != "trunk" {
+		t.Errorf("ResolveTargetBranch() = %q, %v; want trunk", got, err)
+	}
+}
+
+func TestWorktreeManager_MergeToTargetBranch(t *testing.T) {
+	repoDir, wm := setupTestRepo(t)
+	cmd := exec.Command("git", "branch", "-M", "trunk")
+	cmd.Dir = repoDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to rename branch: %v", err)
+	}
+	wm.SetTargetBranch("trunk")
+
+	task := &types.Task{ID: "task-trunk", Title: "Test Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(worktreePath, "trunk.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := wm.Commit(task.ID, "trunk change"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err := wm.MergeToMain(task.ID); err != nil {
+		t.Fatalf("MergeToMain() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "trunk.txt")); err != nil {
+		t.Errorf("Expected change merged into trunk: %v", err)
+	}
+}
+
+func TestWorktreeManager_MergeConcurrentTargets(t *testing.T) {
+	repoDir, mainMgr := setupTestRepo(t)
+	cmd := exec.Command("git", "branch", "release")
+	cmd.Dir = repoDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Failed to create release branch: %v", err)
+	}
+	mainMgr.SetTargetBranch("main")
+	releaseMgr := git.NewWorktreeManager(repoDir, filepath.Join(repoDir, ".drover", "worktrees"))
+	releaseMgr.SetTargetBranch("release")
+
+	type job struct {
+		wm   *git.WorktreeManager
+		task *types.Task
+	}
+	jobs := []job{
+		{mainMgr, &types.Task{ID: "task-main", Title: "Main change"}},
+		{releaseMgr, &types.Task{ID: "task-release", Title: "Release change"}},
+	}
+	for _, j := range jobs {
+		path, err := j.wm.Create(j.task)
+		if err != nil {
+			t.Fatalf("Failed to create worktree: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(path, j.task.ID+".txt"), []byte("x\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if _, err := j.wm.Commit(j.task.ID, j.task.Title); err != nil {
+			t.Fatalf("Commit() error = %v", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(jobs))
+	for i, j := range jobs {
+		wg.Add(1)
+		go func(i int, j job) {
+			defer wg.Done()
+			errs[i] = j.wm.MergeToMain(j.task.ID)
+		}(i, j)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("MergeToMain() error = %v", err)
+		}
+	}
+
+	// Each target got only its own change, and the base checkout stayed on main
+	files := func(branch string) string {
+		cmd := exec.Command("git", "ls-tree", "--name-only", branch)
+		cmd.Dir = repoDir
+		output, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("Failed to list %s: %v", branch, err)
+		}
+		return string(output)
+	}
+	if got := files("main"); !strings.Contains(got, "task-main.txt") || strings.Contains(got, "task-release.txt") {
+		t.Errorf("main tree = %q", got)
+	}
+	if got := files("release"); !strings.Contains(got, "task-release.txt") || strings.Contains(got, "task-main.txt") {
+		t.Errorf("release tree = %q", got)
+	}
+	cmd = exec.Command("git", "symbolic-ref", "--short", "HEAD")
+	cmd.Dir = repoDir
+	if output, _ := cmd.Output(); strings.TrimSpace(string(output)) != "main" {
+		t.Errorf("Expected base checkout to stay on main, got %q", output)
+	}
+}
+
+func TestWorktreeManager_ContextCancellation(t *testing.T) {
+	repoDir, wm := setupTestRepo(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := wm.CreateWithContext(ctx, &types.Task{ID: "task-cancelled", Title: "Cancelled"}); err == nil {
+		t.Error("Expected CreateWithContext to fail with a cancelled context")
+	}
+
+	task := &types.Task{ID: "task-ctx", Title: "Context Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+	if err := os.WriteFile(filepath.Join(worktreePath, "ctx.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := wm.CommitWithContext(ctx, task.ID, "cancelled"); err == nil {
+		t.Error("Expected CommitWithContext to fail with a cancelled context")
+	}
+	if _, err := wm.CommitWithContext(context.Background(), task.ID, "live"); err != nil {
+		t.Fatalf("CommitWithContext() error = %v", err)
+	}
+
+	if err := wm.MergeToMainWithContext(ctx, task.ID); !errors.Is(err, context.Canceled) {
+		t.Errorf("MergeToMainWithContext() error = %v, want context.Canceled", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "ctx.txt")); !os.IsNotExist(err) {
+		t.Error("Expected nothing merged after cancellation")
+	}
+	if err := wm.MergeToMainWithContext(context.Background(), task.ID); err != nil {
+		t.Fatalf("MergeToMainWithContext() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "ctx.txt")); err != nil {
+		t.Errorf("Expected change merged: %v", err)
+	}
+}
+
+func TestWorktreeManager_WaitObserver(t *testing.T) {
+	_, wm := setupTestRepo(t)
+	var mu sync.Mutex
+	waits := make(map[string]int)
+	wm.SetWaitObserver(func(point string, wait time.Duration) {
+		mu.Lock()
+		waits[point]++
+		mu.Unlock()
+	})
+
+	task := &types.Task{ID: "task-wait", Title: "Wait Task"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+	if err := os.WriteFile(filepath.Join(worktreePath, "wait.txt"), []byte("x\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := wm.Commit(task.ID, "wait"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	if err := wm.MergeToMain(task.ID); err != nil {
+		t.Fatalf("MergeToMain() error = %v", err)
+	}
+
+	if waits[git.WaitMerge] != 1 {
+		t.Errorf("Expected one merge wait observation, got %v", waits)
+	}
+}
+
+func TestInitEmptyRepo(t *testing.T) {
+	repoDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "trunk"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repoDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	// Files already in place must survive the root commit untouched
+	if err := os.WriteFile(filepath.Join(repoDir, "notes.txt"), []byte("keep\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if !git.IsEmptyRepo(repoDir) {
+		t.Fatal("Expected freshly initialized repo to be empty")
+	}
+	branch, err := git.InitEmptyRepo(repoDir, "")
+	if err != nil {
+		t.Fatalf("InitEmptyRepo() error = %v", err)
+	}
+	if branch != "trunk" {
+		t.Errorf("InitEmptyRepo() branch = %q, want trunk", branch)
+	}
+	if git.IsEmptyRepo(repoDir) {
+		t.Error("Expected repo to have history after InitEmptyRepo")
+	}
+	if _, err := os.Stat(filepath.Join(repoDir, "notes.txt")); err != nil {
+		t.Errorf("Expected existing file to be left in place: %v", err)
+	}
+
+	// Task worktrees can now branch from the target
+	wm := git.NewWorktreeManager(repoDir, filepath.Join(repoDir, ".drover", "worktrees"))
+	wm.SetTargetBranch(branch)
+	if _, err := wm.Create(&types.Task{ID: "task-scaffold", Title: "Scaffold"}); err != nil {
+		t.Fatalf("Failed to create worktree after bootstrap: %v", err)
+	}
+	wm.Remove("task-scaffold")
+}
+
+// TestRepoManager_PerRepoWorktrees verifies tasks of another repository get
+// its worktrees and merge into it, not into the primary repository
+func TestRepoManager_PerRepoWorktrees(t *testing.T) {
+	primaryDir, primary := setupTestRepo(t)
+	frontendDir, frontend := setupTestRepo(t)
+
+	repos := git.NewRepoManager(primary)
+	if err := repos.Add("frontend", frontend); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := repos.Add("frontend", frontend); err == nil {
+		t.Error("Expected registering a repository twice to fail")
+	}
+	if got := repos.Names(); len(got) != 1 || got[0] != "frontend" {
+		t.Errorf("Names() = %v, want [frontend]", got)
+	}
+	if wm, err := repos.Get(""); err != nil || wm != primary {
+		t.Errorf("Get(\"\") = %v, %v; want the primary manager", wm, err)
+	}
+	if _, err := repos.Get("backend"); err == nil {
+		t.Error("Expected an unknown repository to fail")
+	}
+
+	wm, err := repos.Get("frontend")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	task := &types.Task{ID: "task-web", Title: "Frontend change", Repo: "frontend"}
+	worktreePath, err := wm.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer wm.Remove(task.ID)
+	if err := os.WriteFile(filepath.Join(worktreePath, "web.txt"), []byte("web\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := wm.Commit(task.ID, "frontend change"); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+	if err := wm.MergeToMain(task.ID); err != nil {
+		t.Fatalf("Failed to merge: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(frontendDir, "web.txt")); err != nil {
+		t.Errorf("Expected the change merged into the frontend repository: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(primaryDir, "web.txt")); !os.IsNotExist(err) {
+		t.Error("Expected the primary repository untouched")
+	}
+}
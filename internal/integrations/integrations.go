@@ -0,0 +1,42 @@
+// Package integrations connects drover to external issue trackers: issues are
+// imported as tasks and epics, and task progress is pushed back to them
+package integrations
+
+import "strings"
+
+// Sources of imported tasks, the prefix of their external refs
+const (
+	SourceJira   = "jira"
+	SourceLinear = "linear"
+)
+
+// ImportedIssue is an issue imported as a task
+type ImportedIssue struct {
+	Key    string
+	TaskID string
+	Title  string
+}
+
+// ImportResult summarizes an import from an issue tracker
+type ImportResult struct {
+	EpicsCreated int
+	Tasks        []ImportedIssue
+	Existing     int      // Issues imported by an earlier run
+	Done         int      // Finished issues, not imported
+	Warnings     []string // Issues or links that couldn't be imported as-is
+}
+
+// Ref formats the external ref stored on an imported task or epic, e.g.
+// "jira:PROJ-123" or "linear:ENG-42"
+func Ref(source, key string) string {
+	return source + ":" + key
+}
+
+// ParseRef splits an external ref into its source and issue key
+func ParseRef(ref string) (source, key string, ok bool) {
+	source, key, ok = strings.Cut(ref, ":")
+	if !ok || source == "" || key == "" {
+		return "", "", false
+	}
+	return source, key, true
+}
@@ -0,0 +1,317 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// jiraPageSize is how many issues are requested per search page
+const jiraPageSize = 100
+
+// jiraFields are the issue fields an import needs
+const jiraFields = "summary,description,issuetype,priority,status,parent,issuelinks"
+
+// JiraClient talks to the Jira REST API (v2, for plain-text descriptions)
+type JiraClient struct {
+	baseURL string // e.g. https://example.atlassian.net
+	email   string // Jira Cloud account; empty for a Data Center personal access token
+	token   string
+	client  *http.Client
+}
+
+// NewJiraClient creates a client. With an email the token is a Jira Cloud API
+// token sent as basic auth; without one it is sent as a bearer token
+func NewJiraClient(baseURL, email, token string) *JiraClient {
+	return &JiraClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		email:   email,
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// BrowseURL returns the web link to an issue
+func (c *JiraClient) BrowseURL(key string) string {
+	return c.baseURL + "/browse/" + key
+}
+
+// JiraIssue is the subset of a Jira issue drover imports
+type JiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string `json:"summary"`
+		Description string `json:"description"`
+		IssueType   struct {
+			Name    string `json:"name"`
+			Subtask bool   `json:"subtask"`
+		} `json:"issuetype"`
+		Priority *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Status struct {
+			Name           string `json:"name"`
+			StatusCategory struct {
+				Key string `json:"key"` // "new", "indeterminate" or "done"
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Parent *struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary   string `json:"summary"`
+				IssueType struct {
+					Name string `json:"name"`
+				} `json:"issuetype"`
+			} `json:"fields"`
+		} `json:"parent"`
+		IssueLinks []struct {
+			Type struct {
+				Name string `json:"name"`
+			} `json:"type"`
+			InwardIssue *struct {
+				Key string `json:"key"`
+			} `json:"inwardIssue"`
+			OutwardIssue *struct {
+				Key string `json:"key"`
+			} `json:"outwardIssue"`
+		} `json:"issuelinks"`
+	} `json:"fields"`
+}
+
+// IsEpic reports whether the issue is an epic
+func (i *JiraIssue) IsEpic() bool {
+	return strings.EqualFold(i.Fields.IssueType.Name, "epic")
+}
+
+// IsDone reports whether the issue is in a done status category
+func (i *JiraIssue) IsDone() bool {
+	return i.Fields.Status.StatusCategory.Key == "done"
+}
+
+// Priority maps the Jira priority onto drover's scale, where 0 is the default
+// and higher is more urgent
+func (i *JiraIssue) Priority() int {
+	if i.Fields.Priority == nil {
+		return 0
+	}
+	switch strings.ToLower(i.Fields.Priority.Name) {
+	case "highest", "blocker":
+		return 2
+	case "high", "critical", "major":
+		return 1
+	case "low", "minor":
+		return -1
+	case "lowest", "trivial":
+		return -2
+	}
+	return 0
+}
+
+// BlockedBy returns the keys of the issues linked as blocking this one
+func (i *JiraIssue) BlockedBy() []string {
+	var keys []string
+	for _, link := range i.Fields.IssueLinks {
+		// Seen from this issue, an inward "Blocks" link reads "is blocked by"
+		if strings.EqualFold(link.Type.Name, "blocks") && link.InwardIssue != nil {
+			keys = append(keys, link.InwardIssue.Key)
+		}
+	}
+	return keys
+}
+
+// Search returns every issue matching a JQL query, following pagination
+func (c *JiraClient) Search(ctx context.Context, jql string) ([]JiraIssue, error) {
+	issues, err := c.searchJQL(ctx, jql)
+	if apiErr, ok := err.(*jiraError); ok && apiErr.status == http.StatusNotFound {
+		// Jira Data Center has no /search/jql; fall back to the offset-paged search
+		return c.searchLegacy(ctx, jql)
+	}
+	return issues, err
+}
+
+// searchJQL pages through /search/jql by token (Jira Cloud)
+func (c *JiraClient) searchJQL(ctx context.Context, jql string) ([]JiraIssue, error) {
+	var issues []JiraIssue
+	token := ""
+	for {
+		query := url.Values{
+			"jql":        {jql},
+			"fields":     {jiraFields},
+			"maxResults": {fmt.Sprint(jiraPageSize)},
+		}
+		if token != "" {
+			query.Set("nextPageToken", token)
+		}
+		var page struct {
+			Issues        []JiraIssue `json:"issues"`
+			NextPageToken string      `json:"nextPageToken"`
+			IsLast        bool        `json:"isLast"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/rest/api/2/search/jql?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		issues = append(issues, page.Issues...)
+		if page.IsLast || page.NextPageToken == "" {
+			return issues, nil
+		}
+		token = page.NextPageToken
+	}
+}
+
+// searchLegacy pages through /search by offset (Jira Data Center)
+func (c *JiraClient) searchLegacy(ctx context.Context, jql string) ([]JiraIssue, error) {
+	var issues []JiraIssue
+	for {
+		query := url.Values{
+			"jql":        {jql},
+			"fields":     {jiraFields},
+			"startAt":    {fmt.Sprint(len(issues))},
+			"maxResults": {fmt.Sprint(jiraPageSize)},
+		}
+		var page struct {
+			Issues []JiraIssue `json:"issues"`
+			Total  int         `json:"total"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/rest/api/2/search?"+query.Encode(), nil, &page); err != nil {
+			return nil, err
+		}
+		issues = append(issues, page.Issues...)
+		if len(page.Issues) == 0 || len(issues) >= page.Total {
+			return issues, nil
+		}
+	}
+}
+
+// Transition moves an issue to the named status through one of the
+// workflow transitions available to it. An issue already in that status is
+// left alone
+func (c *JiraClient) Transition(ctx context.Context, key, status string) error {
+	var available struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	if err := c.do(ctx, http.MethodGet, path, nil, &available); err != nil {
+		return err
+	}
+
+	for _, t := range available.Transitions {
+		if strings.EqualFold(t.To.Name, status) || strings.EqualFold(t.Name, status) {
+			body := map[string]any{"transition": map[string]string{"id": t.ID}}
+			return c.do(ctx, http.MethodPost, path, body, nil)
+		}
+	}
+
+	var issue JiraIssue
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=status", nil, &issue); err != nil {
+		return err
+	}
+	if strings.EqualFold(issue.Fields.Status.Name, status) {
+		return nil
+	}
+	return fmt.Errorf("no transition from %q to %q available for %s", issue.Fields.Status.Name, status, key)
+}
+
+// jiraError is a non-2xx response from the Jira API
+type jiraError struct {
+	status int
+	body   string
+}
+
+func (e *jiraError) Error() string {
+	return fmt.Sprintf("jira: HTTP %d: %s", e.status, e.body)
+}
+
+// do sends a request and decodes the JSON response into out, if given
+func (c *JiraClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshaling request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "drover")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.email != "" {
+		req.SetBasicAuth(c.email, c.token)
+	} else if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("jira: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &jiraError{status: resp.StatusCode, body: strings.TrimSpace(string(msg))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("jira: decoding response: %w", err)
+	}
+	return nil
+}
+
+// JiraSync pushes task status transitions to the Jira issues tasks were
+// imported from
+type JiraSync struct {
+	client     *JiraClient
+	inProgress string // Jira status for a task being worked on
+	done       string // Jira status for a completed task
+}
+
+// NewJiraSync creates a syncer moving issues to the given Jira statuses
+func NewJiraSync(client *JiraClient, inProgress, done string) *JiraSync {
+	if inProgress == "" {
+		inProgress = "In Progress"
+	}
+	if done == "" {
+		done = "Done"
+	}
+	return &JiraSync{client: client, inProgress: inProgress, done: done}
+}
+
+// PushStatus moves the Jira issue behind a task to the status matching a
+// drover status. Tasks not imported from Jira, and drover statuses without a
+// Jira counterpart, are ignored
+func (s *JiraSync) PushStatus(ctx context.Context, task *types.Task, status types.TaskStatus) error {
+	source, key, ok := ParseRef(task.ExternalRef)
+	if !ok || source != SourceJira {
+		return nil
+	}
+	switch status {
+	case types.TaskStatusInProgress:
+		return s.client.Transition(ctx, key, s.inProgress)
+	case types.TaskStatusCompleted:
+		return s.client.Transition(ctx, key, s.done)
+	}
+	return nil
+}
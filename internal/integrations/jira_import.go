@@ -0,0 +1,227 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// JiraImportOptions controls a Jira import
+type JiraImportOptions struct {
+	JQL    string
+	EpicID string // Drover epic for issues that don't belong to a Jira epic
+}
+
+// ImportJira imports the issues matching a JQL query. Epics become drover
+// epics, sub-tasks become sub-tasks of their parent's task, everything else a
+// task; "is blocked by" links become dependencies. Every imported task and
+// epic keeps its Jira key as an external ref, so running the same import again
+// only picks up new issues
+func ImportJira(ctx context.Context, client *JiraClient, store *db.Store, opts JiraImportOptions) (*ImportResult, error) {
+	issues, err := client.Search(ctx, opts.JQL)
+	if err != nil {
+		return nil, fmt.Errorf("searching jira: %w", err)
+	}
+	imp := &jiraImport{
+		client: client,
+		store:  store,
+		opts:   opts,
+		result: &ImportResult{},
+		epics:  make(map[string]string),
+		tasks:  make(map[string]*types.Task),
+	}
+	return imp.run(issues)
+}
+
+// jiraImport is the state of one import
+type jiraImport struct {
+	client *JiraClient
+	store  *db.Store
+	opts   JiraImportOptions
+	result *ImportResult
+	epics  map[string]string      // Jira key -> drover epic ID
+	tasks  map[string]*types.Task // Jira key -> drover task, imported now or before
+}
+
+func (imp *jiraImport) run(issues []JiraIssue) (*ImportResult, error) {
+	var topLevel, subtasks []*JiraIssue
+	for i := range issues {
+		issue := &issues[i]
+		switch {
+		case issue.IsDone():
+			imp.result.Done++
+		case issue.IsEpic():
+			if _, err := imp.epic(issue.Key, issue.Fields.Summary, issue.Fields.Description); err != nil {
+				return imp.result, err
+			}
+		default:
+			existing, err := imp.store.FindTaskByExternalRef(Ref(SourceJira, issue.Key))
+			if err != nil {
+				return imp.result, err
+			}
+			if existing != nil {
+				imp.tasks[issue.Key] = existing
+				imp.result.Existing++
+				continue
+			}
+			if issue.Fields.IssueType.Subtask && issue.Fields.Parent != nil {
+				subtasks = append(subtasks, issue)
+			} else {
+				topLevel = append(topLevel, issue)
+			}
+		}
+	}
+
+	// Create blockers before the tasks they block
+	for len(topLevel) > 0 {
+		var waiting []*JiraIssue
+		for _, issue := range topLevel {
+			if imp.blockersPending(issue, topLevel) {
+				waiting = append(waiting, issue)
+				continue
+			}
+			if err := imp.createTask(issue); err != nil {
+				return imp.result, err
+			}
+		}
+		if len(waiting) == len(topLevel) {
+			// A dependency cycle: import the rest without their remaining links
+			for _, issue := range waiting {
+				imp.warn("%s: dependency cycle, imported without its blockers in the cycle", issue.Key)
+				if err := imp.createTask(issue); err != nil {
+					return imp.result, err
+				}
+			}
+			break
+		}
+		topLevel = waiting
+	}
+
+	for _, issue := range subtasks {
+		if err := imp.createSubtask(issue); err != nil {
+			return imp.result, err
+		}
+	}
+	return imp.result, nil
+}
+
+// epic returns the drover epic for a Jira epic, creating it on first use
+func (imp *jiraImport) epic(key, summary, description string) (string, error) {
+	if id, ok := imp.epics[key]; ok {
+		return id, nil
+	}
+	ref := Ref(SourceJira, key)
+	id, err := imp.store.FindEpicByExternalRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		epic, err := imp.store.CreateEpic(fmt.Sprintf("%s: %s", key, summary), description)
+		if err != nil {
+			return "", fmt.Errorf("creating epic for %s: %w", key, err)
+		}
+		if err := imp.store.SetEpicExternalRef(epic.ID, ref); err != nil {
+			return "", err
+		}
+		id = epic.ID
+		imp.result.EpicsCreated++
+	}
+	imp.epics[key] = id
+	return id, nil
+}
+
+// blockersPending reports whether any of an issue's blockers is among the
+// issues still to be created
+func (imp *jiraImport) blockersPending(issue *JiraIssue, pending []*JiraIssue) bool {
+	for _, key := range issue.BlockedBy() {
+		if _, created := imp.tasks[key]; created {
+			continue
+		}
+		for _, p := range pending {
+			if p.Key == key && p != issue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// blockers returns the drover tasks an issue waits for. Blockers that weren't
+// imported or have already finished are left out
+func (imp *jiraImport) blockers(issue *JiraIssue) []string {
+	var ids []string
+	for _, key := range issue.BlockedBy() {
+		task, ok := imp.tasks[key]
+		if !ok {
+			continue
+		}
+		switch task.Status {
+		case types.TaskStatusCompleted, types.TaskStatusCancelled:
+			continue
+		}
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func (imp *jiraImport) createTask(issue *JiraIssue) error {
+	epicID := imp.opts.EpicID
+	if parent := issue.Fields.Parent; parent != nil && strings.EqualFold(parent.Fields.IssueType.Name, "epic") {
+		id, err := imp.epic(parent.Key, parent.Fields.Summary, "")
+		if err != nil {
+			return err
+		}
+		epicID = id
+	}
+
+	task, err := imp.store.CreateTask(issue.Fields.Summary, imp.description(issue), epicID, issue.Priority(), imp.blockers(issue))
+	if err != nil {
+		return fmt.Errorf("creating task for %s: %w", issue.Key, err)
+	}
+	return imp.imported(issue, task)
+}
+
+func (imp *jiraImport) createSubtask(issue *JiraIssue) error {
+	parent, ok := imp.tasks[issue.Fields.Parent.Key]
+	if !ok {
+		imp.warn("%s: parent %s was not imported, imported as a task", issue.Key, issue.Fields.Parent.Key)
+		return imp.createTask(issue)
+	}
+	if parent.ParentID != "" {
+		imp.warn("%s: parent %s is itself a sub-task, imported as a task", issue.Key, issue.Fields.Parent.Key)
+		return imp.createTask(issue)
+	}
+
+	task, err := imp.store.CreateSubTask(issue.Fields.Summary, imp.description(issue), parent.ID, issue.Priority(), nil)
+	if err != nil {
+		return fmt.Errorf("creating sub-task for %s: %w", issue.Key, err)
+	}
+	return imp.imported(issue, task)
+}
+
+// imported records the Jira key on a newly created task
+func (imp *jiraImport) imported(issue *JiraIssue, task *types.Task) error {
+	task.ExternalRef = Ref(SourceJira, issue.Key)
+	if err := imp.store.SetTaskExternalRef(task.ID, task.ExternalRef); err != nil {
+		return err
+	}
+	imp.tasks[issue.Key] = task
+	imp.result.Tasks = append(imp.result.Tasks, ImportedIssue{Key: issue.Key, TaskID: task.ID, Title: task.Title})
+	return nil
+}
+
+// description is the issue description followed by a link back to the issue
+func (imp *jiraImport) description(issue *JiraIssue) string {
+	link := fmt.Sprintf("Jira: %s", imp.client.BrowseURL(issue.Key))
+	if desc := strings.TrimSpace(issue.Fields.Description); desc != "" {
+		return desc + "\n\n" + link
+	}
+	return link
+}
+
+func (imp *jiraImport) warn(format string, args ...any) {
+	imp.result.Warnings = append(imp.result.Warnings, fmt.Sprintf(format, args...))
+}
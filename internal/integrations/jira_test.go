@@ -0,0 +1,151 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+const searchResponse = `{
+  "isLast": true,
+  "issues": [
+    {"key": "PROJ-1", "fields": {"summary": "Checkout", "issuetype": {"name": "Epic"},
+      "status": {"name": "To Do", "statusCategory": {"key": "new"}}}},
+    {"key": "PROJ-3", "fields": {"summary": "Payment form", "description": "Card fields",
+      "issuetype": {"name": "Story"}, "priority": {"name": "High"},
+      "status": {"name": "To Do", "statusCategory": {"key": "new"}},
+      "parent": {"key": "PROJ-1", "fields": {"summary": "Checkout", "issuetype": {"name": "Epic"}}},
+      "issuelinks": [{"type": {"name": "Blocks"}, "inwardIssue": {"key": "PROJ-2"}}]}},
+    {"key": "PROJ-2", "fields": {"summary": "Payment API", "issuetype": {"name": "Story"},
+      "status": {"name": "To Do", "statusCategory": {"key": "new"}},
+      "parent": {"key": "PROJ-1", "fields": {"summary": "Checkout", "issuetype": {"name": "Epic"}}}}},
+    {"key": "PROJ-4", "fields": {"summary": "Validate card number", "issuetype": {"name": "Sub-task", "subtask": true},
+      "status": {"name": "To Do", "statusCategory": {"key": "new"}},
+      "parent": {"key": "PROJ-3", "fields": {"summary": "Payment form", "issuetype": {"name": "Story"}}}}},
+    {"key": "PROJ-5", "fields": {"summary": "Old work", "issuetype": {"name": "Task"},
+      "status": {"name": "Done", "statusCategory": {"key": "done"}}}}
+  ]
+}`
+
+func setupStore(t *testing.T) *db.Store {
+	t.Helper()
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open test store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to init schema: %v", err)
+	}
+	if err := store.MigrateSchema(); err != nil {
+		t.Fatalf("Failed to migrate schema: %v", err)
+	}
+	return store
+}
+
+func TestImportJira(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/search/jql" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@example.com" || pass != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(searchResponse))
+	}))
+	defer server.Close()
+
+	store := setupStore(t)
+	client := NewJiraClient(server.URL, "me@example.com", "secret")
+	opts := JiraImportOptions{JQL: "project = PROJ"}
+
+	result, err := ImportJira(context.Background(), client, store, opts)
+	if err != nil {
+		t.Fatalf("ImportJira: %v", err)
+	}
+	if result.EpicsCreated != 1 || len(result.Tasks) != 3 || result.Done != 1 {
+		t.Fatalf("result = %+v, want 1 epic, 3 tasks, 1 done", result)
+	}
+
+	api, _ := store.FindTaskByExternalRef("jira:PROJ-2")
+	form, _ := store.FindTaskByExternalRef("jira:PROJ-3")
+	card, _ := store.FindTaskByExternalRef("jira:PROJ-4")
+	if api == nil || form == nil || card == nil {
+		t.Fatalf("missing imported tasks: %v %v %v", api, form, card)
+	}
+	if form.Status != types.TaskStatusBlocked || form.Priority != 1 || form.EpicID != api.EpicID || form.EpicID == "" {
+		t.Errorf("PROJ-3 = status %s, priority %d, epic %q; want blocked, 1, the PROJ-1 epic", form.Status, form.Priority, form.EpicID)
+	}
+	if !strings.Contains(form.Description, server.URL+"/browse/PROJ-3") {
+		t.Errorf("description lacks the issue link: %q", form.Description)
+	}
+	blockedBy, _ := store.GetBlockedBy(form.ID)
+	if len(blockedBy) != 1 || blockedBy[0] != api.ID {
+		t.Errorf("PROJ-3 blocked by %v, want [%s]", blockedBy, api.ID)
+	}
+	if card.ParentID != form.ID {
+		t.Errorf("PROJ-4 parent = %q, want %s", card.ParentID, form.ID)
+	}
+
+	// Importing again adds nothing
+	result, err = ImportJira(context.Background(), client, store, opts)
+	if err != nil {
+		t.Fatalf("second ImportJira: %v", err)
+	}
+	if result.EpicsCreated != 0 || len(result.Tasks) != 0 || result.Existing != 3 {
+		t.Errorf("second import = %+v, want only existing tasks", result)
+	}
+}
+
+func TestJiraSync_PushStatus(t *testing.T) {
+	var moved []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pat" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/PROJ-7/transitions":
+			w.Write([]byte(`{"transitions": [
+				{"id": "11", "name": "Start work", "to": {"name": "In Progress"}},
+				{"id": "31", "name": "Resolve", "to": {"name": "Done"}}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/PROJ-7/transitions":
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			moved = append(moved, body.Transition.ID)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	syncer := NewJiraSync(NewJiraClient(server.URL, "", "pat"), "", "")
+	task := &types.Task{ID: "task-1", ExternalRef: "jira:PROJ-7"}
+	for _, status := range []types.TaskStatus{types.TaskStatusInProgress, types.TaskStatusFailed, types.TaskStatusCompleted} {
+		if err := syncer.PushStatus(context.Background(), task, status); err != nil {
+			t.Fatalf("PushStatus(%s): %v", status, err)
+		}
+	}
+	if strings.Join(moved, ",") != "11,31" {
+		t.Errorf("transitions = %v, want [11 31]", moved)
+	}
+
+	// Tasks that didn't come from Jira are left alone
+	if err := syncer.PushStatus(context.Background(), &types.Task{ID: "task-2"}, types.TaskStatusCompleted); err != nil {
+		t.Errorf("PushStatus on a local task: %v", err)
+	}
+}
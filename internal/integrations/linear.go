@@ -0,0 +1,251 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LinearAPIURL is the Linear GraphQL endpoint
+const LinearAPIURL = "https://api.linear.app/graphql"
+
+// linearPageSize is how many issues are requested per page
+const linearPageSize = 100
+
+// linearIssueFields are the issue fields an import needs
+const linearIssueFields = `
+fragment IssueFields on Issue {
+  id
+  identifier
+  title
+  description
+  priority
+  url
+  state { name type }
+  project { id name description }
+  parent { identifier }
+  labels { nodes { name } }
+  inverseRelations { nodes { type issue { identifier } } }
+}`
+
+// LinearClient talks to the Linear GraphQL API
+type LinearClient struct {
+	endpoint string
+	apiKey   string
+	client   *http.Client
+}
+
+// NewLinearClient creates a client authenticating with a personal API key.
+// An empty endpoint means the public Linear API
+func NewLinearClient(endpoint, apiKey string) *LinearClient {
+	if endpoint == "" {
+		endpoint = LinearAPIURL
+	}
+	return &LinearClient{
+		endpoint: endpoint,
+		apiKey:   apiKey,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// LinearIssue is the subset of a Linear issue drover imports
+type LinearIssue struct {
+	ID          string `json:"id"`
+	Identifier  string `json:"identifier"` // e.g. ENG-42
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Priority    int    `json:"priority"` // 0 none, 1 urgent, 2 high, 3 medium, 4 low
+	URL         string `json:"url"`
+	State       struct {
+		Name string `json:"name"`
+		Type string `json:"type"` // triage, backlog, unstarted, started, completed or canceled
+	} `json:"state"`
+	Project *struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	} `json:"project"`
+	Parent *struct {
+		Identifier string `json:"identifier"`
+	} `json:"parent"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	InverseRelations struct {
+		Nodes []struct {
+			Type  string `json:"type"`
+			Issue struct {
+				Identifier string `json:"identifier"`
+			} `json:"issue"`
+		} `json:"nodes"`
+	} `json:"inverseRelations"`
+}
+
+// IsDone reports whether the issue is completed or canceled
+func (i *LinearIssue) IsDone() bool {
+	return i.State.Type == "completed" || i.State.Type == "canceled"
+}
+
+// HasLabel reports whether the issue carries a label, ignoring case
+func (i *LinearIssue) HasLabel(name string) bool {
+	for _, label := range i.Labels.Nodes {
+		if strings.EqualFold(label.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// DroverPriority maps the Linear priority onto drover's scale, where 0 is the
+// default and higher is more urgent
+func (i *LinearIssue) DroverPriority() int {
+	switch i.Priority {
+	case 1: // Urgent
+		return 2
+	case 2: // High
+		return 1
+	case 4: // Low
+		return -1
+	}
+	return 0
+}
+
+// BlockedBy returns the identifiers of the issues blocking this one
+func (i *LinearIssue) BlockedBy() []string {
+	var keys []string
+	for _, rel := range i.InverseRelations.Nodes {
+		// An inverse "blocks" relation is another issue blocking this one
+		if rel.Type == "blocks" {
+			keys = append(keys, rel.Issue.Identifier)
+		}
+	}
+	return keys
+}
+
+// LinearFilter selects the issues to import. Empty fields match everything
+type LinearFilter struct {
+	Team    string // Team key, e.g. ENG
+	Project string // Project name
+	Label   string // Label name
+}
+
+// graphQL returns the filter as a Linear IssueFilter
+func (f LinearFilter) graphQL() map[string]any {
+	filter := map[string]any{}
+	if f.Team != "" {
+		filter["team"] = map[string]any{"key": map[string]string{"eqIgnoreCase": f.Team}}
+	}
+	if f.Project != "" {
+		filter["project"] = map[string]any{"name": map[string]string{"eqIgnoreCase": f.Project}}
+	}
+	if f.Label != "" {
+		filter["labels"] = map[string]any{"name": map[string]string{"eqIgnoreCase": f.Label}}
+	}
+	return filter
+}
+
+// Issues returns every issue matching a filter, following pagination
+func (c *LinearClient) Issues(ctx context.Context, filter LinearFilter) ([]LinearIssue, error) {
+	query := `query Issues($filter: IssueFilter, $after: String, $first: Int) {
+  issues(filter: $filter, after: $after, first: $first) {
+    nodes { ...IssueFields }
+    pageInfo { hasNextPage endCursor }
+  }
+}` + linearIssueFields
+
+	var issues []LinearIssue
+	vars := map[string]any{"filter": filter.graphQL(), "first": linearPageSize}
+	for {
+		var data struct {
+			Issues struct {
+				Nodes    []LinearIssue `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"issues"`
+		}
+		if err := c.do(ctx, query, vars, &data); err != nil {
+			return nil, err
+		}
+		issues = append(issues, data.Issues.Nodes...)
+		if !data.Issues.PageInfo.HasNextPage || data.Issues.PageInfo.EndCursor == "" {
+			return issues, nil
+		}
+		vars["after"] = data.Issues.PageInfo.EndCursor
+	}
+}
+
+// Issue fetches one issue by ID or identifier
+func (c *LinearClient) Issue(ctx context.Context, id string) (*LinearIssue, error) {
+	query := `query Issue($id: String!) {
+  issue(id: $id) { ...IssueFields }
+}` + linearIssueFields
+
+	var data struct {
+		Issue *LinearIssue `json:"issue"`
+	}
+	if err := c.do(ctx, query, map[string]any{"id": id}, &data); err != nil {
+		return nil, err
+	}
+	if data.Issue == nil {
+		return nil, fmt.Errorf("linear: issue %s not found", id)
+	}
+	return data.Issue, nil
+}
+
+// do runs a GraphQL query and decodes its data into out
+func (c *LinearClient) do(ctx context.Context, query string, vars map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": vars})
+	if err != nil {
+		return fmt.Errorf("marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "drover")
+	// Personal API keys are sent as-is; OAuth tokens need the Bearer scheme
+	req.Header.Set("Authorization", c.apiKey)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("linear: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("linear: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("linear: decoding response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		msgs := make([]string, len(result.Errors))
+		for i, e := range result.Errors {
+			msgs[i] = e.Message
+		}
+		return fmt.Errorf("linear: %s", strings.Join(msgs, "; "))
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return fmt.Errorf("linear: decoding data: %w", err)
+	}
+	return nil
+}
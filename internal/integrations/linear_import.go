@@ -0,0 +1,251 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// LinearImportOptions controls a Linear import
+type LinearImportOptions struct {
+	Filter LinearFilter
+	EpicID string // Drover epic for issues that don't belong to a Linear project
+}
+
+// ImportLinear imports the issues matching a filter. Projects become drover
+// epics, sub-issues become sub-tasks of their parent's task, everything else a
+// task; "blocked by" relations become dependencies. Every imported task and
+// epic keeps its Linear identifier as an external ref, so running the same
+// import again only picks up new issues
+func ImportLinear(ctx context.Context, client *LinearClient, store *db.Store, opts LinearImportOptions) (*ImportResult, error) {
+	issues, err := client.Issues(ctx, opts.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("listing linear issues: %w", err)
+	}
+	return ImportLinearIssues(store, issues, opts.EpicID)
+}
+
+// ImportLinearIssues imports issues already fetched from Linear. Parents and
+// blockers outside the batch are linked when an earlier import created them
+func ImportLinearIssues(store *db.Store, issues []LinearIssue, epicID string) (*ImportResult, error) {
+	imp := &linearImport{
+		store:  store,
+		epicID: epicID,
+		result: &ImportResult{},
+		epics:  make(map[string]string),
+		tasks:  make(map[string]*types.Task),
+	}
+	return imp.run(issues)
+}
+
+// linearImport is the state of one import
+type linearImport struct {
+	store  *db.Store
+	epicID string
+	result *ImportResult
+	epics  map[string]string      // Linear project ID -> drover epic ID
+	tasks  map[string]*types.Task // Linear identifier -> drover task, imported now or before
+}
+
+func (imp *linearImport) run(issues []LinearIssue) (*ImportResult, error) {
+	var topLevel, subtasks []*LinearIssue
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsDone() {
+			imp.result.Done++
+			continue
+		}
+		existing, err := imp.task(issue.Identifier)
+		if err != nil {
+			return imp.result, err
+		}
+		if existing != nil {
+			imp.result.Existing++
+			continue
+		}
+		if issue.Parent != nil {
+			subtasks = append(subtasks, issue)
+		} else {
+			topLevel = append(topLevel, issue)
+		}
+	}
+
+	// Create blockers before the tasks they block
+	for len(topLevel) > 0 {
+		var waiting []*LinearIssue
+		for _, issue := range topLevel {
+			if imp.blockersPending(issue, topLevel) {
+				waiting = append(waiting, issue)
+				continue
+			}
+			if err := imp.createTask(issue); err != nil {
+				return imp.result, err
+			}
+		}
+		if len(waiting) == len(topLevel) {
+			// A dependency cycle: import the rest without their remaining links
+			for _, issue := range waiting {
+				imp.warn("%s: dependency cycle, imported without its blockers in the cycle", issue.Identifier)
+				if err := imp.createTask(issue); err != nil {
+					return imp.result, err
+				}
+			}
+			break
+		}
+		topLevel = waiting
+	}
+
+	for _, issue := range subtasks {
+		if err := imp.createSubtask(issue); err != nil {
+			return imp.result, err
+		}
+	}
+	return imp.result, nil
+}
+
+// task returns the drover task imported for a Linear issue, or nil
+func (imp *linearImport) task(identifier string) (*types.Task, error) {
+	if task, ok := imp.tasks[identifier]; ok {
+		return task, nil
+	}
+	task, err := imp.store.FindTaskByExternalRef(Ref(SourceLinear, identifier))
+	if err != nil {
+		return nil, err
+	}
+	if task != nil {
+		imp.tasks[identifier] = task
+	}
+	return task, nil
+}
+
+// epic returns the drover epic for an issue's Linear project, creating it on
+// first use. Issues without a project go to the import's epic, if any
+func (imp *linearImport) epic(issue *LinearIssue) (string, error) {
+	project := issue.Project
+	if project == nil {
+		return imp.epicID, nil
+	}
+	if id, ok := imp.epics[project.ID]; ok {
+		return id, nil
+	}
+	ref := Ref(SourceLinear, project.ID)
+	id, err := imp.store.FindEpicByExternalRef(ref)
+	if err != nil {
+		return "", err
+	}
+	if id == "" {
+		epic, err := imp.store.CreateEpic(project.Name, project.Description)
+		if err != nil {
+			return "", fmt.Errorf("creating epic for project %s: %w", project.Name, err)
+		}
+		if err := imp.store.SetEpicExternalRef(epic.ID, ref); err != nil {
+			return "", err
+		}
+		id = epic.ID
+		imp.result.EpicsCreated++
+	}
+	imp.epics[project.ID] = id
+	return id, nil
+}
+
+// blockersPending reports whether any of an issue's blockers is among the
+// issues still to be created
+func (imp *linearImport) blockersPending(issue *LinearIssue, pending []*LinearIssue) bool {
+	for _, key := range issue.BlockedBy() {
+		if _, created := imp.tasks[key]; created {
+			continue
+		}
+		for _, p := range pending {
+			if p.Identifier == key && p != issue {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// blockers returns the drover tasks an issue waits for. Blockers that weren't
+// imported or have already finished are left out
+func (imp *linearImport) blockers(issue *LinearIssue) ([]string, error) {
+	var ids []string
+	for _, key := range issue.BlockedBy() {
+		task, err := imp.task(key)
+		if err != nil {
+			return nil, err
+		}
+		if task == nil {
+			continue
+		}
+		switch task.Status {
+		case types.TaskStatusCompleted, types.TaskStatusCancelled:
+			continue
+		}
+		ids = append(ids, task.ID)
+	}
+	return ids, nil
+}
+
+func (imp *linearImport) createTask(issue *LinearIssue) error {
+	epicID, err := imp.epic(issue)
+	if err != nil {
+		return err
+	}
+	blockers, err := imp.blockers(issue)
+	if err != nil {
+		return err
+	}
+
+	task, err := imp.store.CreateTask(issue.Title, linearDescription(issue), epicID, issue.DroverPriority(), blockers)
+	if err != nil {
+		return fmt.Errorf("creating task for %s: %w", issue.Identifier, err)
+	}
+	return imp.imported(issue, task)
+}
+
+func (imp *linearImport) createSubtask(issue *LinearIssue) error {
+	parent, err := imp.task(issue.Parent.Identifier)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		imp.warn("%s: parent %s was not imported, imported as a task", issue.Identifier, issue.Parent.Identifier)
+		return imp.createTask(issue)
+	}
+	if parent.ParentID != "" {
+		imp.warn("%s: parent %s is itself a sub-task, imported as a task", issue.Identifier, issue.Parent.Identifier)
+		return imp.createTask(issue)
+	}
+
+	task, err := imp.store.CreateSubTask(issue.Title, linearDescription(issue), parent.ID, issue.DroverPriority(), nil)
+	if err != nil {
+		return fmt.Errorf("creating sub-task for %s: %w", issue.Identifier, err)
+	}
+	return imp.imported(issue, task)
+}
+
+// imported records the Linear identifier on a newly created task
+func (imp *linearImport) imported(issue *LinearIssue, task *types.Task) error {
+	task.ExternalRef = Ref(SourceLinear, issue.Identifier)
+	if err := imp.store.SetTaskExternalRef(task.ID, task.ExternalRef); err != nil {
+		return err
+	}
+	imp.tasks[issue.Identifier] = task
+	imp.result.Tasks = append(imp.result.Tasks, ImportedIssue{Key: issue.Identifier, TaskID: task.ID, Title: task.Title})
+	return nil
+}
+
+func (imp *linearImport) warn(format string, args ...any) {
+	imp.result.Warnings = append(imp.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+// linearDescription is the issue description followed by a link back to the issue
+func linearDescription(issue *LinearIssue) string {
+	link := fmt.Sprintf("Linear: %s", issue.URL)
+	if desc := strings.TrimSpace(issue.Description); desc != "" {
+		return desc + "\n\n" + link
+	}
+	return link
+}
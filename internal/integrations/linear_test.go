@@ -0,0 +1,165 @@
+package integrations
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+const linearIssuesPage1 = `{"data": {"issues": {
+  "pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+  "nodes": [
+    {"id": "u3", "identifier": "ENG-3", "title": "Payment form", "description": "Card fields", "priority": 2,
+      "url": "https://linear.app/acme/issue/ENG-3", "state": {"type": "unstarted"},
+      "project": {"id": "p1", "name": "Checkout", "description": "New checkout"},
+      "labels": {"nodes": [{"name": "drover"}]},
+      "inverseRelations": {"nodes": [{"type": "blocks", "issue": {"identifier": "ENG-2"}}, {"type": "related", "issue": {"identifier": "ENG-9"}}]}},
+    {"id": "u4", "identifier": "ENG-4", "title": "Validate card number", "priority": 4,
+      "url": "https://linear.app/acme/issue/ENG-4", "state": {"type": "backlog"},
+      "parent": {"identifier": "ENG-3"}, "labels": {"nodes": [{"name": "drover"}]}}
+  ]}}}`
+
+const linearIssuesPage2 = `{"data": {"issues": {
+  "pageInfo": {"hasNextPage": false},
+  "nodes": [
+    {"id": "u2", "identifier": "ENG-2", "title": "Payment API", "priority": 1,
+      "url": "https://linear.app/acme/issue/ENG-2", "state": {"type": "started"},
+      "project": {"id": "p1", "name": "Checkout"}, "labels": {"nodes": [{"name": "Drover"}]}},
+    {"id": "u5", "identifier": "ENG-5", "title": "Old work",
+      "url": "https://linear.app/acme/issue/ENG-5", "state": {"type": "completed"}}
+  ]}}}`
+
+// linearServer answers issue list queries in two pages and single issue
+// queries with ENG-7
+func linearServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		switch {
+		case strings.HasPrefix(req.Query, "query Issues"):
+			if req.Variables["after"] == "c1" {
+				w.Write([]byte(linearIssuesPage2))
+			} else {
+				w.Write([]byte(linearIssuesPage1))
+			}
+		case strings.HasPrefix(req.Query, "query Issue"):
+			labels := `[{"name": "drover"}]`
+			if req.Variables["id"] == "unlabeled" {
+				labels = `[]`
+			}
+			w.Write([]byte(`{"data": {"issue": {"id": "u7", "identifier": "ENG-7", "title": "Webhook issue",
+				"url": "https://linear.app/acme/issue/ENG-7", "state": {"type": "unstarted"},
+				"labels": {"nodes": ` + labels + `}}}}`))
+		default:
+			w.Write([]byte(`{"errors": [{"message": "unknown query"}]}`))
+		}
+	}))
+}
+
+func TestImportLinear(t *testing.T) {
+	server := linearServer(t)
+	defer server.Close()
+
+	store := setupStore(t)
+	client := NewLinearClient(server.URL, "lin_api_key")
+	opts := LinearImportOptions{Filter: LinearFilter{Team: "ENG", Label: "drover"}}
+
+	result, err := ImportLinear(context.Background(), client, store, opts)
+	if err != nil {
+		t.Fatalf("ImportLinear: %v", err)
+	}
+	if result.EpicsCreated != 1 || len(result.Tasks) != 3 || result.Done != 1 {
+		t.Fatalf("result = %+v, want 1 epic, 3 tasks, 1 done", result)
+	}
+
+	api, _ := store.FindTaskByExternalRef("linear:ENG-2")
+	form, _ := store.FindTaskByExternalRef("linear:ENG-3")
+	card, _ := store.FindTaskByExternalRef("linear:ENG-4")
+	if api == nil || form == nil || card == nil {
+		t.Fatalf("missing imported tasks: %v %v %v", api, form, card)
+	}
+	if api.Priority != 2 || form.Priority != 1 || card.Priority != -1 {
+		t.Errorf("priorities = %d %d %d, want 2 1 -1", api.Priority, form.Priority, card.Priority)
+	}
+	if form.Status != types.TaskStatusBlocked || form.EpicID != api.EpicID || form.EpicID == "" {
+		t.Errorf("ENG-3 = status %s, epic %q; want blocked, the Checkout epic", form.Status, form.EpicID)
+	}
+	if !strings.Contains(form.Description, "https://linear.app/acme/issue/ENG-3") {
+		t.Errorf("description lacks the issue link: %q", form.Description)
+	}
+	if card.ParentID != form.ID {
+		t.Errorf("ENG-4 parent = %q, want %s", card.ParentID, form.ID)
+	}
+
+	// Importing again adds nothing
+	result, err = ImportLinear(context.Background(), client, store, opts)
+	if err != nil {
+		t.Fatalf("second ImportLinear: %v", err)
+	}
+	if result.EpicsCreated != 0 || len(result.Tasks) != 0 || result.Existing != 3 {
+		t.Errorf("second import = %+v, want only existing tasks", result)
+	}
+}
+
+func TestLinearWebhook(t *testing.T) {
+	server := linearServer(t)
+	defer server.Close()
+
+	store := setupStore(t)
+	handler := NewLinearWebhook(NewLinearClient(server.URL, "lin_api_key"), store, "drover", "", "whsec", nil)
+
+	deliver := func(body, signature string) int {
+		req := httptest.NewRequest(http.MethodPost, "/linear", strings.NewReader(body))
+		req.Header.Set("Linear-Signature", signature)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	sign := func(body string) string {
+		h := hmac.New(sha256.New, []byte("whsec"))
+		h.Write([]byte(body))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	created := `{"action": "create", "type": "Issue", "data": {"id": "u7"}}`
+	if code := deliver(created, "bad"); code != http.StatusUnauthorized {
+		t.Errorf("bad signature: HTTP %d, want 401", code)
+	}
+	unlabeled := `{"action": "update", "type": "Issue", "data": {"id": "unlabeled"}}`
+	if code := deliver(unlabeled, sign(unlabeled)); code != http.StatusOK {
+		t.Errorf("unlabeled issue: HTTP %d, want 200", code)
+	}
+	if task, _ := store.FindTaskByExternalRef("linear:ENG-7"); task != nil {
+		t.Fatalf("unlabeled issue was imported as %s", task.ID)
+	}
+
+	for i := 0; i < 2; i++ {
+		if code := deliver(created, sign(created)); code != http.StatusOK {
+			t.Fatalf("labeled issue: HTTP %d, want 200", code)
+		}
+	}
+	tasks, err := store.ListTasks()
+	if err != nil {
+		t.Fatalf("ListTasks: %v", err)
+	}
+	if len(tasks) != 1 || tasks[0].ExternalRef != "linear:ENG-7" || tasks[0].Status != types.TaskStatusReady {
+		t.Errorf("tasks = %+v, want one ready task for ENG-7", tasks)
+	}
+}
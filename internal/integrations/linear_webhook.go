@@ -0,0 +1,109 @@
+package integrations
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/webhooks"
+)
+
+// maxLinearWebhookBody caps the size of an accepted webhook payload
+const maxLinearWebhookBody = 1 << 20
+
+// LinearWebhook receives Linear issue webhooks and imports issues as ready
+// tasks once they carry the automation label, so a running 'drover run' or
+// fleet picks them up
+type LinearWebhook struct {
+	client *LinearClient
+	store  *db.Store
+	label  string // Issues with this label are imported
+	epicID string // Drover epic for issues without a Linear project
+	secret string // Signing secret; empty skips signature checks
+	logger *log.Logger
+
+	mu sync.Mutex // Serializes imports, so one issue isn't imported twice
+}
+
+// NewLinearWebhook creates a webhook handler importing issues labeled label
+func NewLinearWebhook(client *LinearClient, store *db.Store, label, epicID, secret string, logger *log.Logger) *LinearWebhook {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LinearWebhook{
+		client: client,
+		store:  store,
+		label:  label,
+		epicID: epicID,
+		secret: secret,
+		logger: logger,
+	}
+}
+
+// linearWebhookPayload is the envelope of a Linear webhook delivery
+type linearWebhookPayload struct {
+	Action string `json:"action"` // create, update or remove
+	Type   string `json:"type"`   // Issue, Comment, Project, ...
+	Data   struct {
+		ID string `json:"id"`
+	} `json:"data"`
+}
+
+// ServeHTTP handles one webhook delivery. The payload only says which issue
+// changed; the issue itself is fetched from the API, so labels, project and
+// relations are current when it is imported
+func (h *LinearWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxLinearWebhookBody))
+	if err != nil {
+		http.Error(w, "reading body", http.StatusBadRequest)
+		return
+	}
+	if h.secret != "" && !webhooks.VerifySignature(body, r.Header.Get("Linear-Signature"), h.secret) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload linearWebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if payload.Type != "Issue" || payload.Action == "remove" || payload.Data.ID == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	issue, err := h.client.Issue(r.Context(), payload.Data.ID)
+	if err != nil {
+		h.logger.Printf("[linear] fetching issue %s: %v", payload.Data.ID, err)
+		http.Error(w, "fetching issue", http.StatusBadGateway)
+		return
+	}
+	if h.label != "" && !issue.HasLabel(h.label) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	h.mu.Lock()
+	result, err := ImportLinearIssues(h.store, []LinearIssue{*issue}, h.epicID)
+	h.mu.Unlock()
+	if err != nil {
+		h.logger.Printf("[linear] importing %s: %v", issue.Identifier, err)
+		http.Error(w, "importing issue", http.StatusInternalServerError)
+		return
+	}
+	for _, t := range result.Tasks {
+		h.logger.Printf("[linear] %s queued as %s: %s", t.Key, t.TaskID, t.Title)
+	}
+	for _, warning := range result.Warnings {
+		h.logger.Printf("[linear] %s", warning)
+	}
+	w.WriteHeader(http.StatusOK)
+}
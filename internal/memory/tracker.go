@@ -302,3 +302,22 @@ func FormatBytes(bytes int64) string {
 	}
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
+
+// ParseBytes parses a size such as "512", "64K", "32M" or "1.5GB", in powers
+// of 1024, into a byte count
+func ParseBytes(s string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(s))
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "B"), "I")
+	multiplier := int64(1)
+	if n := len(text); n > 0 {
+		if exp := strings.IndexByte("KMGT", text[n-1]); exp >= 0 {
+			multiplier = int64(1) << (10 * (exp + 1))
+			text = strings.TrimSpace(text[:n-1])
+		}
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size %q (want e.g. 512K, 32M or 2G)", s)
+	}
+	return int64(value * float64(multiplier)), nil
+}
@@ -294,3 +294,31 @@ func TestTrackerTrackPeakRSS(t *testing.T) {
 		time.Sleep(10 * time.Millisecond)
 	}
 }
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{"512", 512, false},
+		{"64K", 64 * 1024, false},
+		{"32M", 32 * 1024 * 1024, false},
+		{"32MiB", 32 * 1024 * 1024, false},
+		{"1.5GB", 1536 * 1024 * 1024, false},
+		{" 2g ", 2 * 1024 * 1024 * 1024, false},
+		{"lots", 0, true},
+		{"-1M", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseBytes(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBytes(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Package notify posts run summaries and failure alerts to Slack and Discord
+// incoming webhooks
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a kind of notification
+type Event string
+
+const (
+	EventStart   Event = "start"   // A run started
+	EventFailure Event = "failure" // A task failed for good, or failures parked the run
+	EventEnd     Event = "end"     // A run ended, with its summary
+)
+
+// maxErrorExcerpt bounds the error shown in a failure alert
+const maxErrorExcerpt = 500
+
+// maxListedFailures bounds the failed tasks listed in a run summary
+const maxListedFailures = 10
+
+// maxDiscordContent is the longest message Discord accepts
+const maxDiscordContent = 2000
+
+// Config says where notifications go and which are sent
+type Config struct {
+	SlackWebhookURL   string
+	DiscordWebhookURL string
+	Events            []Event // Notifications to send; empty sends all
+	FailuresOnly      bool    // Skip run starts, and run ends without failed tasks
+	DashboardURL      string  // Linked from every notification
+}
+
+// ParseEvents parses a comma-separated list of events, e.g. "failure,end"
+func ParseEvents(spec string) ([]Event, error) {
+	var list []Event
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		switch e := Event(name); e {
+		case EventStart, EventFailure, EventEnd:
+			list = append(list, e)
+		default:
+			return nil, fmt.Errorf("unknown notification %q (want start, failure or end)", name)
+		}
+	}
+	return list, nil
+}
+
+// RunStart describes a run as it starts
+type RunStart struct {
+	EpicID  string
+	Workers int
+	Tasks   int // Tasks queued for the run; 0 when not known up front
+}
+
+// RunSummary describes how a run ended
+type RunSummary struct {
+	ID        string
+	Outcome   string // finished, drained, parked or interrupted
+	EpicID    string
+	Duration  time.Duration
+	Total     int // Tasks attempted this run
+	Completed int
+	Failed    int
+	CostUSD   float64
+	Failures  []TaskFailure
+}
+
+// TaskFailure is a task that failed, for the run summary
+type TaskFailure struct {
+	ID    string
+	Title string
+	Error string
+}
+
+// target is one chat webhook and the JSON field its message goes in
+type target struct {
+	name  string
+	url   string
+	field string
+	limit int // Longest message accepted; 0 for no limit
+}
+
+// Notifier posts notifications to the configured webhooks. A nil Notifier
+// sends nothing
+type Notifier struct {
+	cfg     Config
+	targets []target
+	client  *http.Client
+	wg      sync.WaitGroup // Failure alerts still being posted
+}
+
+// New creates a notifier, or returns nil when no webhook is configured
+func New(cfg Config) *Notifier {
+	n := &Notifier{cfg: cfg, client: &http.Client{Timeout: 15 * time.Second}}
+	if cfg.SlackWebhookURL != "" {
+		n.targets = append(n.targets, target{name: "Slack", url: cfg.SlackWebhookURL, field: "text"})
+	}
+	if cfg.DiscordWebhookURL != "" {
+		n.targets = append(n.targets, target{name: "Discord", url: cfg.DiscordWebhookURL, field: "content", limit: maxDiscordContent})
+	}
+	if len(n.targets) == 0 {
+		return nil
+	}
+	return n
+}
+
+// wants reports whether notifications of kind e are sent
+func (n *Notifier) wants(e Event) bool {
+	if n == nil {
+		return false
+	}
+	if len(n.cfg.Events) == 0 {
+		return true
+	}
+	for _, want := range n.cfg.Events {
+		if want == e {
+			return true
+		}
+	}
+	return false
+}
+
+// RunStarted posts that a run started, in the background
+func (n *Notifier) RunStarted(run RunStart) {
+	if !n.wants(EventStart) || n.cfg.FailuresOnly {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("🚜 Drover run started")
+	var details []string
+	if run.Tasks > 0 {
+		details = append(details, fmt.Sprintf("%d task(s)", run.Tasks))
+	}
+	if run.Workers > 0 {
+		details = append(details, fmt.Sprintf("%d worker(s)", run.Workers))
+	}
+	if run.EpicID != "" {
+		details = append(details, "epic "+run.EpicID)
+	}
+	if len(details) > 0 {
+		b.WriteString(": " + strings.Join(details, ", "))
+	}
+	n.goPost(n.withDashboard(b.String()))
+}
+
+// TaskFailed posts an alert for a task that failed for good, in the
+// background
+func (n *Notifier) TaskFailed(taskID, title, errMsg string) {
+	if !n.wants(EventFailure) {
+		return
+	}
+	msg := fmt.Sprintf("❌ Task %s failed: %s", taskID, title)
+	if excerpt := excerpt(errMsg, maxErrorExcerpt); excerpt != "" {
+		msg += "\n```\n" + excerpt + "\n```"
+	}
+	n.goPost(n.withDashboard(msg))
+}
+
+// RunParked posts an alert for a run that stopped starting tasks because
+// they kept failing, in the background
+func (n *Notifier) RunParked(reason string) {
+	if !n.wants(EventFailure) {
+		return
+	}
+	n.goPost(n.withDashboard(fmt.Sprintf("🧯 Drover run parked: %s. No new tasks start until it's run again", reason)))
+}
+
+// RunEnded posts the run's summary, once the failure alerts still in flight
+// are out, and returns when it's posted
+func (n *Notifier) RunEnded(run RunSummary) {
+	if n == nil {
+		return
+	}
+	n.wg.Wait()
+	if !n.wants(EventEnd) || (n.cfg.FailuresOnly && run.Failed == 0) {
+		return
+	}
+	n.post(n.withDashboard(summaryMessage(run)))
+}
+
+// summaryMessage renders a run summary
+func summaryMessage(run RunSummary) string {
+	icon := "✅"
+	switch {
+	case run.Outcome == "interrupted":
+		icon = "🛑"
+	case run.Failed > 0:
+		icon = "⚠️"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s Drover run %s %s", icon, run.ID, run.Outcome)
+	if run.Duration > 0 {
+		fmt.Fprintf(&b, " in %v", run.Duration.Round(time.Second))
+	}
+	fmt.Fprintf(&b, ": %d completed, %d failed of %d task(s)", run.Completed, run.Failed, run.Total)
+	if run.CostUSD > 0 {
+		fmt.Fprintf(&b, ", $%.2f", run.CostUSD)
+	}
+	if run.EpicID != "" {
+		fmt.Fprintf(&b, " (epic %s)", run.EpicID)
+	}
+	for i, f := range run.Failures {
+		if i == maxListedFailures {
+			fmt.Fprintf(&b, "\n… and %d more", len(run.Failures)-i)
+			break
+		}
+		fmt.Fprintf(&b, "\n• %s %s", f.ID, f.Title)
+		if line := firstLine(f.Error); line != "" {
+			fmt.Fprintf(&b, ": %s", excerpt(line, 200))
+		}
+	}
+	return b.String()
+}
+
+// withDashboard appends the dashboard link, when there is one
+func (n *Notifier) withDashboard(msg string) string {
+	if n.cfg.DashboardURL == "" {
+		return msg
+	}
+	return msg + "\nDashboard: " + n.cfg.DashboardURL
+}
+
+// goPost posts msg in the background; RunEnded waits for it
+func (n *Notifier) goPost(msg string) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.post(msg)
+	}()
+}
+
+// post sends msg to every webhook, logging rather than failing: a
+// notification that doesn't go out never stops a run
+func (n *Notifier) post(msg string) {
+	for _, t := range n.targets {
+		if err := n.send(t, msg); err != nil {
+			log.Printf("⚠️  Posting %s notification: %v", t.name, err)
+		}
+	}
+}
+
+// send posts one message to one webhook
+func (n *Notifier) send(t target, msg string) error {
+	if t.limit > 0 && len(msg) > t.limit {
+		msg = strings.ToValidUTF8(msg[:t.limit-len("…")], "") + "…"
+	}
+	body, err := json.Marshal(map[string]string{t.field: msg})
+	if err != nil {
+		return fmt.Errorf("marshaling message: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "drover")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(reply)))
+	}
+	return nil
+}
+
+// excerpt returns the start of s, at most n bytes, marking the cut
+func excerpt(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "") + "…"
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
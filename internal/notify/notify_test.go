@@ -0,0 +1,174 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder is a chat webhook collecting the messages posted to it
+type recorder struct {
+	mu       sync.Mutex
+	messages []map[string]string
+}
+
+func (r *recorder) server(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode message: %v", err)
+		}
+		r.mu.Lock()
+		r.messages = append(r.messages, body)
+		r.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func (r *recorder) texts(field string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var texts []string
+	for _, m := range r.messages {
+		texts = append(texts, m[field])
+	}
+	return texts
+}
+
+func TestNew(t *testing.T) {
+	if n := New(Config{DashboardURL: "http://localhost:3847"}); n != nil {
+		t.Errorf("Expected no notifier without a webhook, got %+v", n)
+	}
+	// A nil notifier sends nothing
+	var n *Notifier
+	n.RunStarted(RunStart{Workers: 2})
+	n.TaskFailed("task-1", "Title", "boom")
+	n.RunParked("3 consecutive tasks failed the same way")
+	n.RunEnded(RunSummary{})
+}
+
+func TestParseEvents(t *testing.T) {
+	events, err := ParseEvents("failure, end")
+	if err != nil || len(events) != 2 || events[0] != EventFailure || events[1] != EventEnd {
+		t.Errorf("Expected failure and end, got %v (%v)", events, err)
+	}
+	if events, err := ParseEvents(""); err != nil || events != nil {
+		t.Errorf("Expected no events for an empty list, got %v (%v)", events, err)
+	}
+	if _, err := ParseEvents("start,finish"); err == nil {
+		t.Error("Expected an error for an unknown notification")
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	var slack, discord recorder
+	n := New(Config{
+		SlackWebhookURL:   slack.server(t).URL,
+		DiscordWebhookURL: discord.server(t).URL,
+		DashboardURL:      "http://localhost:3847",
+	})
+
+	n.RunStarted(RunStart{EpicID: "epic-1", Workers: 4, Tasks: 12})
+	n.TaskFailed("task-7", "Add login page", "tests failed\n"+strings.Repeat("x", 2*maxErrorExcerpt))
+	n.RunEnded(RunSummary{
+		ID:        "20261016-142233",
+		Outcome:   "finished",
+		Duration:  90 * time.Second,
+		Total:     12,
+		Completed: 11,
+		Failed:    1,
+		CostUSD:   1.5,
+		Failures:  []TaskFailure{{ID: "task-7", Title: "Add login page", Error: "tests failed\nmore detail"}},
+	})
+
+	texts := slack.texts("text")
+	if len(texts) != 3 {
+		t.Fatalf("Expected 3 Slack messages, got %d: %q", len(texts), texts)
+	}
+	if got := discord.texts("content"); len(got) != 3 {
+		t.Errorf("Expected the same 3 messages on Discord, got %q", got)
+	}
+	for _, text := range texts {
+		if !strings.HasSuffix(text, "\nDashboard: http://localhost:3847") {
+			t.Errorf("Expected a dashboard link, got %q", text)
+		}
+	}
+
+	// The alerts are posted in the background, so they may arrive in either order
+	var alert, summary string
+	for _, text := range texts {
+		switch {
+		case strings.Contains(text, "failed: Add login page"):
+			alert = text
+		case strings.Contains(text, "Drover run 20261016-142233"):
+			summary = text
+		}
+	}
+	if !strings.Contains(alert, "task-7") || !strings.Contains(alert, "tests failed") || len(alert) > maxErrorExcerpt+200 {
+		t.Errorf("Expected an alert with an excerpt of the error, got %q", alert)
+	}
+	if summary != texts[2] {
+		t.Errorf("Expected the summary posted last, got %q", texts)
+	}
+	for _, want := range []string{"finished in 1m30s", "11 completed, 1 failed of 12 task(s)", "$1.50", "• task-7 Add login page: tests failed"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected the summary to contain %q, got %q", want, summary)
+		}
+	}
+	if strings.Contains(summary, "more detail") {
+		t.Errorf("Expected only the first line of each error in the summary, got %q", summary)
+	}
+}
+
+func TestNotifierThresholds(t *testing.T) {
+	var slack recorder
+	url := slack.server(t).URL
+
+	// Only failures: no start, and no summary for a clean run
+	n := New(Config{SlackWebhookURL: url, FailuresOnly: true})
+	n.RunStarted(RunStart{Workers: 1})
+	n.RunEnded(RunSummary{ID: "clean", Outcome: "finished", Total: 3, Completed: 3})
+	n.RunEnded(RunSummary{ID: "failing", Outcome: "finished", Total: 3, Completed: 2, Failed: 1})
+	if texts := slack.texts("text"); len(texts) != 1 || !strings.Contains(texts[0], "failing") {
+		t.Errorf("Expected only the summary of the run with a failure, got %q", texts)
+	}
+
+	// Only the run end: no start, no alerts
+	slack.messages = nil
+	n = New(Config{SlackWebhookURL: url, Events: []Event{EventEnd}})
+	n.RunStarted(RunStart{Workers: 1})
+	n.TaskFailed("task-1", "Title", "boom")
+	n.RunEnded(RunSummary{ID: "run", Outcome: "finished", Total: 1, Failed: 1})
+	if texts := slack.texts("text"); len(texts) != 1 || !strings.Contains(texts[0], "Drover run run") {
+		t.Errorf("Expected only the run summary, got %q", texts)
+	}
+
+	// A parked run alerts as a failure
+	slack.messages = nil
+	n = New(Config{SlackWebhookURL: url, Events: []Event{EventFailure}})
+	n.RunParked("5 consecutive tasks failed the same way (401 unauthorized)")
+	n.RunEnded(RunSummary{ID: "run", Outcome: "parked", Total: 5, Failed: 5})
+	if texts := slack.texts("text"); len(texts) != 1 || !strings.Contains(texts[0], "run parked: 5 consecutive tasks") {
+		t.Errorf("Expected only the parked alert, got %q", texts)
+	}
+}
+
+func TestSendTruncatesForDiscord(t *testing.T) {
+	var discord recorder
+	n := New(Config{DiscordWebhookURL: discord.server(t).URL})
+	failures := make([]TaskFailure, 5)
+	for i := range failures {
+		failures[i] = TaskFailure{ID: "task", Title: strings.Repeat("é", 400)}
+	}
+	n.RunEnded(RunSummary{ID: "big", Outcome: "finished", Failed: 5, Failures: failures})
+	texts := discord.texts("content")
+	if len(texts) != 1 || len(texts[0]) > maxDiscordContent || !strings.HasSuffix(texts[0], "…") {
+		t.Errorf("Expected one message cut to Discord's limit, got %q", texts)
+	}
+}
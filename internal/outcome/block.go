@@ -0,0 +1,104 @@
+package outcome
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// VerdictFence is the info string of the fenced block agents end their reply
+// with to report their verdict
+const VerdictFence = "drover-verdict"
+
+// VerdictInstructions asks an agent to end its reply with a verdict block;
+// prompts append it so every agent reports its outcome the same way
+const VerdictInstructions = "When you are done, end your reply with a verdict block in exactly this form:\n\n" +
+	"```" + VerdictFence + "\n" +
+	`{"verdict": "pass", "reason": "one line on the outcome", "files_changed": ["path/to/file.go"], "followups": ["work left for a later task"]}` + "\n" +
+	"```\n\n" +
+	`Use "pass" when the task is done, "fail" when you couldn't finish it and "blocked" when something outside the task stops it, such as a missing dependency or an unanswered question. ` +
+	"List every file you changed; leave followups empty when nothing is left."
+
+// VerdictBlock is the verdict an agent reports on its own work
+type VerdictBlock struct {
+	Verdict      Verdict  `json:"verdict"`
+	Reason       string   `json:"reason,omitempty"`
+	FilesChanged []string `json:"files_changed,omitempty"`
+	Followups    []string `json:"followups,omitempty"`
+}
+
+// fencedBlockPattern matches a fenced block, whose closing fence may be
+// missing when the output ends inside it
+var fencedBlockPattern = regexp.MustCompile("(?m)^[ \t]*```[ \t]*([a-zA-Z0-9_-]*)[ \t]*\r?\n((?s:.*?))(?:^[ \t]*```[ \t]*$|\\z)")
+
+// trailingCommaPattern matches a comma before a closing bracket, which
+// agents write and JSON doesn't allow
+var trailingCommaPattern = regexp.MustCompile(`,(\s*[}\]])`)
+
+// ParseVerdictBlock reads the verdict block from an agent's output. The last
+// block wins, so one quoted earlier in the conversation doesn't; ok is false
+// when the output has none with a pass, fail or blocked verdict. Besides
+// drover-verdict blocks, json and unlabelled blocks holding a verdict count,
+// as agents don't always keep the label
+func ParseVerdictBlock(output string) (block *VerdictBlock, ok bool) {
+	matches := fencedBlockPattern.FindAllStringSubmatch(output, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		switch strings.ToLower(matches[i][1]) {
+		case VerdictFence, "json", "":
+		default:
+			continue
+		}
+		if block, ok := parseVerdictJSON(matches[i][2]); ok {
+			return block, true
+		}
+	}
+	return nil, false
+}
+
+// parseVerdictJSON parses the body of a verdict block, forgiving the slips
+// agents make: trailing commas, a different case and a single string where
+// a list belongs
+func parseVerdictJSON(body string) (*VerdictBlock, bool) {
+	body = trailingCommaPattern.ReplaceAllString(strings.TrimSpace(body), "$1")
+	var raw struct {
+		Verdict      string          `json:"verdict"`
+		Reason       string          `json:"reason"`
+		FilesChanged json.RawMessage `json:"files_changed"`
+		Followups    json.RawMessage `json:"followups"`
+	}
+	if err := json.Unmarshal([]byte(body), &raw); err != nil {
+		return nil, false
+	}
+	verdict := Verdict(strings.ToLower(strings.TrimSpace(raw.Verdict)))
+	switch verdict {
+	case VerdictPass, VerdictFail, VerdictBlocked:
+	default:
+		return nil, false
+	}
+	return &VerdictBlock{
+		Verdict:      verdict,
+		Reason:       strings.TrimSpace(raw.Reason),
+		FilesChanged: stringList(raw.FilesChanged),
+		Followups:    stringList(raw.Followups),
+	}, true
+}
+
+// stringList reads a list of strings, or a single one, dropping empty
+// entries
+func stringList(raw json.RawMessage) []string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err != nil {
+		var single string
+		if json.Unmarshal(raw, &single) != nil {
+			return nil
+		}
+		list = []string{single}
+	}
+	var kept []string
+	for _, entry := range list {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
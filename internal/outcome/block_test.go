@@ -0,0 +1,68 @@
+package outcome
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVerdictBlock(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   *VerdictBlock
+	}{
+		{
+			name: "verdict block",
+			output: "Done.\n\n```drover-verdict\n" +
+				`{"verdict": "pass", "reason": "added the flag", "files_changed": ["cmd/run.go"], "followups": ["document the flag"]}` +
+				"\n```\n",
+			want: &VerdictBlock{Verdict: VerdictPass, Reason: "added the flag", FilesChanged: []string{"cmd/run.go"}, Followups: []string{"document the flag"}},
+		},
+		{
+			name: "last block wins",
+			output: "```drover-verdict\n{\"verdict\": \"pass\"}\n```\nthen the tests broke\n" +
+				"```drover-verdict\n{\"verdict\": \"fail\", \"reason\": \"tests fail\"}\n```",
+			want: &VerdictBlock{Verdict: VerdictFail, Reason: "tests fail"},
+		},
+		{
+			name:   "json block, trailing comma and upper case",
+			output: "```json\n{\"verdict\": \"BLOCKED\", \"reason\": \"needs an API key\", \"followups\": [\"get a key\",],}\n```",
+			want:   &VerdictBlock{Verdict: VerdictBlocked, Reason: "needs an API key", Followups: []string{"get a key"}},
+		},
+		{
+			name:   "single string for a list",
+			output: "```\n{\"verdict\": \"pass\", \"files_changed\": \"main.go\"}\n```",
+			want:   &VerdictBlock{Verdict: VerdictPass, FilesChanged: []string{"main.go"}},
+		},
+		{
+			name:   "unclosed block at the end",
+			output: "```drover-verdict\n{\"verdict\": \"pass\", \"reason\": \"done\"}\n",
+			want:   &VerdictBlock{Verdict: VerdictPass, Reason: "done"},
+		},
+		{
+			name:   "block of another language is skipped",
+			output: "```drover-verdict\n{\"verdict\": \"pass\"}\n```\n```go\n{\"verdict\": \"fail\"}\n```",
+			want:   &VerdictBlock{Verdict: VerdictPass},
+		},
+		{
+			name:   "unknown verdict",
+			output: "```drover-verdict\n{\"verdict\": \"mostly\"}\n```",
+		},
+		{
+			name:   "no block",
+			output: "Verdict: pass, task completed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseVerdictBlock(tt.output)
+			if ok != (tt.want != nil) {
+				t.Fatalf("ParseVerdictBlock() ok = %v, want %v", ok, tt.want != nil)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseVerdictBlock() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package output applies the configured output style to CLI and log output
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// Style controls how decorative symbols are rendered
+type Style string
+
+const (
+	// StyleEmoji prints output unchanged (default)
+	StyleEmoji Style = "emoji"
+	// StylePlain replaces emoji with short text tags but keeps other Unicode
+	StylePlain Style = "plain"
+	// StyleASCII replaces emoji and box-drawing characters and guarantees 7-bit output
+	StyleASCII Style = "ascii"
+)
+
+// ParseStyle validates an output style string
+// An empty string selects the default emoji style
+func ParseStyle(s string) (Style, error) {
+	switch Style(strings.ToLower(s)) {
+	case "", StyleEmoji:
+		return StyleEmoji, nil
+	case StylePlain:
+		return StylePlain, nil
+	case StyleASCII:
+		return StyleASCII, nil
+	default:
+		return "", fmt.Errorf("unknown output style %q (expected emoji, plain, or ascii)", s)
+	}
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(StyleEmoji)
+}
+
+// SetStyle sets the process-wide output style
+func SetStyle(s Style) {
+	if s == "" {
+		s = StyleEmoji
+	}
+	current.Store(s)
+}
+
+// CurrentStyle returns the process-wide output style
+func CurrentStyle() Style {
+	return current.Load().(Style)
+}
+
+// emojiTags maps emoji used by drover to their text equivalents
+// An empty tag means the emoji is purely decorative and is dropped
+var emojiTags = map[string]string{
+	"✅":  "[ok]",
+	"✓":  "[ok]",
+	"❌":  "[error]",
+	"✗":  "[x]",
+	"⚠️": "[warn]",
+	"⚠":  "[warn]",
+	"⛔":  "[blocked]",
+	"🚫":  "[blocked]",
+	"🚧":  "[blocked]",
+	"🛑":  "[stop]",
+	"❓":  "[?]",
+	"💡":  "[tip]",
+	"🗑️": "[rm]",
+	"🧹":  "[clean]",
+	"🔄":  "[retry]",
+	"♻️": "[recycle]",
+	"↩️": "[undo]",
+	"🔒":  "[lock]",
+	"🔓":  "[unlock]",
+	"🟢":  "[ok]",
+	"🟡":  "[warn]",
+	"🔵":  "[info]",
+	"🐂":  "",
+	"📋":  "",
+	"📝":  "",
+	"🤖":  "",
+	"📦":  "",
+	"🚀":  "",
+	"🎯":  "",
+	"📤":  "",
+	"👷":  "",
+	"📊":  "",
+	"🧪":  "",
+	"👋":  "",
+	"🔗":  "",
+	"📡":  "",
+	"📭":  "",
+	"📚":  "",
+	"⚡":  "",
+	"📁":  "",
+	"🌳":  "",
+	"👻":  "",
+	"📄":  "",
+	"🔍":  "",
+	"💾":  "",
+	"📌":  "",
+	"✨":  "",
+	"🔨":  "",
+}
+
+// asciiReplacements maps non-emoji Unicode symbols to ASCII equivalents
+var asciiReplacements = map[rune]string{
+	'═': "=", '─': "-", '║': "|", '│': "|",
+	'╔': "+", '╗': "+", '╚': "+", '╝': "+", '╠': "+", '╣': "+",
+	'┌': "+", '┐': "+", '└': "`-", '┘': "+", '├': "|-",
+	'•': "*", '→': "->", '←': "<-", '↑': "^", '↓': "v",
+	'█': "#", '░': ".", '…': "...",
+}
+
+// Format renders s in the current output style
+func Format(s string) string {
+	return FormatStyle(s, CurrentStyle())
+}
+
+// FormatStyle renders s in the given output style
+func FormatStyle(s string, style Style) string {
+	if style == StyleEmoji || style == "" {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		if tag, n, ok := matchEmoji(s[i:]); ok {
+			i += n
+			// Drop the separator that followed a removed emoji so lines don't gain a leading space
+			if tag == "" {
+				for i < len(s) && s[i] == ' ' {
+					i++
+				}
+			} else {
+				b.WriteString(tag)
+			}
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if style == StyleASCII && r >= utf8.RuneSelf {
+			if repl, ok := asciiReplacements[r]; ok {
+				b.WriteString(repl)
+			} else if r != '\uFE0F' {
+				b.WriteByte('?')
+			}
+			continue
+		}
+		if r == '\uFE0F' {
+			continue // stray variation selector
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// matchEmoji reports whether s starts with a known emoji, returning its tag and byte length
+func matchEmoji(s string) (string, int, bool) {
+	if s == "" || s[0] < utf8.RuneSelf {
+		return "", 0, false
+	}
+	// Prefer the longest match so "⚠️" wins over "⚠"
+	best := -1
+	var bestTag string
+	for emoji, tag := range emojiTags {
+		if len(emoji) > best && strings.HasPrefix(s, emoji) {
+			best = len(emoji)
+			bestTag = tag
+		}
+	}
+	if best < 0 {
+		return "", 0, false
+	}
+	return bestTag, best, true
+}
+
+// Printf formats according to a format specifier and writes styled output to stdout
+func Printf(format string, a ...any) {
+	fmt.Fprint(os.Stdout, Format(fmt.Sprintf(format, a...)))
+}
+
+// Println writes styled output to stdout followed by a newline
+func Println(a ...any) {
+	fmt.Fprint(os.Stdout, Format(fmt.Sprintln(a...)))
+}
+
+// Print writes styled output to stdout
+func Print(a ...any) {
+	fmt.Fprint(os.Stdout, Format(fmt.Sprint(a...)))
+}
+
+// Sprintf formats according to a format specifier and returns the styled string
+func Sprintf(format string, a ...any) string {
+	return Format(fmt.Sprintf(format, a...))
+}
+
+// styledWriter applies the current style to everything written through it
+type styledWriter struct {
+	w io.Writer
+}
+
+// NewWriter wraps w so all writes are rendered in the current output style
+// Use it with log.SetOutput to route the standard logger through the facade
+func NewWriter(w io.Writer) io.Writer {
+	return &styledWriter{w: w}
+}
+
+// Write implements io.Writer
+func (sw *styledWriter) Write(p []byte) (int, error) {
+	if CurrentStyle() == StyleEmoji {
+		return sw.w.Write(p)
+	}
+	if _, err := io.WriteString(sw.w, Format(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
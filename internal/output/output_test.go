@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Style
+		wantErr bool
+	}{
+		{"", StyleEmoji, false},
+		{"emoji", StyleEmoji, false},
+		{"plain", StylePlain, false},
+		{"ASCII", StyleASCII, false},
+		{"fancy", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseStyle(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStyle(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseStyle(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatStyle(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		style Style
+		want  string
+	}{
+		{"emoji unchanged", "✅ Task done", StyleEmoji, "✅ Task done"},
+		{"plain status tag", "✅ Task done", StylePlain, "[ok] Task done"},
+		{"plain warning with selector", "⚠️  Retrying", StylePlain, "[warn]  Retrying"},
+		{"plain drops decoration", "🐂 Drover Run Complete", StylePlain, "Drover Run Complete"},
+		{"plain keeps box drawing", "═══", StylePlain, "═══"},
+		{"ascii box drawing", "═══", StyleASCII, "==="},
+		{"ascii bullet and arrow", "• a → b", StyleASCII, "* a -> b"},
+		{"ascii unknown rune", "café", StyleASCII, "caf?"},
+		{"ascii error tag", "❌ Failed", StyleASCII, "[error] Failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatStyle(tt.input, tt.style); got != tt.want {
+				t.Errorf("FormatStyle(%q, %q) = %q, want %q", tt.input, tt.style, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	defer SetStyle(StyleEmoji)
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	SetStyle(StyleASCII)
+	msg := "🔄 Retrying task → ready\n"
+	n, err := w.Write([]byte(msg))
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if n != len(msg) {
+		t.Errorf("Write() = %d, want %d", n, len(msg))
+	}
+	if got, want := buf.String(), "[retry] Retrying task -> ready\n"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
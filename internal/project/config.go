@@ -34,10 +34,230 @@ type Config struct {
 	// Labels to apply to all tasks
 	DefaultLabels []string `toml:"default_labels"`
 
+	// Paths agents may not modify (e.g. ".github/workflows/", "infra/")
+	ProtectedPaths []string `toml:"protected_paths"`
+
+	// Command whose findings are fed back to the agent before commit
+	// (detected from go.mod, tsconfig.json or Cargo.toml when empty)
+	DiagnosticsCommand string `toml:"diagnostics_command"`
+
+	// Branch task work is merged into (detected from origin/HEAD when empty)
+	TargetBranch string `toml:"target_branch"`
+
+	// Scaffold task run first when the repository has no commits yet
+	BootstrapTitle       string `toml:"bootstrap_title"`
+	BootstrapDescription string `toml:"bootstrap_description"`
+
+	// Definition of done checked before a task's work is merged: "tests_added",
+	// "docs_updated" and "no_todos" are checked from the diff, any other item is
+	// confirmed by the agent. dod_policy is "block" (default) or "follow_up"
+	DefinitionOfDone []string `toml:"definition_of_done"`
+	DoDPolicy        string   `toml:"dod_policy"`
+
+	// Commands run in a task's worktree after the agent finishes and before
+	// its work is merged, e.g. ["go test ./...", "npm run lint"]. A failing
+	// command fails the attempt and its output guides the retry
+	Verify        []string      `toml:"verify"`
+	VerifyTimeout time.Duration `toml:"verify_timeout"`
+
+	// How new task and epic IDs look: "timestamp" (default), "ulid", "uuid"
+	// (UUIDv7) or "short"
+	IDFormat string `toml:"id_format"`
+
+	// Which ready task workers claim first: "priority" (default; priority,
+	// then age), "fifo", "critical-path" (priority, then the most work waiting
+	// on it), "round-robin" (epics take turns) or a registered custom schedule
+	Schedule string `toml:"schedule"`
+
+	// How failed attempts are retried, as comma-separated settings:
+	// "backoff=30s,max=10m,factor=2,jitter=0.2,on=agent|timeout|rate_limit"
+	RetryPolicy string `toml:"retry_policy"`
+
+	// Models a task falls back to in turn when the agent's model is rate
+	// limited: a model of the agent, such as "haiku", or another agent and
+	// its model, such as "opencode/openai/gpt-4o"
+	ModelFallback []string `toml:"model_fallback"`
+
+	// Output of an agent run kept in memory per stream, e.g. "64M"; a run
+	// over it keeps its start and end and spills the whole of it to the
+	// attempt's log (empty = 32M)
+	MaxOutput string `toml:"max_output"`
+
+	// Other repositories tasks can work in, by the name tasks give as their
+	// repo, e.g. [repos.frontend] with path = "../web"
+	Repos map[string]RepoConfig `toml:"repos"`
+
+	// Environment variables every task's agent runs with, e.g. [env] with
+	// API_BASE_URL = "http://localhost:8080"; a task's own env overrides them
+	Env map[string]string `toml:"env"`
+
+	// The in-house agent run when agent = "custom"
+	CustomAgent CustomAgentConfig `toml:"custom_agent"`
+
+	// Sandbox agents run in, e.g. [sandbox] with type = "docker" and an image
+	Sandbox SandboxConfig `toml:"sandbox"`
+
+	// MCP servers written into each task's worktree before the agent runs,
+	// e.g. [mcp_servers.postgres] with command = "npx" and args
+	MCPServers map[string]MCPServerConfig `toml:"mcp_servers"`
+
+	// Rotation and retention of the attempt logs under .drover/logs, e.g.
+	// [logs] with max_size = "10M"
+	Logs LogsConfig `toml:"logs"`
+
+	// CPU limits for the processes agents run for tasks, so a runaway build
+	// in one worktree doesn't starve the others, e.g. [cpu] with nice = 10
+	CPU CPUConfig `toml:"cpu"`
+
 	// File path where this config was loaded
 	configPath string
 }
 
+// LogsConfig is how the attempt logs under .drover/logs are rotated and how
+// long they're kept
+type LogsConfig struct {
+	// Size a log is rotated at, e.g. "10M" (empty = 10M)
+	MaxSize string `toml:"max_size"`
+
+	// Rotated parts kept per log (0 = 3, -1 = none)
+	MaxFiles int `toml:"max_files"`
+
+	// Logs last written longer ago are removed when a run starts, e.g. "720h"
+	// (0 = kept)
+	Retention time.Duration `toml:"retention"`
+}
+
+// CPUConfig constrains the CPU the processes agents run for tasks get; a
+// task's children, such as the builds and tests it runs, inherit the limits
+type CPUConfig struct {
+	// Niceness the processes run at, 1-19 (0 = drover's own)
+	Nice int `toml:"nice"`
+
+	// GOMAXPROCS set for the processes, bounding the threads of the Go tools
+	// they run (0 = unset)
+	MaxProcs int `toml:"max_procs"`
+
+	// CPU time each task's processes get together, in CPUs, e.g. 1.5;
+	// enforced with a cgroup v2 cpu.max on Linux (0 = unlimited)
+	CPUs float64 `toml:"cpus"`
+
+	// Delegated cgroup v2 directory the tasks' cgroups are created in, e.g.
+	// "/sys/fs/cgroup/user.slice/user-1000.slice/drover" (empty = drover's own
+	// cgroup, which works only where it has no other processes)
+	Cgroup string `toml:"cgroup"`
+}
+
+// CustomAgentConfig is an in-house agent drover runs as a command
+type CustomAgentConfig struct {
+	// Command line, a Go template over the task split into arguments like a
+	// shell would once rendered, e.g.
+	// "mytool --prompt {{.PromptFile}} --cwd {{.Worktree}}"
+	Command string `toml:"command"`
+
+	// Regexp the output must match for the run to succeed
+	SuccessPattern string `toml:"success_pattern"`
+
+	// Regexp failing the run when the output matches it
+	FailurePattern string `toml:"failure_pattern"`
+
+	// Regexp with a "verdict" group (pass, fail or blocked) and an optional
+	// "reason" group reading the agent's verdict from its output
+	VerdictPattern string `toml:"verdict_pattern"`
+}
+
+// SandboxConfig confines the processes agents run for tasks, so task code
+// can't touch the host
+type SandboxConfig struct {
+	// "docker" runs each agent process in a container, "bwrap" under
+	// bubblewrap (empty or "none" = on the host)
+	Type string `toml:"type"`
+
+	// Image the container runs, with the agent installed (docker)
+	Image string `toml:"image"`
+
+	// File, relative to the project directory, with the paths a bubblewrap
+	// sandbox lets agents reach (bwrap; empty = only the task's own)
+	Policy string `toml:"policy"`
+
+	// Extra volumes, e.g. ["~/.cache/go-build:/cache/go-build"]; the task's
+	// worktree is always mounted (docker)
+	Mounts []string `toml:"mounts"`
+
+	// Host variables passed in besides the agents' API keys
+	Env []string `toml:"env"`
+
+	// CPU and memory limits in docker's notation, e.g. "2" and "4g" (docker)
+	CPUs   string `toml:"cpus"`
+	Memory string `toml:"memory"`
+
+	// Run without network access
+	NoNetwork bool `toml:"no_network"`
+}
+
+// SandboxPolicy is what a bubblewrap sandbox lets agents reach besides the
+// task's worktree and caches, kept in the file [sandbox] policy names
+type SandboxPolicy struct {
+	// Host paths agents may read, e.g. a toolchain outside /usr
+	ReadOnly []string `toml:"read_only"`
+
+	// Host paths agents may also write, e.g. "~/.claude" for Claude's sessions
+	ReadWrite []string `toml:"read_write"`
+
+	// Host variables passed in besides the agents' API keys
+	Env []string `toml:"env"`
+
+	// Run without network access
+	NoNetwork bool `toml:"no_network"`
+}
+
+// LoadPolicy reads the sandbox's policy file; an empty policy when there is
+// none
+func (c SandboxConfig) LoadPolicy(projectDir string) (*SandboxPolicy, error) {
+	policy := &SandboxPolicy{}
+	if c.Policy == "" {
+		return policy, nil
+	}
+	path := c.Policy
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectDir, path)
+	}
+	if _, err := toml.DecodeFile(path, policy); err != nil {
+		return nil, fmt.Errorf("reading sandbox policy: %w", err)
+	}
+	return policy, nil
+}
+
+// MCPServerConfig is an MCP server giving agents a project-specific tool
+type MCPServerConfig struct {
+	// Program starting the server and its arguments
+	Command string   `toml:"command"`
+	Args    []string `toml:"args"`
+
+	// Environment the server runs with
+	Env map[string]string `toml:"env"`
+}
+
+// RepoConfig is a repository besides the project's own that tasks can target
+type RepoConfig struct {
+	// Checkout of the repository, absolute or relative to the project directory
+	Path string `toml:"path"`
+
+	// Branch its task work is merged into (detected from origin/HEAD when empty)
+	TargetBranch string `toml:"target_branch"`
+}
+
+// RepoPath returns where a configured repository is checked out
+func (c *Config) RepoPath(projectDir, name string) (string, error) {
+	repo, ok := c.Repos[name]
+	if !ok {
+		return "", fmt.Errorf("unknown repository %q (configure it under [repos] in .drover.toml)", name)
+	}
+	if filepath.IsAbs(repo.Path) {
+		return filepath.Clean(repo.Path), nil
+	}
+	return filepath.Join(projectDir, repo.Path), nil
+}
+
 // ByteSize represents a size in bytes (supports KB, MB, GB suffixes in TOML)
 type ByteSize int64
 
@@ -207,9 +427,65 @@ func (c *Config) Validate() error {
 		"codex":   true,
 		"amp":     true,
 		"opencode": true,
+		"aider":    true,
+		"goose":    true,
+		"custom":   true,
+		"llm":      true,
 	}
 	if c.Agent != "" && !validAgents[c.Agent] {
-		return fmt.Errorf("unknown agent type: %s (valid: claude, codex, amp, opencode)", c.Agent)
+		return fmt.Errorf("unknown agent type: %s (valid: claude, codex, amp, opencode, aider, goose, custom, llm)", c.Agent)
+	}
+	if c.Agent == "custom" && strings.TrimSpace(c.CustomAgent.Command) == "" {
+		return fmt.Errorf("agent \"custom\" needs a command under [custom_agent]")
+	}
+	if c.DoDPolicy != "" && c.DoDPolicy != "block" && c.DoDPolicy != "follow_up" {
+		return fmt.Errorf("unknown dod_policy: %s (valid: block, follow_up)", c.DoDPolicy)
+	}
+	for _, command := range c.Verify {
+		if strings.TrimSpace(command) == "" {
+			return fmt.Errorf("verify commands cannot be empty")
+		}
+	}
+	if c.VerifyTimeout < 0 {
+		return fmt.Errorf("verify_timeout cannot be negative")
+	}
+	switch c.IDFormat {
+	case "", "timestamp", "ulid", "uuid", "short":
+	default:
+		return fmt.Errorf("unknown id_format: %s (valid: timestamp, ulid, uuid, short)", c.IDFormat)
+	}
+	// schedule is checked against the registered schedules when a run starts
+	for name, repo := range c.Repos {
+		if name == "" || strings.ContainsAny(name, `/\`) {
+			return fmt.Errorf("invalid repository name %q", name)
+		}
+		if repo.Path == "" {
+			return fmt.Errorf("repos.%s: path is required", name)
+		}
+	}
+	switch c.Sandbox.Type {
+	case "", "none":
+	case "docker":
+		if strings.TrimSpace(c.Sandbox.Image) == "" {
+			return fmt.Errorf("sandbox type \"docker\" needs an image")
+		}
+	case "bwrap":
+	default:
+		return fmt.Errorf("unknown sandbox type: %s (valid: docker, bwrap, none)", c.Sandbox.Type)
+	}
+	if c.CPU.Nice < 0 || c.CPU.Nice > 19 {
+		return fmt.Errorf("cpu.nice must be between 0 and 19")
+	}
+	if c.CPU.MaxProcs < 0 {
+		return fmt.Errorf("cpu.max_procs cannot be negative")
+	}
+	if c.CPU.CPUs < 0 {
+		return fmt.Errorf("cpu.cpus cannot be negative")
+	}
+	for name, server := range c.MCPServers {
+		if strings.TrimSpace(server.Command) == "" {
+			return fmt.Errorf("mcp_servers.%s: command is required", name)
+		}
 	}
 
 	return nil
@@ -253,3 +529,17 @@ func (c *Config) GetLabels() []string {
 	}
 	return c.DefaultLabels
 }
+
+// GetProtectedPaths returns the project's protected paths merged with extra (global) entries
+func (c *Config) GetProtectedPaths(extra []string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, p := range append(append([]string{}, c.ProtectedPaths...), extra...) {
+		p = strings.TrimSpace(p)
+		if p != "" && !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,88 @@
+package simulate
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// Plan is the order a run would execute the graph in if every task took the
+// same time: waves of tasks started together, each once the one before it
+// finished
+type Plan struct {
+	Workers       int
+	Waves         [][]string // Task IDs per wave, in the order workers claim them
+	Unschedulable []string   // Tasks that never become ready (dependency cycles)
+	MaxParallel   int        // Most tasks that could ever run at once, however many workers
+}
+
+// Parallelism is the mean number of tasks running per wave
+func (p *Plan) Parallelism() float64 {
+	if len(p.Waves) == 0 {
+		return 0
+	}
+	var n int
+	for _, wave := range p.Waves {
+		n += len(wave)
+	}
+	return float64(n) / float64(len(p.Waves))
+}
+
+// PlanWaves lays the graph out in waves for the given number of workers. Each
+// wave starts the highest-priority ready tasks, one per worker, as workers
+// claim them; tasks become ready when everything they wait for finished in
+// an earlier wave. With criticalPath, priority ties go to the tasks with the
+// most work waiting on them, as the critical-path schedule claims. Sub-task
+// steps and not-before times are not modelled
+func PlanWaves(tasks []Task, workers int, criticalPath bool) (*Plan, error) {
+	if workers < 1 {
+		return nil, fmt.Errorf("worker count must be at least 1, got %d", workers)
+	}
+	g := newGraph(tasks)
+	if criticalPath {
+		g.weigh()
+	}
+	plan := &Plan{
+		Workers:       workers,
+		Unschedulable: g.unschedulable(),
+	}
+	for _, wave := range g.waves(workers) {
+		ids := make([]string, len(wave))
+		for i, t := range wave {
+			ids[i] = g.tasks[t].ID
+		}
+		plan.Waves = append(plan.Waves, ids)
+	}
+	for _, wave := range g.waves(len(tasks)) {
+		plan.MaxParallel = max(plan.MaxParallel, len(wave))
+	}
+	return plan, nil
+}
+
+// waves groups the schedulable tasks into waves of at most workers tasks
+func (g *graph) waves(workers int) [][]int {
+	remaining := append([]int(nil), g.blockers...)
+	ready := g.readyQueue()
+	for i, n := range remaining {
+		if n == 0 {
+			heap.Push(ready, i)
+		}
+	}
+
+	var waves [][]int
+	for ready.Len() > 0 {
+		var wave []int
+		for len(wave) < workers && ready.Len() > 0 {
+			wave = append(wave, heap.Pop(ready).(int))
+		}
+		// Dependents unblocked by this wave are ready for the next one
+		for _, i := range wave {
+			for _, d := range g.dependents[i] {
+				if remaining[d]--; remaining[d] == 0 {
+					heap.Push(ready, d)
+				}
+			}
+		}
+		waves = append(waves, wave)
+	}
+	return waves
+}
@@ -0,0 +1,382 @@
+// Package simulate estimates how long and how much a run will take by
+// replaying the task graph many times with task durations drawn from history
+// (Monte Carlo), without executing anything
+package simulate
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"time"
+)
+
+// minHistory is how many historical durations are needed before they are
+// resampled; with fewer, durations are drawn around Config.DefaultDuration
+const minHistory = 5
+
+// Task is one unit of work in the simulated graph
+type Task struct {
+	ID        string
+	Priority  int
+	Order     int           // Tie-breaker within a priority, lower runs first (creation order)
+	BlockedBy []string      // Unfinished tasks that must complete first
+	Steps     int           // Executions the task takes: 1 plus its pending sub-tasks
+	NotBefore time.Duration // How long from now until the task may start
+}
+
+// Config controls a simulation
+type Config struct {
+	Workers         []int           // Worker counts to compare
+	Trials          int             // Monte Carlo trials per worker count
+	History         []time.Duration // Observed durations of completed tasks
+	DefaultDuration time.Duration   // Typical duration when history is too thin
+	CostPerHour     float64         // Agent spend per hour of task execution
+	Seed            uint64          // Random seed, for reproducible reports
+	CriticalPath    bool            // Claim as the critical-path schedule does
+}
+
+// Estimate summarizes the trials for one worker count
+type Estimate struct {
+	Workers     int
+	MakespanP50 time.Duration
+	MakespanP90 time.Duration
+	CostP10     float64
+	CostP50     float64
+	CostP90     float64
+	Utilization float64 // Mean share of worker time spent executing tasks
+}
+
+// Report is the result of a simulation
+type Report struct {
+	Tasks         int      // Tasks simulated
+	Unschedulable []string // Tasks whose dependencies can never complete (cycles)
+	FromHistory   bool     // Durations were resampled from history
+	Estimates     []Estimate
+}
+
+// Run simulates the graph once per trial for every configured worker count
+func Run(tasks []Task, cfg Config) (*Report, error) {
+	if cfg.Trials < 1 {
+		return nil, fmt.Errorf("trials must be at least 1")
+	}
+	if len(cfg.Workers) == 0 {
+		return nil, fmt.Errorf("no worker counts to simulate")
+	}
+	for _, w := range cfg.Workers {
+		if w < 1 {
+			return nil, fmt.Errorf("worker count must be at least 1, got %d", w)
+		}
+	}
+	if len(cfg.History) < minHistory && cfg.DefaultDuration <= 0 {
+		return nil, fmt.Errorf("not enough history; a default duration is required")
+	}
+
+	g := newGraph(tasks)
+	if cfg.CriticalPath {
+		g.weigh()
+	}
+	report := &Report{
+		Tasks:         len(tasks),
+		Unschedulable: g.unschedulable(),
+		FromHistory:   len(cfg.History) >= minHistory,
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed^0x9e3779b97f4a7c15))
+	sample := sampler(cfg, rng)
+
+	for _, workers := range cfg.Workers {
+		makespans := make([]float64, cfg.Trials)
+		costs := make([]float64, cfg.Trials)
+		var utilization float64
+		for i := 0; i < cfg.Trials; i++ {
+			makespan, busy := g.simulate(workers, sample)
+			makespans[i] = makespan.Seconds()
+			costs[i] = busy.Hours() * cfg.CostPerHour
+			if makespan > 0 {
+				utilization += busy.Seconds() / (makespan.Seconds() * float64(workers))
+			}
+		}
+		sort.Float64s(makespans)
+		sort.Float64s(costs)
+		report.Estimates = append(report.Estimates, Estimate{
+			Workers:     workers,
+			MakespanP50: seconds(percentile(makespans, 50)),
+			MakespanP90: seconds(percentile(makespans, 90)),
+			CostP10:     percentile(costs, 10),
+			CostP50:     percentile(costs, 50),
+			CostP90:     percentile(costs, 90),
+			Utilization: utilization / float64(cfg.Trials),
+		})
+	}
+	return report, nil
+}
+
+// Sensitivity compares each worker count with the next smaller one, e.g.
+// "8 workers only 12% faster than 6". Estimates must be in ascending order
+func (r *Report) Sensitivity() []string {
+	var lines []string
+	for i := 1; i < len(r.Estimates); i++ {
+		prev, cur := r.Estimates[i-1], r.Estimates[i]
+		if prev.MakespanP50 <= 0 {
+			continue
+		}
+		gain := 1 - cur.MakespanP50.Seconds()/prev.MakespanP50.Seconds()
+		extra := float64(cur.Workers-prev.Workers) / float64(prev.Workers)
+		switch {
+		case gain < 0.01:
+			lines = append(lines, fmt.Sprintf("%d workers are no faster than %d", cur.Workers, prev.Workers))
+		case gain < extra/2:
+			lines = append(lines, fmt.Sprintf("%d workers only %.0f%% faster than %d", cur.Workers, gain*100, prev.Workers))
+		default:
+			lines = append(lines, fmt.Sprintf("%d workers %.0f%% faster than %d", cur.Workers, gain*100, prev.Workers))
+		}
+	}
+	return lines
+}
+
+// sampler returns a function drawing one task execution's duration: a
+// resampled historical duration, or the default duration scaled by a
+// log-normal factor (median 1) when history is too thin
+func sampler(cfg Config, rng *rand.Rand) func() time.Duration {
+	if len(cfg.History) >= minHistory {
+		history := cfg.History
+		return func() time.Duration {
+			return history[rng.IntN(len(history))]
+		}
+	}
+	base := float64(cfg.DefaultDuration)
+	return func() time.Duration {
+		return time.Duration(base * math.Exp(0.5*rng.NormFloat64()))
+	}
+}
+
+// graph is the dependency graph in the form the simulation walks
+type graph struct {
+	tasks      []Task
+	dependents [][]int  // Indexes of the tasks each task unblocks
+	blockers   []int    // Number of unfinished blockers per task
+	weights    []weight // Downstream weight per task; set for the critical-path schedule
+}
+
+func newGraph(tasks []Task) *graph {
+	index := make(map[string]int, len(tasks))
+	for i, t := range tasks {
+		index[t.ID] = i
+	}
+	g := &graph{
+		tasks:      tasks,
+		dependents: make([][]int, len(tasks)),
+		blockers:   make([]int, len(tasks)),
+	}
+	for i, t := range tasks {
+		for _, id := range t.BlockedBy {
+			// Blockers outside the graph already finished
+			if j, ok := index[id]; ok && j != i {
+				g.dependents[j] = append(g.dependents[j], i)
+				g.blockers[i]++
+			}
+		}
+	}
+	return g
+}
+
+// unschedulable lists the tasks that never become ready, i.e. that sit on or
+// behind a dependency cycle
+func (g *graph) unschedulable() []string {
+	remaining := append([]int(nil), g.blockers...)
+	var queue []int
+	for i, n := range remaining {
+		if n == 0 {
+			queue = append(queue, i)
+		}
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, d := range g.dependents[i] {
+			if remaining[d]--; remaining[d] == 0 {
+				queue = append(queue, d)
+			}
+		}
+	}
+	var stuck []string
+	for i, n := range remaining {
+		if n > 0 {
+			stuck = append(stuck, g.tasks[i].ID)
+		}
+	}
+	return stuck
+}
+
+// weight is how much unfinished work waits on a task
+type weight struct {
+	path    int // Tasks on the longest chain of dependents, the task included
+	blocked int // Tasks waiting on it, directly or through others
+}
+
+// weigh works out every task's downstream weight for the critical-path
+// schedule
+func (g *graph) weigh() {
+	paths := make([]int, len(g.tasks)) // 0 until worked out
+	onChain := make([]bool, len(g.tasks))
+	var longest func(i int) int
+	longest = func(i int) int {
+		if paths[i] > 0 {
+			return paths[i]
+		}
+		onChain[i] = true
+		n := 0
+		for _, d := range g.dependents[i] {
+			// Skip edges back onto the chain: the graph may have cycles
+			if !onChain[d] {
+				n = max(n, longest(d))
+			}
+		}
+		onChain[i] = false
+		paths[i] = n + 1
+		return n + 1
+	}
+
+	g.weights = make([]weight, len(g.tasks))
+	for i := range g.tasks {
+		seen := map[int]bool{i: true}
+		queue := []int{i}
+		for len(queue) > 0 {
+			next := queue[0]
+			queue = queue[1:]
+			for _, d := range g.dependents[next] {
+				if !seen[d] {
+					seen[d] = true
+					queue = append(queue, d)
+				}
+			}
+		}
+		g.weights[i] = weight{path: longest(i), blocked: len(seen) - 1}
+	}
+}
+
+// simulate runs one trial with the given number of workers. Ready tasks are
+// started highest priority first, as workers claim them, once their
+// not-before time has passed. Returns the time until the last task finished
+// and the total task execution time
+func (g *graph) simulate(workers int, sample func() time.Duration) (makespan, busy time.Duration) {
+	remaining := append([]int(nil), g.blockers...)
+	ready := g.readyQueue()
+	waiting := &finishQueue{} // Unblocked tasks held until their not-before time
+	running := &finishQueue{}
+	var now time.Duration
+
+	unblock := func(i int) {
+		if g.tasks[i].NotBefore > now {
+			heap.Push(waiting, finish{task: i, at: g.tasks[i].NotBefore})
+		} else {
+			heap.Push(ready, i)
+		}
+	}
+	for i, n := range remaining {
+		if n == 0 {
+			unblock(i)
+		}
+	}
+
+	for ready.Len() > 0 || running.Len() > 0 || waiting.Len() > 0 {
+		for waiting.Len() > 0 && (*waiting)[0].at <= now {
+			heap.Push(ready, heap.Pop(waiting).(finish).task)
+		}
+		for running.Len() < workers && ready.Len() > 0 {
+			i := heap.Pop(ready).(int)
+			var d time.Duration
+			for s := 0; s < max(g.tasks[i].Steps, 1); s++ {
+				d += sample()
+			}
+			busy += d
+			heap.Push(running, finish{task: i, at: now + d})
+		}
+
+		// Advance to whichever comes first: a task finishing or one being released
+		if running.Len() == 0 || (waiting.Len() > 0 && (*waiting)[0].at < (*running)[0].at) {
+			now = (*waiting)[0].at
+			continue
+		}
+		done := heap.Pop(running).(finish)
+		now = done.at
+		for _, d := range g.dependents[done.task] {
+			if remaining[d]--; remaining[d] == 0 {
+				unblock(d)
+			}
+		}
+	}
+	return now, busy
+}
+
+// readyQueue orders ready tasks the way workers claim them
+type readyQueue struct {
+	tasks   []Task
+	weights []weight // Break priority ties on downstream weight when set
+	items   []int
+}
+
+// readyQueue returns an empty queue claiming in the graph's schedule
+func (g *graph) readyQueue() *readyQueue {
+	return &readyQueue{tasks: g.tasks, weights: g.weights}
+}
+
+func (q *readyQueue) Len() int { return len(q.items) }
+func (q *readyQueue) Less(i, j int) bool {
+	a, b := q.tasks[q.items[i]], q.tasks[q.items[j]]
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	if q.weights != nil {
+		wa, wb := q.weights[q.items[i]], q.weights[q.items[j]]
+		if wa.path != wb.path {
+			return wa.path > wb.path
+		}
+		if wa.blocked != wb.blocked {
+			return wa.blocked > wb.blocked
+		}
+	}
+	return a.Order < b.Order
+}
+func (q *readyQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+func (q *readyQueue) Push(x any)    { q.items = append(q.items, x.(int)) }
+func (q *readyQueue) Pop() any {
+	last := q.items[len(q.items)-1]
+	q.items = q.items[:len(q.items)-1]
+	return last
+}
+
+// finish is a task and when it completes (or, while waiting, when it is released)
+type finish struct {
+	task int
+	at   time.Duration
+}
+
+// finishQueue orders tasks by their time
+type finishQueue []finish
+
+func (q finishQueue) Len() int           { return len(q) }
+func (q finishQueue) Less(i, j int) bool { return q[i].at < q[j].at }
+func (q finishQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *finishQueue) Push(x any)        { *q = append(*q, x.(finish)) }
+func (q *finishQueue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
+
+// percentile returns the p-th percentile of sorted values (nearest rank)
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,201 @@
+package simulate
+
+import (
+	"container/heap"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fixedHistory makes every task take exactly d
+func fixedHistory(d time.Duration) []time.Duration {
+	history := make([]time.Duration, minHistory)
+	for i := range history {
+		history[i] = d
+	}
+	return history
+}
+
+func TestRun_Makespan(t *testing.T) {
+	// a and b are independent; c waits for both, d for c
+	tasks := []Task{
+		{ID: "a", Steps: 1},
+		{ID: "b", Steps: 2}, // Parent with one pending sub-task
+		{ID: "c", BlockedBy: []string{"a", "b"}, Steps: 1},
+		{ID: "d", BlockedBy: []string{"c", "done-elsewhere"}, Steps: 1},
+	}
+	report, err := Run(tasks, Config{
+		Workers:     []int{1, 2, 4},
+		Trials:      10,
+		History:     fixedHistory(time.Hour),
+		CostPerHour: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.FromHistory {
+		t.Error("expected durations to be resampled from history")
+	}
+
+	want := map[int]time.Duration{1: 5 * time.Hour, 2: 4 * time.Hour, 4: 4 * time.Hour}
+	for _, e := range report.Estimates {
+		if e.MakespanP50 != want[e.Workers] || e.MakespanP90 != want[e.Workers] {
+			t.Errorf("%d workers: makespan p50=%v p90=%v, want %v", e.Workers, e.MakespanP50, e.MakespanP90, want[e.Workers])
+		}
+		if e.CostP50 != 25 {
+			t.Errorf("%d workers: cost p50 = %v, want 25", e.Workers, e.CostP50)
+		}
+	}
+
+	lines := report.Sensitivity()
+	if len(lines) != 2 || !strings.Contains(lines[0], "2 workers") || !strings.Contains(lines[1], "4 workers are no faster than 2") {
+		t.Errorf("unexpected sensitivity: %q", lines)
+	}
+}
+
+func TestRun_NotBefore(t *testing.T) {
+	// b can't start for 3h, so its dependent c finishes at 3h + 1h + 1h
+	tasks := []Task{
+		{ID: "a", Steps: 1},
+		{ID: "b", Steps: 1, NotBefore: 3 * time.Hour},
+		{ID: "c", BlockedBy: []string{"b"}, Steps: 1},
+	}
+	report, err := Run(tasks, Config{Workers: []int{1}, Trials: 1, History: fixedHistory(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := report.Estimates[0]
+	if e.MakespanP50 != 5*time.Hour {
+		t.Errorf("makespan = %v, want 5h", e.MakespanP50)
+	}
+	if e.Utilization != 0.6 {
+		t.Errorf("utilization = %v, want 0.6", e.Utilization)
+	}
+}
+
+func TestReadyQueue_ClaimOrder(t *testing.T) {
+	// Workers claim by priority, then oldest first
+	tasks := []Task{
+		{ID: "old-low", Priority: 0, Order: 0},
+		{ID: "new-high", Priority: 5, Order: 3},
+		{ID: "old-high", Priority: 5, Order: 1},
+		{ID: "mid", Priority: 2, Order: 2},
+	}
+	q := &readyQueue{tasks: tasks}
+	for i := range tasks {
+		heap.Push(q, i)
+	}
+	var got []string
+	for q.Len() > 0 {
+		got = append(got, tasks[heap.Pop(q).(int)].ID)
+	}
+	if want := "old-high new-high mid old-low"; strings.Join(got, " ") != want {
+		t.Errorf("claim order = %v, want %s", got, want)
+	}
+}
+
+func TestRun_Cycle(t *testing.T) {
+	tasks := []Task{
+		{ID: "a", BlockedBy: []string{"b"}, Steps: 1},
+		{ID: "b", BlockedBy: []string{"a"}, Steps: 1},
+		{ID: "c", Steps: 1},
+	}
+	report, err := Run(tasks, Config{Workers: []int{2}, Trials: 5, DefaultDuration: time.Minute, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Unschedulable) != 2 {
+		t.Errorf("expected a and b to be unschedulable, got %v", report.Unschedulable)
+	}
+	if report.FromHistory {
+		t.Error("expected estimated durations without history")
+	}
+	if report.Estimates[0].MakespanP50 <= 0 {
+		t.Error("expected the schedulable task to take time")
+	}
+}
+
+func TestRun_Validation(t *testing.T) {
+	if _, err := Run(nil, Config{Workers: []int{1}, Trials: 0, DefaultDuration: time.Minute}); err == nil {
+		t.Error("expected an error for zero trials")
+	}
+	if _, err := Run(nil, Config{Workers: []int{0}, Trials: 1, DefaultDuration: time.Minute}); err == nil {
+		t.Error("expected an error for zero workers")
+	}
+	if _, err := Run(nil, Config{Workers: []int{1}, Trials: 1}); err == nil {
+		t.Error("expected an error without history or a default duration")
+	}
+}
+
+func TestPlanWaves(t *testing.T) {
+	// a, b and e are ready; c waits for a and b, d for c; x and y wait for each other
+	tasks := []Task{
+		{ID: "a", Priority: 1, Order: 0},
+		{ID: "b", Priority: 5, Order: 1},
+		{ID: "c", BlockedBy: []string{"a", "b"}, Order: 2},
+		{ID: "d", BlockedBy: []string{"c"}, Order: 3},
+		{ID: "e", Order: 4},
+		{ID: "x", BlockedBy: []string{"y"}, Order: 5},
+		{ID: "y", BlockedBy: []string{"x"}, Order: 6},
+	}
+	plan, err := PlanWaves(tasks, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{"b", "a"}, {"c", "e"}, {"d"}}
+	if fmt.Sprint(plan.Waves) != fmt.Sprint(want) {
+		t.Errorf("waves = %v, want %v", plan.Waves, want)
+	}
+	if fmt.Sprint(plan.Unschedulable) != "[x y]" {
+		t.Errorf("unschedulable = %v, want [x y]", plan.Unschedulable)
+	}
+	if plan.MaxParallel != 3 {
+		t.Errorf("max parallel = %d, want 3 (a, b and e at once)", plan.MaxParallel)
+	}
+	if got := plan.Parallelism(); got != 5.0/3 {
+		t.Errorf("parallelism = %v, want 5/3", got)
+	}
+
+	if _, err := PlanWaves(tasks, 0, false); err == nil {
+		t.Error("planning for no workers should fail")
+	}
+}
+
+func TestPlanWaves_CriticalPath(t *testing.T) {
+	// head starts a chain of three; the older solo tasks unblock nothing
+	tasks := []Task{
+		{ID: "solo1", Order: 0},
+		{ID: "solo2", Order: 1},
+		{ID: "head", Order: 2},
+		{ID: "mid", BlockedBy: []string{"head"}, Order: 3},
+		{ID: "tail", BlockedBy: []string{"mid"}, Order: 4},
+	}
+
+	byAge, err := PlanWaves(tasks, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[[solo1 solo2] [head] [mid] [tail]]"; fmt.Sprint(byAge.Waves) != want {
+		t.Errorf("priority waves = %v, want %s", byAge.Waves, want)
+	}
+
+	critical, err := PlanWaves(tasks, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[[head solo1] [mid solo2] [tail]]"; fmt.Sprint(critical.Waves) != want {
+		t.Errorf("critical-path waves = %v, want %s", critical.Waves, want)
+	}
+
+	// Priority still comes first
+	tasks[0].Priority = 1
+	critical, err = PlanWaves(tasks, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if critical.Waves[0][0] != "solo1" {
+		t.Errorf("first claim = %s, want the higher-priority solo1", critical.Waves[0][0])
+	}
+}
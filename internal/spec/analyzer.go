@@ -250,7 +250,8 @@ Respond ONLY with valid JSON in this exact format:
               "priority": 3
             }
           ],
-          "blocked_by": ["0.0"]
+          "blocked_by": ["0.0"],
+          "mutex_key": "db-schema"
         }
       ]
     }
@@ -266,6 +267,7 @@ Respond ONLY with valid JSON in this exact format:
 - Use "lenient" test_mode for non-critical work
 - Use "disabled" test_mode for documentation/research
 - Set appropriate task dependencies using blocked_by (format: "epicIndex.taskIndex", e.g., "0.0", "0.1")
+- Give tasks that edit the same shared file or resource (e.g. a schema, a lockfile, a generated client) the same mutex_key so they never run in parallel; omit it for tasks that can run alongside any other
 - Be specific about files, components, and technical details
 
 Begin your analysis now.`, content)
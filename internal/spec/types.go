@@ -24,6 +24,7 @@ type TaskSpec struct {
 	TestScope         string        `json:"test_scope"`        // all, diff, skip
 	SubTasks          []SubTaskSpec `json:"sub_tasks,omitempty"`
 	BlockedBy         []string      `json:"blocked_by,omitempty"`
+	MutexKey          string        `json:"mutex_key,omitempty"`   // Tasks sharing the key never run at once
 }
 
 // SubTaskSpec represents a subtask
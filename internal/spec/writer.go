@@ -25,70 +25,76 @@ type WriteResult struct {
 	SubTasks []*types.Task
 }
 
-// WriteAnalysis creates epics and tasks from the analysis
+// WriteAnalysis creates epics and tasks from the analysis, all or nothing
 func (w *Writer) WriteAnalysis(analysis *SpecAnalysis) (*WriteResult, error) {
-	result := &WriteResult{
-		Epics:    make([]*types.Epic, 0),
-		Tasks:    make([]*types.Task, 0),
-		SubTasks: make([]*types.Task, 0),
-	}
-
-	// Track created task IDs for dependency resolution
-	taskIDMap := make(map[string]string) // Maps epic index.task index to actual task ID
+	var batch db.BulkTasks
 
+	// Map "epic index.task index" references to batch indexes up front, so
+	// tasks can wait for ones later in the spec; sub-tasks follow their parent
+	taskIndex := make(map[string]int)
+	next := 0
 	for epicIdx, epicSpec := range analysis.Epics {
-		// Create epic
-		epic, err := w.store.CreateEpic(epicSpec.Title, epicSpec.Description)
-		if err != nil {
-			return nil, fmt.Errorf("creating epic %d (%s): %w", epicIdx, epicSpec.Title, err)
+		for taskIdx, taskSpec := range epicSpec.Tasks {
+			taskIndex[fmt.Sprintf("%d.%d", epicIdx, taskIdx)] = next
+			next += 1 + len(taskSpec.SubTasks)
 		}
-		result.Epics = append(result.Epics, epic)
+	}
+
+	for epicIdx, epicSpec := range analysis.Epics {
+		batch.Epics = append(batch.Epics, db.NewEpic{Title: epicSpec.Title, Description: epicSpec.Description})
 
-		// Create tasks for this epic
 		for taskIdx, taskSpec := range epicSpec.Tasks {
 			taskKey := fmt.Sprintf("%d.%d", epicIdx, taskIdx)
 
 			// Resolve blocked_by references
-			blockedBy, err := w.resolveDependencies(taskSpec.BlockedBy, taskIDMap)
+			blockedBy, err := w.resolveDependencies(taskSpec.BlockedBy, taskIndex)
 			if err != nil {
 				return nil, fmt.Errorf("resolving dependencies for task %s: %w", taskKey, err)
 			}
 
-			// Create task with test configuration
-			task, err := w.store.CreateTaskWithTestConfig(
-				taskSpec.Title,
-				w.buildTaskDescription(&taskSpec),
-				epic.ID,
-				taskSpec.Priority,
-				blockedBy,
-				"", // operator
-				taskSpec.TestMode,
-				taskSpec.TestScope,
-				"", // test command (use default)
-			)
-			if err != nil {
-				return nil, fmt.Errorf("creating task %s: %w", taskKey, err)
-			}
-			result.Tasks = append(result.Tasks, task)
-			taskIDMap[taskKey] = task.ID
-
-			// Create subtasks
-			for subTaskIdx, subTaskSpec := range taskSpec.SubTasks {
-				subTask, err := w.store.CreateSubTask(
-					subTaskSpec.Title,
-					subTaskSpec.Description,
-					task.ID,
-					subTaskSpec.Priority,
-					nil, // subtasks don't support blocking in current implementation
-				)
-				if err != nil {
-					return nil, fmt.Errorf("creating subtask %s.%d: %w", taskKey, subTaskIdx, err)
-				}
-				result.SubTasks = append(result.SubTasks, subTask)
+			parent := len(batch.Tasks)
+			batch.Tasks = append(batch.Tasks, db.NewTask{
+				Title:       taskSpec.Title,
+				Description: w.buildTaskDescription(&taskSpec),
+				Priority:    taskSpec.Priority,
+				Epic:        epicIdx,
+				Parent:      db.NoRef,
+				BlockedBy:   blockedBy,
+				TestMode:    taskSpec.TestMode,
+				TestScope:   taskSpec.TestScope,
+				MutexKey:    taskSpec.MutexKey,
+			})
+
+			// Subtasks don't support blocking in current implementation
+			for _, subTaskSpec := range taskSpec.SubTasks {
+				batch.Tasks = append(batch.Tasks, db.NewTask{
+					Title:       subTaskSpec.Title,
+					Description: subTaskSpec.Description,
+					Priority:    subTaskSpec.Priority,
+					Epic:        db.NoRef,
+					Parent:      parent,
+				})
 			}
 		}
 	}
 
+	created, err := w.store.CreateTasksBulk(batch)
+	if err != nil {
+		return nil, fmt.Errorf("creating tasks: %w", err)
+	}
+
+	result := &WriteResult{
+		Epics:    created.Epics,
+		Tasks:    make([]*types.Task, 0),
+		SubTasks: make([]*types.Task, 0),
+	}
+	for _, task := range created.Tasks {
+		if task.ParentID != "" {
+			result.SubTasks = append(result.SubTasks, task)
+		} else {
+			result.Tasks = append(result.Tasks, task)
+		}
+	}
 	return result, nil
 }
 
@@ -104,20 +110,20 @@ func (w *Writer) buildTaskDescription(spec *TaskSpec) string {
 	return desc
 }
 
-// resolveDependencies converts task reference strings to actual task IDs
-func (w *Writer) resolveDependencies(blockedBy []string, taskIDMap map[string]string) ([]string, error) {
+// resolveDependencies converts task reference strings to batch indexes
+func (w *Writer) resolveDependencies(blockedBy []string, taskIndex map[string]int) ([]int, error) {
 	if len(blockedBy) == 0 {
 		return nil, nil
 	}
 
-	resolved := make([]string, 0, len(blockedBy))
+	resolved := make([]int, 0, len(blockedBy))
 	for _, ref := range blockedBy {
 		// Handle references like "0.1" -> epic 0, task 1
-		taskID, ok := taskIDMap[ref]
+		idx, ok := taskIndex[ref]
 		if !ok {
-			return nil, fmt.Errorf("unknown task reference: %s (may refer to a task that hasn't been created yet)", ref)
+			return nil, fmt.Errorf("unknown task reference: %s", ref)
 		}
-		resolved = append(resolved, taskID)
+		resolved = append(resolved, idx)
 	}
 	return resolved, nil
 }
@@ -72,6 +72,9 @@ type Runner struct {
 	config      *TestConfig
 	baseDir     string // Base repository directory
 	verbose     bool
+	env         []string // Extra environment for test commands (e.g. shared GOCACHE)
+	baseBranch  string   // Branch diff-scoped runs compare against
+	shards      int      // Parallel shards for go test and jest (0 or 1 runs unsharded)
 }
 
 // NewRunner creates a new test runner
@@ -91,6 +94,22 @@ func (r *Runner) SetVerbose(v bool) {
 	r.verbose = v
 }
 
+// SetEnv sets extra KEY=VALUE environment entries for test commands
+func (r *Runner) SetEnv(env []string) {
+	r.env = env
+}
+
+// SetBaseBranch sets the branch diff-scoped runs compare against (default "main")
+func (r *Runner) SetBaseBranch(branch string) {
+	r.baseBranch = branch
+}
+
+// SetShards splits go test and jest runs into n parallel shards. Other
+// commands, and suites too small to split, still run whole
+func (r *Runner) SetShards(n int) {
+	r.shards = n
+}
+
 // Run executes tests for a task in the given worktree directory
 func (r *Runner) Run(worktreePath string, taskID string) *TestResult {
 	result := &TestResult{
@@ -140,8 +159,14 @@ func (r *Runner) Run(worktreePath string, taskID string) *TestResult {
 		return result
 	}
 
-	// Run the tests
-	output, err := r.runCommand(worktreePath, cmd, args)
+	// Run the tests, sharded when that was asked for and the command supports it
+	var output string
+	if shards := r.shardCommands(worktreePath, cmd, args, r.shards); shards != nil {
+		log.Printf("🧪 Running tests for task %s in %d parallel shards", taskID, len(shards))
+		output, err = r.runShards(worktreePath, shards)
+	} else {
+		output, err = r.runCommand(worktreePath, cmd, args)
+	}
 	result.Output = output
 
 	if err != nil {
@@ -195,9 +220,13 @@ func (r *Runner) shouldRunTests(worktreePath string) (bool, error) {
 	}
 }
 
-// hasChanges checks if the worktree has any changes compared to main
+// hasChanges checks if the worktree has any changes compared to the base branch
 func (r *Runner) hasChanges(worktreePath string) (bool, error) {
-	cmd := exec.Command("git", "diff", "--quiet", "main")
+	baseBranch := r.baseBranch
+	if baseBranch == "" {
+		baseBranch = "main"
+	}
+	cmd := exec.Command("git", "diff", "--quiet", baseBranch)
 	cmd.Dir = worktreePath
 	err := cmd.Run()
 
@@ -274,6 +303,10 @@ func (r *Runner) runCommand(worktreePath string, cmd string, args []string) (str
 		command.Stderr = &stderr
 	}
 
+	if len(r.env) > 0 {
+		command.Env = append(os.Environ(), r.env...)
+	}
+
 	err := command.Run()
 
 	output := stdout.String()
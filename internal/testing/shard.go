@@ -0,0 +1,153 @@
+package testing
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// goListTestPattern matches the test names printed by go test -list
+var goListTestPattern = regexp.MustCompile(`^(Test|Example|Fuzz)\w*$`)
+
+// shardCommands splits the test command into n commands that together run
+// every test once: go test is partitioned with -run over the names reported
+// by go test -list, and jest gets --shard=i/n. Returns nil when the command
+// can't be sharded, and the caller runs it whole
+func (r *Runner) shardCommands(worktreePath, cmd string, args []string, n int) [][]string {
+	if n < 2 {
+		return nil
+	}
+
+	switch {
+	case cmd == "go" && len(args) > 0 && args[0] == "test":
+		return r.goShards(worktreePath, args, n)
+	case usesJest(cmd, args):
+		return jestShards(cmd, args, n, false)
+	case cmd == "npm" && len(args) > 0 && args[0] == "test" && r.testScriptUsesJest(worktreePath):
+		return jestShards(cmd, args, n, true)
+	}
+	return nil
+}
+
+// goShards partitions the tests of a go test command round-robin by name
+func (r *Runner) goShards(worktreePath string, args []string, n int) [][]string {
+	for _, arg := range args {
+		if arg == "-run" || strings.HasPrefix(arg, "-run=") || arg == "-list" || strings.HasPrefix(arg, "-list=") {
+			return nil // Already filtered; don't second-guess it
+		}
+	}
+
+	listArgs := append(append([]string{}, args...), "-list", ".")
+	output, err := r.runCommand(worktreePath, "go", listArgs)
+	if err != nil {
+		if r.verbose {
+			log.Printf("🧪 Not sharding: listing tests failed: %v", err)
+		}
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, line := range strings.Split(output, "\n") {
+		name := strings.TrimSpace(line)
+		if goListTestPattern.MatchString(name) && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) < 2 {
+		return nil
+	}
+	sort.Strings(names)
+	if n > len(names) {
+		n = len(names)
+	}
+
+	groups := make([][]string, n)
+	for i, name := range names {
+		groups[i%n] = append(groups[i%n], regexp.QuoteMeta(name))
+	}
+
+	commands := make([][]string, n)
+	for i, group := range groups {
+		commands[i] = append(append([]string{"go"}, args...), "-run", "^("+strings.Join(group, "|")+")$")
+	}
+	return commands
+}
+
+// jestShards appends --shard=i/n to a jest command; viaNPM passes it through npm test
+func jestShards(cmd string, args []string, n int, viaNPM bool) [][]string {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--shard") {
+			return nil
+		}
+	}
+
+	commands := make([][]string, n)
+	for i := range commands {
+		command := append([]string{cmd}, args...)
+		if viaNPM && !containsArg(args, "--") {
+			command = append(command, "--")
+		}
+		commands[i] = append(command, fmt.Sprintf("--shard=%d/%d", i+1, n))
+	}
+	return commands
+}
+
+// usesJest reports whether the command invokes jest directly (e.g. "npx jest")
+func usesJest(cmd string, args []string) bool {
+	if filepath.Base(cmd) == "jest" {
+		return true
+	}
+	return (cmd == "npx" || cmd == "yarn" || cmd == "pnpm") && containsArg(args, "jest")
+}
+
+// testScriptUsesJest reports whether package.json's test script runs jest
+func (r *Runner) testScriptUsesJest(worktreePath string) bool {
+	content, err := os.ReadFile(filepath.Join(worktreePath, "package.json"))
+	if err != nil {
+		return false
+	}
+	return regexp.MustCompile(`"test"\s*:\s*"[^"]*\bjest\b`).Match(content)
+}
+
+func containsArg(args []string, want string) bool {
+	for _, arg := range args {
+		if arg == want {
+			return true
+		}
+	}
+	return false
+}
+
+// runShards runs the shard commands in parallel and combines their output.
+// The returned error is the first shard failure, if any
+func (r *Runner) runShards(worktreePath string, commands [][]string) (string, error) {
+	outputs := make([]string, len(commands))
+	errs := make([]error, len(commands))
+
+	var wg sync.WaitGroup
+	for i, command := range commands {
+		wg.Add(1)
+		go func(i int, command []string) {
+			defer wg.Done()
+			outputs[i], errs[i] = r.runCommand(worktreePath, command[0], command[1:])
+		}(i, command)
+	}
+	wg.Wait()
+
+	var b strings.Builder
+	var firstErr error
+	for i, output := range outputs {
+		fmt.Fprintf(&b, "=== shard %d/%d ===\n%s\n", i+1, len(commands), output)
+		if errs[i] != nil && firstErr == nil {
+			firstErr = fmt.Errorf("shard %d/%d: %w", i+1, len(commands), errs[i])
+		}
+	}
+	return b.String(), firstErr
+}
@@ -0,0 +1,79 @@
+package testing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShardCommandsGo(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/shard\n\ngo 1.21\n",
+		"a_test.go": `package shard
+
+import "testing"
+
+func TestAlpha(t *testing.T) {}
+func TestBeta(t *testing.T)  {}
+func TestGamma(t *testing.T) {}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	runner := NewRunner(&TestConfig{Mode: TestModeStrict, Scope: TestScopeAll}, dir)
+	commands := runner.shardCommands(dir, "go", []string{"test", "./..."}, 2)
+	if len(commands) != 2 {
+		t.Fatalf("Expected 2 shard commands, got %v", commands)
+	}
+	if got := strings.Join(commands[0], " "); got != "go test ./... -run ^(TestAlpha|TestGamma)$" {
+		t.Errorf("Unexpected first shard %q", got)
+	}
+	if got := strings.Join(commands[1], " "); got != "go test ./... -run ^(TestBeta)$" {
+		t.Errorf("Unexpected second shard %q", got)
+	}
+
+	output, err := runner.runShards(dir, commands)
+	if err != nil {
+		t.Fatalf("Expected shards to pass: %v\n%s", err, output)
+	}
+	if !strings.Contains(output, "=== shard 2/2 ===") {
+		t.Errorf("Expected shard headers in output:\n%s", output)
+	}
+
+	// A command that already filters tests runs whole
+	if commands := runner.shardCommands(dir, "go", []string{"test", "-run", "TestAlpha", "./..."}, 2); commands != nil {
+		t.Errorf("Expected no sharding with -run, got %v", commands)
+	}
+}
+
+func TestShardCommandsJest(t *testing.T) {
+	dir := t.TempDir()
+	pkg := `{"scripts": {"test": "jest --ci"}}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(pkg), 0644); err != nil {
+		t.Fatalf("Failed to write package.json: %v", err)
+	}
+
+	runner := NewRunner(nil, dir)
+	commands := runner.shardCommands(dir, "npm", []string{"test"}, 3)
+	if len(commands) != 3 {
+		t.Fatalf("Expected 3 shard commands, got %v", commands)
+	}
+	if got := strings.Join(commands[2], " "); got != "npm test -- --shard=3/3" {
+		t.Errorf("Unexpected npm shard %q", got)
+	}
+
+	commands = runner.shardCommands(dir, "npx", []string{"jest"}, 2)
+	if got := strings.Join(commands[0], " "); got != "npx jest --shard=1/2" {
+		t.Errorf("Unexpected npx shard %q", got)
+	}
+
+	if commands := runner.shardCommands(dir, "cargo", []string{"test"}, 2); commands != nil {
+		t.Errorf("Expected cargo to run unsharded, got %v", commands)
+	}
+}
@@ -0,0 +1,112 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CommitState is the state of a commit status check
+type CommitState string
+
+const (
+	CommitStatePending CommitState = "pending"
+	CommitStateSuccess CommitState = "success"
+	CommitStateFailure CommitState = "failure"
+	CommitStateError   CommitState = "error"
+)
+
+// maxStatusDescription is GitHub's limit on commit status descriptions, in
+// characters
+const maxStatusDescription = 140
+
+// StatusReporter publishes task progress as commit status checks so branch
+// protection rules can require drover's gates before a PR is merged
+type StatusReporter struct {
+	apiURL    string // e.g. https://api.github.com
+	repo      string // owner/name
+	token     string
+	context   string // status context prefix, e.g. "drover"
+	targetURL string // optional link shown next to the check
+	client    *http.Client
+}
+
+// NewStatusReporter creates a reporter for the GitHub commit status API
+func NewStatusReporter(apiURL, repo, token, statusContext string) *StatusReporter {
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	if statusContext == "" {
+		statusContext = "drover"
+	}
+	return &StatusReporter{
+		apiURL:  strings.TrimSuffix(apiURL, "/"),
+		repo:    repo,
+		token:   token,
+		context: statusContext,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// SetTargetURL sets the link attached to every reported status (e.g. the dashboard)
+func (r *StatusReporter) SetTargetURL(url string) {
+	r.targetURL = url
+}
+
+// commitStatusRequest is the body of a GitHub create-commit-status request
+type commitStatusRequest struct {
+	State       CommitState `json:"state"`
+	TargetURL   string      `json:"target_url,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Context     string      `json:"context"`
+}
+
+// Report sets the status of check "<context>/<taskID>" on the given commit
+func (r *StatusReporter) Report(ctx context.Context, sha, taskID string, state CommitState, description string) error {
+	if sha == "" {
+		return fmt.Errorf("commit sha is required")
+	}
+	if utf8.RuneCountInString(description) > maxStatusDescription {
+		description = string([]rune(description)[:maxStatusDescription-3]) + "..."
+	}
+
+	body, err := json.Marshal(commitStatusRequest{
+		State:       state,
+		TargetURL:   r.targetURL,
+		Description: description,
+		Context:     fmt.Sprintf("%s/%s", r.context, taskID),
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling status: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", r.apiURL, r.repo, sha)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "drover")
+	if r.token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("posting status: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
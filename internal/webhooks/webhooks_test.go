@@ -8,6 +8,7 @@ import (
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 )
 
 // TestWebhookManager tests basic webhook manager functionality
@@ -237,3 +238,59 @@ func TestWebhookDisable(t *testing.T) {
 		t.Errorf("Expected 2 calls total (before disable + after enable), got %d", callCount)
 	}
 }
+
+// TestStatusReporter verifies commit statuses are posted in the GitHub format
+func TestStatusReporter(t *testing.T) {
+	var gotPath, gotAuth string
+	var gotBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&gotBody); err != nil {
+			t.Errorf("Failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	r := NewStatusReporter(server.URL, "acme/widgets", "secret-token", "")
+	long := strings.Repeat("x", 200)
+	if err := r.Report(context.Background(), "abc123", "task-1", CommitStatePending, long); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+
+	if gotPath != "/repos/acme/widgets/statuses/abc123" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotAuth != "Bearer secret-token" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if gotBody["state"] != "pending" || gotBody["context"] != "drover/task-1" {
+		t.Errorf("unexpected body: %v", gotBody)
+	}
+	if len(gotBody["description"]) != maxStatusDescription {
+		t.Errorf("description length = %d, want %d", len(gotBody["description"]), maxStatusDescription)
+	}
+
+	// Multi-byte characters are counted as one, and never split
+	if err := r.Report(context.Background(), "abc123", "task-1", CommitStatePending, strings.Repeat("é", 200)); err != nil {
+		t.Fatalf("Report() error = %v", err)
+	}
+	if got := gotBody["description"]; !utf8.ValidString(got) || utf8.RuneCountInString(got) != maxStatusDescription {
+		t.Errorf("description = %q, want %d valid characters", got, maxStatusDescription)
+	}
+}
+
+// TestStatusReporterHTTPError verifies non-2xx responses are surfaced
+func TestStatusReporterHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Bad credentials", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	r := NewStatusReporter(server.URL, "acme/widgets", "bad", "drover")
+	err := r.Report(context.Background(), "abc123", "task-1", CommitStateSuccess, "done")
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Report() error = %v, want HTTP 401", err)
+	}
+}
@@ -30,6 +30,7 @@ when tasks complete. This prevents OOM issues in the main Drover orchestrator.`,
 
 	// Add execute command
 	cli.rootCmd.AddCommand(cli.executeCmd())
+	cli.rootCmd.AddCommand(cli.serveCmd())
 
 	return cli
 }
@@ -42,16 +43,17 @@ func (cli *CLI) Execute() error {
 // executeCmd handles the execute command
 func (cli *CLI) executeCmd() *cobra.Command {
 	var (
-		taskID      string
-		worktree    string
-		title       string
-		description string
-		epicID      string
-		guidance    []string
-		timeout     string
-		claudePath  string
-		verbose     bool
-		memoryLimit string
+		taskID       string
+		worktree     string
+		title        string
+		description  string
+		epicID       string
+		guidance     []string
+		timeout      string
+		claudePath   string
+		verbose      bool
+		memoryLimit  string
+		guidanceFile string
 	)
 
 	cmd := &cobra.Command{
@@ -90,16 +92,17 @@ Use "-" as the first argument to read from stdin.`,
 				}
 
 				input = TaskInput{
-					ID:          taskID,
-					Title:       title,
-					Description: description,
-					EpicID:      epicID,
-					Worktree:    worktree,
-					Guidance:    guidance,
-					Timeout:     timeout,
-					ClaudePath:  claudePath,
-					Verbose:     verbose,
-					MemoryLimit: memoryLimit,
+					ID:           taskID,
+					Title:        title,
+					Description:  description,
+					EpicID:       epicID,
+					Worktree:     worktree,
+					Guidance:     guidance,
+					Timeout:      timeout,
+					ClaudePath:   claudePath,
+					Verbose:      verbose,
+					MemoryLimit:  memoryLimit,
+					GuidanceFile: guidanceFile,
 				}
 			}
 
@@ -143,6 +146,7 @@ Use "-" as the first argument to read from stdin.`,
 	cmd.Flags().StringVar(&description, "description", "", "Task description (required)")
 	cmd.Flags().StringVar(&epicID, "epic-id", "", "Parent epic ID")
 	cmd.Flags().StringArrayVar(&guidance, "guidance", []string{}, "Guidance messages (can be specified multiple times)")
+	cmd.Flags().StringVar(&guidanceFile, "guidance-file", "", "File to read guidance added while the task runs from")
 	cmd.Flags().StringVar(&timeout, "timeout", "", "Task timeout (default: 30m)")
 	cmd.Flags().StringVar(&claudePath, "claude-path", "", "Path to Claude binary (default: claude)")
 	cmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose logging")
@@ -150,3 +154,26 @@ Use "-" as the first argument to read from stdin.`,
 
 	return cmd
 }
+
+// serveCmd handles the serve command
+func (cli *CLI) serveCmd() *cobra.Command {
+	var pool bool
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the gRPC worker protocol over stdin and stdout",
+		Long: `Serve the drover.worker.v1.Worker gRPC service over stdin and stdout.
+
+The orchestrator starts the worker with its pipes as the connection and runs
+one task on it with an ExecuteTask stream: it sends the task, and may cancel
+it, while the worker streams heartbeats, progress, Claude's output and memory
+stats, then the result. The worker exits once the stream ends, or with
+--pool, once the orchestrator closes its pipes, running task after task
+until then.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ServeStdio(pool)
+		},
+	}
+	cmd.Flags().BoolVar(&pool, "pool", false, "Serve tasks one after another until stdin closes, as a pooled worker")
+	return cmd
+}
@@ -8,19 +8,24 @@ import (
 	"os"
 	"os/exec"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/cloud-shuttle/drover/internal/backpressure"
-	"github.com/cloud-shuttle/drover/pkg/types"
+	"github.com/cloud-shuttle/drover/internal/outcome"
 )
 
 // Execute runs a task and returns the result
 func (e *Executor) Execute(input *TaskInput) *TaskResult {
+	return e.ExecuteContext(context.Background(), input)
+}
+
+// ExecuteContext runs a task until it's done, its timeout passes or ctx is
+// cancelled, and returns the result
+func (e *Executor) ExecuteContext(ctx context.Context, input *TaskInput) *TaskResult {
 	start := time.Now()
 
 	// Start heartbeat goroutine
-	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
 	defer cancel()
 
 	heartbeatDone := make(chan struct{})
@@ -30,8 +35,17 @@ func (e *Executor) Execute(input *TaskInput) *TaskResult {
 	// Build the prompt
 	prompt := e.buildPrompt(input)
 
-	// Execute Claude Code
-	output, err := e.runClaude(ctx, input.Worktree, prompt)
+	// Execute Claude Code, in a session guidance can be added to as it runs
+	// when the orchestrator keeps a guidance file
+	var output string
+	var usage claudeUsage
+	var delivered []string
+	var err error
+	if input.GuidanceFile != "" {
+		output, usage, delivered, err = e.runClaudeSession(ctx, input.ID, input.Worktree, prompt, input.GuidanceFile, input.Env)
+	} else {
+		output, usage, err = e.runClaude(ctx, input.Worktree, prompt, input.Env)
+	}
 
 	duration := time.Since(start)
 
@@ -39,7 +53,7 @@ func (e *Executor) Execute(input *TaskInput) *TaskResult {
 	signal := e.detectSignal(output, duration, err)
 
 	// Determine verdict
-	verdict, verdictReason := e.determineVerdict(output, err)
+	block := e.determineVerdict(output, err)
 
 	return &TaskResult{
 		Success:       err == nil,
@@ -48,8 +62,14 @@ func (e *Executor) Execute(input *TaskInput) *TaskResult {
 		Error:        errorString(err),
 		DurationMs:   duration.Milliseconds(),
 		Signal:       signal,
-		Verdict:      verdict,
-		VerdictReason: verdictReason,
+		Verdict:      string(block.Verdict),
+		VerdictReason: block.Reason,
+		FilesChanged:  block.FilesChanged,
+		Followups:     block.Followups,
+		InputTokens:   usage.InputTokens,
+		OutputTokens:  usage.OutputTokens,
+		CostUSD:       usage.CostUSD,
+		GuidanceDelivered: delivered,
 	}
 }
 
@@ -77,50 +97,72 @@ func (e *Executor) buildPrompt(input *TaskInput) string {
 		prompt.WriteString(fmt.Sprintf("\n\nThis task is part of epic: %s", input.EpicID))
 	}
 
+	prompt.WriteString("\n\n" + outcome.VerdictInstructions)
+
 	return prompt.String()
 }
 
-// runClaude executes Claude Code and captures output
-func (e *Executor) runClaude(ctx context.Context, worktree, prompt string) (string, error) {
-	cmd := exec.CommandContext(ctx, e.claudePath, "-p", prompt, "--dangerously-skip-permissions")
+// claudeUsage is what a Claude run used
+type claudeUsage struct {
+	InputTokens  int64
+	OutputTokens int64
+	CostUSD      float64
+}
+
+// claudeOutput is what 'claude -p --output-format json' prints once it's
+// done: the reply and what the run used
+type claudeOutput struct {
+	Subtype      string  `json:"subtype"`
+	IsError      bool    `json:"is_error"`
+	Result       string  `json:"result"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+	Usage        struct {
+		InputTokens              int64 `json:"input_tokens"`
+		CacheCreationInputTokens int64 `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int64 `json:"cache_read_input_tokens"`
+		OutputTokens             int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// runClaude executes Claude Code and captures its reply and usage. Stdout
+// carries the worker's result, so Claude's output is streamed to stderr
+func (e *Executor) runClaude(ctx context.Context, worktree, prompt string, env []string) (string, claudeUsage, error) {
+	var usage claudeUsage
+	cmd := exec.CommandContext(ctx, e.claudePath, "-p", prompt, "--dangerously-skip-permissions", "--output-format", "json")
 	cmd.Dir = worktree
+	cmd.Env = claudeEnv(env)
+	cmd.WaitDelay = claudeWaitDelay
 
-	// Capture output while also streaming to stdout/stderr
+	// Capture output while also streaming stderr
 	var outputBuf, errBuf strings.Builder
-	stdoutPipe, err := cmd.StdoutPipe()
-	if err != nil {
-		return "", fmt.Errorf("failed to create stdout pipe: %w", err)
-	}
-	stderrPipe, err := cmd.StderrPipe()
-	if err != nil {
-		return "", fmt.Errorf("failed to create stderr pipe: %w", err)
-	}
-
+	cmd.Stdout = &outputBuf
+	cmd.Stderr = io.MultiWriter(e.sink.output(), &errBuf)
 	if err := cmd.Start(); err != nil {
-		return "", fmt.Errorf("failed to start claude: %w", err)
+		return "", usage, fmt.Errorf("failed to start claude: %w", err)
+	}
+	err := cmd.Wait()
+
+	// The JSON arrives in one piece once Claude is done; output that isn't
+	// it, such as an error Claude printed instead, is kept as it is
+	reply := outputBuf.String()
+	var out claudeOutput
+	if json.Unmarshal([]byte(strings.TrimSpace(reply)), &out) == nil {
+		reply = strings.TrimSpace(out.Result)
+		if reply == "" && out.IsError {
+			reply = "Claude ended with " + out.Subtype
+		}
+		reply += "\n"
+		// Cached prompt tokens are still tokens sent
+		usage = claudeUsage{
+			InputTokens:  out.Usage.InputTokens + out.Usage.CacheCreationInputTokens + out.Usage.CacheReadInputTokens,
+			OutputTokens: out.Usage.OutputTokens,
+			CostUSD:      out.TotalCostUSD,
+		}
 	}
-
-	// Stream output to parent stdout/stderr while capturing
-	var wg sync.WaitGroup
-	wg.Add(2)
-
-	go func() {
-		defer wg.Done()
-		io.Copy(io.MultiWriter(os.Stdout, &outputBuf), stdoutPipe)
-	}()
-
-	go func() {
-		defer wg.Done()
-		io.Copy(io.MultiWriter(os.Stderr, &errBuf), stderrPipe)
-	}()
-
-	wg.Wait()
-	err = cmd.Wait()
+	io.WriteString(e.sink.output(), reply)
 
 	// Combine stdout and stderr for the result
-	fullOutput := outputBuf.String() + errBuf.String()
-
-	return fullOutput, err
+	return reply + errBuf.String(), usage, err
 }
 
 // detectSignal analyzes output and duration to determine worker signal
@@ -153,33 +195,20 @@ func (e *Executor) detectSignal(output string, duration time.Duration, execErr e
 	return backpressure.SignalOK
 }
 
-// determineVerdict analyzes output to determine task verdict
-func (e *Executor) determineVerdict(output string, execErr error) (string, string) {
+// determineVerdict reads the verdict block Claude ended its reply with. A
+// failed run fails whatever it reported; without a block the verdict is
+// left empty, so the exit status decides
+func (e *Executor) determineVerdict(output string, execErr error) *outcome.VerdictBlock {
 	if execErr != nil {
-		return string(types.TaskVerdictFail), execErr.Error()
-	}
-
-	// Parse output for verdict patterns
-	lowerOutput := strings.ToLower(output)
-
-	// Look for explicit verdict markers
-	if strings.Contains(lowerOutput, "verdict: pass") || strings.Contains(lowerOutput, "task completed") {
-		return string(types.TaskVerdictPass), "Task completed successfully"
+		return &outcome.VerdictBlock{Verdict: outcome.VerdictFail, Reason: execErr.Error()}
 	}
-
-	if strings.Contains(lowerOutput, "verdict: fail") || strings.Contains(lowerOutput, "task failed") {
-		return string(types.TaskVerdictFail), "Task failed during execution"
-	}
-
-	if strings.Contains(lowerOutput, "blocked by") || strings.Contains(lowerOutput, "verdict: blocked") {
-		return string(types.TaskVerdictBlocked), "Task blocked by dependencies"
+	if block, ok := outcome.ParseVerdictBlock(output); ok {
+		return block
 	}
-
-	// Default to pass if no error and no explicit failure
-	return string(types.TaskVerdictPass), "Task completed (no explicit verdict found)"
+	return &outcome.VerdictBlock{}
 }
 
-// heartbeatLoop sends periodic heartbeats to stderr
+// heartbeatLoop sends periodic heartbeats
 func (e *Executor) heartbeatLoop(taskID string, done <-chan struct{}) {
 	ticker := time.NewTicker(HeartbeatInterval)
 	defer ticker.Stop()
@@ -189,18 +218,24 @@ func (e *Executor) heartbeatLoop(taskID string, done <-chan struct{}) {
 		case <-done:
 			return
 		case <-ticker.C:
-			heartbeat := HeartbeatMessage{
+			e.sink.heartbeat(HeartbeatMessage{
 				Type:      "heartbeat",
 				TaskID:    taskID,
 				Timestamp: time.Now().Unix(),
-			}
-			if data, err := json.Marshal(heartbeat); err == nil {
-				fmt.Fprintf(os.Stderr, "%s\n", string(data))
-			}
+			})
 		}
 	}
 }
 
+// claudeEnv returns Claude's environment: the worker's plus the task's
+// entries. Returns nil, meaning inherit, when there are none
+func claudeEnv(env []string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	return append(os.Environ(), env...)
+}
+
 // errorString converts error to string, returning empty if nil
 func errorString(err error) string {
 	if err == nil {
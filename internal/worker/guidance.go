@@ -0,0 +1,218 @@
+package worker
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// guidanceEntry is a line of the guidance file
+type guidanceEntry struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+// guidanceFeed reads the guidance file as the orchestrator appends to it
+type guidanceFeed struct {
+	path   string
+	offset int64
+}
+
+// next returns the guidance appended since the last call. A line still
+// being written is left for the next call
+func (f *guidanceFeed) next() []guidanceEntry {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return nil
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil
+	}
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return nil
+	}
+	f.offset += int64(end + 1)
+
+	var entries []guidanceEntry
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		var entry guidanceEntry
+		if json.Unmarshal(line, &entry) != nil || strings.TrimSpace(entry.Message) == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// guidancePrompt is the message a piece of guidance is handed to Claude in
+func guidancePrompt(message string) string {
+	return "New guidance from the operator for the task you're working on:\n\n" + message +
+		"\n\nTake it into account in the rest of your work, then end your reply with the verdict block as before."
+}
+
+// sessionMessage is a user message on the stream-json input of 'claude -p'
+type sessionMessage struct {
+	Type    string `json:"type"`
+	Message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	} `json:"message"`
+}
+
+// runClaudeSession executes Claude Code like runClaude, but keeps its input
+// open while it works: guidance appended to the guidance file is sent to the
+// session as a further user message, which Claude takes up once it's done
+// with what it's doing. The session ends once Claude has answered every
+// message. It returns the IDs of the guidance sent
+func (e *Executor) runClaudeSession(ctx context.Context, taskID, worktree, prompt, guidanceFile string, env []string) (string, claudeUsage, []string, error) {
+	var usage claudeUsage
+	// Guidance already in the file was there before this run; what of it
+	// wasn't delivered is appended again
+	feed := &guidanceFeed{path: guidanceFile}
+	if info, err := os.Stat(guidanceFile); err == nil {
+		feed.offset = info.Size()
+	}
+
+	cmd := exec.CommandContext(ctx, e.claudePath, "-p", "--dangerously-skip-permissions",
+		"--input-format", "stream-json", "--output-format", "stream-json", "--verbose")
+	cmd.Dir = worktree
+	cmd.Env = claudeEnv(env)
+	cmd.WaitDelay = claudeWaitDelay
+
+	var errBuf strings.Builder
+	cmd.Stderr = io.MultiWriter(e.sink.output(), &errBuf)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return "", usage, nil, fmt.Errorf("failed to start claude: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", usage, nil, fmt.Errorf("failed to start claude: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return "", usage, nil, fmt.Errorf("failed to start claude: %w", err)
+	}
+
+	inputOpen := true
+	closeInput := func() {
+		if inputOpen {
+			stdin.Close()
+			inputOpen = false
+		}
+	}
+	defer closeInput()
+	send := func(content string) bool {
+		msg := sessionMessage{Type: "user"}
+		msg.Message.Role, msg.Message.Content = "user", content
+		data, err := json.Marshal(msg)
+		if err != nil || !inputOpen {
+			return false
+		}
+		_, err = stdin.Write(append(data, '\n'))
+		return err == nil
+	}
+
+	// Claude prints one JSON event per line; a result event ends each reply
+	results := make(chan claudeOutput)
+	var raw strings.Builder
+	go func() {
+		defer close(results)
+		reader := bufio.NewReader(stdout)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				raw.Write(line)
+				var event struct {
+					Type string `json:"type"`
+				}
+				var out claudeOutput
+				if json.Unmarshal(line, &event) == nil && event.Type == "result" && json.Unmarshal(line, &out) == nil {
+					results <- out
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	sent, answered := 0, 0
+	if send(prompt) {
+		sent++
+	}
+	var delivered []string
+	deliver := func() {
+		for _, entry := range feed.next() {
+			if !send(guidancePrompt(entry.Message)) {
+				return
+			}
+			sent++
+			delivered = append(delivered, entry.ID)
+			e.sink.progress(ProgressMessage{Type: "progress", TaskID: taskID, Message: "Guidance delivered: " + entry.Message})
+		}
+	}
+	if sent == 0 {
+		closeInput()
+	}
+
+	ticker := time.NewTicker(GuidancePollInterval)
+	defer ticker.Stop()
+	var replies []string
+	gotResult := false
+	for results != nil {
+		select {
+		case <-ticker.C:
+			if inputOpen {
+				deliver()
+			}
+		case out, ok := <-results:
+			if !ok {
+				results = nil
+				break
+			}
+			gotResult = true
+			reply := strings.TrimSpace(out.Result)
+			if reply == "" && out.IsError {
+				reply = "Claude ended with " + out.Subtype
+			}
+			replies = append(replies, reply)
+			io.WriteString(e.sink.output(), reply+"\n")
+			// Usage is per reply, while the cost is the session's so far
+			usage.InputTokens += out.Usage.InputTokens + out.Usage.CacheCreationInputTokens + out.Usage.CacheReadInputTokens
+			usage.OutputTokens += out.Usage.OutputTokens
+			usage.CostUSD = out.TotalCostUSD
+
+			answered++
+			if answered >= sent {
+				// Guidance that came in as Claude finished still gets a reply
+				deliver()
+				if answered >= sent {
+					closeInput()
+				}
+			}
+		}
+	}
+	closeInput()
+	err = cmd.Wait()
+
+	// Output that isn't a reply, such as an error Claude printed instead,
+	// is kept as it is
+	reply := raw.String()
+	if gotResult {
+		reply = strings.Join(replies, "\n\n") + "\n"
+	}
+	return reply + errBuf.String(), usage, delivered, err
+}
@@ -0,0 +1,359 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// ServiceName is the gRPC service drover-worker serves. The version in it
+// changes only with changes old orchestrators or workers can't read; fields
+// are added to the messages instead
+const ServiceName = "drover.worker.v1.Worker"
+
+// executeTaskMethod is the full name of the ExecuteTask method
+const executeTaskMethod = "/" + ServiceName + "/ExecuteTask"
+
+// ExecuteRequest is a message from the orchestrator on an ExecuteTask
+// stream: the task first, then any control messages while it runs
+type ExecuteRequest struct {
+	Task   *TaskInput `json:"task,omitempty"`
+	Cancel bool       `json:"cancel,omitempty"` // Stop the task; its result follows
+}
+
+// ExecuteEvent is a message from the worker on an ExecuteTask stream. Each
+// has one field set; the stream ends with the result
+type ExecuteEvent struct {
+	Heartbeat *HeartbeatMessage `json:"heartbeat,omitempty"`
+	Progress  *ProgressMessage  `json:"progress,omitempty"`
+	Output    *OutputChunk      `json:"output,omitempty"`
+	Memory    *MemoryStats      `json:"memory,omitempty"`
+	Result    *TaskResult       `json:"result,omitempty"`
+}
+
+// OutputChunk is a piece of Claude's output as it runs
+type OutputChunk struct {
+	Data string `json:"data"`
+}
+
+// MemoryStats is the worker's memory use, sent with each heartbeat
+type MemoryStats struct {
+	RSSBytes     int64 `json:"rss_bytes"`
+	PeakRSSBytes int64 `json:"peak_rss_bytes"`
+}
+
+// jsonCodec encodes the service's messages as JSON, so they're plain Go
+// structs and a field either side doesn't know is skipped
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) { return json.Marshal(v) }
+
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+func (jsonCodec) Name() string { return "json" }
+
+// executeTaskStream is the description of the ExecuteTask stream
+var executeTaskStream = grpc.StreamDesc{
+	StreamName:    "ExecuteTask",
+	ServerStreams: true,
+	ClientStreams: true,
+}
+
+// workerService is the implementation of the service
+type workerService interface {
+	executeTask(stream grpc.ServerStream) error
+}
+
+// serviceDesc describes the service for grpc.Server
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*workerService)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    executeTaskStream.StreamName,
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv any, stream grpc.ServerStream) error {
+			return srv.(workerService).executeTask(stream)
+		},
+	}},
+}
+
+// server runs the tasks of ExecuteTask streams
+type server struct {
+	done func() // Called once a task's stream ends
+}
+
+// executeTask runs the task the stream starts with, streaming heartbeats,
+// progress, Claude's output and memory stats as it runs, then its result
+func (s *server) executeTask(stream grpc.ServerStream) error {
+	if s.done != nil {
+		defer s.done()
+	}
+	var req ExecuteRequest
+	if err := stream.RecvMsg(&req); err != nil {
+		return err
+	}
+	if req.Task == nil {
+		return errors.New("the stream must start with a task")
+	}
+	input := req.Task
+	if input.Timeout == "" {
+		input.Timeout = DefaultTimeout.String()
+	}
+	if input.ClaudePath == "" {
+		input.ClaudePath = "claude"
+	}
+	timeout, err := time.ParseDuration(input.Timeout)
+	if err != nil {
+		return fmt.Errorf("invalid timeout: %w", err)
+	}
+
+	// A cancel message, or the orchestrator going away, stops the task
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	go func() {
+		for {
+			var req ExecuteRequest
+			if err := stream.RecvMsg(&req); err != nil {
+				return
+			}
+			if req.Cancel {
+				cancel()
+			}
+		}
+	}()
+
+	sink := &streamSink{stream: stream}
+	executor := NewExecutor(input.ClaudePath, timeout, input.Verbose)
+	executor.sink = sink
+	result := executor.ExecuteContext(ctx, input)
+	return sink.send(&ExecuteEvent{Result: result})
+}
+
+// streamSink reports on an ExecuteTask stream
+type streamSink struct {
+	mu     sync.Mutex
+	stream grpc.ServerStream
+	peak   int64
+}
+
+// send sends an event; a stream carries one message at a time
+func (s *streamSink) send(event *ExecuteEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stream.SendMsg(event)
+}
+
+func (s *streamSink) heartbeat(msg HeartbeatMessage) {
+	s.send(&ExecuteEvent{Heartbeat: &msg})
+	if mem, err := memory.GetSelfMemory(); err == nil {
+		s.mu.Lock()
+		s.peak = max(s.peak, mem.RSSBytes)
+		stats := &MemoryStats{RSSBytes: mem.RSSBytes, PeakRSSBytes: s.peak}
+		s.mu.Unlock()
+		s.send(&ExecuteEvent{Memory: stats})
+	}
+}
+
+func (s *streamSink) progress(msg ProgressMessage) { s.send(&ExecuteEvent{Progress: &msg}) }
+
+func (s *streamSink) output() io.Writer { return s }
+
+// Write sends Claude's output as it comes
+func (s *streamSink) Write(p []byte) (int, error) {
+	s.send(&ExecuteEvent{Output: &OutputChunk{Data: string(p)}})
+	return len(p), nil
+}
+
+// ServeStdio serves ExecuteTask streams over stdin and stdout, the pipes of
+// the orchestrator that started the worker. It returns once the first is
+// done, or with pool set, once the orchestrator closes the connection, for
+// a pooled worker that runs task after task
+func ServeStdio(pool bool) error {
+	srv := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}))
+	conn := newPipeConn(os.Stdin, os.Stdout)
+	lis := newPipeListener(conn)
+	conn.onClose = func() { lis.Close() }
+	s := &server{}
+	if !pool {
+		s.done = func() { go srv.GracefulStop() }
+	}
+	srv.RegisterService(&serviceDesc, s)
+	err := srv.Serve(lis)
+	if errors.Is(err, grpc.ErrServerStopped) || errors.Is(err, net.ErrClosed) {
+		return nil
+	}
+	return err
+}
+
+// ExecuteTask runs a task on a worker served over a pipe, reading r and
+// writing w, such as the stdout and stdin of a 'drover-worker serve' process.
+// onEvent is called with each event other than the result as it arrives.
+// Cancelling ctx sends the worker a cancel message, then waits for the
+// result of the stopped task
+func ExecuteTask(ctx context.Context, r io.Reader, w io.Writer, input *TaskInput, onEvent func(*ExecuteEvent)) (*TaskResult, error) {
+	client, err := NewClient(r, w)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return client.ExecuteTask(ctx, input, onEvent)
+}
+
+// Client is a connection to a worker served over a pipe, which runs one
+// task at a time
+type Client struct {
+	conn *grpc.ClientConn
+}
+
+// NewClient connects to a worker served over a pipe, reading r and writing
+// w, such as the stdout and stdin of a 'drover-worker serve --pool' process
+func NewClient(r io.Reader, w io.Writer) (*Client, error) {
+	dialed := false
+	conn, err := grpc.NewClient("passthrough:///drover-worker",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			// The pipes carry one connection
+			if dialed {
+				return nil, errors.New("the worker's connection is closed")
+			}
+			dialed = true
+			return newPipeConn(r, w), nil
+		}),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})))
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn}, nil
+}
+
+// Close closes the connection, and with it the pipe
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// ExecuteTask runs a task on the worker like the package's ExecuteTask
+func (c *Client) ExecuteTask(ctx context.Context, input *TaskInput, onEvent func(*ExecuteEvent)) (*TaskResult, error) {
+	// The stream outlives ctx, so a cancelled task still reports its result
+	streamCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	stream, err := c.conn.NewStream(streamCtx, &executeTaskStream, executeTaskMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(&ExecuteRequest{Task: input}); err != nil {
+		return nil, err
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.SendMsg(&ExecuteRequest{Cancel: true})
+		case <-streamCtx.Done():
+		}
+	}()
+
+	for {
+		var event ExecuteEvent
+		if err := stream.RecvMsg(&event); err != nil {
+			if err == io.EOF {
+				err = errors.New("the worker ended the stream without a result")
+			}
+			return nil, err
+		}
+		if event.Result != nil {
+			stream.CloseSend()
+			return event.Result, nil
+		}
+		if onEvent != nil {
+			onEvent(&event)
+		}
+	}
+}
+
+// pipeConn is a net.Conn over a reader and a writer, such as a process's
+// stdin and stdout
+type pipeConn struct {
+	io.Reader
+	io.Writer
+	closers []io.Closer
+	once    sync.Once
+	onClose func() // Called once closed
+}
+
+// newPipeConn returns a connection reading r and writing w, closing both
+// when closed
+func newPipeConn(r io.Reader, w io.Writer) *pipeConn {
+	c := &pipeConn{Reader: r, Writer: w}
+	for _, v := range []any{r, w} {
+		if closer, ok := v.(io.Closer); ok {
+			c.closers = append(c.closers, closer)
+		}
+	}
+	return c
+}
+
+func (c *pipeConn) Close() error {
+	var errs []error
+	c.once.Do(func() {
+		for _, closer := range c.closers {
+			errs = append(errs, closer.Close())
+		}
+		if c.onClose != nil {
+			c.onClose()
+		}
+	})
+	return errors.Join(errs...)
+}
+
+func (c *pipeConn) LocalAddr() net.Addr                { return pipeAddr{} }
+func (c *pipeConn) RemoteAddr() net.Addr               { return pipeAddr{} }
+func (c *pipeConn) SetDeadline(t time.Time) error      { return nil }
+func (c *pipeConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *pipeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// pipeAddr is the address of both ends of a pipeConn
+type pipeAddr struct{}
+
+func (pipeAddr) Network() string { return "pipe" }
+func (pipeAddr) String() string  { return "pipe" }
+
+// pipeListener is a net.Listener accepting a single connection
+type pipeListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+// newPipeListener returns a listener whose one connection is conn
+func newPipeListener(conn net.Conn) *pipeListener {
+	l := &pipeListener{conns: make(chan net.Conn, 1), closed: make(chan struct{})}
+	l.conns <- conn
+	return l
+}
+
+// Accept returns the connection, then blocks until the listener is closed
+func (l *pipeListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *pipeListener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *pipeListener) Addr() net.Addr { return pipeAddr{} }
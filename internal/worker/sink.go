@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// eventSink is where the executor reports on a task as it runs
+type eventSink interface {
+	heartbeat(msg HeartbeatMessage)
+	progress(msg ProgressMessage)
+	output() io.Writer // Claude's output as it comes
+}
+
+// stderrSink reports on stderr, one JSON message per line with Claude's
+// output in between, as 'drover-worker execute' does
+type stderrSink struct{}
+
+func (stderrSink) heartbeat(msg HeartbeatMessage) { writeStderrJSON(msg) }
+
+func (stderrSink) progress(msg ProgressMessage) { writeStderrJSON(msg) }
+
+func (stderrSink) output() io.Writer { return os.Stderr }
+
+// writeStderrJSON writes a message to stderr as a line of JSON
+func writeStderrJSON(msg any) {
+	if data, err := json.Marshal(msg); err == nil {
+		fmt.Fprintf(os.Stderr, "%s\n", data)
+	}
+}
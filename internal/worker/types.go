@@ -30,6 +30,15 @@ type TaskInput struct {
 	ClaudePath  string   `json:"claude_path,omitempty"`
 	Verbose     bool     `json:"verbose,omitempty"`
 	MemoryLimit string   `json:"memory_limit,omitempty"`
+
+	// File the orchestrator appends guidance added while the task runs to,
+	// one JSON object with an id and a message per line; the worker hands
+	// each new line to the running Claude session
+	GuidanceFile string `json:"guidance_file,omitempty"`
+
+	// Extra KEY=VALUE entries for Claude's environment, for a worker that
+	// doesn't inherit the orchestrator's, such as one on a remote host
+	Env []string `json:"env,omitempty"`
 }
 
 // TaskResult represents the output of a worker task execution
@@ -42,6 +51,19 @@ type TaskResult struct {
 	Signal       WorkerSignal `json:"signal"`
 	Verdict      string       `json:"verdict,omitempty"`
 	VerdictReason string      `json:"verdict_reason,omitempty"`
+
+	// Files Claude reported changing and the follow-up work it suggests,
+	// from its verdict block
+	FilesChanged []string `json:"files_changed,omitempty"`
+	Followups    []string `json:"followups,omitempty"`
+
+	// Usage Claude reported for the run; zero when it reported none
+	InputTokens  int64   `json:"input_tokens,omitempty"`
+	OutputTokens int64   `json:"output_tokens,omitempty"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+
+	// IDs of the guidance from the guidance file Claude was given mid-run
+	GuidanceDelivered []string `json:"guidance_delivered,omitempty"`
 }
 
 // HeartbeatMessage is sent periodically to stderr for crash recovery
@@ -69,6 +91,7 @@ type Executor struct {
 	claudePath string
 	timeout    time.Duration
 	verbose    bool
+	sink       eventSink // Where heartbeats, progress and Claude's output go
 }
 
 // NewExecutor creates a new worker executor
@@ -77,6 +100,7 @@ func NewExecutor(claudePath string, timeout time.Duration, verbose bool) *Execut
 		claudePath: claudePath,
 		timeout:    timeout,
 		verbose:    verbose,
+		sink:       stderrSink{},
 	}
 }
 
@@ -88,3 +112,11 @@ const SlowThreshold = 10 * time.Second
 
 // HeartbeatInterval is how often to send heartbeats
 const HeartbeatInterval = 10 * time.Second
+
+// claudeWaitDelay is how long a stopped Claude's output is waited for, as
+// processes it started may hold on to it
+const claudeWaitDelay = 2 * time.Second
+
+// GuidancePollInterval is how often the guidance file is checked for new
+// guidance
+const GuidancePollInterval = 2 * time.Second
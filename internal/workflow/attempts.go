@@ -0,0 +1,244 @@
+package workflow
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/attemptlog"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/memory"
+	"github.com/cloud-shuttle/drover/internal/project"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// maxAttemptErrorGuidance bounds how much of the previous attempt's error a
+// retry is given as guidance
+const maxAttemptErrorGuidance = 2000
+
+// attempt tracks one execution of a task for its attempt history. A nil
+// attempt, when recording failed, ignores every call
+type attempt struct {
+	store      *db.Store
+	projectDir string
+	record     *types.TaskAttempt
+	outputPath string   // Relative to projectDir; empty until output is saved
+	err        string   // Error to record when the task's own last error won't say
+	secrets    []string // Values scrubbed from the saved output and error
+	rotation   attemptlog.Rotation
+}
+
+// startAttempt records that workerID started executing a task
+func startAttempt(store *db.Store, projectDir, taskID, workerID string) *attempt {
+	if store == nil {
+		return nil
+	}
+	record, err := store.StartAttempt(taskID, workerID)
+	if err != nil {
+		log.Printf("⚠️  Task %s: %v", taskID, err)
+		return nil
+	}
+	return &attempt{store: store, projectDir: projectDir, record: record}
+}
+
+// saveOutput keeps the agent's output for this attempt as its transcript in
+// the database and in .drover/logs/<task>/<attempt>.log, rotated by size.
+// fullLog is the file an output that went over the output limit spilled to,
+// which the log is made from in its place
+func (a *attempt) saveOutput(output, fullLog string) {
+	if a == nil || output == "" {
+		return
+	}
+	output = redactSecrets(output, a.secrets)
+	if err := a.store.SaveTranscript(a.record.ID, output); err != nil {
+		log.Printf("⚠️  Saving transcript of task %s: %v", a.record.TaskID, err)
+	}
+	if a.projectDir == "" {
+		return
+	}
+	rel := attemptlog.Path(a.record.TaskID, a.record.Number)
+	path := filepath.Join(a.projectDir, rel)
+	if fullLog != "" {
+		err := a.writeFullLog(path, fullLog)
+		if err == nil {
+			a.outputPath = rel
+			return
+		}
+		log.Printf("⚠️  Saving full output of task %s, keeping the truncated one: %v", a.record.TaskID, err)
+	}
+	if err := a.writeLog(path, strings.NewReader(output)); err != nil {
+		log.Printf("⚠️  Saving output of task %s: %v", a.record.TaskID, err)
+		return
+	}
+	a.outputPath = rel
+}
+
+// writeFullLog moves the file an agent's full output spilled to into the
+// attempt's log
+func (a *attempt) writeFullLog(path, fullLog string) error {
+	file, err := os.Open(fullLog)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := a.writeLog(path, file); err != nil {
+		return err
+	}
+	return os.Remove(fullLog)
+}
+
+// writeLog writes the attempt's log from in a line at a time, so an output
+// too big to hold in memory isn't, scrubbing secrets on the way
+func (a *attempt) writeLog(path string, in io.Reader) error {
+	w, err := attemptlog.Create(path, a.rotation)
+	if err != nil {
+		return err
+	}
+	reader := bufio.NewReaderSize(in, 1<<20)
+	for {
+		// Secrets are single lines, so a chunk ends at a newline where it can
+		chunk, readErr := reader.ReadSlice('\n')
+		if _, err := io.WriteString(w, redactSecrets(string(chunk), a.secrets)); err != nil {
+			w.Close()
+			return err
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil && readErr != bufio.ErrBufferFull {
+			w.Close()
+			return readErr
+		}
+	}
+	return w.Close()
+}
+
+// rotateLogs sets when the attempt's log is rotated and how much of it is
+// kept
+func (a *attempt) rotateLogs(rotation attemptlog.Rotation) {
+	if a != nil {
+		a.rotation = rotation
+	}
+}
+
+// setSession records the agent session the attempt ran in, for a retry to
+// resume
+func (a *attempt) setSession(sessionID string) {
+	if a == nil || sessionID == "" {
+		return
+	}
+	if err := a.store.SetAttemptSession(a.record.ID, sessionID); err != nil {
+		log.Printf("⚠️  Task %s: %v", a.record.TaskID, err)
+	}
+}
+
+// setModel records the agent and model that ran the attempt
+func (a *attempt) setModel(model string) {
+	if a == nil || model == "" {
+		return
+	}
+	if err := a.store.SetAttemptModel(a.record.ID, model); err != nil {
+		log.Printf("⚠️  Task %s: %v", a.record.TaskID, err)
+	}
+}
+
+// fail sets the error recorded for the attempt, for failures that leave the
+// task's last error unset
+func (a *attempt) fail(errMsg string) {
+	if a != nil {
+		a.err = redactSecrets(errMsg, a.secrets)
+	}
+}
+
+// redact keeps secrets, such as the task's secret environment values, out
+// of the attempt's saved output and error
+func (a *attempt) redact(secrets []string) {
+	if a != nil {
+		a.secrets = append(a.secrets, secrets...)
+	}
+}
+
+// finish records the outcome the attempt left the task in. Call it once the
+// task's status for this attempt is final
+func (a *attempt) finish() {
+	if a == nil {
+		return
+	}
+	if err := a.store.FinishAttempt(a.record.ID, a.outputPath, a.err); err != nil {
+		log.Printf("⚠️  Task %s: %v", a.record.TaskID, err)
+	}
+}
+
+// setUpAttemptLogs reads how the attempt logs are rotated from [logs] in
+// .drover.toml, and removes the logs past its retention
+func setUpAttemptLogs(projectDir string, cfg project.LogsConfig) (attemptlog.Rotation, error) {
+	rotation := attemptlog.Rotation{MaxFiles: cfg.MaxFiles}
+	if cfg.MaxSize != "" {
+		size, err := memory.ParseBytes(cfg.MaxSize)
+		if err != nil {
+			return rotation, fmt.Errorf("[logs] max_size: %w", err)
+		}
+		rotation.MaxSize = size
+	}
+	if cfg.Retention > 0 && projectDir != "" {
+		removed, err := attemptlog.Prune(projectDir, cfg.Retention)
+		if err != nil {
+			log.Printf("⚠️  Removing attempt logs older than %v: %v", cfg.Retention, err)
+		} else if removed > 0 {
+			log.Printf("🧹 Removed %d attempt log(s) older than %v", removed, cfg.Retention)
+		}
+	}
+	return rotation, nil
+}
+
+// withPreviousAttempt carries what the task's previous attempt left behind
+// into the one starting: the error it failed with, as guidance so the agent
+// doesn't repeat the mistake, and with resume set the agent session to pick
+// up from. A task's first attempt, or one after a completed attempt, is left
+// alone
+func withPreviousAttempt(store *db.Store, task *types.Task, resume bool) {
+	if store == nil {
+		return
+	}
+	attempts, err := store.ListAttempts(task.ID)
+	if err != nil {
+		log.Printf("⚠️  Task %s: %v", task.ID, err)
+		return
+	}
+	// The attempt starting is still running; the last one that ended is its predecessor
+	var previous *types.TaskAttempt
+	for i := len(attempts) - 1; i >= 0; i-- {
+		if attempts[i].EndedAt != nil {
+			previous = attempts[i]
+			break
+		}
+	}
+	if previous == nil || previous.Outcome == types.TaskStatusCompleted {
+		return
+	}
+
+	if previous.Error != "" {
+		if task.ExecutionContext == nil {
+			task.ExecutionContext = &types.TaskExecutionContext{}
+		}
+		task.ExecutionContext.Guidance = append(task.ExecutionContext.Guidance, &types.GuidanceMessage{
+			ID:     "previous-attempt",
+			TaskID: task.ID,
+			Message: fmt.Sprintf("Attempt %d of this task failed with:\n%s\nDon't repeat what caused it.",
+				previous.Number, tail(previous.Error, maxAttemptErrorGuidance)),
+			CreatedAt: time.Now().Unix(),
+		})
+	}
+	if resume && previous.SessionID != "" {
+		if task.ExecutionContext == nil {
+			task.ExecutionContext = &types.TaskExecutionContext{}
+		}
+		task.ExecutionContext.ResumeSession = previous.SessionID
+		log.Printf("🔁 Task %s resumes session %s of attempt %d", task.ID, previous.SessionID, previous.Number)
+	}
+}
@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestWithPreviousAttempt(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	task, _ := store.CreateTask("Fix the build", "", "", 0, nil)
+
+	// The first attempt has nothing before it
+	first := startAttempt(store, "", task.ID, "worker-0")
+	withPreviousAttempt(store, task, true)
+	if task.ExecutionContext != nil {
+		t.Fatalf("Expected nothing carried into the first attempt, got %+v", task.ExecutionContext)
+	}
+	first.setSession("session-1")
+	_ = store.UpdateTaskStatus(task.ID, types.TaskStatusReady, "go vet: undefined: foo")
+	first.finish()
+
+	startAttempt(store, "", task.ID, "worker-0")
+	withPreviousAttempt(store, task, false)
+	if task.ExecutionContext == nil || len(task.ExecutionContext.Guidance) != 1 {
+		t.Fatalf("Expected the previous error as guidance, got %+v", task.ExecutionContext)
+	}
+	if msg := task.ExecutionContext.Guidance[0].Message; !strings.Contains(msg, "Attempt 1") || !strings.Contains(msg, "undefined: foo") {
+		t.Errorf("Guidance = %q, want attempt 1's error", msg)
+	}
+	if task.ExecutionContext.ResumeSession != "" {
+		t.Errorf("Expected no session to resume with resume off, got %q", task.ExecutionContext.ResumeSession)
+	}
+
+	task.ExecutionContext = nil
+	withPreviousAttempt(store, task, true)
+	if task.ExecutionContext == nil || task.ExecutionContext.ResumeSession != "session-1" {
+		t.Errorf("Expected attempt 1's session to resume, got %+v", task.ExecutionContext)
+	}
+}
+
+func TestAttemptSaveOutput_FullLog(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	task, _ := store.CreateTask("Chatty task", "", "", 0, nil)
+
+	fullLog := filepath.Join(t.TempDir(), "spill.log")
+	if err := os.WriteFile(fullLog, []byte("start\nkey=sk-secret\n...lots...\nend\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	projectDir := t.TempDir()
+	att := startAttempt(store, projectDir, task.ID, "worker-0")
+	att.redact([]string{"sk-secret"})
+	att.saveOutput("start\n[... truncated ...]\nend\n", fullLog)
+
+	data, err := os.ReadFile(filepath.Join(projectDir, att.outputPath))
+	if err != nil {
+		t.Fatalf("Reading the attempt's log: %v", err)
+	}
+	if got := string(data); !strings.Contains(got, "...lots...") || strings.Contains(got, "sk-secret") {
+		t.Errorf("Expected the full output with secrets scrubbed, got %q", got)
+	}
+	if _, err := os.Stat(fullLog); !os.IsNotExist(err) {
+		t.Errorf("Expected the spill file to be moved, got %v", err)
+	}
+	transcript, _ := store.GetTranscript(task.ID, 1)
+	if !strings.Contains(transcript, "truncated") {
+		t.Errorf("Expected the truncated output as the transcript, got %q", transcript)
+	}
+}
@@ -0,0 +1,244 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/dashboard"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/events"
+	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/internal/webhooks"
+	"github.com/cloud-shuttle/drover/pkg/telemetry"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"github.com/dbos-inc/dbos-transact-golang/dbos"
+)
+
+// blockerType is the kind of obstacle outside a task's own work that failed it
+type blockerType string
+
+const (
+	blockerMissingDependency blockerType = "missing_dependency" // A module, package or tool the project needs isn't available
+	blockerUnrelatedTest     blockerType = "unrelated_test"     // Tests in code the task didn't change fail
+	blockerLintConfig        blockerType = "lint_config"        // The linter's configuration is broken
+)
+
+// blocker is an obstacle detected in a failure
+type blocker struct {
+	Type     blockerType
+	Subject  string // What is missing or failing, e.g. a module or a test package
+	Evidence string // The output line it was detected in
+}
+
+// blockerPattern detects a blocker in failure output. The first group of re,
+// if any, captures the subject; subject is used otherwise
+type blockerPattern struct {
+	typ     blockerType
+	re      *regexp.Regexp
+	subject string
+}
+
+var blockerPatterns = []blockerPattern{
+	// Go
+	{typ: blockerMissingDependency, re: regexp.MustCompile(`no required module provides package (\S+?);?\s`)},
+	{typ: blockerMissingDependency, re: regexp.MustCompile(`missing go\.sum entry for module providing package (\S+)`)},
+	// Node
+	{typ: blockerMissingDependency, re: regexp.MustCompile(`Cannot find module '([^']+)'`)},
+	{typ: blockerMissingDependency, re: regexp.MustCompile(`Module not found: (?:Error: )?Can't resolve '([^']+)'`)},
+	// Python
+	{typ: blockerMissingDependency, re: regexp.MustCompile(`ModuleNotFoundError: No module named '([^']+)'`)},
+	// Rust
+	{typ: blockerMissingDependency, re: regexp.MustCompile("no matching package named `([^`]+)` found")},
+	// Tools
+	{typ: blockerMissingDependency, re: regexp.MustCompile(`exec: "([^"]+)": executable file not found in \$PATH`)},
+	{typ: blockerMissingDependency, re: regexp.MustCompile(`([\w.+-]+): command not found`)},
+
+	{typ: blockerLintConfig, re: regexp.MustCompile(`ESLint couldn't find (?:a|the) config(?:uration)?`), subject: "eslint"},
+	{typ: blockerLintConfig, re: regexp.MustCompile(`Failed to load (?:config|plugin) "([^"]+)"`)},
+	{typ: blockerLintConfig, re: regexp.MustCompile(`can't load config`), subject: "golangci-lint"},
+	{typ: blockerLintConfig, re: regexp.MustCompile(`unknown linters?:? '?([\w-]+)`)},
+	{typ: blockerLintConfig, re: regexp.MustCompile(`[Ff]ailed to parse (\S*(?:\.golangci|\.eslintrc|\.prettierrc|ruff|pyproject)\S*)`)},
+}
+
+// Where failing tests live: Go packages and Jest files, and pytest files
+var (
+	failedTestLocation   = regexp.MustCompile(`(?m)^FAIL[ \t]+(\S+)`)
+	failedPytestLocation = regexp.MustCompile(`(?m)^FAILED (\S+?)::`)
+)
+
+// detectBlocker classifies the output of a failed attempt. changed lists the
+// files the task changed; tests failing in a test or verification run are
+// only put down to a blocker when none of them is in code the task touched. Returns nil when the failure
+// looks like the task's own
+func detectBlocker(class FailureClass, msg string, changed []string) *blocker {
+	for _, p := range blockerPatterns {
+		loc := p.re.FindStringSubmatchIndex(msg)
+		if loc == nil {
+			continue
+		}
+		subject := p.subject
+		if len(loc) >= 4 && loc[2] >= 0 {
+			subject = msg[loc[2]:loc[3]]
+		}
+		return &blocker{Type: p.typ, Subject: subject, Evidence: lineAt(msg, loc[0])}
+	}
+
+	if (class != FailureTests && class != FailureVerify) || len(changed) == 0 {
+		return nil
+	}
+	var locations []string
+	evidence := ""
+	for _, re := range []*regexp.Regexp{failedTestLocation, failedPytestLocation} {
+		for _, loc := range re.FindAllStringSubmatchIndex(msg, -1) {
+			location := msg[loc[2]:loc[3]]
+			if touchesLocation(changed, location) {
+				return nil
+			}
+			if evidence == "" {
+				evidence = lineAt(msg, loc[0])
+			}
+			locations = appendUnique(locations, location)
+		}
+	}
+	if len(locations) == 0 {
+		return nil
+	}
+	if len(locations) > 3 {
+		locations = append(locations[:3], "…")
+	}
+	return &blocker{Type: blockerUnrelatedTest, Subject: strings.Join(locations, ", "), Evidence: evidence}
+}
+
+// touchesLocation reports whether a changed file is in a failing test's
+// location: a file's directory, or a Go package by its import path. A file
+// at the repository root could affect anything, so it touches every location
+func touchesLocation(changed []string, location string) bool {
+	for _, file := range changed {
+		dir := path.Dir(file)
+		if dir == "." {
+			return true
+		}
+		if path.Ext(location) != "" && path.Dir(location) == dir {
+			return true
+		}
+		if location == dir || strings.HasSuffix(location, "/"+dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineAt returns the line of s containing offset i
+func lineAt(s string, i int) string {
+	start := strings.LastIndexByte(s[:i], '\n') + 1
+	end := len(s)
+	if n := strings.IndexByte(s[i:], '\n'); n >= 0 {
+		end = i + n
+	}
+	return strings.TrimSpace(s[start:end])
+}
+
+// appendUnique appends s unless list already has it
+func appendUnique(list []string, s string) []string {
+	for _, have := range list {
+		if have == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// title is the title of the fix task for a blocker, the same for every task
+// the blocker fails so they share it
+func (b *blocker) title() string {
+	switch b.Type {
+	case blockerMissingDependency:
+		return "Add missing dependency " + b.Subject
+	case blockerUnrelatedTest:
+		return "Fix failing tests in " + b.Subject
+	default:
+		return "Fix lint configuration (" + b.Subject + ")"
+	}
+}
+
+// description is what the fix task asks of its agent
+func (b *blocker) description(task *types.Task) string {
+	var what string
+	switch b.Type {
+	case blockerMissingDependency:
+		what = b.Subject + " is missing: add it to the project's dependencies or setup"
+	case blockerUnrelatedTest:
+		what = "tests in " + b.Subject + " fail although the task didn't change them"
+	default:
+		what = "the lint configuration (" + b.Subject + ") is broken"
+	}
+	return fmt.Sprintf("Task %s (%s) failed on a blocker outside its own work: %s.\n\n%s\n\n"+
+		"Fix this in the project; don't do the work of %s itself, which runs again once this task completes.",
+		task.ID, task.Title, what, b.Evidence, task.ID)
+}
+
+// queueBlockerFix looks for a blocker behind a failed attempt and, if there is
+// one, queues a fix task that outranks the task and makes the task wait for
+// it. Fix tasks, sub-tasks (rerun by their parent) and tasks out of attempts
+// never wait for a fix. Reports whether the task now does
+func queueBlockerFix(store *db.Store, hooks *webhooks.Manager, record func(events.EventType, string, string, map[string]any),
+	gitMgr *git.WorktreeManager, task *types.Task, class FailureClass, msg string) bool {
+	if task.Type == types.TaskTypeFix || task.ParentID != "" || task.Attempts >= task.MaxAttempts {
+		return false
+	}
+
+	ctx := context.Background()
+	var changed []string
+	if (class == FailureTests || class == FailureVerify) && gitMgr != nil {
+		var err error
+		if changed, err = gitMgr.ChangedFiles(ctx, task.ID); err != nil {
+			log.Printf("⚠️  Could not list files changed by task %s: %v", task.ID, err)
+		}
+	}
+	b := detectBlocker(class, msg, changed)
+	if b == nil {
+		return false
+	}
+	telemetry.RecordBlockerDetected(ctx, string(b.Type), task.EpicID)
+
+	fix, created, err := store.QueueFixTask(task.ID, b.title(), b.description(task), task.Priority+1)
+	if err != nil {
+		log.Printf("Error queueing fix task for task %s: %v", task.ID, err)
+		return false
+	}
+	if created {
+		telemetry.RecordFixTaskCreated(ctx, string(b.Type), task.EpicID)
+		log.Printf("🧰 Task %s hit a blocker (%s: %s); queued fix task %s", task.ID, b.Type, b.Subject, fix.ID)
+	} else {
+		log.Printf("🧰 Task %s hit a blocker (%s: %s); waiting for fix task %s", task.ID, b.Type, b.Subject, fix.ID)
+	}
+
+	dashboard.BroadcastTaskBlocked(task.ID, task.Title)
+	if hooks != nil {
+		hooks.EmitTaskBlocked(task.ID, task.Title)
+	}
+	record(events.EventTaskBlocked, task.ID, task.EpicID, map[string]any{
+		"reason":   b.Evidence,
+		"blocker":  string(b.Type),
+		"fix_task": fix.ID,
+	})
+	return true
+}
+
+// fixBlocker is queueBlockerFix for a task the DBOS workflow runs
+func (o *DBOSOrchestrator) fixBlocker(ctx dbos.DBOSContext, task TaskInput, class FailureClass, msg string) bool {
+	if !o.config.FixBlockers || o.store == nil {
+		return false
+	}
+	t, err := o.store.GetTask(task.TaskID)
+	if err != nil {
+		log.Printf("Error fetching task %s: %v", task.TaskID, err)
+		return false
+	}
+	gitMgr, _ := o.worktreesFor(t.Repo)
+	return queueBlockerFix(o.store, o.webhooks, o.eventRecorder(ctx), gitMgr, t, class, msg)
+}
@@ -0,0 +1,182 @@
+package workflow
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/events"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestDetectBlocker(t *testing.T) {
+	tests := []struct {
+		name        string
+		class       FailureClass
+		msg         string
+		changed     []string
+		wantType    blockerType
+		wantSubject string
+	}{
+		{
+			name:        "go module",
+			class:       FailureTests,
+			msg:         "tests failed\nmain.go:5:2: no required module provides package github.com/google/uuid; to add it:\n\tgo get github.com/google/uuid",
+			wantType:    blockerMissingDependency,
+			wantSubject: "github.com/google/uuid",
+		},
+		{
+			name:        "node module",
+			class:       FailureVerdict,
+			msg:         "Error: Cannot find module 'left-pad'",
+			wantType:    blockerMissingDependency,
+			wantSubject: "left-pad",
+		},
+		{
+			name:        "python module",
+			class:       FailureTests,
+			msg:         "ModuleNotFoundError: No module named 'requests'",
+			wantType:    blockerMissingDependency,
+			wantSubject: "requests",
+		},
+		{
+			name:        "missing tool",
+			class:       FailureTests,
+			msg:         "sh: line 1: protoc: command not found",
+			wantType:    blockerMissingDependency,
+			wantSubject: "protoc",
+		},
+		{
+			name:        "lint config",
+			class:       FailureTests,
+			msg:         "level=error msg=\"Running error: can't load config: unknown field\"",
+			wantType:    blockerLintConfig,
+			wantSubject: "golangci-lint",
+		},
+		{
+			name:        "unrelated go test",
+			class:       FailureTests,
+			msg:         "--- FAIL: TestLegacy (0.00s)\nFAIL\nFAIL\texample.com/app/internal/legacy\t0.01s\nok  \texample.com/app/internal/api\t0.02s",
+			changed:     []string{"internal/api/handler.go"},
+			wantType:    blockerUnrelatedTest,
+			wantSubject: "example.com/app/internal/legacy",
+		},
+		{
+			name:        "unrelated pytest",
+			class:       FailureTests,
+			msg:         "FAILED tests/test_billing.py::test_invoice - assert 1 == 2",
+			changed:     []string{"app/users.py"},
+			wantType:    blockerUnrelatedTest,
+			wantSubject: "tests/test_billing.py",
+		},
+		{
+			name:    "test the task changed",
+			class:   FailureTests,
+			msg:     "FAIL\texample.com/app/internal/api\t0.01s",
+			changed: []string{"internal/api/handler.go"},
+		},
+		{
+			name:    "failing test outside a tests failure",
+			class:   FailureVerdict,
+			msg:     "FAIL\texample.com/app/internal/legacy\t0.01s",
+			changed: []string{"internal/api/handler.go"},
+		},
+		{
+			name:  "the task's own bug",
+			class: FailureTests,
+			msg:   "handler_test.go:12: expected 200, got 500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := detectBlocker(tt.class, tt.msg, tt.changed)
+			if tt.wantType == "" {
+				if b != nil {
+					t.Fatalf("Expected no blocker, got %+v", b)
+				}
+				return
+			}
+			if b == nil {
+				t.Fatalf("Expected a %s blocker, got none", tt.wantType)
+			}
+			if b.Type != tt.wantType || b.Subject != tt.wantSubject {
+				t.Errorf("Expected %s (%q), got %s (%q)", tt.wantType, tt.wantSubject, b.Type, b.Subject)
+			}
+			if b.Evidence == "" || strings.Contains(b.Evidence, "\n") {
+				t.Errorf("Expected a single line of evidence, got %q", b.Evidence)
+			}
+		})
+	}
+}
+
+func TestQueueBlockerFix(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	var recorded []events.EventType
+	record := func(eventType events.EventType, taskID, epicID string, data map[string]any) {
+		recorded = append(recorded, eventType)
+	}
+	msg := "Error: Cannot find module 'left-pad'"
+
+	first, _ := store.CreateTask("Render the header", "", "", 2, nil)
+	_ = store.UpdateTaskStatus(first.ID, types.TaskStatusInProgress, "")
+	first, _ = store.GetTask(first.ID)
+	if !queueBlockerFix(store, nil, record, nil, first, FailureVerdict, msg) {
+		t.Fatal("Expected the task to wait for a fix task")
+	}
+
+	blockers, _ := store.GetBlockedBy(first.ID)
+	if len(blockers) != 1 {
+		t.Fatalf("Expected the task blocked on one fix task, got %v", blockers)
+	}
+	fix, _ := store.GetTask(blockers[0])
+	if fix.Type != types.TaskTypeFix || fix.Priority != 3 || fix.Status != types.TaskStatusReady {
+		t.Errorf("Expected a ready fix task outranking the task, got type %q, priority %d, %s", fix.Type, fix.Priority, fix.Status)
+	}
+	if !strings.Contains(fix.Description, first.ID) || !strings.Contains(fix.Description, msg) {
+		t.Errorf("Expected the fix task to name the task and the failure, got %q", fix.Description)
+	}
+	got, _ := store.GetTask(first.ID)
+	if got.Status != types.TaskStatusBlocked || got.Attempts != 1 {
+		t.Errorf("Expected the task blocked with its attempt counted, got %s after %d attempts", got.Status, got.Attempts)
+	}
+	if len(recorded) != 1 || recorded[0] != events.EventTaskBlocked {
+		t.Errorf("Expected a task.blocked event, got %v", recorded)
+	}
+
+	// A second task failing on the same blocker waits for the same fix
+	second, _ := store.CreateTask("Render the footer", "", "", 5, nil)
+	_ = store.UpdateTaskStatus(second.ID, types.TaskStatusInProgress, "")
+	second, _ = store.GetTask(second.ID)
+	if !queueBlockerFix(store, nil, record, nil, second, FailureVerdict, msg) {
+		t.Fatal("Expected the second task to wait for the fix task")
+	}
+	if blockers, _ := store.GetBlockedBy(second.ID); len(blockers) != 1 || blockers[0] != fix.ID {
+		t.Errorf("Expected the second task to share fix task %s, got %v", fix.ID, blockers)
+	}
+	if fix, _ = store.GetTask(fix.ID); fix.Priority != 6 {
+		t.Errorf("Expected the fix task raised to outrank the second task, got priority %d", fix.Priority)
+	}
+
+	// Fix tasks never get fix tasks of their own
+	if queueBlockerFix(store, nil, record, nil, fix, FailureVerdict, msg) {
+		t.Error("Expected no fix task for a fix task")
+	}
+
+	if err := store.CompleteTask(fix.ID); err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	for _, id := range []string{first.ID, second.ID} {
+		if status, _ := store.GetTaskStatus(id); status != types.TaskStatusReady {
+			t.Errorf("Expected %s back in the queue once its fix completed, got %s", id, status)
+		}
+	}
+}
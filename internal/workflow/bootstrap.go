@@ -0,0 +1,99 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/internal/project"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// Scaffold task used when .drover.toml doesn't configure one
+const (
+	defaultBootstrapTitle       = "Initialize project skeleton"
+	defaultBootstrapDescription = "This repository has no commits yet. Create the initial project skeleton " +
+		"(build configuration, directory layout, README and .gitignore) following the project guidelines " +
+		"so the remaining tasks have a codebase to build on."
+)
+
+// bootstrapEmptyRepo prepares a repository with no commits for task execution
+// It creates an empty root commit on the target branch so worktrees can branch
+// from it, then queues the configured scaffold task in the run's epic and
+// blocks every other pending task on it. A repository still at that root
+// commit gets the scaffold task it's missing, such as after a crash, but never
+// a second one. It does nothing if bootstrap is disabled or the repository
+// already has history
+func bootstrapEmptyRepo(cfg *config.Config, projectCfg *project.Config, store *db.Store, projectDir string) error {
+	if !cfg.Bootstrap {
+		return nil
+	}
+	if git.IsEmptyRepo(projectDir) {
+		// Root the history on the configured target branch, or whatever HEAD names
+		targetBranch := cfg.TargetBranch
+		if targetBranch == "" {
+			targetBranch = projectCfg.TargetBranch
+		}
+		branch, err := git.InitEmptyRepo(projectDir, targetBranch)
+		if err != nil {
+			return fmt.Errorf("initializing empty repository: %w", err)
+		}
+		log.Printf("[bootstrap] repository was empty; created root commit on %s", branch)
+	} else if !git.IsBootstrapRoot(projectDir) {
+		return nil
+	}
+
+	if store == nil {
+		return nil
+	}
+
+	title := projectCfg.BootstrapTitle
+	if title == "" {
+		title = defaultBootstrapTitle
+	}
+	description := projectCfg.BootstrapDescription
+	if description == "" {
+		description = defaultBootstrapDescription
+	}
+
+	task, err := scaffoldTask(store, title)
+	if err != nil {
+		return err
+	}
+	switch {
+	case task == nil:
+		if task, err = store.CreateTask(title, description, cfg.Epic, 0, nil); err != nil {
+			return fmt.Errorf("creating bootstrap task: %w", err)
+		}
+	case task.Status == types.TaskStatusCompleted:
+		// It finished without landing anything; the tasks it held back are free
+		return nil
+	case task.EpicID != cfg.Epic && cfg.Epic != "":
+		// A run filtered to another epic would never claim it
+		if err := store.SetTaskEpic(task.ID, cfg.Epic); err != nil {
+			return fmt.Errorf("moving bootstrap task to epic %s: %w", cfg.Epic, err)
+		}
+	}
+	blocked, err := store.BlockTasksOn(task.ID)
+	if err != nil {
+		return fmt.Errorf("blocking tasks on bootstrap task: %w", err)
+	}
+	log.Printf("[bootstrap] queued scaffold task %s; %d tasks wait for it", task.ID, blocked)
+	return nil
+}
+
+// scaffoldTask returns the scaffold task an earlier run queued, or nil
+func scaffoldTask(store *db.Store, title string) (*types.Task, error) {
+	tasks, err := store.ListTasks()
+	if err != nil {
+		return nil, fmt.Errorf("listing tasks: %w", err)
+	}
+	for _, task := range tasks {
+		if task.Title == title && task.ParentID == "" {
+			return task, nil
+		}
+	}
+	return nil, nil
+}
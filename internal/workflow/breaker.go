@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// failureSignatureLen bounds the part of an error failures are compared by
+const failureSignatureLen = 120
+
+// volatileToken matches the parts of an error that differ between otherwise
+// identical failures: numbers, IDs and hashes
+var volatileToken = regexp.MustCompile(`[0-9a-f]*[0-9][0-9a-f]*`)
+
+// failureSignature reduces an error to what similar failures share, e.g.
+// "401 unauthorized for task-3f2a" and "401 unauthorized for task-9b1c"
+func failureSignature(errMsg string) string {
+	sig := strings.ToLower(firstLine(errMsg))
+	sig = volatileToken.ReplaceAllString(sig, "#")
+	if len(sig) > failureSignatureLen {
+		sig = strings.ToValidUTF8(sig[:failureSignatureLen], "")
+	}
+	return sig
+}
+
+// failureBreaker parks a run once consecutive tasks keep failing the same
+// way, e.g. on an expired API key or a provider outage, instead of letting it
+// burn through the backlog and the API budget
+type failureBreaker struct {
+	limit int
+
+	mu        sync.Mutex
+	signature string // What the failures of the current streak share
+	example   string // The latest error of the streak, as reported
+	streak    int
+	tripped   bool
+}
+
+// newFailureBreaker returns nil, which never trips, for a limit of 0
+func newFailureBreaker(limit int) *failureBreaker {
+	if limit <= 0 {
+		return nil
+	}
+	return &failureBreaker{limit: limit}
+}
+
+// failure records a failed task and reports whether this failure tripped the
+// breaker; it trips once
+func (b *failureBreaker) failure(errMsg string) bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	sig := failureSignature(errMsg)
+	if sig == b.signature {
+		b.streak++
+	} else {
+		b.signature, b.streak = sig, 1
+	}
+	b.example = firstLine(errMsg)
+	if b.tripped || b.streak < b.limit {
+		return false
+	}
+	b.tripped = true
+	log.Printf("🧯 %s: parking the run; no new tasks start. Fix the cause and run again", b.reasonLocked())
+	return true
+}
+
+// success ends the current streak
+func (b *failureBreaker) success() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.signature, b.streak = "", 0
+}
+
+// open reports whether the breaker tripped, parking the run
+func (b *failureBreaker) open() bool {
+	if b == nil {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tripped
+}
+
+// reason says why the breaker tripped
+func (b *failureBreaker) reason() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reasonLocked()
+}
+
+func (b *failureBreaker) reasonLocked() string {
+	return fmt.Sprintf("%d consecutive tasks failed the same way (%s)", b.streak, b.example)
+}
@@ -0,0 +1,45 @@
+package workflow
+
+import "testing"
+
+func TestFailureSignature(t *testing.T) {
+	a := failureSignature("API error 401: unauthorized for task-3f2a1b\nstack trace")
+	b := failureSignature("API error 401: unauthorized for task-9c0d44")
+	if a != b {
+		t.Errorf("Expected the same signature, got %q and %q", a, b)
+	}
+	if c := failureSignature("tests failed in ./internal/db"); c == a {
+		t.Errorf("Expected a different signature for a different error, got %q", c)
+	}
+}
+
+func TestFailureBreaker(t *testing.T) {
+	if newFailureBreaker(0) != nil {
+		t.Fatal("Expected no breaker for a limit of 0")
+	}
+	var none *failureBreaker
+	if none.failure("boom") || none.open() {
+		t.Error("Expected a nil breaker never to trip")
+	}
+
+	b := newFailureBreaker(3)
+	b.failure("rate limited (attempt 1)")
+	b.failure("rate limited (attempt 2)")
+	b.success() // A completed task ends the streak
+	b.failure("rate limited (attempt 3)")
+	b.failure("tests failed")
+	b.failure("rate limited (attempt 4)")
+	if b.open() {
+		t.Fatal("Expected no trip without 3 similar failures in a row")
+	}
+	b.failure("rate limited (attempt 5)")
+	if !b.failure("rate limited (attempt 6)") || !b.open() {
+		t.Fatal("Expected the third similar failure in a row to trip the breaker")
+	}
+	if b.failure("rate limited (attempt 7)") {
+		t.Error("Expected the breaker to trip only once")
+	}
+	if reason := b.reason(); reason != "4 consecutive tasks failed the same way (rate limited (attempt 7))" {
+		t.Errorf("Unexpected reason %q", reason)
+	}
+}
@@ -0,0 +1,154 @@
+package workflow
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/git"
+)
+
+// waitMutexKey is the serialization point of tasks sharing a mutex key
+const waitMutexKey = "mutex_key"
+
+// waitLabels describes serialization points in the post-run summary
+var waitLabels = map[string]string{
+	git.WaitMerge:       "waiting to merge",
+	git.WaitPoolAcquire: "waiting for a pooled worktree",
+	waitMutexKey:        "waiting for a task with the same mutex key",
+}
+
+// waitStat aggregates the waits observed at one serialization point
+type waitStat struct {
+	total time.Duration
+	max   time.Duration
+	count int
+}
+
+// concurrencyStats accumulates run-level worker busy time and the share of it
+// spent queued at serialization points, to point at the run's bottleneck
+type concurrencyStats struct {
+	mu     sync.Mutex
+	busy   time.Duration
+	active int // Workers executing a task right now
+	waits  map[string]*waitStat
+}
+
+func newConcurrencyStats() *concurrencyStats {
+	return &concurrencyStats{waits: make(map[string]*waitStat)}
+}
+
+// observeWait records a wait at a serialization point (a git.WaitObserver)
+func (c *concurrencyStats) observeWait(point string, wait time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w, ok := c.waits[point]
+	if !ok {
+		w = &waitStat{}
+		c.waits[point] = w
+	}
+	w.total += wait
+	w.count++
+	if wait > w.max {
+		w.max = wait
+	}
+}
+
+// addBusy records time a worker spent executing a task (waits included)
+func (c *concurrencyStats) addBusy(d time.Duration) {
+	c.mu.Lock()
+	c.busy += d
+	c.mu.Unlock()
+}
+
+// enter marks a worker as executing a task until the returned func is called
+func (c *concurrencyStats) enter() func() {
+	c.mu.Lock()
+	c.active++
+	c.mu.Unlock()
+	return func() {
+		c.mu.Lock()
+		c.active--
+		c.mu.Unlock()
+	}
+}
+
+// testShards returns how many shards a test gate may use: one for the
+// calling worker plus one per idle worker, capped at max
+func (c *concurrencyStats) testShards(workers, max int) int {
+	if max < 2 {
+		return 1
+	}
+	c.mu.Lock()
+	idle := workers - c.active
+	c.mu.Unlock()
+	if idle < 0 {
+		idle = 0
+	}
+	if shards := 1 + idle; shards < max {
+		return shards
+	}
+	return max
+}
+
+// Summary returns one line per serialization point, largest share first, e.g.
+// "workers spent 32% of busy time waiting to merge (12.3s over 8 waits, max 4.1s)"
+func (c *concurrencyStats) Summary() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.busy <= 0 {
+		return nil
+	}
+
+	points := make([]string, 0, len(c.waits))
+	for point, w := range c.waits {
+		if w.count > 0 {
+			points = append(points, point)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return c.waits[points[i]].total > c.waits[points[j]].total
+	})
+
+	lines := make([]string, 0, len(points))
+	for _, point := range points {
+		w := c.waits[point]
+		label := waitLabels[point]
+		if label == "" {
+			label = "waiting at " + strings.ReplaceAll(point, "_", " ")
+		}
+		pct := float64(w.total) / float64(c.busy) * 100
+		lines = append(lines, fmt.Sprintf("workers spent %.0f%% of busy time %s (%s over %d waits, max %s)",
+			pct, label, w.total.Round(100*time.Millisecond), w.count, w.max.Round(100*time.Millisecond)))
+	}
+	return lines
+}
+
+// mutexKeys lets one task per mutex key execute at a time, for orchestrators
+// that don't claim through the store
+type mutexKeys struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock blocks until no other task holds key, recording the wait, and returns
+// the func releasing it
+func (m *mutexKeys) lock(key string, stats *concurrencyStats) func() {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*sync.Mutex)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		m.locks[key] = l
+	}
+	m.mu.Unlock()
+
+	start := time.Now()
+	l.Lock()
+	stats.observeWait(waitMutexKey, time.Since(start))
+	return l.Unlock
+}
@@ -0,0 +1,62 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/git"
+)
+
+func TestConcurrencyStats_Summary(t *testing.T) {
+	stats := newConcurrencyStats()
+	if lines := stats.Summary(); len(lines) != 0 {
+		t.Fatalf("Expected no summary before any work, got %v", lines)
+	}
+
+	stats.addBusy(60 * time.Second)
+	stats.addBusy(40 * time.Second)
+	stats.observeWait(git.WaitMerge, 20*time.Second)
+	stats.observeWait(git.WaitMerge, 12*time.Second)
+	stats.observeWait(git.WaitPoolAcquire, 5*time.Second)
+
+	lines := stats.Summary()
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 summary lines, got %v", lines)
+	}
+	if !strings.HasPrefix(lines[0], "workers spent 32% of busy time waiting to merge") {
+		t.Errorf("Expected merge waits first, got %q", lines[0])
+	}
+	if !strings.Contains(lines[0], "over 2 waits, max 20s") {
+		t.Errorf("Expected wait count and max in %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "workers spent 5% of busy time waiting for a pooled worktree") {
+		t.Errorf("Unexpected pool line %q", lines[1])
+	}
+}
+
+func TestConcurrencyStats_TestShards(t *testing.T) {
+	stats := newConcurrencyStats()
+	if got := stats.testShards(4, 0); got != 1 {
+		t.Errorf("Expected sharding disabled with max 0, got %d", got)
+	}
+
+	exit := stats.enter()
+	if got := stats.testShards(4, 8); got != 4 {
+		t.Errorf("Expected the caller plus 3 idle workers, got %d", got)
+	}
+	if got := stats.testShards(4, 2); got != 2 {
+		t.Errorf("Expected shards capped at 2, got %d", got)
+	}
+
+	exits := []func(){exit, stats.enter(), stats.enter(), stats.enter()}
+	if got := stats.testShards(4, 8); got != 1 {
+		t.Errorf("Expected no extra shards with every worker busy, got %d", got)
+	}
+	for _, exit := range exits {
+		exit()
+	}
+	if got := stats.testShards(4, 8); got != 5 {
+		t.Errorf("Expected the caller plus 4 idle workers, got %d", got)
+	}
+}
@@ -0,0 +1,295 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/cloud-shuttle/drover/internal/backpressure"
+)
+
+// controlFile is where a running run's settings are changed, relative to the
+// project directory
+const controlFile = ".drover/control.toml"
+
+// controlPoll is how often a run checks the control file for changes
+const controlPoll = 2 * time.Second
+
+// runControl is the control file: settings that override the ones the run
+// started with. Settings left out, or the whole file removed, go back to them
+type runControl struct {
+	Workers      int      `toml:"workers"`
+	TaskTimeout  duration `toml:"task_timeout"`
+	DrainTimeout duration `toml:"drain_timeout"`
+	Backpressure struct {
+		MinConcurrency   int      `toml:"min_concurrency"`
+		MaxConcurrency   int      `toml:"max_concurrency"`
+		RateLimitBackoff duration `toml:"rate_limit_backoff"`
+		MaxBackoff       duration `toml:"max_backoff"`
+	} `toml:"backpressure"`
+}
+
+// duration is a time.Duration written as a string in TOML, e.g. "45m"
+type duration time.Duration
+
+func (d *duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = duration(v)
+	return nil
+}
+
+// loadRunControl parses a control file
+func loadRunControl(path string) (*runControl, error) {
+	var ctl runControl
+	meta, err := toml.DecodeFile(path, &ctl)
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown setting %q", undecoded[0].String())
+	}
+	bp := ctl.Backpressure
+	if ctl.Workers < 0 || ctl.TaskTimeout < 0 || ctl.DrainTimeout < 0 ||
+		bp.MinConcurrency < 0 || bp.MaxConcurrency < 0 || bp.RateLimitBackoff < 0 || bp.MaxBackoff < 0 {
+		return nil, errors.New("settings can't be negative")
+	}
+	return &ctl, nil
+}
+
+// runSettings are the settings of a run that can change while it runs
+type runSettings struct {
+	workers      int
+	taskTimeout  time.Duration // Bounds each agent run (0 = the agent's own timeout)
+	drainTimeout time.Duration
+	backpressure backpressure.Limits
+}
+
+// with returns the settings with the control file's applied
+func (s runSettings) with(ctl *runControl) runSettings {
+	if ctl == nil {
+		return s
+	}
+	if ctl.Workers > 0 {
+		s.workers = ctl.Workers
+	}
+	if ctl.TaskTimeout > 0 {
+		s.taskTimeout = time.Duration(ctl.TaskTimeout)
+	}
+	if ctl.DrainTimeout > 0 {
+		s.drainTimeout = time.Duration(ctl.DrainTimeout)
+	}
+	bp := ctl.Backpressure
+	if bp.MinConcurrency > 0 {
+		s.backpressure.MinConcurrency = bp.MinConcurrency
+	}
+	if bp.MaxConcurrency > 0 {
+		s.backpressure.MaxConcurrency = bp.MaxConcurrency
+	}
+	if bp.RateLimitBackoff > 0 {
+		s.backpressure.RateLimitBackoff = time.Duration(bp.RateLimitBackoff)
+	}
+	if bp.MaxBackoff > 0 {
+		s.backpressure.MaxBackoff = time.Duration(bp.MaxBackoff)
+	}
+	return s
+}
+
+// changes describes what differs from the settings before
+func (s runSettings) changes(before runSettings) string {
+	var list []string
+	if s.workers != before.workers {
+		list = append(list, fmt.Sprintf("workers %d → %d", before.workers, s.workers))
+	}
+	if s.taskTimeout != before.taskTimeout {
+		list = append(list, fmt.Sprintf("task timeout %s → %s", showTimeout(before.taskTimeout), showTimeout(s.taskTimeout)))
+	}
+	if s.drainTimeout != before.drainTimeout {
+		list = append(list, fmt.Sprintf("drain timeout %v → %v", before.drainTimeout, s.drainTimeout))
+	}
+	if s.backpressure != before.backpressure {
+		list = append(list, fmt.Sprintf("backpressure concurrency %d-%d, backoff %v up to %v",
+			s.backpressure.MinConcurrency, s.backpressure.MaxConcurrency, s.backpressure.RateLimitBackoff, s.backpressure.MaxBackoff))
+	}
+	return strings.Join(list, ", ")
+}
+
+func showTimeout(d time.Duration) string {
+	if d == 0 {
+		return "the agent's own"
+	}
+	return d.String()
+}
+
+// controlWatcher notices the control file being written, created or removed
+type controlWatcher struct {
+	path    string
+	modTime time.Time
+	exists  bool
+}
+
+// prime records the control file as it is, so that only changes made
+// from now on apply
+func (w *controlWatcher) prime() {
+	if info, err := os.Stat(w.path); err == nil {
+		w.exists, w.modTime = true, info.ModTime()
+	}
+}
+
+// poll re-reads the control file when it changed since the last poll.
+// changed is false when it didn't, or when it doesn't parse; a nil control
+// means the file is gone
+func (w *controlWatcher) poll() (ctl *runControl, changed bool) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) || !w.exists {
+			return nil, false
+		}
+		w.exists, w.modTime = false, time.Time{}
+		return nil, true
+	}
+	if w.exists && info.ModTime().Equal(w.modTime) {
+		return nil, false
+	}
+	w.exists, w.modTime = true, info.ModTime()
+	ctl, err = loadRunControl(w.path)
+	if err != nil {
+		log.Printf("⚠️  Ignoring %s: %v", w.path, err)
+		return nil, false
+	}
+	return ctl, true
+}
+
+// startSettings are the settings the run starts with
+func (o *Orchestrator) startSettings() runSettings {
+	s := runSettings{workers: o.workers, drainTimeout: o.config.DrainTimeout}
+	if o.backpressure != nil {
+		s.backpressure = o.backpressure.Limits()
+	}
+	return s
+}
+
+// runSettings returns the run's current settings
+func (o *Orchestrator) runSettings() runSettings {
+	o.settingsMu.Lock()
+	defer o.settingsMu.Unlock()
+	return o.settings
+}
+
+// watchControl applies the control file each time it's saved, on top of the
+// settings the run started with, until ctx is done
+func (o *Orchestrator) watchControl(ctx context.Context, startWorker func(id int)) {
+	start := o.startSettings()
+	w := &controlWatcher{path: filepath.Join(o.projectDir, controlFile)}
+	w.prime()
+
+	ticker := time.NewTicker(controlPoll)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-o.draining:
+			return
+		case <-ticker.C:
+		}
+		ctl, changed := w.poll()
+		if !changed {
+			continue
+		}
+		next := start.with(ctl)
+		o.settingsMu.Lock()
+		before := o.settings
+		o.settings = next
+		o.settingsMu.Unlock()
+
+		if changes := next.changes(before); changes != "" {
+			log.Printf("🎛️  Run settings changed: %s", changes)
+		}
+		if next.workers != before.workers {
+			o.crew.resize(next.workers, startWorker)
+		}
+		if next.backpressure != before.backpressure && o.backpressure != nil {
+			o.backpressure.SetLimits(next.backpressure)
+		}
+	}
+}
+
+// workerSet runs a run's workers and lets their number change while it
+// runs: lowering it retires workers once their task is done
+type workerSet struct {
+	wg sync.WaitGroup
+
+	mu      sync.Mutex
+	target  int
+	running map[int]uint64 // Worker ID -> the generation of its goroutine
+	gen     uint64
+	done    bool // Every worker exited; none are started again
+}
+
+// resize sets the number of workers, starting the missing ones with start
+func (s *workerSet) resize(n int, start func(id int)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.target = n
+	if s.done {
+		return
+	}
+	if s.running == nil {
+		s.running = make(map[int]uint64)
+	}
+	for id := 0; id < n; id++ {
+		if _, ok := s.running[id]; ok {
+			continue
+		}
+		s.gen++
+		gen := s.gen
+		s.running[id] = gen
+		s.wg.Add(1)
+		go func() {
+			defer s.exit(id, gen)
+			start(id)
+		}()
+	}
+}
+
+// retire reports whether worker id is past the number of workers, in which
+// case it must stop
+func (s *workerSet) retire(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if id < s.target {
+		return false
+	}
+	delete(s.running, id)
+	return true
+}
+
+// size returns the number of workers
+func (s *workerSet) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.target
+}
+
+func (s *workerSet) exit(id int, gen uint64) {
+	s.mu.Lock()
+	if s.running[id] == gen {
+		delete(s.running, id)
+	}
+	if len(s.running) == 0 {
+		s.done = true
+	}
+	s.mu.Unlock()
+	s.wg.Done()
+}
@@ -0,0 +1,111 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/backpressure"
+)
+
+func TestControlWatcher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "control.toml")
+	w := &controlWatcher{path: path}
+	w.prime()
+	if _, changed := w.poll(); changed {
+		t.Fatal("Expected no change without a control file")
+	}
+
+	write := func(content string, at time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write control file: %v", err)
+		}
+		os.Chtimes(path, at, at)
+	}
+	now := time.Now()
+	write("workers = 6\ntask_timeout = \"45m\"\n\n[backpressure]\nmax_concurrency = 6\n", now)
+	ctl, changed := w.poll()
+	if !changed || ctl == nil {
+		t.Fatal("Expected the new control file read")
+	}
+	start := runSettings{workers: 3, drainTimeout: 10 * time.Minute, backpressure: backpressure.Limits{MinConcurrency: 1, MaxConcurrency: 4}}
+	got := start.with(ctl)
+	want := runSettings{workers: 6, taskTimeout: 45 * time.Minute, drainTimeout: 10 * time.Minute, backpressure: backpressure.Limits{MinConcurrency: 1, MaxConcurrency: 6}}
+	if got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+	if _, changed := w.poll(); changed {
+		t.Error("Expected no change until the file is saved again")
+	}
+
+	// A file that doesn't parse is ignored, keeping the settings as they are
+	write("workers = -1\n", now.Add(time.Second))
+	if _, changed := w.poll(); changed {
+		t.Error("Expected a negative setting rejected")
+	}
+	write("wokers = 2\n", now.Add(2*time.Second))
+	if _, changed := w.poll(); changed {
+		t.Error("Expected an unknown setting rejected")
+	}
+
+	// Removing the file goes back to the settings the run started with
+	os.Remove(path)
+	ctl, changed = w.poll()
+	if !changed || ctl != nil || start.with(ctl) != start {
+		t.Errorf("Expected the start settings back once the file is removed, got %+v (changed %v)", ctl, changed)
+	}
+}
+
+func TestWorkerSet(t *testing.T) {
+	set := &workerSet{}
+	var mu sync.Mutex
+	started := map[int]int{}
+	var stop atomic.Bool
+	start := func(id int) {
+		mu.Lock()
+		started[id]++
+		mu.Unlock()
+		for !stop.Load() {
+			if set.retire(id) {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	set.resize(3, start)
+	set.resize(1, start)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		set.mu.Lock()
+		n := len(set.running)
+		set.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected workers 1 and 2 retired, %d still running", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	set.resize(2, start)
+	stop.Store(true)
+	set.wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if started[0] != 1 || started[1] != 2 || started[2] != 1 {
+		t.Errorf("Expected worker 0 started once and worker 1 restarted, got %v", started)
+	}
+
+	// Once every worker exited none start again
+	set.resize(3, start)
+	set.wg.Wait()
+	if started[0] != 1 {
+		t.Errorf("Expected no workers started after the set stopped, got %v", started)
+	}
+}
@@ -0,0 +1,243 @@
+package workflow
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxControlWorkers bounds the worker count the control API accepts
+const maxControlWorkers = 256
+
+// Run states reported by the control API
+const (
+	stateRunning  = "running"
+	statePaused   = "paused"
+	stateDraining = "draining"
+	stateAborting = "aborting"
+)
+
+// controlStatus is what the control API reports about the run
+type controlStatus struct {
+	State      string `json:"state"`
+	Workers    int    `json:"workers"`
+	Ready      int    `json:"ready"`
+	InProgress int    `json:"in_progress"`
+	Completed  int    `json:"completed"`
+	Failed     int    `json:"failed"`
+}
+
+// controlAPI serves the HTTP endpoints that steer a running run
+type controlAPI struct {
+	orch        *Orchestrator
+	token       string // Bearer token every request must carry; "" for none
+	host        string // Host the API listens on; "" for every interface
+	startWorker func(id int)
+}
+
+// serveControl starts the control API on addr and returns a function that
+// stops it. Without a token it only listens on a loopback address
+func (o *Orchestrator) serveControl(addr, token string, startWorker func(id int)) (func(), error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("control API: %w", err)
+	}
+	if !loopbackHost(host) && token == "" {
+		return nil, fmt.Errorf("control API: refusing to listen on %s without DROVER_CONTROL_TOKEN; use a loopback address such as localhost:7070 or set a token", addr)
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = ""
+	}
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("control API: %w", err)
+	}
+	api := &controlAPI{orch: o, token: token, host: host, startWorker: startWorker}
+	server := &http.Server{Handler: api.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("⚠️  Control API: %v", err)
+		}
+	}()
+	log.Printf("🕹️  Control API listening on http://%s/run", l.Addr())
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+func (a *controlAPI) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /run", a.handleStatus)
+	mux.HandleFunc("POST /run/pause", a.handlePause)
+	mux.HandleFunc("POST /run/resume", a.handleResume)
+	mux.HandleFunc("POST /run/workers", a.handleWorkers)
+	mux.HandleFunc("POST /run/drain", a.handleDrain)
+	mux.HandleFunc("POST /run/abort", a.handleAbort)
+	return a.authorize(mux)
+}
+
+// authorize rejects requests without the bearer token, when one is set. A
+// web page can't steer the run either way: requests for a Host other than
+// loopback or the listen address are rejected, which stops DNS rebinding,
+// cross-origin requests are rejected, and a POST must be JSON, which a
+// browser only sends cross-origin after a preflight the API never answers
+func (a *controlAPI) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.allowedHost(r.Host) {
+			http.Error(w, "unknown host", http.StatusForbidden)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || u.Host != r.Host {
+				http.Error(w, "cross-origin requests are not allowed", http.StatusForbidden)
+				return
+			}
+		}
+		if r.Method == http.MethodPost {
+			if media, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); media != "application/json" {
+				http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+		if a.token != "" {
+			given, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(given), []byte(a.token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether a request's Host names the API: a loopback
+// host, the host it listens on, or any host when it listens on every
+// interface, which it only does behind a token
+func (a *controlAPI) allowedHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if loopbackHost(host) {
+		return true
+	}
+	if a.host == "" {
+		return a.token != ""
+	}
+	return strings.EqualFold(host, a.host)
+}
+
+// loopbackHost reports whether host names this machine's loopback interface
+func loopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (a *controlAPI) handleStatus(w http.ResponseWriter, r *http.Request) {
+	a.respond(w)
+}
+
+func (a *controlAPI) handlePause(w http.ResponseWriter, r *http.Request) {
+	if a.orch.held.CompareAndSwap(false, true) {
+		log.Printf("⏸️  Run paused through the control API: no new tasks start until it's resumed; the ones in flight go on")
+	}
+	a.respond(w)
+}
+
+func (a *controlAPI) handleResume(w http.ResponseWriter, r *http.Request) {
+	if a.orch.held.CompareAndSwap(true, false) {
+		log.Printf("▶️  Run resumed through the control API")
+	}
+	a.respond(w)
+}
+
+func (a *controlAPI) handleWorkers(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Workers int `json:"workers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Workers < 1 || req.Workers > maxControlWorkers {
+		http.Error(w, fmt.Sprintf("workers must be between 1 and %d", maxControlWorkers), http.StatusBadRequest)
+		return
+	}
+	a.orch.setWorkers(req.Workers, a.startWorker)
+	a.respond(w)
+}
+
+func (a *controlAPI) handleDrain(w http.ResponseWriter, r *http.Request) {
+	if !a.orch.isDraining() {
+		log.Printf("🛑 Draining through the control API: no new tasks will start; waiting for the ones in flight")
+		a.orch.Drain()
+	}
+	a.respond(w)
+}
+
+func (a *controlAPI) handleAbort(w http.ResponseWriter, r *http.Request) {
+	if a.orch.shutdownCtx.Err() == nil {
+		log.Printf("🛑 Aborted through the control API, stopping in-flight tasks...")
+		a.orch.shutdownFunc()
+	}
+	a.respond(w)
+}
+
+// respond writes the run's status
+func (a *controlAPI) respond(w http.ResponseWriter) {
+	o := a.orch
+	status := controlStatus{State: stateRunning, Workers: o.crew.size()}
+	switch {
+	case o.shutdownCtx.Err() != nil:
+		status.State = stateAborting
+	case o.isDraining():
+		status.State = stateDraining
+	case o.held.Load():
+		status.State = statePaused
+	}
+	if project, err := o.store.GetProjectStatus(); err == nil {
+		status.Ready = project.Ready
+		status.InProgress = project.InProgress + project.Claimed
+		status.Completed = project.Completed
+		status.Failed = project.Failed
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// setWorkers changes the number of workers mid-run
+func (o *Orchestrator) setWorkers(n int, startWorker func(id int)) {
+	o.settingsMu.Lock()
+	before := o.settings.workers
+	o.settings.workers = n
+	o.settingsMu.Unlock()
+	if n != before {
+		log.Printf("🎛️  Run settings changed: workers %d → %d", before, n)
+		o.crew.resize(n, startWorker)
+	}
+}
+
+// isDraining reports whether the run stopped claiming tasks for good
+func (o *Orchestrator) isDraining() bool {
+	select {
+	case <-o.draining:
+		return true
+	default:
+		return false
+	}
+}
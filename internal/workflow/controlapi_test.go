@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+)
+
+func TestControlAPI(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	store.CreateTask("Queued", "", "", 0, nil)
+
+	o := &Orchestrator{store: store, crew: &workerSet{}, draining: make(chan struct{}), settings: runSettings{workers: 1}}
+	o.shutdownCtx, o.shutdownFunc = context.WithCancel(context.Background())
+	// Workers idle until the test ends
+	done := make(chan struct{})
+	startWorker := func(id int) {
+		for !o.crew.retire(id) {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+	defer func() { close(done); o.crew.wg.Wait() }()
+	o.crew.resize(1, startWorker)
+
+	api := &controlAPI{orch: o, token: "s3cret", host: "127.0.0.1", startWorker: startWorker}
+	server := httptest.NewServer(api.handler())
+	defer server.Close()
+
+	call := func(method, path, body string) (int, controlStatus) {
+		t.Helper()
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer s3cret")
+		if method == "POST" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		var status controlStatus
+		if resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+				t.Fatalf("Decoding %s %s: %v", method, path, err)
+			}
+		}
+		return resp.StatusCode, status
+	}
+
+	if resp, err := http.Get(server.URL + "/run"); err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("GET /run without the token = %v, %v; want 401", resp, err)
+	}
+	// A page elsewhere can't steer the run, even without a token
+	crossSite, _ := http.NewRequest("POST", server.URL+"/run/abort", strings.NewReader("{}"))
+	crossSite.Header.Set("Authorization", "Bearer s3cret")
+	crossSite.Header.Set("Content-Type", "application/json")
+	crossSite.Header.Set("Origin", "https://evil.example")
+	if resp, err := http.DefaultClient.Do(crossSite); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("Cross-origin POST /run/abort = %v, %v; want 403", resp, err)
+	}
+	// Nor can a page on a rebound name of the address
+	rebound, _ := http.NewRequest("GET", server.URL+"/run", nil)
+	rebound.Header.Set("Authorization", "Bearer s3cret")
+	rebound.Host = "attacker.example:7070"
+	if resp, err := http.DefaultClient.Do(rebound); err != nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("GET /run for another host = %v, %v; want 403", resp, err)
+	}
+	form, _ := http.NewRequest("POST", server.URL+"/run/abort", strings.NewReader("workers=1"))
+	form.Header.Set("Authorization", "Bearer s3cret")
+	form.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if resp, err := http.DefaultClient.Do(form); err != nil || resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("Form POST /run/abort = %v, %v; want 415", resp, err)
+	}
+
+	if code, status := call("GET", "/run", ""); code != http.StatusOK || status.State != stateRunning || status.Workers != 1 || status.Ready != 1 {
+		t.Fatalf("GET /run = %d %+v; want running with 1 worker and 1 ready task", code, status)
+	}
+
+	if _, status := call("POST", "/run/pause", ""); status.State != statePaused || !o.held.Load() {
+		t.Errorf("Expected the run paused, got %+v", status)
+	}
+	if _, status := call("POST", "/run/resume", ""); status.State != stateRunning || o.held.Load() {
+		t.Errorf("Expected the run resumed, got %+v", status)
+	}
+
+	if code, _ := call("POST", "/run/workers", `{"workers": 0}`); code != http.StatusBadRequest {
+		t.Errorf("Expected 0 workers rejected, got %d", code)
+	}
+	if _, status := call("POST", "/run/workers", `{"workers": 3}`); status.Workers != 3 || o.runSettings().workers != 3 {
+		t.Errorf("Expected 3 workers, got %+v", status)
+	}
+
+	if _, status := call("POST", "/run/drain", ""); status.State != stateDraining || !o.isDraining() {
+		t.Errorf("Expected the run draining, got %+v", status)
+	}
+	if _, status := call("POST", "/run/abort", ""); status.State != stateAborting || o.shutdownCtx.Err() == nil {
+		t.Errorf("Expected the run aborting, got %+v", status)
+	}
+
+	if _, err := o.serveControl(":0", "", startWorker); err == nil {
+		t.Error("Expected the API refused on every interface without a token")
+	}
+}
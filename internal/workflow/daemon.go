@@ -0,0 +1,58 @@
+package workflow
+
+import (
+	"log"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/git"
+)
+
+// daemonIdle follows the queue of a run in daemon mode, which doesn't end
+// when the queue empties but waits for tasks to be added. Once the queue has
+// been empty for idleAfter, the worktree pool is scaled down; new work
+// scales it back up
+type daemonIdle struct {
+	pool      *git.WorktreePool
+	idleAfter time.Duration
+
+	emptySince time.Time // Zero while there is work
+	scaledDown bool
+}
+
+// newDaemonIdle returns the idle tracker of a daemon run, scaling pool down
+// after idleAfter; 0 never scales it down
+func newDaemonIdle(pool *git.WorktreePool, idleAfter time.Duration) *daemonIdle {
+	if pool != nil && !pool.IsEnabled() {
+		pool = nil
+	}
+	return &daemonIdle{pool: pool, idleAfter: idleAfter}
+}
+
+// observe takes the number of active (ready, claimed or in progress) tasks at
+// now. Reports whether the queue just emptied
+func (d *daemonIdle) observe(active int, now time.Time) bool {
+	if active > 0 {
+		if !d.emptySince.IsZero() {
+			log.Printf("📥 %d new task(s) to run", active)
+			d.emptySince = time.Time{}
+		}
+		if d.scaledDown {
+			log.Println("🚀 Warming the worktree pool back up")
+			d.pool.SetIdle(false)
+			d.scaledDown = false
+		}
+		return false
+	}
+
+	if d.emptySince.IsZero() {
+		d.emptySince = now
+		log.Println("💤 Queue empty; waiting for new tasks (Ctrl-C to stop)")
+		return true
+	}
+	if d.pool != nil && !d.scaledDown && d.idleAfter > 0 && now.Sub(d.emptySince) >= d.idleAfter {
+		log.Printf("💤 Idle for %v; scaling the worktree pool down", d.idleAfter)
+		d.pool.SetIdle(true)
+		d.scaledDown = true
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/dbos-inc/dbos-transact-golang/dbos"
+)
+
+// taskWorkflowIDPrefix starts the ID of every workflow executing an attempt
+// of a task
+func taskWorkflowIDPrefix(taskID string) string {
+	return fmt.Sprintf("drover-%s-attempt-", taskID)
+}
+
+// taskWorkflowID is the ID of the workflow executing one attempt of a task
+func taskWorkflowID(taskID string, attempt int) string {
+	return fmt.Sprintf("%s%d", taskWorkflowIDPrefix(taskID), attempt)
+}
+
+// nextTaskWorkflowID picks the workflow ID for a task's next attempt given
+// the workflows already recorded for the task. The attempt after the recorded
+// ones comes first; while its workflow already finished, because the task was
+// reset or requeued since, the next number is tried. attached reports that
+// the workflow is still queued or running, so enqueueing it again attaches
+// to it instead of running the task twice
+func nextTaskWorkflowID(taskID string, attempts int, existing map[string]dbos.WorkflowStatusType) (id string, attached bool) {
+	for attempt := attempts + 1; ; attempt++ {
+		id = taskWorkflowID(taskID, attempt)
+		status, ok := existing[id]
+		switch {
+		case !ok:
+			return id, false
+		case status == dbos.WorkflowStatusPending || status == dbos.WorkflowStatusEnqueued:
+			return id, true
+		}
+	}
+}
+
+// workflowIDFor derives the ID of the workflow that executes a task's next
+// attempt, so a second 'drover run' attaches to executions the first one
+// queued instead of duplicating their work
+func (o *DBOSOrchestrator) workflowIDFor(task TaskInput) (id string, attached bool) {
+	attempts := 0
+	if o.store != nil {
+		if t, err := o.store.GetTask(task.TaskID); err == nil {
+			attempts = t.Attempts
+		}
+	}
+
+	existing := make(map[string]dbos.WorkflowStatusType)
+	statuses, err := dbos.ListWorkflows(o.dbosCtx,
+		dbos.WithWorkflowIDPrefix(taskWorkflowIDPrefix(task.TaskID)),
+		dbos.WithLoadInput(false),
+		dbos.WithLoadOutput(false),
+	)
+	if err != nil {
+		log.Printf("⚠️  Listing workflows of task %s: %v", task.TaskID, err)
+	}
+	for _, s := range statuses {
+		existing[s.ID] = s.Status
+	}
+	return nextTaskWorkflowID(task.TaskID, attempts, existing)
+}
+
+// TaskWorkflowID is the workflow ID a task's next attempt was enqueued
+// under, recorded as a step of the workflow enqueueing it
+type TaskWorkflowID struct {
+	ID       string
+	Attached bool
+}
+
+// enqueueTask enqueues the workflow executing a task's next attempt under its
+// derived ID. Enqueued from a workflow's ctx, the task workflow is its child,
+// and its ID is derived in a step, so a recovered workflow enqueues the same
+// child instead of deriving a new ID from what changed since. attached
+// reports it was already queued or running
+func (o *DBOSOrchestrator) enqueueTask(ctx dbos.DBOSContext, task TaskInput) (handle dbos.WorkflowHandle[TaskResult], attached bool, err error) {
+	var id string
+	if _, notInWorkflow := dbos.GetWorkflowID(ctx); notInWorkflow != nil {
+		id, attached = o.workflowIDFor(task)
+	} else {
+		derived, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (TaskWorkflowID, error) {
+			id, attached := o.workflowIDFor(task)
+			return TaskWorkflowID{ID: id, Attached: attached}, nil
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("deriving the workflow ID of task %s: %w", task.TaskID, err)
+		}
+		id, attached = derived.ID, derived.Attached
+	}
+	handle, err = dbos.RunWorkflow(ctx, o.ExecuteTaskWorkflow, task,
+		dbos.WithQueue(o.queue.Name),
+		dbos.WithWorkflowID(id),
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if attached {
+		log.Printf("🔗 Task %s is already queued or running as workflow %s; attaching to it", task.TaskID, id)
+	} else {
+		log.Printf("📤 Enqueued task %s: %s", task.TaskID, task.Title)
+	}
+	return handle, attached, nil
+}
@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/dbos-inc/dbos-transact-golang/dbos"
+)
+
+func TestNextTaskWorkflowID(t *testing.T) {
+	tests := []struct {
+		name         string
+		attempts     int
+		existing     map[string]dbos.WorkflowStatusType
+		wantID       string
+		wantAttached bool
+	}{
+		{
+			name:   "first run",
+			wantID: "drover-task-1-attempt-1",
+		},
+		{
+			name:     "after failed attempts",
+			attempts: 2,
+			wantID:   "drover-task-1-attempt-3",
+		},
+		{
+			name:         "queued by an earlier run",
+			existing:     map[string]dbos.WorkflowStatusType{"drover-task-1-attempt-1": dbos.WorkflowStatusEnqueued},
+			wantID:       "drover-task-1-attempt-1",
+			wantAttached: true,
+		},
+		{
+			name:         "running in an earlier run",
+			existing:     map[string]dbos.WorkflowStatusType{"drover-task-1-attempt-1": dbos.WorkflowStatusPending},
+			wantID:       "drover-task-1-attempt-1",
+			wantAttached: true,
+		},
+		{
+			name: "reset after its workflows finished",
+			existing: map[string]dbos.WorkflowStatusType{
+				"drover-task-1-attempt-1": dbos.WorkflowStatusSuccess,
+				"drover-task-1-attempt-2": dbos.WorkflowStatusCancelled,
+			},
+			wantID: "drover-task-1-attempt-3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, attached := nextTaskWorkflowID("task-1", tt.attempts, tt.existing)
+			if id != tt.wantID || attached != tt.wantAttached {
+				t.Errorf("Expected %s (attached %v), got %s (attached %v)", tt.wantID, tt.wantAttached, id, attached)
+			}
+		})
+	}
+}
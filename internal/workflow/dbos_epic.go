@@ -0,0 +1,111 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dbos-inc/dbos-transact-golang/dbos"
+)
+
+// epicProgressEvent is the DBOS event an epic workflow publishes its
+// progress under
+const epicProgressEvent = "progress"
+
+// EpicInput is the input of the workflow executing an epic's ready tasks
+type EpicInput struct {
+	EpicID string
+	Tasks  []TaskInput
+}
+
+// EpicRunProgress is how far an epic workflow got, published as its
+// "progress" event each time one of its tasks finishes
+type EpicRunProgress struct {
+	EpicID    string
+	Total     int
+	Completed int
+	Failed    int
+}
+
+// epicWorkflowID is the ID of the workflow executing an epic's tasks within
+// the run workflow runID
+func epicWorkflowID(runID, epicID string) string {
+	return fmt.Sprintf("%s-epic-%s", runID, epicID)
+}
+
+// groupByEpic splits tasks into one group per epic, in the order the epics
+// first appear, and the tasks that belong to no epic
+func groupByEpic(tasks []TaskInput) (epics []EpicInput, loose []TaskInput) {
+	index := make(map[string]int)
+	for _, task := range tasks {
+		if task.EpicID == "" {
+			loose = append(loose, task)
+			continue
+		}
+		i, ok := index[task.EpicID]
+		if !ok {
+			i = len(epics)
+			index[task.EpicID] = i
+			epics = append(epics, EpicInput{EpicID: task.EpicID})
+		}
+		epics[i].Tasks = append(epics[i].Tasks, task)
+	}
+	return epics, loose
+}
+
+// ExecuteEpicWorkflow is a DBOS workflow that enqueues an epic's tasks as its
+// child workflows and waits for them, so DBOS shows each task under its epic
+// and the epic's progress can be read from its "progress" event. It holds no
+// queue slot itself; its tasks are limited by the queue as usual
+func (o *DBOSOrchestrator) ExecuteEpicWorkflow(ctx dbos.DBOSContext, input EpicInput) (QueueStats, error) {
+	start := time.Now()
+	log.Printf("🗂️  Epic %s: enqueuing %d task(s)", input.EpicID, len(input.Tasks))
+
+	var handles []dbos.WorkflowHandle[TaskResult]
+	stats := QueueStats{}
+	for _, task := range input.Tasks {
+		handle, attachedTo, err := o.enqueueTask(ctx, task)
+		if err != nil {
+			log.Printf("❌ Failed to enqueue task %s: %v", task.TaskID, err)
+			stats.Failed++
+			continue
+		}
+		handles = append(handles, handle)
+		if attachedTo {
+			stats.Attached++
+		}
+	}
+	stats.TotalEnqueued = len(handles)
+
+	progress := EpicRunProgress{EpicID: input.EpicID, Total: len(input.Tasks), Failed: stats.Failed}
+	for _, handle := range handles {
+		result, err := handle.GetResult()
+		switch {
+		case err != nil:
+			log.Printf("❌ Task failed: %v", err)
+			stats.Failed++
+		case result.Success:
+			stats.Completed++
+		default:
+			stats.Failed++
+		}
+		progress.Completed, progress.Failed = stats.Completed, stats.Failed
+		if err := dbos.SetEvent(ctx, epicProgressEvent, progress); err != nil {
+			log.Printf("⚠️  Publishing progress of epic %s: %v", input.EpicID, err)
+		}
+	}
+
+	stats.Duration = time.Since(start)
+	log.Printf("🗂️  Epic %s: %d completed, %d failed of %d task(s) in %v",
+		input.EpicID, stats.Completed, stats.Failed, progress.Total, stats.Duration.Round(time.Second))
+	return stats, nil
+}
+
+// startEpic starts the child workflow executing an epic's tasks
+func (o *DBOSOrchestrator) startEpic(ctx dbos.DBOSContext, epic EpicInput) (dbos.WorkflowHandle[QueueStats], error) {
+	runID, err := dbos.GetWorkflowID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading the run's workflow ID: %w", err)
+	}
+	return dbos.RunWorkflow(ctx, o.ExecuteEpicWorkflow, epic, dbos.WithWorkflowID(epicWorkflowID(runID, epic.EpicID)))
+}
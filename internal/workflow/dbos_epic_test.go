@@ -0,0 +1,25 @@
+package workflow
+
+import "testing"
+
+func TestGroupByEpic(t *testing.T) {
+	tasks := []TaskInput{
+		{TaskID: "task-1", EpicID: "epic-b"},
+		{TaskID: "task-2"},
+		{TaskID: "task-3", EpicID: "epic-a"},
+		{TaskID: "task-4", EpicID: "epic-b"},
+	}
+	epics, loose := groupByEpic(tasks)
+	if len(epics) != 2 || epics[0].EpicID != "epic-b" || epics[1].EpicID != "epic-a" {
+		t.Fatalf("Expected epics epic-b and epic-a in order, got %+v", epics)
+	}
+	if len(epics[0].Tasks) != 2 || epics[0].Tasks[1].TaskID != "task-4" {
+		t.Errorf("Expected task-1 and task-4 under epic-b, got %+v", epics[0].Tasks)
+	}
+	if len(loose) != 1 || loose[0].TaskID != "task-2" {
+		t.Errorf("Expected task-2 without an epic, got %+v", loose)
+	}
+	if id := epicWorkflowID("run-1", "epic-a"); id != "run-1-epic-epic-a" {
+		t.Errorf("Expected run-1-epic-epic-a, got %s", id)
+	}
+}
@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestDBOSOrchestrator_FailOrRequeue(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	o := &DBOSOrchestrator{store: store, retry: RetryPolicy{}}
+	task, _ := store.CreateTask("Unmet definition of done", "", "", 0, nil)
+	for attempt := 1; attempt <= task.MaxAttempts; attempt++ {
+		if !o.failOrRequeue(task.ID, FailureDoD, "tests not added") {
+			t.Fatalf("Expected attempt %d to be retried", attempt)
+		}
+		got, _ := store.GetTask(task.ID)
+		if got.Status != types.TaskStatusReady || got.Attempts != attempt || got.LastError != "tests not added" {
+			t.Fatalf("Expected the task back in the queue after attempt %d, got %s with %d attempts (%q)", attempt, got.Status, got.Attempts, got.LastError)
+		}
+	}
+	if o.failOrRequeue(task.ID, FailureDoD, "tests not added") {
+		t.Fatal("Expected the last attempt to fail the task")
+	}
+	if got, _ := store.GetTask(task.ID); got.Status != types.TaskStatusFailed {
+		t.Errorf("Expected the task failed once out of attempts, got %s", got.Status)
+	}
+
+	// A policy that doesn't retry the class fails the task at once
+	o.retry = RetryPolicy{RetryOn: []FailureClass{FailureAgent}}
+	other, _ := store.CreateTask("Not retried", "", "", 0, nil)
+	if o.failOrRequeue(other.ID, FailureDoD, "tests not added") {
+		t.Error("Expected a dod failure not to be retried by a policy retrying agent failures only")
+	}
+	if got, _ := store.GetTask(other.ID); got.Status != types.TaskStatusFailed || got.Attempts != 0 {
+		t.Errorf("Expected the task failed on its first attempt, got %s with %d attempts", got.Status, got.Attempts)
+	}
+}
@@ -0,0 +1,99 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"github.com/dbos-inc/dbos-transact-golang/dbos"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// executeSubTasksDBOS runs a parent task's sub-tasks in sequence order before
+// the parent itself, each in its own worktree and as durable steps, mirroring
+// Orchestrator.executeSubTasks. Sub-tasks that already completed are skipped,
+// so a retried parent resumes at the first unfinished one
+func (o *DBOSOrchestrator) executeSubTasksDBOS(ctx dbos.DBOSContext, parent TaskInput, span trace.Span) error {
+	subTasks, err := o.store.GetSubTasks(parent.TaskID)
+	if err != nil {
+		return fmt.Errorf("getting sub-tasks: %w", err)
+	}
+	if len(subTasks) == 0 {
+		return nil
+	}
+
+	log.Printf("📋 Executing %d sub-tasks for %s", len(subTasks), parent.TaskID)
+
+	for _, subTask := range subTasks {
+		if subTask.Status == types.TaskStatusCompleted {
+			continue
+		}
+		log.Printf("📋 Executing sub-task %s: %s", subTask.ID, subTask.Title)
+		start := time.Now()
+
+		if err := o.store.UpdateTaskStatus(subTask.ID, types.TaskStatusInProgress, ""); err != nil {
+			log.Printf("⚠️  Error updating sub-task status: %v", err)
+		}
+
+		input := TaskInput{
+			TaskID:      subTask.ID,
+			Title:       subTask.Title,
+			Description: subTask.Description,
+			EpicID:      parent.EpicID,
+			Priority:    subTask.Priority,
+			MaxAttempts: subTask.MaxAttempts,
+			Repo:        parent.Repo, // Sub-tasks work in their parent's repository
+		}
+
+		worktreePath, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (string, error) {
+			return o.createWorktreeStep(stepCtx, input)
+		}, dbos.WithStepMaxRetries(3))
+		if err != nil {
+			return o.failSubTask(subTask.ID, fmt.Errorf("creating worktree: %w", err))
+		}
+
+		result, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (*executor.ExecutionResult, error) {
+			return o.executeClaudeStep(stepCtx, worktreePath, input, span)
+		}, o.agentStepOptions(subTask.ID)...)
+		if err != nil {
+			return o.failSubTask(subTask.ID, fmt.Errorf("agent error: %w", err))
+		}
+
+		commit, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (CommitStepResult, error) {
+			return o.commitChangesStep(stepCtx, input, result.Output)
+		}, dbos.WithStepMaxRetries(3))
+		if err != nil {
+			return o.failSubTask(subTask.ID, err)
+		}
+		if len(commit.ProtectedPaths) > 0 {
+			return o.failSubTask(subTask.ID, &git.ProtectedPathError{Paths: commit.ProtectedPaths})
+		}
+
+		if _, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
+			return o.mergeToMainStep(stepCtx, input)
+		}, dbos.WithStepMaxRetries(3)); err != nil {
+			log.Printf("⚠️  Sub-task %s completed but merge failed: %v", subTask.ID, err)
+		}
+
+		if err := o.store.CompleteTask(subTask.ID); err != nil {
+			log.Printf("⚠️  Error completing sub-task: %v", err)
+		}
+		log.Printf("✅ Completed sub-task %s in %v", subTask.ID, time.Since(start))
+	}
+
+	log.Printf("✅ All %d sub-tasks completed for %s", len(subTasks), parent.TaskID)
+	return nil
+}
+
+// failSubTask marks a sub-task failed and returns the error for its parent
+func (o *DBOSOrchestrator) failSubTask(taskID string, err error) error {
+	log.Printf("❌ Sub-task %s failed: %v", taskID, err)
+	if updateErr := o.store.UpdateTaskStatus(taskID, types.TaskStatusFailed, err.Error()); updateErr != nil {
+		log.Printf("⚠️  Error updating sub-task status to failed: %v", updateErr)
+	}
+	return fmt.Errorf("sub-task %s: %w", taskID, err)
+}
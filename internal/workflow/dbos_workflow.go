@@ -4,9 +4,11 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -16,14 +18,19 @@ import (
 	"time"
 
 	"github.com/cloud-shuttle/drover/internal/analytics"
+	"github.com/cloud-shuttle/drover/internal/attemptlog"
 	"github.com/cloud-shuttle/drover/internal/config"
 	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
 	"github.com/cloud-shuttle/drover/internal/dashboard"
 	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/diagnostics"
+	"github.com/cloud-shuttle/drover/internal/dod"
 	"github.com/cloud-shuttle/drover/internal/events"
-	outcomepkg "github.com/cloud-shuttle/drover/internal/outcome"
 	"github.com/cloud-shuttle/drover/internal/executor"
 	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/internal/integrations"
+	"github.com/cloud-shuttle/drover/internal/notify"
+	"github.com/cloud-shuttle/drover/internal/output"
 	"github.com/cloud-shuttle/drover/internal/project"
 	"github.com/cloud-shuttle/drover/internal/testing"
 	"github.com/cloud-shuttle/drover/internal/webhooks"
@@ -45,6 +52,10 @@ type TaskInput struct {
 	MaxAttempts int
 	// BlockedBy lists task IDs that must complete before this task can run
 	BlockedBy []string
+	// MutexKey, when set, keeps the task from executing alongside others with the same key
+	MutexKey string
+	// Repo names the repository the task works in; empty is the project's own
+	Repo string
 }
 
 // TaskResult represents the output of a task execution step
@@ -57,6 +68,13 @@ type TaskResult struct {
 	CommitHash string
 }
 
+// CommitStepResult is the durable output of the commit step
+type CommitStepResult struct {
+	HasChanges bool
+	// ProtectedPaths lists changes that were unstaged because they touched protected paths
+	ProtectedPaths []string
+}
+
 // QueuedTasksInput represents input for the queue-based workflow
 type QueuedTasksInput struct {
 	Tasks []TaskInput
@@ -65,6 +83,7 @@ type QueuedTasksInput struct {
 // QueueStats represents statistics about queue execution
 type QueueStats struct {
 	TotalEnqueued int
+	Attached      int // Task workflows already queued or running from an earlier run, attached to instead of duplicated
 	Completed     int
 	Failed        int
 	Duration      time.Duration
@@ -76,14 +95,34 @@ type DBOSOrchestrator struct {
 	git            *git.WorktreeManager
 	pool           *git.WorktreePool // Worktree pool for pre-warming
 	agent          executor.Agent // Agent interface for Claude/Codex/Amp
+	diagnostics    *diagnostics.Checker // Static checks fed back to the agent (nil disables)
+	dod            *dod.Checklist       // Definition of done checked before merge (nil disables)
+	verify         *verifier            // Verification commands run before merge (nil disables)
 	dbosCtx        dbos.DBOSContext
 	queue          dbos.WorkflowQueue
+	gate           *queueGate // Queue limits as changed on the live deployment
+	hedge          *hedger    // Races stuck tasks against a second attempt (nil = never)
+	breaker        *failureBreaker // Parks the run when tasks keep failing the same way (nil = never)
 	store          *db.Store // SQLite store for worktree tracking
 	verbose        bool
 	dependencyMap  map[string][]string // taskID -> list of dependent task IDs
 	dependencyMu   sync.RWMutex
 	webhooks       *webhooks.Manager // Webhook notification manager
+	statuses       *webhooks.StatusReporter // Commit status checks (PR mode only)
+	jira           *integrations.JiraSync   // Status transitions pushed to Jira (nil when not configured)
+	notifier       *notify.Notifier         // Chat notifications (nil when not configured)
 	analytics      *analytics.Manager // Analytics manager
+	projectDir     string             // Project directory, for per-attempt output logs
+	concurrency    *concurrencyStats  // Busy time and serialization waits for the run summary
+	usage          runUsage           // Tokens and cost spent this run
+	retry          RetryPolicy        // Backoff between retries of the agent step and of failed tasks
+	mutexes        mutexKeys          // One executing task per mutex key
+	deadline       *runDeadline       // Wall-clock budget of the run (nil = none)
+	report         *runReporter       // Merge results for the run report
+	repos          *git.RepoManager   // Worktree managers per repository, the project's own (git) included
+	env            map[string]string  // Agent environment defaults from [env] in .drover.toml
+	mcp            map[string]executor.MCPServer // MCP servers from [mcp_servers] in .drover.toml
+	logRotation    attemptlog.Rotation // Rotation of the attempt logs, from [logs] in .drover.toml
 }
 
 // NewDBOSOrchestrator creates a new DBOS-based orchestrator
@@ -94,9 +133,45 @@ func NewDBOSOrchestrator(cfg *config.Config, dbosCtx dbos.DBOSContext, projectDi
 	)
 	gitMgr.SetVerbose(cfg.Verbose)
 
+	isolationMode, err := git.ParseIsolationMode(cfg.IsolationMode)
+	if err != nil {
+		return nil, err
+	}
+	gitMgr.SetIsolationMode(isolationMode)
+	gitMgr.SetBranchTemplate(cfg.BranchPrefix, cfg.BranchTemplate)
+	gitMgr.SetNetworkTimeout(cfg.GitNetworkTimeout)
+
+	// Load project configuration
+	projectCfg, err := project.Load(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	// Validate project config
+	if err := projectCfg.Validate(); err != nil {
+		log.Printf("[project] warning: %v", err)
+	}
+	checklist, err := dod.New(projectCfg.DefinitionOfDone, projectCfg.DoDPolicy)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := configuredRetryPolicy(cfg, projectCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Give an empty repository a root commit and a scaffold task before
+	// anything (including the pool) tries to branch from it
+	if err := bootstrapEmptyRepo(cfg, projectCfg, store, projectDir); err != nil {
+		return nil, err
+	}
+
 	// Initialize worktree pool if enabled
+	// The pool pre-creates linked worktrees, so it is skipped in clone mode
 	var pool *git.WorktreePool
-	if cfg.PoolEnabled {
+	if cfg.PoolEnabled && isolationMode == git.IsolationClone {
+		log.Printf("⚠️  Worktree pooling is not supported in clone isolation mode, disabling pool")
+	} else if cfg.PoolEnabled {
 		poolConfig := &git.PoolConfig{
 			MinSize:         cfg.PoolMinSize,
 			MaxSize:         cfg.PoolMaxSize,
@@ -104,26 +179,69 @@ func NewDBOSOrchestrator(cfg *config.Config, dbosCtx dbos.DBOSContext, projectDi
 			CleanupOnExit:   cfg.PoolCleanupOnExit,
 			EnableSymlinks:  true,
 			GoModCache:      true,
+
+			GoBuildCache:              cfg.PoolGoBuildCache,
+			GoBuildCacheMode:          cfg.PoolGoBuildCacheMode,
+			GoBuildCacheMaxMB:         cfg.PoolGoBuildCacheMaxMB,
+			GoBuildCacheCleanInterval: cfg.PoolGoBuildCacheCleanInterval,
+
+			FetchInterval:  cfg.PoolFetchInterval,
+			FetchJitter:    cfg.PoolFetchJitter,
+			FetchSkipInUse: cfg.PoolFetchSkipInUse,
+			StaleAfter:     cfg.PoolStaleAfter,
+			StalePolicy:    cfg.PoolStalePolicy,
+
+			AdaptiveMaxSize: cfg.PoolAdaptiveMaxSize,
+			DiskReserveMB:   cfg.PoolDiskReserveMB,
+			MemoryReserveMB: cfg.PoolMemoryReserveMB,
+			SizingInterval:  cfg.PoolSizingInterval,
 		}
 		pool = git.NewWorktreePool(gitMgr, poolConfig)
+		pool.FollowWorkers(func() int { return cfg.Workers })
 		if err := pool.Start(); err != nil {
 			return nil, fmt.Errorf("starting worktree pool: %w", err)
 		}
 	}
 
-	// Load project configuration
-	projectCfg, err := project.Load(projectDir)
+	// Merge project config with global config
+	projectCfg.MergeWithGlobal(cfg.AgentType, cfg.Workers, cfg.TaskTimeout, cfg.MaxTaskAttempts)
+
+	// Protected paths from .drover.toml and DROVER_PROTECTED_PATHS
+	if protected := projectCfg.GetProtectedPaths(cfg.ProtectedPaths); len(protected) > 0 {
+		gitMgr.SetProtectedPaths(protected)
+		log.Printf("[project] protected paths: %v", protected)
+	}
+
+	// Merge target: explicit override, then .drover.toml, then the detected default branch
+	targetOverride := cfg.TargetBranch
+	if targetOverride == "" {
+		targetOverride = projectCfg.TargetBranch
+	}
+	targetBranch, err := git.ResolveTargetBranch(projectDir, targetOverride, cfg.BranchPrefix)
 	if err != nil {
-		return nil, fmt.Errorf("loading project config: %w", err)
+		if targetOverride != "" {
+			if pool != nil {
+				pool.Stop()
+			}
+			return nil, fmt.Errorf("validating target branch: %w", err)
+		}
+		log.Printf("[git] warning: %v; merging into main", err)
+	} else {
+		gitMgr.SetTargetBranch(targetBranch)
+		if cfg.Verbose {
+			log.Printf("[git] merge target branch: %s", targetBranch)
+		}
 	}
 
-	// Validate project config
-	if err := projectCfg.Validate(); err != nil {
-		log.Printf("[project] warning: %v", err)
+	// Attribute task commits to the agent that produced them
+	if cfg.CommitAttribution {
+		gitMgr.SetCommitAuthor(git.RenderCommitAuthor(cfg.CommitAuthorName, cfg.CommitAuthorEmail, projectCfg.Agent, cfg.AgentModel))
 	}
 
-	// Merge project config with global config
-	projectCfg.MergeWithGlobal(cfg.AgentType, cfg.Workers, cfg.TaskTimeout, cfg.MaxTaskAttempts)
+	// Persist worktree lifecycle (path, branch, state, setup time, disk usage)
+	if store != nil {
+		gitMgr.SetRecorder(store)
+	}
 
 	// Create the agent based on configuration with project guidelines
 	agentType := projectCfg.Agent
@@ -135,14 +253,72 @@ func NewDBOSOrchestrator(cfg *config.Config, dbosCtx dbos.DBOSContext, projectDi
 		}
 	}
 
+	sandbox, err := newSandbox(projectCfg.Sandbox, projectDir)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, fmt.Errorf("configuring sandbox: %w", err)
+	}
+	prompts, err := loadPromptTemplates(projectDir)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, err
+	}
+	fallbacks, err := configuredModelFallback(cfg, projectCfg)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, err
+	}
+	maxOutput, err := configuredMaxOutput(cfg, projectCfg)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, err
+	}
+	logRotation, err := setUpAttemptLogs(projectDir, projectCfg.Logs)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, err
+	}
 	agent, err := executor.NewAgent(&executor.AgentConfig{
 		Type:              agentType,
 		Path:              cfg.AgentPath,
+		Model:             cfg.AgentModel,
+		Fallbacks:         fallbacks,
+		Provider:          cfg.AgentProvider,
+		Custom: executor.CustomAgentConfig{
+			Command:        projectCfg.CustomAgent.Command,
+			SuccessPattern: projectCfg.CustomAgent.SuccessPattern,
+			FailurePattern: projectCfg.CustomAgent.FailurePattern,
+			VerdictPattern: projectCfg.CustomAgent.VerdictPattern,
+		},
 		Timeout:           projectCfg.TaskTimeout,
 		Verbose:           cfg.Verbose,
 		ProjectGuidelines: projectCfg.GetGuidelines(),
 		WorkerBinary:      cfg.WorkerBinary,
 		WorkerMemoryLimit: cfg.WorkerMemoryLimit,
+		WorkerProtocol:    cfg.WorkerProtocol,
+		RemoteWorkers:     remoteWorkers(cfg),
+		WorkerPoolSize:    cfg.WorkerPoolSize,
+		WorkerPoolMaxTasks: cfg.WorkerPoolMaxTasks,
+		CPULimit:          cpuLimit(projectCfg),
+		OpenCodeURL:       cfg.OpenCodeURL,
+		StreamEvents:      cfg.DashboardPort != "",
+		Resume:            cfg.ClaudeResume,
+		LLMURL:            cfg.LLMURL,
+		LLMAPIKey:         cfg.LLMAPIKey,
+		OpenCodeServers:   cfg.OpenCodeServers,
+		Sandbox:           sandbox,
+		PromptTemplates:   prompts,
+		MaxOutput:         maxOutput,
 		ContextThresholds: &ctxmngr.ContentThresholds{
 			MaxDescriptionSize: projectCfg.MaxDescriptionSize,
 			MaxDiffSize:       projectCfg.MaxDiffSize,
@@ -172,11 +348,17 @@ func NewDBOSOrchestrator(cfg *config.Config, dbosCtx dbos.DBOSContext, projectDi
 		return nil, fmt.Errorf("checking %s: %w", cfg.AgentType, err)
 	}
 
-	// Create a workflow queue for parallel task execution
-	// Use a shorter polling interval for faster task processing
+	// Create a workflow queue for parallel task execution, under the
+	// configured limits. Use a shorter polling interval for faster task processing
+	gate := newQueueGate(store, cfg)
 	queue := dbos.NewWorkflowQueue(dbosCtx, "drover-tasks",
-		dbos.WithQueueBasePollingInterval(10*time.Millisecond), // Poll every 10ms for faster execution
+		append(gate.started.queueOptions(),
+			dbos.WithQueueBasePollingInterval(10*time.Millisecond), // Poll every 10ms for faster execution
+		)...,
 	)
+	if gate.started != (queueLimitValues{}) {
+		log.Printf("🎚️  Queue limits: %s", gate.started)
+	}
 
 	// Create webhook manager
 	webhookMgr := cfg.CreateWebhookManager()
@@ -184,18 +366,49 @@ func NewDBOSOrchestrator(cfg *config.Config, dbosCtx dbos.DBOSContext, projectDi
 	// Create analytics manager
 	analyticsMgr, _ := cfg.CreateAnalyticsManager()
 
+	concurrency := newConcurrencyStats()
+	gitMgr.SetWaitObserver(concurrency.observeWait)
+
+	// Worktree managers of the other repositories tasks may work in
+	repos, err := newRepoManager(cfg, projectCfg, projectDir, gitMgr, store, concurrency.observeWait)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		executor.CloseAgent(agent)
+		return nil, err
+	}
+
 	return &DBOSOrchestrator{
 		config:        cfg,
 		git:           gitMgr,
+		repos:         repos,
 		pool:          pool,
 		agent:         agent,
+		diagnostics:   newDiagnosticsChecker(cfg, projectCfg),
+		dod:           checklist,
+		verify:        newVerifier(projectCfg),
 		dbosCtx:       dbosCtx,
 		queue:         queue,
+		gate:          gate,
+		hedge:         newHedger(store, cfg.HedgeFactor, cfg.HedgeBudget),
+		breaker:       newFailureBreaker(cfg.FailureStreak),
 		store:         store,
 		verbose:       cfg.Verbose,
 		dependencyMap: make(map[string][]string),
 		webhooks:      webhookMgr,
+		statuses:      cfg.CreateStatusReporter(),
+		jira:          cfg.CreateJiraSync(),
+		notifier:      cfg.CreateNotifier(),
 		analytics:     analyticsMgr,
+		projectDir:    projectDir,
+		concurrency:   concurrency,
+		retry:         retry,
+		deadline:      newRunDeadline(store, cfg.MaxDuration),
+		report:        newRunReporter(),
+		env:           projectCfg.Env,
+		mcp:           mcpServers(projectCfg),
+		logRotation:   logRotation,
 	}, nil
 }
 
@@ -207,6 +420,9 @@ func (o *DBOSOrchestrator) RegisterWorkflows() error {
 	// Register the queue-based workflow for parallel execution
 	dbos.RegisterWorkflow(o.dbosCtx, o.ExecuteTasksWithQueue)
 
+	// Register the per-epic workflow (the parent of its tasks' workflows)
+	dbos.RegisterWorkflow(o.dbosCtx, o.ExecuteEpicWorkflow)
+
 	// Register the per-task workflow (enqueued tasks call this)
 	dbos.RegisterWorkflow(o.dbosCtx, o.ExecuteTaskWorkflow)
 
@@ -288,26 +504,39 @@ func (o *DBOSOrchestrator) ExecuteTasksWithQueue(ctx dbos.DBOSContext, input Que
 
 	log.Printf("📋 Enqueuing %d ready tasks (out of %d total)", len(readyTasks), len(tasks))
 
-	// Enqueue all ready tasks for parallel execution using RunWorkflow with queue option
+	// Each epic's tasks run under a child workflow of their own; tasks without
+	// an epic are enqueued here.
 	// Note: We use dbos.RunWorkflow with dbos.WithQueue instead of dbos.Enqueue
 	// because dbos.Enqueue requires a DBOS client which needs database URL that's
 	// not available when called from within a workflow context.
-	handles := make([]dbos.WorkflowHandle[TaskResult], len(readyTasks))
-	for i, task := range readyTasks {
-		handle, err := dbos.RunWorkflow(o.dbosCtx, o.ExecuteTaskWorkflow, task,
-			dbos.WithQueue(o.queue.Name),
-		)
+	epics, loose := groupByEpic(readyTasks)
+	var epicHandles []dbos.WorkflowHandle[QueueStats]
+	for _, epic := range epics {
+		handle, err := o.startEpic(ctx, epic)
+		if err != nil {
+			log.Printf("❌ Failed to start the workflow of epic %s: %v", epic.EpicID, err)
+			continue
+		}
+		epicHandles = append(epicHandles, handle)
+	}
+	var handles []dbos.WorkflowHandle[TaskResult]
+	attached := 0
+	for _, task := range loose {
+		handle, attachedTo, err := o.enqueueTask(ctx, task)
 		if err != nil {
 			log.Printf("❌ Failed to enqueue task %s: %v", task.TaskID, err)
 			continue
 		}
-		handles[i] = handle
-		log.Printf("📤 Enqueued task %s: %s", task.TaskID, task.Title)
+		handles = append(handles, handle)
+		if attachedTo {
+			attached++
+		}
 	}
 
 	// Wait for all enqueued tasks to complete
 	completed := 0
 	failed := 0
+	enqueued := len(handles)
 
 	// Give the queue runner a moment to start processing workflows
 	log.Printf("⏸️  Giving queue runner a moment to start...")
@@ -330,17 +559,38 @@ func (o *DBOSOrchestrator) ExecuteTasksWithQueue(ctx dbos.DBOSContext, input Que
 			failed++
 		}
 	}
+	for _, handle := range epicHandles {
+		epicStats, err := handle.GetResult()
+		if err != nil {
+			log.Printf("❌ Epic workflow %s failed: %v", handle.GetWorkflowID(), err)
+			continue
+		}
+		enqueued += epicStats.TotalEnqueued
+		attached += epicStats.Attached
+		completed += epicStats.Completed
+		failed += epicStats.Failed
+	}
 
 	duration := time.Since(start)
 
 	stats := QueueStats{
-		TotalEnqueued: len(handles),
+		TotalEnqueued: enqueued,
+		Attached:      attached,
 		Completed:     completed,
 		Failed:        failed,
 		Duration:      duration,
 	}
 
 	log.Printf("📊 Queue execution complete in %v", duration)
+	for _, line := range o.concurrency.Summary() {
+		log.Printf("⏱️  %s", line)
+	}
+	if usage := o.usage.Total(); !usage.IsZero() {
+		log.Printf("💰 Spent %s", usage)
+		for _, line := range o.usage.epicLines() {
+			log.Printf("   %s", line)
+		}
+	}
 	return stats, nil
 }
 
@@ -370,16 +620,17 @@ func (o *DBOSOrchestrator) ExecuteTasksWithQueueDirectly(tasks []TaskInput) (Que
 
 	// Enqueue all ready tasks for parallel execution
 	handles := make([]dbos.WorkflowHandle[TaskResult], len(readyTasks))
+	attached := 0
 	for i, task := range readyTasks {
-		handle, err := dbos.RunWorkflow(o.dbosCtx, o.ExecuteTaskWorkflow, task,
-			dbos.WithQueue(o.queue.Name),
-		)
+		handle, attachedTo, err := o.enqueueTask(o.dbosCtx, task)
 		if err != nil {
 			log.Printf("❌ Failed to enqueue task %s: %v", task.TaskID, err)
 			continue
 		}
 		handles[i] = handle
-		log.Printf("📤 Enqueued task %s: %s", task.TaskID, task.Title)
+		if attachedTo {
+			attached++
+		}
 	}
 
 	// Wait for all enqueued tasks to complete
@@ -412,12 +663,22 @@ func (o *DBOSOrchestrator) ExecuteTasksWithQueueDirectly(tasks []TaskInput) (Que
 
 	stats := QueueStats{
 		TotalEnqueued: len(handles),
+		Attached:      attached,
 		Completed:     completed,
 		Failed:        failed,
 		Duration:      duration,
 	}
 
 	log.Printf("📊 Queue execution complete in %v", duration)
+	for _, line := range o.concurrency.Summary() {
+		log.Printf("⏱️  %s", line)
+	}
+	if usage := o.usage.Total(); !usage.IsZero() {
+		log.Printf("💰 Spent %s", usage)
+		for _, line := range o.usage.epicLines() {
+			log.Printf("   %s", line)
+		}
+	}
 	return stats, nil
 }
 
@@ -425,6 +686,43 @@ func (o *DBOSOrchestrator) ExecuteTasksWithQueueDirectly(tasks []TaskInput) (Que
 // This is a separate workflow so each task can be independently recovered
 func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskInput) (TaskResult, error) {
 	start := time.Now()
+	defer func() { o.concurrency.addBusy(time.Since(start)) }()
+	defer o.concurrency.enter()()
+
+	// Paused after it was queued; it's queued again once resumed
+	if paused(o.store, task.TaskID) {
+		log.Printf("⏸️  Skipping paused task %s", task.TaskID)
+		return TaskResult{Success: false, Error: "task is paused"}, nil
+	}
+	// Cancelled after it was queued
+	if o.store != nil {
+		if status, err := o.store.GetTaskStatus(task.TaskID); err == nil && status == types.TaskStatusCancelled {
+			log.Printf("🛑 Skipping cancelled task %s", task.TaskID)
+			return TaskResult{Success: false, Error: "task was cancelled"}, nil
+		}
+	}
+	// Queued before the run reached its cost budget; it waits for the next run
+	if o.usage.overBudget(o.config.MaxCost) {
+		log.Printf("💰 Skipping task %s: the run's $%.2f cost budget is spent", task.TaskID, o.config.MaxCost)
+		return TaskResult{Success: false, Error: "cost budget reached"}, nil
+	}
+	if !o.deadline.allows(time.Now()) {
+		log.Printf("⏰ Skipping task %s: it likely wouldn't finish before the run's deadline", task.TaskID)
+		return TaskResult{Success: false, Error: "run deadline reached"}, nil
+	}
+	if o.breaker.open() {
+		log.Printf("🧯 Skipping task %s: the run is parked after consecutive failures", task.TaskID)
+		return TaskResult{Success: false, Error: "run parked"}, nil
+	}
+	release, err := o.gate.acquire(ctx, task.TaskID)
+	if err != nil {
+		return TaskResult{}, fmt.Errorf("waiting for a queue slot: %w", err)
+	}
+	defer release()
+	if task.MutexKey != "" {
+		defer o.mutexes.lock(task.MutexKey, o.concurrency)()
+	}
+	defer func(started time.Time) { o.deadline.observe(time.Since(started)) }(time.Now())
 	log.Printf("👷 Executing task %s: %s", task.TaskID, task.Title)
 
 	// Start telemetry span for task execution
@@ -453,13 +751,14 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 	if o.webhooks != nil {
 		o.webhooks.EmitTaskStarted(task.TaskID, task.Title, "dbos-workflow")
 	}
+	o.syncTrackerStatusStep(ctx, task.TaskID, types.TaskStatusInProgress)
 
 	// Record events
-	o.recordEvent(events.EventTaskClaimed, task.TaskID, task.EpicID, map[string]any{
+	o.recordEvent(ctx, events.EventTaskClaimed, task.TaskID, task.EpicID, map[string]any{
 		"worker": "dbos-workflow",
 		"title":  task.Title,
 	})
-	o.recordEvent(events.EventTaskStarted, task.TaskID, task.EpicID, map[string]any{
+	o.recordEvent(ctx, events.EventTaskStarted, task.TaskID, task.EpicID, map[string]any{
 		"worker": "dbos-workflow",
 		"title":  task.Title,
 	})
@@ -469,6 +768,12 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		log.Printf("⚠️  Error updating task status to in_progress: %v", err)
 	}
 
+	// Keep this execution in the task's attempt history
+	att := startAttempt(o.store, o.projectDir, task.TaskID, "dbos-workflow")
+	att.rotateLogs(o.logRotation)
+	defer att.finish()
+	att.redact(loadAgentEnv(o.store, o.env, task.TaskID).secrets())
+
 	// Start analytics tracking
 	if o.analytics != nil {
 		o.analytics.StartTask(task.TaskID, task.Title, o.config.AgentType, "")
@@ -487,6 +792,27 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		}
 	}
 
+	// Sub-tasks run first, in sequence order, and the parent only after all of them succeed
+	if err := o.executeSubTasksDBOS(ctx, task, span); err != nil {
+		errMsg := fmt.Sprintf("sub-tasks failed: %v", err)
+		telemetry.RecordError(span, err, "SubTaskFailed", telemetry.ErrorCategoryAgent)
+		telemetry.RecordTaskFailed(taskCtx, "dbos-workflow", "", "other", "subtask_error", 0)
+		dashboard.BroadcastTaskFailed(task.TaskID, task.Title, errMsg)
+		if o.webhooks != nil {
+			o.webhooks.EmitTaskFailed(task.TaskID, task.Title, errMsg, 0)
+		}
+		if o.analytics != nil {
+			o.analytics.EndTask(task.TaskID, "failed", errMsg)
+		}
+		o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+			"error": errMsg,
+		})
+		if updateErr := o.store.UpdateTaskStatus(task.TaskID, types.TaskStatusFailed, errMsg); updateErr != nil {
+			log.Printf("⚠️  Error updating task status to failed: %v", updateErr)
+		}
+		return TaskResult{Success: false, Error: errMsg}, err
+	}
+
 	// Create worktree for isolated execution (as a step)
 	worktreePath, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (string, error) {
 		return o.createWorktreeStep(stepCtx, task)
@@ -502,7 +828,7 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		if o.analytics != nil {
 			o.analytics.EndTask(task.TaskID, "failed", errMsg)
 		}
-		o.recordEvent(events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+		o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
 			"error": errMsg,
 		})
 		// Update task status to failed in database
@@ -515,7 +841,7 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 	// Execute Claude Code (as a step for durability)
 	claudeResult, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (*executor.ExecutionResult, error) {
 		return o.executeClaudeStep(stepCtx, worktreePath, task, span)
-	}, dbos.WithStepMaxRetries(3))
+	}, o.agentStepOptions(task.TaskID)...)
 	if err != nil {
 		errMsg := fmt.Sprintf("agent error: %v", err)
 		telemetry.RecordError(span, err, "ClaudeExecutionError", telemetry.ErrorCategoryAgent)
@@ -527,7 +853,7 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		if o.analytics != nil {
 			o.analytics.EndTask(task.TaskID, "failed", errMsg)
 		}
-		o.recordEvent(events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+		o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
 			"error": errMsg,
 		})
 		// Update task status to failed in database
@@ -537,8 +863,47 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		return TaskResult{Success: false, Error: errMsg}, err
 	}
 
+	att.saveOutput(claudeResult.Output, claudeResult.OutputLog)
+	att.setSession(claudeResult.SessionID)
+	att.setModel(claudeResult.Model)
+
+	// A task paused while the agent ran is parked with its worktree, uncommitted
+	if paused(o.store, task.TaskID) {
+		log.Printf("⏸️  Task %s paused, parking it with its worktree at %s", task.TaskID, worktreePath)
+		if o.analytics != nil {
+			o.analytics.EndTask(task.TaskID, "paused", "")
+		}
+		return TaskResult{Success: false, Output: claudeResult.Output, Error: "task was paused"}, nil
+	}
+
+	// A task cancelled while the agent ran had its agent killed; it ends
+	// cancelled, with its worktree released
+	if cancelRequested(o.store, task.TaskID) {
+		log.Printf("🛑 Task %s cancelled, agent stopped", task.TaskID)
+		if err := o.store.CancelTask(task.TaskID, ""); err != nil {
+			log.Printf("⚠️  Error cancelling task %s: %v", task.TaskID, err)
+		}
+		o.releaseWorktree(task, false)
+		o.recordEvent(ctx, events.EventTaskCancelled, task.TaskID, task.EpicID, nil)
+		if o.analytics != nil {
+			o.analytics.EndTask(task.TaskID, "cancelled", "")
+		}
+		return TaskResult{Success: false, Output: claudeResult.Output, Error: "task was cancelled"}, nil
+	}
+
+	// The agent reported it can't go on; the task waits for 'drover resolve'
+	if verdict, reason := agentVerdict(claudeResult); verdict == types.TaskVerdictBlocked {
+		blockOnVerdict(o.store, o.webhooks, o.eventRecorder(ctx), &types.Task{ID: task.TaskID, Title: task.Title, EpicID: task.EpicID}, reason)
+		o.releaseWorktree(task, false)
+		if o.analytics != nil {
+			o.analytics.EndTask(task.TaskID, "blocked", reason)
+		}
+		return TaskResult{Success: false, Output: claudeResult.Output, Error: "task blocked: " + reason}, nil
+	}
+
 	if !claudeResult.Success {
 		errMsg := claudeResult.Error.Error()
+		att.fail(errMsg)
 		telemetry.RecordError(span, claudeResult.Error, "ClaudeTaskFailed", telemetry.ErrorCategoryAgent)
 		telemetry.RecordTaskFailed(taskCtx, "dbos-workflow", "", "other", "agent_error", claudeResult.Duration)
 		dashboard.BroadcastTaskFailed(task.TaskID, task.Title, errMsg)
@@ -548,7 +913,7 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		if o.analytics != nil {
 			o.analytics.EndTask(task.TaskID, "failed", errMsg)
 		}
-		o.recordEvent(events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+		o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
 			"error": errMsg,
 		})
 		return TaskResult{
@@ -559,9 +924,10 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 	}
 
 	// Commit changes (as a step)
-	hasChanges, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
+	commitResult, err := dbos.RunAsStep(ctx, func(stepCtx context.Context) (CommitStepResult, error) {
 		return o.commitChangesStep(stepCtx, task, claudeResult.Output)
 	}, dbos.WithStepMaxRetries(3))
+	hasChanges := commitResult.HasChanges
 	if err != nil {
 		errMsg := fmt.Sprintf("committing: %v", err)
 		telemetry.RecordError(span, err, "CommitError", telemetry.ErrorCategoryGit)
@@ -573,7 +939,7 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		if o.analytics != nil {
 			o.analytics.EndTask(task.TaskID, "failed", errMsg)
 		}
-		o.recordEvent(events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+		o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
 			"error": errMsg,
 		})
 		return TaskResult{
@@ -583,19 +949,153 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		}, err
 	}
 
-	// Merge to main (as a step)
-	_, err = dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
-		return o.mergeToMainStep(stepCtx, task.TaskID)
-	}, dbos.WithStepMaxRetries(3))
-	if err != nil {
-		// Log warning but don't fail - task completed successfully
-		log.Printf("⚠️  Task %s completed but merge failed: %v", task.TaskID, err)
+	// Changes to protected paths fail the task outright; nothing is merged
+	if len(commitResult.ProtectedPaths) > 0 {
+		violation := &git.ProtectedPathError{Paths: commitResult.ProtectedPaths}
+		errMsg := violation.Error()
+		log.Printf("🚫 Task %s failed: %s", task.TaskID, errMsg)
+		telemetry.RecordError(span, violation, "PolicyViolation", telemetry.ErrorCategoryGit)
+		telemetry.RecordTaskFailed(taskCtx, "dbos-workflow", "", "other", "policy_violation", 0)
+		dashboard.BroadcastTaskFailed(task.TaskID, task.Title, errMsg)
+		if o.webhooks != nil {
+			o.webhooks.EmitTaskFailed(task.TaskID, task.Title, errMsg, 0)
+		}
+		if o.analytics != nil {
+			o.analytics.EndTask(task.TaskID, "failed", errMsg)
+		}
+		o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+			"error":           errMsg,
+			"verdict":         string(types.TaskVerdictPolicyViolation),
+			"protected_paths": commitResult.ProtectedPaths,
+		})
+		if updateErr := o.store.UpdateTaskStatus(task.TaskID, types.TaskStatusFailed, errMsg); updateErr != nil {
+			log.Printf("⚠️  Error updating task status to failed: %v", updateErr)
+		}
+		if verdictErr := o.store.SetTaskVerdict(task.TaskID, types.TaskVerdictPolicyViolation, errMsg); verdictErr != nil {
+			log.Printf("Error storing verdict for task %s: %v", task.TaskID, verdictErr)
+		}
+		return TaskResult{
+			Success: false,
+			Output:  claudeResult.Output,
+			Error:   errMsg,
+		}, violation
+	}
+
+	// Unmet definition-of-done items block the merge or become follow-up tasks
+	if hasChanges && o.dod != nil {
+		unmet, _ := dbos.RunAsStep(ctx, func(stepCtx context.Context) (string, error) {
+			return o.definitionOfDoneStep(stepCtx, task, claudeResult.Output), nil
+		})
+		if unmet != "" {
+			dodErr := errors.New(unmet)
+			log.Printf("☐  Task %s failed: %s", task.TaskID, unmet)
+			telemetry.RecordError(span, dodErr, "DefinitionOfDoneUnmet", "policy")
+			telemetry.RecordTaskFailed(taskCtx, "dbos-workflow", "", "other", "dod_unmet", 0)
+			// The task's retry policy decides whether it gets another attempt
+			requeued, _ := dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
+				return o.failOrRequeue(task.TaskID, FailureDoD, unmet), nil
+			})
+			if !requeued {
+				dashboard.BroadcastTaskFailed(task.TaskID, task.Title, unmet)
+				if o.webhooks != nil {
+					o.webhooks.EmitTaskFailed(task.TaskID, task.Title, unmet, 0)
+				}
+				if o.analytics != nil {
+					o.analytics.EndTask(task.TaskID, "failed", unmet)
+				}
+				o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+					"error": unmet,
+					"class": string(FailureDoD),
+				})
+			}
+			return TaskResult{
+				Success: false,
+				Output:  claudeResult.Output,
+				Error:   unmet,
+			}, dodErr
+		}
+	}
+
+	// The project's verification commands must pass before the work is merged
+	if hasChanges && o.verify != nil {
+		failure, _ := dbos.RunAsStep(ctx, func(stepCtx context.Context) (string, error) {
+			if err := o.verify.run(stepCtx, task.TaskID, worktreePath, taskCommandEnv(o.pool, worktreePath, loadAgentEnv(o.store, o.env, task.TaskID))); err != nil {
+				return err.Error(), nil
+			}
+			return "", nil
+		})
+		if failure != "" {
+			verifyErr := errors.New(failure)
+			log.Printf("❌ Task %s failed verification: %s", task.TaskID, firstLine(failure))
+			telemetry.RecordError(span, verifyErr, "VerificationFailed", "verify")
+			telemetry.RecordTaskFailed(taskCtx, "dbos-workflow", "", "other", "verify_failed", 0)
+			dashboard.BroadcastTaskFailed(task.TaskID, task.Title, failure)
+			if o.webhooks != nil {
+				o.webhooks.EmitTaskFailed(task.TaskID, task.Title, failure, 0)
+			}
+			if o.analytics != nil {
+				o.analytics.EndTask(task.TaskID, "failed", failure)
+			}
+			o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+				"error": failure,
+				"class": string(FailureVerify),
+			})
+			if updateErr := o.store.UpdateTaskStatus(task.TaskID, types.TaskStatusFailed, failure); updateErr != nil {
+				log.Printf("⚠️  Error updating task status to failed: %v", updateErr)
+			}
+			// The guidance waits for the task's retry ('drover retry')
+			retryOnVerification(o.store, task.TaskID, failure, true)
+			return TaskResult{
+				Success: false,
+				Output:  claudeResult.Output,
+				Error:   failure,
+			}, verifyErr
+		}
+	}
+
+	var pushedSHA string
+	if o.config.PRMode {
+		// Push the branch for review instead of merging (as a step)
+		if hasChanges {
+			pushedSHA, err = dbos.RunAsStep(ctx, func(stepCtx context.Context) (string, error) {
+				gitMgr, err := o.worktreesFor(task.Repo)
+				if err != nil {
+					return "", err
+				}
+				return gitMgr.PushBranchWithContext(stepCtx, task.TaskID, o.config.PRRemote)
+			}, dbos.WithStepMaxRetries(3))
+			if err != nil {
+				log.Printf("⚠️  Task %s completed but push failed: %v", task.TaskID, err)
+			}
+			o.report.merged(task.TaskID, mergeResult("push", hasChanges, err))
+			o.reportCommitStatusStep(ctx, pushedSHA, task.TaskID, webhooks.CommitStatePending, "Running verification")
+		}
+	} else {
+		// Merge to main (as a step)
+		_, err = dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
+			return o.mergeToMainStep(stepCtx, task)
+		}, dbos.WithStepMaxRetries(3))
+		if err != nil {
+			// Log warning but don't fail - task completed successfully
+			log.Printf("⚠️  Task %s completed but merge failed: %v", task.TaskID, err)
+		}
+		o.report.merged(task.TaskID, mergeResult("merge", hasChanges, err))
 	}
 
 	// Run automated tests before task completion
 	testErr := o.runTestsDBOS(ctx, task.TaskID, worktreePath, span)
 	if testErr != nil {
 		errMsg := fmt.Sprintf("automated tests failed: %v", testErr)
+		o.reportCommitStatusStep(ctx, pushedSHA, task.TaskID, webhooks.CommitStateFailure, "Automated tests failed")
+		// A blocker outside the task's own work gets a fix task; a later run
+		// picks both up
+		if o.fixBlocker(ctx, task, FailureTests, testErr.Error()) {
+			o.releaseWorktree(task, false)
+			if o.analytics != nil {
+				o.analytics.EndTask(task.TaskID, "blocked", errMsg)
+			}
+			return TaskResult{Success: false, Output: claudeResult.Output, Error: "task blocked: " + errMsg}, nil
+		}
 		telemetry.RecordError(span, testErr, "TestExecutionFailed", "tests")
 		telemetry.RecordTaskFailed(taskCtx, "dbos-workflow", "", "other", "test_error", 0)
 		dashboard.BroadcastTaskFailed(task.TaskID, task.Title, errMsg)
@@ -605,7 +1105,7 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 		if o.analytics != nil {
 			o.analytics.EndTask(task.TaskID, "failed", errMsg)
 		}
-		o.recordEvent(events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
+		o.recordEvent(ctx, events.EventTaskFailed, task.TaskID, task.EpicID, map[string]any{
 			"error": errMsg,
 		})
 		// Update task status to failed in database
@@ -629,19 +1129,23 @@ func (o *DBOSOrchestrator) ExecuteTaskWorkflow(ctx dbos.DBOSContext, task TaskIn
 	if o.webhooks != nil {
 		o.webhooks.EmitTaskCompleted(task.TaskID, task.Title, duration.Milliseconds())
 	}
+	o.syncTrackerStatusStep(ctx, task.TaskID, types.TaskStatusCompleted)
 
 	// Record event
-	o.recordEvent(events.EventTaskCompleted, task.TaskID, task.EpicID, map[string]any{
+	o.recordEvent(ctx, events.EventTaskCompleted, task.TaskID, task.EpicID, map[string]any{
 		"worker":   "dbos-workflow",
 		"title":    task.Title,
 		"duration": duration.Milliseconds(),
 	})
 
-	// Parse and store structured outcome
-	outcome := outcomepkg.ParseOutput(claudeResult.Output)
-	if err := o.store.SetTaskVerdict(task.TaskID, types.TaskVerdict(outcome.Verdict), outcome.Summary); err != nil {
+	// Store the structured outcome
+	verdict, verdictReason := completionVerdict(claudeResult, claudeResult.Output)
+	if err := o.store.SetTaskVerdict(task.TaskID, verdict, verdictReason); err != nil {
 		log.Printf("Error storing verdict for task %s: %v", task.TaskID, err)
 	}
+	state, description := verdictStatus(verdict, verdictReason)
+	o.reportCommitStatusStep(ctx, pushedSHA, task.TaskID, state, description)
+	logFollowups(task.TaskID, claudeResult)
 
 	// Update task status to completed in database
 	if err := o.store.UpdateTaskStatus(task.TaskID, types.TaskStatusCompleted, ""); err != nil {
@@ -703,7 +1207,7 @@ func (o *DBOSOrchestrator) buildDependencyMap(tasks []TaskInput) {
 }
 
 // recordEvent records an event in the database
-func (o *DBOSOrchestrator) recordEvent(eventType events.EventType, taskID, epicID string, data map[string]any) {
+func (o *DBOSOrchestrator) recordEvent(ctx dbos.DBOSContext, eventType events.EventType, taskID, epicID string, data map[string]any) {
 	eventID := uuid.New().String()
 	timestamp := time.Now().Unix()
 
@@ -718,6 +1222,32 @@ func (o *DBOSOrchestrator) recordEvent(eventType events.EventType, taskID, epicI
 	if err := o.store.RecordEvent(eventID, string(eventType), timestamp, taskID, epicID, dataJSON); err != nil {
 		log.Printf("Error recording event: %v", err)
 	}
+
+	switch eventType {
+	case events.EventTaskFailed:
+		// Alerts are steps, so a recovered workflow doesn't post them again
+		_, _ = dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
+			notifyTaskFailed(o.notifier, o.store, o.env, taskID, data)
+			return true, nil
+		})
+		errMsg, _ := data["error"].(string)
+		if o.breaker.failure(errMsg) {
+			reason := o.breaker.reason()
+			_, _ = dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
+				o.notifier.RunParked(reason)
+				return true, nil
+			})
+		}
+	case events.EventTaskCompleted:
+		o.breaker.success()
+	}
+}
+
+// eventRecorder is recordEvent for the workflow running in ctx
+func (o *DBOSOrchestrator) eventRecorder(ctx dbos.DBOSContext) func(events.EventType, string, string, map[string]any) {
+	return func(eventType events.EventType, taskID, epicID string, data map[string]any) {
+		o.recordEvent(ctx, eventType, taskID, epicID, data)
+	}
 }
 
 // findReadyTasks returns tasks that have no unresolved dependencies
@@ -742,32 +1272,32 @@ func (o *DBOSOrchestrator) createWorktreeStep(ctx context.Context, task TaskInpu
 		ID:       task.TaskID,
 		Title:    task.Title,
 		Priority: task.Priority,
+		Repo:     task.Repo,
+	}
+	gitMgr, err := o.worktreesFor(task.Repo)
+	if err != nil {
+		return "", err
 	}
 
 	var worktreePath string
-	var err error
 
 	// Use pool if enabled
-	if o.pool != nil && o.pool.IsEnabled() {
-		worktreePath, err = o.pool.Acquire(task.TaskID)
+	if o.pooled(task.Repo) {
+		worktreePath, err = o.pool.AcquireWithAffinity(task.TaskID, git.TaskAffinity(task.EpicID, task.Title, task.Description))
 		if err != nil {
 			return "", fmt.Errorf("acquiring worktree from pool: %w", err)
 		}
+	} else if existing, err := gitMgr.GetWorktreePath(task.TaskID); err == nil && existing != "" {
+		// Left by the task when it was paused
+		worktreePath = existing
+		log.Printf("♻️  Reusing existing worktree for task %s at %s", task.TaskID, worktreePath)
 	} else {
-		worktreePath, err = o.git.Create(taskObj)
+		worktreePath, err = gitMgr.CreateWithContext(ctx, taskObj)
 		if err != nil {
 			return "", fmt.Errorf("creating worktree: %w", err)
 		}
 	}
 
-	// Track worktree in database
-	branchName := fmt.Sprintf("drover-%s", task.TaskID)
-	if o.store != nil {
-		if err := o.store.CreateWorktree(task.TaskID, worktreePath, branchName); err != nil {
-			log.Printf("⚠️  Failed to track worktree in database: %v", err)
-		}
-	}
-
 	return worktreePath, nil
 }
 
@@ -778,24 +1308,29 @@ func (o *DBOSOrchestrator) executeClaudeStep(ctx context.Context, worktreePath s
 	if _, err := os.Stat(worktreePath); os.IsNotExist(err) {
 		log.Printf("⚠️  Worktree %s no longer exists, attempting to recreate...", worktreePath)
 
+		gitMgr, err := o.worktreesFor(task.Repo)
+		if err != nil {
+			return nil, err
+		}
+
 		// Clean up any stale registrations first
-		o.git.PruneStale(task.TaskID)
+		gitMgr.PruneStaleWithContext(ctx, task.TaskID)
 
 		// Recreate the worktree
 		taskObj := &types.Task{
 			ID:       task.TaskID,
 			Title:    task.Title,
 			Priority: task.Priority,
+			Repo:     task.Repo,
 		}
 
-		var err error
-		if o.pool != nil && o.pool.IsEnabled() {
-			worktreePath, err = o.pool.Acquire(task.TaskID)
+		if o.pooled(task.Repo) {
+			worktreePath, err = o.pool.AcquireWithAffinity(task.TaskID, git.TaskAffinity(task.EpicID, task.Title, task.Description))
 			if err != nil {
 				return nil, fmt.Errorf("recreating worktree from pool: %w", err)
 			}
 		} else {
-			worktreePath, err = o.git.Create(taskObj)
+			worktreePath, err = gitMgr.CreateWithContext(ctx, taskObj)
 			if err != nil {
 				return nil, fmt.Errorf("recreating worktree: %w", err)
 			}
@@ -804,28 +1339,137 @@ func (o *DBOSOrchestrator) executeClaudeStep(ctx context.Context, worktreePath s
 		log.Printf("✅ Recreated worktree at %s", worktreePath)
 	}
 
-	result := o.agent.ExecuteWithContext(ctx, worktreePath, &types.Task{
+	taskObj := &types.Task{
 		ID:          task.TaskID,
 		Title:       task.Title,
 		Description: task.Description,
 		EpicID:      task.EpicID,
-	}, parentSpan)
+	}
+	if env := worktreeEnv(o.pool, worktreePath); len(env) > 0 {
+		taskObj.ExecutionContext = &types.TaskExecutionContext{Env: env}
+	}
+	// The project's and the task's own variables for the agent
+	if env := loadAgentEnv(o.store, o.env, task.TaskID); len(env) > 0 {
+		if taskObj.ExecutionContext == nil {
+			taskObj.ExecutionContext = &types.TaskExecutionContext{}
+		}
+		taskObj.ExecutionContext.Env = append(taskObj.ExecutionContext.Env, env.entries()...)
+		taskObj.ExecutionContext.Secrets = env.secrets()
+		log.Printf("🔧 Task %s environment: %s", task.TaskID, env)
+	}
+	// Guidance includes why the agent failed a previous try of this step
+	if guidance := o.pendingGuidance(task.TaskID); len(guidance) > 0 {
+		if taskObj.ExecutionContext == nil {
+			taskObj.ExecutionContext = &types.TaskExecutionContext{}
+		}
+		taskObj.ExecutionContext.Guidance = guidance
+		defer o.markGuidanceDelivered(guidance)
+	}
+	withDoDGuidance(taskObj, o.dod)
+	withPreviousAttempt(o.store, taskObj, o.config.ClaudeResume)
+	withMCPConfig(taskObj, worktreePath, o.mcp)
+	// Guidance added while the agent works is handed to it as it runs
+	feed := feedGuidance(o.store, taskObj, worktreePath, o.config.UseWorkerSubprocess)
+	defer feed.stop()
+
+	// Pausing or cancelling the task stops the agent
+	agentCtx, stopWatch := watchStop(ctx, o.store, task.TaskID)
+	defer stopWatch()
+	// A task stuck far past the usual duration may race a second attempt
+	gitMgr, _ := o.worktreesFor(task.Repo)
+	result := o.hedge.execute(agentCtx, gitMgr, taskObj, worktreePath,
+		func(ctx context.Context, path string, t *types.Task) *executor.ExecutionResult {
+			return o.agent.ExecuteWithContext(ctx, path, t, parentSpan)
+		},
+		func(discarded *executor.ExecutionResult) { o.usage.record(o.store, taskObj, discarded) })
+	feed.settle(result)
 
+	// Let the agent fix what go vet/tsc/clippy find before the task is committed
+	result = fixDiagnostics(agentCtx, o.agent, o.diagnostics, o.config.DiagnosticsIterations, worktreePath, taskObj, result, parentSpan)
+	feed.settle(result)
+	o.usage.record(o.store, taskObj, result)
+
+	// A stopped agent's failure isn't retried; the workflow parks or cancels the task
+	if !result.Success && (paused(o.store, task.TaskID) || cancelRequested(o.store, task.TaskID)) {
+		return result, nil
+	}
 	if !result.Success {
 		return nil, result.Error
 	}
 
+	// An agent failing its own work has the step retried, told why
+	if verdict, reason := agentVerdict(result); verdict == types.TaskVerdictFail {
+		log.Printf("❌ Task %s: agent verdict: fail: %s", task.TaskID, reason)
+		retryOnVerdict(o.store, task.TaskID, reason, true)
+		return nil, fmt.Errorf("agent verdict: fail: %s", reason)
+	}
+
 	return result, nil
 }
 
+// pendingGuidance returns the guidance waiting for a task's agent
+func (o *DBOSOrchestrator) pendingGuidance(taskID string) []*types.GuidanceMessage {
+	if o.store == nil {
+		return nil
+	}
+	guidance, err := o.store.GetPendingGuidance(taskID)
+	if err != nil {
+		log.Printf("Error fetching guidance: %v", err)
+		return nil
+	}
+	return guidance
+}
+
+// markGuidanceDelivered records that the agent was given guidance
+func (o *DBOSOrchestrator) markGuidanceDelivered(guidance []*types.GuidanceMessage) {
+	ids := make([]string, len(guidance))
+	for i, g := range guidance {
+		ids[i] = g.ID
+	}
+	if err := o.store.MarkGuidanceDelivered(ids); err != nil {
+		log.Printf("Error marking guidance delivered: %v", err)
+	}
+}
+
+// agentStepOptions has DBOS retry a failed agent step with the backoff of the
+// task's retry policy. DBOS retries every error, so the policy's retry_on
+// classes don't apply here
+func (o *DBOSOrchestrator) agentStepOptions(taskID string) []dbos.StepOption {
+	opts := []dbos.StepOption{dbos.WithStepMaxRetries(3)}
+	var task *types.Task
+	if o.store != nil {
+		task, _ = o.store.GetTask(taskID)
+	}
+	policy := taskRetryPolicy(o.retry, task)
+	if policy.Backoff > 0 {
+		opts = append(opts, dbos.WithBaseInterval(policy.Backoff), dbos.WithBackoffFactor(policy.Multiplier))
+		if policy.MaxBackoff > 0 {
+			opts = append(opts, dbos.WithMaxInterval(policy.MaxBackoff))
+		}
+	}
+	return opts
+}
+
 // commitChangesStep commits any changes made by Claude
 // This is a step function - must accept only context.Context
-func (o *DBOSOrchestrator) commitChangesStep(ctx context.Context, task TaskInput, output string) (bool, error) {
+func (o *DBOSOrchestrator) commitChangesStep(ctx context.Context, task TaskInput, output string) (CommitStepResult, error) {
 	commitMsg := fmt.Sprintf("drover: %s\n\nTask: %s", task.TaskID, task.Title)
 
-	hasChanges, err := o.git.Commit(task.TaskID, commitMsg)
+	gitMgr, err := o.worktreesFor(task.Repo)
 	if err != nil {
-		return false, fmt.Errorf("committing: %w", err)
+		return CommitStepResult{}, err
+	}
+	hasChanges, err := gitMgr.CommitWithContext(ctx, task.TaskID, commitMsg)
+	var violation *git.ProtectedPathError
+	if errors.As(err, &violation) {
+		// Not retryable: report it through the result so the workflow can fail the task
+		return CommitStepResult{HasChanges: hasChanges, ProtectedPaths: violation.Paths}, nil
+	}
+	if err != nil {
+		return CommitStepResult{}, fmt.Errorf("committing: %w", err)
+	}
+	if hasChanges {
+		recordCommitAuthor(o.store, gitMgr, task.TaskID)
 	}
 
 	// Log diagnostic output when no changes were detected
@@ -841,36 +1485,85 @@ func (o *DBOSOrchestrator) commitChangesStep(ctx context.Context, task TaskInput
 		log.Printf("╚════════════════════════════════════════════════════════════════════════╝")
 	}
 
-	return hasChanges, nil
+	return CommitStepResult{HasChanges: hasChanges}, nil
+}
+
+// definitionOfDoneStep evaluates the definition of done for a committed task
+// and returns why it blocks the merge, or "" when it doesn't. Follow-up tasks
+// are created inside the step so a replayed workflow doesn't create them twice
+// This is a step function - must accept only context.Context
+func (o *DBOSOrchestrator) definitionOfDoneStep(ctx context.Context, task TaskInput, output string) string {
+	taskObj := &types.Task{
+		ID:       task.TaskID,
+		Title:    task.Title,
+		EpicID:   task.EpicID,
+		Priority: task.Priority,
+		Repo:     task.Repo,
+	}
+	gitMgr, err := o.worktreesFor(task.Repo)
+	if err != nil {
+		return err.Error()
+	}
+	if err := enforceDefinitionOfDone(ctx, o.store, gitMgr, o.dod, taskObj, output); err != nil {
+		return err.Error()
+	}
+	return ""
 }
 
 // mergeToMainStep merges the worktree changes to main branch
 // This is a step function - must accept only context.Context
-func (o *DBOSOrchestrator) mergeToMainStep(ctx context.Context, taskID string) (bool, error) {
-	err := o.git.MergeToMain(taskID)
+func (o *DBOSOrchestrator) mergeToMainStep(ctx context.Context, task TaskInput) (bool, error) {
+	gitMgr, err := o.worktreesFor(task.Repo)
 	if err != nil {
+		return false, err
+	}
+	if err := gitMgr.MergeToMainWithContext(ctx, task.TaskID); err != nil {
 		return false, fmt.Errorf("merging to main: %w", err)
 	}
 
 	// Clean up worktree after successful merge
-	if o.pool != nil && o.pool.IsEnabled() {
-		o.pool.Release(taskID, false) // Don't retain worktree after merge
-	} else {
-		if err := o.git.Remove(taskID); err != nil {
-			log.Printf("⚠️  Failed to clean up worktree for task %s: %v", taskID, err)
-		}
+	o.releaseWorktree(task, true)
+	return true, nil
+}
+
+// releaseWorktree returns a task's worktree to the pool, or removes it. The
+// pool takes the worktree of a merged task back warm when retaining
+func (o *DBOSOrchestrator) releaseWorktree(task TaskInput, merged bool) {
+	if o.pooled(task.Repo) {
+		o.pool.Release(task.TaskID, o.config.PoolRetain && merged)
+		return
+	}
+	gitMgr, err := o.worktreesFor(task.Repo)
+	if err != nil {
+		return
 	}
+	if err := gitMgr.Remove(task.TaskID); err != nil {
+		log.Printf("⚠️  Failed to clean up worktree for task %s: %v", task.TaskID, err)
+	}
+}
 
-	// Mark worktree as removed in database
-	if o.store != nil {
-		if err := o.store.UpdateWorktreeStatus(taskID, "merged"); err != nil {
-			log.Printf("⚠️  Failed to update worktree status in database: %v", err)
-		}
-		// Delete the worktree record since it's been cleaned up
-		o.store.DeleteWorktree(taskID)
+// WriteRunReport writes the report of the run under .drover/runs, and posts
+// its summary when chat notifications are configured; finished is false for
+// a run whose workflow failed or was interrupted
+func (o *DBOSOrchestrator) WriteRunReport(epicID string, finished bool) {
+	outcome := RunFinished
+	switch {
+	case !finished:
+		outcome = RunInterrupted
+	case o.breaker.open():
+		outcome = RunParked
+	case o.deadline.reached() || o.usage.overBudget(o.config.MaxCost):
+		outcome = RunDrained
+	}
+	if report := o.report.write(o.store, o.projectDir, &o.usage, "dbos", epicID, o.config.Workers, outcome); report != nil {
+		o.notifier.RunEnded(report.Summary())
 	}
+}
 
-	return true, nil
+// NotifyRunStarted posts that the run started with the given number of
+// tasks queued, when chat notifications are configured
+func (o *DBOSOrchestrator) NotifyRunStarted(epicID string, tasks int) {
+	o.notifier.RunStarted(notify.RunStart{EpicID: epicID, Workers: o.config.Workers, Tasks: tasks})
 }
 
 // PrintResults prints the final results of the workflow execution
@@ -887,38 +1580,41 @@ func (o *DBOSOrchestrator) PrintResults(results []TaskResult) {
 		}
 	}
 
-	fmt.Println("\n🐂 Drover Run Complete")
-	fmt.Println("═════════════════════")
-	fmt.Printf("\nTotal tasks:     %d", total)
-	fmt.Printf("\nCompleted:       %d", completed)
-	fmt.Printf("\nFailed:          %d", failed)
+	output.Println("\n🐂 Drover Run Complete")
+	output.Println("═════════════════════")
+	output.Printf("\nTotal tasks:     %d", total)
+	output.Printf("\nCompleted:       %d", completed)
+	output.Printf("\nFailed:          %d", failed)
 
 	if total > 0 {
 		successRate := float64(completed) / float64(total) * 100
-		fmt.Printf("\n\nSuccess rate:    %.1f%%", successRate)
+		output.Printf("\n\nSuccess rate:    %.1f%%", successRate)
 	}
 
 	if failed > 0 {
-		fmt.Println("\n\n⚠️  Some tasks did not complete successfully")
+		output.Println("\n\n⚠️  Some tasks did not complete successfully")
 	}
 }
 
 // PrintQueueStats prints statistics about queue-based execution
 func (o *DBOSOrchestrator) PrintQueueStats(stats QueueStats) {
-	fmt.Println("\n🐂 Drover Run Complete (Queue Mode)")
-	fmt.Println("═════════════════════════════════")
-	fmt.Printf("\nTotal enqueued: %d", stats.TotalEnqueued)
-	fmt.Printf("\nCompleted:       %d", stats.Completed)
-	fmt.Printf("\nFailed:          %d", stats.Failed)
-	fmt.Printf("\nDuration:        %v", stats.Duration)
+	output.Println("\n🐂 Drover Run Complete (Queue Mode)")
+	output.Println("═════════════════════════════════")
+	output.Printf("\nTotal enqueued: %d", stats.TotalEnqueued)
+	if stats.Attached > 0 {
+		output.Printf("\nDeduplicated:    %d (already queued or running from an earlier run)", stats.Attached)
+	}
+	output.Printf("\nCompleted:       %d", stats.Completed)
+	output.Printf("\nFailed:          %d", stats.Failed)
+	output.Printf("\nDuration:        %v", stats.Duration)
 
 	if stats.TotalEnqueued > 0 {
 		successRate := float64(stats.Completed) / float64(stats.TotalEnqueued) * 100
-		fmt.Printf("\n\nSuccess rate:    %.1f%%", successRate)
+		output.Printf("\n\nSuccess rate:    %.1f%%", successRate)
 	}
 
 	if stats.Failed > 0 {
-		fmt.Println("\n\n⚠️  Some tasks did not complete successfully")
+		output.Println("\n\n⚠️  Some tasks did not complete successfully")
 	}
 }
 
@@ -927,6 +1623,71 @@ func generateWorkflowID() string {
 	return fmt.Sprintf("workflow-%d", time.Now().UnixNano())
 }
 
+// syncTrackerStatusStep pushes a task's status to the issue it was imported
+// from as a step, so a recovered workflow doesn't push it again
+func (o *DBOSOrchestrator) syncTrackerStatusStep(ctx dbos.DBOSContext, taskID string, status types.TaskStatus) {
+	if o.jira == nil {
+		return
+	}
+	_, _ = dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
+		syncTrackerStatus(o.jira, o.store, taskID, status)
+		return true, nil
+	})
+}
+
+// reportCommitStatusStep publishes a commit status check as a step, so a
+// recovered workflow doesn't publish it again
+func (o *DBOSOrchestrator) reportCommitStatusStep(ctx dbos.DBOSContext, sha, taskID string, state webhooks.CommitState, description string) {
+	if o.statuses == nil || sha == "" {
+		return
+	}
+	_, _ = dbos.RunAsStep(ctx, func(stepCtx context.Context) (bool, error) {
+		reportCommitStatus(o.statuses, sha, taskID, state, description)
+		return true, nil
+	})
+}
+
+// failOrRequeue returns a failed task to the queue when its retry policy
+// retries the failure and it has attempts left, else marks it failed. It
+// reports whether the task was requeued
+func (o *DBOSOrchestrator) failOrRequeue(taskID string, class FailureClass, errorMsg string) bool {
+	task, err := o.store.GetTask(taskID)
+	if err != nil {
+		log.Printf("Error fetching task %s: %v", taskID, err)
+		_ = o.store.UpdateTaskStatus(taskID, types.TaskStatusFailed, errorMsg)
+		return false
+	}
+
+	policy := taskRetryPolicy(o.retry, task)
+	if task.Attempts >= task.MaxAttempts || !policy.Retries(class) {
+		if err := o.store.UpdateTaskStatus(taskID, types.TaskStatusFailed, errorMsg); err != nil {
+			log.Printf("⚠️  Error updating task status to failed: %v", err)
+		}
+		return false
+	}
+
+	// Sub-tasks are rerun by their parent, which waits out its own backoff
+	var delay time.Duration
+	var notBefore time.Time
+	if task.ParentID == "" {
+		delay = policy.Delay(task.Attempts+1, rand.Float64)
+		if delay > 0 {
+			notBefore = time.Now().Add(delay)
+		}
+	}
+	if err := o.store.RequeueTask(taskID, errorMsg, notBefore); err != nil {
+		log.Printf("Error requeueing task %s: %v", taskID, err)
+		_ = o.store.UpdateTaskStatus(taskID, types.TaskStatusFailed, errorMsg)
+		return false
+	}
+	if delay > 0 {
+		log.Printf("🔄 Task %s retrying in %v (%s failure, attempt %d/%d)", taskID, delay, class, task.Attempts+1, task.MaxAttempts)
+	} else {
+		log.Printf("🔄 Task %s retrying (attempt %d/%d)", taskID, task.Attempts+1, task.MaxAttempts)
+	}
+	return true
+}
+
 // runTestsDBOS executes automated tests before task completion in DBOS workflow
 // Returns an error if tests fail and the task is configured to block on test failures
 func (o *DBOSOrchestrator) runTestsDBOS(ctx dbos.DBOSContext, taskID, worktreePath string, taskSpan trace.Span) error {
@@ -966,6 +1727,11 @@ func (o *DBOSOrchestrator) runTestsDBOS(ctx dbos.DBOSContext, taskID, worktreePa
 	// Create test runner and run tests
 	runner := testing.NewRunner(testConfig, worktreePath)
 	runner.SetVerbose(o.verbose)
+	runner.SetEnv(taskCommandEnv(o.pool, worktreePath, loadAgentEnv(o.store, o.env, taskID)))
+	if gitMgr, err := o.worktreesFor(task.Repo); err == nil {
+		runner.SetBaseBranch(gitMgr.TargetBranch())
+	}
+	runner.SetShards(o.concurrency.testShards(o.config.Workers, o.config.TestShards))
 
 	result := runner.Run(worktreePath, taskID)
 
@@ -1014,6 +1780,7 @@ func (o *DBOSOrchestrator) Stop() {
 	if o.pool != nil {
 		o.pool.Stop()
 	}
+	executor.CloseAgent(o.agent)
 }
 
 // getProjectTaskContextCount returns the task context count from project config or default
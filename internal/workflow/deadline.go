@@ -0,0 +1,95 @@
+package workflow
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+)
+
+// deadlineHistory is how many recent task durations the expected duration of
+// the next task is averaged over
+const deadlineHistory = 50
+
+// runDeadline is a run's wall-clock budget. A task is only started if a task
+// of average duration would finish before the deadline, so the run drains in
+// time instead of leaving work half done when it ends
+type runDeadline struct {
+	at time.Time
+
+	mu        sync.Mutex
+	durations []time.Duration // Most recent last
+	stopped   bool            // A task was held back for the deadline
+}
+
+// newRunDeadline starts a budget of maxDuration from now, seeded with the
+// durations of recently completed tasks. Returns nil, no deadline, when
+// maxDuration is 0
+func newRunDeadline(store *db.Store, maxDuration time.Duration) *runDeadline {
+	if maxDuration <= 0 {
+		return nil
+	}
+	d := &runDeadline{at: time.Now().Add(maxDuration)}
+	if store != nil {
+		recent, err := store.CompletedTaskDurations(deadlineHistory)
+		if err == nil {
+			// Newest first from the store
+			for i := len(recent) - 1; i >= 0; i-- {
+				d.durations = append(d.durations, recent[i])
+			}
+		}
+	}
+	return d
+}
+
+// observe records how long a task took this run
+func (d *runDeadline) observe(took time.Duration) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.durations = append(d.durations, took)
+	if len(d.durations) > deadlineHistory {
+		d.durations = d.durations[len(d.durations)-deadlineHistory:]
+	}
+}
+
+// expected is the average duration of recent tasks, 0 without any history
+func (d *runDeadline) expected() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.durations) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, took := range d.durations {
+		total += took
+	}
+	return total / time.Duration(len(d.durations))
+}
+
+// allows reports whether a task started now would be expected to finish
+// before the deadline. Always true without a deadline
+func (d *runDeadline) allows(now time.Time) bool {
+	if d == nil {
+		return true
+	}
+	if !now.Add(d.expected()).After(d.at) {
+		return true
+	}
+	d.mu.Lock()
+	d.stopped = true
+	d.mu.Unlock()
+	return false
+}
+
+// reached reports whether the deadline held a task back
+func (d *runDeadline) reached() bool {
+	if d == nil {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stopped
+}
@@ -0,0 +1,47 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRunDeadline_Allows(t *testing.T) {
+	var none *runDeadline
+	if !none.allows(time.Now()) || none.reached() {
+		t.Fatal("Expected a run without a deadline to start every task")
+	}
+	if newRunDeadline(nil, 0) != nil {
+		t.Fatal("Expected no deadline for a max duration of 0")
+	}
+
+	d := newRunDeadline(nil, time.Hour)
+	now := time.Now()
+	if !d.allows(now) {
+		t.Error("Expected a task to start with an hour left and no history")
+	}
+
+	d.observe(10 * time.Minute)
+	d.observe(30 * time.Minute)
+	if got := d.expected(); got != 20*time.Minute {
+		t.Errorf("expected() = %v, want the 20m average", got)
+	}
+	if !d.allows(d.at.Add(-25 * time.Minute)) {
+		t.Error("Expected a task to start with 25m left when tasks take 20m")
+	}
+	if d.reached() {
+		t.Error("Expected the deadline not to have held anything back yet")
+	}
+	if d.allows(d.at.Add(-15 * time.Minute)) {
+		t.Error("Expected no task to start with 15m left when tasks take 20m")
+	}
+	if !d.reached() {
+		t.Error("Expected the deadline to report holding a task back")
+	}
+
+	for i := 0; i < deadlineHistory; i++ {
+		d.observe(time.Minute)
+	}
+	if got := d.expected(); got != time.Minute {
+		t.Errorf("expected() = %v after %d one-minute tasks, want 1m", got, deadlineHistory)
+	}
+}
@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/diagnostics"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/project"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxDiagnosticsInPrompt bounds how many findings one fix-it round shows the agent
+const maxDiagnosticsInPrompt = 50
+
+// newDiagnosticsChecker returns the checker for the fix-it loop, or nil when
+// it is disabled. DROVER_DIAGNOSTICS_COMMAND overrides .drover.toml's
+// diagnostics_command, which overrides detection from the project files
+func newDiagnosticsChecker(cfg *config.Config, projectCfg *project.Config) *diagnostics.Checker {
+	if cfg.DiagnosticsIterations <= 0 {
+		return nil
+	}
+	command := cfg.DiagnosticsCommand
+	if command == "" {
+		command = projectCfg.DiagnosticsCommand
+	}
+	return diagnostics.NewChecker(command, cfg.DiagnosticsTimeout)
+}
+
+// fixDiagnostics runs the project's static checks after a successful agent run
+// and, while they report problems, re-runs the agent with the findings as
+// guidance, at most maxIterations times. Checker failures are logged and end
+// the loop; the task then continues to commit and tests as usual. Returns the
+// result of the last agent run, with the task's execution context as it was
+func fixDiagnostics(ctx context.Context, agent executor.Agent, checker *diagnostics.Checker, maxIterations int,
+	worktreePath string, task *types.Task, result *executor.ExecutionResult, span trace.Span) *executor.ExecutionResult {
+	if checker == nil || maxIterations <= 0 || !result.Success {
+		return result
+	}
+
+	// The findings are for the rounds here only, not the task's later runs
+	original := task.ExecutionContext
+	defer func() { task.ExecutionContext = original }()
+
+	// Keep the operator's guidance in every round alongside the findings
+	var guidance []*types.GuidanceMessage
+	var env []string
+	if task.ExecutionContext != nil {
+		guidance = task.ExecutionContext.Guidance
+		env = task.ExecutionContext.Env
+	}
+
+	for iteration := 1; ; iteration++ {
+		check, err := checker.Check(ctx, worktreePath, env)
+		if err != nil {
+			log.Printf("⚠️  Diagnostics for task %s: %v", task.ID, err)
+			return result
+		}
+		if check.Clean() {
+			if iteration > 1 {
+				log.Printf("🩺 Task %s: diagnostics clean after %d fix-it round(s)", task.ID, iteration-1)
+			}
+			return result
+		}
+		if iteration > maxIterations {
+			log.Printf("⚠️  Task %s: %d diagnostic(s) from %s remain after %d fix-it round(s)",
+				task.ID, len(check.Diagnostics), check.Tool, maxIterations)
+			return result
+		}
+
+		log.Printf("🩺 Task %s: %s reported %d diagnostic(s), asking the agent to fix them (round %d/%d)",
+			task.ID, check.Tool, len(check.Diagnostics), iteration, maxIterations)
+
+		execCtx := types.TaskExecutionContext{}
+		if task.ExecutionContext != nil {
+			execCtx = *task.ExecutionContext
+		}
+		execCtx.Guidance = append(guidance[:len(guidance):len(guidance)], &types.GuidanceMessage{
+			ID:        fmt.Sprintf("diagnostics-%d", iteration),
+			TaskID:    task.ID,
+			Message:   check.Prompt(maxDiagnosticsInPrompt),
+			CreatedAt: time.Now().Unix(),
+		})
+		task.ExecutionContext = &execCtx
+
+		earlier := result
+		result = agent.ExecuteWithContext(ctx, worktreePath, task, span)
+		result.AddUsage(earlier)
+		if !result.Success {
+			return result
+		}
+	}
+}
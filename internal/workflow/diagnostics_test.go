@@ -0,0 +1,116 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
+	"github.com/cloud-shuttle/drover/internal/diagnostics"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fixingAgent removes the file the diagnostics command complains about after
+// fixAfter runs, and records the guidance it was given
+type fixingAgent struct {
+	runs     int
+	fixAfter int
+	badFile  string
+	guidance []string
+}
+
+func (a *fixingAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *executor.ExecutionResult {
+	a.runs++
+	if task.ExecutionContext != nil {
+		for _, g := range task.ExecutionContext.Guidance {
+			a.guidance = append(a.guidance, g.Message)
+		}
+	}
+	if a.runs > a.fixAfter {
+		os.Remove(a.badFile)
+	}
+	return &executor.ExecutionResult{Success: true}
+}
+
+func (a *fixingAgent) CheckInstalled() error                           { return nil }
+func (a *fixingAgent) SetVerbose(bool)                                 {}
+func (a *fixingAgent) SetProjectGuidelines(string)                     {}
+func (a *fixingAgent) SetContextManager(*ctxmngr.Manager)              {}
+func (a *fixingAgent) SetTaskContext(recentTasks []*types.Task, n int) {}
+
+func TestFixDiagnostics(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the diagnostics command")
+	}
+
+	setup := func(t *testing.T) (string, *diagnostics.Checker) {
+		dir := t.TempDir()
+		badFile := filepath.Join(dir, "bad.go")
+		if err := os.WriteFile(badFile, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		script := filepath.Join(dir, "check.sh")
+		body := "#!/bin/sh\nif [ -f bad.go ]; then echo './bad.go:1:1: unused variable x'; exit 1; fi\n"
+		if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return dir, diagnostics.NewChecker(script, 0)
+	}
+
+	t.Run("fixed within budget", func(t *testing.T) {
+		dir, checker := setup(t)
+		agent := &fixingAgent{fixAfter: 0, badFile: filepath.Join(dir, "bad.go")}
+		execCtx := &types.TaskExecutionContext{
+			Guidance: []*types.GuidanceMessage{{ID: "g1", Message: "use the new logger"}},
+		}
+		task := &types.Task{ID: "task-1", ExecutionContext: execCtx}
+
+		result := fixDiagnostics(context.Background(), agent, checker, 3, dir, task, &executor.ExecutionResult{Success: true}, nil)
+		if !result.Success {
+			t.Fatalf("expected success, got %v", result.Error)
+		}
+		if agent.runs != 1 {
+			t.Errorf("expected one fix-it run, got %d", agent.runs)
+		}
+		if len(agent.guidance) != 2 || agent.guidance[0] != "use the new logger" ||
+			!strings.Contains(agent.guidance[1], "bad.go:1:1: unused variable x") {
+			t.Errorf("expected operator guidance plus diagnostics, got %q", agent.guidance)
+		}
+		// The findings don't stick to the task
+		if task.ExecutionContext != execCtx || len(execCtx.Guidance) != 1 {
+			t.Errorf("expected the task's execution context restored, got %+v", task.ExecutionContext)
+		}
+	})
+
+	t.Run("bounded by max iterations", func(t *testing.T) {
+		dir, checker := setup(t)
+		agent := &fixingAgent{fixAfter: 10, badFile: filepath.Join(dir, "bad.go")}
+		task := &types.Task{ID: "task-2"}
+
+		fixDiagnostics(context.Background(), agent, checker, 2, dir, task, &executor.ExecutionResult{Success: true}, nil)
+		if agent.runs != 2 {
+			t.Errorf("expected 2 fix-it runs, got %d", agent.runs)
+		}
+		// Each round only carries that round's findings
+		if len(agent.guidance) != 2 {
+			t.Errorf("expected one guidance message per round, got %q", agent.guidance)
+		}
+		if task.ExecutionContext != nil {
+			t.Errorf("expected no execution context left on the task, got %+v", task.ExecutionContext)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		dir, checker := setup(t)
+		agent := &fixingAgent{badFile: filepath.Join(dir, "bad.go")}
+		fixDiagnostics(context.Background(), agent, checker, 0, dir, &types.Task{ID: "task-3"}, &executor.ExecutionResult{Success: true}, nil)
+		if agent.runs != 0 {
+			t.Errorf("expected no fix-it runs when disabled, got %d", agent.runs)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/dod"
+	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// withDoDGuidance adds the definition-of-done instructions to the task's
+// guidance so the agent reports on the items the diff can't answer
+func withDoDGuidance(task *types.Task, checklist *dod.Checklist) {
+	guidance := checklist.Guidance()
+	if guidance == "" {
+		return
+	}
+	if task.ExecutionContext == nil {
+		task.ExecutionContext = &types.TaskExecutionContext{}
+	}
+	task.ExecutionContext.Guidance = append(task.ExecutionContext.Guidance, &types.GuidanceMessage{
+		ID:        "definition-of-done",
+		TaskID:    task.ID,
+		Message:   guidance,
+		CreatedAt: time.Now().Unix(),
+	})
+}
+
+// enforceDefinitionOfDone evaluates the checklist against a task's committed
+// work and the agent's output, and records the evaluation on the task's
+// timeline. Under the block policy unmet items are returned as a
+// *dod.UnmetError; under follow_up a task is created for each of them in the
+// same epic and nil is returned
+func enforceDefinitionOfDone(ctx context.Context, store *db.Store, gitMgr *git.WorktreeManager,
+	checklist *dod.Checklist, task *types.Task, output string) error {
+	if checklist == nil {
+		return nil
+	}
+
+	diff, err := gitMgr.TaskDiff(ctx, task.ID)
+	if err != nil {
+		// Without a diff every mechanical item would count as unmet
+		log.Printf("⚠️  Skipping definition of done for task %s: %v", task.ID, err)
+		return nil
+	}
+	results := checklist.Evaluate(diff, output)
+	unmet := dod.Unmet(results)
+
+	lines := make([]string, len(results))
+	for i, r := range results {
+		lines[i] = r.String()
+	}
+	if _, err := store.RecordActivity(task.ID, types.ActivityChecklist, "", "Definition of done:\n"+strings.Join(lines, "\n")); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
+	if len(unmet) == 0 {
+		log.Printf("☑️  Task %s meets the definition of done", task.ID)
+		return nil
+	}
+	if checklist.Policy == dod.PolicyBlock {
+		return &dod.UnmetError{Results: unmet}
+	}
+
+	for _, r := range unmet {
+		title := fmt.Sprintf("Definition of done for %q: %s", task.Title, r.Item)
+		description := fmt.Sprintf("Task %s was merged without meeting this definition-of-done item: %s.\n\nReason: %s",
+			task.ID, r.Item, r.Reason)
+		followUp, err := store.CreateTask(title, description, task.EpicID, task.Priority, nil)
+		if err != nil {
+			log.Printf("⚠️  Creating definition-of-done follow-up for task %s: %v", task.ID, err)
+			continue
+		}
+		log.Printf("📌 Task %s: %s unmet, created follow-up %s", task.ID, r.Item, followUp.ID)
+	}
+	return nil
+}
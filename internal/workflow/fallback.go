@@ -0,0 +1,28 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/project"
+)
+
+// configuredModelFallback is the run's model fallback list: --model-fallback
+// or DROVER_MODEL_FALLBACK, else .drover.toml
+func configuredModelFallback(cfg *config.Config, projectCfg *project.Config) ([]executor.ModelFallback, error) {
+	spec := cfg.ModelFallback
+	if spec == "" {
+		spec = strings.Join(projectCfg.ModelFallback, ",")
+	}
+	fallbacks, err := executor.ParseModelFallback(spec)
+	if err != nil {
+		return nil, fmt.Errorf("model fallback: %w", err)
+	}
+	if len(fallbacks) > 0 {
+		log.Printf("[agent] rate limited tasks fall back to %v", fallbacks)
+	}
+	return fallbacks, nil
+}
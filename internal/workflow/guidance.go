@@ -0,0 +1,143 @@
+package workflow
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// guidanceFeed hands guidance added while a task runs to its agent, through
+// a guidance file in the worktree the agent watches. Only drover-worker
+// watches one; other agents get the guidance on the next attempt
+type guidanceFeed struct {
+	store  *db.Store
+	taskID string
+	path   string
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu        sync.Mutex
+	written   map[string]bool // Guidance in the file, by ID
+	delivered map[string]bool // Guidance the agent was given, by ID
+}
+
+// feedGuidance starts feeding the task's agent the guidance added while it
+// runs, when enabled. The guidance the agent starts with isn't fed again.
+// A nil feed is returned when there's nothing to feed; its methods do nothing
+func feedGuidance(store *db.Store, task *types.Task, worktreePath string, enabled bool) *guidanceFeed {
+	if !enabled || store == nil {
+		return nil
+	}
+	path, err := executor.CreateGuidanceFile(worktreePath)
+	if err != nil {
+		log.Printf("⚠️  Task %s: guidance added while it runs waits for its next attempt: %v", task.ID, err)
+		return nil
+	}
+	if task.ExecutionContext == nil {
+		task.ExecutionContext = &types.TaskExecutionContext{}
+	}
+	task.ExecutionContext.GuidanceFile = path
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &guidanceFeed{
+		store:     store,
+		taskID:    task.ID,
+		path:      path,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		written:   map[string]bool{},
+		delivered: map[string]bool{},
+	}
+	for _, g := range task.ExecutionContext.Guidance {
+		f.written[g.ID], f.delivered[g.ID] = true, true // Marked delivered by the caller
+	}
+
+	go func() {
+		defer close(f.done)
+		ticker := time.NewTicker(pausePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				f.poll()
+			}
+		}
+	}()
+	return f
+}
+
+// poll appends the guidance added since the last poll to the file
+func (f *guidanceFeed) poll() {
+	guidance, err := f.store.GetPendingGuidance(f.taskID)
+	if err != nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var fresh []*types.GuidanceMessage
+	for _, g := range guidance {
+		if !f.written[g.ID] {
+			fresh = append(fresh, g)
+		}
+	}
+	if len(fresh) == 0 {
+		return
+	}
+	if err := executor.AppendGuidance(f.path, fresh); err != nil {
+		log.Printf("⚠️  Task %s: handing guidance to its agent: %v", f.taskID, err)
+		return
+	}
+	for _, g := range fresh {
+		f.written[g.ID] = true
+	}
+	log.Printf("💡 Task %s: handing %d new guidance messages to its running agent", f.taskID, len(fresh))
+}
+
+// settle records the guidance the agent run that produced result was given
+// as delivered. What it wasn't given, as it finished first, is fed again to
+// a further run, such as a diagnostics fix, and otherwise stays pending for
+// the next attempt
+func (f *guidanceFeed) settle(result *executor.ExecutionResult) {
+	if f == nil || result == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ids []string
+	for _, id := range result.GuidanceDelivered {
+		if !f.delivered[id] {
+			f.delivered[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 0 {
+		if err := f.store.MarkGuidanceDelivered(ids); err != nil {
+			log.Printf("Error marking guidance delivered: %v", err)
+		} else {
+			log.Printf("💡 Task %s: its agent took %d guidance messages mid-run", f.taskID, len(ids))
+		}
+	}
+	for id := range f.written {
+		if !f.delivered[id] {
+			delete(f.written, id)
+		}
+	}
+}
+
+// stop ends the feed and removes the guidance file
+func (f *guidanceFeed) stop() {
+	if f == nil {
+		return
+	}
+	f.cancel()
+	<-f.done
+	os.Remove(f.path)
+}
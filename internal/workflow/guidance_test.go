@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestGuidanceFeed(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	repo := initHedgeRepo(t)
+
+	task, _ := store.CreateTask("Guided task", "", "", 0, nil)
+	given, _ := store.AddGuidance(task.ID, "use the v2 API")
+	task.ExecutionContext = &types.TaskExecutionContext{Guidance: []*types.GuidanceMessage{given}}
+
+	if feedGuidance(store, task, repo, false) != nil {
+		t.Fatal("Expected no feed for an agent that doesn't take guidance mid-run")
+	}
+	feed := feedGuidance(store, task, repo, true)
+	if feed == nil {
+		t.Fatal("Expected a feed")
+	}
+	path := task.ExecutionContext.GuidanceFile
+	if path == "" {
+		t.Fatal("Expected the guidance file set on the task")
+	}
+
+	added, _ := store.AddGuidance(task.ID, "keep the old endpoint working")
+	feed.poll()
+	feed.poll()
+	data, _ := os.ReadFile(path)
+	if strings.Count(string(data), added.ID) != 1 || strings.Contains(string(data), given.ID) {
+		t.Errorf("Expected only the new guidance in the file, once, got:\n%s", data)
+	}
+
+	// Guidance the run finished before taking is fed to the next one
+	feed.settle(&executor.ExecutionResult{})
+	feed.poll()
+	data, _ = os.ReadFile(path)
+	if strings.Count(string(data), added.ID) != 2 {
+		t.Errorf("Expected the undelivered guidance appended again, got:\n%s", data)
+	}
+
+	feed.settle(&executor.ExecutionResult{GuidanceDelivered: []string{added.ID}})
+	pending, _ := store.GetPendingGuidance(task.ID)
+	if len(pending) != 1 || pending[0].ID != given.ID {
+		t.Errorf("Expected only the delivered guidance marked, pending: %+v", pending)
+	}
+
+	status, err := exec.Command("git", "-C", repo, "status", "--porcelain").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(strings.TrimSpace(string(status))) > 0 {
+		t.Errorf("Expected the guidance file kept out of commits, git status:\n%s", status)
+	}
+
+	feed.stop()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the guidance file removed, got %v", err)
+	}
+}
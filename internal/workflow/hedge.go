@@ -0,0 +1,162 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// hedgeHistory is how many recent task durations the hedging threshold is
+// computed from
+const hedgeHistory = 100
+
+// minHedgeHistory is how many completed tasks it takes before tasks are hedged
+const minHedgeHistory = 10
+
+// hedger starts a second, speculative attempt of a task whose agent runs far
+// past how long tasks usually take, in a fresh worktree, and keeps whichever
+// attempt finishes first
+type hedger struct {
+	after  time.Duration // How long an agent runs before its task is hedged
+	budget int           // Second attempts the run may start
+
+	mu   sync.Mutex
+	used int
+}
+
+// newHedger hedges tasks whose agent runs past factor times the p95 duration
+// of recently completed tasks. Returns nil, which never hedges, for a factor
+// or budget of 0 or without enough history
+func newHedger(store *db.Store, factor float64, budget int) *hedger {
+	if factor <= 0 || budget <= 0 || store == nil {
+		return nil
+	}
+	durations, err := store.CompletedTaskDurations(hedgeHistory)
+	if err != nil {
+		log.Printf("[hedge] warning: reading task durations: %v", err)
+		return nil
+	}
+	if len(durations) < minHedgeHistory {
+		log.Printf("[hedge] %d completed task(s) of history, %d needed: not hedging this run", len(durations), minHedgeHistory)
+		return nil
+	}
+	after := time.Duration(float64(p95(durations)) * factor)
+	log.Printf("🏇 Hedging on: tasks still running after %v get a second attempt (budget %d)", after.Round(time.Second), budget)
+	return &hedger{after: after, budget: budget}
+}
+
+// p95 is the 95th percentile of durations (nearest rank)
+func p95(durations []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := (95*len(sorted) + 99) / 100
+	return sorted[rank-1]
+}
+
+// take spends one second attempt of the budget, if any is left
+func (h *hedger) take() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.used >= h.budget {
+		return false
+	}
+	h.used++
+	return true
+}
+
+// agentRun executes a task's agent in a worktree
+type agentRun func(ctx context.Context, worktreePath string, task *types.Task) *executor.ExecutionResult
+
+// hedgeAttempt is how one attempt of a hedged task ended
+type hedgeAttempt struct {
+	second bool // The speculative attempt, not the task's own
+	result *executor.ExecutionResult
+}
+
+// execute runs the task's agent in its worktree. Once it has run for h.after
+// a second attempt starts in a fresh worktree; the first attempt to succeed
+// wins and the other is stopped. A winning second attempt's work is adopted
+// into the task's worktree, so the task carries on from there as usual.
+// spent records what the discarded attempt spent
+func (h *hedger) execute(ctx context.Context, gitMgr *git.WorktreeManager, task *types.Task, worktreePath string,
+	run agentRun, spent func(*executor.ExecutionResult)) *executor.ExecutionResult {
+	if h == nil || gitMgr == nil {
+		return run(ctx, worktreePath, task)
+	}
+
+	firstCtx, stopFirst := context.WithCancel(ctx)
+	defer stopFirst()
+	done := make(chan hedgeAttempt, 2)
+	go func() { done <- hedgeAttempt{result: run(firstCtx, worktreePath, task)} }()
+
+	timer := time.NewTimer(h.after)
+	defer timer.Stop()
+	select {
+	case attempt := <-done:
+		return attempt.result
+	case <-timer.C:
+	}
+	if !h.take() {
+		log.Printf("🐢 Task %s has run past %v, but the hedging budget is spent", task.ID, h.after.Round(time.Second))
+		return (<-done).result
+	}
+
+	hedgeID := task.ID + "-hedge"
+	hedgePath, err := gitMgr.CreateWithContext(ctx, &types.Task{ID: hedgeID, Title: task.Title, EpicID: task.EpicID})
+	if err != nil {
+		log.Printf("⚠️  Task %s: creating a worktree for a second attempt: %v", task.ID, err)
+		return (<-done).result
+	}
+	defer func() {
+		// Force the worktree out, uncommitted work and all, then drop its branch
+		gitMgr.RemoveAggressive(hedgeID)
+		gitMgr.Remove(hedgeID)
+	}()
+	log.Printf("🏇 Task %s has run past %v: starting a second attempt; the first to finish wins", task.ID, h.after.Round(time.Second))
+
+	secondCtx, stopSecond := context.WithCancel(ctx)
+	defer stopSecond()
+	second := *task
+	go func() { done <- hedgeAttempt{second: true, result: run(secondCtx, hedgePath, &second)} }()
+
+	winner := <-done
+	var loser hedgeAttempt
+	if winner.result.Success {
+		// Stop the other attempt and wait for it to exit
+		if winner.second {
+			stopFirst()
+		} else {
+			stopSecond()
+		}
+		loser = <-done
+	} else {
+		// The other attempt may still succeed
+		loser, winner = winner, <-done
+		if !winner.result.Success && winner.second {
+			// Both failed: the task's own attempt tells why
+			winner, loser = loser, winner
+		}
+	}
+	spent(loser.result)
+
+	if !winner.second {
+		log.Printf("🏁 Task %s: keeping its own attempt; the second one was discarded", task.ID)
+		return winner.result
+	}
+	if err := gitMgr.AdoptWorktree(ctx, task.ID, hedgePath); err != nil {
+		return &executor.ExecutionResult{
+			Output: winner.result.Output,
+			Error:  fmt.Errorf("adopting the work of the second attempt: %w", err),
+		}
+	}
+	log.Printf("🏁 Task %s: the second attempt finished first; keeping its work", task.ID)
+	return winner.result
+}
@@ -0,0 +1,117 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestP95(t *testing.T) {
+	var durations []time.Duration
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Second)
+	}
+	if got := p95(durations); got != 19*time.Second {
+		t.Errorf("Expected a p95 of 19s, got %v", got)
+	}
+	if newHedger(nil, 2, 1) != nil {
+		t.Error("Expected no hedger without a store")
+	}
+}
+
+// initHedgeRepo creates a repository with one commit on main
+func initHedgeRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Test Repo\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+		{"add", "README.md"},
+		{"commit", "-qm", "Initial commit"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	return dir
+}
+
+func TestHedger_Execute(t *testing.T) {
+	dir := initHedgeRepo(t)
+	gitMgr := git.NewWorktreeManager(dir, filepath.Join(dir, ".drover", "worktrees"))
+	task := &types.Task{ID: "task-stuck", Title: "Stuck task"}
+	worktreePath, err := gitMgr.Create(task)
+	if err != nil {
+		t.Fatalf("Failed to create worktree: %v", err)
+	}
+	defer gitMgr.Remove(task.ID)
+
+	// The task's own attempt hangs until it's stopped; the second one finishes
+	var runs, discarded atomic.Int32
+	run := func(ctx context.Context, path string, _ *types.Task) *executor.ExecutionResult {
+		if runs.Add(1) == 1 {
+			os.WriteFile(filepath.Join(path, "stuck.txt"), []byte("wip\n"), 0644)
+			<-ctx.Done()
+			return &executor.ExecutionResult{Error: ctx.Err()}
+		}
+		os.WriteFile(filepath.Join(path, "done.txt"), []byte("done\n"), 0644)
+		return &executor.ExecutionResult{Success: true, Output: "second"}
+	}
+	spent := func(*executor.ExecutionResult) { discarded.Add(1) }
+
+	h := &hedger{after: 20 * time.Millisecond, budget: 1}
+	result := h.execute(context.Background(), gitMgr, task, worktreePath, run, spent)
+	if !result.Success || result.Output != "second" {
+		t.Fatalf("Expected the second attempt's result, got %+v", result)
+	}
+	if discarded.Load() != 1 {
+		t.Errorf("Expected the stopped attempt's spend recorded, got %d", discarded.Load())
+	}
+	if _, err := os.Stat(filepath.Join(worktreePath, "done.txt")); err != nil {
+		t.Errorf("Expected the second attempt's work adopted: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(worktreePath, "stuck.txt")); !os.IsNotExist(err) {
+		t.Errorf("Expected the stopped attempt's work discarded, got %v", err)
+	}
+	if _, err := os.Stat(gitMgr.Path(task.ID + "-hedge")); !os.IsNotExist(err) {
+		t.Errorf("Expected the second attempt's worktree removed, got %v", err)
+	}
+	branch := exec.Command("git", "rev-parse", "--verify", "-q", gitMgr.BranchName(task.ID)+"-hedge")
+	branch.Dir = dir
+	if branch.Run() == nil {
+		t.Error("Expected the second attempt's branch deleted")
+	}
+
+	// With the budget spent the task's own attempt runs to the end
+	runs.Store(0)
+	slow := func(ctx context.Context, path string, _ *types.Task) *executor.ExecutionResult {
+		runs.Add(1)
+		time.Sleep(60 * time.Millisecond)
+		return &executor.ExecutionResult{Error: errors.New("agent failed")}
+	}
+	result = h.execute(context.Background(), gitMgr, task, worktreePath, slow, spent)
+	if result.Success || runs.Load() != 1 {
+		t.Errorf("Expected only the task's own, failed attempt, got %+v after %d run(s)", result, runs.Load())
+	}
+
+	// Without a hedger the agent simply runs
+	var none *hedger
+	if result := none.execute(context.Background(), gitMgr, task, worktreePath, run, spent); result == nil {
+		t.Error("Expected a result without a hedger")
+	}
+}
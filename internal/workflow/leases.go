@@ -0,0 +1,134 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+)
+
+// leaseRenewals is how many times a worker renews its lease per TTL, so a
+// renewal or two can fail before the claim expires
+const leaseRenewals = 3
+
+// holdLease renews a worker's claim on a task until the returned func is
+// called. The returned context is cancelled once the claim is lost to the
+// reaper, so the worker stops its run of a task that may already run
+// elsewhere. With leases disabled it does nothing
+func holdLease(store *db.Store, taskID, workerID string) (lost context.Context, release func()) {
+	ttl := store.LeaseTTL()
+	if ttl <= 0 {
+		return context.Background(), func() {}
+	}
+
+	lost, markLost := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(ttl / leaseRenewals)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := store.RenewLease(taskID, workerID)
+				if errors.Is(err, db.ErrLeaseLost) {
+					if paused(store, taskID) {
+						return // Paused tasks hold no lease until resumed
+					}
+					log.Printf("⚠️  Task %s: %v; it was returned to the queue, stopping this worker's run of it", taskID, err)
+					markLost()
+					return
+				}
+				if err != nil {
+					log.Printf("⚠️  Task %s: %v", taskID, err)
+				}
+			}
+		}
+	}()
+	return lost, func() {
+		close(done)
+		wg.Wait()
+		markLost()
+	}
+}
+
+// leaseLost reports whether the worker lost its claim on the task, so its
+// run of it must not be merged or recorded
+func leaseLost(lost context.Context, taskID string) bool {
+	if lost.Err() == nil {
+		return false
+	}
+	log.Printf("⚠️  Task %s: lease lost, dropping this worker's run of it", taskID)
+	return true
+}
+
+// lostClaim reports whether err means the worker no longer holds the task's
+// claim, logging that it leaves the task to whoever does
+func lostClaim(taskID string, err error) bool {
+	if !errors.Is(err, db.ErrLeaseLost) {
+		return false
+	}
+	log.Printf("⚠️  Task %s: %v; leaving it to the worker that holds it now", taskID, err)
+	return true
+}
+
+// leaseReaper periodically returns tasks whose lease expired, because the
+// worker holding them died or hung, to the queue
+type leaseReaper struct {
+	store *db.Store
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+// startLeaseReaper reaps expired leases every half TTL until stop is called.
+// Returns nil with leases disabled
+func startLeaseReaper(store *db.Store) *leaseReaper {
+	ttl := store.LeaseTTL()
+	if ttl <= 0 {
+		return nil
+	}
+
+	r := &leaseReaper{store: store, done: make(chan struct{})}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			r.reap()
+			select {
+			case <-r.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return r
+}
+
+func (r *leaseReaper) reap() {
+	reaped, err := r.store.ReapExpiredLeases()
+	if err != nil {
+		log.Printf("[lease] warning: reaping expired leases: %v", err)
+		return
+	}
+	for _, t := range reaped {
+		log.Printf("[lease] %s: lease of %s expired, now %s (attempt %d)", t.TaskID, t.ClaimedBy, t.Status, t.Attempts)
+	}
+}
+
+// stop stops reaping; safe on a nil reaper
+func (r *leaseReaper) stop() {
+	if r == nil {
+		return
+	}
+	close(r.done)
+	r.wg.Wait()
+}
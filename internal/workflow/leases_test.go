@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+)
+
+func TestHoldLease_Lost(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	store.SetLeaseTTL(300 * time.Millisecond)
+
+	task, _ := store.CreateTask("Leased", "", "", 0, nil)
+	if claimed, _ := store.ClaimTask("worker-1"); claimed == nil {
+		t.Fatal("ClaimTask claimed nothing")
+	}
+	lost, release := holdLease(store, task.ID, "worker-1")
+	defer release()
+
+	// Renewals keep the claim past its TTL
+	time.Sleep(500 * time.Millisecond)
+	if leaseLost(lost, task.ID) {
+		t.Fatal("Expected the renewed lease held")
+	}
+
+	// Another worker takes the task over
+	if _, err := store.DB.Exec(`UPDATE tasks SET claimed_by = 'worker-2' WHERE id = ?`, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lost.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the lost lease to stop the worker's run")
+	}
+}
@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"log"
+	"sort"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/project"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// mcpServers returns the MCP servers configured under [mcp_servers] in
+// .drover.toml, in the form agents are given them
+func mcpServers(projectCfg *project.Config) map[string]executor.MCPServer {
+	if len(projectCfg.MCPServers) == 0 {
+		return nil
+	}
+	servers := make(map[string]executor.MCPServer, len(projectCfg.MCPServers))
+	for name, server := range projectCfg.MCPServers {
+		servers[name] = executor.MCPServer{Command: server.Command, Args: server.Args, Env: server.Env}
+	}
+	return servers
+}
+
+// withMCPConfig writes the MCP servers into the task's worktree before its
+// agent runs, and points the agent at the file. A task whose servers can't be
+// written runs without them
+func withMCPConfig(task *types.Task, worktreePath string, servers map[string]executor.MCPServer) {
+	if len(servers) == 0 {
+		return
+	}
+	path, err := executor.WriteMCPConfig(worktreePath, servers)
+	if err != nil {
+		log.Printf("⚠️  Task %s: writing MCP servers: %v", task.ID, err)
+		return
+	}
+	if task.ExecutionContext == nil {
+		task.ExecutionContext = &types.TaskExecutionContext{}
+	}
+	task.ExecutionContext.MCPConfig = path
+
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.Printf("🔌 Task %s MCP servers: %v", task.ID, names)
+}
@@ -8,28 +8,36 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math/rand/v2"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/cloud-shuttle/drover/internal/analytics"
+	"github.com/cloud-shuttle/drover/internal/attemptlog"
 	"github.com/cloud-shuttle/drover/internal/backpressure"
 	"github.com/cloud-shuttle/drover/internal/beads"
 	"github.com/cloud-shuttle/drover/internal/config"
 	ctxmngr "github.com/cloud-shuttle/drover/internal/context"
 	"github.com/cloud-shuttle/drover/internal/dashboard"
 	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/diagnostics"
+	"github.com/cloud-shuttle/drover/internal/dod"
 	"github.com/cloud-shuttle/drover/internal/events"
-	outcomepkg "github.com/cloud-shuttle/drover/internal/outcome"
 	"github.com/cloud-shuttle/drover/internal/executor"
 	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/internal/integrations"
+	"github.com/cloud-shuttle/drover/internal/notify"
+	"github.com/cloud-shuttle/drover/internal/output"
 	"github.com/cloud-shuttle/drover/internal/project"
 	"github.com/cloud-shuttle/drover/internal/testing"
 	"github.com/cloud-shuttle/drover/internal/webhooks"
@@ -44,17 +52,44 @@ type Orchestrator struct {
 	config        *config.Config
 	store         *db.Store
 	git           *git.WorktreeManager
+	repos         *git.RepoManager // Worktree managers per repository, the project's own (git) included
 	pool          *git.WorktreePool // Worktree pool for pre-warming
 	agent         executor.Agent // Agent interface for Claude/Codex/Amp
+	diagnostics   *diagnostics.Checker // Static checks fed back to the agent (nil disables)
+	dod           *dod.Checklist       // Definition of done checked before merge (nil disables)
+	verify        *verifier            // Verification commands run before merge (nil disables)
 	workers       int
 	verbose       bool // Enable verbose logging
 	projectDir    string // Project directory for beads sync
 	epicID        string // Optional epic filter for task execution
 	webhooks      *webhooks.Manager // Webhook notification manager
+	statuses      *webhooks.StatusReporter // Commit status checks (PR mode only)
+	notifier      *notify.Notifier         // Chat notifications (nil when not configured)
+	tracker       *trackerSync             // Status transitions pushed to Jira (nil when not configured)
+	concurrency   *concurrencyStats        // Busy time and serialization waits for the run summary
+	usage         runUsage                 // Tokens and cost spent this run
+	retry         RetryPolicy              // How failed attempts are retried, unless a task overrides it
+	reaper        *leaseReaper             // Requeues tasks of workers that died (nil with leases disabled)
 	analytics     *analytics.Manager // Analytics manager
 	backpressure  *backpressure.Controller // Backpressure controller for adaptive concurrency
 	shutdownCtx   context.Context // Context for shutdown signal
 	shutdownFunc  context.CancelFunc // Function to cancel shutdown context
+	draining      chan struct{} // Closed once the run drains: workers stop claiming
+	drainOnce     sync.Once
+	budgetOnce    sync.Once     // Reports the cost budget being reached, once
+	deadline      *runDeadline  // Wall-clock budget of the run (nil = none)
+	deadlineOnce  sync.Once     // Reports the deadline approaching, once
+	preempt       *preemptor    // Pauses low-priority work for urgent tasks (nil = never)
+	hedge         *hedger       // Races stuck tasks against a second attempt (nil = never)
+	breaker       *failureBreaker // Parks the run when tasks keep failing the same way (nil = never)
+	report        *runReporter  // Merge results for the run report (nil outside Run)
+	crew          *workerSet    // The run's workers (nil outside Run)
+	settingsMu    sync.Mutex
+	settings      runSettings   // Settings as changed mid-run with the control file
+	env           map[string]string // Agent environment defaults from [env] in .drover.toml
+	mcp           map[string]executor.MCPServer // MCP servers from [mcp_servers] in .drover.toml
+	logRotation   attemptlog.Rotation // Rotation of the attempt logs, from [logs] in .drover.toml
+	held          atomic.Bool       // Paused through the control API: workers claim nothing until resumed
 }
 
 // NewOrchestrator creates a new workflow orchestrator
@@ -65,9 +100,45 @@ func NewOrchestrator(cfg *config.Config, store *db.Store, projectDir string) (*O
 	)
 	gitMgr.SetVerbose(cfg.Verbose)
 
+	isolationMode, err := git.ParseIsolationMode(cfg.IsolationMode)
+	if err != nil {
+		return nil, err
+	}
+	gitMgr.SetIsolationMode(isolationMode)
+	gitMgr.SetBranchTemplate(cfg.BranchPrefix, cfg.BranchTemplate)
+	gitMgr.SetNetworkTimeout(cfg.GitNetworkTimeout)
+
+	// Load project configuration
+	projectCfg, err := project.Load(projectDir)
+	if err != nil {
+		return nil, fmt.Errorf("loading project config: %w", err)
+	}
+
+	// Validate project config
+	if err := projectCfg.Validate(); err != nil {
+		log.Printf("[project] warning: %v", err)
+	}
+	checklist, err := dod.New(projectCfg.DefinitionOfDone, projectCfg.DoDPolicy)
+	if err != nil {
+		return nil, err
+	}
+	retry, err := configuredRetryPolicy(cfg, projectCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Give an empty repository a root commit and a scaffold task before
+	// anything (including the pool) tries to branch from it
+	if err := bootstrapEmptyRepo(cfg, projectCfg, store, projectDir); err != nil {
+		return nil, err
+	}
+
 	// Initialize worktree pool if enabled
+	// The pool pre-creates linked worktrees, so it is skipped in clone mode
 	var pool *git.WorktreePool
-	if cfg.PoolEnabled {
+	if cfg.PoolEnabled && isolationMode == git.IsolationClone {
+		log.Printf("⚠️  Worktree pooling is not supported in clone isolation mode, disabling pool")
+	} else if cfg.PoolEnabled {
 		poolConfig := &git.PoolConfig{
 			MinSize:         cfg.PoolMinSize,
 			MaxSize:         cfg.PoolMaxSize,
@@ -75,6 +146,22 @@ func NewOrchestrator(cfg *config.Config, store *db.Store, projectDir string) (*O
 			CleanupOnExit:   cfg.PoolCleanupOnExit,
 			EnableSymlinks:  true,
 			GoModCache:      true,
+
+			GoBuildCache:              cfg.PoolGoBuildCache,
+			GoBuildCacheMode:          cfg.PoolGoBuildCacheMode,
+			GoBuildCacheMaxMB:         cfg.PoolGoBuildCacheMaxMB,
+			GoBuildCacheCleanInterval: cfg.PoolGoBuildCacheCleanInterval,
+
+			FetchInterval:  cfg.PoolFetchInterval,
+			FetchJitter:    cfg.PoolFetchJitter,
+			FetchSkipInUse: cfg.PoolFetchSkipInUse,
+			StaleAfter:     cfg.PoolStaleAfter,
+			StalePolicy:    cfg.PoolStalePolicy,
+
+			AdaptiveMaxSize: cfg.PoolAdaptiveMaxSize,
+			DiskReserveMB:   cfg.PoolDiskReserveMB,
+			MemoryReserveMB: cfg.PoolMemoryReserveMB,
+			SizingInterval:  cfg.PoolSizingInterval,
 		}
 		pool = git.NewWorktreePool(gitMgr, poolConfig)
 		if err := pool.Start(); err != nil {
@@ -82,22 +169,45 @@ func NewOrchestrator(cfg *config.Config, store *db.Store, projectDir string) (*O
 		}
 	}
 
-	// Load project configuration
-	projectCfg, err := project.Load(projectDir)
+	// Merge project config with global config
+	projectCfg.MergeWithGlobal(cfg.AgentType, cfg.Workers, cfg.TaskTimeout, cfg.MaxTaskAttempts)
+
+	// Protected paths from .drover.toml and DROVER_PROTECTED_PATHS
+	if protected := projectCfg.GetProtectedPaths(cfg.ProtectedPaths); len(protected) > 0 {
+		gitMgr.SetProtectedPaths(protected)
+		log.Printf("[project] protected paths: %v", protected)
+	}
+
+	// Merge target: explicit override, then .drover.toml, then the detected default branch
+	targetOverride := cfg.TargetBranch
+	if targetOverride == "" {
+		targetOverride = projectCfg.TargetBranch
+	}
+	targetBranch, err := git.ResolveTargetBranch(projectDir, targetOverride, cfg.BranchPrefix)
 	if err != nil {
-		if pool != nil {
-			pool.Stop()
+		if targetOverride != "" {
+			if pool != nil {
+				pool.Stop()
+			}
+			return nil, fmt.Errorf("validating target branch: %w", err)
+		}
+		log.Printf("[git] warning: %v; merging into main", err)
+	} else {
+		gitMgr.SetTargetBranch(targetBranch)
+		if cfg.Verbose {
+			log.Printf("[git] merge target branch: %s", targetBranch)
 		}
-		return nil, fmt.Errorf("loading project config: %w", err)
 	}
 
-	// Validate project config
-	if err := projectCfg.Validate(); err != nil {
-		log.Printf("[project] warning: %v", err)
+	// Attribute task commits to the agent that produced them
+	if cfg.CommitAttribution {
+		gitMgr.SetCommitAuthor(git.RenderCommitAuthor(cfg.CommitAuthorName, cfg.CommitAuthorEmail, projectCfg.Agent, cfg.AgentModel))
 	}
 
-	// Merge project config with global config
-	projectCfg.MergeWithGlobal(cfg.AgentType, cfg.Workers, cfg.TaskTimeout, cfg.MaxTaskAttempts)
+	// Persist worktree lifecycle (path, branch, state, setup time, disk usage)
+	if store != nil {
+		gitMgr.SetRecorder(store)
+	}
 
 	// Create the agent based on configuration with project guidelines
 	agentType := projectCfg.Agent
@@ -109,14 +219,72 @@ func NewOrchestrator(cfg *config.Config, store *db.Store, projectDir string) (*O
 		}
 	}
 
+	sandbox, err := newSandbox(projectCfg.Sandbox, projectDir)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, fmt.Errorf("configuring sandbox: %w", err)
+	}
+	prompts, err := loadPromptTemplates(projectDir)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, err
+	}
+	fallbacks, err := configuredModelFallback(cfg, projectCfg)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, err
+	}
+	maxOutput, err := configuredMaxOutput(cfg, projectCfg)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, err
+	}
+	logRotation, err := setUpAttemptLogs(projectDir, projectCfg.Logs)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		return nil, err
+	}
 	agent, err := executor.NewAgent(&executor.AgentConfig{
 		Type:              agentType,
 		Path:              cfg.AgentPath,
+		Model:             cfg.AgentModel,
+		Fallbacks:         fallbacks,
+		Provider:          cfg.AgentProvider,
+		Custom: executor.CustomAgentConfig{
+			Command:        projectCfg.CustomAgent.Command,
+			SuccessPattern: projectCfg.CustomAgent.SuccessPattern,
+			FailurePattern: projectCfg.CustomAgent.FailurePattern,
+			VerdictPattern: projectCfg.CustomAgent.VerdictPattern,
+		},
 		Timeout:           projectCfg.TaskTimeout,
 		Verbose:           cfg.Verbose,
 		ProjectGuidelines: projectCfg.GetGuidelines(),
 		WorkerBinary:      cfg.WorkerBinary,
 		WorkerMemoryLimit: cfg.WorkerMemoryLimit,
+		WorkerProtocol:    cfg.WorkerProtocol,
+		RemoteWorkers:     remoteWorkers(cfg),
+		WorkerPoolSize:    cfg.WorkerPoolSize,
+		WorkerPoolMaxTasks: cfg.WorkerPoolMaxTasks,
+		CPULimit:          cpuLimit(projectCfg),
+		OpenCodeURL:       cfg.OpenCodeURL,
+		StreamEvents:      cfg.DashboardPort != "",
+		Resume:            cfg.ClaudeResume,
+		LLMURL:            cfg.LLMURL,
+		LLMAPIKey:         cfg.LLMAPIKey,
+		OpenCodeServers:   cfg.OpenCodeServers,
+		Sandbox:           sandbox,
+		PromptTemplates:   prompts,
+		MaxOutput:         maxOutput,
 		ContextThresholds: &ctxmngr.ContentThresholds{
 			MaxDescriptionSize: projectCfg.MaxDescriptionSize,
 			MaxDiffSize:       projectCfg.MaxDiffSize,
@@ -161,6 +329,9 @@ func NewOrchestrator(cfg *config.Config, store *db.Store, projectDir string) (*O
 			MemoryThresholdMB:    cfg.BackpressureMemoryThresholdMB,
 			MemoryCriticalMB:     cfg.BackpressureMemoryCriticalMB,
 			WorkerRSSLimitMB:     cfg.BackpressureWorkerRSSLimitMB,
+
+			// Staggered ramp-up
+			RampUpInterval: cfg.BackpressureRampUpInterval,
 		}
 		backpressureCtrl = backpressure.NewController(backpressureCfg)
 		if cfg.Verbose {
@@ -170,6 +341,9 @@ func NewOrchestrator(cfg *config.Config, store *db.Store, projectDir string) (*O
 				log.Printf("[backpressure] memory-aware: threshold=%dMB, critical=%dMB, worker_limit=%dMB",
 					backpressureCfg.MemoryThresholdMB, backpressureCfg.MemoryCriticalMB, backpressureCfg.WorkerRSSLimitMB)
 			}
+			if backpressureCfg.RampUpInterval > 0 {
+				log.Printf("[backpressure] staggered ramp-up: one worker every %v", backpressureCfg.RampUpInterval)
+			}
 		}
 	}
 
@@ -181,22 +355,56 @@ func NewOrchestrator(cfg *config.Config, store *db.Store, projectDir string) (*O
 		return nil, fmt.Errorf("checking %s: %w", cfg.AgentType, err)
 	}
 
+	concurrency := newConcurrencyStats()
+	gitMgr.SetWaitObserver(concurrency.observeWait)
+
+	// Worktree managers of the other repositories tasks may work in
+	repos, err := newRepoManager(cfg, projectCfg, projectDir, gitMgr, store, concurrency.observeWait)
+	if err != nil {
+		if pool != nil {
+			pool.Stop()
+		}
+		executor.CloseAgent(agent)
+		return nil, err
+	}
+
+	// Claims expire unless the worker holding them keeps renewing them
+	store.SetLeaseTTL(cfg.LeaseTTL)
+
 	orch := &Orchestrator{
 		config:       cfg,
 		store:        store,
 		git:          gitMgr,
+		repos:        repos,
 		pool:         pool,
 		agent:        agent,
+		diagnostics:  newDiagnosticsChecker(cfg, projectCfg),
+		dod:          checklist,
+		verify:       newVerifier(projectCfg),
 		workers:      cfg.Workers,
 		verbose:      cfg.Verbose,
 		projectDir:   projectDir,
 		webhooks:     webhookMgr,
 		analytics:    analyticsMgr,
 		backpressure: backpressureCtrl,
+		statuses:     cfg.CreateStatusReporter(),
+		notifier:     cfg.CreateNotifier(),
+		tracker:      newTrackerSync(cfg.CreateJiraSync(), store),
+		concurrency:  concurrency,
+		retry:        retry,
+		env:          projectCfg.Env,
+		mcp:          mcpServers(projectCfg),
+		logRotation:  logRotation,
+	}
+
+	orch.settings = orch.startSettings()
+	if pool != nil {
+		pool.FollowWorkers(func() int { return orch.runSettings().workers })
 	}
 
 	// Create shutdown context for graceful shutdown
 	orch.shutdownCtx, orch.shutdownFunc = context.WithCancel(context.Background())
+	orch.draining = make(chan struct{})
 
 	// Setup signal handlers for graceful shutdown
 	orch.setupSignalHandlers()
@@ -204,18 +412,51 @@ func NewOrchestrator(cfg *config.Config, store *db.Store, projectDir string) (*O
 	return orch, nil
 }
 
-// setupSignalHandlers installs signal handlers for graceful shutdown
+// setupSignalHandlers drains the run on the first interrupt, and cancels the
+// tasks still in flight on the second or once the drain timeout passes
 func (o *Orchestrator) setupSignalHandlers() {
-	sigChan := make(chan os.Signal, 1)
+	sigChan := make(chan os.Signal, 2)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	go func() {
 		sig := <-sigChan
-		log.Printf("Received signal %v, initiating graceful shutdown...", sig)
+		timeout := o.runSettings().drainTimeout
+		if timeout <= 0 {
+			log.Printf("🛑 Received %v, stopping in-flight tasks...", sig)
+			o.shutdownFunc()
+			return
+		}
+		log.Printf("🛑 Received %v, draining: no new tasks will start; waiting up to %v for the ones in flight (interrupt again to stop them now)", sig, timeout)
+		o.Drain()
+		select {
+		case sig = <-sigChan:
+			log.Printf("🛑 Received %v again, stopping in-flight tasks...", sig)
+		case <-time.After(timeout):
+			log.Printf("⏱️  Drain timeout of %v reached, stopping in-flight tasks...", timeout)
+		}
 		o.shutdownFunc()
 	}()
 }
 
+// recordUsage records what an execution spent and, once the run reaches its
+// cost budget, drains it
+func (o *Orchestrator) recordUsage(task *types.Task, result *executor.ExecutionResult) {
+	o.usage.record(o.store, task, result)
+	if o.usage.overBudget(o.config.MaxCost) {
+		o.budgetOnce.Do(func() {
+			log.Printf("💰 Cost budget of $%.2f reached ($%.2f spent): no new tasks will start; finishing the ones in flight",
+				o.config.MaxCost, o.usage.Total().CostUSD)
+			o.Drain()
+		})
+	}
+}
+
+// Drain stops workers claiming new tasks. Run returns once the tasks already
+// in flight finish
+func (o *Orchestrator) Drain() {
+	o.drainOnce.Do(func() { close(o.draining) })
+}
+
 // SetEpicFilter sets the epic filter for task execution
 // Only tasks belonging to the specified epic will be executed
 func (o *Orchestrator) SetEpicFilter(epicID string) {
@@ -251,42 +492,58 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 	_, workflowSpan := telemetry.StartWorkflowSpan(mergedCtx, telemetry.SpanWorkflowRun, "")
 	defer workflowSpan.End()
 
-	// Start webhook manager
-	if o.webhooks != nil && o.config.WebhooksEnabled {
-		o.webhooks.Start(o.config.WebhookWorkers)
-		defer func() {
-			stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			_ = o.webhooks.Stop(stopCtx)
-		}()
-	}
+	o.Start()
+	defer o.Close()
 
-	// Analytics manager starts automatically on creation
-	// Just ensure it stops on exit
-	if o.analytics != nil {
-		defer func() {
-			stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer cancel()
-			_ = o.analytics.Stop(stopCtx)
-		}()
+	// Every exit writes the run's report; the ones that finish say how
+	o.report = newRunReporter()
+	outcome := RunInterrupted
+	defer func() {
+		if report := o.report.write(o.store, o.projectDir, &o.usage, "sqlite", o.epicID, o.workers, outcome); report != nil {
+			o.notifier.RunEnded(report.Summary())
+		}
+	}()
+	o.notifier.RunStarted(notify.RunStart{EpicID: o.epicID, Workers: o.workers})
+
+	o.deadline = newRunDeadline(o.store, o.config.MaxDuration)
+	if o.deadline != nil {
+		log.Printf("⏰ Run deadline: %s; tasks that wouldn't finish by then aren't started", o.deadline.at.Format("15:04:05"))
 	}
 
-	// Recover orphaned tasks from previous crashes
-	if err := o.recoverOrphanedTasks(); err != nil {
-		log.Printf("[recovery] warning: failed to recover orphaned tasks: %v", err)
+	o.preempt = newPreemptor(o.store, o.epicID, func() int { return o.runSettings().workers }, o.config.PreemptGap, o.recordEvent)
+	if o.preempt != nil {
+		log.Printf("⏸️  Preemption on: tasks %d or more priority levels above the lowest running one pause it", o.config.PreemptGap)
+		go o.preempt.run(mergedCtx)
+		defer o.preempt.resumeAll()
 	}
+	o.hedge = newHedger(o.store, o.config.HedgeFactor, o.config.HedgeBudget)
+	o.breaker = newFailureBreaker(o.config.FailureStreak)
 
-	// Ensure pool is stopped when we exit
-	if o.pool != nil {
-		defer o.pool.Stop()
+	// A daemon outlives its queue, waiting for tasks to be added
+	var idle *daemonIdle
+	if o.config.Daemon {
+		log.Println("🔁 Daemon mode: running tasks as they are added until stopped")
+		idle = newDaemonIdle(o.pool, o.config.DaemonIdle)
 	}
 
 	// Start workers - they will claim tasks independently
-	var wg sync.WaitGroup
-	for i := 0; i < o.workers; i++ {
-		wg.Add(1)
-		go o.worker(mergedCtx, i, &wg)
+	o.crew = &workerSet{}
+	startWorker := func(id int) { o.worker(mergedCtx, id) }
+	if o.config.ControlAddr != "" {
+		stopControl, err := o.serveControl(o.config.ControlAddr, o.config.ControlToken, startWorker)
+		if err != nil {
+			return err
+		}
+		defer stopControl()
 	}
+	o.crew.resize(o.workers, startWorker)
+	wg := &o.crew.wg
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
+	go o.watchControl(mergedCtx, startWorker)
 
 	// Main orchestration loop - just print progress and check for completion
 	ticker := time.NewTicker(o.config.PollInterval)
@@ -294,12 +551,28 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 
 	for {
 		select {
-		case <-ctx.Done():
+		case <-mergedCtx.Done():
 			log.Println("🛑 Context cancelled, stopping...")
 			wg.Wait()
-			_ = o.git.Cleanup() // Clean up any remaining worktrees
+			o.cleanupWorktrees() // Clean up any remaining worktrees
 			o.syncToBeadsIfNeeded()
-			return ctx.Err()
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return context.Canceled
+
+		case <-workersDone:
+			// Workers only stop on their own once the run drains
+			log.Println("✅ Drained: every in-flight task finished")
+			if status, err := o.store.GetProjectStatus(); err == nil {
+				o.printFinalStatus(status)
+			}
+			o.syncToBeadsIfNeeded()
+			outcome = RunDrained
+			if o.breaker.open() {
+				outcome = RunParked
+			}
+			return nil
 
 		case <-ticker.C:
 			// Check if we're done
@@ -309,13 +582,34 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 				continue
 			}
 
-			// Calculate if we're complete
-			active := status.Ready + status.InProgress + status.Claimed
+			// Calculate if we're complete; a task not due to start yet is
+			// left for a later run
+			active := status.Ready - status.Scheduled + status.InProgress + status.Claimed
+			if active == 0 && o.preempt.waiting() {
+				o.preempt.check() // The urgent work is done; bring the preempted tasks back
+				continue
+			}
+			if idle != nil {
+				if idle.observe(active, time.Now()) {
+					o.syncToBeadsIfNeeded()
+				}
+				if active == 0 {
+					continue
+				}
+			}
 			if active == 0 {
 				log.Println("✅ All tasks complete!")
+				if status.Paused > 0 {
+					log.Printf("⏸️  %d paused task(s) left parked; resume them with 'drover resume-task'", status.Paused)
+				}
+				if status.Scheduled > 0 {
+					log.Printf("🕒 %d scheduled task(s) not due to start yet; a later run picks them up", status.Scheduled)
+				}
+				o.Drain() // Nothing is left to claim; let the workers exit
 				wg.Wait()
 				o.printFinalStatus(status)
 				o.syncToBeadsIfNeeded()
+				outcome = RunFinished
 				return nil
 			}
 
@@ -325,10 +619,9 @@ func (o *Orchestrator) Run(ctx context.Context) error {
 	}
 }
 
-// worker claims and executes tasks until the context is cancelled
-func (o *Orchestrator) worker(ctx context.Context, id int, wg *sync.WaitGroup) {
-	defer wg.Done()
-
+// worker claims and executes tasks until the context is cancelled, the run
+// drains or the worker count drops below id
+func (o *Orchestrator) worker(ctx context.Context, id int) {
 	workerID := fmt.Sprintf("worker-%d", id)
 	log.Printf("👷 Worker %d started", id)
 	if o.webhooks != nil {
@@ -343,64 +636,167 @@ func (o *Orchestrator) worker(ctx context.Context, id int, wg *sync.WaitGroup) {
 				o.webhooks.EmitWorkerStopped(workerID, id, 0)
 			}
 			return
+		case <-o.draining:
+			log.Printf("👷 Worker %d stopping (draining)", id)
+			if o.webhooks != nil {
+				o.webhooks.EmitWorkerStopped(workerID, id, 0)
+			}
+			return
 		default:
+			if o.crew.retire(id) {
+				log.Printf("👷 Worker %d stopping (worker count lowered to %d)", id, o.crew.size())
+				if o.webhooks != nil {
+					o.webhooks.EmitWorkerStopped(workerID, id, 0)
+				}
+				return
+			}
+			if o.held.Load() {
+				time.Sleep(time.Second)
+				continue
+			}
+
 			// Check backpressure controller before claiming
 			if o.backpressure != nil && !o.backpressure.CanSpawn() {
 				// In backoff period, wait and retry
 				stats := o.backpressure.GetStats()
 				if o.verbose {
-					log.Printf("[backpressure] worker %d waiting: backoff until %v (in-flight: %d/%d)",
-						id, stats.BackoffUntil.Format("15:04:05"), stats.CurrentInFlight, stats.MaxInFlight)
+					if stats.RampingUp {
+						log.Printf("[backpressure] worker %d waiting: ramping up (in-flight: %d/%d)",
+							id, stats.CurrentInFlight, stats.RampLimit)
+					} else {
+						log.Printf("[backpressure] worker %d waiting: backoff until %v (in-flight: %d/%d)",
+							id, stats.BackoffUntil.Format("15:04:05"), stats.CurrentInFlight, stats.MaxInFlight)
+					}
 				}
 				time.Sleep(time.Second)
 				continue
 			}
 
-			// Try to claim a task (filtered by epic if set)
-			workerID := fmt.Sprintf("worker-%d-%d", id, time.Now().UnixNano())
-			task, err := o.store.ClaimTaskForEpic(workerID, o.epicID)
+			// Don't start a task that likely runs past the deadline
+			if !o.deadline.allows(time.Now()) {
+				o.deadlineOnce.Do(func() {
+					log.Printf("⏰ Run deadline %s is near (tasks take %v on average): no new tasks will start; finishing the ones in flight",
+						o.deadline.at.Format("15:04:05"), o.deadline.expected().Round(time.Second))
+					o.Drain()
+				})
+				continue
+			}
+
+			claimed, err := o.RunNext(ctx, id)
 			if err != nil {
 				log.Printf("Worker %d: error claiming task: %v", id, err)
 				time.Sleep(time.Second)
 				continue
 			}
-
-			if task == nil {
+			if !claimed {
 				// No tasks available, wait a bit before trying again
 				time.Sleep(time.Second)
-				continue
 			}
+		}
+	}
+}
 
-			// Track worker started in backpressure controller
-			if o.backpressure != nil {
-				o.backpressure.WorkerStarted()
-			}
+// RunNext claims the next ready task (within the epic filter, if set) and
+// executes it on the calling goroutine as worker workerID. Reports whether a
+// task was claimed. Callers other than Run must call Start first and Close
+// when done
+func (o *Orchestrator) RunNext(ctx context.Context, workerID int) (bool, error) {
+	claimID := fmt.Sprintf("worker-%d-%d", workerID, time.Now().UnixNano())
+	task, err := o.store.ClaimTaskForEpicWithContext(ctx, claimID, o.epicID)
+	if err != nil {
+		return false, err
+	}
+	if task == nil {
+		return false, nil
+	}
+	// Track worker started in backpressure controller
+	if o.backpressure != nil {
+		o.backpressure.WorkerStarted()
+	}
 
-			// Broadcast task claimed to dashboard
-			dashboard.BroadcastTaskClaimed(task.ID, task.Title, workerID)
+	// Broadcast task claimed to dashboard
+	dashboard.BroadcastTaskClaimed(task.ID, task.Title, claimID)
 
-			// Emit webhook event
-			if o.webhooks != nil {
-				o.webhooks.EmitTaskClaimed(task.ID, task.Title, workerID)
-			}
+	// Emit webhook event
+	if o.webhooks != nil {
+		o.webhooks.EmitTaskClaimed(task.ID, task.Title, claimID)
+	}
 
-			// Execute the task
-			o.executeTask(id, task)
+	// Execute the task
+	started := time.Now()
+	o.preempt.started(task)
+	o.executeTask(ctx, workerID, task)
+	o.preempt.finished(task.ID)
+	o.deadline.observe(time.Since(started))
 
-			// Track worker finished in backpressure controller
-			if o.backpressure != nil {
-				o.backpressure.WorkerFinished()
-			}
-		}
+	// Track worker finished in backpressure controller
+	if o.backpressure != nil {
+		o.backpressure.WorkerFinished()
 	}
+	return true, nil
+}
+
+// Start prepares the orchestrator to execute tasks: it starts webhook
+// delivery and the lease reaper, and recovers tasks orphaned by a previous
+// crash
+func (o *Orchestrator) Start() {
+	if o.webhooks != nil && o.config.WebhooksEnabled {
+		o.webhooks.Start(o.config.WebhookWorkers)
+	}
+
+	// Recover orphaned tasks from previous crashes
+	if err := o.recoverOrphanedTasks(); err != nil {
+		log.Printf("[recovery] warning: failed to recover orphaned tasks: %v", err)
+	}
+
+	o.reaper = startLeaseReaper(o.store)
+}
+
+// Close stops the lease reaper, the webhook and analytics managers, the
+// worktree pool and the agent
+func (o *Orchestrator) Close() {
+	o.reaper.stop()
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if o.webhooks != nil && o.config.WebhooksEnabled {
+		_ = o.webhooks.Stop(stopCtx)
+	}
+	// Analytics manager starts automatically on creation
+	if o.analytics != nil {
+		_ = o.analytics.Stop(stopCtx)
+	}
+	if o.pool != nil {
+		o.pool.Stop()
+	}
+	o.tracker.close()
+	executor.CloseAgent(o.agent)
 }
 
 // executeTask executes a single task
-func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
+func (o *Orchestrator) executeTask(ctx context.Context, workerID int, task *types.Task) {
 	start := time.Now()
+	defer func() { o.concurrency.addBusy(time.Since(start)) }()
+	defer o.concurrency.enter()()
 	taskCompleted := false
 
 	log.Printf("👷 Worker %d executing task %s: %s", workerID, task.ID, task.Title)
+	lost, releaseLease := holdLease(o.store, task.ID, task.ClaimedBy)
+	defer releaseLease()
+
+	// Keep this execution in the task's attempt history; runs last, once the
+	// task's status for it is settled
+	att := startAttempt(o.store, o.projectDir, task.ID, fmt.Sprintf("worker-%d", workerID))
+	att.rotateLogs(o.logRotation)
+	defer att.finish()
+
+	// The task works in its repository's worktrees and merges into its target branch
+	gitMgr, err := o.worktreesFor(task.Repo)
+	if err != nil {
+		log.Printf("❌ Task %s failed: %v", task.ID, err)
+		_ = o.store.FailClaimedTask(task.ID, task.ClaimedBy, err.Error())
+		return
+	}
 
 	// Check if task has sub-tasks - execute them first
 	hasChildren, err := o.store.HasSubTasks(task.ID)
@@ -409,10 +805,10 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 	}
 	if hasChildren {
 		log.Printf("📋 Task %s has sub-tasks, executing them first", task.ID)
-		if !o.executeSubTasks(workerID, task) {
+		if !o.executeSubTasks(ctx, workerID, task) {
 			// Sub-tasks failed, mark parent as failed
 			log.Printf("❌ Task %s failed due to sub-task failures", task.ID)
-			_ = o.store.UpdateTaskStatus(task.ID, types.TaskStatusFailed, "Sub-tasks failed")
+			_ = o.store.FailClaimedTask(task.ID, task.ClaimedBy, "Sub-tasks failed")
 			taskCompleted = true
 			return
 		}
@@ -468,6 +864,7 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 	if o.webhooks != nil {
 		o.webhooks.EmitTaskStarted(task.ID, task.Title, workerIDStr)
 	}
+	o.tracker.push(task.ID, types.TaskStatusInProgress)
 
 	// Record event
 	o.recordEvent(events.EventTaskStarted, task.ID, task.EpicID, map[string]any{
@@ -488,7 +885,9 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 			status, err := o.store.GetTaskStatus(task.ID)
 			if err == nil && status == types.TaskStatusInProgress {
 				log.Printf("⚠️  Task %s still in_progress at exit, marking as failed", task.ID)
-				_ = o.store.UpdateTaskStatus(task.ID, types.TaskStatusFailed, "Task did not complete")
+				if err := o.store.FailClaimedTask(task.ID, task.ClaimedBy, "Task did not complete"); err != nil {
+					lostClaim(task.ID, err)
+				}
 			}
 		}
 	}()
@@ -496,35 +895,36 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 	// Create worktree (use pool if enabled)
 	var worktreePath string
 	var worktreeCleanupNeeded = true
-	if o.pool != nil && o.pool.IsEnabled() {
-		worktreePath, err = o.pool.Acquire(task.ID)
+	var retainWorktree bool // The pool takes the worktree back warm
+	if o.pooled(task.Repo) {
+		worktreePath, err = o.pool.AcquireWithAffinity(task.ID, git.TaskAffinity(task.EpicID, task.Title, task.Description))
 		if err != nil {
 			log.Printf("❌ Task %s failed: acquiring worktree from pool: %v", task.ID, err)
 			telemetry.RecordError(taskSpan, err, "WorktreeAcquireFailed", "pool")
 			telemetry.SetTaskStatus(taskSpan, "failed")
-			if o.handleTaskFailure(task.ID, err.Error()) {
+			if o.handleTaskFailure(task.ID, task.ClaimedBy, FailureWorktree, err.Error()) {
 				taskCompleted = true // Task set to ready for retry
 			}
 			return
 		}
 		defer func() {
 			if worktreeCleanupNeeded {
-				o.pool.Release(task.ID, false) // Don't retain worktree after task completion
+				o.pool.Release(task.ID, retainWorktree)
 			}
 		}()
 	} else {
 		// Check if worktree already exists (from a paused task)
-		existingPath, err := o.git.GetWorktreePath(task.ID)
+		existingPath, err := gitMgr.GetWorktreePath(task.ID)
 		if err == nil && existingPath != "" {
 			worktreePath = existingPath
 			log.Printf("♻️  Reusing existing worktree for task %s at %s", task.ID, worktreePath)
 		} else {
-			worktreePath, err = o.git.Create(task)
+			worktreePath, err = gitMgr.CreateWithContext(ctx, task)
 			if err != nil {
 				log.Printf("❌ Task %s failed: creating worktree: %v", task.ID, err)
 				telemetry.RecordError(taskSpan, err, "WorktreeCreationFailed", "git")
 				telemetry.SetTaskStatus(taskSpan, "failed")
-				if o.handleTaskFailure(task.ID, err.Error()) {
+				if o.handleTaskFailure(task.ID, task.ClaimedBy, FailureWorktree, err.Error()) {
 					taskCompleted = true // Task set to ready for retry
 				}
 				return
@@ -532,11 +932,16 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 		}
 		defer func() {
 			if worktreeCleanupNeeded {
-				o.git.Remove(task.ID)
+				gitMgr.Remove(task.ID)
 			}
 		}()
 	}
 
+	// Worktree is ready: report cold-start cost so ramp-up can pace new workers
+	if o.backpressure != nil {
+		o.backpressure.ObserveColdStart(time.Since(start))
+	}
+
 	// Fetch pending guidance and set on task execution context
 	guidance, err := o.store.GetPendingGuidance(task.ID)
 	if err != nil {
@@ -561,6 +966,30 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 		}()
 	}
 
+	// Point Go builds the agent runs at the shared build cache
+	if env := worktreeEnv(o.pool, worktreePath); len(env) > 0 {
+		if task.ExecutionContext == nil {
+			task.ExecutionContext = &types.TaskExecutionContext{}
+		}
+		task.ExecutionContext.Env = env
+	}
+	// The project's and the task's own variables for the agent
+	if env := loadAgentEnv(o.store, o.env, task.ID); len(env) > 0 {
+		if task.ExecutionContext == nil {
+			task.ExecutionContext = &types.TaskExecutionContext{}
+		}
+		task.ExecutionContext.Env = append(task.ExecutionContext.Env, env.entries()...)
+		task.ExecutionContext.Secrets = env.secrets()
+		att.redact(env.secrets())
+		log.Printf("🔧 Task %s environment: %s", task.ID, env)
+	}
+	withDoDGuidance(task, o.dod)
+	withPreviousAttempt(o.store, task, o.config.ClaudeResume)
+	withMCPConfig(task, worktreePath, o.mcp)
+	// Guidance added while the agent works is handed to it as it runs
+	feed := feedGuidance(o.store, task, worktreePath, o.config.UseWorkerSubprocess)
+	defer feed.stop()
+
 	// Fetch recent completed tasks for context carrying (if enabled)
 	taskContextCount := o.getProjectTaskContextCount()
 	if taskContextCount > 0 {
@@ -574,8 +1003,69 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 		}
 	}
 
-	// Execute Claude Code and capture the result
-	result := o.agent.ExecuteWithContext(taskCtx, worktreePath, task, taskSpan)
+	// Execute Claude Code and capture the result; pausing or cancelling the task stops it
+	agentCtx, stopWatch := watchStop(taskCtx, o.store, task.ID)
+	defer context.AfterFunc(lost, stopWatch)() // So does losing the claim
+	if timeout := o.runSettings().taskTimeout; timeout > 0 {
+		var stopTimeout context.CancelFunc
+		agentCtx, stopTimeout = context.WithTimeout(agentCtx, timeout)
+		defer stopTimeout()
+	}
+	// A task stuck far past the usual duration may race a second attempt
+	result := o.hedge.execute(agentCtx, gitMgr, task, worktreePath,
+		func(ctx context.Context, path string, t *types.Task) *executor.ExecutionResult {
+			return o.agent.ExecuteWithContext(ctx, path, t, taskSpan)
+		},
+		func(discarded *executor.ExecutionResult) { o.recordUsage(task, discarded) })
+	feed.settle(result)
+
+	// Let the agent fix what go vet/tsc/clippy find before the task is committed
+	result = fixDiagnostics(agentCtx, o.agent, o.diagnostics, o.config.DiagnosticsIterations, worktreePath, task, result, taskSpan)
+	feed.settle(result)
+	stopWatch()
+	o.recordUsage(task, result)
+	att.saveOutput(result.Output, result.OutputLog)
+	att.setSession(result.SessionID)
+	att.setModel(result.Model)
+
+	// The task went back to the queue while it ran; another worker has it
+	if leaseLost(lost, task.ID) {
+		telemetry.SetTaskStatus(taskSpan, "lost")
+		if o.analytics != nil {
+			o.analytics.EndTask(task.ID, "lost", "lease lost")
+		}
+		return
+	}
+
+	// A paused task keeps its worktree, uncommitted work and all, for when it's resumed
+	if paused(o.store, task.ID) {
+		log.Printf("⏸️  Task %s paused, parking it with its worktree at %s", task.ID, worktreePath)
+		worktreeCleanupNeeded = false
+		_ = o.store.DeleteCheckpoint(task.ID) // Not orphaned; recovery must leave it alone
+		telemetry.SetTaskStatus(taskSpan, "paused")
+		if o.analytics != nil {
+			o.analytics.EndTask(task.ID, "paused", "")
+		}
+		return
+	}
+
+	// A cancelled task's agent was killed; its worktree is released like a
+	// finished task's and it ends cancelled rather than failed
+	if cancelRequested(o.store, task.ID) {
+		log.Printf("🛑 Task %s cancelled, agent stopped", task.ID)
+		if err := o.store.CancelTask(task.ID, ""); err != nil {
+			log.Printf("Error cancelling task %s: %v", task.ID, err)
+		}
+		_ = o.store.DeleteCheckpoint(task.ID) // Not orphaned; recovery must leave it alone
+		o.recordEvent(events.EventTaskCancelled, task.ID, task.EpicID, map[string]any{
+			"worker": workerIDStr,
+		})
+		telemetry.SetTaskStatus(taskSpan, "cancelled")
+		if o.analytics != nil {
+			o.analytics.EndTask(task.ID, "cancelled", "")
+		}
+		return
+	}
 
 	// Report signal to backpressure controller
 	if o.backpressure != nil {
@@ -586,10 +1076,31 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 		log.Printf("❌ Task %s failed: claude execution: %v", task.ID, result.Error)
 		telemetry.RecordError(taskSpan, result.Error, "AgentExecutionFailed", "agent")
 		telemetry.SetTaskStatus(taskSpan, "failed")
-		if o.handleTaskFailure(task.ID, result.Error.Error()) {
+		if o.handleTaskFailure(task.ID, task.ClaimedBy, classifyAgentFailure(result.Signal, result.Error), result.Error.Error()) {
+			taskCompleted = true // Task set to ready for retry
+		}
+		return
+	}
+
+	// A clean exit is only a pass if the agent's verdict doesn't say otherwise
+	switch verdict, reason := agentVerdict(result); verdict {
+	case types.TaskVerdictFail:
+		log.Printf("❌ Task %s failed: agent verdict: %s", task.ID, reason)
+		telemetry.SetTaskStatus(taskSpan, "failed")
+		requeued := o.handleTaskFailure(task.ID, task.ClaimedBy, FailureVerdict, reason)
+		retryOnVerdict(o.store, task.ID, reason, requeued)
+		if requeued {
 			taskCompleted = true // Task set to ready for retry
 		}
 		return
+	case types.TaskVerdictBlocked:
+		blockOnVerdict(o.store, o.webhooks, o.recordEvent, task, reason)
+		telemetry.SetTaskStatus(taskSpan, "blocked")
+		if o.analytics != nil {
+			o.analytics.EndTask(task.ID, "blocked", reason)
+		}
+		taskCompleted = true
+		return
 	}
 
 	// Store the Claude output for later use (if no changes detected)
@@ -597,16 +1108,52 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 
 	// Commit changes (if any)
 	commitMsg := fmt.Sprintf("drover: %s\n\nTask: %s", task.ID, task.Title)
-	hasChanges, err := o.git.Commit(task.ID, commitMsg)
+	hasChanges, err := gitMgr.CommitWithContext(ctx, task.ID, commitMsg)
+	var violation *git.ProtectedPathError
+	if errors.As(err, &violation) {
+		// Protected changes were unstaged; don't merge anything from this task
+		o.failPolicyViolation(task, violation)
+		telemetry.RecordError(taskSpan, violation, "PolicyViolation", "git")
+		telemetry.SetTaskStatus(taskSpan, "failed")
+		taskCompleted = true
+		return
+	}
 	if err != nil {
 		log.Printf("❌ Task %s failed: committing: %v", task.ID, err)
 		telemetry.RecordError(taskSpan, err, "CommitFailed", "git")
 		telemetry.SetTaskStatus(taskSpan, "failed")
-		if o.handleTaskFailure(task.ID, err.Error()) {
+		if o.handleTaskFailure(task.ID, task.ClaimedBy, FailureGit, err.Error()) {
 			taskCompleted = true // Task set to ready for retry
 		}
 		return
 	}
+	if hasChanges {
+		recordCommitAuthor(o.store, gitMgr, task.ID)
+
+		// Unmet definition-of-done items block the merge or become follow-up tasks
+		if err := enforceDefinitionOfDone(ctx, o.store, gitMgr, o.dod, task, claudeOutput); err != nil {
+			log.Printf("☐  Task %s failed: %v", task.ID, err)
+			telemetry.RecordError(taskSpan, err, "DefinitionOfDoneUnmet", "policy")
+			telemetry.SetTaskStatus(taskSpan, "failed")
+			if o.handleTaskFailure(task.ID, task.ClaimedBy, FailureDoD, err.Error()) {
+				taskCompleted = true // Task set to ready for retry
+			}
+			return
+		}
+
+		// The project's verification commands must pass before the work is merged
+		if err := o.verify.run(ctx, task.ID, worktreePath, taskCommandEnv(o.pool, worktreePath, loadAgentEnv(o.store, o.env, task.ID))); err != nil {
+			log.Printf("❌ Task %s failed verification: %s", task.ID, firstLine(err.Error()))
+			telemetry.RecordError(taskSpan, err, "VerificationFailed", "verify")
+			telemetry.SetTaskStatus(taskSpan, "failed")
+			requeued := o.handleTaskFailure(task.ID, task.ClaimedBy, FailureVerify, err.Error())
+			retryOnVerification(o.store, task.ID, err.Error(), requeued)
+			if requeued {
+				taskCompleted = true // Task set to ready for retry
+			}
+			return
+		}
+	}
 
 	// Log diagnostic output when no changes were detected
 	if !hasChanges && o.verbose {
@@ -621,31 +1168,62 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 		log.Printf("╚════════════════════════════════════════════════════════════════════════╝")
 	}
 
-	// Try to merge to main (if there are changes to merge)
-	if err := o.git.MergeToMain(task.ID); err != nil {
+	// Only the worker holding the claim may merge the task's work
+	if leaseLost(lost, task.ID) {
+		telemetry.SetTaskStatus(taskSpan, "lost")
+		return
+	}
+
+	// In PR mode, push the branch for review instead of merging locally
+	var pushedSHA string
+	merged := true // The task's work reached the target branch or remote, if it had any
+	if o.config.PRMode {
+		if hasChanges {
+			pushedSHA, err = gitMgr.PushBranchWithContext(ctx, task.ID, o.config.PRRemote)
+			merged = err == nil
+			if err != nil {
+				log.Printf("⚠️  Task %s completed but push failed: %v", task.ID, err)
+				telemetry.RecordError(taskSpan, err, "PushFailed", "git")
+			}
+			o.recordMerge(task.ID, mergeResult("push", hasChanges, err))
+			reportCommitStatus(o.statuses, pushedSHA, task.ID, webhooks.CommitStatePending, "Running verification")
+		}
+	} else if err := gitMgr.MergeToMainWithContext(ctx, task.ID); err != nil {
+		// Try to merge to main (if there are changes to merge)
 		// Log merge error but continue - task completed successfully even if merge failed
 		log.Printf("⚠️  Task %s completed but merge failed: %v", task.ID, err)
 		telemetry.RecordError(taskSpan, err, "MergeFailed", "git")
+		o.recordMerge(task.ID, mergeResult("merge", hasChanges, err))
+		merged = false
 		// Don't return here - continue to mark task as complete
+	} else {
+		o.recordMerge(task.ID, mergeResult("merge", hasChanges, nil))
 	}
 
 	// Run automated tests before task completion
-	if err := o.runTests(task.ID, worktreePath, taskSpan); err != nil {
+	if err := o.runTests(gitMgr, task.ID, worktreePath, taskSpan); err != nil {
 		log.Printf("❌ Task %s failed automated tests: %v", task.ID, err)
+		reportCommitStatus(o.statuses, pushedSHA, task.ID, webhooks.CommitStateFailure, "Automated tests failed")
 		telemetry.RecordError(taskSpan, err, "TestExecutionFailed", "tests")
 		telemetry.SetTaskStatus(taskSpan, "failed")
-		if o.handleTaskFailure(task.ID, err.Error()) {
+		if o.handleTaskFailure(task.ID, task.ClaimedBy, FailureTests, err.Error()) {
 			taskCompleted = true // Task set to ready for retry
 		}
 		return
 	}
 
 	// Mark complete and unblock dependents
-	if err := o.store.CompleteTask(task.ID); err != nil {
+	if err := o.store.CompleteClaimedTask(task.ID, task.ClaimedBy); err != nil {
+		if lostClaim(task.ID, err) {
+			telemetry.SetTaskStatus(taskSpan, "lost")
+			return
+		}
 		log.Printf("Error completing task: %v", err)
 	}
+	o.breaker.success()
 
 	taskCompleted = true
+	retainWorktree = o.config.PoolRetain && merged
 	duration := time.Since(start)
 	log.Printf("✅ Worker %d completed task %s in %v", workerID, task.ID, duration)
 
@@ -656,6 +1234,7 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 	if o.webhooks != nil {
 		o.webhooks.EmitTaskCompleted(task.ID, task.Title, duration.Milliseconds())
 	}
+	o.tracker.push(task.ID, types.TaskStatusCompleted)
 
 	// Record event
 	o.recordEvent(events.EventTaskCompleted, task.ID, task.EpicID, map[string]any{
@@ -664,11 +1243,13 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 		"duration": duration.Milliseconds(),
 	})
 
-	// Parse and store structured outcome
-	outcome := outcomepkg.ParseOutput(claudeOutput)
-	if err := o.store.SetTaskVerdict(task.ID, types.TaskVerdict(outcome.Verdict), outcome.Summary); err != nil {
+	// Store the structured outcome
+	verdict, verdictReason := completionVerdict(result, claudeOutput)
+	if err := o.store.SetTaskVerdict(task.ID, verdict, verdictReason); err != nil {
 		log.Printf("Error storing verdict for task %s: %v", task.ID, err)
 	}
+	reportVerdictStatus(o.statuses, pushedSHA, task.ID, verdict, verdictReason)
+	logFollowups(task.ID, result)
 
 	// End analytics tracking
 	if o.analytics != nil {
@@ -680,9 +1261,118 @@ func (o *Orchestrator) executeTask(workerID int, task *types.Task) {
 	telemetry.RecordTaskCompleted(taskCtx, workerIDStr, o.epicID, string(task.Type), duration)
 }
 
+// failPolicyViolation fails a task whose changes touched protected paths
+// Retrying would reproduce the same changes, so the failure is permanent
+func (o *Orchestrator) failPolicyViolation(task *types.Task, violation *git.ProtectedPathError) {
+	errMsg := violation.Error()
+	log.Printf("🚫 Task %s failed: %s", task.ID, errMsg)
+
+	if err := o.store.FailClaimedTask(task.ID, task.ClaimedBy, errMsg); err != nil {
+		if lostClaim(task.ID, err) {
+			return
+		}
+		log.Printf("Error updating task status: %v", err)
+	}
+	if err := o.store.SetTaskVerdict(task.ID, types.TaskVerdictPolicyViolation, errMsg); err != nil {
+		log.Printf("Error storing verdict for task %s: %v", task.ID, err)
+	}
+
+	dashboard.BroadcastTaskFailed(task.ID, task.Title, errMsg)
+	if o.webhooks != nil {
+		o.webhooks.EmitTaskFailed(task.ID, task.Title, errMsg, task.Attempts)
+	}
+	o.recordEvent(events.EventTaskFailed, task.ID, task.EpicID, map[string]any{
+		"error":           errMsg,
+		"verdict":         string(types.TaskVerdictPolicyViolation),
+		"protected_paths": violation.Paths,
+	})
+	if o.analytics != nil {
+		o.analytics.EndTask(task.ID, "failed", errMsg)
+	}
+}
+
+// worktreeEnv returns per-command environment for commands run in a worktree,
+// currently the shared Go build cache when the pool manages one
+func worktreeEnv(pool *git.WorktreePool, worktreePath string) []string {
+	if pool == nil || !pool.IsEnabled() {
+		return nil
+	}
+	return pool.GoCacheEnv(worktreePath)
+}
+
+// recordCommitAuthor stores the author identity and commit of a task on its row
+// so blame in the repository and the task database agree on who produced it
+func recordCommitAuthor(store *db.Store, gitMgr *git.WorktreeManager, taskID string) {
+	author := gitMgr.CommitAuthor()
+	if store == nil || author.IsZero() {
+		return
+	}
+	sha, err := gitMgr.CommitSHA(taskID)
+	if err != nil {
+		log.Printf("⚠️  Failed to resolve commit for task %s: %v", taskID, err)
+		return
+	}
+	if err := store.SetTaskCommitAuthor(taskID, author.String(), sha); err != nil {
+		log.Printf("⚠️  Failed to record commit author for task %s: %v", taskID, err)
+	}
+}
+
+// reportCommitStatus publishes a commit status check for a pushed task branch
+// No-op outside PR mode or when nothing was pushed
+func reportCommitStatus(statuses *webhooks.StatusReporter, sha, taskID string, state webhooks.CommitState, description string) {
+	if statuses == nil || sha == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := statuses.Report(ctx, sha, taskID, state, description); err != nil {
+		log.Printf("⚠️  Failed to report %s status for task %s: %v", state, taskID, err)
+	}
+}
+
+// syncTrackerStatus pushes a task's status to the issue it was imported from
+// No-op when Jira isn't configured or the task didn't come from Jira
+func syncTrackerStatus(jira *integrations.JiraSync, store *db.Store, taskID string, status types.TaskStatus) {
+	if jira == nil {
+		return
+	}
+	task, err := store.GetTask(taskID)
+	if err != nil || task.ExternalRef == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := jira.PushStatus(ctx, task, status); err != nil {
+		log.Printf("⚠️  Failed to sync %s status for task %s to %s: %v", status, task.ID, task.ExternalRef, err)
+	}
+}
+
+// reportVerdictStatus maps a task verdict to the final commit status check
+func reportVerdictStatus(statuses *webhooks.StatusReporter, sha, taskID string, verdict types.TaskVerdict, summary string) {
+	state, description := verdictStatus(verdict, summary)
+	reportCommitStatus(statuses, sha, taskID, state, description)
+}
+
+// verdictStatus returns the commit status check a task verdict maps to
+func verdictStatus(verdict types.TaskVerdict, summary string) (webhooks.CommitState, string) {
+	state := webhooks.CommitStateSuccess
+	description := "Task completed"
+	switch verdict {
+	case types.TaskVerdictFail, types.TaskVerdictBlocked:
+		state = webhooks.CommitStateFailure
+		description = fmt.Sprintf("Verdict: %s", verdict)
+	case types.TaskVerdictPass:
+		description = "Verdict: pass"
+	}
+	if summary != "" {
+		description += " - " + summary
+	}
+	return state, description
+}
+
 // executeSubTasks executes all sub-tasks of a parent task
 // Returns true if all sub-tasks succeeded, false if any failed
-func (o *Orchestrator) executeSubTasks(workerID int, parentTask *types.Task) bool {
+func (o *Orchestrator) executeSubTasks(ctx context.Context, workerID int, parentTask *types.Task) bool {
 	subTasks, err := o.store.GetSubTasks(parentTask.ID)
 	if err != nil {
 		log.Printf("Error getting sub-tasks: %v", err)
@@ -693,10 +1383,21 @@ func (o *Orchestrator) executeSubTasks(workerID int, parentTask *types.Task) boo
 		return true
 	}
 
+	// Sub-tasks work in their parent's repository
+	gitMgr, err := o.worktreesFor(parentTask.Repo)
+	if err != nil {
+		log.Printf("❌ Task %s failed: %v", parentTask.ID, err)
+		return false
+	}
+
 	log.Printf("📋 Executing %d sub-tasks for %s", len(subTasks), parentTask.ID)
 
-	// Execute sub-tasks sequentially in order
+	// Execute sub-tasks sequentially in order, resuming after those that already
+	// completed when the parent is retried
 	for _, subTask := range subTasks {
+		if subTask.Status == types.TaskStatusCompleted {
+			continue
+		}
 		log.Printf("📋 Executing sub-task %s: %s", subTask.ID, subTask.Title)
 
 		// Update sub-task status to in_progress
@@ -715,18 +1416,18 @@ func (o *Orchestrator) executeSubTasks(workerID int, parentTask *types.Task) boo
 
 		// Create worktree for sub-task (use pool if enabled)
 		var worktreePath string
-		if o.pool != nil && o.pool.IsEnabled() {
-			worktreePath, err = o.pool.Acquire(subTask.ID)
+		if o.pooled(parentTask.Repo) {
+			worktreePath, err = o.pool.AcquireWithAffinity(subTask.ID, git.TaskAffinity(parentTask.EpicID, subTask.Title, subTask.Description))
 			if err != nil {
 				log.Printf("❌ Sub-task %s failed: acquiring worktree from pool: %v", subTask.ID, err)
-				o.handleTaskFailure(subTask.ID, err.Error())
+				o.handleTaskFailure(subTask.ID, "", FailureWorktree, err.Error())
 				return false
 			}
 		} else {
-			worktreePath, err = o.git.Create(subTask)
+			worktreePath, err = gitMgr.CreateWithContext(ctx, subTask)
 			if err != nil {
 				log.Printf("❌ Sub-task %s failed: creating worktree: %v", subTask.ID, err)
-				o.handleTaskFailure(subTask.ID, err.Error())
+				o.handleTaskFailure(subTask.ID, "", FailureWorktree, err.Error())
 				return false
 			}
 		}
@@ -755,6 +1456,10 @@ func (o *Orchestrator) executeSubTasks(workerID int, parentTask *types.Task) boo
 			}()
 		}
 
+		subAttempt := startAttempt(o.store, o.projectDir, subTask.ID, fmt.Sprintf("worker-%d", workerID))
+		subAttempt.rotateLogs(o.logRotation)
+		defer subAttempt.finish()
+
 		// Execute sub-task
 		start := time.Now()
 		taskCtx, taskSpan := telemetry.StartTaskSpan(context.Background(),
@@ -763,44 +1468,88 @@ func (o *Orchestrator) executeSubTasks(workerID int, parentTask *types.Task) boo
 		telemetry.RecordTaskClaimed(taskCtx, fmt.Sprintf("worker-%d", workerID), parentTask.EpicID)
 		defer taskSpan.End()
 
+		if env := worktreeEnv(o.pool, worktreePath); len(env) > 0 {
+			// Keep any guidance loaded above
+			if subTask.ExecutionContext == nil {
+				subTask.ExecutionContext = &types.TaskExecutionContext{}
+			}
+			subTask.ExecutionContext.Env = env
+		}
+		// Sub-tasks run with their parent's variables, and their own on top
+		if env := loadAgentEnv(o.store, o.env, parentTask.ID, subTask.ID); len(env) > 0 {
+			if subTask.ExecutionContext == nil {
+				subTask.ExecutionContext = &types.TaskExecutionContext{}
+			}
+			subTask.ExecutionContext.Env = append(subTask.ExecutionContext.Env, env.entries()...)
+			subTask.ExecutionContext.Secrets = env.secrets()
+			subAttempt.redact(env.secrets())
+		}
+		withPreviousAttempt(o.store, subTask, o.config.ClaudeResume)
+		withMCPConfig(subTask, worktreePath, o.mcp)
 		result := o.agent.ExecuteWithContext(taskCtx, worktreePath, subTask, taskSpan)
+		o.recordUsage(subTask, result)
+		subAttempt.saveOutput(result.Output, result.OutputLog)
+		subAttempt.setSession(result.SessionID)
+		subAttempt.setModel(result.Model)
 
 		// Report signal to backpressure controller
 		if o.backpressure != nil {
 			o.backpressure.OnWorkerSignal(result.Signal)
 		}
 
-		// Clean up worktree
-		if o.pool != nil && o.pool.IsEnabled() {
-			o.pool.Release(subTask.ID, false)
-		} else {
-			o.git.Remove(subTask.ID)
+		// Clean up the worktree once its changes are committed and merged;
+		// the pool takes a merged one back warm when retaining
+		releaseWorktree := func(merged bool) {
+			if o.pooled(parentTask.Repo) {
+				o.pool.Release(subTask.ID, o.config.PoolRetain && merged)
+			} else {
+				gitMgr.Remove(subTask.ID)
+			}
 		}
 
 		if !result.Success {
+			releaseWorktree(false)
 			log.Printf("❌ Sub-task %s failed: %v", subTask.ID, result.Error)
 			telemetry.RecordError(taskSpan, result.Error, "AgentExecutionFailed", "agent")
 			telemetry.SetTaskStatus(taskSpan, "failed")
-			o.handleTaskFailure(subTask.ID, result.Error.Error())
+			o.handleTaskFailure(subTask.ID, "", classifyAgentFailure(result.Signal, result.Error), result.Error.Error())
+			return false
+		}
+		if verdict, reason := agentVerdict(result); verdict != "" {
+			releaseWorktree(false)
+			if verdict == types.TaskVerdictBlocked {
+				blockOnVerdict(o.store, o.webhooks, o.recordEvent, subTask, reason)
+				telemetry.SetTaskStatus(taskSpan, "blocked")
+				return false
+			}
+			log.Printf("❌ Sub-task %s failed: agent verdict: %s", subTask.ID, reason)
+			telemetry.SetTaskStatus(taskSpan, "failed")
+			retryOnVerdict(o.store, subTask.ID, reason, o.handleTaskFailure(subTask.ID, "", FailureVerdict, reason))
 			return false
 		}
 
 		// Commit changes
 		commitMsg := fmt.Sprintf("drover: %s (sub-task of %s)\n\nTask: %s", subTask.ID, parentTask.ID, subTask.Title)
-		_, err = o.git.Commit(subTask.ID, commitMsg)
+		subHasChanges, err := gitMgr.CommitWithContext(ctx, subTask.ID, commitMsg)
 		if err != nil {
+			releaseWorktree(false)
 			log.Printf("❌ Sub-task %s failed: committing: %v", subTask.ID, err)
 			telemetry.RecordError(taskSpan, err, "CommitFailed", "git")
 			telemetry.SetTaskStatus(taskSpan, "failed")
-			o.handleTaskFailure(subTask.ID, err.Error())
+			o.handleTaskFailure(subTask.ID, "", FailureGit, err.Error())
 			return false
 		}
+		if subHasChanges {
+			recordCommitAuthor(o.store, gitMgr, subTask.ID)
+		}
 
 		// Try to merge to main
-		if err := o.git.MergeToMain(subTask.ID); err != nil {
-			log.Printf("⚠️  Sub-task %s completed but merge failed: %v", subTask.ID, err)
-			telemetry.RecordError(taskSpan, err, "MergeFailed", "git")
+		mergeErr := gitMgr.MergeToMainWithContext(ctx, subTask.ID)
+		if mergeErr != nil {
+			log.Printf("⚠️  Sub-task %s completed but merge failed: %v", subTask.ID, mergeErr)
+			telemetry.RecordError(taskSpan, mergeErr, "MergeFailed", "git")
 		}
+		releaseWorktree(mergeErr == nil)
 
 		// Mark sub-task complete
 		if err := o.store.CompleteTask(subTask.ID); err != nil {
@@ -835,11 +1584,25 @@ func (o *Orchestrator) recordEvent(eventType events.EventType, taskID, epicID st
 	if err := o.store.RecordEvent(eventID, string(eventType), timestamp, taskID, epicID, dataJSON); err != nil {
 		log.Printf("Error recording event: %v", err)
 	}
+
+	if eventType == events.EventTaskFailed {
+		notifyTaskFailed(o.notifier, o.store, o.env, taskID, data)
+	}
 }
 
-// handleTaskFailure increments attempts and either retries or marks as failed
-// Returns true if the task was set to ready for retry (false if permanently failed)
-func (o *Orchestrator) handleTaskFailure(taskID, errorMsg string) bool {
+// handleTaskFailure either requeues a failed task under its retry policy or
+// marks it as failed, once it's out of attempts or failed in a way the policy
+// doesn't retry. A requeued task waits out the policy's backoff before it can
+// be claimed again. Returns true if the task was set to ready for retry
+// (false if permanently failed). With claim set, the task is only touched
+// while that claim still holds it
+func (o *Orchestrator) handleTaskFailure(taskID, claim string, class FailureClass, errorMsg string) bool {
+	// Tasks failing the same way over and over park the run
+	if o.breaker.failure(errorMsg) {
+		o.notifier.RunParked(o.breaker.reason())
+		o.Drain()
+	}
+
 	// Fetch current task to check attempts before incrementing
 	task, err := o.store.GetTask(taskID)
 	if err != nil {
@@ -847,7 +1610,7 @@ func (o *Orchestrator) handleTaskFailure(taskID, errorMsg string) bool {
 		_ = o.store.UpdateTaskStatus(taskID, types.TaskStatusFailed, errorMsg)
 		dashboard.BroadcastTaskFailed(taskID, taskID, errorMsg)
 		if o.webhooks != nil {
-			o.webhooks.EmitTaskFailed(taskID, taskID, errorMsg, task.Attempts)
+			o.webhooks.EmitTaskFailed(taskID, taskID, errorMsg, 0)
 		}
 		if o.analytics != nil {
 			o.analytics.EndTask(taskID, "failed", errorMsg)
@@ -855,10 +1618,36 @@ func (o *Orchestrator) handleTaskFailure(taskID, errorMsg string) bool {
 		return false
 	}
 
-	// Check if we've exceeded max attempts
-	if task.Attempts >= task.MaxAttempts {
-		_ = o.store.UpdateTaskStatus(taskID, types.TaskStatusFailed, errorMsg)
-		log.Printf("❌ Task %s failed after %d attempts", taskID, task.Attempts)
+	// A task that went back to the queue is another worker's to fail or retry
+	if claim != "" && task.ClaimedBy != claim {
+		lostClaim(taskID, fmt.Errorf("%w on task %s", db.ErrLeaseLost, taskID))
+		return false
+	}
+
+	// A blocker outside the task's own work gets a fix task to wait for
+	if o.config.FixBlockers {
+		gitMgr, _ := o.worktreesFor(task.Repo)
+		if queueBlockerFix(o.store, o.webhooks, o.recordEvent, gitMgr, task, class, errorMsg) {
+			if o.analytics != nil {
+				o.analytics.EndTask(taskID, "blocked", errorMsg)
+			}
+			return true
+		}
+	}
+
+	policy := taskRetryPolicy(o.retry, task)
+	retryable := policy.Retries(class)
+
+	// Check if we've exceeded max attempts or the failure isn't retried
+	if task.Attempts >= task.MaxAttempts || !retryable {
+		if err := o.store.FailClaimedTask(taskID, claim, errorMsg); lostClaim(taskID, err) {
+			return false
+		}
+		if retryable {
+			log.Printf("❌ Task %s failed after %d attempts", taskID, task.Attempts)
+		} else {
+			log.Printf("❌ Task %s failed (%s failures are not retried)", taskID, class)
+		}
 		dashboard.BroadcastTaskFailed(task.ID, task.Title, errorMsg)
 		if o.webhooks != nil {
 			o.webhooks.EmitTaskFailed(task.ID, task.Title, errorMsg, task.Attempts)
@@ -869,14 +1658,28 @@ func (o *Orchestrator) handleTaskFailure(taskID, errorMsg string) bool {
 		o.recordEvent(events.EventTaskFailed, task.ID, task.EpicID, map[string]any{
 			"error":    errorMsg,
 			"attempts": task.Attempts,
+			"class":    string(class),
 		})
 		return false
 	}
 
-	// Increment attempts in database
-	if err := o.store.IncrementTaskAttempts(taskID); err != nil {
-		log.Printf("Error incrementing attempts for task %s: %v", taskID, err)
-		_ = o.store.UpdateTaskStatus(taskID, types.TaskStatusFailed, errorMsg)
+	// Sub-tasks are rerun by their parent, which waits out its own backoff
+	var delay time.Duration
+	var notBefore time.Time
+	if task.ParentID == "" {
+		delay = policy.Delay(task.Attempts+1, rand.Float64)
+		if delay > 0 {
+			notBefore = time.Now().Add(delay)
+		}
+	}
+
+	// Count the attempt and return the task to the queue
+	if err := o.store.RequeueClaimedTask(taskID, claim, errorMsg, notBefore); err != nil {
+		if lostClaim(taskID, err) {
+			return false
+		}
+		log.Printf("Error requeueing task %s: %v", taskID, err)
+		_ = o.store.FailClaimedTask(taskID, claim, errorMsg)
 		dashboard.BroadcastTaskFailed(task.ID, task.Title, errorMsg)
 		if o.analytics != nil {
 			o.analytics.EndTask(taskID, "failed", errorMsg)
@@ -884,15 +1687,17 @@ func (o *Orchestrator) handleTaskFailure(taskID, errorMsg string) bool {
 		return false
 	}
 
-	// Allow retry - task.Attempts is now incremented
-	_ = o.store.UpdateTaskStatus(taskID, types.TaskStatusReady, errorMsg)
-	log.Printf("🔄 Task %s retrying (attempt %d/%d)", taskID, task.Attempts+1, task.MaxAttempts)
+	if delay > 0 {
+		log.Printf("🔄 Task %s retrying in %v (%s failure, attempt %d/%d)", taskID, delay, class, task.Attempts+1, task.MaxAttempts)
+	} else {
+		log.Printf("🔄 Task %s retrying (attempt %d/%d)", taskID, task.Attempts+1, task.MaxAttempts)
+	}
 	return true
 }
 
 // runTests executes automated tests before task completion
 // Returns an error if tests fail and the task is configured to block on test failures
-func (o *Orchestrator) runTests(taskID, worktreePath string, taskSpan trace.Span) error {
+func (o *Orchestrator) runTests(gitMgr *git.WorktreeManager, taskID, worktreePath string, taskSpan trace.Span) error {
 	// Get the task to check test configuration
 	task, err := o.store.GetTask(taskID)
 	if err != nil {
@@ -929,6 +1734,9 @@ func (o *Orchestrator) runTests(taskID, worktreePath string, taskSpan trace.Span
 	// Create test runner and run tests
 	runner := testing.NewRunner(testConfig, worktreePath)
 	runner.SetVerbose(o.verbose)
+	runner.SetEnv(taskCommandEnv(o.pool, worktreePath, loadAgentEnv(o.store, o.env, taskID)))
+	runner.SetBaseBranch(gitMgr.TargetBranch())
+	runner.SetShards(o.concurrency.testShards(o.config.Workers, o.config.TestShards))
 
 	result := runner.Run(worktreePath, taskID)
 
@@ -982,25 +1790,65 @@ func (o *Orchestrator) printProgress(status *db.ProjectStatus) {
 	log.Printf("📊 Progress: %d/%d tasks (%.1f%%) | Ready: %d | In Progress: %d | Paused: %d | Blocked: %d | Failed: %d",
 		status.Completed, status.Total, progress,
 		status.Ready, status.InProgress, status.Paused, status.Blocked, status.Failed)
+	if status.Scheduled > 0 || status.Overdue > 0 {
+		log.Printf("⏰ Scheduled for later: %d | Overdue: %d", status.Scheduled, status.Overdue)
+	}
+}
+
+// recordMerge notes a task's merge result for the run report
+func (o *Orchestrator) recordMerge(taskID, result string) {
+	if o.report != nil {
+		o.report.merged(taskID, result)
+	}
 }
 
 // printFinalStatus prints final run results
 func (o *Orchestrator) printFinalStatus(status *db.ProjectStatus) {
-	fmt.Println("\n🐂 Drover Run Complete")
-	fmt.Println("═════════════════════")
-	fmt.Printf("\nTotal tasks:     %d", status.Total)
-	fmt.Printf("\nCompleted:       %d", status.Completed)
-	fmt.Printf("\nFailed:          %d", status.Failed)
-	fmt.Printf("\nBlocked:         %d", status.Blocked)
+	output.Println("\n🐂 Drover Run Complete")
+	output.Println("═════════════════════")
+	output.Printf("\nTotal tasks:     %d", status.Total)
+	output.Printf("\nCompleted:       %d", status.Completed)
+	output.Printf("\nFailed:          %d", status.Failed)
+	output.Printf("\nBlocked:         %d", status.Blocked)
+	if status.Overdue > 0 {
+		output.Printf("\nOverdue:         %d", status.Overdue)
+	}
 
 	if status.Total > 0 {
 		successRate := float64(status.Completed) / float64(status.Total) * 100
-		fmt.Printf("\n\nSuccess rate:    %.1f%%", successRate)
+		output.Printf("\n\nSuccess rate:    %.1f%%", successRate)
+	}
+
+	if usage := o.usage.Total(); !usage.IsZero() {
+		output.Printf("\nSpent this run:  %s", usage)
+		if o.config.MaxCost > 0 {
+			output.Printf(" of a $%.2f budget", o.config.MaxCost)
+		}
+		output.Printf("\nSpent in total:  %s", status.Usage)
+		if lines := o.usage.epicLines(); len(lines) > 0 {
+			output.Printf("\n\n💰 Spent per epic this run")
+			for _, line := range lines {
+				output.Printf("\n   %s", line)
+			}
+		}
+	}
+	if o.usage.overBudget(o.config.MaxCost) {
+		output.Printf("\n\n💰 Stopped early: the cost budget was reached; 'drover run' again to continue")
+	}
+	if o.deadline.reached() {
+		output.Printf("\n\n⏰ Stopped early: the run's --max-duration was nearly up; 'drover run' again to continue")
+	}
+
+	if lines := o.concurrency.Summary(); len(lines) > 0 {
+		output.Printf("\n\n⏱️  Bottlenecks")
+		for _, line := range lines {
+			output.Printf("\n   %s", line)
+		}
 	}
 
 	if status.Failed > 0 || status.Blocked > 0 {
-		fmt.Println("\n\n⚠️  Some tasks did not complete successfully")
-		fmt.Println("   Run 'drover status' for details")
+		output.Println("\n\n⚠️  Some tasks did not complete successfully")
+		output.Println("   Run 'drover status' for details")
 	}
 }
 
@@ -1079,62 +1927,3 @@ func (o *Orchestrator) getProjectTaskContextMaxAge() time.Duration {
 	// Return default if no project config
 	return 24 * time.Hour
 }
-
-// recoverOrphanedTasks finds and recovers tasks that were in progress but crashed
-func (o *Orchestrator) recoverOrphanedTasks() error {
-	// Default orphan timeout: 2 minutes
-	orphanTimeout := int64(120)
-	if o.config.StallTimeout > 0 {
-		orphanTimeout = int64(o.config.StallTimeout.Seconds())
-	}
-
-	// Find orphaned checkpoints
-	orphaned, err := o.store.FindOrphanedCheckpoints(orphanTimeout)
-	if err != nil {
-		return fmt.Errorf("finding orphaned checkpoints: %w", err)
-	}
-
-	if len(orphaned) == 0 {
-		if o.verbose {
-			log.Printf("[recovery] no orphaned tasks found")
-		}
-		return nil
-	}
-
-	log.Printf("[recovery] found %d orphaned task(s)", len(orphaned))
-
-	for _, checkpoint := range orphaned {
-		// Check if we should requeue the task
-		task, err := o.store.GetTask(checkpoint.TaskID)
-		if err != nil {
-			log.Printf("[recovery] warning: failed to get task %s: %v", checkpoint.TaskID, err)
-			continue
-		}
-
-		if task == nil {
-			log.Printf("[recovery] warning: task %s no longer exists", checkpoint.TaskID)
-			_ = o.store.DeleteCheckpoint(checkpoint.TaskID)
-			continue
-		}
-
-		// Check retry limit
-		if checkpoint.Attempt >= task.MaxAttempts {
-			log.Printf("[recovery] task %s exceeded max attempts (%d), marking as failed",
-				checkpoint.TaskID, task.MaxAttempts)
-			_ = o.store.UpdateTaskStatus(checkpoint.TaskID, types.TaskStatusFailed,
-				fmt.Sprintf("Max retries exceeded (%d attempts)", checkpoint.Attempt))
-			_ = o.store.DeleteCheckpoint(checkpoint.TaskID)
-			continue
-		}
-
-		// Requeue the task for retry
-		log.Printf("[recovery] requeueing task %s (attempt %d/%d)",
-			checkpoint.TaskID, checkpoint.Attempt+1, task.MaxAttempts)
-		_ = o.store.UpdateTaskStatus(checkpoint.TaskID, types.TaskStatusReady,
-			fmt.Sprintf("Recovering from crash (attempt %d)", checkpoint.Attempt))
-		_ = o.store.DeleteCheckpoint(checkpoint.TaskID)
-	}
-
-	log.Printf("[recovery] recovered %d task(s)", len(orphaned))
-	return nil
-}
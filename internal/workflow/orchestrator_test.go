@@ -7,12 +7,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/cloud-shuttle/drover/internal/config"
 	"github.com/cloud-shuttle/drover/internal/db"
 	"github.com/cloud-shuttle/drover/internal/workflow"
+	"github.com/cloud-shuttle/drover/pkg/types"
 )
 
 // setupTestWorkflow creates a complete test environment for workflow integration tests
@@ -229,6 +231,38 @@ func TestOrchestrator_MultipleTasks(t *testing.T) {
 	}
 }
 
+// TestOrchestrator_ScheduledTask verifies a run finishes with a task that
+// isn't due to start yet left ready for a later run
+func TestOrchestrator_ScheduledTask(t *testing.T) {
+	_, store, orch, cleanup := setupTestWorkflow(t)
+	defer cleanup()
+
+	now, err := store.CreateTask("Due task", "Do some work", "", 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	later, err := store.CreateTask("Deferred task", "Do it tomorrow", "", 10, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	if err := store.SetTaskSchedule(later.ID, time.Now().Add(time.Hour), time.Time{}); err != nil {
+		t.Fatalf("SetTaskSchedule failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	if err := orch.Run(ctx); err != nil {
+		t.Fatalf("Expected the run to finish, got %v", err)
+	}
+
+	if status, _ := store.GetTaskStatus(now.ID); status != "completed" {
+		t.Errorf("Expected the due task completed, got %q", status)
+	}
+	if status, _ := store.GetTaskStatus(later.ID); status != "ready" {
+		t.Errorf("Expected the deferred task left ready, got %q", status)
+	}
+}
+
 // TestOrchestrator_DependentTasks verifies dependent tasks are processed in order
 func TestOrchestrator_DependentTasks(t *testing.T) {
 	_, store, orch, cleanup := setupTestWorkflow(t)
@@ -305,6 +339,7 @@ exit 1
 		Workers:      1,
 		WorktreeDir:  filepath.Join(tmpDir, ".drover", "worktrees"),
 		PollInterval: 100 * time.Millisecond,
+		RetryPolicy:  "backoff=0s", // Retry at once to keep the test fast
 		Verbose:      true,
 	}
 
@@ -400,6 +435,7 @@ exit 0
 		Workers:      1,
 		WorktreeDir:  filepath.Join(tmpDir, ".drover", "worktrees"),
 		PollInterval: 100 * time.Millisecond,
+		RetryPolicy:  "backoff=0s", // Retry at once to keep the test fast
 		Verbose:      true,
 	}
 
@@ -443,3 +479,466 @@ exit 0
 		t.Errorf("Expected task status 'completed' after retries, got '%s'", status)
 	}
 }
+
+// TestOrchestrator_RetryBackoff verifies a failed task waits out its backoff
+// before it can be claimed again, and that unretried failures fail at once
+func TestOrchestrator_RetryBackoff(t *testing.T) {
+	tmpDir, store, _, cleanup := setupTestWorkflow(t)
+	defer cleanup()
+
+	mockClaude := filepath.Join(tmpDir, "mock-claude-fail.sh")
+	scriptContent := `#!/bin/bash
+if [ "$1" = "--version" ]; then
+	echo "claude-mock-fail version 1.0.0"
+	exit 0
+fi
+echo "Claude error" >&2
+exit 1
+`
+	if err := os.WriteFile(mockClaude, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("Failed to create mock claude: %v", err)
+	}
+
+	cfg := &config.Config{
+		AgentType:    "claude",
+		AgentPath:    mockClaude,
+		TaskTimeout:  5 * time.Second,
+		Workers:      1,
+		WorktreeDir:  filepath.Join(tmpDir, ".drover", "worktrees"),
+		PollInterval: 100 * time.Millisecond,
+		RetryPolicy:  "backoff=1h,max=2h,jitter=0",
+	}
+	orch, err := workflow.NewOrchestrator(cfg, store, tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	backedOff, _ := store.CreateTask("Backed off", "Fails and waits", "", 10, nil)
+	notRetried, _ := store.CreateTask("Not retried", "Fails for good", "", 5, nil)
+	if err := store.SetTaskRetryPolicy(notRetried.ID, "on=tests"); err != nil {
+		t.Fatalf("SetTaskRetryPolicy: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	orch.Start()
+	defer orch.Close()
+	for i := 0; i < 2; i++ {
+		if _, err := orch.RunNext(ctx, 1); err != nil {
+			t.Fatalf("RunNext: %v", err)
+		}
+	}
+
+	task, err := store.GetTask(backedOff.ID)
+	if err != nil {
+		t.Fatalf("GetTask: %v", err)
+	}
+	if task.Status != "ready" || task.Attempts != 1 {
+		t.Errorf("backed-off task is %s after %d attempt(s), want ready after 1", task.Status, task.Attempts)
+	}
+	if task.ScheduledAt == nil || time.Until(time.Unix(*task.ScheduledAt, 0)) < 59*time.Minute {
+		t.Errorf("backed-off task may be claimed at %v, want an hour from now", task.ScheduledAt)
+	}
+	if status, _ := store.GetTaskStatus(notRetried.ID); status != "failed" {
+		t.Errorf("task that doesn't retry agent failures is %s, want failed", status)
+	}
+
+	// Nothing is claimable until the backoff is over
+	if claimed, err := orch.RunNext(ctx, 1); err != nil || claimed {
+		t.Errorf("RunNext during the backoff = %v, %v; want nothing claimed", claimed, err)
+	}
+}
+
+func TestParseRetryPolicy(t *testing.T) {
+	p, err := workflow.ParseRetryPolicy("backoff=10s, max=1m, factor=3, jitter=0, on=agent|timeout", workflow.DefaultRetryPolicy)
+	if err != nil {
+		t.Fatalf("ParseRetryPolicy: %v", err)
+	}
+	for n, want := range map[int]time.Duration{1: 10 * time.Second, 2: 30 * time.Second, 3: time.Minute, 10: time.Minute} {
+		if got := p.Delay(n, nil); got != want {
+			t.Errorf("delay before retry %d = %v, want %v", n, got, want)
+		}
+	}
+	if !p.Retries(workflow.FailureTimeout) || p.Retries(workflow.FailureTests) {
+		t.Errorf("policy %s should retry timeouts but not test failures", p)
+	}
+
+	// Jitter stays within its share of the delay
+	jittered, _ := workflow.ParseRetryPolicy("backoff=100s,jitter=0.2", workflow.DefaultRetryPolicy)
+	if lo, hi := jittered.Delay(1, func() float64 { return 0 }), jittered.Delay(1, func() float64 { return 0.999 }); lo != 80*time.Second || hi > 120*time.Second || hi < 119*time.Second {
+		t.Errorf("jittered delays = %v..%v, want 80s..120s", lo, hi)
+	}
+
+	// Settings not in the spec keep the base policy's values
+	if got, _ := workflow.ParseRetryPolicy("", workflow.DefaultRetryPolicy); got.String() != workflow.DefaultRetryPolicy.String() {
+		t.Errorf("empty spec = %s, want the default", got)
+	}
+	for _, bad := range []string{"backoff", "backoff=soon", "factor=0.5", "jitter=2", "on=flaky", "retries=3"} {
+		if _, err := workflow.ParseRetryPolicy(bad, workflow.DefaultRetryPolicy); err == nil {
+			t.Errorf("ParseRetryPolicy(%q) should fail", bad)
+		}
+	}
+}
+
+func TestNewOrchestrator_BootstrapEmptyRepo(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "trunk"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test User"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tmpDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, ".drover"), 0755); err != nil {
+		t.Fatalf("Failed to create db directory: %v", err)
+	}
+	store, err := db.Open(filepath.Join(tmpDir, ".drover", "drover.db"))
+	if err != nil {
+		t.Fatalf("Failed to open db: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to init schema: %v", err)
+	}
+	epic, err := store.CreateEpic("Launch", "")
+	if err != nil {
+		t.Fatalf("Failed to create epic: %v", err)
+	}
+	feature, err := store.CreateTask("Add feature", "", epic.ID, 5, nil)
+	if err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+
+	mockAgent := filepath.Join(tmpDir, "mock-claude.sh")
+	if err := os.WriteFile(mockAgent, []byte("#!/bin/bash\nexit 0\n"), 0755); err != nil {
+		t.Fatalf("Failed to create mock agent: %v", err)
+	}
+
+	cfg := &config.Config{
+		AgentType:   "claude",
+		AgentPath:   mockAgent,
+		TaskTimeout: 5 * time.Second,
+		Workers:     1,
+		WorktreeDir: filepath.Join(tmpDir, ".drover", "worktrees"),
+		Bootstrap:   true,
+		Epic:        epic.ID,
+	}
+	if _, err := workflow.NewOrchestrator(cfg, store, tmpDir); err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	// The unborn branch now has a root commit
+	cmd := exec.Command("git", "rev-parse", "--verify", "trunk")
+	cmd.Dir = tmpDir
+	if err := cmd.Run(); err != nil {
+		t.Fatalf("Expected trunk to exist after bootstrap: %v", err)
+	}
+
+	blockers, err := store.GetBlockedBy(feature.ID)
+	if err != nil || len(blockers) != 1 {
+		t.Fatalf("Expected feature task to be blocked on the scaffold task, got %v (%v)", blockers, err)
+	}
+	scaffold, err := store.GetTask(blockers[0])
+	if err != nil {
+		t.Fatalf("Failed to get scaffold task: %v", err)
+	}
+	if scaffold.Status != "ready" {
+		t.Errorf("Expected scaffold task to be ready, got %s", scaffold.Status)
+	}
+	// A run filtered to the epic has to be able to claim it
+	if scaffold.EpicID != epic.ID {
+		t.Errorf("Expected scaffold task in the run's epic %s, got %q", epic.ID, scaffold.EpicID)
+	}
+	if got, _ := store.GetTask(feature.ID); got.Status != "blocked" {
+		t.Errorf("Expected feature task to be blocked, got %s", got.Status)
+	}
+
+	// The next run finds the repository still at the root commit, and the
+	// scaffold task already queued
+	if _, err := workflow.NewOrchestrator(cfg, store, tmpDir); err != nil {
+		t.Fatalf("Failed to create second orchestrator: %v", err)
+	}
+	if tasks, _ := store.ListTasks(); len(tasks) != 2 {
+		t.Errorf("Expected one scaffold task across runs, got %d tasks", len(tasks))
+	}
+
+	// A run that stopped before queueing it gets it on the next
+	for _, query := range []string{"DELETE FROM task_dependencies WHERE blocked_by = ?", "DELETE FROM tasks WHERE id = ?"} {
+		if _, err := store.DB.Exec(query, scaffold.ID); err != nil {
+			t.Fatalf("Failed to remove scaffold task: %v", err)
+		}
+	}
+	if _, err := workflow.NewOrchestrator(cfg, store, tmpDir); err != nil {
+		t.Fatalf("Failed to create third orchestrator: %v", err)
+	}
+	if blockers, _ := store.GetBlockedBy(feature.ID); len(blockers) != 1 || blockers[0] == scaffold.ID {
+		t.Errorf("Expected feature task blocked on a new scaffold task, got %v", blockers)
+	}
+}
+
+// TestOrchestrator_Drain verifies a drained run finishes the task in flight
+// and claims no more
+func TestOrchestrator_Drain(t *testing.T) {
+	tmpDir, store, _, cleanup := setupTestWorkflow(t)
+	defer cleanup()
+
+	mockClaude := filepath.Join(tmpDir, "mock-claude-slow.sh")
+	scriptContent := `#!/bin/bash
+if [ "$1" = "--version" ]; then
+	echo "claude-mock-slow version 1.0.0"
+	exit 0
+fi
+sleep 1
+echo "slow work" > "work-$$.txt"
+exit 0
+`
+	if err := os.WriteFile(mockClaude, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("Failed to create mock claude: %v", err)
+	}
+
+	cfg := &config.Config{
+		AgentType:    "claude",
+		AgentPath:    mockClaude,
+		TaskTimeout:  10 * time.Second,
+		Workers:      1,
+		WorktreeDir:  filepath.Join(tmpDir, ".drover", "worktrees"),
+		PollInterval: 100 * time.Millisecond,
+		DrainTimeout: time.Minute,
+	}
+	orch, err := workflow.NewOrchestrator(cfg, store, tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	first, _ := store.CreateTask("First", "Runs before the drain", "", 10, nil)
+	second, _ := store.CreateTask("Second", "Never starts", "", 5, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- orch.Run(ctx)
+	}()
+
+	for {
+		if status, _ := store.GetTaskStatus(first.ID); status == "in_progress" {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the first task to start")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	orch.Drain()
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Errorf("Drained run returned %v, want nil", err)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("Drained run didn't return")
+	}
+
+	if status, _ := store.GetTaskStatus(first.ID); status != "completed" {
+		t.Errorf("Task in flight during the drain is %s, want completed", status)
+	}
+	if status, _ := store.GetTaskStatus(second.ID); status != "ready" {
+		t.Errorf("Task queued during the drain is %s, want ready", status)
+	}
+}
+
+func TestOrchestrator_CancelRunningTask(t *testing.T) {
+	tmpDir, store, _, cleanup := setupTestWorkflow(t)
+	defer cleanup()
+
+	// The agent's child holds its output open, so the run only finishes
+	// quickly if the whole process group is killed
+	mockClaude := filepath.Join(tmpDir, "mock-claude-stuck.sh")
+	scriptContent := `#!/bin/bash
+if [ "$1" = "--version" ]; then
+	echo "claude-mock-stuck version 1.0.0"
+	exit 0
+fi
+sleep 60
+exit 0
+`
+	if err := os.WriteFile(mockClaude, []byte(scriptContent), 0755); err != nil {
+		t.Fatalf("Failed to create mock claude: %v", err)
+	}
+
+	cfg := &config.Config{
+		AgentType:    "claude",
+		AgentPath:    mockClaude,
+		TaskTimeout:  time.Minute,
+		Workers:      1,
+		WorktreeDir:  filepath.Join(tmpDir, ".drover", "worktrees"),
+		PollInterval: 100 * time.Millisecond,
+	}
+	orch, err := workflow.NewOrchestrator(cfg, store, tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	task, _ := store.CreateTask("Stuck", "Never finishes on its own", "", 10, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- orch.Run(ctx)
+	}()
+
+	for {
+		if status, _ := store.GetTaskStatus(task.ID); status == "in_progress" {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the task to start")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+	if err := store.RequestCancel(task.ID, "no longer needed"); err != nil {
+		t.Fatalf("RequestCancel: %v", err)
+	}
+
+	select {
+	case err := <-errChan:
+		if err != nil {
+			t.Errorf("Run returned %v, want nil", err)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("Run didn't return after the task was cancelled")
+	}
+
+	got, _ := store.GetTask(task.ID)
+	if got.Status != "cancelled" || got.LastError != "no longer needed" {
+		t.Errorf("Cancelled task is %s with last error %q, want cancelled with the reason", got.Status, got.LastError)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.WorktreeDir, task.ID)); !os.IsNotExist(err) {
+		t.Errorf("Worktree of the cancelled task still exists (stat: %v)", err)
+	}
+}
+
+func TestOrchestrator_Daemon(t *testing.T) {
+	tmpDir, store, _, cleanup := setupTestWorkflow(t)
+	defer cleanup()
+
+	cfg := &config.Config{
+		AgentType:    "claude",
+		AgentPath:    filepath.Join(tmpDir, "mock-claude.sh"),
+		TaskTimeout:  5 * time.Second,
+		Workers:      1,
+		WorktreeDir:  filepath.Join(tmpDir, ".drover", "worktrees"),
+		PollInterval: 100 * time.Millisecond,
+		Daemon:       true,
+		DaemonIdle:   time.Minute,
+	}
+	orch, err := workflow.NewOrchestrator(cfg, store, tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- orch.Run(ctx)
+	}()
+
+	// An empty queue doesn't end a daemon
+	select {
+	case err := <-errChan:
+		t.Fatalf("Daemon returned %v with an empty queue", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	task, _ := store.CreateTask("Late arrival", "Added while the daemon waits", "", 10, nil)
+	for {
+		if status, _ := store.GetTaskStatus(task.ID); status == "completed" {
+			break
+		}
+		select {
+		case err := <-errChan:
+			t.Fatalf("Daemon returned %v before running the new task", err)
+		case <-ctx.Done():
+			t.Fatal("Timed out waiting for the daemon to run the new task")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	select {
+	case err := <-errChan:
+		t.Fatalf("Daemon returned %v once the queue emptied again", err)
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case err := <-errChan:
+		if err == nil {
+			t.Error("Stopped daemon returned nil, want the context's error")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Daemon didn't stop")
+	}
+}
+
+// TestOrchestrator_Verify verifies work failing a verification command is
+// retried instead of merged, with the failure as guidance for the retry
+func TestOrchestrator_Verify(t *testing.T) {
+	tmpDir, store, _, cleanup := setupTestWorkflow(t)
+	defer cleanup()
+
+	// Fails the first time it runs, passes after that
+	marker := filepath.Join(tmpDir, "verified-once")
+	projectCfg := fmt.Sprintf("verify = [\"test -f %s || { touch %s; echo 'lint: 3 problems'; exit 1; }\"]\n", marker, marker)
+	if err := os.WriteFile(filepath.Join(tmpDir, ".drover.toml"), []byte(projectCfg), 0644); err != nil {
+		t.Fatalf("Failed to write .drover.toml: %v", err)
+	}
+
+	cfg := &config.Config{
+		AgentType:    "claude",
+		AgentPath:    filepath.Join(tmpDir, "mock-claude.sh"),
+		TaskTimeout:  5 * time.Second,
+		Workers:      1,
+		WorktreeDir:  filepath.Join(tmpDir, ".drover", "worktrees"),
+		PollInterval: 100 * time.Millisecond,
+		RetryPolicy:  "backoff=0s",
+	}
+	orch, err := workflow.NewOrchestrator(cfg, store, tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create orchestrator: %v", err)
+	}
+
+	task, _ := store.CreateTask("Verified task", "Do some work", "", 10, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := orch.Run(ctx); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	got, _ := store.GetTask(task.ID)
+	if got.Status != "completed" || got.Attempts != 1 {
+		t.Errorf("Expected the task completed on its retry, got %s after %d failed attempt(s)", got.Status, got.Attempts)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("Expected the verification command to have run: %v", err)
+	}
+	activity, _ := store.ListActivity(task.ID)
+	guided := false
+	for _, a := range activity {
+		guided = guided || a.Kind == types.ActivityGuidance && strings.Contains(a.Body, "lint: 3 problems")
+	}
+	if !guided {
+		t.Error("Expected the verification failure queued as guidance for the retry")
+	}
+}
@@ -0,0 +1,30 @@
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/memory"
+	"github.com/cloud-shuttle/drover/internal/project"
+)
+
+// configuredMaxOutput is how much of each output stream of an agent run is
+// kept in memory: --max-output or DROVER_MAX_OUTPUT, else .drover.toml; 0
+// leaves the executor's default
+func configuredMaxOutput(cfg *config.Config, projectCfg *project.Config) (int64, error) {
+	size := cfg.MaxOutput
+	if size == "" {
+		size = projectCfg.MaxOutput
+	}
+	if size == "" {
+		return 0, nil
+	}
+	max, err := memory.ParseBytes(size)
+	if err != nil {
+		return 0, fmt.Errorf("max output: %w", err)
+	}
+	if max < 1024 {
+		return 0, fmt.Errorf("max output: %s is too small to keep a run's outcome (want at least 1K)", size)
+	}
+	return max, nil
+}
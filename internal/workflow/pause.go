@@ -0,0 +1,61 @@
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// pausePollInterval is how often a running task is checked for a pause or a
+// cancellation request
+const pausePollInterval = 2 * time.Second
+
+// watchStop returns a context that is cancelled once the task is paused or
+// its cancellation is requested, so the agent working on it stops. The
+// returned func ends the watch
+func watchStop(ctx context.Context, store *db.Store, taskID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	if store == nil {
+		return ctx, cancel
+	}
+
+	go func() {
+		ticker := time.NewTicker(pausePollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if paused(store, taskID) || cancelRequested(store, taskID) {
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return ctx, cancel
+}
+
+// paused reports whether a task has been paused. A paused task is parked by
+// its worker: its status, claim and worktree are left for ResumeTask
+func paused(store *db.Store, taskID string) bool {
+	if store == nil {
+		return false
+	}
+	status, err := store.GetTaskStatus(taskID)
+	return err == nil && status == types.TaskStatusPaused
+}
+
+// cancelRequested reports whether a running task's cancellation was
+// requested. Its worker kills the agent, releases the worktree and cancels
+// the task rather than failing it
+func cancelRequested(store *db.Store, taskID string) bool {
+	if store == nil {
+		return false
+	}
+	requested, err := store.CancelRequested(taskID)
+	return err == nil && requested
+}
@@ -0,0 +1,205 @@
+package workflow
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/events"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// preemptPollInterval is how often a run with preemption looks for urgent
+// ready tasks and finished urgent ones
+const preemptPollInterval = 2 * time.Second
+
+// preemptor pauses the lowest-priority task in flight when every worker is
+// busy and a ready task outranks it by at least gap priority levels. The
+// paused task keeps its worktree and goes back to the queue once the task it
+// made room for is done
+type preemptor struct {
+	store   *db.Store
+	epicID  string
+	workers func() int // The run's current worker count
+	gap     int
+	record  func(eventType events.EventType, taskID, epicID string, data map[string]any)
+
+	mu      sync.Mutex
+	running map[string]*types.Task // Tasks in flight on this run's workers
+	paused  map[string]preemption  // Preempted tasks by ID
+}
+
+// preemption is a task paused to make room for an urgent one
+type preemption struct {
+	task   *types.Task
+	urgent string // ID of the task it made room for
+}
+
+// newPreemptor returns nil, which never preempts, for a gap of 0. workers
+// reports the run's worker count, which may change while it runs
+func newPreemptor(store *db.Store, epicID string, workers func() int, gap int,
+	record func(events.EventType, string, string, map[string]any)) *preemptor {
+	if gap <= 0 {
+		return nil
+	}
+	return &preemptor{
+		store:   store,
+		epicID:  epicID,
+		workers: workers,
+		gap:     gap,
+		record:  record,
+		running: make(map[string]*types.Task),
+		paused:  make(map[string]preemption),
+	}
+}
+
+// started notes a task a worker began executing; safe on a nil preemptor
+func (p *preemptor) started(task *types.Task) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running[task.ID] = task
+}
+
+// finished notes a worker being done with a task, parked or not
+func (p *preemptor) finished(taskID string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.running, taskID)
+}
+
+// run checks for preemption every preemptPollInterval until ctx ends
+func (p *preemptor) run(ctx context.Context) {
+	if p == nil {
+		return
+	}
+	ticker := time.NewTicker(preemptPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.check()
+		}
+	}
+}
+
+// check resumes the preempted tasks whose urgent task is done, then pauses
+// a task for the most urgent ready one if it's worth it
+func (p *preemptor) check() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id, pre := range p.paused {
+		if status, err := p.store.GetTaskStatus(pre.urgent); err == nil && waitingOrRunning(status) {
+			continue
+		}
+		p.resume(id)
+	}
+
+	// A free worker will claim the urgent task without any help
+	if len(p.running) < p.workers() {
+		return
+	}
+	ready, err := p.store.ReadyTasks(p.epicID)
+	if err != nil {
+		log.Printf("[preempt] warning: listing ready tasks: %v", err)
+		return
+	}
+	if len(ready) == 0 {
+		return
+	}
+	urgent := ready[0]
+	for _, pre := range p.paused {
+		if pre.urgent == urgent.ID {
+			return // Room was made already; a worker is about to claim it
+		}
+	}
+
+	var victim *types.Task
+	for id, task := range p.running {
+		if _, ok := p.paused[id]; ok {
+			continue
+		}
+		// Only a task past its start, so the worker's own status update can't undo the pause
+		if status, err := p.store.GetTaskStatus(id); err != nil || status != types.TaskStatusInProgress {
+			continue
+		}
+		if victim == nil || task.ClaimPriority() < victim.ClaimPriority() {
+			victim = task
+		}
+	}
+	if victim == nil || urgent.ClaimPriority()-victim.ClaimPriority() < p.gap {
+		return
+	}
+
+	if err := p.store.PauseTask(victim.ID); err != nil {
+		log.Printf("[preempt] warning: pausing %s: %v", victim.ID, err)
+		return
+	}
+	p.paused[victim.ID] = preemption{task: victim, urgent: urgent.ID}
+	log.Printf("⏸️  Preempting %s (p%d) for %s (p%d); it resumes once that is done",
+		victim.ID, victim.ClaimPriority(), urgent.ID, urgent.ClaimPriority())
+	_, _ = p.store.AddComment(victim.ID, "preemption", "Paused to make room for "+urgent.ID)
+	p.record(events.EventTaskPaused, victim.ID, victim.EpicID, map[string]any{"preempted_by": urgent.ID})
+}
+
+// waiting reports whether tasks are paused for urgent ones
+func (p *preemptor) waiting() bool {
+	if p == nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.paused) > 0
+}
+
+// resumeAll resumes every task still paused for an urgent one, so none is
+// left parked when the run stops
+func (p *preemptor) resumeAll() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id := range p.paused {
+		p.resume(id)
+	}
+}
+
+// resume returns a preempted task to the queue; its next run reuses the
+// worktree it was parked with. Called with p.mu held
+func (p *preemptor) resume(taskID string) {
+	pre := p.paused[taskID]
+	delete(p.paused, taskID)
+	// Only a task still parked: someone may have resumed or cancelled it meanwhile
+	if status, err := p.store.GetTaskStatus(taskID); err != nil || status != types.TaskStatusPaused {
+		return
+	}
+	if err := p.store.ResumeTask(taskID); err != nil {
+		log.Printf("[preempt] warning: resuming %s: %v", taskID, err)
+		return
+	}
+	// The agent starts afresh in the parked worktree; tell it what happened
+	_, _ = p.store.AddGuidance(taskID, "This task was paused to make room for an urgent one. "+
+		"The work done so far is still in this worktree, uncommitted; carry on from where it left off.")
+	log.Printf("▶️  Resuming preempted task %s", taskID)
+	p.record(events.EventTaskResumed, taskID, pre.task.EpicID, map[string]any{"preempted_by": pre.urgent})
+}
+
+// waitingOrRunning reports whether a task is still to run or running
+func waitingOrRunning(status types.TaskStatus) bool {
+	switch status {
+	case types.TaskStatusReady, types.TaskStatusClaimed, types.TaskStatusInProgress:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/events"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// oneWorker is the worker count of the runs the preemptor tests stand in for
+func oneWorker() int { return 1 }
+
+func TestPreemptor_PausesAndResumes(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	if newPreemptor(store, "", oneWorker, 0, nil) != nil {
+		t.Fatal("Expected no preemptor for a gap of 0")
+	}
+	var recorded []events.EventType
+	p := newPreemptor(store, "", oneWorker, 5, func(e events.EventType, _, _ string, _ map[string]any) {
+		recorded = append(recorded, e)
+	})
+
+	low, _ := store.CreateTask("Low", "", "", 1, nil)
+	claimed, err := store.ClaimTask("worker-0")
+	if err != nil || claimed == nil || claimed.ID != low.ID {
+		t.Fatalf("ClaimTask = %v, %v", claimed, err)
+	}
+	if err := store.UpdateTaskStatus(low.ID, types.TaskStatusInProgress, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	p.started(claimed)
+
+	// Not urgent enough
+	if _, err := store.CreateTask("Medium", "", "", 4, nil); err != nil {
+		t.Fatalf("CreateTask: %v", err)
+	}
+	p.check()
+	if status, _ := store.GetTaskStatus(low.ID); status != types.TaskStatusInProgress {
+		t.Fatalf("Low task is %s after a ready task 3 levels above it, want in_progress", status)
+	}
+
+	urgent, _ := store.CreateTask("Urgent", "", "", 10, nil)
+	p.check()
+	if status, _ := store.GetTaskStatus(low.ID); status != types.TaskStatusPaused {
+		t.Fatalf("Low task is %s after an urgent task arrived, want paused", status)
+	}
+	p.check() // Room was made already: nothing more is paused
+	if len(recorded) != 1 || recorded[0] != events.EventTaskPaused {
+		t.Errorf("Recorded events = %v, want one pause", recorded)
+	}
+
+	// The worker parks the task and claims the urgent one
+	p.finished(low.ID)
+	next, err := store.ClaimTask("worker-0")
+	if err != nil || next == nil || next.ID != urgent.ID {
+		t.Fatalf("ClaimTask = %v, %v; want the urgent task", next, err)
+	}
+	p.started(next)
+	p.check()
+	if status, _ := store.GetTaskStatus(low.ID); status != types.TaskStatusPaused {
+		t.Fatalf("Low task is %s while the urgent task runs, want paused", status)
+	}
+
+	if err := store.UpdateTaskStatus(urgent.ID, types.TaskStatusCompleted, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	p.finished(urgent.ID)
+	p.check()
+	if status, _ := store.GetTaskStatus(low.ID); status != types.TaskStatusReady {
+		t.Errorf("Low task is %s once the urgent task is done, want ready", status)
+	}
+	if guidance, _ := store.GetPendingGuidance(low.ID); len(guidance) != 1 {
+		t.Errorf("Resumed task has %d guidance message(s), want 1 explaining the pause", len(guidance))
+	}
+	if p.waiting() {
+		t.Error("Expected nothing left paused")
+	}
+}
+
+func TestPreemptor_QueuePositionAndResize(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	if err := store.MigrateSchema(); err != nil {
+		t.Fatalf("Failed to migrate schema: %v", err)
+	}
+	workers := 2
+	p := newPreemptor(store, "", func() int { return workers }, 5, func(events.EventType, string, string, map[string]any) {})
+
+	low, _ := store.CreateTask("Low", "", "", 1, nil)
+	claimed, err := store.ClaimTask("worker-0")
+	if err != nil || claimed == nil || claimed.ID != low.ID {
+		t.Fatalf("ClaimTask = %v, %v", claimed, err)
+	}
+	if err := store.UpdateTaskStatus(low.ID, types.TaskStatusInProgress, ""); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	p.started(claimed)
+
+	// A task pinned to the front of the queue is as urgent as its place there
+	pinned, _ := store.CreateTask("Pinned", "", "", 1, nil)
+	if err := store.MoveTask(pinned.ID, db.QueuePin); err != nil {
+		t.Fatalf("MoveTask: %v", err)
+	}
+	p.check()
+	if status, _ := store.GetTaskStatus(low.ID); status != types.TaskStatusInProgress {
+		t.Fatalf("Low task is %s with a worker free, want in_progress", status)
+	}
+
+	// The run is scaled down to one worker, which the task occupies
+	workers = 1
+	p.check()
+	if status, _ := store.GetTaskStatus(low.ID); status != types.TaskStatusPaused {
+		t.Fatalf("Low task is %s after a task was pinned, want paused", status)
+	}
+}
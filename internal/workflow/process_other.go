@@ -0,0 +1,13 @@
+//go:build !linux && !darwin
+
+package workflow
+
+import "os"
+
+// ProcessAlive reports whether a process exists. Where that can't be told
+// apart, the process is assumed alive: crash recovery leaves its tasks to
+// their leases and destructive commands need --force
+func ProcessAlive(pid int) bool {
+	_, err := os.FindProcess(pid)
+	return err == nil
+}
@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package workflow
+
+import (
+	"errors"
+	"syscall"
+)
+
+// ProcessAlive reports whether a process exists, by sending it signal 0
+func ProcessAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,27 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+)
+
+// promptTemplateDir is where prompt templates are kept, relative to the
+// project directory
+const promptTemplateDir = ".drover/prompts"
+
+// loadPromptTemplates loads and checks the project's prompt templates; nil
+// when it has none and agents use their built-in prompts
+func loadPromptTemplates(projectDir string) (*executor.PromptTemplates, error) {
+	dir := filepath.Join(projectDir, promptTemplateDir)
+	prompts, err := executor.LoadPromptTemplates(dir)
+	if err != nil {
+		return nil, fmt.Errorf("loading prompt templates: %w", err)
+	}
+	if prompts != nil {
+		log.Printf("📝 Prompt templates from %s: %v", promptTemplateDir, prompts.Names())
+	}
+	return prompts, nil
+}
@@ -0,0 +1,199 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/dbos-inc/dbos-transact-golang/dbos"
+)
+
+// queueLimitsPoll is how often a running orchestrator re-reads the limits
+// set with 'drover queue limits'
+const queueLimitsPoll = 5 * time.Second
+
+// queueSlotRetry is how long a task waiting for a queue slot sleeps between
+// checks
+const queueSlotRetry = 250 * time.Millisecond
+
+// queueLimitValues are the task queue's limits; 0 means no limit
+type queueLimitValues struct {
+	worker int // Tasks this orchestrator runs at once
+	global int // Tasks running at once across orchestrators
+	rate   int // Task starts per period
+	period time.Duration
+}
+
+func (v queueLimitValues) String() string {
+	show := func(n int) string {
+		if n == 0 {
+			return "unlimited"
+		}
+		return fmt.Sprint(n)
+	}
+	rate := "unlimited"
+	if v.rate > 0 {
+		rate = fmt.Sprintf("%d per %v", v.rate, v.period)
+	}
+	return fmt.Sprintf("worker concurrency %s, global concurrency %s, rate %s", show(v.worker), show(v.global), rate)
+}
+
+// with returns the limits with the ones set on the live deployment applied
+func (v queueLimitValues) with(live *db.QueueLimits) queueLimitValues {
+	if live == nil {
+		return v
+	}
+	if live.WorkerConcurrency != nil {
+		v.worker = *live.WorkerConcurrency
+	}
+	if live.GlobalConcurrency != nil {
+		v.global = *live.GlobalConcurrency
+	}
+	if live.RateLimit != nil {
+		v.rate = *live.RateLimit
+		if live.RatePeriod != nil {
+			v.period = *live.RatePeriod
+		}
+	}
+	return v
+}
+
+// queueOptions are the DBOS queue options for the limits the run started
+// with. DBOS enforces them as a ceiling: a live deployment can tighten the
+// limits below them, or relax them back up to them
+func (v queueLimitValues) queueOptions() []dbos.QueueOption {
+	var opts []dbos.QueueOption
+	if v.worker > 0 {
+		opts = append(opts, dbos.WithWorkerConcurrency(v.worker))
+	}
+	if v.global > 0 {
+		opts = append(opts, dbos.WithGlobalConcurrency(v.global))
+	}
+	if v.rate > 0 {
+		opts = append(opts, dbos.WithRateLimiter(&dbos.RateLimiter{Limit: v.rate, Period: v.period}))
+	}
+	return opts
+}
+
+// queueGate admits DBOS task workflows under the queue's current limits:
+// the ones the run started with, as changed on the live deployment
+type queueGate struct {
+	store   *db.Store
+	started queueLimitValues
+
+	mu      sync.Mutex
+	current queueLimitValues
+	readAt  time.Time   // When the live limits were last read
+	running int         // Tasks admitted and not yet released
+	starts  []time.Time // Admissions within the rate period
+}
+
+// newQueueGate creates the gate for the queue limits in cfg
+func newQueueGate(store *db.Store, cfg *config.Config) *queueGate {
+	started := queueLimitValues{
+		worker: cfg.QueueWorkerConcurrency,
+		global: cfg.QueueGlobalConcurrency,
+		rate:   cfg.QueueRateLimit,
+		period: cfg.QueueRatePeriod,
+	}
+	if started.rate > 0 && started.period <= 0 {
+		started.period = time.Minute
+	}
+	return &queueGate{store: store, started: started, current: started}
+}
+
+// refresh re-reads the limits set on the live deployment when they're due.
+// Caller must hold g.mu
+func (g *queueGate) refresh(now time.Time) {
+	if g.store == nil || now.Sub(g.readAt) < queueLimitsPoll {
+		return
+	}
+	g.readAt = now
+	live, err := g.store.GetQueueLimits()
+	if err != nil {
+		log.Printf("⚠️  Reading queue limits: %v", err)
+		return
+	}
+	if next := g.started.with(live); next != g.current {
+		g.current = next
+		log.Printf("🎚️  Queue limits changed: %s", next)
+	}
+}
+
+// blocked returns why a task can't start now, or "" when it can. Caller
+// must hold g.mu
+func (g *queueGate) blocked(now time.Time) string {
+	limits := g.current
+	if limits.worker > 0 && g.running >= limits.worker {
+		return fmt.Sprintf("%d task(s) running, worker concurrency %d", g.running, limits.worker)
+	}
+	if limits.rate > 0 {
+		recent := g.starts[:0]
+		for _, at := range g.starts {
+			if now.Sub(at) < limits.period {
+				recent = append(recent, at)
+			}
+		}
+		g.starts = recent
+		if len(g.starts) >= limits.rate {
+			return fmt.Sprintf("%d task(s) started in the last %v, rate limit %d", len(g.starts), limits.period, limits.rate)
+		}
+	}
+	if limits.global > 0 && g.store != nil {
+		running, err := g.store.CountRunning()
+		if err != nil {
+			log.Printf("⚠️  Counting running tasks: %v", err)
+			return ""
+		}
+		// Tasks admitted here may not be marked running yet
+		if g.running > running {
+			running = g.running
+		}
+		if running >= limits.global {
+			return fmt.Sprintf("%d task(s) running across orchestrators, global concurrency %d", running, limits.global)
+		}
+	}
+	return ""
+}
+
+// acquire waits until the task may start under the queue's limits, and
+// returns the function that gives its slot back
+func (g *queueGate) acquire(ctx context.Context, taskID string) (func(), error) {
+	logged := false
+	for {
+		now := time.Now()
+		g.mu.Lock()
+		g.refresh(now)
+		reason := g.blocked(now)
+		if reason == "" {
+			g.running++
+			if g.current.rate > 0 {
+				g.starts = append(g.starts, now)
+			}
+			g.mu.Unlock()
+			return g.release, nil
+		}
+		g.mu.Unlock()
+
+		if !logged {
+			log.Printf("⏳ Task %s waiting for a queue slot: %s", taskID, reason)
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(queueSlotRetry):
+		}
+	}
+}
+
+// release gives a task's slot back
+func (g *queueGate) release() {
+	g.mu.Lock()
+	g.running--
+	g.mu.Unlock()
+}
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/db"
+)
+
+func TestQueueGate(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	g := newQueueGate(store, &config.Config{QueueWorkerConcurrency: 2, QueueRateLimit: 3})
+	if g.started.period != time.Minute {
+		t.Errorf("Expected the rate period to default to a minute, got %v", g.started.period)
+	}
+	if opts := g.started.queueOptions(); len(opts) != 2 {
+		t.Errorf("Expected DBOS options for the worker and rate limits, got %d", len(opts))
+	}
+
+	now := time.Now()
+	g.mu.Lock()
+	g.refresh(now)
+	if reason := g.blocked(now); reason != "" {
+		t.Errorf("Expected a free slot, got %q", reason)
+	}
+	g.running = 2
+	if reason := g.blocked(now); reason == "" {
+		t.Error("Expected the worker limit to block a third task")
+	}
+	g.running = 0
+	g.starts = []time.Time{now.Add(-2 * time.Minute), now, now, now}
+	if reason := g.blocked(now); reason == "" {
+		t.Error("Expected the rate limit to block a fourth start within the period")
+	}
+	g.starts = g.starts[1:2]
+	g.mu.Unlock()
+
+	// Lifting the worker limit and setting a global one on the live deployment
+	worker, global := 0, 1
+	if err := store.SetQueueLimits(db.QueueLimits{WorkerConcurrency: &worker, GlobalConcurrency: &global}); err != nil {
+		t.Fatalf("SetQueueLimits: %v", err)
+	}
+	g.mu.Lock()
+	g.readAt = time.Time{}
+	g.refresh(now)
+	if g.current.worker != 0 || g.current.global != 1 || g.current.rate != 3 {
+		t.Errorf("Expected the live limits over the started ones, got %s", g.current)
+	}
+	g.mu.Unlock()
+
+	release, err := g.acquire(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*queueSlotRetry)
+	defer cancel()
+	if _, err := g.acquire(ctx, "task-2"); err == nil {
+		t.Error("Expected the global limit to hold a second task until its context ends")
+	}
+	release()
+	if release, err := g.acquire(context.Background(), "task-2"); err != nil {
+		t.Errorf("Expected a slot once the first task released its own, got %v", err)
+	} else {
+		release()
+	}
+}
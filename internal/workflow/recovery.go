@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// processStarted is when this drover process started. A checkpoint carrying
+// this process's PID but written before then is from an earlier process
+// that got the same PID, as restarted containers usually do
+var processStarted = time.Now()
+
+// defaultOrphanTimeout is how stale the heartbeat of a checkpoint without a
+// worker PID must be before its task is recovered, when no stall timeout is
+// configured
+const defaultOrphanTimeout = 2 * time.Minute
+
+// recoverOrphanedTasks takes back the tasks a crashed drover process left in
+// claimed or in_progress, so they don't wait for a manual reset. A task is
+// orphaned when the worker process named in its checkpoint is gone; tasks
+// whose worker is still alive, or that never got a checkpoint, are left to
+// their lease, which the lease reaper returns once it expires. An orphaned
+// task runs again unless it is out of attempts, and its worktree is kept so
+// the retry resumes the uncommitted work the crashed run left there
+func (o *Orchestrator) recoverOrphanedTasks() error {
+	stranded, err := o.store.StrandedTasks()
+	if err != nil {
+		return fmt.Errorf("finding stranded tasks: %w", err)
+	}
+
+	orphanTimeout := defaultOrphanTimeout
+	if o.config.StallTimeout > 0 {
+		orphanTimeout = o.config.StallTimeout
+	}
+
+	recovered := 0
+	for _, t := range stranded {
+		why, orphaned := orphanedBy(t.Checkpoint, orphanTimeout, time.Now())
+		if !orphaned {
+			if o.verbose {
+				log.Printf("[recovery] %s is %s by a live or unknown worker; leaving it to its lease", t.TaskID, t.Status)
+			}
+			continue
+		}
+
+		attempt := t.Attempts
+		if t.Checkpoint.Attempt > attempt {
+			attempt = t.Checkpoint.Attempt
+		}
+		to := types.TaskStatusReady
+		note := fmt.Sprintf("recovered after a crash: %s (attempt %d/%d)", why, attempt, t.MaxAttempts)
+		if attempt >= t.MaxAttempts {
+			to = types.TaskStatusFailed
+			note = fmt.Sprintf("crashed: %s, out of attempts (%d/%d)", why, attempt, t.MaxAttempts)
+		}
+
+		ok, err := o.store.RecoverTask(t.TaskID, to, attempt, note)
+		if err != nil {
+			log.Printf("[recovery] warning: recovering %s: %v", t.TaskID, err)
+			continue
+		}
+		if !ok {
+			continue // Finished while we looked
+		}
+		recovered++
+		log.Printf("[recovery] %s: %s, now %s", t.TaskID, why, to)
+		o.reattachWorktree(t, to)
+	}
+
+	if recovered > 0 {
+		log.Printf("[recovery] recovered %d task(s) from a previous crash", recovered)
+	} else if o.verbose {
+		log.Printf("[recovery] no orphaned tasks found")
+	}
+	return nil
+}
+
+// orphanedBy reports whether the worker that checkpointed a task is gone,
+// and why. A checkpoint without a PID falls back to its heartbeat
+func orphanedBy(checkpoint *types.TaskCheckpoint, timeout time.Duration, now time.Time) (string, bool) {
+	if checkpoint == nil {
+		return "", false
+	}
+	pid := checkpoint.WorkerPID
+	switch {
+	case pid <= 0:
+		if now.Sub(time.Unix(checkpoint.LastHeartbeat, 0)) > timeout {
+			return fmt.Sprintf("no heartbeat since %s", time.Unix(checkpoint.LastHeartbeat, 0).Format(time.RFC3339)), true
+		}
+		return "", false
+	case pid == os.Getpid():
+		if checkpoint.StartedAt < processStarted.Unix() {
+			return fmt.Sprintf("worker pid %d was an earlier drover process", pid), true
+		}
+		return "", false
+	case !ProcessAlive(pid):
+		return fmt.Sprintf("worker pid %d is gone", pid), true
+	}
+	return "", false
+}
+
+// reattachWorktree keeps a recovered task's surviving worktree for its retry,
+// which reuses it, or removes it once the task failed. Pooled worktrees are
+// recycled by the pool when it starts, so their work isn't resumed
+func (o *Orchestrator) reattachWorktree(t db.StrandedTask, to types.TaskStatus) {
+	if o.git == nil || o.pooled(t.Repo) {
+		return
+	}
+	gitMgr, err := o.worktreesFor(t.Repo)
+	if err != nil {
+		return
+	}
+	path, err := gitMgr.GetWorktreePath(t.TaskID)
+	if err != nil || path == "" {
+		return
+	}
+	if to != types.TaskStatusReady {
+		if err := gitMgr.Remove(t.TaskID); err != nil {
+			log.Printf("[recovery] warning: removing worktree of %s: %v", t.TaskID, err)
+		}
+		return
+	}
+	n, _ := gitMgr.UncommittedFiles(t.TaskID)
+	log.Printf("[recovery] ♻️  %s keeps its worktree at %s (%d uncommitted file(s)); the retry resumes there", t.TaskID, path, n)
+}
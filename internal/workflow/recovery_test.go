@@ -0,0 +1,47 @@
+package workflow
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestOrphanedBy(t *testing.T) {
+	now := time.Now()
+
+	if _, orphaned := orphanedBy(nil, time.Minute, now); orphaned {
+		t.Error("Expected a task without a checkpoint to be left to its lease")
+	}
+
+	// A worker that exited
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Skipf("running true: %v", err)
+	}
+	dead := &types.TaskCheckpoint{WorkerPID: exited.Process.Pid, StartedAt: now.Unix()}
+	if why, orphaned := orphanedBy(dead, time.Minute, now); !orphaned || why == "" {
+		t.Errorf("orphanedBy(exited worker) = %q, %v; want orphaned", why, orphaned)
+	}
+
+	// This process, before and after it started
+	ours := &types.TaskCheckpoint{WorkerPID: os.Getpid(), StartedAt: processStarted.Unix() - 60}
+	if _, orphaned := orphanedBy(ours, time.Minute, now); !orphaned {
+		t.Error("Expected a checkpoint from an earlier process with our PID to be orphaned")
+	}
+	ours.StartedAt = now.Unix()
+	if _, orphaned := orphanedBy(ours, time.Minute, now); orphaned {
+		t.Error("Expected a task this process is running not to be orphaned")
+	}
+
+	// No PID: the heartbeat decides
+	silent := &types.TaskCheckpoint{LastHeartbeat: now.Add(-5 * time.Minute).Unix()}
+	if _, orphaned := orphanedBy(silent, 10*time.Minute, now); orphaned {
+		t.Error("Expected a recent heartbeat to keep the task")
+	}
+	if _, orphaned := orphanedBy(silent, time.Minute, now); !orphaned {
+		t.Error("Expected a stale heartbeat to orphan the task")
+	}
+}
@@ -0,0 +1,23 @@
+package workflow
+
+import (
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/executor"
+)
+
+// remoteWorkers returns the hosts configured to run workers on over SSH, or
+// nil to run them locally
+func remoteWorkers(cfg *config.Config) *executor.RemoteWorkers {
+	var hosts []string
+	for _, host := range strings.Split(cfg.RemoteWorkers, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+	return executor.NewRemoteWorkers(hosts, cfg.RemoteWorkerDir, cfg.RemoteWorkerBinary)
+}
@@ -0,0 +1,384 @@
+package workflow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/notify"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// runReportDir is where each run writes its report, one directory per run,
+// relative to the project directory
+const runReportDir = ".drover/runs"
+
+// runReportIDLayout names run report directories after when the run started
+const runReportIDLayout = "20060102-150405"
+
+// How a run ended, as recorded in its report
+const (
+	RunFinished    = "finished"    // Nothing was left to run
+	RunDrained     = "drained"     // Stopped claiming, on a budget or deadline, and finished what was in flight
+	RunInterrupted = "interrupted" // Cancelled or signalled before it was done
+	RunParked      = "parked"      // Stopped claiming after consecutive tasks failed the same way
+)
+
+// RunReport is what one run did: written as report.json, with a markdown
+// rendering next to it, under .drover/runs/<id>/ when the run ends
+type RunReport struct {
+	ID           string       `json:"id"` // Start time, e.g. 20261016-142233
+	StartedAt    int64        `json:"started_at"`
+	EndedAt      int64        `json:"ended_at"`
+	Outcome      string       `json:"outcome"` // RunFinished, RunDrained, RunParked or RunInterrupted
+	Engine       string       `json:"engine"`  // sqlite or dbos
+	EpicID       string       `json:"epic_id,omitempty"`
+	Workers      int          `json:"workers"`
+	InputTokens  int64        `json:"input_tokens"`
+	OutputTokens int64        `json:"output_tokens"`
+	CostUSD      float64      `json:"cost_usd"`
+	Tasks        []TaskReport `json:"tasks"` // Every task attempted this run, in the order it started
+}
+
+// TaskReport is what became of one task in a run
+type TaskReport struct {
+	ID           string           `json:"id"`
+	Title        string           `json:"title"`
+	EpicID       string           `json:"epic_id,omitempty"`
+	Outcome      types.TaskStatus `json:"outcome"`  // Status the run left the task in
+	Attempts     int              `json:"attempts"` // Attempts started this run
+	DurationMS   int64            `json:"duration_ms"`
+	InputTokens  int64            `json:"input_tokens"`
+	OutputTokens int64            `json:"output_tokens"`
+	CostUSD      float64          `json:"cost_usd"`
+	Merge        string           `json:"merge,omitempty"` // merged, pushed, no changes, or why it failed
+	Error        string           `json:"error,omitempty"` // Last error of a task that didn't complete
+}
+
+// Duration returns how long the task's attempts ran this run
+func (t TaskReport) Duration() time.Duration {
+	return time.Duration(t.DurationMS) * time.Millisecond
+}
+
+// Usage returns what the task spent this run
+func (t TaskReport) Usage() db.Usage {
+	return db.Usage{InputTokens: t.InputTokens, OutputTokens: t.OutputTokens, CostUSD: t.CostUSD}
+}
+
+// Usage returns what the run spent
+func (r *RunReport) Usage() db.Usage {
+	return db.Usage{InputTokens: r.InputTokens, OutputTokens: r.OutputTokens, CostUSD: r.CostUSD}
+}
+
+// Duration returns how long the run took
+func (r *RunReport) Duration() time.Duration {
+	return time.Duration(r.EndedAt-r.StartedAt) * time.Second
+}
+
+// Count returns how many of the run's tasks it left in status
+func (r *RunReport) Count(status types.TaskStatus) int {
+	n := 0
+	for _, t := range r.Tasks {
+		if t.Outcome == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Markdown renders the report for people
+func (r *RunReport) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Drover run %s\n\n", r.ID)
+	fmt.Fprintf(&b, "- **Outcome:** %s\n", r.Outcome)
+	fmt.Fprintf(&b, "- **Started:** %s\n", time.Unix(r.StartedAt, 0).Format(time.RFC3339))
+	fmt.Fprintf(&b, "- **Duration:** %s\n", r.Duration())
+	fmt.Fprintf(&b, "- **Engine:** %s, %d worker(s)\n", r.Engine, r.Workers)
+	if r.EpicID != "" {
+		fmt.Fprintf(&b, "- **Epic:** %s\n", r.EpicID)
+	}
+	fmt.Fprintf(&b, "- **Tasks:** %d run, %d completed, %d failed\n",
+		len(r.Tasks), r.Count(types.TaskStatusCompleted), r.Count(types.TaskStatusFailed))
+	if usage := r.Usage(); !usage.IsZero() {
+		fmt.Fprintf(&b, "- **Spent:** %s\n", usage)
+	}
+
+	if len(r.Tasks) == 0 {
+		b.WriteString("\nNo task ran.\n")
+		return b.String()
+	}
+
+	b.WriteString("\n## Tasks\n\n")
+	b.WriteString("| Task | Title | Outcome | Attempts | Duration | Cost | Merge |\n")
+	b.WriteString("|------|-------|---------|----------|----------|------|-------|\n")
+	for _, t := range r.Tasks {
+		cost := "-"
+		if usage := t.Usage(); !usage.IsZero() {
+			cost = fmt.Sprintf("$%.2f", t.CostUSD)
+		}
+		merge := t.Merge
+		if merge == "" {
+			merge = "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %d | %s | %s | %s |\n", t.ID, markdownCell(t.Title),
+			t.Outcome, t.Attempts, t.Duration().Round(time.Second), cost, markdownCell(merge))
+	}
+
+	var failures []TaskReport
+	for _, t := range r.Tasks {
+		if t.Error != "" {
+			failures = append(failures, t)
+		}
+	}
+	if len(failures) > 0 {
+		b.WriteString("\n## Failures\n")
+		for _, t := range failures {
+			fmt.Fprintf(&b, "\n### %s: %s\n\n```\n%s\n```\n", t.ID, t.Title, strings.TrimRight(t.Error, "\n"))
+		}
+	}
+	return b.String()
+}
+
+// markdownCell keeps text from breaking out of a table cell
+func markdownCell(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// runReporter collects what the database doesn't keep about a run, the
+// merge results of its tasks, and writes the run's report when it ends
+type runReporter struct {
+	started time.Time
+
+	mu     sync.Mutex
+	merges map[string]string // Merge results by task ID
+}
+
+func newRunReporter() *runReporter {
+	return &runReporter{started: time.Now(), merges: make(map[string]string)}
+}
+
+// merged records what merging or pushing a task's changes came to
+func (r *runReporter) merged(taskID, result string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.merges[taskID] = result
+}
+
+// mergeResult describes the outcome of merging (or, in PR mode, pushing) a
+// task's changes for its report
+func mergeResult(action string, hasChanges bool, err error) string {
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s failed: %v", action, err)
+	case !hasChanges:
+		return "no changes"
+	case action == "push":
+		return "pushed"
+	}
+	return "merged"
+}
+
+// build assembles the run's report from the attempts started since it
+// began; usage is nil when the run didn't track it
+func (r *runReporter) build(store *db.Store, usage *runUsage, engine, epicID string, workers int, outcome string) (*RunReport, error) {
+	now := time.Now()
+	report := &RunReport{
+		ID:        r.started.Format(runReportIDLayout),
+		StartedAt: r.started.Unix(),
+		EndedAt:   now.Unix(),
+		Outcome:   outcome,
+		Engine:    engine,
+		EpicID:    epicID,
+		Workers:   workers,
+		Tasks:     []TaskReport{},
+	}
+	if usage != nil {
+		total := usage.Total()
+		report.InputTokens, report.OutputTokens, report.CostUSD = total.InputTokens, total.OutputTokens, total.CostUSD
+	}
+
+	attempts, err := store.ListAttemptsSince(r.started.Unix())
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int)
+	for _, a := range attempts {
+		i, ok := index[a.TaskID]
+		if !ok {
+			i = len(report.Tasks)
+			index[a.TaskID] = i
+			report.Tasks = append(report.Tasks, TaskReport{ID: a.TaskID})
+		}
+		t := &report.Tasks[i]
+		t.Attempts++
+		ended := now.Unix()
+		if a.EndedAt != nil {
+			ended = *a.EndedAt
+		}
+		t.DurationMS += (ended - a.StartedAt) * 1000
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range report.Tasks {
+		t := &report.Tasks[i]
+		task, err := store.GetTask(t.ID)
+		if err != nil {
+			return nil, err
+		}
+		t.Title, t.EpicID, t.Outcome = task.Title, task.EpicID, task.Status
+		if task.Status != types.TaskStatusCompleted {
+			t.Error = task.LastError
+		}
+		if usage != nil {
+			spent := usage.task(t.ID)
+			t.InputTokens, t.OutputTokens, t.CostUSD = spent.InputTokens, spent.OutputTokens, spent.CostUSD
+		}
+		t.Merge = r.merges[t.ID]
+	}
+	return report, nil
+}
+
+// write builds the run's report and saves it under the project directory,
+// logging rather than failing: the run is over either way. Returns the
+// report, or nil when it couldn't be built
+func (r *runReporter) write(store *db.Store, projectDir string, usage *runUsage, engine, epicID string, workers int, outcome string) *RunReport {
+	report, err := r.build(store, usage, engine, epicID, workers, outcome)
+	if err != nil {
+		log.Printf("⚠️  Writing run report: %v", err)
+		return nil
+	}
+	dir, err := SaveRunReport(projectDir, report)
+	if err != nil {
+		log.Printf("⚠️  Writing run report: %v", err)
+		return report
+	}
+	log.Printf("📝 Run report: %s", filepath.Join(dir, "report.md"))
+	return report
+}
+
+// Summary condenses the report for a run-end notification
+func (r *RunReport) Summary() notify.RunSummary {
+	summary := notify.RunSummary{
+		ID:        r.ID,
+		Outcome:   r.Outcome,
+		EpicID:    r.EpicID,
+		Duration:  r.Duration(),
+		Total:     len(r.Tasks),
+		Completed: r.Count(types.TaskStatusCompleted),
+		Failed:    r.Count(types.TaskStatusFailed),
+		CostUSD:   r.CostUSD,
+	}
+	for _, t := range r.Tasks {
+		if t.Outcome == types.TaskStatusFailed {
+			summary.Failures = append(summary.Failures, notify.TaskFailure{ID: t.ID, Title: t.Title, Error: t.Error})
+		}
+	}
+	return summary
+}
+
+// notifyTaskFailed posts the failure alert for a task that failed for good,
+// from the data of its task.failed event. The task's secret environment
+// values are redacted from the error first, as the alert leaves the host
+func notifyTaskFailed(n *notify.Notifier, store *db.Store, projectEnv map[string]string, taskID string, data map[string]any) {
+	if n == nil {
+		return
+	}
+	var title string
+	if task, err := store.GetTask(taskID); err == nil {
+		title = task.Title
+	}
+	errMsg, _ := data["error"].(string)
+	errMsg = redactSecrets(errMsg, loadAgentEnv(store, projectEnv, taskID).secrets())
+	n.TaskFailed(taskID, title, errMsg)
+}
+
+// SaveRunReport writes a report as report.json and report.md in its own
+// directory under .drover/runs, returning the directory. A run starting in
+// the same second as an earlier one gets a suffixed ID
+func SaveRunReport(projectDir string, report *RunReport) (string, error) {
+	root := filepath.Join(projectDir, runReportDir)
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return "", fmt.Errorf("creating run report directory: %w", err)
+	}
+	base := report.ID
+	dir := filepath.Join(root, report.ID)
+	for n := 2; ; n++ {
+		err := os.Mkdir(dir, 0o755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("creating run report directory: %w", err)
+		}
+		report.ID = fmt.Sprintf("%s-%d", base, n)
+		dir = filepath.Join(root, report.ID)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encoding run report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.json"), append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("writing run report: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "report.md"), []byte(report.Markdown()), 0o644); err != nil {
+		return "", fmt.Errorf("writing run report: %w", err)
+	}
+	return dir, nil
+}
+
+// LoadRunReport reads the report of the run with the given ID
+func LoadRunReport(projectDir, id string) (*RunReport, error) {
+	data, err := os.ReadFile(filepath.Join(projectDir, runReportDir, id, "report.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("run not found: %s", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading run report: %w", err)
+	}
+	var report RunReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("parsing run report %s: %w", id, err)
+	}
+	return &report, nil
+}
+
+// LoadRunReports reads every run's report, newest first. Directories
+// without a readable report are skipped
+func LoadRunReports(projectDir string) ([]*RunReport, error) {
+	entries, err := os.ReadDir(filepath.Join(projectDir, runReportDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing run reports: %w", err)
+	}
+	var reports []*RunReport
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		report, err := LoadRunReport(projectDir, entry.Name())
+		if err != nil {
+			continue
+		}
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].StartedAt != reports[j].StartedAt {
+			return reports[i].StartedAt > reports[j].StartedAt
+		}
+		return reports[i].ID > reports[j].ID
+	})
+	return reports, nil
+}
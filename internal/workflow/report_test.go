@@ -0,0 +1,146 @@
+package workflow
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/notify"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestRunReporter_WritesAndLoadsReport(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	// An attempt from before the run isn't part of its report
+	earlier, _ := store.CreateTask("Earlier", "", "", 0, nil)
+	if _, err := store.DB.Exec(`INSERT INTO task_attempts (task_id, number, worker_id, started_at) VALUES (?, 1, 'w', ?)`,
+		earlier.ID, time.Now().Add(-time.Hour).Unix()); err != nil {
+		t.Fatalf("inserting old attempt: %v", err)
+	}
+
+	reporter := newRunReporter()
+	var usage runUsage
+
+	done, _ := store.CreateTask("Done", "", "", 0, nil)
+	broken, _ := store.CreateTask("Broken | piped", "", "", 0, nil)
+	for _, task := range []*types.Task{done, broken} {
+		attempt, err := store.StartAttempt(task.ID, "worker-0")
+		if err != nil {
+			t.Fatalf("StartAttempt: %v", err)
+		}
+		if err := store.FinishAttempt(attempt.ID, "", ""); err != nil {
+			t.Fatalf("FinishAttempt: %v", err)
+		}
+	}
+	usage.record(store, done, &executor.ExecutionResult{InputTokens: 1000, OutputTokens: 200, CostUSD: 0.5})
+	if err := store.CompleteTask(done.ID); err != nil {
+		t.Fatalf("CompleteTask: %v", err)
+	}
+	reporter.merged(done.ID, mergeResult("merge", true, nil))
+	if err := store.UpdateTaskStatus(broken.ID, types.TaskStatusFailed, "tests failed\nsecond line"); err != nil {
+		t.Fatalf("UpdateTaskStatus: %v", err)
+	}
+	reporter.merged(broken.ID, mergeResult("merge", true, errors.New("conflict")))
+
+	projectDir := t.TempDir()
+	reporter.write(store, projectDir, &usage, "sqlite", "", 2, RunFinished)
+	reporter.write(store, projectDir, &usage, "sqlite", "", 2, RunInterrupted) // Same second: a suffixed ID
+
+	reports, err := LoadRunReports(projectDir)
+	if err != nil {
+		t.Fatalf("LoadRunReports: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("Expected 2 reports, got %d", len(reports))
+	}
+	if reports[0].ID != reports[1].ID+"-2" || reports[0].Outcome != RunInterrupted {
+		t.Errorf("Expected the second report newest with a suffixed ID, got %s (%s) then %s",
+			reports[0].ID, reports[0].Outcome, reports[1].ID)
+	}
+
+	report, err := LoadRunReport(projectDir, reports[1].ID)
+	if err != nil {
+		t.Fatalf("LoadRunReport: %v", err)
+	}
+	if len(report.Tasks) != 2 {
+		t.Fatalf("Expected the run's 2 tasks, got %+v", report.Tasks)
+	}
+	got := report.Tasks[0]
+	if got.ID != done.ID || got.Outcome != types.TaskStatusCompleted || got.Attempts != 1 ||
+		got.CostUSD != 0.5 || got.Merge != "merged" || got.Error != "" {
+		t.Errorf("Unexpected report of the completed task: %+v", got)
+	}
+	got = report.Tasks[1]
+	if got.Outcome != types.TaskStatusFailed || got.Merge != "merge failed: conflict" || got.Error != "tests failed\nsecond line" {
+		t.Errorf("Unexpected report of the failed task: %+v", got)
+	}
+	if report.CostUSD != 0.5 || report.Count(types.TaskStatusCompleted) != 1 || report.Count(types.TaskStatusFailed) != 1 {
+		t.Errorf("Unexpected run totals: %+v", report)
+	}
+
+	summary := report.Summary()
+	if summary.Total != 2 || summary.Completed != 1 || summary.Failed != 1 || summary.CostUSD != 0.5 ||
+		len(summary.Failures) != 1 || summary.Failures[0].ID != broken.ID || summary.Failures[0].Error != "tests failed\nsecond line" {
+		t.Errorf("Unexpected run summary: %+v", summary)
+	}
+
+	md := report.Markdown()
+	for _, want := range []string{"# Drover run " + report.ID, "**Outcome:** finished", "Broken \\| piped",
+		"$0.50", "## Failures", "tests failed\nsecond line"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown is missing %q:\n%s", want, md)
+		}
+	}
+
+	if _, err := LoadRunReport(projectDir, "missing"); err == nil {
+		t.Error("Expected an error for an unknown run")
+	}
+}
+
+func TestNotifyTaskFailed_RedactsSecrets(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	task, _ := store.CreateTask("Deploy", "", "", 0, nil)
+	store.SetTaskEnv(task.ID, map[string]string{"DB_PASSWORD": "hunter2"})
+
+	posted := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted <- string(body)
+	}))
+	defer server.Close()
+
+	n := notify.New(notify.Config{SlackWebhookURL: server.URL})
+	notifyTaskFailed(n, store, map[string]string{"API_TOKEN": "tok-1234"}, task.ID, map[string]any{
+		"error": "login with hunter2 and tok-1234 refused",
+	})
+	select {
+	case body := <-posted:
+		if strings.Contains(body, "hunter2") || strings.Contains(body, "tok-1234") || !strings.Contains(body, redactedValue) {
+			t.Errorf("posted %s, want the secrets redacted", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the failure alert to be posted")
+	}
+}
@@ -0,0 +1,122 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/git"
+	"github.com/cloud-shuttle/drover/internal/project"
+)
+
+// newRepoManager puts the project's worktree manager together with one for
+// each repository under [repos] in .drover.toml. The others are set up like
+// the project's own, keep their worktrees in <worktree dir>-<name> and merge
+// into their own target branch. The worktree pool only serves the project's
+// own repository
+func newRepoManager(cfg *config.Config, projectCfg *project.Config, projectDir string,
+	primary *git.WorktreeManager, store *db.Store, observe git.WaitObserver) (*git.RepoManager, error) {
+	repos := git.NewRepoManager(primary)
+
+	names := make([]string, 0, len(projectCfg.Repos))
+	for name := range projectCfg.Repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	isolationMode, err := git.ParseIsolationMode(cfg.IsolationMode)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		path, err := projectCfg.RepoPath(projectDir, name)
+		if err != nil {
+			return nil, err
+		}
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("repos.%s: %s is not a directory", name, path)
+		}
+
+		wm := git.NewWorktreeManager(path, filepath.Join(projectDir, cfg.WorktreeDir+"-"+name))
+		wm.SetVerbose(cfg.Verbose)
+		wm.SetIsolationMode(isolationMode)
+		wm.SetBranchTemplate(cfg.BranchPrefix, cfg.BranchTemplate)
+		wm.SetNetworkTimeout(cfg.GitNetworkTimeout)
+		wm.SetWaitObserver(observe)
+		if protected := projectCfg.GetProtectedPaths(cfg.ProtectedPaths); len(protected) > 0 {
+			wm.SetProtectedPaths(protected)
+		}
+		if cfg.CommitAttribution {
+			wm.SetCommitAuthor(git.RenderCommitAuthor(cfg.CommitAuthorName, cfg.CommitAuthorEmail, projectCfg.Agent, cfg.AgentModel))
+		}
+		if store != nil {
+			wm.SetRecorder(store)
+		}
+
+		override := projectCfg.Repos[name].TargetBranch
+		target, err := git.ResolveTargetBranch(path, override, cfg.BranchPrefix)
+		if err != nil {
+			if override != "" {
+				return nil, fmt.Errorf("repos.%s: validating target branch: %w", name, err)
+			}
+			log.Printf("[git] warning: repos.%s: %v; merging into main", name, err)
+		} else {
+			wm.SetTargetBranch(target)
+		}
+
+		if err := repos.Add(name, wm); err != nil {
+			return nil, err
+		}
+		log.Printf("[repos] %s: %s, merging into %s", name, path, wm.TargetBranch())
+	}
+	return repos, nil
+}
+
+// worktreesFor returns the worktree manager of the repository a task works
+// in, by its name; "" is the project's own
+func (o *Orchestrator) worktreesFor(repo string) (*git.WorktreeManager, error) {
+	if o.repos == nil {
+		if repo != "" {
+			return nil, fmt.Errorf("unknown repository %q (configure it under [repos] in .drover.toml)", repo)
+		}
+		return o.git, nil
+	}
+	return o.repos.Get(repo)
+}
+
+// pooled reports whether tasks in a repository take their worktrees from the
+// pool, which only serves the project's own
+func (o *Orchestrator) pooled(repo string) bool {
+	return repo == "" && o.pool != nil && o.pool.IsEnabled()
+}
+
+// cleanupWorktrees removes the drover worktrees of every repository
+func (o *Orchestrator) cleanupWorktrees() {
+	if o.repos == nil {
+		_ = o.git.Cleanup()
+		return
+	}
+	_ = o.repos.Cleanup()
+}
+
+// worktreesFor returns the worktree manager of the repository a task works
+// in, by its name; "" is the project's own
+func (o *DBOSOrchestrator) worktreesFor(repo string) (*git.WorktreeManager, error) {
+	if o.repos == nil {
+		if repo != "" {
+			return nil, fmt.Errorf("unknown repository %q (configure it under [repos] in .drover.toml)", repo)
+		}
+		return o.git, nil
+	}
+	return o.repos.Get(repo)
+}
+
+// pooled reports whether tasks in a repository take their worktrees from the
+// pool, which only serves the project's own
+func (o *DBOSOrchestrator) pooled(repo string) bool {
+	return repo == "" && o.pool != nil && o.pool.IsEnabled()
+}
@@ -0,0 +1,218 @@
+package workflow
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/config"
+	"github.com/cloud-shuttle/drover/internal/project"
+	"github.com/cloud-shuttle/drover/internal/worker"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// FailureClass is the kind of error an attempt failed with, which a retry
+// policy can choose to retry or not
+type FailureClass string
+
+const (
+	FailureWorktree  FailureClass = "worktree"   // Creating or acquiring the worktree
+	FailureAgent     FailureClass = "agent"      // The agent exited with an error
+	FailureTimeout   FailureClass = "timeout"    // The agent ran out of time
+	FailureRateLimit FailureClass = "rate_limit" // The agent was rate limited
+	FailureGit       FailureClass = "git"        // Committing the task's work
+	FailureDoD       FailureClass = "dod"        // The definition of done wasn't met
+	FailureTests     FailureClass = "tests"      // The automated tests failed
+	FailureVerdict   FailureClass = "verdict"    // The agent reported the task failed
+	FailureVerify    FailureClass = "verify"     // A verification command failed
+)
+
+// failureClasses lists every class, for validating retry_on
+var failureClasses = []FailureClass{
+	FailureWorktree, FailureAgent, FailureTimeout, FailureRateLimit,
+	FailureGit, FailureDoD, FailureTests, FailureVerdict, FailureVerify,
+}
+
+// RetryPolicy decides whether a failed attempt is retried and how long the
+// task waits before it may be claimed again. The delay before retry n is
+// Backoff × Multiplier^(n-1), capped at MaxBackoff, with up to Jitter of it
+// randomized either way so failed tasks don't all come back at once
+type RetryPolicy struct {
+	Backoff    time.Duration  // Delay before the first retry; 0 retries at once
+	MaxBackoff time.Duration  // Longest delay, however many attempts failed (0 = no limit)
+	Multiplier float64        // How much the delay grows per failed attempt
+	Jitter     float64        // Fraction of the delay randomized, 0 to 1
+	RetryOn    []FailureClass // Failure classes retried; empty retries every class
+}
+
+// DefaultRetryPolicy is the policy used when none is configured
+var DefaultRetryPolicy = RetryPolicy{
+	Backoff:    30 * time.Second,
+	MaxBackoff: 10 * time.Minute,
+	Multiplier: 2,
+	Jitter:     0.2,
+}
+
+// ParseRetryPolicy applies a retry policy spec on top of base. A spec is
+// comma-separated settings, each optional:
+//
+//	backoff=30s,max=10m,factor=2,jitter=0.2,on=agent|timeout|rate_limit
+//
+// "on=all" retries every failure class. An empty spec returns base
+func ParseRetryPolicy(spec string, base RetryPolicy) (RetryPolicy, error) {
+	p := base
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return base, fmt.Errorf("retry policy setting %q is not key=value", field)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "backoff":
+			p.Backoff, err = parsePolicyDuration(value)
+		case "max":
+			p.MaxBackoff, err = parsePolicyDuration(value)
+		case "factor":
+			p.Multiplier, err = strconv.ParseFloat(value, 64)
+			if err == nil && p.Multiplier < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		case "jitter":
+			p.Jitter, err = strconv.ParseFloat(value, 64)
+			if err == nil && (p.Jitter < 0 || p.Jitter > 1) {
+				err = fmt.Errorf("must be between 0 and 1")
+			}
+		case "on":
+			p.RetryOn, err = parseFailureClasses(value)
+		default:
+			return base, fmt.Errorf("unknown retry policy setting %q (want backoff, max, factor, jitter or on)", key)
+		}
+		if err != nil {
+			return base, fmt.Errorf("retry policy %s=%s: %w", key, value, err)
+		}
+	}
+	return p, nil
+}
+
+// parsePolicyDuration parses a non-negative duration
+func parsePolicyDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	return d, nil
+}
+
+// parseFailureClasses parses a |-separated list of failure classes
+func parseFailureClasses(value string) ([]FailureClass, error) {
+	if value == "all" {
+		return nil, nil
+	}
+	var classes []FailureClass
+	for _, name := range strings.Split(value, "|") {
+		class := FailureClass(strings.TrimSpace(name))
+		known := false
+		for _, c := range failureClasses {
+			known = known || c == class
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown failure class %q (want all or some of %v)", class, failureClasses)
+		}
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// Retries reports whether the policy retries failures of the given class
+func (p RetryPolicy) Retries(class FailureClass) bool {
+	if len(p.RetryOn) == 0 {
+		return true
+	}
+	for _, c := range p.RetryOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// Delay returns how long to wait before retry n (1 for the first retry).
+// rnd draws from [0, 1) for the jitter
+func (p RetryPolicy) Delay(n int, rnd func() float64) time.Duration {
+	if p.Backoff <= 0 {
+		return 0
+	}
+	delay := float64(p.Backoff) * math.Pow(max(p.Multiplier, 1), float64(max(n-1, 0)))
+	if p.MaxBackoff > 0 {
+		delay = min(delay, float64(p.MaxBackoff))
+	}
+	if p.Jitter > 0 && rnd != nil {
+		delay *= 1 + p.Jitter*(2*rnd()-1)
+	}
+	return time.Duration(delay).Round(time.Second)
+}
+
+// String returns the policy as a spec ParseRetryPolicy accepts
+func (p RetryPolicy) String() string {
+	on := "all"
+	if len(p.RetryOn) > 0 {
+		names := make([]string, len(p.RetryOn))
+		for i, c := range p.RetryOn {
+			names[i] = string(c)
+		}
+		on = strings.Join(names, "|")
+	}
+	return fmt.Sprintf("backoff=%s,max=%s,factor=%g,jitter=%g,on=%s", p.Backoff, p.MaxBackoff, p.Multiplier, p.Jitter, on)
+}
+
+// taskRetryPolicy is the global policy with the task's own overrides applied.
+// Overrides that don't parse are ignored
+func taskRetryPolicy(global RetryPolicy, task *types.Task) RetryPolicy {
+	if task == nil || task.RetryPolicy == "" {
+		return global
+	}
+	p, err := ParseRetryPolicy(task.RetryPolicy, global)
+	if err != nil {
+		return global
+	}
+	return p
+}
+
+// configuredRetryPolicy is the default policy with the run's retry_policy
+// applied: --retry-policy or DROVER_RETRY_POLICY, else .drover.toml
+func configuredRetryPolicy(cfg *config.Config, projectCfg *project.Config) (RetryPolicy, error) {
+	spec := cfg.RetryPolicy
+	if spec == "" {
+		spec = projectCfg.RetryPolicy
+	}
+	return ParseRetryPolicy(spec, DefaultRetryPolicy)
+}
+
+// classifyAgentFailure tells a rate limit or timeout apart from any other
+// failed agent execution
+func classifyAgentFailure(signal worker.WorkerSignal, err error) FailureClass {
+	if signal == worker.SignalRateLimited {
+		return FailureRateLimit
+	}
+	if err != nil {
+		msg := strings.ToLower(err.Error())
+		switch {
+		case strings.Contains(msg, "timed out") || strings.Contains(msg, "deadline exceeded"):
+			return FailureTimeout
+		case strings.Contains(msg, "rate limit") || strings.Contains(msg, "429"):
+			return FailureRateLimit
+		}
+	}
+	return FailureAgent
+}
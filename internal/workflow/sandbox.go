@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"log"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/project"
+)
+
+// newSandbox creates the sandbox configured under [sandbox] in .drover.toml;
+// nil when agents run on the host
+func newSandbox(cfg project.SandboxConfig, projectDir string) (executor.Sandbox, error) {
+	switch cfg.Type {
+	case "docker":
+		sandbox, err := executor.NewDockerSandbox(executor.DockerSandboxConfig{
+			Image:     cfg.Image,
+			Mounts:    cfg.Mounts,
+			Env:       cfg.Env,
+			CPUs:      cfg.CPUs,
+			Memory:    cfg.Memory,
+			NoNetwork: cfg.NoNetwork,
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("📦 Agents run in docker containers of %s", cfg.Image)
+		return sandbox, nil
+	case "bwrap":
+		policy, err := cfg.LoadPolicy(projectDir)
+		if err != nil {
+			return nil, err
+		}
+		sandbox, err := executor.NewBwrapSandbox(executor.BwrapSandboxConfig{
+			ReadOnly:  policy.ReadOnly,
+			ReadWrite: policy.ReadWrite,
+			Env:       append(cfg.Env, policy.Env...),
+			NoNetwork: cfg.NoNetwork || policy.NoNetwork,
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("📦 Agents run under bubblewrap")
+		return sandbox, nil
+	}
+	return nil, nil
+}
+
+// cpuLimit returns the CPU limit the project sets for agents' processes
+func cpuLimit(projectCfg *project.Config) executor.CPULimit {
+	return executor.CPULimit{
+		Nice:     projectCfg.CPU.Nice,
+		MaxProcs: projectCfg.CPU.MaxProcs,
+		CPUs:     projectCfg.CPU.CPUs,
+		Cgroup:   projectCfg.CPU.Cgroup,
+	}
+}
@@ -2,11 +2,17 @@
 package workflow
 
 import (
+	"context"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
+	"github.com/cloud-shuttle/drover/internal/config"
 	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/git"
 	"github.com/cloud-shuttle/drover/pkg/types"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TestSubTaskExecutionFlow tests that sub-tasks execute when parent runs
@@ -194,3 +200,67 @@ func TestSubTaskSequencing(t *testing.T) {
 		t.Errorf("Expected ID %s.20, got %s", parent.ID, s20.ID)
 	}
 }
+
+// orderAgent records which tasks it ran, in order
+type orderAgent struct {
+	fixingAgent
+	ran []string
+}
+
+func (a *orderAgent) ExecuteWithContext(ctx context.Context, worktreePath string, task *types.Task, parentSpan ...trace.Span) *executor.ExecutionResult {
+	a.ran = append(a.ran, task.ID)
+	return &executor.ExecutionResult{Success: true}
+}
+
+// TestExecuteSubTasksResumes tests that sub-tasks run in sequence order and
+// that those completed by an earlier attempt are not run again
+func TestExecuteSubTasksResumes(t *testing.T) {
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-b", "main"},
+		{"-c", "user.email=t@example.com", "-c", "user.name=t", "commit", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	parent, _ := store.CreateTask("Parent task", "", "", 0, nil)
+	sub1, _ := store.CreateSubTask("Sub task 1", "", parent.ID, 0, nil)
+	sub2, _ := store.CreateSubTask("Sub task 2", "", parent.ID, 0, nil)
+	sub3, _ := store.CreateSubTask("Sub task 3", "", parent.ID, 0, nil)
+	if err := store.CompleteTask(sub1.ID); err != nil {
+		t.Fatalf("CompleteTask failed: %v", err)
+	}
+
+	agent := &orderAgent{}
+	o := &Orchestrator{
+		config: &config.Config{},
+		store:  store,
+		git:    git.NewWorktreeManager(repo, filepath.Join(repo, ".drover", "worktrees")),
+		agent:  agent,
+	}
+	if !o.executeSubTasks(context.Background(), 1, parent) {
+		t.Fatal("Expected sub-tasks to succeed")
+	}
+
+	if len(agent.ran) != 2 || agent.ran[0] != sub2.ID || agent.ran[1] != sub3.ID {
+		t.Errorf("Expected only %s then %s to run, got %v", sub2.ID, sub3.ID, agent.ran)
+	}
+	for _, id := range []string{sub2.ID, sub3.ID} {
+		if status, _ := store.GetTaskStatus(id); status != types.TaskStatusCompleted {
+			t.Errorf("Expected sub-task %s completed, got %s", id, status)
+		}
+	}
+}
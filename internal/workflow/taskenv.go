@@ -0,0 +1,154 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/internal/git"
+)
+
+// redactedValue stands in for a secret in logs and transcripts
+const redactedValue = executor.RedactedValue
+
+// minSecretLen is the shortest secret scrubbed from agent output; shorter
+// values would mangle unrelated text
+const minSecretLen = 4
+
+// secretEnvName matches the names of variables that usually hold secrets
+var secretEnvName = regexp.MustCompile(`(?i)(secret|token|passw(or)?d|pwd|credential|private|api_?key|access_?key|auth)`)
+
+// secretEnvValue matches values shaped like well-known API keys
+var secretEnvValue = regexp.MustCompile(`^(sk-|ghp_|gho_|github_pat_|xox[abpr]-|AKIA)`)
+
+// envNamePattern is what an environment variable name may look like
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseEnv parses KEY=VALUE entries, e.g. from --env flags
+func ParseEnv(entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	env := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || !envNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable %q (want KEY=VALUE)", entry)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// envSecret returns the part of a variable's value to keep out of logs: all
+// of it for secret-looking names and values, the password of a URL with
+// credentials, or "" when nothing is secret
+func envSecret(name, value string) string {
+	if value == "" {
+		return ""
+	}
+	if secretEnvName.MatchString(name) || secretEnvValue.MatchString(value) {
+		return value
+	}
+	if u, err := url.Parse(value); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			return password
+		}
+	}
+	return ""
+}
+
+// RedactEnvValue returns a variable's value as it may be shown in logs
+func RedactEnvValue(name, value string) string {
+	secret := envSecret(name, value)
+	if secret == "" {
+		return value
+	}
+	return strings.ReplaceAll(value, secret, redactedValue)
+}
+
+// agentEnv is the environment a task's agent runs with on top of drover's
+// own
+type agentEnv map[string]string
+
+// loadAgentEnv merges the project's [env] defaults with the env of each task
+// in taskIDs, later ones overriding earlier ones
+func loadAgentEnv(store *db.Store, projectEnv map[string]string, taskIDs ...string) agentEnv {
+	env := make(agentEnv, len(projectEnv))
+	for name, value := range projectEnv {
+		env[name] = value
+	}
+	if store == nil {
+		return env
+	}
+	for _, id := range taskIDs {
+		taskEnv, err := store.GetTaskEnv(id)
+		if err != nil {
+			log.Printf("⚠️  Task %s: %v", id, err)
+			continue
+		}
+		for name, value := range taskEnv {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+// names returns the variable names, sorted
+func (e agentEnv) names() []string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// entries returns the variables as KEY=VALUE entries for a process
+func (e agentEnv) entries() []string {
+	var entries []string
+	for _, name := range e.names() {
+		entries = append(entries, name+"="+e[name])
+	}
+	return entries
+}
+
+// secrets returns the values to scrub from logs and transcripts
+func (e agentEnv) secrets() []string {
+	var secrets []string
+	for _, name := range e.names() {
+		if secret := envSecret(name, e[name]); len(secret) >= minSecretLen {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// String lists the variables for logs, secrets redacted
+func (e agentEnv) String() string {
+	list := make([]string, 0, len(e))
+	for _, name := range e.names() {
+		list = append(list, name+"="+RedactEnvValue(name, e[name]))
+	}
+	return strings.Join(list, ", ")
+}
+
+// taskCommandEnv is the environment added for commands run in a task's
+// worktree, such as its tests: the worktree's own, then the task's variables
+func taskCommandEnv(pool *git.WorktreePool, worktreePath string, env agentEnv) []string {
+	return append(worktreeEnv(pool, worktreePath), env.entries()...)
+}
+
+// redactSecrets replaces every secret in s
+func redactSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redactedValue)
+	}
+	return s
+}
@@ -0,0 +1,79 @@
+package workflow
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+)
+
+func TestParseEnv(t *testing.T) {
+	env, err := ParseEnv([]string{"FEATURE_X=on", "API_BASE_URL=http://localhost:8080/?a=b", "EMPTY="})
+	if err != nil {
+		t.Fatalf("ParseEnv: %v", err)
+	}
+	if env["FEATURE_X"] != "on" || env["API_BASE_URL"] != "http://localhost:8080/?a=b" || env["EMPTY"] != "" || len(env) != 3 {
+		t.Errorf("ParseEnv = %v", env)
+	}
+	for _, bad := range []string{"NOVALUE", "=x", "1X=y", "A-B=c"} {
+		if _, err := ParseEnv([]string{bad}); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestRedactEnvValue(t *testing.T) {
+	tests := []struct {
+		name, value, want string
+	}{
+		{"OPENAI_API_KEY", "abc123", redactedValue},
+		{"GITHUB_TOKEN", "whatever", redactedValue},
+		{"UPSTREAM", "sk-live-abcdef", redactedValue},
+		{"DATABASE_URL", "postgres://app:hunter2@db:5432/test", "postgres://app:" + redactedValue + "@db:5432/test"},
+		{"DATABASE_URL", "postgres://localhost/test", "postgres://localhost/test"},
+		{"FEATURE_X", "on", "on"},
+	}
+	for _, tt := range tests {
+		if got := RedactEnvValue(tt.name, tt.value); got != tt.want {
+			t.Errorf("RedactEnvValue(%q, %q) = %q, want %q", tt.name, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLoadAgentEnv(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	parent, _ := store.CreateTask("Parent", "", "", 0, nil)
+	child, _ := store.CreateTask("Child", "", "", 0, nil)
+	store.SetTaskEnv(parent.ID, map[string]string{"API_BASE_URL": "http://staging", "DB_PASSWORD": "hunter2"})
+	store.SetTaskEnv(child.ID, map[string]string{"FEATURE_X": "off"})
+
+	project := map[string]string{"FEATURE_X": "on", "API_BASE_URL": "http://prod"}
+	env := loadAgentEnv(store, project, parent.ID, child.ID)
+	want := []string{"API_BASE_URL=http://staging", "DB_PASSWORD=hunter2", "FEATURE_X=off"}
+	if got := env.entries(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+	if project["API_BASE_URL"] != "http://prod" {
+		t.Error("Expected the project's env left alone")
+	}
+	if s := env.String(); strings.Contains(s, "hunter2") || !strings.Contains(s, "DB_PASSWORD="+redactedValue) {
+		t.Errorf("String = %q, want the password redacted", s)
+	}
+
+	secrets := env.secrets()
+	if len(secrets) != 1 || secrets[0] != "hunter2" {
+		t.Fatalf("secrets = %v, want [hunter2]", secrets)
+	}
+	if got := redactSecrets("connecting with hunter2 failed", secrets); got != "connecting with "+redactedValue+" failed" {
+		t.Errorf("redactSecrets = %q", got)
+	}
+}
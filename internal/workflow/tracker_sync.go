@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"sync"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/integrations"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// trackerUpdate is a task status to push to the issue tracker
+type trackerUpdate struct {
+	taskID string
+	status types.TaskStatus
+}
+
+// trackerSync pushes task statuses to the issues the tasks were imported
+// from in the background, so a slow tracker doesn't hold up the workers.
+// Updates go out one at a time, so an issue's transitions land in order
+type trackerSync struct {
+	updates chan trackerUpdate
+	done    chan struct{}
+	stop    sync.Once
+}
+
+// newTrackerSync starts a syncer pushing to jira, or returns nil when it's
+// nil
+func newTrackerSync(jira *integrations.JiraSync, store *db.Store) *trackerSync {
+	if jira == nil {
+		return nil
+	}
+	s := &trackerSync{updates: make(chan trackerUpdate, 64), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		for u := range s.updates {
+			syncTrackerStatus(jira, store, u.taskID, u.status)
+		}
+	}()
+	return s
+}
+
+// push queues a task's status for its issue. No-op on a nil syncer
+func (s *trackerSync) push(taskID string, status types.TaskStatus) {
+	if s == nil {
+		return
+	}
+	s.updates <- trackerUpdate{taskID: taskID, status: status}
+}
+
+// close waits for the queued updates to go out. Nothing is pushed after it
+func (s *trackerSync) close() {
+	if s == nil {
+		return
+	}
+	s.stop.Do(func() { close(s.updates) })
+	<-s.done
+}
@@ -0,0 +1,103 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// runUsage totals the tokens and cost of the executions in one run, overall,
+// per epic and per task, for the run summary, the run report and the cost
+// budget; the all-time per-task totals live on the tasks themselves
+type runUsage struct {
+	mu     sync.Mutex
+	total  db.Usage
+	byEpic map[string]db.Usage // Keyed by epic ID; "" for tasks outside any epic
+	byTask map[string]db.Usage // Keyed by task ID
+}
+
+// record adds an execution's usage to its task, when there is a store, and
+// to the run totals
+func (r *runUsage) record(store *db.Store, task *types.Task, result *executor.ExecutionResult) {
+	usage := db.Usage{
+		InputTokens:  result.InputTokens,
+		OutputTokens: result.OutputTokens,
+		CostUSD:      result.CostUSD,
+	}
+	if usage.IsZero() {
+		return
+	}
+	if store != nil {
+		if err := store.AddTaskUsage(task.ID, usage); err != nil {
+			log.Printf("⚠️  Recording usage of task %s: %v", task.ID, err)
+		}
+	}
+	r.mu.Lock()
+	r.total.Add(usage)
+	if r.byEpic == nil {
+		r.byEpic = make(map[string]db.Usage)
+	}
+	epic := r.byEpic[task.EpicID]
+	epic.Add(usage)
+	r.byEpic[task.EpicID] = epic
+	if r.byTask == nil {
+		r.byTask = make(map[string]db.Usage)
+	}
+	spent := r.byTask[task.ID]
+	spent.Add(usage)
+	r.byTask[task.ID] = spent
+	r.mu.Unlock()
+}
+
+// task returns what a task spent this run
+func (r *runUsage) task(taskID string) db.Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.byTask[taskID]
+}
+
+// Total returns the usage recorded so far
+func (r *runUsage) Total() db.Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.total
+}
+
+// overBudget reports whether the run has spent at least maxCost USD. A
+// maxCost of 0 is no budget
+func (r *runUsage) overBudget(maxCost float64) bool {
+	return maxCost > 0 && r.Total().CostUSD >= maxCost
+}
+
+// epicLines returns one line per epic that spent anything this run, the
+// costliest first, e.g. "epic-12: 1.2M in / 80.5k out tokens, $4.10"
+func (r *runUsage) epicLines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	epics := make([]string, 0, len(r.byEpic))
+	for epic := range r.byEpic {
+		epics = append(epics, epic)
+	}
+	sort.Slice(epics, func(i, j int) bool {
+		a, b := r.byEpic[epics[i]], r.byEpic[epics[j]]
+		if a.CostUSD != b.CostUSD {
+			return a.CostUSD > b.CostUSD
+		}
+		return epics[i] < epics[j]
+	})
+
+	lines := make([]string, len(epics))
+	for i, epic := range epics {
+		name := epic
+		if name == "" {
+			name = "(no epic)"
+		}
+		lines[i] = fmt.Sprintf("%s: %s", name, r.byEpic[epic])
+	}
+	return lines
+}
@@ -0,0 +1,48 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestRunUsage_BudgetAndEpics(t *testing.T) {
+	var usage runUsage
+	if usage.overBudget(5) || usage.overBudget(0) {
+		t.Fatal("Expected a run that spent nothing to be within any budget")
+	}
+
+	usage.record(nil, &types.Task{ID: "task-1", EpicID: "epic-a"}, &executor.ExecutionResult{InputTokens: 1000, CostUSD: 1.5})
+	usage.record(nil, &types.Task{ID: "task-2", EpicID: "epic-b"}, &executor.ExecutionResult{InputTokens: 2000, CostUSD: 2.5})
+	usage.record(nil, &types.Task{ID: "task-3"}, &executor.ExecutionResult{CostUSD: 0.5})
+	usage.record(nil, &types.Task{ID: "task-4", EpicID: "epic-a"}, &executor.ExecutionResult{}) // Nothing reported
+
+	if got := usage.Total().CostUSD; got != 4.5 {
+		t.Errorf("Total cost = %v, want 4.5", got)
+	}
+	if usage.overBudget(5) {
+		t.Error("Expected $4.50 to be within a $5 budget")
+	}
+	if !usage.overBudget(4.5) {
+		t.Error("Expected $4.50 to reach a $4.50 budget")
+	}
+	if usage.overBudget(0) {
+		t.Error("Expected no budget with a max cost of 0")
+	}
+
+	lines := usage.epicLines()
+	want := []string{
+		"epic-b: 2.0k in / 0 out tokens, $2.50",
+		"epic-a: 1.0k in / 0 out tokens, $1.50",
+		"(no epic): 0 in / 0 out tokens, $0.50",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("epicLines() = %v, want %v", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("epicLines()[%d] = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
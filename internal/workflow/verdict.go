@@ -0,0 +1,104 @@
+package workflow
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/cloud-shuttle/drover/internal/dashboard"
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/events"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	outcomepkg "github.com/cloud-shuttle/drover/internal/outcome"
+	"github.com/cloud-shuttle/drover/internal/webhooks"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// agentVerdict returns the verdict an agent reported against a run that
+// exited cleanly, and why: fail or blocked, or "" when the run goes on as a
+// pass
+func agentVerdict(result *executor.ExecutionResult) (types.TaskVerdict, string) {
+	if result == nil {
+		return "", ""
+	}
+	switch result.Verdict {
+	case types.TaskVerdictFail, types.TaskVerdictBlocked:
+		reason := strings.TrimSpace(result.VerdictReason)
+		if reason == "" {
+			reason = fmt.Sprintf("the agent reported a %s verdict", result.Verdict)
+		}
+		return result.Verdict, reason
+	}
+	return "", ""
+}
+
+// completionVerdict is the verdict stored on a completed task: the agent's
+// pass when it reported one, else what its output reads as
+func completionVerdict(result *executor.ExecutionResult, output string) (types.TaskVerdict, string) {
+	outcome := outcomepkg.ParseOutput(output)
+	verdict, reason := types.TaskVerdict(outcome.Verdict), outcome.Summary
+	if result != nil && result.Verdict == types.TaskVerdictPass {
+		verdict = types.TaskVerdictPass
+		if r := strings.TrimSpace(result.VerdictReason); r != "" {
+			reason = r
+		}
+	}
+	return verdict, reason
+}
+
+// logFollowups logs the follow-up work the agent's verdict block suggests
+// for a task it completed, for whoever plans the next tasks
+func logFollowups(taskID string, result *executor.ExecutionResult) {
+	if result == nil {
+		return
+	}
+	for _, followup := range result.Followups {
+		log.Printf("📌 Task %s suggests a follow-up: %s", taskID, followup)
+	}
+}
+
+// verdictGuidance is the guidance the retry of a task gets after the agent
+// failed its own attempt
+func verdictGuidance(reason string) string {
+	return "The previous attempt ended with a fail verdict: " + reason + ". Address this before finishing."
+}
+
+// retryOnVerdict records a fail verdict and, unless the task is out of
+// attempts, leaves its reason as guidance for the retry
+func retryOnVerdict(store *db.Store, taskID, reason string, requeued bool) {
+	if store == nil {
+		return
+	}
+	if err := store.SetTaskVerdict(taskID, types.TaskVerdictFail, reason); err != nil {
+		log.Printf("Error storing verdict for task %s: %v", taskID, err)
+	}
+	if !requeued {
+		return
+	}
+	if _, err := store.AddGuidance(taskID, verdictGuidance(reason)); err != nil {
+		log.Printf("Error adding guidance for task %s: %v", taskID, err)
+	}
+}
+
+// blockOnVerdict parks a task the agent reported blocked, with its reason as
+// the task's last error and verdict, until 'drover resolve' sends it back to
+// the queue
+func blockOnVerdict(store *db.Store, hooks *webhooks.Manager, record func(events.EventType, string, string, map[string]any),
+	task *types.Task, reason string) {
+	log.Printf("🚧 Task %s blocked: %s (unblock it with 'drover resolve %s')", task.ID, reason, task.ID)
+	if err := store.UpdateTaskStatus(task.ID, types.TaskStatusBlocked, reason); err != nil {
+		log.Printf("Error updating task status: %v", err)
+	}
+	if err := store.SetTaskVerdict(task.ID, types.TaskVerdictBlocked, reason); err != nil {
+		log.Printf("Error storing verdict for task %s: %v", task.ID, err)
+	}
+
+	dashboard.BroadcastTaskBlocked(task.ID, task.Title)
+	if hooks != nil {
+		hooks.EmitTaskBlocked(task.ID, task.Title)
+	}
+	record(events.EventTaskBlocked, task.ID, task.EpicID, map[string]any{
+		"reason":  reason,
+		"verdict": string(types.TaskVerdictBlocked),
+	})
+}
@@ -0,0 +1,89 @@
+package workflow
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/events"
+	"github.com/cloud-shuttle/drover/internal/executor"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestAgentVerdict(t *testing.T) {
+	tests := []struct {
+		result      *executor.ExecutionResult
+		wantVerdict types.TaskVerdict
+		wantReason  string
+	}{
+		{&executor.ExecutionResult{Success: true}, "", ""},
+		{&executor.ExecutionResult{Success: true, Verdict: types.TaskVerdictPass, VerdictReason: "done"}, "", ""},
+		{&executor.ExecutionResult{Success: true, Verdict: types.TaskVerdictFail, VerdictReason: " tests still red "}, types.TaskVerdictFail, "tests still red"},
+		{&executor.ExecutionResult{Success: true, Verdict: types.TaskVerdictBlocked}, types.TaskVerdictBlocked, "the agent reported a blocked verdict"},
+		{nil, "", ""},
+	}
+	for _, tt := range tests {
+		verdict, reason := agentVerdict(tt.result)
+		if verdict != tt.wantVerdict || reason != tt.wantReason {
+			t.Errorf("agentVerdict(%+v) = %q, %q; want %q, %q", tt.result, verdict, reason, tt.wantVerdict, tt.wantReason)
+		}
+	}
+
+	verdict, reason := completionVerdict(&executor.ExecutionResult{Verdict: types.TaskVerdictPass, VerdictReason: "all green"}, "Error: something unrelated failed")
+	if verdict != types.TaskVerdictPass || reason != "all green" {
+		t.Errorf("Expected the agent's pass to win over its output, got %q, %q", verdict, reason)
+	}
+}
+
+func TestVerdict_RetryAndBlock(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	failed, _ := store.CreateTask("Failed by its agent", "", "", 0, nil)
+	retryOnVerdict(store, failed.ID, "the migration is missing", true)
+	got, _ := store.GetTask(failed.ID)
+	if got.Verdict != types.TaskVerdictFail || got.VerdictReason != "the migration is missing" {
+		t.Errorf("Expected the fail verdict recorded, got %q (%q)", got.Verdict, got.VerdictReason)
+	}
+	guidance, err := store.GetPendingGuidance(failed.ID)
+	if err != nil {
+		t.Fatalf("GetPendingGuidance: %v", err)
+	}
+	if len(guidance) != 1 || !strings.Contains(guidance[0].Message, "the migration is missing") {
+		t.Errorf("Expected the reason as guidance for the retry, got %+v", guidance)
+	}
+
+	exhausted, _ := store.CreateTask("Out of attempts", "", "", 0, nil)
+	retryOnVerdict(store, exhausted.ID, "still broken", false)
+	if guidance, _ := store.GetPendingGuidance(exhausted.ID); len(guidance) != 0 {
+		t.Errorf("Expected no guidance for a task that won't be retried, got %+v", guidance)
+	}
+
+	blocked, _ := store.CreateTask("Blocked by its agent", "", "", 0, nil)
+	var recorded []events.EventType
+	record := func(eventType events.EventType, taskID, epicID string, data map[string]any) {
+		recorded = append(recorded, eventType)
+	}
+	blockOnVerdict(store, nil, record, blocked, "needs an API key")
+	got, _ = store.GetTask(blocked.ID)
+	if got.Status != types.TaskStatusBlocked || got.LastError != "needs an API key" || got.Verdict != types.TaskVerdictBlocked {
+		t.Errorf("Expected the task blocked with the agent's reason, got %s (%q, verdict %q)", got.Status, got.LastError, got.Verdict)
+	}
+	if len(recorded) != 1 || recorded[0] != events.EventTaskBlocked {
+		t.Errorf("Expected a task.blocked event, got %v", recorded)
+	}
+
+	if err := store.ResolveTask(blocked.ID, "key added"); err != nil {
+		t.Fatalf("ResolveTask: %v", err)
+	}
+	if status, _ := store.GetTaskStatus(blocked.ID); status != types.TaskStatusReady {
+		t.Errorf("Expected 'drover resolve' to send the task back to the queue, got %s", status)
+	}
+}
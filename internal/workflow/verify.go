@@ -0,0 +1,123 @@
+package workflow
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/project"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+// defaultVerifyTimeout bounds one verification command when verify_timeout
+// isn't set
+const defaultVerifyTimeout = 10 * time.Minute
+
+// maxVerifyOutput bounds the output of a failed command kept on the task and
+// shown to the retry; the end of the output is kept, where failures show up
+const maxVerifyOutput = 8 * 1024
+
+// verifier runs the project's verification commands in a task's worktree
+// before its work is merged
+type verifier struct {
+	commands []string
+	timeout  time.Duration // Upper bound for each command
+}
+
+// newVerifier returns the verifier for .drover.toml's verify commands, or nil
+// when there are none
+func newVerifier(projectCfg *project.Config) *verifier {
+	if len(projectCfg.Verify) == 0 {
+		return nil
+	}
+	timeout := projectCfg.VerifyTimeout
+	if timeout == 0 {
+		timeout = defaultVerifyTimeout
+	}
+	return &verifier{commands: projectCfg.Verify, timeout: timeout}
+}
+
+// verifyError is a verification command that failed
+type verifyError struct {
+	Command string
+	Output  string // The end of what the command printed
+	Err     error
+}
+
+func (e *verifyError) Error() string {
+	return fmt.Sprintf("verification %q failed: %v\n%s", e.Command, e.Err, e.Output)
+}
+
+// run runs the commands in order in dir, with extra KEY=VALUE env entries,
+// stopping at the first that fails. A nil verifier passes
+func (v *verifier) run(ctx context.Context, taskID, dir string, env []string) error {
+	if v == nil {
+		return nil
+	}
+	for _, command := range v.commands {
+		cmdCtx, cancel := context.WithTimeout(ctx, v.timeout)
+		cmd := exec.CommandContext(cmdCtx, "sh", "-c", command)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		var output bytes.Buffer
+		cmd.Stdout = &output
+		cmd.Stderr = &output
+
+		start := time.Now()
+		err := cmd.Run()
+		if cmdCtx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %v", v.timeout)
+		}
+		cancel()
+		if err != nil {
+			return &verifyError{Command: command, Output: tail(output.String(), maxVerifyOutput), Err: err}
+		}
+		log.Printf("✔️  Task %s: %s passed in %v", taskID, command, time.Since(start).Round(time.Millisecond))
+	}
+	return nil
+}
+
+// tail returns the last n bytes of s, marking the cut
+func tail(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return "…" + s[len(s)-n:]
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// verifyGuidance is the guidance the retry of a task gets after its work
+// failed verification
+func verifyGuidance(failure string) string {
+	return "The previous attempt failed verification before merge: " + failure + "\n\nMake sure this passes before finishing."
+}
+
+// retryOnVerification records a failed verification as the task's fail
+// verdict and, unless the task is out of attempts, leaves the failure as
+// guidance for the retry
+func retryOnVerification(store *db.Store, taskID, failure string, requeued bool) {
+	if store == nil {
+		return
+	}
+	if err := store.SetTaskVerdict(taskID, types.TaskVerdictFail, firstLine(failure)); err != nil {
+		log.Printf("Error storing verdict for task %s: %v", taskID, err)
+	}
+	if !requeued {
+		return
+	}
+	if _, err := store.AddGuidance(taskID, verifyGuidance(failure)); err != nil {
+		log.Printf("Error adding guidance for task %s: %v", taskID, err)
+	}
+}
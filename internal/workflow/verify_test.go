@@ -0,0 +1,69 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloud-shuttle/drover/internal/db"
+	"github.com/cloud-shuttle/drover/internal/project"
+	"github.com/cloud-shuttle/drover/pkg/types"
+)
+
+func TestVerifier(t *testing.T) {
+	if v := newVerifier(&project.Config{}); v != nil {
+		t.Errorf("Expected no verifier without verify commands, got %+v", v)
+	}
+	if err := (*verifier)(nil).run(context.Background(), "task-1", t.TempDir(), nil); err != nil {
+		t.Errorf("Expected a nil verifier to pass, got %v", err)
+	}
+
+	v := newVerifier(&project.Config{Verify: []string{"test \"$STAGE\" = verify", "echo 3 problems; exit 3", "touch never-run"}})
+	if v.timeout != defaultVerifyTimeout {
+		t.Errorf("Expected the default timeout, got %v", v.timeout)
+	}
+	dir := t.TempDir()
+	err := v.run(context.Background(), "task-1", dir, []string{"STAGE=verify"})
+	var verr *verifyError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected a verification failure, got %v", err)
+	}
+	if verr.Command != "echo 3 problems; exit 3" || verr.Output != "3 problems" {
+		t.Errorf("Expected the failing command and its output, got %q: %q", verr.Command, verr.Output)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "never-run")); len(matches) != 0 {
+		t.Error("Expected verification to stop at the first failing command")
+	}
+
+	slow := &verifier{commands: []string{"sleep 5"}, timeout: 100 * time.Millisecond}
+	if err := slow.run(context.Background(), "task-1", dir, nil); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected the command to time out, got %v", err)
+	}
+}
+
+func TestRetryOnVerification(t *testing.T) {
+	store, err := db.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer store.Close()
+	if err := store.InitSchema(); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	task, _ := store.CreateTask("Failed verification", "", "", 0, nil)
+	failure := (&verifyError{Command: "npm run lint", Output: "src/a.ts: 2 errors", Err: errors.New("exit status 1")}).Error()
+	retryOnVerification(store, task.ID, failure, true)
+
+	got, _ := store.GetTask(task.ID)
+	if got.Verdict != types.TaskVerdictFail || got.VerdictReason != `verification "npm run lint" failed: exit status 1` {
+		t.Errorf("Expected a fail verdict naming the command, got %q (%q)", got.Verdict, got.VerdictReason)
+	}
+	guidance, _ := store.GetPendingGuidance(task.ID)
+	if len(guidance) != 1 || !strings.Contains(guidance[0].Message, "src/a.ts: 2 errors") {
+		t.Errorf("Expected the command's output as guidance for the retry, got %+v", guidance)
+	}
+}
@@ -31,6 +31,9 @@ const (
 	KeyWorktreePath   = "drover.worktree.path"
 	KeyWorktreeID     = "drover.worktree.id"
 
+	// Concurrency attributes
+	KeySerializationPoint = "drover.serialization.point"
+
 	// Agent attributes
 	KeyAgentType      = "drover.agent.type"
 	KeyAgentModel     = "drover.agent.model"
@@ -58,6 +61,10 @@ const (
 	AgentTypeCodex      = "codex"
 	AgentTypeAmp        = "amp"
 	AgentTypeOpenCode   = "opencode"
+	AgentTypeAider      = "aider"
+	AgentTypeGoose      = "goose"
+	AgentTypeCustom     = "custom"
+	AgentTypeLLM        = "llm"
 
 	// Error categories
 	ErrorCategoryAgent     = "agent"
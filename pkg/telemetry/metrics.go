@@ -52,6 +52,7 @@ var (
 	claimLatencyHistogram       metric.Float64Histogram
 	worktreeSetupHistogram      metric.Float64Histogram
 	syncDurationHistogram       metric.Float64Histogram
+	serializationWaitHistogram  metric.Float64Histogram
 )
 
 // initMetrics initializes all metric instruments
@@ -200,6 +201,14 @@ func initMetrics() error {
 		return err
 	}
 
+	if serializationWaitHistogram, err = meter.Float64Histogram(
+		"drover_serialization_wait_seconds",
+		metric.WithDescription("Time workers spend queued at serialization points (merge lock, pool acquire)"),
+		metric.WithUnit("s"),
+	); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -381,6 +390,16 @@ func RecordWorktreeSetup(ctx context.Context, duration time.Duration) {
 	worktreeSetupHistogram.Record(ctx, duration.Seconds())
 }
 
+// RecordSerializationWait records time a worker spent queued at a serialization point
+func RecordSerializationWait(ctx context.Context, point string, wait time.Duration) {
+	if serializationWaitHistogram == nil {
+		return
+	}
+	serializationWaitHistogram.Record(ctx, wait.Seconds(),
+		metric.WithAttributes(attribute.String(KeySerializationPoint, point)),
+	)
+}
+
 // Sync metric recording functions
 
 // RecordSyncCompleted records a successful worktree sync operation
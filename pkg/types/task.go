@@ -37,6 +37,7 @@ const (
 	TaskVerdictFail    TaskVerdict = "fail"     // Task failed
 	TaskVerdictBlocked TaskVerdict = "blocked"  // Task was blocked
 	TaskVerdictUnknown TaskVerdict = "unknown"  // Verdict could not be determined
+	TaskVerdictPolicyViolation TaskVerdict = "policy_violation" // Task modified protected paths
 )
 
 // Task represents a unit of work for an AI agent
@@ -55,18 +56,40 @@ type Task struct {
 	LastError      string                `json:"last_error" db:"last_error"`
 	ClaimedBy      string                `json:"claimed_by" db:"claimed_by"`
 	ClaimedAt      *int64                `json:"claimed_at" db:"claimed_at"`
-	Operator       string                `json:"operator" db:"operator"` // The operator/user who created or claimed this task
+	Operator       string                `json:"operator" db:"operator"` // The human or bot overseeing this task
 	Verdict        TaskVerdict           `json:"verdict" db:"verdict"`     // Structured outcome verdict
 	VerdictReason   string               `json:"verdict_reason" db:"verdict_reason"` // Reason for verdict
 	TestMode       string                `json:"test_mode,omitempty" db:"test_mode"`       // Test execution mode (strict/lenient/disabled)
 	TestScope      string                `json:"test_scope,omitempty" db:"test_scope"`     // Test scope (all/diff/skip)
 	TestCommand    string                `json:"test_command,omitempty" db:"test_command"` // Custom test command
+	CommitAuthor   string                `json:"commit_author,omitempty" db:"commit_author"` // Agent identity the task's commit was authored as
+	CommitSHA      string                `json:"commit_sha,omitempty" db:"commit_sha"`       // Commit produced by the task
+	ScheduledAt    *int64                `json:"scheduled_at,omitempty" db:"scheduled_at"`   // Not claimed before this time
+	DueAt          *int64                `json:"due_at,omitempty" db:"due_at"`               // Overdue if unfinished after this time
+	EffectivePriority *int               `json:"effective_priority,omitempty" db:"effective_priority"` // Claim-order override set by moving the task in the queue
+	ExternalRef    string                `json:"external_ref,omitempty" db:"external_ref"`   // Issue the task was imported from, e.g. "jira:PROJ-123"
+	InputTokens    int64                 `json:"input_tokens,omitempty" db:"input_tokens"`   // Tokens sent to the agent, over all attempts
+	OutputTokens   int64                 `json:"output_tokens,omitempty" db:"output_tokens"` // Tokens the agent generated, over all attempts
+	CostUSD        float64               `json:"cost_usd,omitempty" db:"cost_usd"`           // What the agent reported the task cost, over all attempts
+	RetryPolicy    string                `json:"retry_policy,omitempty" db:"retry_policy"`   // Overrides of the global retry policy, e.g. "backoff=1m,on=agent"
+	MutexKey       string                `json:"mutex_key,omitempty" db:"mutex_key"`         // Tasks with the same key never run at once, e.g. "schema.sql"
+	Repo           string                `json:"repo,omitempty" db:"repo"`                   // Repository the task works in, by its name in .drover.toml; empty is the project's own
+	Env            map[string]string     `json:"env,omitempty" db:"env"`                     // Environment variables the task's agent runs with, over the project's [env]
 	CreatedAt      int64                 `json:"created_at" db:"created_at"`
 	UpdatedAt      int64                 `json:"updated_at" db:"updated_at"`
 	// ExecutionContext is not persisted in DB - it's set at runtime for execution
 	ExecutionContext *TaskExecutionContext `json:"-" db:"-"` // Runtime execution context (guidance, worktree path, etc.)
 }
 
+// ClaimPriority is the priority workers claim the task by: its effective
+// priority if it was moved in the queue, otherwise its priority
+func (t *Task) ClaimPriority() int {
+	if t.EffectivePriority != nil {
+		return *t.EffectivePriority
+	}
+	return t.Priority
+}
+
 // EpicStatus represents the state of an epic
 type EpicStatus string
 
@@ -77,11 +100,12 @@ const (
 
 // Epic groups related tasks
 type Epic struct {
-	ID          string     `json:"id" db:"id"`
-	Title       string     `json:"title" db:"title"`
-	Description string     `json:"description" db:"description"`
-	Status      EpicStatus `json:"status" db:"status"`
-	CreatedAt   int64      `json:"created_at" db:"created_at"`
+	ID           string     `json:"id" db:"id"`
+	Title        string     `json:"title" db:"title"`
+	Description  string     `json:"description" db:"description"`
+	Status       EpicStatus `json:"status" db:"status"`
+	ParentEpicID string     `json:"parent_epic_id,omitempty" db:"parent_epic_id"` // Enclosing epic, for sub-epics
+	CreatedAt    int64      `json:"created_at" db:"created_at"`
 }
 
 // TaskDependency represents a blocked-by relationship
@@ -99,10 +123,66 @@ type GuidanceMessage struct {
 	Delivered bool   `json:"delivered"`
 }
 
+// ActivityKind classifies an entry in a task's activity timeline
+type ActivityKind string
+
+const (
+	ActivityComment    ActivityKind = "comment"    // Human note
+	ActivityGuidance   ActivityKind = "guidance"   // Guidance queued for the agent
+	ActivityStatus     ActivityKind = "status"     // Status transition
+	ActivityVerdict    ActivityKind = "verdict"    // Agent verdict on an attempt
+	ActivityAssignment ActivityKind = "assignment" // Operator assigned or unassigned
+	ActivityChecklist  ActivityKind = "checklist"  // Definition-of-done evaluation
+	ActivityQueue      ActivityKind = "queue"      // Moved in the run queue
+)
+
+// TaskActivity is one entry in a task's activity timeline
+type TaskActivity struct {
+	ID        string       `json:"id"`
+	TaskID    string       `json:"task_id"`
+	Kind      ActivityKind `json:"kind"`
+	Author    string       `json:"author,omitempty"` // Operator or worker; empty for drover itself
+	Body      string       `json:"body"`
+	CreatedAt int64        `json:"created_at"`
+}
+
+// AuditEvent is an immutable record of one task status transition
+type AuditEvent struct {
+	ID        int64      `json:"id"`
+	TaskID    string     `json:"task_id"`
+	Actor     string     `json:"actor"` // Operator, worker, or "drover"
+	From      TaskStatus `json:"from"`
+	To        TaskStatus `json:"to"`
+	Error     string     `json:"error,omitempty"` // Error or reason recorded with the transition
+	CreatedAt int64      `json:"created_at"`
+}
+
+// TaskAttempt is one execution of a task, kept after the task is retried
+type TaskAttempt struct {
+	ID             int64       `json:"id"`
+	TaskID         string      `json:"task_id"`
+	Number         int         `json:"number"` // 1 for the first attempt
+	WorkerID       string      `json:"worker_id"`
+	StartedAt      int64       `json:"started_at"`
+	EndedAt        *int64      `json:"ended_at,omitempty"` // Nil while the attempt runs
+	Outcome        TaskStatus  `json:"outcome,omitempty"`  // Task status the attempt left behind
+	Error          string      `json:"error,omitempty"`
+	Verdict        TaskVerdict `json:"verdict,omitempty"`
+	OutputPath     string      `json:"output_path,omitempty"`     // Log of the agent's output
+	TranscriptSize int64       `json:"transcript_size,omitempty"` // Bytes the agent output; its transcript is kept when non-zero
+	SessionID      string      `json:"session_id,omitempty"`      // Agent session the attempt ran in, for a retry to resume
+	Model          string      `json:"model,omitempty"`           // Agent and model that ran the attempt, when it could fall back to another
+}
+
 // TaskExecutionContext provides additional context for task execution
 type TaskExecutionContext struct {
 	Guidance   []*GuidanceMessage `json:"guidance,omitempty"`   // Pending guidance messages
 	WorktreePath string           `json:"worktree_path,omitempty"` // Path to the worktree
+	Env          []string         `json:"env,omitempty"`           // Extra KEY=VALUE entries for commands run in the worktree
+	ResumeSession string          `json:"resume_session,omitempty"` // Agent session of the previous attempt to pick up from
+	MCPConfig     string          `json:"mcp_config,omitempty"`     // MCP server config written into the worktree for the agent
+	GuidanceFile  string          `json:"guidance_file,omitempty"`  // File guidance added while the task runs is appended to, for agents that take it mid-run
+	Secrets       []string        `json:"-"`                        // Values of Env redacted from the agent's output wherever it goes
 }
 
 // TaskCheckpoint represents the execution state of a task for crash recovery